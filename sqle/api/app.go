@@ -112,9 +112,17 @@ func StartApi(net *gracenet.Net, exitChan chan struct{}, config config.SqleConfi
 		// rule template
 		v1Router.POST("/rule_templates", v1.CreateRuleTemplate, AdminUserAllowed())
 		v1Router.POST("/rule_templates/:rule_template_name/clone", v1.CloneRuleTemplate, AdminUserAllowed())
+		v1Router.POST("/rule_templates/import", v1.ImportRuleTemplate, AdminUserAllowed())
 		v1Router.PATCH("/rule_templates/:rule_template_name/", v1.UpdateRuleTemplate, AdminUserAllowed())
 		v1Router.DELETE("/rule_templates/:rule_template_name/", v1.DeleteRuleTemplate, AdminUserAllowed())
 
+		// custom rule
+		v1Router.GET("/custom_rules", v1.GetCustomRules, AdminUserAllowed())
+		v1Router.POST("/custom_rules", v1.CreateCustomRule, AdminUserAllowed())
+		v1Router.GET("/custom_rules/:rule_name/", v1.GetCustomRule, AdminUserAllowed())
+		v1Router.PATCH("/custom_rules/:rule_name/", v1.UpdateCustomRule, AdminUserAllowed())
+		v1Router.DELETE("/custom_rules/:rule_name/", v1.DeleteCustomRule, AdminUserAllowed())
+
 		// workflow template
 		v1Router.GET("/workflow_templates", v1.GetWorkflowTemplates, AdminUserAllowed())
 		v1Router.POST("/workflow_templates", v1.CreateWorkflowTemplate, AdminUserAllowed())
@@ -132,6 +140,13 @@ func StartApi(net *gracenet.Net, exitChan chan struct{}, config config.SqleConfi
 		v1Router.PATCH("/audit_whitelist/:audit_whitelist_id/", v1.UpdateAuditWhitelistById, AdminUserAllowed())
 		v1Router.DELETE("/audit_whitelist/:audit_whitelist_id/", v1.DeleteAuditWhitelistById, AdminUserAllowed())
 
+		// rule exemption
+		v1Router.GET("/rule_exemptions", v1.GetRuleExemptions, AdminUserAllowed())
+		v1Router.POST("/rule_exemptions", v1.CreateRuleExemption, AdminUserAllowed())
+		v1Router.GET("/rule_exemptions/:rule_exemption_id/", v1.GetRuleExemption, AdminUserAllowed())
+		v1Router.PATCH("/rule_exemptions/:rule_exemption_id/", v1.UpdateRuleExemption, AdminUserAllowed())
+		v1Router.DELETE("/rule_exemptions/:rule_exemption_id/", v1.DeleteRuleExemption, AdminUserAllowed())
+
 		// configurations
 		v1Router.GET("/configurations/ldap", v1.GetLDAPConfiguration, AdminUserAllowed())
 		v1Router.PATCH("/configurations/ldap", v1.UpdateLDAPConfiguration, AdminUserAllowed())
@@ -149,6 +164,9 @@ func StartApi(net *gracenet.Net, exitChan chan struct{}, config config.SqleConfi
 		v1Router.POST("/configurations/license/check", v1.CheckLicense, AdminUserAllowed())
 		v1Router.GET("/configurations/oauth2", v1.GetOauth2Configuration, AdminUserAllowed())
 		v1Router.PATCH("/configurations/oauth2", v1.UpdateOauth2Configuration, AdminUserAllowed())
+		v1Router.GET("/configurations/plugins", v1.GetPlugins, AdminUserAllowed())
+		v1Router.GET("/configurations/bundle", v1.ExportConfigurationBundle, AdminUserAllowed())
+		v1Router.POST("/configurations/bundle/apply", v1.ApplyConfigurationBundle, AdminUserAllowed())
 
 	}
 
@@ -175,6 +193,7 @@ func StartApi(net *gracenet.Net, exitChan chan struct{}, config config.SqleConfi
 	v1Router.GET("/rule_templates", v1.GetRuleTemplates)
 	v1Router.GET("/rule_template_tips", v1.GetRuleTemplateTips)
 	v1Router.GET("/rule_templates/:rule_template_name/", v1.GetRuleTemplate)
+	v1Router.GET("/rule_templates/:rule_template_name/export", v1.ExportRuleTemplate)
 
 	//rule
 	v1Router.GET("/rules", v1.GetRules)
@@ -189,6 +208,7 @@ func StartApi(net *gracenet.Net, exitChan chan struct{}, config config.SqleConfi
 	v1Router.PATCH("/workflows/:workflow_id/", v1.UpdateWorkflow)
 	v1Router.PUT("/workflows/:workflow_id/schedule", v1.UpdateWorkflowSchedule)
 	v1Router.POST("/workflows/:workflow_id/task/execute", v1.ExecuteTaskOnWorkflow)
+	v1Router.POST("/workflows/:workflow_id/task/rollback", v1.RollbackTaskOnWorkflow)
 
 	// task
 	v1Router.POST("/tasks/audits", v1.CreateAndAuditTask)
@@ -197,6 +217,9 @@ func StartApi(net *gracenet.Net, exitChan chan struct{}, config config.SqleConfi
 	v1Router.GET("/tasks/audits/:task_id/sql_report", v1.DownloadTaskSQLReportFile)
 	v1Router.GET("/tasks/audits/:task_id/sql_file", v1.DownloadTaskSQLFile)
 	v1Router.GET("/tasks/audits/:task_id/sql_content", v1.GetAuditTaskSQLContent)
+	v1Router.POST("/tasks/audits/:task_id/dry_run", v1.DryRunTask)
+	v1Router.POST("/tasks/audits/:task_id/cancel", v1.CancelTask)
+	v1Router.GET("/tasks/audits/:task_id/progress", v1.GetTaskProgress)
 	v1Router.PATCH("/tasks/audits/:task_id/sqls/:number", v1.UpdateAuditTaskSQLs)
 
 	// dashboard
@@ -213,6 +236,7 @@ func StartApi(net *gracenet.Net, exitChan chan struct{}, config config.SqleConfi
 	v1Router.GET("/audit_plans", v1.GetAuditPlans)
 	v1Router.GET("/audit_plans/:audit_plan_name/reports", v1.GetAuditPlanReports)
 	v1Router.GET("/audit_plans/:audit_plan_name/reports/:audit_plan_report_id/", v1.GetAuditPlanReport)
+	v1Router.GET("/audit_plans/:audit_plan_name/reports/:audit_plan_report_id/export", v1.ExportAuditPlanReport)
 	// deprecated
 	v1Router.GET("/audit_plans/:audit_plan_name/report/:audit_plan_report_id/", DeprecatedBy(apiV2))
 	v2Router.GET("/audit_plans/:audit_plan_name/report/:audit_plan_report_id/", v2.GetAuditPlanReportSQLs)
@@ -102,23 +102,30 @@ func JSONNewDataNotExistErr(c echo.Context, format string, a ...interface{}) err
 // ReadFileContent read content from http body by name if file exist,
 // the name is a http form data key, not file name.
 func ReadFileContent(c echo.Context, name string) (content string, fileExist bool, err error) {
+	_, content, fileExist, err = ReadFile(c, name)
+	return content, fileExist, err
+}
+
+// ReadFile is the same as ReadFileContent, but it also returns the name of
+// uploaded file.
+func ReadFile(c echo.Context, name string) (fileName, content string, fileExist bool, err error) {
 	file, err := c.FormFile(name)
 	if err == http.ErrMissingFile {
-		return "", false, nil
+		return "", "", false, nil
 	}
 	if err != nil {
-		return "", false, errors.New(errors.ReadUploadFileError, err)
+		return "", "", false, errors.New(errors.ReadUploadFileError, err)
 	}
 	src, err := file.Open()
 	if err != nil {
-		return "", false, errors.New(errors.ReadUploadFileError, err)
+		return "", "", false, errors.New(errors.ReadUploadFileError, err)
 	}
 	defer src.Close()
 	data, err := ioutil.ReadAll(src)
 	if err != nil {
-		return "", false, errors.New(errors.ReadUploadFileError, err)
+		return "", "", false, errors.New(errors.ReadUploadFileError, err)
 	}
-	return string(data), true, nil
+	return file.Filename, string(data), true, nil
 }
 
 // subjectUser should be admin user.
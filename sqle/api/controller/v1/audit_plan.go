@@ -15,6 +15,7 @@ import (
 	"github.com/actiontech/sqle/sqle/model"
 	"github.com/actiontech/sqle/sqle/notification"
 	"github.com/actiontech/sqle/sqle/pkg/params"
+	"github.com/actiontech/sqle/sqle/pkg/report"
 	"github.com/actiontech/sqle/sqle/server/auditplan"
 	"github.com/actiontech/sqle/sqle/utils"
 
@@ -631,6 +632,94 @@ func GetAuditPlanReport(c echo.Context) error {
 	})
 }
 
+type ExportAuditPlanReportReqV1 struct {
+	Format    string `json:"format" query:"format" enums:"sarif,junit" valid:"oneof=sarif junit"`
+	FailLevel string `json:"fail_level" query:"fail_level" enums:"notice,warn,error" valid:"omitempty,oneof=notice warn error"`
+}
+
+// @Summary 导出指定审核计划的SQL审核报告
+// @Description export audit plan report in SARIF 2.1.0 or JUnit XML format, which can be consumed by code scanning of GitHub or GitLab
+// @Id exportAuditPlanReportV1
+// @Tags audit_plan
+// @Security ApiKeyAuth
+// @Param audit_plan_name path string true "audit plan name"
+// @Param audit_plan_report_id path string true "audit plan report id"
+// @Param format query string true "report format" Enums(sarif, junit)
+// @Param fail_level query string false "the SQLs whose audit level is higher or equal than it are failed test cases in JUnit XML, default is error" Enums(notice, warn, error)
+// @Success 200 file 1 "audit plan report file"
+// @router /v1/audit_plans/{audit_plan_name}/reports/{audit_plan_report_id}/export [get]
+func ExportAuditPlanReport(c echo.Context) error {
+	req := new(ExportAuditPlanReportReqV1)
+	if err := controller.BindAndValidateReq(c, req); err != nil {
+		return err
+	}
+	apName := c.Param("audit_plan_name")
+	err := CheckCurrentUserCanAccessAuditPlan(c, apName, model.OP_AUDIT_PLAN_VIEW_OTHERS)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	id := c.Param("audit_plan_report_id")
+	reportID, err := strconv.Atoi(id)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataInvalid, fmt.Errorf("parse audit plan report id failed: %v", err)))
+	}
+	s := model.GetStorage()
+	ap, _, err := s.GetAuditPlanByName(apName)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	apReport, exist, err := s.GetAuditPlanReportByID(uint(reportID))
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist || apReport.AuditPlanID != ap.ID {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataNotExist, fmt.Errorf("audit plan report not exist")))
+	}
+	reportSQLs, err := s.GetAuditPlanReportSQLsByReportID(apReport.ID)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	r := newAuditReport(apName)
+	for i, reportSQL := range reportSQLs {
+		sqlResults, err := reportSQL.GetAuditResults()
+		if err != nil {
+			return controller.JSONBaseErrorReq(c, err)
+		}
+		results := make([]*report.Result, 0, len(sqlResults))
+		for _, result := range sqlResults {
+			results = append(results, &report.Result{
+				Level:      result.Level,
+				Message:    result.Message,
+				RuleName:   result.RuleName,
+				Category:   result.Category,
+				Suggestion: result.Suggestion,
+				Line:       result.PositionLine,
+				Exempted:   result.ExemptionId != 0,
+			})
+		}
+		// the reports which are created before the structured findings are stored.
+		if len(results) == 0 {
+			results = parseAuditResult(reportSQL.AuditResult)
+		}
+		r.SQLs = append(r.SQLs, &report.SQL{
+			Number:   uint(i + 1),
+			Content:  reportSQL.SQL,
+			FilePath: reportSQL.SourceFile,
+			Line:     reportSQL.SourceLine,
+			Results:  results,
+		})
+	}
+
+	fileName := fmt.Sprintf("audit_plan_report_%v_%v", apName, id)
+	err = downloadAuditReport(c, r, ap.DBType, req.Format, req.FailLevel, fileName)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	return nil
+}
+
 type GetAuditPlanReportSQLsReqV1 struct {
 	PageIndex uint32 `json:"page_index" query:"page_index" valid:"required"`
 	PageSize  uint32 `json:"page_size" query:"page_size" valid:"required"`
@@ -674,6 +763,14 @@ type AuditPlanSQLReqV1 struct {
 	Counter              string `json:"audit_plan_sql_counter" form:"audit_plan_sql_counter" example:"6" valid:"required"`
 	LastReceiveText      string `json:"audit_plan_sql_last_receive_text" form:"audit_plan_sql_last_receive_text" example:"select * from t1 where id = 1"`
 	LastReceiveTimestamp string `json:"audit_plan_sql_last_receive_timestamp" form:"audit_plan_sql_last_receive_timestamp" example:"RFC3339"`
+	// the following metrics are uploaded by slow query scanner, the time is in seconds.
+	QueryTimeAvg    *float64 `json:"audit_plan_sql_query_time_avg,omitempty" form:"audit_plan_sql_query_time_avg" example:"3.22"`
+	QueryTimeMax    *float64 `json:"audit_plan_sql_query_time_max,omitempty" form:"audit_plan_sql_query_time_max" example:"5.12"`
+	LockTimeAvg     *float64 `json:"audit_plan_sql_lock_time_avg,omitempty" form:"audit_plan_sql_lock_time_avg" example:"0.01"`
+	RowsExaminedAvg *float64 `json:"audit_plan_sql_rows_examined_avg,omitempty" form:"audit_plan_sql_rows_examined_avg" example:"1000"`
+	// the location of SQL is uploaded by the scanners which extract SQL from source files.
+	FilePath string `json:"audit_plan_sql_file_path,omitempty" form:"audit_plan_sql_file_path" example:"db/migration/V1__init.sql"`
+	Line     int    `json:"audit_plan_sql_line,omitempty" form:"audit_plan_sql_line" example:"10"`
 }
 
 // @Summary 全量同步SQL到审核计划
@@ -792,6 +889,22 @@ func checkAndConvertToModelAuditPlanSQL(c echo.Context, apName string, reqSQLs [
 			"counter":                counter,
 			"last_receive_timestamp": reqSQL.LastReceiveTimestamp,
 		}
+		if reqSQL.QueryTimeAvg != nil {
+			info[auditplan.InfoKeyQueryTimeAvg] = *reqSQL.QueryTimeAvg
+		}
+		if reqSQL.QueryTimeMax != nil {
+			info[auditplan.InfoKeyQueryTimeMax] = *reqSQL.QueryTimeMax
+		}
+		if reqSQL.LockTimeAvg != nil {
+			info[auditplan.InfoKeyLockTimeAvg] = *reqSQL.LockTimeAvg
+		}
+		if reqSQL.RowsExaminedAvg != nil {
+			info[auditplan.InfoKeyRowsExaminedAvg] = *reqSQL.RowsExaminedAvg
+		}
+		if reqSQL.FilePath != "" {
+			info[auditplan.InfoKeyFilePath] = reqSQL.FilePath
+			info[auditplan.InfoKeyLine] = reqSQL.Line
+		}
 		sqls[i] = &auditplan.SQL{
 			Fingerprint: fp,
 			SQLContent:  reqSQL.LastReceiveText,
@@ -3,6 +3,7 @@ package v1
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/actiontech/sqle/sqle/api/controller"
 	"github.com/actiontech/sqle/sqle/config"
@@ -412,7 +413,13 @@ type GetDriversResV1 struct {
 }
 
 type DriversResV1 struct {
-	Drivers []string `json:"driver_name_list"`
+	Drivers    []string       `json:"driver_name_list"`
+	DriverList []*DriverResV1 `json:"driver_list"`
+}
+
+type DriverResV1 struct {
+	Name         string   `json:"driver_name"`
+	Capabilities []string `json:"capabilities" example:"rollback,tx,explain,schemas,online_ddl"`
 }
 
 // GetDrivers get support Driver list.
@@ -424,9 +431,68 @@ type DriversResV1 struct {
 // @Success 200 {object} v1.GetDriversResV1
 // @router /v1/configurations/drivers [get]
 func GetDrivers(c echo.Context) error {
+	drivers := driver.AllDrivers()
+	allCapabilities := driver.AllCapabilities()
+
+	driverList := make([]*DriverResV1, 0, len(drivers))
+	for _, name := range drivers {
+		driverList = append(driverList, &DriverResV1{
+			Name:         name,
+			Capabilities: allCapabilities[name].Strings(),
+		})
+	}
+
 	return c.JSON(http.StatusOK, &GetDriversResV1{
 		BaseRes: controller.NewBaseReq(nil),
-		Data:    DriversResV1{Drivers: driver.AllDrivers()},
+		Data: DriversResV1{
+			Drivers:    drivers,
+			DriverList: driverList,
+		},
+	})
+}
+
+type GetPluginsResV1 struct {
+	controller.BaseRes
+	Data []*PluginResV1 `json:"data"`
+}
+
+type PluginResV1 struct {
+	Name         string    `json:"plugin_name"`
+	Path         string    `json:"plugin_path"`
+	Version      string    `json:"version"`
+	PID          int       `json:"pid"`
+	Status       string    `json:"status" enums:"running,restarting"`
+	LastError    string    `json:"last_error"`
+	RestartCount int       `json:"restart_count"`
+	StartedAt    time.Time `json:"started_at"`
+}
+
+// GetPlugins get status of driver plugins.
+// @Summary 获取插件运行状态
+// @Description get status of driver plugins
+// @Id getPluginsV1
+// @Tags configuration
+// @Security ApiKeyAuth
+// @Success 200 {object} v1.GetPluginsResV1
+// @router /v1/configurations/plugins [get]
+func GetPlugins(c echo.Context) error {
+	statuses := driver.AllPluginStatuses()
+	data := make([]*PluginResV1, 0, len(statuses))
+	for _, status := range statuses {
+		data = append(data, &PluginResV1{
+			Name:         status.Name,
+			Path:         status.Path,
+			Version:      status.Version,
+			PID:          status.PID,
+			Status:       status.Status,
+			LastError:    status.LastError,
+			RestartCount: status.RestartCount,
+			StartedAt:    status.StartedAt,
+		})
+	}
+	return c.JSON(http.StatusOK, &GetPluginsResV1{
+		BaseRes: controller.NewBaseReq(nil),
+		Data:    data,
 	})
 }
 
@@ -0,0 +1,823 @@
+package v1
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/actiontech/sqle/sqle/api/controller"
+	"github.com/actiontech/sqle/sqle/config"
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/errors"
+	"github.com/actiontech/sqle/sqle/log"
+	"github.com/actiontech/sqle/sqle/model"
+	"github.com/actiontech/sqle/sqle/pkg/bundle"
+	"github.com/actiontech/sqle/sqle/pkg/params"
+	"github.com/actiontech/sqle/sqle/pkg/ruletemplate"
+	"github.com/actiontech/sqle/sqle/server/auditplan"
+	"github.com/actiontech/sqle/sqle/utils"
+
+	"github.com/labstack/echo/v4"
+	"github.com/ungerik/go-dry"
+)
+
+type ExportConfigurationBundleReqV1 struct {
+	Format string `json:"format" query:"format" enums:"yaml,json" valid:"omitempty,oneof=yaml json"`
+}
+
+// @Summary 导出配置包
+// @Description export the rule templates, workflow templates, instances, roles, user groups and audit plans
+// @Description as a declarative configuration bundle, the passwords of instances are not exported.
+// @Id exportConfigurationBundleV1
+// @Tags configuration
+// @Security ApiKeyAuth
+// @Param format query string false "file format, default is yaml" Enums(yaml, json)
+// @Success 200 file 1 "configuration bundle file"
+// @router /v1/configurations/bundle [get]
+func ExportConfigurationBundle(c echo.Context) error {
+	req := new(ExportConfigurationBundleReqV1)
+	if err := controller.BindAndValidateReq(c, req); err != nil {
+		return err
+	}
+	format := req.Format
+	if format == "" {
+		format = bundle.FormatYAML
+	}
+
+	b, err := loadConfigurationBundle(model.GetStorage())
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	b.SQLEVersion = config.Version
+	for _, inst := range b.Instances {
+		inst.Password = ""
+	}
+	data, err := bundle.Marshal(b, format)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.WriteDataToTheFileError, err))
+	}
+	contentType := echo.MIMEApplicationJSON
+	if format == bundle.FormatYAML {
+		contentType = "application/x-yaml"
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		mime.FormatMediaType("attachment", map[string]string{
+			"filename": fmt.Sprintf("sqle_configuration_bundle.%v", format)}))
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
+const InputConfigurationBundleFileFormKey = "bundle_file"
+
+type ApplyConfigurationBundleReqV1 struct {
+	DryRun bool `json:"dry_run" form:"dry_run"`
+	Prune  bool `json:"prune" form:"prune"`
+}
+
+type ApplyConfigurationBundleResV1 struct {
+	controller.BaseRes
+	Data *ApplyConfigurationBundleResDataV1 `json:"data"`
+}
+
+type ApplyConfigurationBundleResDataV1 struct {
+	Applied bool                              `json:"applied"`
+	Changes []*ConfigurationBundleChangeResV1 `json:"changes"`
+}
+
+type ConfigurationBundleChangeResV1 struct {
+	Kind   string   `json:"kind" enums:"rule_template,workflow_template,instance,role,user_group,audit_plan"`
+	Name   string   `json:"name"`
+	Action string   `json:"action" enums:"create,update,delete"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+var errConfigurationBundleDryRun = fmt.Errorf("dry run")
+
+// @Summary 应用配置包
+// @Description apply the declarative configuration bundle, the differences between the bundle and SQLE are planned
+// @Description and applied in a transaction. The resources which are not in bundle are deleted if prune is true,
+// @Description but only the kinds of resource present in bundle are pruned, and the default templates are never pruned.
+// @Description If dry_run is true, the plan is validated and returned, but not applied.
+// @Id applyConfigurationBundleV1
+// @Tags configuration
+// @Security ApiKeyAuth
+// @Accept mpfd
+// @Produce json
+// @Param bundle_file formData file true "configuration bundle file in YAML or JSON"
+// @Param dry_run formData boolean false "only plan the changes"
+// @Param prune formData boolean false "delete the resources which are not in bundle"
+// @Success 200 {object} v1.ApplyConfigurationBundleResV1
+// @router /v1/configurations/bundle/apply [post]
+func ApplyConfigurationBundle(c echo.Context) error {
+	req := new(ApplyConfigurationBundleReqV1)
+	if err := controller.BindAndValidateReq(c, req); err != nil {
+		return err
+	}
+	_, content, exist, err := controller.ReadFile(c, InputConfigurationBundleFileFormKey)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataInvalid,
+			fmt.Errorf("configuration bundle file is required")))
+	}
+	desired, err := bundle.Unmarshal([]byte(content))
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataInvalid, err))
+	}
+	if err := completeConfigurationBundle(model.GetStorage(), desired); err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	user, err := controller.GetCurrentUser(c)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	s := model.GetStorage()
+	var changes []*bundle.Change
+	// the changes are also applied in dry run to validate them, and then
+	// rolled back.
+	err = s.TxStorage(func(txStorage *model.Storage) error {
+		current, err := loadConfigurationBundle(txStorage)
+		if err != nil {
+			return err
+		}
+		changes = excludeDefaultTemplateDeletion(txStorage, current, bundle.Diff(desired, current, req.Prune))
+		for _, change := range changes {
+			if err := applyConfigurationBundleChange(txStorage, desired, change, user); err != nil {
+				return err
+			}
+		}
+		if req.DryRun {
+			return errConfigurationBundleDryRun
+		}
+		return nil
+	})
+	if err != nil && err != errConfigurationBundleDryRun {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	if !req.DryRun {
+		manager := auditplan.GetManager()
+		for _, change := range changes {
+			if change.Kind != bundle.KindAuditPlan {
+				continue
+			}
+			if err := manager.SyncTask(change.Name); err != nil {
+				log.NewEntry().Errorf("sync audit plan %s failed, error: %v", change.Name, err)
+			}
+		}
+	}
+
+	data := &ApplyConfigurationBundleResDataV1{
+		Applied: !req.DryRun,
+		Changes: make([]*ConfigurationBundleChangeResV1, 0, len(changes)),
+	}
+	for _, change := range changes {
+		data.Changes = append(data.Changes, &ConfigurationBundleChangeResV1{
+			Kind:   change.Kind,
+			Name:   change.Name,
+			Action: change.Action,
+			Fields: change.Fields,
+		})
+	}
+	return c.JSON(http.StatusOK, &ApplyConfigurationBundleResV1{
+		BaseRes: controller.NewBaseReq(nil),
+		Data:    data,
+	})
+}
+
+// loadConfigurationBundle loads the resources of bundle from storage.
+func loadConfigurationBundle(s *model.Storage) (*bundle.Bundle, error) {
+	b := &bundle.Bundle{FormatVersion: bundle.FormatVersion}
+
+	ruleTemplates, err := s.GetRuleTemplatesDetail()
+	if err != nil {
+		return nil, err
+	}
+	b.RuleTemplates = make([]*bundle.RuleTemplate, 0, len(ruleTemplates))
+	for _, t := range ruleTemplates {
+		b.RuleTemplates = append(b.RuleTemplates, &bundle.RuleTemplate{
+			Name:   t.Name,
+			Desc:   t.Desc,
+			DBType: t.DBType,
+			Rules:  convertRuleTemplateToFile(t).Rules,
+		})
+	}
+
+	workflowTemplates, err := s.GetWorkflowTemplatesDetail()
+	if err != nil {
+		return nil, err
+	}
+	b.WorkflowTemplates = make([]*bundle.WorkflowTemplate, 0, len(workflowTemplates))
+	for _, t := range workflowTemplates {
+		template := &bundle.WorkflowTemplate{
+			Name:                          t.Name,
+			Desc:                          t.Desc,
+			AllowSubmitWhenLessAuditLevel: t.AllowSubmitWhenLessAuditLevel,
+			ExecFailureStrategy:           t.ExecFailureStrategy,
+			Steps:                         make([]*bundle.WorkflowStep, 0, len(t.Steps)),
+		}
+		for _, step := range t.Steps {
+			bundleStep := &bundle.WorkflowStep{
+				Type:                 step.Typ,
+				Desc:                 step.Desc,
+				ApprovedByAuthorized: step.ApprovedByAuthorized.Bool,
+			}
+			for _, user := range step.Users {
+				bundleStep.AssigneeUserNames = append(bundleStep.AssigneeUserNames, user.Name)
+			}
+			template.Steps = append(template.Steps, bundleStep)
+		}
+		b.WorkflowTemplates = append(b.WorkflowTemplates, template)
+	}
+
+	instances, err := s.GetInstancesDetail()
+	if err != nil {
+		return nil, err
+	}
+	b.Instances = make([]*bundle.Instance, 0, len(instances))
+	for _, inst := range instances {
+		instance := &bundle.Instance{
+			Name:     inst.Name,
+			DBType:   inst.DbType,
+			Host:     inst.Host,
+			Port:     inst.Port,
+			User:     inst.User,
+			Password: inst.Password,
+			Desc:     inst.Desc,
+		}
+		if inst.WorkflowTemplate != nil {
+			instance.WorkflowTemplateName = inst.WorkflowTemplate.Name
+		}
+		if len(inst.RuleTemplates) > 0 {
+			instance.RuleTemplateName = inst.RuleTemplates[0].Name
+		}
+		for _, p := range inst.MaintenancePeriod {
+			instance.MaintenancePeriods = append(instance.MaintenancePeriods, bundle.MaintenancePeriod{
+				StartHour:   p.StartHour,
+				StartMinute: p.StartMinute,
+				EndHour:     p.EndHour,
+				EndMinute:   p.EndMinute,
+			})
+		}
+		instance.AdditionalParams = convertParamsToBundleParams(inst.AdditionalParams)
+		b.Instances = append(b.Instances, instance)
+	}
+
+	roles, err := s.GetRolesDetail()
+	if err != nil {
+		return nil, err
+	}
+	b.Roles = make([]*bundle.Role, 0, len(roles))
+	for _, r := range roles {
+		role := &bundle.Role{
+			Name: r.Name,
+			Desc: r.Desc,
+		}
+		for _, inst := range r.Instances {
+			role.InstanceNames = append(role.InstanceNames, inst.Name)
+		}
+		for _, user := range r.Users {
+			role.UserNames = append(role.UserNames, user.Name)
+		}
+		ops, err := s.GetRoleOperationsByRoleID(r.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, op := range ops {
+			role.OperationCodes = append(role.OperationCodes, op.Code)
+		}
+		b.Roles = append(b.Roles, role)
+	}
+
+	userGroups, err := s.GetUserGroupsDetail()
+	if err != nil {
+		return nil, err
+	}
+	b.UserGroups = make([]*bundle.UserGroup, 0, len(userGroups))
+	for _, ug := range userGroups {
+		userGroup := &bundle.UserGroup{
+			Name: ug.Name,
+			Desc: ug.Desc,
+		}
+		for _, user := range ug.Users {
+			userGroup.UserNames = append(userGroup.UserNames, user.Name)
+		}
+		for _, role := range ug.Roles {
+			userGroup.RoleNames = append(userGroup.RoleNames, role.Name)
+		}
+		b.UserGroups = append(b.UserGroups, userGroup)
+	}
+
+	auditPlans, err := s.GetAuditPlans()
+	if err != nil {
+		return nil, err
+	}
+	b.AuditPlans = make([]*bundle.AuditPlan, 0, len(auditPlans))
+	for _, ap := range auditPlans {
+		b.AuditPlans = append(b.AuditPlans, &bundle.AuditPlan{
+			Name:                ap.Name,
+			Type:                ap.Type,
+			DBType:              ap.DBType,
+			InstanceName:        ap.InstanceName,
+			InstanceDatabase:    ap.InstanceDatabase,
+			Cron:                ap.CronExpression,
+			Params:              convertParamsToBundleParams(ap.Params),
+			NotifyInterval:      ap.NotifyInterval,
+			NotifyLevel:         ap.NotifyLevel,
+			EnableEmailNotify:   ap.EnableEmailNotify,
+			EnableWebHookNotify: ap.EnableWebHookNotify,
+			WebHookURL:          ap.WebHookURL,
+			WebHookTemplate:     ap.WebHookTemplate,
+		})
+	}
+
+	b.Normalize()
+	return b, nil
+}
+
+func convertParamsToBundleParams(ps params.Params) []bundle.Param {
+	bundleParams := make([]bundle.Param, 0, len(ps))
+	for _, p := range ps {
+		bundleParams = append(bundleParams, bundle.Param{Key: p.Key, Value: p.Value})
+	}
+	return bundleParams
+}
+
+// completeConfigurationBundle validates the rules, additional params of
+// instance and params of audit plan by drivers, and fills the params which
+// are absent with default value, so the bundle can be compared with the
+// bundle loaded from storage.
+func completeConfigurationBundle(s *model.Storage, b *bundle.Bundle) error {
+	for _, t := range b.RuleTemplates {
+		rules, err := getBundleRuleTemplateRules(s, t)
+		if err != nil {
+			return err
+		}
+		t.Rules = make([]ruletemplate.Rule, 0, len(rules))
+		for _, rule := range rules {
+			t.Rules = append(t.Rules, ruletemplate.Rule{
+				Name:   rule.Name,
+				Level:  string(rule.Level),
+				Params: convertParamsToRuleTemplateParams(rule.Params),
+			})
+		}
+	}
+	for _, inst := range b.Instances {
+		ps, err := getBundleInstanceAdditionalParams(inst)
+		if err != nil {
+			return err
+		}
+		inst.AdditionalParams = convertParamsToBundleParams(ps)
+	}
+	for _, ap := range b.AuditPlans {
+		ps, err := getBundleAuditPlanParams(ap)
+		if err != nil {
+			return err
+		}
+		ap.Params = convertParamsToBundleParams(ps)
+	}
+	b.Normalize()
+	return nil
+}
+
+func convertParamsToRuleTemplateParams(ps params.Params) []ruletemplate.Param {
+	if len(ps) == 0 {
+		return nil
+	}
+	rtParams := make([]ruletemplate.Param, 0, len(ps))
+	for _, p := range ps {
+		rtParams = append(rtParams, ruletemplate.Param{Key: p.Key, Value: p.Value})
+	}
+	return rtParams
+}
+
+func getBundleRuleTemplateRules(s *model.Storage, t *bundle.RuleTemplate) ([]*driver.Rule, error) {
+	driverRules, ok, err := getRulesWithCustomRules(s, t.DBType)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New(errors.DriverNotExist, &driver.DriverNotSupportedError{DriverTyp: t.DBType})
+	}
+	f := &ruletemplate.File{Rules: t.Rules}
+	rules, unknown, err := f.DriverRules(driverRules)
+	if err != nil {
+		return nil, errors.New(errors.DataInvalid, fmt.Errorf("rule template %s: %v", t.Name, err))
+	}
+	if len(unknown) > 0 {
+		return nil, errors.New(errors.DataInvalid, fmt.Errorf("rules %s of rule template %s are not supported",
+			strings.Join(unknown, ", "), t.Name))
+	}
+	return rules, nil
+}
+
+func getBundleInstanceAdditionalParams(inst *bundle.Instance) (params.Params, error) {
+	if !dry.StringInSlice(inst.DBType, driver.AllDrivers()) {
+		return nil, errors.New(errors.DriverNotExist, &driver.DriverNotSupportedError{DriverTyp: inst.DBType})
+	}
+	additionalParams := driver.AllAdditionalParams()[inst.DBType]
+	for _, p := range inst.AdditionalParams {
+		if err := additionalParams.SetParamValue(p.Key, p.Value); err != nil {
+			return nil, errors.New(errors.DataInvalid, fmt.Errorf("instance %s: %v", inst.Name, err))
+		}
+	}
+	return additionalParams, nil
+}
+
+func getBundleAuditPlanParams(ap *bundle.AuditPlan) (params.Params, error) {
+	if !dry.StringInSlice(ap.DBType, driver.AllDrivers()) {
+		return nil, errors.New(errors.DriverNotExist, &driver.DriverNotSupportedError{DriverTyp: ap.DBType})
+	}
+	paramsReq := make([]AuditPlanParamReqV1, 0, len(ap.Params))
+	for _, p := range ap.Params {
+		paramsReq = append(paramsReq, AuditPlanParamReqV1{Key: p.Key, Value: p.Value})
+	}
+	ps, err := checkAndGenerateAuditPlanParams(ap.Type, ap.DBType, paramsReq)
+	if err != nil {
+		return nil, errors.New(errors.DataInvalid, fmt.Errorf("audit plan %s: %v", ap.Name, err))
+	}
+	return ps, nil
+}
+
+// excludeDefaultTemplateDeletion excludes the deletion of default rule
+// templates and workflow template, which are created by SQLE.
+func excludeDefaultTemplateDeletion(s *model.Storage, current *bundle.Bundle, changes []*bundle.Change) []*bundle.Change {
+	defaultRuleTemplates := map[string]struct{}{}
+	for _, t := range current.RuleTemplates {
+		defaultRuleTemplates[s.GetDefaultRuleTemplateName(t.DBType)] = struct{}{}
+	}
+	result := make([]*bundle.Change, 0, len(changes))
+	for _, change := range changes {
+		if change.Action == bundle.ActionDelete {
+			if _, ok := defaultRuleTemplates[change.Name]; ok && change.Kind == bundle.KindRuleTemplate {
+				continue
+			}
+			if change.Kind == bundle.KindWorkflowTemplate && change.Name == model.DefaultWorkflowTemplate {
+				continue
+			}
+		}
+		result = append(result, change)
+	}
+	return result
+}
+
+func applyConfigurationBundleChange(s *model.Storage, b *bundle.Bundle, change *bundle.Change, user *model.User) error {
+	if change.Action == bundle.ActionDelete {
+		return deleteConfigurationBundleResource(s, change.Kind, change.Name)
+	}
+	switch change.Kind {
+	case bundle.KindRuleTemplate:
+		for _, t := range b.RuleTemplates {
+			if t.Name == change.Name {
+				return applyBundleRuleTemplate(s, t)
+			}
+		}
+	case bundle.KindWorkflowTemplate:
+		for _, t := range b.WorkflowTemplates {
+			if t.Name == change.Name {
+				return applyBundleWorkflowTemplate(s, t)
+			}
+		}
+	case bundle.KindInstance:
+		for _, inst := range b.Instances {
+			if inst.Name == change.Name {
+				return applyBundleInstance(s, inst)
+			}
+		}
+	case bundle.KindRole:
+		for _, r := range b.Roles {
+			if r.Name == change.Name {
+				return applyBundleRole(s, r)
+			}
+		}
+	case bundle.KindUserGroup:
+		for _, ug := range b.UserGroups {
+			if ug.Name == change.Name {
+				return applyBundleUserGroup(s, ug)
+			}
+		}
+	case bundle.KindAuditPlan:
+		for _, ap := range b.AuditPlans {
+			if ap.Name == change.Name {
+				return applyBundleAuditPlan(s, ap, user)
+			}
+		}
+	}
+	return fmt.Errorf("%s %s is not found in bundle", change.Kind, change.Name)
+}
+
+func applyBundleRuleTemplate(s *model.Storage, t *bundle.RuleTemplate) error {
+	rules, err := getBundleRuleTemplateRules(s, t)
+	if err != nil {
+		return err
+	}
+	template, exist, err := s.GetRuleTemplateByName(t.Name)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		template = &model.RuleTemplate{
+			Name:   t.Name,
+			DBType: t.DBType,
+		}
+	} else if template.DBType != t.DBType {
+		return errors.New(errors.DataConflict,
+			fmt.Errorf("db type of rule template %s can not be changed", t.Name))
+	}
+	template.Desc = t.Desc
+	return saveRuleTemplate(s, template, rules)
+}
+
+func applyBundleWorkflowTemplate(s *model.Storage, t *bundle.WorkflowTemplate) error {
+	reqSteps := make([]*WorkFlowStepTemplateReqV1, 0, len(t.Steps))
+	for _, step := range t.Steps {
+		reqSteps = append(reqSteps, &WorkFlowStepTemplateReqV1{
+			Type:                 step.Type,
+			Desc:                 step.Desc,
+			ApprovedByAuthorized: step.ApprovedByAuthorized,
+			Users:                step.AssigneeUserNames,
+		})
+	}
+	steps, err := checkAndGenerateWorkflowStepTemplates(s, reqSteps)
+	if err != nil {
+		return err
+	}
+
+	template, exist, err := s.GetWorkflowTemplateByName(t.Name)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return s.SaveWorkflowTemplate(&model.WorkflowTemplate{
+			Name:                          t.Name,
+			Desc:                          t.Desc,
+			AllowSubmitWhenLessAuditLevel: t.AllowSubmitWhenLessAuditLevel,
+			ExecFailureStrategy:           t.ExecFailureStrategy,
+			Steps:                         steps,
+		})
+	}
+	err = s.UpdateWorkflowTemplateSteps(template.ID, steps)
+	if err != nil {
+		return err
+	}
+	template.Desc = t.Desc
+	template.AllowSubmitWhenLessAuditLevel = t.AllowSubmitWhenLessAuditLevel
+	template.ExecFailureStrategy = t.ExecFailureStrategy
+	return s.Save(template)
+}
+
+func applyBundleInstance(s *model.Storage, inst *bundle.Instance) error {
+	instance, exist, err := s.GetInstanceByName(inst.Name)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		if inst.Password == "" {
+			return errors.New(errors.DataInvalid, fmt.Errorf("password of instance %s is required", inst.Name))
+		}
+		instance = &model.Instance{
+			Name:   inst.Name,
+			DbType: inst.DBType,
+		}
+	} else if instance.DbType != inst.DBType {
+		return errors.New(errors.DataConflict,
+			fmt.Errorf("db type of instance %s can not be changed", inst.Name))
+	}
+	instance.Host = inst.Host
+	instance.Port = inst.Port
+	instance.User = inst.User
+	instance.Desc = inst.Desc
+	if inst.Password != "" && inst.Password != instance.Password {
+		// the password is encrypted when the instance is saved.
+		instance.Password = inst.Password
+		instance.SecretPassword = ""
+	}
+
+	instance.MaintenancePeriod = make(model.Periods, 0, len(inst.MaintenancePeriods))
+	for _, p := range inst.MaintenancePeriods {
+		instance.MaintenancePeriod = append(instance.MaintenancePeriod, &model.Period{
+			StartHour:   p.StartHour,
+			StartMinute: p.StartMinute,
+			EndHour:     p.EndHour,
+			EndMinute:   p.EndMinute,
+		})
+	}
+	if !instance.MaintenancePeriod.SelfCheck() {
+		return errWrongTimePeriod
+	}
+
+	instance.AdditionalParams, err = getBundleInstanceAdditionalParams(inst)
+	if err != nil {
+		return err
+	}
+
+	instance.WorkflowTemplateId = 0
+	if inst.WorkflowTemplateName != "" {
+		workflowTemplate, exist, err := s.GetWorkflowTemplateByName(inst.WorkflowTemplateName)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return errors.New(errors.DataNotExist,
+				fmt.Errorf("workflow template %s is not exist", inst.WorkflowTemplateName))
+		}
+		instance.WorkflowTemplateId = workflowTemplate.ID
+	}
+
+	var ruleTemplates []*model.RuleTemplate
+	if inst.RuleTemplateName != "" {
+		ruleTemplates, err = s.GetAndCheckRuleTemplateExist([]string{inst.RuleTemplateName})
+		if err != nil {
+			return err
+		}
+	}
+	err = CheckInstanceAndRuleTemplateDbType(ruleTemplates, instance)
+	if err != nil {
+		return err
+	}
+
+	err = s.Save(instance)
+	if err != nil {
+		return err
+	}
+	return s.UpdateInstanceRuleTemplates(instance, ruleTemplates...)
+}
+
+func applyBundleRole(s *model.Storage, r *bundle.Role) error {
+	instances := []*model.Instance{}
+	if len(r.InstanceNames) > 0 {
+		var err error
+		instances, err = s.GetAndCheckInstanceExist(r.InstanceNames)
+		if err != nil {
+			return err
+		}
+	}
+	users := []*model.User{}
+	if len(r.UserNames) > 0 {
+		var err error
+		users, err = s.GetAndCheckUserExist(r.UserNames)
+		if err != nil {
+			return err
+		}
+	}
+	opCodes := []uint{}
+	if len(r.OperationCodes) > 0 {
+		if err := model.CheckIfOperationCodeValid(r.OperationCodes); err != nil {
+			return err
+		}
+		opCodes = r.OperationCodes
+	}
+
+	role, exist, err := s.GetRoleByName(r.Name)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		role = &model.Role{Name: r.Name}
+	}
+	role.Desc = r.Desc
+	// the user groups of role are bound by user groups.
+	return s.SaveRoleAndAssociations(role, instances, opCodes, users, nil)
+}
+
+func applyBundleUserGroup(s *model.Storage, ug *bundle.UserGroup) error {
+	users := []*model.User{}
+	if len(ug.UserNames) > 0 {
+		var err error
+		users, err = s.GetAndCheckUserExist(ug.UserNames)
+		if err != nil {
+			return err
+		}
+	}
+	roles := []*model.Role{}
+	if len(ug.RoleNames) > 0 {
+		var err error
+		roles, err = s.GetAndCheckRoleExist(ug.RoleNames)
+		if err != nil {
+			return err
+		}
+	}
+
+	userGroup, exist, err := s.GetUserGroupByName(ug.Name)
+	if err != nil {
+		return errors.ConnectStorageErrWrapper(err)
+	}
+	if !exist {
+		userGroup = &model.UserGroup{Name: ug.Name}
+	}
+	userGroup.Desc = ug.Desc
+	return errors.ConnectStorageErrWrapper(s.SaveUserGroupAndAssociations(userGroup, users, roles))
+}
+
+func applyBundleAuditPlan(s *model.Storage, ap *bundle.AuditPlan, user *model.User) error {
+	if ap.InstanceName != "" {
+		inst, exist, err := s.GetInstanceByName(ap.InstanceName)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return errors.New(errors.DataNotExist,
+				fmt.Errorf("instance %s of audit plan %s is not exist", ap.InstanceName, ap.Name))
+		}
+		if inst.DbType != ap.DBType {
+			return errors.New(errors.DataConflict,
+				fmt.Errorf("db type of audit plan %s is different from instance %s", ap.Name, ap.InstanceName))
+		}
+	}
+	ps, err := getBundleAuditPlanParams(ap)
+	if err != nil {
+		return err
+	}
+
+	auditPlan, exist, err := s.GetAuditPlanByName(ap.Name)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		j := utils.NewJWT(utils.JWTSecretKey)
+		t, err := j.CreateToken(user.Name, time.Now().Add(tokenExpire).Unix(),
+			utils.WithAuditPlanName(ap.Name))
+		if err != nil {
+			return errors.New(errors.DataConflict, err)
+		}
+		auditPlan = &model.AuditPlan{
+			Name:         ap.Name,
+			Token:        t,
+			CreateUserID: user.ID,
+		}
+	}
+	auditPlan.Type = ap.Type
+	auditPlan.DBType = ap.DBType
+	auditPlan.InstanceName = ap.InstanceName
+	auditPlan.InstanceDatabase = ap.InstanceDatabase
+	auditPlan.CronExpression = ap.Cron
+	auditPlan.Params = ps
+	auditPlan.NotifyInterval = ap.NotifyInterval
+	auditPlan.NotifyLevel = ap.NotifyLevel
+	auditPlan.EnableEmailNotify = ap.EnableEmailNotify
+	auditPlan.EnableWebHookNotify = ap.EnableWebHookNotify
+	auditPlan.WebHookURL = ap.WebHookURL
+	auditPlan.WebHookTemplate = ap.WebHookTemplate
+	return s.Save(auditPlan)
+}
+
+func deleteConfigurationBundleResource(s *model.Storage, kind, name string) error {
+	switch kind {
+	case bundle.KindRuleTemplate:
+		template, exist, err := s.GetRuleTemplateByName(name)
+		if err != nil || !exist {
+			return err
+		}
+		return s.Delete(template)
+	case bundle.KindWorkflowTemplate:
+		template, exist, err := s.GetWorkflowTemplateByName(name)
+		if err != nil || !exist {
+			return err
+		}
+		return s.Delete(template)
+	case bundle.KindInstance:
+		instance, exist, err := s.GetInstanceByName(name)
+		if err != nil || !exist {
+			return err
+		}
+		tasks, err := s.GetTaskByInstanceId(instance.ID)
+		if err != nil {
+			return err
+		}
+		taskIds := make([]uint, 0, len(tasks))
+		for _, task := range tasks {
+			taskIds = append(taskIds, task.ID)
+		}
+		isRunning, err := s.TaskWorkflowIsRunning(taskIds)
+		if err != nil {
+			return err
+		}
+		if isRunning {
+			return errors.New(errors.DataExist,
+				fmt.Errorf("%s can't be deleted,cause on_process workflow exist", name))
+		}
+		return s.Delete(instance)
+	case bundle.KindRole:
+		role, exist, err := s.GetRoleByName(name)
+		if err != nil || !exist {
+			return err
+		}
+		return s.DeleteRoleAndAssociations(role)
+	case bundle.KindUserGroup:
+		userGroup, exist, err := s.GetUserGroupByName(name)
+		if err != nil || !exist {
+			return errors.ConnectStorageErrWrapper(err)
+		}
+		return s.Delete(userGroup)
+	case bundle.KindAuditPlan:
+		auditPlan, exist, err := s.GetAuditPlanByName(name)
+		if err != nil || !exist {
+			return err
+		}
+		return s.Delete(auditPlan)
+	}
+	return fmt.Errorf("unknown kind %s of bundle", kind)
+}
@@ -0,0 +1,311 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/api/controller"
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/errors"
+	"github.com/actiontech/sqle/sqle/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CustomRuleMatcherV1 struct {
+	MatchType          string   `json:"match_type" valid:"required,oneof=sql fingerprint ast expr" enums:"sql,fingerprint,ast,expr"`
+	Pattern            string   `json:"pattern" example:"(?i)sql_no_cache"`
+	StmtTypes          []string `json:"stmt_types" enums:"select,insert,update,delete,create_table,alter_table,drop_table,create_index,drop_index,truncate"`
+	TablePattern       string   `json:"table_pattern" example:"^order"`
+	WhereWithoutColumn string   `json:"where_without_column" example:"tenant_id"`
+	ColumnPattern      string   `json:"column_pattern" example:"^is_"`
+	Expr               string   `json:"expr" example:"ast.type == 'update' && ast.where == null"`
+	MessageTemplate    string   `json:"message_template" example:"表 {{.Table}} 的 WHERE 条件必须包含 {{.Column}}"`
+}
+
+func convertCustomRuleMatcherToDriver(m *CustomRuleMatcherV1) *driver.CustomRule {
+	return &driver.CustomRule{
+		MatchType:          m.MatchType,
+		Pattern:            m.Pattern,
+		StmtTypes:          m.StmtTypes,
+		TablePattern:       m.TablePattern,
+		WhereWithoutColumn: m.WhereWithoutColumn,
+		ColumnPattern:      m.ColumnPattern,
+		Expr:               m.Expr,
+		MessageTemplate:    m.MessageTemplate,
+	}
+}
+
+func convertCustomRuleToMatcherRes(r *driver.CustomRule) CustomRuleMatcherV1 {
+	return CustomRuleMatcherV1{
+		MatchType:          r.MatchType,
+		Pattern:            r.Pattern,
+		StmtTypes:          r.StmtTypes,
+		TablePattern:       r.TablePattern,
+		WhereWithoutColumn: r.WhereWithoutColumn,
+		ColumnPattern:      r.ColumnPattern,
+		Expr:               r.Expr,
+		MessageTemplate:    r.MessageTemplate,
+	}
+}
+
+// validateCustomRule checks the custom rule and whether the driver of db type
+// can evaluate it, the expr rule is evaluated by the plugins with expr_rule.
+func validateCustomRule(dbType string, custom *driver.CustomRule) error {
+	if !driver.HasCapability(dbType, custom.Capability()) {
+		return errors.New(errors.DataInvalid,
+			fmt.Errorf("custom rule of match type %s is not supported by db type %s", custom.MatchType, dbType))
+	}
+	if err := custom.Validate(); err != nil {
+		return errors.New(errors.DataInvalid, err)
+	}
+	return nil
+}
+
+type CreateCustomRuleReqV1 struct {
+	Name    string              `json:"rule_name" valid:"required,name" example:"custom_update_without_tenant"`
+	DBType  string              `json:"db_type" valid:"required" example:"mysql"`
+	Desc    string              `json:"desc" valid:"required"`
+	Level   string              `json:"level" valid:"required,oneof=normal notice warn error" enums:"normal,notice,warn,error"`
+	Typ     string              `json:"type" valid:"required" example:"自定义规范"`
+	Matcher CustomRuleMatcherV1 `json:"matcher"`
+}
+
+// @Summary 添加自定义规则
+// @Description create a rule defined by user, the rule name must start with "custom_". The rule is matched by
+// @Description the regular expression over SQL text or fingerprint, or by the predicate over AST, the AST predicate
+// @Description is matched if all of the non-empty conditions are satisfied. The rule of match type "expr" is matched
+// @Description if the expression over the JSON view of statement is true, it is supported by the plugins built with
+// @Description the plugin adaptor. The rule can be added to rule templates like the rules of driver, and it is
+// @Description evaluated by the driver which supports custom rules.
+// @Id createCustomRuleV1
+// @Tags rule_template
+// @Security ApiKeyAuth
+// @Accept json
+// @Param instance body v1.CreateCustomRuleReqV1 true "create custom rule request"
+// @Success 200 {object} controller.BaseRes
+// @router /v1/custom_rules [post]
+func CreateCustomRule(c echo.Context) error {
+	req := new(CreateCustomRuleReqV1)
+	if err := controller.BindAndValidateReq(c, req); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(req.Name, driver.CustomRuleNamePrefix) {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataInvalid,
+			fmt.Errorf("name of custom rule must start with \"%s\"", driver.CustomRuleNamePrefix)))
+	}
+	custom := convertCustomRuleMatcherToDriver(&req.Matcher)
+	if err := validateCustomRule(req.DBType, custom); err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	s := model.GetStorage()
+	_, exist, err := s.GetRule(req.Name, req.DBType)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		_, exist, err = s.GetCustomRuleByName(req.Name)
+		if err != nil {
+			return controller.JSONBaseErrorReq(c, err)
+		}
+	}
+	if exist {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataExist, fmt.Errorf("rule is exist")))
+	}
+
+	err = s.Save(&model.Rule{
+		Name:   req.Name,
+		DBType: req.DBType,
+		Desc:   req.Desc,
+		Level:  req.Level,
+		Typ:    req.Typ,
+		Custom: custom,
+	})
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	return c.JSON(http.StatusOK, controller.NewBaseReq(nil))
+}
+
+type UpdateCustomRuleReqV1 struct {
+	Desc    *string              `json:"desc"`
+	Level   *string              `json:"level" valid:"omitempty,oneof=normal notice warn error" enums:"normal,notice,warn,error"`
+	Typ     *string              `json:"type"`
+	Matcher *CustomRuleMatcherV1 `json:"matcher"`
+}
+
+// @Summary 更新自定义规则
+// @Description update the rule defined by user, the matcher is replaced if it is present.
+// @Description The level of rule in rule templates is not changed.
+// @Id updateCustomRuleV1
+// @Tags rule_template
+// @Security ApiKeyAuth
+// @Accept json
+// @Param rule_name path string true "custom rule name"
+// @Param instance body v1.UpdateCustomRuleReqV1 true "update custom rule request"
+// @Success 200 {object} controller.BaseRes
+// @router /v1/custom_rules/{rule_name}/ [patch]
+func UpdateCustomRule(c echo.Context) error {
+	req := new(UpdateCustomRuleReqV1)
+	if err := controller.BindAndValidateReq(c, req); err != nil {
+		return err
+	}
+	s := model.GetStorage()
+	rule, exist, err := s.GetCustomRuleByName(c.Param("rule_name"))
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataNotExist, fmt.Errorf("custom rule is not exist")))
+	}
+
+	if req.Desc != nil {
+		rule.Desc = *req.Desc
+	}
+	if req.Level != nil {
+		rule.Level = *req.Level
+	}
+	if req.Typ != nil {
+		rule.Typ = *req.Typ
+	}
+	if req.Matcher != nil {
+		custom := convertCustomRuleMatcherToDriver(req.Matcher)
+		if err := validateCustomRule(rule.DBType, custom); err != nil {
+			return controller.JSONBaseErrorReq(c, err)
+		}
+		rule.Custom = custom
+	}
+	err = s.Save(rule)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	return c.JSON(http.StatusOK, controller.NewBaseReq(nil))
+}
+
+// @Summary 删除自定义规则
+// @Description delete the rule defined by user, it is also removed from rule templates.
+// @Id deleteCustomRuleV1
+// @Tags rule_template
+// @Security ApiKeyAuth
+// @Param rule_name path string true "custom rule name"
+// @Success 200 {object} controller.BaseRes
+// @router /v1/custom_rules/{rule_name}/ [delete]
+func DeleteCustomRule(c echo.Context) error {
+	s := model.GetStorage()
+	rule, exist, err := s.GetCustomRuleByName(c.Param("rule_name"))
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataNotExist, fmt.Errorf("custom rule is not exist")))
+	}
+	err = s.DeleteCustomRule(rule)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	return c.JSON(http.StatusOK, controller.NewBaseReq(nil))
+}
+
+type GetCustomRulesReqV1 struct {
+	FilterDBType string `json:"filter_db_type" query:"filter_db_type"`
+}
+
+type GetCustomRulesResV1 struct {
+	controller.BaseRes
+	Data []*CustomRuleResV1 `json:"data"`
+}
+
+type GetCustomRuleResV1 struct {
+	controller.BaseRes
+	Data *CustomRuleResV1 `json:"data"`
+}
+
+type CustomRuleResV1 struct {
+	Name    string              `json:"rule_name"`
+	DBType  string              `json:"db_type"`
+	Desc    string              `json:"desc"`
+	Level   string              `json:"level" enums:"normal,notice,warn,error"`
+	Typ     string              `json:"type"`
+	Matcher CustomRuleMatcherV1 `json:"matcher"`
+}
+
+func convertCustomRuleToRes(rule *model.Rule) *CustomRuleResV1 {
+	return &CustomRuleResV1{
+		Name:    rule.Name,
+		DBType:  rule.DBType,
+		Desc:    rule.Desc,
+		Level:   rule.Level,
+		Typ:     rule.Typ,
+		Matcher: convertCustomRuleToMatcherRes(rule.Custom),
+	}
+}
+
+// @Summary 自定义规则列表
+// @Description get the rules defined by user
+// @Id getCustomRuleListV1
+// @Tags rule_template
+// @Security ApiKeyAuth
+// @Param filter_db_type query string false "filter db type"
+// @Success 200 {object} v1.GetCustomRulesResV1
+// @router /v1/custom_rules [get]
+func GetCustomRules(c echo.Context) error {
+	req := new(GetCustomRulesReqV1)
+	if err := controller.BindAndValidateReq(c, req); err != nil {
+		return err
+	}
+	rules, err := model.GetStorage().GetCustomRules(req.FilterDBType)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	data := make([]*CustomRuleResV1, 0, len(rules))
+	for _, rule := range rules {
+		data = append(data, convertCustomRuleToRes(rule))
+	}
+	return c.JSON(http.StatusOK, &GetCustomRulesResV1{
+		BaseRes: controller.NewBaseReq(nil),
+		Data:    data,
+	})
+}
+
+// @Summary 获取自定义规则
+// @Description get the rule defined by user
+// @Id getCustomRuleV1
+// @Tags rule_template
+// @Security ApiKeyAuth
+// @Param rule_name path string true "custom rule name"
+// @Success 200 {object} v1.GetCustomRuleResV1
+// @router /v1/custom_rules/{rule_name}/ [get]
+func GetCustomRule(c echo.Context) error {
+	rule, exist, err := model.GetStorage().GetCustomRuleByName(c.Param("rule_name"))
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataNotExist, fmt.Errorf("custom rule is not exist")))
+	}
+	return c.JSON(http.StatusOK, &GetCustomRuleResV1{
+		BaseRes: controller.NewBaseReq(nil),
+		Data:    convertCustomRuleToRes(rule),
+	})
+}
+
+// getRulesWithCustomRules returns the rules of driver and the rules defined
+// by user of the db type.
+func getRulesWithCustomRules(s *model.Storage, dbType string) ([]*driver.Rule, bool, error) {
+	driverRules, ok := driver.AllRules()[dbType]
+	if !ok {
+		return nil, false, nil
+	}
+	customRules, err := s.GetCustomRules(dbType)
+	if err != nil {
+		return nil, false, err
+	}
+	rules := make([]*driver.Rule, 0, len(driverRules)+len(customRules))
+	rules = append(rules, driverRules...)
+	for _, rule := range customRules {
+		rules = append(rules, model.ConvertRuleToDriverRule(rule))
+	}
+	return rules, true, nil
+}
@@ -260,6 +260,7 @@ type InstanceResV1 struct {
 	RuleTemplates        []string                        `json:"rule_template_name_list,omitempty"`
 	Roles                []string                        `json:"role_name_list,omitempty"`
 	AdditionalParams     []*InstanceAdditionalParamResV1 `json:"additional_params"`
+	Capabilities         []string                        `json:"capabilities" example:"rollback,tx,explain,schemas,online_ddl"`
 }
 
 type MaintenanceTimeResV1 struct {
@@ -304,6 +305,7 @@ func convertInstanceToRes(instance *model.Instance) InstanceResV1 {
 		DBType:           instance.DbType,
 		MaintenanceTimes: convertPeriodToMaintenanceTimeResV1(instance.MaintenancePeriod),
 		AdditionalParams: []*InstanceAdditionalParamResV1{},
+		Capabilities:     driver.GetCapabilities(instance.DbType).Strings(),
 	}
 	if instance.WorkflowTemplate != nil {
 		instanceResV1.WorkflowTemplateName = instance.WorkflowTemplate.Name
@@ -790,6 +792,16 @@ func GetInstanceSchemas(c echo.Context) error {
 		return controller.JSONBaseErrorReq(c, err)
 	}
 
+	// the driver can not list schemas, user should input schema by manual.
+	if !driver.HasCapability(instance.DbType, driver.CapabilitySchemas) {
+		return c.JSON(http.StatusOK, &GetInstanceSchemaResV1{
+			BaseRes: controller.NewBaseReq(nil),
+			Data: InstanceSchemaResV1{
+				Schemas: []string{},
+			},
+		})
+	}
+
 	d, err := newDriverWithoutAudit(log.NewEntry(), instance, "")
 	if err != nil {
 		return controller.JSONBaseErrorReq(c, err)
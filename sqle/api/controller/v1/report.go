@@ -0,0 +1,107 @@
+package v1
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/config"
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/errors"
+	"github.com/actiontech/sqle/sqle/model"
+	"github.com/actiontech/sqle/sqle/pkg/report"
+
+	"github.com/labstack/echo/v4"
+)
+
+// newAuditReport returns the report which Version is the version of SQLE.
+func newAuditReport(name string) *report.Report {
+	return &report.Report{
+		Name:    name,
+		Version: config.Version,
+		SQLs:    []*report.SQL{},
+	}
+}
+
+// downloadAuditReport responds the audit report file in SARIF or JUnit XML
+// format, the rules in report are loaded from storage as the rule metadata
+// of SARIF. The fileName has no extension.
+func downloadAuditReport(c echo.Context, r *report.Report, dbType, format, failLevel, fileName string) error {
+	rules, err := getReportRules(r, dbType)
+	if err != nil {
+		return err
+	}
+	r.Rules = rules
+
+	buff := &bytes.Buffer{}
+	var contentType string
+	switch format {
+	case report.FormatSARIF:
+		err = report.WriteSARIF(buff, r)
+		contentType = echo.MIMEApplicationJSON
+		fileName += ".sarif"
+	case report.FormatJUnit:
+		if failLevel == "" {
+			failLevel = string(driver.RuleLevelError)
+		}
+		err = report.WriteJUnit(buff, r, driver.RuleLevel(failLevel))
+		contentType = echo.MIMEApplicationXML
+		fileName += ".xml"
+	default:
+		return errors.New(errors.DataInvalid, fmt.Errorf("unsupported report format: %s", format))
+	}
+	if err != nil {
+		return errors.New(errors.WriteDataToTheFileError, err)
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		mime.FormatMediaType("attachment", map[string]string{"filename": fileName}))
+	return c.Blob(http.StatusOK, contentType, buff.Bytes())
+}
+
+func getReportRules(r *report.Report, dbType string) ([]*driver.Rule, error) {
+	names := []string{}
+	exist := map[string]struct{}{}
+	for _, sql := range r.SQLs {
+		for _, result := range sql.Results {
+			if _, ok := exist[result.RuleName]; ok || result.RuleName == "" {
+				continue
+			}
+			exist[result.RuleName] = struct{}{}
+			names = append(names, result.RuleName)
+		}
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+	rules, err := model.GetStorage().GetRulesByNames(names, dbType)
+	if err != nil {
+		return nil, err
+	}
+	driverRules := make([]*driver.Rule, 0, len(rules))
+	for i := range rules {
+		driverRules = append(driverRules, model.ConvertRuleToDriverRule(&rules[i]))
+	}
+	return driverRules, nil
+}
+
+var auditResultLevelReg = regexp.MustCompile(`^\[(\w+)\](.*)$`)
+
+// parseAuditResult parses the audit result text like "[error]message" line
+// by line, it is used when the structured findings are not stored.
+func parseAuditResult(auditResult string) []*report.Result {
+	results := []*report.Result{}
+	for _, line := range strings.Split(auditResult, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		result := &report.Result{Level: string(driver.RuleLevelNotice), Message: line}
+		if matches := auditResultLevelReg.FindStringSubmatch(line); matches != nil {
+			result.Level, result.Message = matches[1], matches[2]
+		}
+		results = append(results, result)
+	}
+	return results
+}
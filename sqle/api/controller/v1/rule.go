@@ -2,11 +2,15 @@ package v1
 
 import (
 	"fmt"
+	"mime"
 	"net/http"
 
 	"github.com/actiontech/sqle/sqle/api/controller"
+	"github.com/actiontech/sqle/sqle/config"
+	"github.com/actiontech/sqle/sqle/driver"
 	"github.com/actiontech/sqle/sqle/errors"
 	"github.com/actiontech/sqle/sqle/model"
+	"github.com/actiontech/sqle/sqle/pkg/ruletemplate"
 
 	"github.com/labstack/echo/v4"
 )
@@ -388,12 +392,13 @@ type GetRulesResV1 struct {
 }
 
 type RuleResV1 struct {
-	Name   string           `json:"rule_name"`
-	Desc   string           `json:"desc"`
-	Level  string           `json:"level" example:"error" enums:"normal,notice,warn,error"`
-	Typ    string           `json:"type" example:"全局配置" `
-	DBType string           `json:"db_type" example:"mysql"`
-	Params []RuleParamResV1 `json:"params,omitempty"`
+	Name     string           `json:"rule_name"`
+	Desc     string           `json:"desc"`
+	Level    string           `json:"level" example:"error" enums:"normal,notice,warn,error"`
+	Typ      string           `json:"type" example:"全局配置" `
+	DBType   string           `json:"db_type" example:"mysql"`
+	Params   []RuleParamResV1 `json:"params,omitempty"`
+	IsCustom bool             `json:"is_custom"`
 }
 
 type RuleParamResV1 struct {
@@ -405,11 +410,12 @@ type RuleParamResV1 struct {
 
 func convertRuleToRes(rule *model.Rule) RuleResV1 {
 	ruleRes := RuleResV1{
-		Name:   rule.Name,
-		Desc:   rule.Desc,
-		Level:  rule.Level,
-		Typ:    rule.Typ,
-		DBType: rule.DBType,
+		Name:     rule.Name,
+		Desc:     rule.Desc,
+		Level:    rule.Level,
+		Typ:      rule.Typ,
+		DBType:   rule.DBType,
+		IsCustom: rule.Custom != nil,
 	}
 	if rule.Params != nil && len(rule.Params) > 0 {
 		paramsRes := make([]RuleParamResV1, 0, len(rule.Params))
@@ -607,3 +613,241 @@ func CheckRuleTemplateCanBeBindEachInstance(s *model.Storage, tplName string, in
 	}
 	return nil
 }
+
+type ExportRuleTemplateReqV1 struct {
+	Format string `json:"format" query:"format" enums:"yaml,json" valid:"omitempty,oneof=yaml json"`
+}
+
+// @Summary 导出规则模板
+// @Description export rule template as a versioned YAML or JSON file, which contains the rules with level and params,
+// @Description the bound instance names, the db type and the SQLE version. The file can be imported by other SQLE servers.
+// @Id exportRuleTemplateV1
+// @Tags rule_template
+// @Security ApiKeyAuth
+// @Param rule_template_name path string true "rule template name"
+// @Param format query string false "file format, default is yaml" Enums(yaml, json)
+// @Success 200 file 1 "rule template file"
+// @router /v1/rule_templates/{rule_template_name}/export [get]
+func ExportRuleTemplate(c echo.Context) error {
+	req := new(ExportRuleTemplateReqV1)
+	if err := controller.BindAndValidateReq(c, req); err != nil {
+		return err
+	}
+	format := req.Format
+	if format == "" {
+		format = ruletemplate.FormatYAML
+	}
+
+	s := model.GetStorage()
+	templateName := c.Param("rule_template_name")
+	template, exist, err := s.GetRuleTemplateDetailByName(templateName)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataNotExist,
+			fmt.Errorf("rule template is not exist")))
+	}
+
+	data, err := ruletemplate.Marshal(convertRuleTemplateToFile(template), format)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.WriteDataToTheFileError, err))
+	}
+	contentType := echo.MIMEApplicationJSON
+	if format == ruletemplate.FormatYAML {
+		contentType = "application/x-yaml"
+	}
+	c.Response().Header().Set(echo.HeaderContentDisposition,
+		mime.FormatMediaType("attachment", map[string]string{
+			"filename": fmt.Sprintf("rule_template_%v.%v", template.Name, format)}))
+	return c.Blob(http.StatusOK, contentType, data)
+}
+
+func convertRuleTemplateToFile(template *model.RuleTemplate) *ruletemplate.File {
+	f := &ruletemplate.File{
+		FormatVersion: ruletemplate.FormatVersion,
+		SQLEVersion:   config.Version,
+		Name:          template.Name,
+		Desc:          template.Desc,
+		DBType:        template.DBType,
+		Rules:         make([]ruletemplate.Rule, 0, len(template.RuleList)),
+	}
+	for _, instance := range template.Instances {
+		f.InstanceNames = append(f.InstanceNames, instance.Name)
+	}
+	for _, r := range template.RuleList {
+		rule := ruletemplate.Rule{
+			Name:  r.RuleName,
+			Level: r.RuleLevel,
+		}
+		for _, p := range r.RuleParams {
+			rule.Params = append(rule.Params, ruletemplate.Param{Key: p.Key, Value: p.Value})
+		}
+		f.Rules = append(f.Rules, rule)
+	}
+	return f
+}
+
+const InputRuleTemplateFileFormKey = "rule_template_file"
+
+type ImportRuleTemplateReqV1 struct {
+	Overwrite bool `json:"overwrite" form:"overwrite"`
+}
+
+type ImportRuleTemplateResV1 struct {
+	controller.BaseRes
+	Data *ImportRuleTemplateResDataV1 `json:"data"`
+}
+
+type ImportRuleTemplateResDataV1 struct {
+	Name    string `json:"rule_template_name"`
+	Created bool   `json:"created"`
+	// UnknownRuleNames are the rules which are not supported by this SQLE
+	// server, they may be removed or added by a newer version, and they are
+	// skipped when importing.
+	UnknownRuleNames []string `json:"unknown_rule_names"`
+	// NotExistInstanceNames are the bound instances which are not exist in
+	// this SQLE server, they are skipped when importing.
+	NotExistInstanceNames []string `json:"not_exist_instance_names"`
+}
+
+// @Summary 导入规则模板
+// @Description import rule template from the file exported by SQLE, the rules are validated by the rules of driver.
+// @Description The unknown or removed rules and the not exist instances are skipped and reported in response.
+// @Description If the rule template is exist, it is overwritten only when overwrite is true.
+// @Id importRuleTemplateV1
+// @Tags rule_template
+// @Security ApiKeyAuth
+// @Accept mpfd
+// @Produce json
+// @Param rule_template_file formData file true "rule template file in YAML or JSON"
+// @Param overwrite formData boolean false "overwrite the rule template if it is exist"
+// @Success 200 {object} v1.ImportRuleTemplateResV1
+// @router /v1/rule_templates/import [post]
+func ImportRuleTemplate(c echo.Context) error {
+	req := new(ImportRuleTemplateReqV1)
+	if err := controller.BindAndValidateReq(c, req); err != nil {
+		return err
+	}
+	_, content, exist, err := controller.ReadFile(c, InputRuleTemplateFileFormKey)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataInvalid,
+			fmt.Errorf("rule template file is required")))
+	}
+	f, err := ruletemplate.Unmarshal([]byte(content))
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataInvalid, err))
+	}
+
+	data, err := importRuleTemplate(model.GetStorage(), f, req.Overwrite)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	return c.JSON(http.StatusOK, &ImportRuleTemplateResV1{
+		BaseRes: controller.NewBaseReq(nil),
+		Data:    data,
+	})
+}
+
+// importRuleTemplate creates the rule template from file, or overwrites the
+// rules, desc and instances of the exist rule template.
+func importRuleTemplate(s *model.Storage, f *ruletemplate.File, overwrite bool) (*ImportRuleTemplateResDataV1, error) {
+	if f.Name == "" {
+		return nil, errors.New(errors.DataInvalid, fmt.Errorf("name of rule template is empty"))
+	}
+	driverRules, ok, err := getRulesWithCustomRules(s, f.DBType)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.New(errors.DataInvalid, fmt.Errorf("db type %s is not supported", f.DBType))
+	}
+	rules, unknownRuleNames, err := f.DriverRules(driverRules)
+	if err != nil {
+		return nil, errors.New(errors.DataInvalid, err)
+	}
+
+	var instances []*model.Instance
+	notExistInstanceNames := []string{}
+	if len(f.InstanceNames) > 0 {
+		instances, err = s.GetInstancesByNames(f.InstanceNames)
+		if err != nil {
+			return nil, err
+		}
+		existInstanceNames := map[string]struct{}{}
+		for _, instance := range instances {
+			existInstanceNames[instance.Name] = struct{}{}
+		}
+		for _, name := range f.InstanceNames {
+			if _, ok := existInstanceNames[name]; !ok {
+				notExistInstanceNames = append(notExistInstanceNames, name)
+			}
+		}
+	}
+
+	template, exist, err := s.GetRuleTemplateByName(f.Name)
+	if err != nil {
+		return nil, err
+	}
+	if exist && !overwrite {
+		return nil, errors.New(errors.DataExist, fmt.Errorf("rule template is exist"))
+	}
+	if exist && template.DBType != f.DBType {
+		return nil, errors.New(errors.DataConflict,
+			fmt.Errorf("db type of rule template is %s, but it is %s in file", template.DBType, f.DBType))
+	}
+	if !exist {
+		template = &model.RuleTemplate{
+			Name:   f.Name,
+			DBType: f.DBType,
+		}
+	}
+	template.Desc = f.Desc
+
+	err = CheckRuleTemplateCanBeBindEachInstance(s, f.Name, instances)
+	if err != nil {
+		return nil, err
+	}
+	err = CheckInstanceAndRuleTemplateDbType([]*model.RuleTemplate{template}, instances...)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.TxStorage(func(txStorage *model.Storage) error {
+		if err := saveRuleTemplate(txStorage, template, rules); err != nil {
+			return err
+		}
+		return txStorage.UpdateRuleTemplateInstances(template, instances...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if unknownRuleNames == nil {
+		unknownRuleNames = []string{}
+	}
+	return &ImportRuleTemplateResDataV1{
+		Name:                  f.Name,
+		Created:               !exist,
+		UnknownRuleNames:      unknownRuleNames,
+		NotExistInstanceNames: notExistInstanceNames,
+	}, nil
+}
+
+// saveRuleTemplate saves the rule template, and replaces its rules by the
+// rules of driver.
+func saveRuleTemplate(s *model.Storage, template *model.RuleTemplate, rules []*driver.Rule) error {
+	err := s.Save(template)
+	if err != nil {
+		return err
+	}
+	templateRules := make([]model.RuleTemplateRule, 0, len(rules))
+	for _, rule := range rules {
+		templateRules = append(templateRules,
+			model.NewRuleTemplateRule(template, model.GenerateRuleByDriverRule(rule, template.DBType)))
+	}
+	return s.UpdateRuleTemplateRules(template, templateRules...)
+}
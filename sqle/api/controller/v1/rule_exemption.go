@@ -0,0 +1,297 @@
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/actiontech/sqle/sqle/api/controller"
+	"github.com/actiontech/sqle/sqle/errors"
+	"github.com/actiontech/sqle/sqle/model"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CreateRuleExemptionReqV1 struct {
+	RuleNames      []string  `json:"rule_names" valid:"required,min=1" example:"ddl_check_pk_not_exist"`
+	InstanceName   string    `json:"instance_name" example:"inst_1"`
+	SchemaName     string    `json:"schema_name" example:"db1"`
+	TableName      string    `json:"table_name" example:"legacy_orders"`
+	FingerprintSQL string    `json:"fingerprint_sql" example:"select * from legacy_orders where id = 1"`
+	ExpiredAt      time.Time `json:"expired_at" valid:"required" example:"2026-12-31T00:00:00+08:00"`
+	Justification  string    `json:"justification" valid:"required" example:"the legacy table will be dropped"`
+}
+
+// checkRuleExemption checks the rule names, scope, expiry and justification
+// of exemption.
+func checkRuleExemption(s *model.Storage, e *model.RuleExemption) error {
+	if len(e.GetRuleNames()) == 0 {
+		return errors.New(errors.DataInvalid, fmt.Errorf("rule_names is required"))
+	}
+	if e.Justification == "" {
+		return errors.New(errors.DataInvalid, fmt.Errorf("justification is required"))
+	}
+	notExistNames, err := s.GetNotExistRuleNames(e.GetRuleNames())
+	if err != nil {
+		return err
+	}
+	if len(notExistNames) > 0 {
+		return errors.New(errors.DataNotExist, fmt.Errorf("rule %s not exist", strings.Join(notExistNames, ", ")))
+	}
+	if e.InstanceName == "" && e.Schema == "" && e.Table == "" && e.FingerprintSQL == "" {
+		return errors.New(errors.DataInvalid,
+			fmt.Errorf("at least one of instance_name, schema_name, table_name and fingerprint_sql is required"))
+	}
+	if e.InstanceName != "" {
+		_, exist, err := s.GetInstanceByName(e.InstanceName)
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return errors.New(errors.DataNotExist, fmt.Errorf("instance %s not exist", e.InstanceName))
+		}
+	}
+	if !e.ExpiredAt.After(time.Now()) {
+		return errors.New(errors.DataInvalid, fmt.Errorf("expired_at must be in the future"))
+	}
+	return nil
+}
+
+// @Summary 添加规则豁免
+// @Description create a rule exemption, the findings of the rules are exempted on the SQLs in scope until it is expired.
+// @Description The scope is the non-empty ones of instance, schema, table and fingerprint, the SQL is in scope if all
+// @Description of them are matched. The table is matched if the SQL references it, and the fingerprint is matched if
+// @Description the SQL has the same fingerprint with fingerprint_sql. The exempted findings are still shown in audit
+// @Description results and reports as "exempted", but they do not affect the audit level of SQL.
+// @Description The current user is recorded as the approver.
+// @Id createRuleExemptionV1
+// @Tags rule_exemption
+// @Security ApiKeyAuth
+// @Accept json
+// @Param instance body v1.CreateRuleExemptionReqV1 true "create rule exemption request"
+// @Success 200 {object} controller.BaseRes
+// @router /v1/rule_exemptions [post]
+func CreateRuleExemption(c echo.Context) error {
+	req := new(CreateRuleExemptionReqV1)
+	if err := controller.BindAndValidateReq(c, req); err != nil {
+		return err
+	}
+	user, err := controller.GetCurrentUser(c)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	exemption := &model.RuleExemption{
+		InstanceName:   req.InstanceName,
+		Schema:         req.SchemaName,
+		Table:          req.TableName,
+		FingerprintSQL: req.FingerprintSQL,
+		ExpiredAt:      req.ExpiredAt,
+		Justification:  req.Justification,
+		ApproverId:     user.ID,
+	}
+	exemption.SetRuleNames(req.RuleNames)
+
+	s := model.GetStorage()
+	if err := checkRuleExemption(s, exemption); err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if err := s.Save(exemption); err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	return c.JSON(http.StatusOK, controller.NewBaseReq(nil))
+}
+
+type UpdateRuleExemptionReqV1 struct {
+	RuleNames      []string   `json:"rule_names" valid:"omitempty,min=1"`
+	InstanceName   *string    `json:"instance_name"`
+	SchemaName     *string    `json:"schema_name"`
+	TableName      *string    `json:"table_name"`
+	FingerprintSQL *string    `json:"fingerprint_sql"`
+	ExpiredAt      *time.Time `json:"expired_at"`
+	Justification  *string    `json:"justification"`
+}
+
+// @Summary 更新规则豁免
+// @Description update the rule exemption, the current user is recorded as the approver of the updated exemption.
+// @Id updateRuleExemptionV1
+// @Tags rule_exemption
+// @Security ApiKeyAuth
+// @Accept json
+// @Param rule_exemption_id path string true "rule exemption id"
+// @Param instance body v1.UpdateRuleExemptionReqV1 true "update rule exemption request"
+// @Success 200 {object} controller.BaseRes
+// @router /v1/rule_exemptions/{rule_exemption_id}/ [patch]
+func UpdateRuleExemption(c echo.Context) error {
+	req := new(UpdateRuleExemptionReqV1)
+	if err := controller.BindAndValidateReq(c, req); err != nil {
+		return err
+	}
+	user, err := controller.GetCurrentUser(c)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	s := model.GetStorage()
+	exemption, exist, err := s.GetRuleExemptionById(c.Param("rule_exemption_id"))
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataNotExist, fmt.Errorf("rule exemption is not exist")))
+	}
+
+	if req.RuleNames != nil {
+		exemption.SetRuleNames(req.RuleNames)
+	}
+	if req.InstanceName != nil {
+		exemption.InstanceName = *req.InstanceName
+	}
+	if req.SchemaName != nil {
+		exemption.Schema = *req.SchemaName
+	}
+	if req.TableName != nil {
+		exemption.Table = *req.TableName
+	}
+	if req.FingerprintSQL != nil {
+		exemption.FingerprintSQL = *req.FingerprintSQL
+	}
+	if req.ExpiredAt != nil {
+		exemption.ExpiredAt = *req.ExpiredAt
+	}
+	if req.Justification != nil {
+		exemption.Justification = *req.Justification
+	}
+	if err := checkRuleExemption(s, exemption); err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	// the preloaded approver is not saved with exemption.
+	exemption.ApproverId = user.ID
+	exemption.Approver = nil
+
+	if err := s.Save(exemption); err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	return c.JSON(http.StatusOK, controller.NewBaseReq(nil))
+}
+
+// @Summary 删除规则豁免
+// @Description delete the rule exemption, the audited SQLs are not changed.
+// @Id deleteRuleExemptionV1
+// @Tags rule_exemption
+// @Security ApiKeyAuth
+// @Param rule_exemption_id path string true "rule exemption id"
+// @Success 200 {object} controller.BaseRes
+// @router /v1/rule_exemptions/{rule_exemption_id}/ [delete]
+func DeleteRuleExemption(c echo.Context) error {
+	s := model.GetStorage()
+	exemption, exist, err := s.GetRuleExemptionById(c.Param("rule_exemption_id"))
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataNotExist, fmt.Errorf("rule exemption is not exist")))
+	}
+	if err := s.Delete(exemption); err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	return c.JSON(http.StatusOK, controller.NewBaseReq(nil))
+}
+
+type RuleExemptionResV1 struct {
+	Id             uint      `json:"rule_exemption_id"`
+	RuleNames      []string  `json:"rule_names"`
+	InstanceName   string    `json:"instance_name"`
+	SchemaName     string    `json:"schema_name"`
+	TableName      string    `json:"table_name"`
+	FingerprintSQL string    `json:"fingerprint_sql"`
+	ExpiredAt      time.Time `json:"expired_at"`
+	IsExpired      bool      `json:"is_expired"`
+	Justification  string    `json:"justification"`
+	Approver       string    `json:"approver"`
+	ApprovedAt     time.Time `json:"approved_at"`
+}
+
+func convertRuleExemptionToRes(e *model.RuleExemption) *RuleExemptionResV1 {
+	return &RuleExemptionResV1{
+		Id:             e.ID,
+		RuleNames:      e.GetRuleNames(),
+		InstanceName:   e.InstanceName,
+		SchemaName:     e.Schema,
+		TableName:      e.Table,
+		FingerprintSQL: e.FingerprintSQL,
+		ExpiredAt:      e.ExpiredAt,
+		IsExpired:      !e.ExpiredAt.After(time.Now()),
+		Justification:  e.Justification,
+		Approver:       e.ApproverName(),
+		ApprovedAt:     e.UpdatedAt,
+	}
+}
+
+type GetRuleExemptionsReqV1 struct {
+	PageIndex uint32 `json:"page_index" query:"page_index" valid:"required"`
+	PageSize  uint32 `json:"page_size" query:"page_size" valid:"required"`
+}
+
+type GetRuleExemptionsResV1 struct {
+	controller.BaseRes
+	Data      []*RuleExemptionResV1 `json:"data"`
+	TotalNums uint32                `json:"total_nums"`
+}
+
+// @Summary 获取规则豁免列表
+// @Description get the rule exemptions, including the expired ones
+// @Id getRuleExemptionListV1
+// @Tags rule_exemption
+// @Security ApiKeyAuth
+// @Param page_index query uint32 true "page index"
+// @Param page_size query uint32 true "size of per page"
+// @Success 200 {object} v1.GetRuleExemptionsResV1
+// @router /v1/rule_exemptions [get]
+func GetRuleExemptions(c echo.Context) error {
+	req := new(GetRuleExemptionsReqV1)
+	if err := controller.BindAndValidateReq(c, req); err != nil {
+		return err
+	}
+	exemptions, count, err := model.GetStorage().GetRuleExemptions(req.PageIndex, req.PageSize)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	data := make([]*RuleExemptionResV1, 0, len(exemptions))
+	for _, e := range exemptions {
+		data = append(data, convertRuleExemptionToRes(e))
+	}
+	return c.JSON(http.StatusOK, &GetRuleExemptionsResV1{
+		BaseRes:   controller.NewBaseReq(nil),
+		Data:      data,
+		TotalNums: count,
+	})
+}
+
+type GetRuleExemptionResV1 struct {
+	controller.BaseRes
+	Data *RuleExemptionResV1 `json:"data"`
+}
+
+// @Summary 获取规则豁免
+// @Description get the rule exemption
+// @Id getRuleExemptionV1
+// @Tags rule_exemption
+// @Security ApiKeyAuth
+// @Param rule_exemption_id path string true "rule exemption id"
+// @Success 200 {object} v1.GetRuleExemptionResV1
+// @router /v1/rule_exemptions/{rule_exemption_id}/ [get]
+func GetRuleExemption(c echo.Context) error {
+	exemption, exist, err := model.GetStorage().GetRuleExemptionById(c.Param("rule_exemption_id"))
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, errors.New(errors.DataNotExist, fmt.Errorf("rule exemption is not exist")))
+	}
+	return c.JSON(http.StatusOK, &GetRuleExemptionResV1{
+		BaseRes: controller.NewBaseReq(nil),
+		Data:    convertRuleExemptionToRes(exemption),
+	})
+}
@@ -4,19 +4,24 @@ import (
 	"bytes"
 	"context"
 	"encoding/csv"
+	"encoding/json"
 	"fmt"
 	"mime"
 	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/actiontech/sqle/sqle/api/controller"
+	"github.com/actiontech/sqle/sqle/driver"
 	"github.com/actiontech/sqle/sqle/errors"
 	"github.com/actiontech/sqle/sqle/log"
 	"github.com/actiontech/sqle/sqle/model"
+	"github.com/actiontech/sqle/sqle/pkg/mybatis"
+	"github.com/actiontech/sqle/sqle/pkg/report"
 	"github.com/actiontech/sqle/sqle/server"
 
-	mybatis_parser "github.com/actiontech/mybatis-mapper-2-sql"
 	"github.com/labstack/echo/v4"
 )
 
@@ -44,6 +49,14 @@ type AuditTaskResV1 struct {
 	SQLSource      string     `json:"sql_source" enums:"form_data,sql_file,mybatis_xml_file,audit_plan"`
 	ExecStartTime  *time.Time `json:"exec_start_time,omitempty"`
 	ExecEndTime    *time.Time `json:"exec_end_time,omitempty"`
+	DryRunTime     *time.Time `json:"dry_run_time,omitempty"`
+
+	ExecFailureStrategy string `json:"exec_failure_strategy,omitempty" enums:"stop,continue,rollback_executed"`
+	ExecFailureResult   string `json:"exec_failure_result,omitempty"`
+
+	// Capabilities are the capabilities of task driver, the actions which
+	// need the missing capabilities are not supported by task, such as rollback.
+	Capabilities []string `json:"capabilities" example:"rollback,tx,dry_run"`
 }
 
 func convertTaskToRes(task *model.Task) *AuditTaskResV1 {
@@ -58,6 +71,12 @@ func convertTaskToRes(task *model.Task) *AuditTaskResV1 {
 		SQLSource:      task.SQLSource,
 		ExecStartTime:  task.ExecStartAt,
 		ExecEndTime:    task.ExecEndAt,
+		DryRunTime:     task.DryRunAt,
+
+		ExecFailureStrategy: task.ExecFailureStrategy,
+		ExecFailureResult:   task.ExecFailureResult,
+
+		Capabilities: driver.GetCapabilities(task.DBType).Strings(),
 	}
 }
 
@@ -66,29 +85,93 @@ const (
 	InputMyBatisXMLFileName = "input_mybatis_xml_file"
 )
 
-func getSQLFromFile(c echo.Context) (string, string, error) {
+// sqlFile is the uploaded file which SQLs are read from, it locates the
+// parsed SQLs in the file.
+type sqlFile struct {
+	name string
+	// content is the SQLs read from file.
+	content string
+	// lineOf returns the line in file of the offset in content, the offset
+	// passed to it is increasing.
+	lineOf func(offset int) int
+	cursor int
+}
+
+func newSQLFile(name, content string) *sqlFile {
+	line, last := 1, 0
+	return &sqlFile{
+		name:    name,
+		content: content,
+		lineOf: func(offset int) int {
+			line += strings.Count(content[last:offset], "\n")
+			last = offset
+			return line
+		},
+	}
+}
+
+// newMyBatisXMLFile joins the queries in MyBatis XML as mybatis_parser.ParseXML,
+// the line of SQLs is the line of the query element which they are parsed from.
+func newMyBatisXMLFile(name string, queries []mybatis.Query) *sqlFile {
+	buff := strings.Builder{}
+	offsets := make([]int, 0, len(queries))
+	for _, query := range queries {
+		offsets = append(offsets, buff.Len())
+		buff.WriteString(query.SQL)
+		if !strings.HasSuffix(strings.TrimSpace(query.SQL), ";") {
+			buff.WriteString(";")
+		}
+		buff.WriteString("\n")
+	}
+	return &sqlFile{
+		name:    name,
+		content: strings.TrimSuffix(buff.String(), "\n"),
+		lineOf: func(offset int) int {
+			i := sort.Search(len(offsets), func(i int) bool { return offsets[i] > offset }) - 1
+			if i < 0 {
+				return 0
+			}
+			return queries[i].Line
+		},
+	}
+}
+
+// locate returns the line of SQL in file, the SQLs should be located in the
+// order of they are parsed. It returns 0 if the SQL is not found.
+func (f *sqlFile) locate(sql string) int {
+	sql = strings.TrimSpace(sql)
+	idx := strings.Index(f.content[f.cursor:], sql)
+	if sql == "" || idx < 0 {
+		return 0
+	}
+	start := f.cursor + idx
+	f.cursor = start + len(sql)
+	return f.lineOf(start)
+}
+
+func getSQLFromFile(c echo.Context) (*sqlFile, string, error) {
 	// Read it from sql file.
-	sql, exist, err := controller.ReadFileContent(c, InputSQLFileName)
+	name, sql, exist, err := controller.ReadFile(c, InputSQLFileName)
 	if err != nil {
-		return "", model.TaskSQLSourceFromSQLFile, err
+		return nil, model.TaskSQLSourceFromSQLFile, err
 	}
 	if exist {
-		return sql, model.TaskSQLSourceFromSQLFile, nil
+		return newSQLFile(name, sql), model.TaskSQLSourceFromSQLFile, nil
 	}
 
 	// If sql_file is not exist, read it from mybatis xml file.
-	data, exist, err := controller.ReadFileContent(c, InputMyBatisXMLFileName)
+	name, data, exist, err := controller.ReadFile(c, InputMyBatisXMLFileName)
 	if err != nil {
-		return "", model.TaskSQLSourceFromMyBatisXMLFile, err
+		return nil, model.TaskSQLSourceFromMyBatisXMLFile, err
 	}
 	if exist {
-		sql, err := mybatis_parser.ParseXML(data)
+		queries, err := mybatis.ParseXMLQuery(data, false)
 		if err != nil {
-			return "", model.TaskSQLSourceFromMyBatisXMLFile, errors.New(errors.ParseMyBatisXMLFileError, err)
+			return nil, model.TaskSQLSourceFromMyBatisXMLFile, errors.New(errors.ParseMyBatisXMLFileError, err)
 		}
-		return sql, model.TaskSQLSourceFromMyBatisXMLFile, nil
+		return newMyBatisXMLFile(name, queries), model.TaskSQLSourceFromMyBatisXMLFile, nil
 	}
-	return "", "", errors.New(errors.DataInvalid, fmt.Errorf("input sql is empty"))
+	return nil, "", errors.New(errors.DataInvalid, fmt.Errorf("input sql is empty"))
 }
 
 // @Summary 创建Sql审核任务并提交审核
@@ -115,15 +198,17 @@ func CreateAndAuditTask(c echo.Context) error {
 	}
 	var sql string
 	var source string
+	var file *sqlFile
 	var err error
 
 	if req.Sql != "" {
 		sql, source = req.Sql, model.TaskSQLSourceFromFormData
 	} else {
-		sql, source, err = getSQLFromFile(c)
+		file, source, err = getSQLFromFile(c)
 		if err != nil {
 			return controller.JSONBaseErrorReq(c, err)
 		}
+		sql = file.content
 	}
 	s := model.GetStorage()
 	instance, exist, err := s.GetInstanceByName(req.InstanceName)
@@ -169,12 +254,17 @@ func CreateAndAuditTask(c echo.Context) error {
 		return controller.JSONBaseErrorReq(c, err)
 	}
 	for n, node := range nodes {
-		task.ExecuteSQLs = append(task.ExecuteSQLs, &model.ExecuteSQL{
+		executeSQL := &model.ExecuteSQL{
 			BaseSQL: model.BaseSQL{
 				Number:  uint(n + 1),
 				Content: node.Text,
 			},
-		})
+		}
+		if file != nil {
+			executeSQL.SourceFile = file.name
+			executeSQL.SourceLine = file.locate(node.Text)
+		}
+		task.ExecuteSQLs = append(task.ExecuteSQLs, executeSQL)
 	}
 	// if task instance is not nil, gorm will update instance when save task.
 	task.Instance = nil
@@ -232,6 +322,48 @@ func checkCurrentUserCanAccessTask(c echo.Context, task *model.Task, ops []uint)
 	return ErrTaskNoAccess
 }
 
+// checkCurrentUserCanExecuteTask checks the user is allowed to run the SQLs of
+// task on instance. It is the same as executing the workflow of task, the user
+// should be the assignee of sql_execute step or have the execute operation on
+// the instance of task.
+func checkCurrentUserCanExecuteTask(c echo.Context, task *model.Task) error {
+	if controller.GetUserName(c) == model.DefaultAdminUser {
+		return nil
+	}
+	user, err := controller.GetCurrentUser(c)
+	if err != nil {
+		return err
+	}
+	s := model.GetStorage()
+	workflow, exist, err := s.GetWorkflowByTaskId(task.ID)
+	if err != nil {
+		return err
+	}
+	if exist {
+		workflow, exist, err = s.GetWorkflowDetailById(strconv.Itoa(int(workflow.ID)))
+		if err != nil {
+			return err
+		}
+		if exist && len(workflow.Record.Steps) > 0 {
+			for _, assignee := range workflow.ExecuteStep().Assignees {
+				if assignee.ID == user.ID {
+					return nil
+				}
+			}
+		}
+	}
+	if task.Instance != nil {
+		ok, err := s.CheckUserHasOpToInstance(user, task.Instance, []uint{model.OP_WORKFLOW_EXECUTE})
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+	}
+	return ErrTaskNoAccess
+}
+
 // @Summary 获取Sql审核任务信息
 // @Description get task
 // @Tags task
@@ -286,6 +418,26 @@ type AuditTaskSQLResV1 struct {
 	ExecStatus  string `json:"exec_status"`
 	RollbackSQL string `json:"rollback_sql,omitempty"`
 	Description string `json:"description"`
+	SourceFile  string `json:"source_file,omitempty"`
+	SourceLine  int    `json:"source_line,omitempty"`
+
+	AuditResults []*AuditResultResV1 `json:"audit_results"`
+
+	DryRunStatus     string `json:"dry_run_status" enums:"initialized,succeeded,failed,skipped"`
+	DryRunResult     string `json:"dry_run_result"`
+	DryRunRowAffects int64  `json:"dry_run_row_affects"`
+}
+
+type AuditResultResV1 struct {
+	Level          string `json:"level" example:"warn"`
+	Message        string `json:"message"`
+	RuleName       string `json:"rule_name"`
+	Category       string `json:"category"`
+	PositionOffset int    `json:"position_offset"`
+	PositionLine   int    `json:"position_line"`
+	Suggestion     string `json:"suggestion"`
+	Exempted       bool   `json:"exempted"`
+	ExemptionId    uint   `json:"exemption_id"`
 }
 
 // @Summary 获取指定审核任务的SQLs信息
@@ -340,6 +492,25 @@ func GetTaskSQLs(c echo.Context) error {
 		return controller.JSONBaseErrorReq(c, err)
 	}
 
+	auditResults, err := s.GetExecuteSQLAuditResultsByTaskId(taskId)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	auditResultsRes := map[uint][]*AuditResultResV1{}
+	for _, result := range auditResults {
+		auditResultsRes[result.ExecuteSQLId] = append(auditResultsRes[result.ExecuteSQLId], &AuditResultResV1{
+			Level:          result.Level,
+			Message:        result.Message,
+			RuleName:       result.RuleName,
+			Category:       result.Category,
+			PositionOffset: result.PositionOffset,
+			PositionLine:   result.PositionLine,
+			Suggestion:     result.Suggestion,
+			Exempted:       result.ExemptionId != 0,
+			ExemptionId:    result.ExemptionId,
+		})
+	}
+
 	taskSQLsRes := make([]*AuditTaskSQLResV1, 0, len(taskSQLs))
 	for _, taskSQL := range taskSQLs {
 		taskSQLRes := &AuditTaskSQLResV1{
@@ -352,6 +523,14 @@ func GetTaskSQLs(c echo.Context) error {
 			ExecResult:  taskSQL.ExecResult,
 			ExecStatus:  taskSQL.ExecStatus,
 			RollbackSQL: taskSQL.RollbackSQL.String,
+			SourceFile:  taskSQL.SourceFile,
+			SourceLine:  taskSQL.SourceLine,
+
+			AuditResults: auditResultsRes[taskSQL.Id],
+
+			DryRunStatus:     taskSQL.DryRunStatus,
+			DryRunResult:     taskSQL.DryRunResult.String,
+			DryRunRowAffects: taskSQL.DryRunRowAffects,
 		}
 		taskSQLsRes = append(taskSQLsRes, taskSQLRes)
 	}
@@ -363,18 +542,192 @@ func GetTaskSQLs(c echo.Context) error {
 	})
 }
 
+// @Summary 试运行审核任务的SQL
+// @Description dry run the SQLs of task, DMLs are executed in transaction which is rolled back and DDLs are executed by gh-ost in noop mode.
+// @Description The DMLs on the tables of non-transactional engine, such as MyISAM, are skipped.
+// @Tags task
+// @Id dryRunAuditTaskV1
+// @Security ApiKeyAuth
+// @Param task_id path string true "task id"
+// @Success 200 {object} v1.GetAuditTaskResV1
+// @router /v1/tasks/audits/{task_id}/dry_run [post]
+func DryRunTask(c echo.Context) error {
+	s := model.GetStorage()
+	taskId := c.Param("task_id")
+	task, exist, err := s.GetTaskById(taskId)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, ErrTaskNoAccess)
+	}
+	// dry run executes the SQLs on instance, it requires the same permission
+	// as execution.
+	err = checkCurrentUserCanExecuteTask(c, task)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	task, err = server.GetSqled().AddTaskWaitResult(taskId, server.ActionTypeDryRun)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	return c.JSON(http.StatusOK, &GetAuditTaskResV1{
+		BaseRes: controller.NewBaseReq(nil),
+		Data:    convertTaskToRes(task),
+	})
+}
+
+// @Summary 取消执行中的审核任务
+// @Description cancel the running execution, dry run or rollback of task, the running SQL is killed and the remaining SQLs are skipped
+// @Tags task
+// @Id cancelAuditTaskV1
+// @Security ApiKeyAuth
+// @Param task_id path string true "task id"
+// @Success 200 {object} controller.BaseRes
+// @router /v1/tasks/audits/{task_id}/cancel [post]
+func CancelTask(c echo.Context) error {
+	s := model.GetStorage()
+	taskId := c.Param("task_id")
+	task, exist, err := s.GetTaskById(taskId)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, ErrTaskNoAccess)
+	}
+	// cancel stops the running execution, it requires the same permission as
+	// starting the execution.
+	err = checkCurrentUserCanExecuteTask(c, task)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	return controller.JSONBaseErrorReq(c, server.GetSqled().CancelTask(taskId))
+}
+
+type TaskProgressResV1 struct {
+	// TaskStatus is only set in the "done" event.
+	TaskStatus        string `json:"task_status,omitempty"`
+	Running           bool   `json:"running"`
+	Canceled          bool   `json:"canceled"`
+	TotalSQLs         int    `json:"total_sql_count"`
+	FinishedSQLs      int    `json:"finished_sql_count"`
+	RowAffects        int64  `json:"row_affects"`
+	ElapsedSeconds    int64  `json:"elapsed_seconds"`
+	CurrentSQLNumbers []uint `json:"current_sql_numbers"`
+	CurrentSQL        string `json:"current_sql"`
+	CurrentSQLSeconds int64  `json:"current_sql_elapsed_seconds"`
+	// Rollback is the progress of rolling back the executed SQLs after the
+	// execution failed.
+	Rollback *TaskProgressResV1 `json:"rollback,omitempty"`
+}
+
+const taskProgressInterval = time.Second
+
+// @Summary 获取审核任务执行进度
+// @Description stream the progress of the running task by server-sent events, a "progress" event is sent every second and a "done" event is sent when the task is not running
+// @Tags task
+// @Id getAuditTaskProgressV1
+// @Security ApiKeyAuth
+// @Param task_id path string true "task id"
+// @Produce text/event-stream
+// @Success 200 {object} v1.TaskProgressResV1
+// @router /v1/tasks/audits/{task_id}/progress [get]
+func GetTaskProgress(c echo.Context) error {
+	s := model.GetStorage()
+	taskId := c.Param("task_id")
+	task, exist, err := s.GetTaskById(taskId)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, ErrTaskNoAccess)
+	}
+	err = checkCurrentUserCanViewTask(c, task)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	resp := c.Response()
+	resp.Header().Set(echo.HeaderContentType, "text/event-stream")
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.Header().Set("Connection", "keep-alive")
+	resp.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(taskProgressInterval)
+	defer ticker.Stop()
+	for {
+		progress, running := server.GetSqled().GetTaskProgress(taskId)
+		if !running {
+			task, _, err := s.GetTaskById(taskId)
+			if err != nil {
+				return err
+			}
+			return writeTaskProgressEvent(resp, "done", &TaskProgressResV1{TaskStatus: task.Status})
+		}
+		if err := writeTaskProgressEvent(resp, "progress", convertTaskProgressToRes(progress)); err != nil {
+			return err
+		}
+
+		select {
+		case <-c.Request().Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func convertTaskProgressToRes(progress *server.TaskProgress) *TaskProgressResV1 {
+	res := &TaskProgressResV1{
+		Running:           true,
+		Canceled:          progress.Canceled,
+		TotalSQLs:         progress.TotalSQLs,
+		FinishedSQLs:      progress.FinishedSQLs,
+		RowAffects:        progress.RowAffects,
+		CurrentSQLNumbers: progress.CurrentSQLNumbers,
+		CurrentSQL:        progress.CurrentSQL,
+	}
+	if !progress.StartAt.IsZero() {
+		res.ElapsedSeconds = int64(time.Since(progress.StartAt).Seconds())
+	}
+	if progress.CurrentSQL != "" {
+		res.CurrentSQLSeconds = int64(time.Since(progress.CurrentSQLStartAt).Seconds())
+	}
+	if progress.Rollback != nil {
+		res.Rollback = convertTaskProgressToRes(progress.Rollback)
+	}
+	return res
+}
+
+func writeTaskProgressEvent(resp *echo.Response, event string, res *TaskProgressResV1) error {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(resp, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	resp.Flush()
+	return nil
+}
+
 type DownloadAuditTaskSQLsFileReqV1 struct {
-	NoDuplicate bool `json:"no_duplicate" query:"no_duplicate"`
+	NoDuplicate bool   `json:"no_duplicate" query:"no_duplicate"`
+	Format      string `json:"format" query:"format" enums:"csv,sarif,junit" valid:"omitempty,oneof=csv sarif junit"`
+	FailLevel   string `json:"fail_level" query:"fail_level" enums:"notice,warn,error" valid:"omitempty,oneof=notice warn error"`
 }
 
 // @Summary 下载指定审核任务的SQLs信息报告
-// @Description download report file of all SQLs information belong to the specified audit task
+// @Description download report file of all SQLs information belong to the specified audit task,
+// @Description the format can be csv (default), SARIF 2.1.0 or JUnit XML, which can be consumed by code scanning of GitHub or GitLab.
 // @Tags task
 // @Id downloadAuditTaskSQLReportV1
 // @Security ApiKeyAuth
 // @Param task_id path string true "task id"
 // @Param no_duplicate query boolean false "select unique (fingerprint and audit result) for task sql"
-// @Success 200 file 1 "sql report csv file"
+// @Param format query string false "report format" Enums(csv, sarif, junit)
+// @Param fail_level query string false "the SQLs whose audit level is higher or equal than it are failed test cases in JUnit XML, default is error" Enums(notice, warn, error)
+// @Success 200 file 1 "sql report file"
 // @router /v1/tasks/audits/{task_id}/sql_report [get]
 func DownloadTaskSQLReportFile(c echo.Context) error {
 	req := new(DownloadAuditTaskSQLsFileReqV1)
@@ -404,6 +757,19 @@ func DownloadTaskSQLReportFile(c echo.Context) error {
 	if err != nil {
 		return controller.JSONBaseErrorReq(c, err)
 	}
+	if req.Format == report.FormatSARIF || req.Format == report.FormatJUnit {
+		r, err := getTaskAuditReport(task, taskSQLsDetail)
+		if err != nil {
+			return controller.JSONBaseErrorReq(c, err)
+		}
+		fileName := fmt.Sprintf("SQL审核报告_%v_%v", task.InstanceName(), taskId)
+		err = downloadAuditReport(c, r, task.DBType, req.Format, req.FailLevel, fileName)
+		if err != nil {
+			return controller.JSONBaseErrorReq(c, err)
+		}
+		return nil
+	}
+
 	buff := &bytes.Buffer{}
 	buff.WriteString("\xEF\xBB\xBF") // 写入UTF-8 BOM
 	cw := csv.NewWriter(buff)
@@ -438,6 +804,42 @@ func DownloadTaskSQLReportFile(c echo.Context) error {
 	return c.Blob(http.StatusOK, "text/csv", buff.Bytes())
 }
 
+func getTaskAuditReport(task *model.Task, taskSQLs []*model.TaskSQLDetail) (*report.Report, error) {
+	taskId := fmt.Sprintf("%d", task.ID)
+	auditResults, err := model.GetStorage().GetExecuteSQLAuditResultsByTaskId(taskId)
+	if err != nil {
+		return nil, err
+	}
+	results := map[uint][]*report.Result{}
+	for _, result := range auditResults {
+		results[result.ExecuteSQLId] = append(results[result.ExecuteSQLId], &report.Result{
+			Level:      result.Level,
+			Message:    result.Message,
+			RuleName:   result.RuleName,
+			Category:   result.Category,
+			Suggestion: result.Suggestion,
+			Line:       result.PositionLine,
+			Exempted:   result.ExemptionId != 0,
+		})
+	}
+
+	r := newAuditReport(fmt.Sprintf("task_%v", taskId))
+	for _, taskSQL := range taskSQLs {
+		sqlResults, ok := results[taskSQL.Id]
+		if !ok {
+			sqlResults = parseAuditResult(taskSQL.AuditResult)
+		}
+		r.SQLs = append(r.SQLs, &report.SQL{
+			Number:   taskSQL.Number,
+			Content:  taskSQL.ExecSQL,
+			FilePath: taskSQL.SourceFile,
+			Line:     taskSQL.SourceLine,
+			Results:  sqlResults,
+		})
+	}
+	return r, nil
+}
+
 // @Summary 下载指定审核任务的SQL文件
 // @Description download SQL file for the audit task
 // @Tags task
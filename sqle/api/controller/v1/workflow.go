@@ -11,6 +11,7 @@ import (
 
 	"github.com/actiontech/sqle/sqle/api/controller"
 	"github.com/actiontech/sqle/sqle/errors"
+	"github.com/actiontech/sqle/sqle/log"
 	"github.com/actiontech/sqle/sqle/model"
 	"github.com/actiontech/sqle/sqle/notification"
 	"github.com/actiontech/sqle/sqle/server"
@@ -34,6 +35,7 @@ type WorkflowTemplateDetailResV1 struct {
 	Name                          string                       `json:"workflow_template_name"`
 	Desc                          string                       `json:"desc,omitempty"`
 	AllowSubmitWhenLessAuditLevel string                       `json:"allow_submit_when_less_audit_level" enums:"normal,notice,warn,error"`
+	ExecFailureStrategy           string                       `json:"exec_failure_strategy" enums:"stop,continue,rollback_executed"`
 	Steps                         []*WorkFlowStepTemplateResV1 `json:"workflow_step_template_list"`
 	Instances                     []string                     `json:"instance_name_list,omitempty"`
 }
@@ -86,6 +88,7 @@ func getWorkflowTemplateDetailByTemplate(template *model.WorkflowTemplate) (*Wor
 		Name:                          template.Name,
 		Desc:                          template.Desc,
 		AllowSubmitWhenLessAuditLevel: template.AllowSubmitWhenLessAuditLevel,
+		ExecFailureStrategy:           template.ExecFailureStrategy,
 	}
 	stepsRes := make([]*WorkFlowStepTemplateResV1, 0, len(steps))
 	for _, step := range steps {
@@ -118,12 +121,13 @@ type CreateWorkflowTemplateReqV1 struct {
 	Name                          string                       `json:"workflow_template_name" form:"workflow_template_name" valid:"required,name"`
 	Desc                          string                       `json:"desc" form:"desc"`
 	AllowSubmitWhenLessAuditLevel string                       `json:"allow_submit_when_less_audit_level" enums:"normal,notice,warn,error"`
+	ExecFailureStrategy           string                       `json:"exec_failure_strategy" valid:"omitempty,oneof=stop continue rollback_executed" enums:"stop,continue,rollback_executed"`
 	Steps                         []*WorkFlowStepTemplateReqV1 `json:"workflow_step_template_list" form:"workflow_step_template_list" valid:"required,dive,required"`
 	Instances                     []string                     `json:"instance_name_list" form:"instance_name_list"`
 }
 
 type WorkFlowStepTemplateReqV1 struct {
-	Type                 string   `json:"type" form:"type" valid:"oneof=sql_review sql_execute" enums:"sql_review,sql_execute"`
+	Type                 string   `json:"type" form:"type" valid:"oneof=sql_review sql_execute sql_rollback" enums:"sql_review,sql_execute,sql_rollback"`
 	Desc                 string   `json:"desc" form:"desc"`
 	ApprovedByAuthorized bool     `json:"approved_by_authorized"`
 	Users                []string `json:"assignee_user_name_list" form:"assignee_user_name_list"`
@@ -137,14 +141,30 @@ func validWorkflowTemplateReq(steps []*WorkFlowStepTemplateReqV1) error {
 		return fmt.Errorf("workflow steps length must be less than 6")
 	}
 
+	// the sql_rollback step is optional, it just be used after sql_execute step.
+	executeStepIndex := len(steps) - 1
+	if steps[executeStepIndex].Type == model.WorkflowStepTypeSQLRollback {
+		executeStepIndex--
+	}
+	if executeStepIndex < 0 {
+		return fmt.Errorf("workflow step type sql_rollback just be used after sql_execute step")
+	}
+
 	for i, step := range steps {
 		isLastStep := i == len(steps)-1
-		if isLastStep && step.Type != model.WorkflowStepTypeSQLExecute {
+		isExecuteStep := i == executeStepIndex
+		if isExecuteStep && step.Type != model.WorkflowStepTypeSQLExecute {
+			if !isLastStep {
+				return fmt.Errorf("workflow step type sql_rollback just be used after sql_execute step")
+			}
 			return fmt.Errorf("the last workflow step type must be sql_execute")
 		}
-		if !isLastStep && step.Type == model.WorkflowStepTypeSQLExecute {
+		if !isExecuteStep && step.Type == model.WorkflowStepTypeSQLExecute {
 			return fmt.Errorf("workflow step type sql_execute just be used in last step")
 		}
+		if !isLastStep && step.Type == model.WorkflowStepTypeSQLRollback {
+			return fmt.Errorf("workflow step type sql_rollback just be used in last step")
+		}
 		if len(step.Users) == 0 && !step.ApprovedByAuthorized {
 			return fmt.Errorf("the assignee is empty for step %s", step.Desc)
 		}
@@ -155,6 +175,49 @@ func validWorkflowTemplateReq(steps []*WorkFlowStepTemplateReqV1) error {
 	return nil
 }
 
+// checkAndGenerateWorkflowStepTemplates validates the steps, and generates the
+// step templates with the assignees.
+func checkAndGenerateWorkflowStepTemplates(s *model.Storage, reqSteps []*WorkFlowStepTemplateReqV1) (
+	[]*model.WorkflowStepTemplate, error) {
+	err := validWorkflowTemplateReq(reqSteps)
+	if err != nil {
+		return nil, errors.New(errors.DataInvalid, err)
+	}
+	userNames := []string{}
+	for _, step := range reqSteps {
+		userNames = append(userNames, step.Users...)
+	}
+
+	users, err := s.GetAndCheckUserExist(userNames)
+	if err != nil {
+		return nil, err
+	}
+	userMap := map[string]*model.User{}
+	for _, user := range users {
+		userMap[user.Name] = user
+	}
+
+	steps := make([]*model.WorkflowStepTemplate, 0, len(reqSteps))
+	for i, step := range reqSteps {
+		s := &model.WorkflowStepTemplate{
+			Number: uint(i + 1),
+			ApprovedByAuthorized: sql.NullBool{
+				Bool:  step.ApprovedByAuthorized,
+				Valid: true,
+			},
+			Typ:  step.Type,
+			Desc: step.Desc,
+		}
+		stepUsers := make([]*model.User, 0, len(step.Users))
+		for _, userName := range step.Users {
+			stepUsers = append(stepUsers, userMap[userName])
+		}
+		s.Users = stepUsers
+		steps = append(steps, s)
+	}
+	return steps, nil
+}
+
 // @Summary 创建Sql审批流程模板
 // @Description create a workflow template
 // @Accept json
@@ -179,23 +242,10 @@ func CreateWorkflowTemplate(c echo.Context) error {
 		return controller.JSONBaseErrorReq(c, errors.New(errors.DataExist, fmt.Errorf("workflow template is exist")))
 	}
 
-	err = validWorkflowTemplateReq(req.Steps)
-	if err != nil {
-		return controller.JSONBaseErrorReq(c, errors.New(errors.DataInvalid, err))
-	}
-	userNames := []string{}
-	for _, step := range req.Steps {
-		userNames = append(userNames, step.Users...)
-	}
-
-	users, err := s.GetAndCheckUserExist(userNames)
+	steps, err := checkAndGenerateWorkflowStepTemplates(s, req.Steps)
 	if err != nil {
 		return controller.JSONBaseErrorReq(c, err)
 	}
-	userMap := map[string]*model.User{}
-	for _, user := range users {
-		userMap[user.Name] = user
-	}
 
 	instances, err := s.GetAndCheckInstanceExist(req.Instances)
 	if err != nil {
@@ -205,28 +255,15 @@ func CreateWorkflowTemplate(c echo.Context) error {
 	if req.AllowSubmitWhenLessAuditLevel != "" {
 		allowSubmitWhenLessAuditLevel = req.AllowSubmitWhenLessAuditLevel
 	}
+	execFailureStrategy := model.ExecFailureStrategyStop
+	if req.ExecFailureStrategy != "" {
+		execFailureStrategy = req.ExecFailureStrategy
+	}
 	workflowTemplate := &model.WorkflowTemplate{
 		Name:                          req.Name,
 		Desc:                          req.Desc,
 		AllowSubmitWhenLessAuditLevel: allowSubmitWhenLessAuditLevel,
-	}
-	steps := make([]*model.WorkflowStepTemplate, 0, len(req.Steps))
-	for i, step := range req.Steps {
-		s := &model.WorkflowStepTemplate{
-			Number: uint(i + 1),
-			ApprovedByAuthorized: sql.NullBool{
-				Bool:  step.ApprovedByAuthorized,
-				Valid: true,
-			},
-			Typ:  step.Type,
-			Desc: step.Desc,
-		}
-		stepUsers := make([]*model.User, 0, len(step.Users))
-		for _, userName := range step.Users {
-			stepUsers = append(stepUsers, userMap[userName])
-		}
-		s.Users = stepUsers
-		steps = append(steps, s)
+		ExecFailureStrategy:           execFailureStrategy,
 	}
 	workflowTemplate.Steps = steps
 
@@ -245,6 +282,7 @@ func CreateWorkflowTemplate(c echo.Context) error {
 type UpdateWorkflowTemplateReqV1 struct {
 	Desc                          *string                      `json:"desc" form:"desc"`
 	AllowSubmitWhenLessAuditLevel *string                      `json:"allow_submit_when_less_audit_level" enums:"normal,notice,warn,error"`
+	ExecFailureStrategy           *string                      `json:"exec_failure_strategy" valid:"omitempty,oneof=stop continue rollback_executed" enums:"stop,continue,rollback_executed"`
 	Steps                         []*WorkFlowStepTemplateReqV1 `json:"workflow_step_template_list" form:"workflow_step_template_list"`
 	Instances                     []string                     `json:"instance_name_list" form:"instance_name_list"`
 }
@@ -284,42 +322,10 @@ func UpdateWorkflowTemplate(c echo.Context) error {
 	}
 
 	if req.Steps != nil {
-		err = validWorkflowTemplateReq(req.Steps)
-		if err != nil {
-			return controller.JSONBaseErrorReq(c, errors.New(errors.DataInvalid, err))
-		}
-		userNames := []string{}
-		for _, step := range req.Steps {
-			userNames = append(userNames, step.Users...)
-		}
-
-		users, err := s.GetAndCheckUserExist(userNames)
+		steps, err := checkAndGenerateWorkflowStepTemplates(s, req.Steps)
 		if err != nil {
 			return controller.JSONBaseErrorReq(c, err)
 		}
-		userMap := map[string]*model.User{}
-		for _, user := range users {
-			userMap[user.Name] = user
-		}
-
-		steps := make([]*model.WorkflowStepTemplate, 0, len(req.Steps))
-		for i, step := range req.Steps {
-			s := &model.WorkflowStepTemplate{
-				Number: uint(i + 1),
-				ApprovedByAuthorized: sql.NullBool{
-					Bool:  step.ApprovedByAuthorized,
-					Valid: true,
-				},
-				Typ:  step.Type,
-				Desc: step.Desc,
-			}
-			stepUsers := make([]*model.User, 0, len(step.Users))
-			for _, userName := range step.Users {
-				stepUsers = append(stepUsers, userMap[userName])
-			}
-			s.Users = stepUsers
-			steps = append(steps, s)
-		}
 		err = s.UpdateWorkflowTemplateSteps(workflowTemplate.ID, steps)
 		if err != nil {
 			return controller.JSONBaseErrorReq(c, err)
@@ -334,6 +340,10 @@ func UpdateWorkflowTemplate(c echo.Context) error {
 		workflowTemplate.AllowSubmitWhenLessAuditLevel = *req.AllowSubmitWhenLessAuditLevel
 	}
 
+	if req.ExecFailureStrategy != nil {
+		workflowTemplate.ExecFailureStrategy = *req.ExecFailureStrategy
+	}
+
 	err = s.Save(workflowTemplate)
 	if err != nil {
 		return controller.JSONBaseErrorReq(c, err)
@@ -473,10 +483,18 @@ type CreateWorkflowReqV1 struct {
 	Subject string `json:"workflow_subject" form:"workflow_subject" valid:"required,name"`
 	Desc    string `json:"desc" form:"desc"`
 	TaskId  string `json:"task_id" form:"task_id" valid:"required"`
+	// Targets fan out the SQLs of task to other instances, a task is created
+	// and audited for each target.
+	Targets []*WorkflowTargetReqV1 `json:"targets" form:"targets" valid:"dive,required"`
+}
+
+type WorkflowTargetReqV1 struct {
+	InstanceName   string `json:"instance_name" form:"instance_name" valid:"required"`
+	InstanceSchema string `json:"instance_schema" form:"instance_schema"`
 }
 
 // @Summary 创建工单
-// @Description create workflow
+// @Description create workflow, the SQLs of task will be executed on the task instance and all targets.
 // @Accept json
 // @Produce json
 // @Tags workflow
@@ -564,12 +582,19 @@ func CreateWorkflow(c echo.Context) error {
 		return controller.JSONBaseErrorReq(c, err)
 	}
 
+	tasks, err := createTasksForWorkflowTargets(user, task, req.Targets)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
 	stepTemplates, err := s.GetWorkflowStepsByTemplateId(template.ID)
 	if err != nil {
+		deleteWorkflowTargetTasks(tasks)
 		return err
 	}
-	err = s.CreateWorkflow(req.Subject, req.Desc, user, task, stepTemplates)
+	err = s.CreateWorkflow(req.Subject, req.Desc, user, tasks, stepTemplates)
 	if err != nil {
+		deleteWorkflowTargetTasks(tasks)
 		return controller.JSONBaseErrorReq(c, err)
 	}
 
@@ -580,11 +605,194 @@ func CreateWorkflow(c echo.Context) error {
 	if !exist {
 		return controller.JSONBaseErrorReq(c, errors.New(errors.DataNotExist, fmt.Errorf("should exist at least one workflow after create workflow")))
 	}
+	auditWorkflowTargetTasks(tasks)
 	go notification.NotifyWorkflow(fmt.Sprintf("%v", workflow.ID), notification.WorkflowNotifyTypeCreate)
 
 	return c.JSON(http.StatusOK, controller.NewBaseReq(nil))
 }
 
+// createTasksForWorkflowTargets creates a task with the SQLs of main task for
+// each target, the target tasks are audited by auditWorkflowTargetTasks after
+// the workflow is saved. The main task is the first of returned tasks. The
+// created target tasks are deleted if it returns error.
+func createTasksForWorkflowTargets(user *model.User, mainTask *model.Task,
+	targets []*WorkflowTargetReqV1) (_ []*model.Task, err error) {
+
+	tasks := []*model.Task{mainTask}
+	if len(targets) == 0 {
+		return tasks, nil
+	}
+	defer func() {
+		if err != nil {
+			deleteWorkflowTargetTasks(tasks)
+		}
+	}()
+
+	s := model.GetStorage()
+	mainTask, exist, err := s.GetTaskDetailById(fmt.Sprintf("%d", mainTask.ID))
+	if err != nil {
+		return nil, err
+	}
+	if !exist {
+		return nil, ErrTaskNoAccess
+	}
+
+	targetKey := func(instanceId uint, schema string) string {
+		return fmt.Sprintf("%d:%s", instanceId, schema)
+	}
+	existTargets := map[string]struct{}{
+		targetKey(mainTask.InstanceId, mainTask.Schema): {},
+	}
+	for _, target := range targets {
+		instance, exist, err := s.GetInstanceByName(target.InstanceName)
+		if err != nil {
+			return nil, err
+		}
+		if !exist {
+			return nil, errInstanceNoAccess
+		}
+		if _, ok := existTargets[targetKey(instance.ID, target.InstanceSchema)]; ok {
+			continue
+		}
+		existTargets[targetKey(instance.ID, target.InstanceSchema)] = struct{}{}
+
+		if instance.DbType != mainTask.DBType {
+			return nil, errors.New(errors.DataInvalid, fmt.Errorf(
+				"the db type of instance %s is %s, but the task is %s", instance.Name, instance.DbType, mainTask.DBType))
+		}
+		if err := checkCurrentUserCanCreateWorkflow(user, instance); err != nil {
+			return nil, err
+		}
+		// the target task is audited by the rule template of target instance.
+		ruleTemplates, err := s.GetRuleTemplatesByInstance(instance)
+		if err != nil {
+			return nil, err
+		}
+		if len(ruleTemplates) == 0 {
+			return nil, errors.New(errors.DataInvalid, fmt.Errorf(
+				"the instance %s is not bound rule template", instance.Name))
+		}
+
+		task := &model.Task{
+			Schema:       target.InstanceSchema,
+			InstanceId:   instance.ID,
+			CreateUserId: user.ID,
+			ExecuteSQLs:  make([]*model.ExecuteSQL, 0, len(mainTask.ExecuteSQLs)),
+			SQLSource:    mainTask.SQLSource,
+			DBType:       mainTask.DBType,
+		}
+		for _, executeSQL := range mainTask.ExecuteSQLs {
+			task.ExecuteSQLs = append(task.ExecuteSQLs, &model.ExecuteSQL{
+				BaseSQL: model.BaseSQL{
+					Number:  executeSQL.Number,
+					Content: executeSQL.Content,
+				},
+				SourceFile: executeSQL.SourceFile,
+				SourceLine: executeSQL.SourceLine,
+			})
+		}
+		if err := s.Save(task); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+// auditWorkflowTargetTasks audits the target tasks in background by the rule
+// template of the target instance, since auditing many targets may exceed the
+// timeout of request. The workflow can not be approved or executed until all
+// of them are audited, see checkWorkflowTargetsAudited.
+func auditWorkflowTargetTasks(tasks []*model.Task) {
+	if len(tasks) <= 1 {
+		return
+	}
+	go func() {
+		for _, task := range tasks[1:] {
+			_, err := server.GetSqled().AddTaskWaitResult(fmt.Sprintf("%d", task.ID), server.ActionTypeAudit)
+			if err != nil {
+				log.NewEntry().Errorf("audit target task %d failed, error: %v", task.ID, err)
+			}
+		}
+	}()
+}
+
+// checkWorkflowTargetsAudited checks the target tasks of workflow are audited
+// and their audit levels are allowed by the workflow template.
+func checkWorkflowTargetsAudited(workflow *model.Workflow) error {
+	taskIds := workflow.Record.TaskIds()
+	if len(taskIds) <= 1 {
+		return nil
+	}
+	s := model.GetStorage()
+	mainTask, exist, err := s.GetTaskById(fmt.Sprintf("%d", workflow.Record.TaskId))
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return ErrTaskNoAccess
+	}
+	if mainTask.Instance == nil {
+		return errInstanceNotExist
+	}
+	template, exist, err := s.GetWorkflowTemplateById(mainTask.Instance.WorkflowTemplateId)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		return errors.New(errors.DataNotExist, fmt.Errorf("the task instance is not bound workflow template"))
+	}
+	for _, taskId := range taskIds {
+		task, exist, err := s.GetTaskById(fmt.Sprintf("%d", taskId))
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return ErrTaskNoAccess
+		}
+		if task.Status == model.TaskStatusInit {
+			return errors.New(errors.DataInvalid, fmt.Errorf(
+				"the task %d of workflow is not audited yet, please try again later", task.ID))
+		}
+		if err := checkWorkflowCanCommit(template, task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteWorkflowTargetTasks deletes the target tasks created by
+// createTasksForWorkflowTargets, the main task is kept.
+func deleteWorkflowTargetTasks(tasks []*model.Task) {
+	s := model.GetStorage()
+	for _, task := range tasks[1:] {
+		if err := s.DeleteTask(task); err != nil {
+			log.NewEntry().Errorf("delete target task %d failed, error: %v", task.ID, err)
+		}
+	}
+}
+
+// getWorkflowTargets returns the targets except the main task of workflow record.
+func getWorkflowTargets(record *model.WorkflowRecord) ([]*WorkflowTargetReqV1, error) {
+	s := model.GetStorage()
+	taskIds := record.TaskIds()
+	targets := make([]*WorkflowTargetReqV1, 0, len(taskIds))
+	for _, taskId := range taskIds[1:] {
+		task, exist, err := s.GetTaskById(fmt.Sprintf("%d", taskId))
+		if err != nil {
+			return nil, err
+		}
+		if !exist || task.Instance == nil {
+			continue
+		}
+		targets = append(targets, &WorkflowTargetReqV1{
+			InstanceName:   task.Instance.Name,
+			InstanceSchema: task.Schema,
+		})
+	}
+	return targets, nil
+}
+
 func checkWorkflowCanCommit(template *model.WorkflowTemplate, task *model.Task) error {
 	allowLevel := driver.RuleLevelError
 	if template.AllowSubmitWhenLessAuditLevel != "" {
@@ -616,10 +824,12 @@ type WorkflowResV1 struct {
 type WorkflowRecordResV1 struct {
 	TaskId            uint                 `json:"task_id"`
 	CurrentStepNumber uint                 `json:"current_step_number,omitempty"`
-	Status            string               `json:"status" enums:"on_process,rejected,canceled,exec_scheduled,executing,exec_failed,finished"`
+	Status            string               `json:"status" enums:"on_process,rejected,canceled,exec_scheduled,executing,exec_failed,exec_partial_success,finished"`
 	ScheduleTime      *time.Time           `json:"schedule_time,omitempty"`
 	ScheduleUser      string               `json:"schedule_user,omitempty"`
 	Steps             []*WorkflowStepResV1 `json:"workflow_step_list,omitempty"`
+	// Tasks is the tasks of all targets, each target is executed with its own status.
+	Tasks []*AuditTaskResV1 `json:"task_list,omitempty"`
 }
 
 type WorkflowStepResV1 struct {
@@ -651,28 +861,32 @@ func checkCurrentUserCanAccessWorkflow(c echo.Context, workflow *model.Workflow,
 		return nil
 	}
 	if len(ops) > 0 {
-		instance, err := s.GetInstanceByWorkflowID(workflow.ID)
+		instances, err := s.GetInstancesByWorkflowID(workflow.ID)
 		if err != nil {
 			return err
 		}
-		ok, err := s.CheckUserHasOpToInstance(user, instance, ops)
-		if err != nil {
-			return err
-		}
-		if ok {
-			return nil
+		for _, instance := range instances {
+			ok, err := s.CheckUserHasOpToInstance(user, instance, ops)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return nil
+			}
 		}
 	}
 	return ErrWorkflowNoAccess
 }
 
-func convertWorkflowToRes(workflow *model.Workflow, task *model.Task) *WorkflowResV1 {
+// convertWorkflowToRes converts workflow with the tasks of all targets, the
+// first task is the main task.
+func convertWorkflowToRes(workflow *model.Workflow, tasks []*model.Task) *WorkflowResV1 {
 	workflowRes := &WorkflowResV1{
 		Id:                       workflow.ID,
 		Subject:                  workflow.Subject,
 		Desc:                     workflow.Desc,
 		CreateTime:               &workflow.CreatedAt,
-		InstanceMaintenanceTimes: convertPeriodToMaintenanceTimeResV1(task.Instance.MaintenancePeriod),
+		InstanceMaintenanceTimes: convertPeriodToMaintenanceTimeResV1(tasks[0].Instance.MaintenancePeriod),
 	}
 
 	workflowRes.CreateUser = utils.AddDelTag(workflow.CreateUser.DeletedAt, workflow.CreateUserName())
@@ -688,7 +902,7 @@ func convertWorkflowToRes(workflow *model.Workflow, task *model.Task) *WorkflowR
 
 	// find schedule user name by id in final step(sql execute step),
 	// only the person specified in the final step can set the schedule time.
-	finalStep := workflow.FinalStep()
+	finalStep := workflow.ExecuteStep()
 	if workflow.Record.ScheduledAt != nil && finalStep.Template.Users != nil {
 		for _, user := range finalStep.Template.Users {
 			if user.ID == workflow.Record.ScheduleUserId {
@@ -696,7 +910,13 @@ func convertWorkflowToRes(workflow *model.Workflow, task *model.Task) *WorkflowR
 			}
 		}
 	}
-	recordRes.Status = convertWorkflowStatusToRes(workflow.Record.Status, task.Status, workflow.Record.ScheduledAt)
+	taskStatuses := make([]string, 0, len(tasks))
+	recordRes.Tasks = make([]*AuditTaskResV1, 0, len(tasks))
+	for _, task := range tasks {
+		taskStatuses = append(taskStatuses, task.Status)
+		recordRes.Tasks = append(recordRes.Tasks, convertTaskToRes(task))
+	}
+	recordRes.Status = convertWorkflowStatusToRes(workflow.Record.Status, taskStatuses, workflow.Record.ScheduledAt)
 	workflowRes.Record = recordRes
 
 	// convert workflow record history
@@ -768,15 +988,10 @@ func convertWorkflowStepToRes(step *model.WorkflowStep) *WorkflowStepResV1 {
 	return stepRes
 }
 
-func convertWorkflowStatusToRes(workflowStatus, taskStatus string, scheduleTime *time.Time) string {
+func convertWorkflowStatusToRes(workflowStatus string, taskStatuses []string, scheduleTime *time.Time) string {
 	var status = workflowStatus
-	switch taskStatus {
-	case model.TaskStatusExecuting:
-		status = model.WorkflowStatusExecuting
-	case model.TaskStatusExecuteSucceeded:
-		status = model.WorkflowStatusFinish
-	case model.TaskStatusExecuteFailed:
-		status = model.WorkflowStatusExecFailed
+	if execStatus := model.AggregateTaskStatus(taskStatuses); execStatus != "" {
+		status = execStatus
 	}
 	if status == model.WorkflowStatusRunning && scheduleTime != nil {
 		status = model.WorkflowStatusExecScheduled
@@ -819,17 +1034,22 @@ func GetWorkflow(c echo.Context) error {
 	}
 	workflow.RecordHistory = history
 
-	task, exist, err := s.GetTaskById(strconv.Itoa(int(workflow.Record.TaskId)))
-	if err != nil {
-		return controller.JSONBaseErrorReq(c, err)
-	}
-	if !exist {
-		return controller.JSONBaseErrorReq(c, ErrTaskNoAccess)
+	taskIds := workflow.Record.TaskIds()
+	tasks := make([]*model.Task, 0, len(taskIds))
+	for _, taskId := range taskIds {
+		task, exist, err := s.GetTaskById(strconv.Itoa(int(taskId)))
+		if err != nil {
+			return controller.JSONBaseErrorReq(c, err)
+		}
+		if !exist {
+			return controller.JSONBaseErrorReq(c, ErrTaskNoAccess)
+		}
+		tasks = append(tasks, task)
 	}
 
 	return c.JSON(http.StatusOK, &GetWorkflowResV1{
 		BaseRes: controller.NewBaseReq(nil),
-		Data:    convertWorkflowToRes(workflow, task),
+		Data:    convertWorkflowToRes(workflow, tasks),
 	})
 }
 
@@ -839,7 +1059,7 @@ type GetWorkflowsReqV1 struct {
 	FilterCreateTimeTo                string `json:"filter_create_time_to" query:"filter_create_time_to"`
 	FilterCreateUserName              string `json:"filter_create_user_name" query:"filter_create_user_name"`
 	FilterCurrentStepType             string `json:"filter_current_step_type" query:"filter_current_step_type" valid:"omitempty,oneof=sql_review sql_execute"`
-	FilterStatus                      string `json:"filter_status" query:"filter_status" valid:"omitempty,oneof=on_process rejected canceled exec_scheduled executing exec_failed finished"`
+	FilterStatus                      string `json:"filter_status" query:"filter_status" valid:"omitempty,oneof=on_process rejected canceled exec_scheduled executing exec_failed exec_partial_success finished"`
 	FilterCurrentStepAssigneeUserName string `json:"filter_current_step_assignee_user_name" query:"filter_current_step_assignee_user_name"`
 	FilterTaskInstanceName            string `json:"filter_task_instance_name" query:"filter_task_instance_name"`
 	PageIndex                         uint32 `json:"page_index" query:"page_index" valid:"required"`
@@ -864,7 +1084,7 @@ type WorkflowDetailResV1 struct {
 	CreateTime              *time.Time `json:"create_time"`
 	CurrentStepType         string     `json:"current_step_type,omitempty" enums:"sql_review,sql_execute"`
 	CurrentStepAssigneeUser []string   `json:"current_step_assignee_user_name_list,omitempty"`
-	Status                  string     `json:"status" enums:"on_process,rejected,canceled,exec_scheduled,executing,exec_failed,finished"`
+	Status                  string     `json:"status" enums:"on_process,rejected,canceled,exec_scheduled,executing,exec_failed,exec_partial_success,finished"`
 	ScheduleTime            *time.Time `json:"schedule_time,omitempty"`
 }
 
@@ -878,7 +1098,7 @@ type WorkflowDetailResV1 struct {
 // @Param filter_create_time_to query string false "filter create time to"
 // @Param filter_create_user_name query string false "filter create user name"
 // @Param filter_current_step_type query string false "filter current step type" Enums(sql_review, sql_execute)
-// @Param filter_status query string false "filter workflow status" Enums(on_process, rejected, canceled, exec_scheduled, executing, exec_failed, finished)
+// @Param filter_status query string false "filter workflow status" Enums(on_process, rejected, canceled, exec_scheduled, executing, exec_failed, exec_partial_success, finished)
 // @Param filter_current_step_assignee_user_name query string false "filter current step assignee user name"
 // @Param filter_task_instance_name query string false "filter instance name"
 // @Param page_index query uint32 false "page index"
@@ -900,40 +1120,12 @@ func GetWorkflows(c echo.Context) error {
 	if req.PageIndex >= 1 {
 		offset = req.PageSize * (req.PageIndex - 1)
 	}
-	var workflowStatus string
-	var taskStatus string
-	var isScheduled bool
-	var notScheduled bool
-	// filter task status
-	switch req.FilterStatus {
-	case model.WorkflowStatusExecuting:
-		taskStatus = model.TaskStatusExecuting
-	case model.WorkflowStatusExecFailed:
-		taskStatus = model.TaskStatusExecuteFailed
-	case model.WorkflowStatusFinish:
-		taskStatus = model.TaskStatusExecuteSucceeded
-	}
-	// filter workflow status
-	switch req.FilterStatus {
-	case model.WorkflowStatusRunning:
-		workflowStatus = model.WorkflowStatusRunning
-		notScheduled = true
-	case model.WorkflowStatusExecScheduled:
-		workflowStatus = model.WorkflowStatusRunning
-		isScheduled = true
-	case model.WorkflowStatusCancel, model.WorkflowStatusReject:
-		workflowStatus = req.FilterStatus
-	}
-
 	data := map[string]interface{}{
 		"filter_subject":                         req.FilterSubject,
 		"filter_create_time_from":                req.FilterCreateTimeFrom,
 		"filter_create_time_to":                  req.FilterCreateTimeTo,
 		"filter_create_user_name":                req.FilterCreateUserName,
-		"filter_status":                          workflowStatus,
-		"filter_task_status":                     taskStatus,
-		"is_scheduled":                           isScheduled,
-		"not_scheduled":                          notScheduled,
+		"filter_workflow_status":                 req.FilterStatus,
 		"filter_current_step_type":               req.FilterCurrentStepType,
 		"filter_current_step_assignee_user_name": req.FilterCurrentStepAssigneeUserName,
 		"filter_task_instance_name":              req.FilterTaskInstanceName,
@@ -962,7 +1154,7 @@ func GetWorkflows(c echo.Context) error {
 			CreateTime:              workflow.CreateTime,
 			CurrentStepType:         workflow.CurrentStepType.String,
 			CurrentStepAssigneeUser: workflow.CurrentStepAssigneeUser,
-			Status:                  convertWorkflowStatusToRes(workflow.Status, workflow.TaskStatus, workflow.ScheduleTime),
+			Status:                  convertWorkflowStatusToRes(workflow.Status, workflow.TaskStatuses(), workflow.ScheduleTime),
 			ScheduleTime:            workflow.ScheduleTime,
 		}
 		workflowsReq = append(workflowsReq, workflowReq)
@@ -975,10 +1167,12 @@ func GetWorkflows(c echo.Context) error {
 }
 
 func checkUserCanOperateStep(user *model.User, workflow *model.Workflow, stepId int) error {
-	if workflow.Record.Status != model.WorkflowStatusRunning {
+	currentStep := workflow.CurrentStep()
+	// the sql_rollback step is operated after the workflow is executed.
+	isRollbackStep := currentStep != nil && currentStep.Template.Typ == model.WorkflowStepTypeSQLRollback
+	if workflow.Record.Status != model.WorkflowStatusRunning && !isRollbackStep {
 		return fmt.Errorf("workflow status is %s, not allow operate it", workflow.Record.Status)
 	}
-	currentStep := workflow.CurrentStep()
 	if currentStep == nil {
 		return fmt.Errorf("workflow current step not found")
 	}
@@ -1047,18 +1241,36 @@ func ApproveWorkflow(c echo.Context) error {
 			fmt.Errorf("workflow has been approved, you should to execute it")))
 	}
 
+	// the sql_rollback step approves the rollback requested on the executed
+	// workflow, the rollback is started after it is approved.
+	if currentStep.Template.Typ == model.WorkflowStepTypeSQLRollback {
+		if err := server.ApproveRollbackWorkflow(workflow, user.ID); err != nil {
+			return controller.JSONBaseErrorReq(c, err)
+		}
+		return c.JSON(http.StatusOK, controller.NewBaseReq(nil))
+	}
+
+	if err := checkWorkflowTargetsAudited(workflow); err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
 	currentStep.State = model.WorkflowStepStateApprove
 	now := time.Now()
 	currentStep.OperateAt = &now
 	currentStep.OperationUserId = user.ID
 	nextStep := workflow.NextStep()
-	workflow.Record.CurrentWorkflowStepId = nextStep.ID
+	workflow.Record.CurrentWorkflowStepId = 0
+	if nextStep != nil {
+		workflow.Record.CurrentWorkflowStepId = nextStep.ID
+	}
 
 	err = s.UpdateWorkflowStatus(workflow, currentStep)
 	if err != nil {
 		return c.JSON(http.StatusOK, controller.NewBaseReq(err))
 	}
-	go notification.NotifyWorkflow(workflowId, notification.WorkflowNotifyTypeApprove)
+	if nextStep != nil {
+		go notification.NotifyWorkflow(workflowId, notification.WorkflowNotifyTypeApprove)
+	}
 
 	return c.JSON(http.StatusOK, controller.NewBaseReq(nil))
 }
@@ -1129,10 +1341,17 @@ func RejectWorkflow(c echo.Context) error {
 	currentStep.OperateAt = &now
 	currentStep.OperationUserId = user.ID
 
-	workflow.Record.Status = model.WorkflowStatusReject
 	workflow.Record.CurrentWorkflowStepId = 0
 
-	err = s.UpdateWorkflowStatus(workflow, currentStep)
+	// rejecting the sql_rollback step only drops the rollback requested on the
+	// executed workflow, the status of workflow is not changed.
+	if currentStep.Template.Typ == model.WorkflowStepTypeSQLRollback {
+		workflow.Record.SetRollbackRequest(nil, nil)
+		err = s.UpdateWorkflowRollback(workflow, currentStep)
+	} else {
+		workflow.Record.Status = model.WorkflowStatusReject
+		err = s.UpdateWorkflowStatus(workflow, currentStep)
+	}
 	if err != nil {
 		return c.JSON(http.StatusOK, controller.NewBaseReq(err))
 	}
@@ -1243,6 +1462,9 @@ func checkCancelWorkflow(id string) (*model.Workflow, error) {
 
 type UpdateWorkflowReqV1 struct {
 	TaskId string `json:"task_id" form:"task_id" valid:"required"`
+	// Targets is the same as CreateWorkflowReqV1.Targets, the targets of the
+	// rejected workflow are used if it is empty.
+	Targets []*WorkflowTargetReqV1 `json:"targets" form:"targets" valid:"dive,required"`
 }
 
 // @Summary 更新审批流程（驳回后才可更新）
@@ -1349,15 +1571,29 @@ func UpdateWorkflow(c echo.Context) error {
 			fmt.Errorf("failed to find the corresponding workflow template based on the task id")))
 	}
 
+	targets := req.Targets
+	if len(targets) == 0 {
+		targets, err = getWorkflowTargets(workflow.Record)
+		if err != nil {
+			return controller.JSONBaseErrorReq(c, err)
+		}
+	}
 	err = checkWorkflowCanCommit(template, task)
 	if err != nil {
 		return controller.JSONBaseErrorReq(c, err)
 	}
 
-	err = s.UpdateWorkflowRecord(workflow, task)
+	tasks, err := createTasksForWorkflowTargets(user, task, targets)
 	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	err = s.UpdateWorkflowRecord(workflow, tasks)
+	if err != nil {
+		deleteWorkflowTargetTasks(tasks)
 		return c.JSON(http.StatusOK, controller.NewBaseReq(err))
 	}
+	auditWorkflowTargetTasks(tasks)
 	go notification.NotifyWorkflow(workflowId, notification.WorkflowNotifyTypeCreate)
 
 	return c.JSON(http.StatusOK, controller.NewBaseReq(nil))
@@ -1440,12 +1676,20 @@ func UpdateWorkflowSchedule(c echo.Context) error {
 			"request schedule time is too early")))
 	}
 
-	instance, err := s.GetInstanceByWorkflowID(workflow.ID)
+	instances, err := s.GetInstancesByWorkflowID(workflow.ID)
 	if err != nil {
 		return controller.JSONBaseErrorReq(c, err)
 	}
-	if req.ScheduleTime != nil && len(instance.MaintenancePeriod) != 0 && !instance.MaintenancePeriod.IsWithinScope(*req.ScheduleTime) {
-		return controller.JSONBaseErrorReq(c, errWorkflowExecuteTimeIncorrect)
+	for _, instance := range instances {
+		if req.ScheduleTime != nil && len(instance.MaintenancePeriod) != 0 && !instance.MaintenancePeriod.IsWithinScope(*req.ScheduleTime) {
+			return controller.JSONBaseErrorReq(c, errWorkflowExecuteTimeIncorrect)
+		}
+	}
+
+	if req.ScheduleTime != nil {
+		if err := checkWorkflowTargetsAudited(workflow); err != nil {
+			return controller.JSONBaseErrorReq(c, err)
+		}
 	}
 
 	err = s.UpdateWorkflowSchedule(workflow, user.ID, req.ScheduleTime)
@@ -1508,12 +1752,17 @@ func ExecuteTaskOnWorkflow(c echo.Context) error {
 		return controller.JSONBaseErrorReq(c, errors.New(errors.DataInvalid,
 			fmt.Errorf("workflow has been set to scheduled execution, not allowed to be executed")))
 	}
-	instance, err := s.GetInstanceByWorkflowID(workflow.ID)
+	instances, err := s.GetInstancesByWorkflowID(workflow.ID)
 	if err != nil {
 		return controller.JSONBaseErrorReq(c, err)
 	}
-	if len(instance.MaintenancePeriod) != 0 && !instance.MaintenancePeriod.IsWithinScope(time.Now()) {
-		return controller.JSONBaseErrorReq(c, errWorkflowExecuteTimeIncorrect)
+	for _, instance := range instances {
+		if len(instance.MaintenancePeriod) != 0 && !instance.MaintenancePeriod.IsWithinScope(time.Now()) {
+			return controller.JSONBaseErrorReq(c, errWorkflowExecuteTimeIncorrect)
+		}
+	}
+	if err := checkWorkflowTargetsAudited(workflow); err != nil {
+		return controller.JSONBaseErrorReq(c, err)
 	}
 
 	err = server.ExecuteWorkflow(workflow, user.ID)
@@ -1523,6 +1772,105 @@ func ExecuteTaskOnWorkflow(c echo.Context) error {
 	return c.JSON(http.StatusOK, controller.NewBaseReq(nil))
 }
 
+type RollbackTaskOnWorkflowReqV1 struct {
+	TaskId     *uint  `json:"task_id" form:"task_id"`
+	SQLNumbers []uint `json:"sql_number_list" form:"sql_number_list"`
+}
+
+// @Summary 工单提交 SQL 回滚
+// @Description rollback the executed SQLs of task on workflow. If the workflow has sql_rollback step, the rollback is
+// @Description waiting for approval of the step, and the requested SQLs are rolled back only after it is approved.
+// @Tags workflow
+// @Id rollbackTaskOnWorkflowV1
+// @Security ApiKeyAuth
+// @Accept json
+// @Produce json
+// @Param workflow_id path string true "workflow id"
+// @Param rollback body v1.RollbackTaskOnWorkflowReqV1 true "rollback task on workflow request"
+// @Success 200 {object} controller.BaseRes
+// @router /v1/workflows/{workflow_id}/task/rollback [post]
+func RollbackTaskOnWorkflow(c echo.Context) error {
+	req := new(RollbackTaskOnWorkflowReqV1)
+	if err := controller.BindAndValidateReq(c, req); err != nil {
+		return err
+	}
+	workflowId := c.Param("workflow_id")
+	id, err := FormatStringToInt(workflowId)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	err = checkCurrentUserCanAccessWorkflow(c, &model.Workflow{
+		Model: model.Model{ID: uint(id)},
+	}, []uint{model.OP_WORKFLOW_ROLLBACK})
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	user, err := controller.GetCurrentUser(c)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	s := model.GetStorage()
+	workflow, exist, err := s.GetWorkflowDetailById(workflowId)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	if !exist {
+		return controller.JSONBaseErrorReq(c, ErrWorkflowNoAccess)
+	}
+	taskIds := workflow.Record.TaskIds()
+	if req.TaskId != nil {
+		var found bool
+		for _, taskId := range taskIds {
+			if taskId == *req.TaskId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return controller.JSONBaseErrorReq(c, errors.New(errors.DataNotExist,
+				fmt.Errorf("task is not a target of workflow")))
+		}
+		taskIds = []uint{*req.TaskId}
+	}
+
+	err = checkUserCanRollbackWorkflow(user, workflow)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+
+	err = server.RollbackWorkflow(workflow, taskIds, req.SQLNumbers)
+	if err != nil {
+		return controller.JSONBaseErrorReq(c, err)
+	}
+	return c.JSON(http.StatusOK, controller.NewBaseReq(nil))
+}
+
+// checkUserCanRollbackWorkflow checks the user has rollback operation on all
+// instances of workflow.
+func checkUserCanRollbackWorkflow(user *model.User, workflow *model.Workflow) error {
+	if model.IsDefaultAdminUser(user.Name) {
+		return nil
+	}
+
+	s := model.GetStorage()
+	instances, err := s.GetInstancesByWorkflowID(workflow.ID)
+	if err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		ok, err := s.CheckUserHasOpToInstance(user, instance, []uint{model.OP_WORKFLOW_ROLLBACK})
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.NewAccessDeniedErr("user has no access to rollback workflow for instance")
+		}
+	}
+	return nil
+}
+
 func checkCurrentUserCanCreateWorkflow(user *model.User, instance *model.Instance) error {
 
 	if model.IsDefaultAdminUser(user.Name) {
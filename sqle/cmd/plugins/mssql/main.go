@@ -0,0 +1,12 @@
+package main
+
+import (
+	"github.com/actiontech/sqle/sqle/pkg/mssql/plugin"
+)
+
+// version is set by ldflags on build.
+var version string
+
+func main() {
+	plugin.Serve(version)
+}
@@ -0,0 +1,163 @@
+// Package auditor audits SQL files offline by the embedded MySQL driver, it
+// does not connect to SQLE server or database, so it can be used in CI.
+package auditor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners"
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners/common"
+	"github.com/actiontech/sqle/sqle/config"
+	"github.com/actiontech/sqle/sqle/driver"
+	_ "github.com/actiontech/sqle/sqle/driver/mysql"
+	"github.com/actiontech/sqle/sqle/pkg/report"
+	"github.com/actiontech/sqle/sqle/pkg/ruletemplate"
+
+	"github.com/sirupsen/logrus"
+)
+
+// StdinPath is the path which means reading SQL from stdin.
+const StdinPath = "-"
+
+type Params struct {
+	// RuleTemplateFile is the exported rule template, all rules of driver
+	// with default level are used if it is empty.
+	RuleTemplateFile string
+	// Paths are the SQL files or directories, the ".sql" files in directory
+	// are audited. The SQL is read from Stdin if Paths is empty.
+	Paths []string
+	Stdin io.Reader
+}
+
+// Audit audits the SQLs in order, the SQL context is kept across files, so
+// the tables created by previous files are known by the following files.
+func Audit(l *logrus.Entry, params *Params) (*report.Report, error) {
+	rules, err := loadRules(l, params.RuleTemplateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	sqls, err := readSQLs(params)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &report.Report{
+		Name:    "SQLE offline audit",
+		Version: config.Version,
+		Rules:   rules,
+		SQLs:    make([]*report.SQL, 0, len(sqls)),
+	}
+	if len(sqls) == 0 {
+		return r, nil
+	}
+
+	cfg, err := driver.NewConfig(nil, rules)
+	if err != nil {
+		return nil, err
+	}
+	d, err := driver.NewDriver(l, driver.DriverTypeMySQL, cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close(context.TODO())
+
+	contents := make([]string, 0, len(sqls))
+	for _, sql := range sqls {
+		contents = append(contents, sql.RawText)
+	}
+	results, err := d.AuditBatch(context.TODO(), contents)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) != len(sqls) {
+		return nil, fmt.Errorf("expect %d audit results, but got %d", len(sqls), len(results))
+	}
+	for i, sql := range sqls {
+		r.SQLs = append(r.SQLs, &report.SQL{
+			Number:   uint(i + 1),
+			Content:  sql.RawText,
+			FilePath: sql.FilePath,
+			Line:     sql.Line,
+			Results:  report.NewResults(results[i]),
+		})
+	}
+	return r, nil
+}
+
+func loadRules(l *logrus.Entry, ruleTemplateFile string) ([]*driver.Rule, error) {
+	driverRules := driver.AllRules()[driver.DriverTypeMySQL]
+	if ruleTemplateFile == "" {
+		return driverRules, nil
+	}
+
+	template, err := ruletemplate.Load(ruleTemplateFile)
+	if err != nil {
+		return nil, err
+	}
+	if template.DBType != driver.DriverTypeMySQL {
+		return nil, fmt.Errorf("db type %s of rule template is not supported in offline audit, only %s is supported",
+			template.DBType, driver.DriverTypeMySQL)
+	}
+	rules, unknown, err := template.DriverRules(driverRules)
+	if err != nil {
+		return nil, err
+	}
+	if len(unknown) > 0 {
+		l.Warnf("rules %s of rule template are not supported by this version, they are skipped",
+			strings.Join(unknown, ", "))
+	}
+	return rules, nil
+}
+
+func readSQLs(params *Params) ([]scanners.SQL, error) {
+	if len(params.Paths) == 0 {
+		params.Paths = []string{StdinPath}
+	}
+
+	var allSQL []scanners.SQL
+	for _, path := range params.Paths {
+		if path == StdinPath {
+			content, err := ioutil.ReadAll(params.Stdin)
+			if err != nil {
+				return nil, err
+			}
+			sqls, err := common.ParseSQL("stdin", string(content), 1)
+			if err != nil {
+				return nil, err
+			}
+			allSQL = append(allSQL, sqls...)
+			continue
+		}
+
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			// the file which is specified explicitly is audited regardless of its extension.
+			if info.IsDir() || (file != path && !strings.EqualFold(filepath.Ext(file), ".sql")) {
+				return nil
+			}
+			content, err := ioutil.ReadFile(filepath.Clean(file))
+			if err != nil {
+				return err
+			}
+			sqls, err := common.ParseSQL(filepath.ToSlash(file), string(content), 1)
+			if err != nil {
+				return fmt.Errorf("parse SQL file %s failed: %v", file, err)
+			}
+			allSQL = append(allSQL, sqls...)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return allSQL, nil
+}
@@ -0,0 +1,62 @@
+package auditor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	r, err := Audit(logrus.NewEntry(logrus.New()), &Params{
+		RuleTemplateFile: "./testdata/rule_template.yaml",
+		Paths:            []string{"./testdata/migration"},
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, r.SQLs, 3) {
+		t.FailNow()
+	}
+	assert.Len(t, r.Rules, 4)
+
+	createT1 := r.SQLs[0]
+	assert.Equal(t, "testdata/migration/V1__init.sql", createT1.FilePath)
+	assert.Equal(t, 1, createT1.Line)
+	assert.Equal(t, driver.RuleLevelNotice, createT1.Level())
+
+	deleteT1 := r.SQLs[1]
+	assert.Equal(t, 9, deleteT1.Line)
+	assert.Equal(t, driver.RuleLevelError, deleteT1.Level())
+	assert.Equal(t, "all_check_where_is_invalid", deleteT1.Results[0].RuleName)
+
+	createT2 := r.SQLs[2]
+	assert.Equal(t, "testdata/migration/V2__add_t2.sql", createT2.FilePath)
+	assert.Equal(t, 2, createT2.Line)
+	ruleNames := []string{}
+	for _, result := range createT2.Results {
+		ruleNames = append(ruleNames, result.RuleName)
+	}
+	assert.ElementsMatch(t, []string{"ddl_check_pk_not_exist", "ddl_check_table_without_comment"}, ruleNames)
+
+	assert.Equal(t, driver.RuleLevelError, r.Level())
+}
+
+func TestAuditStdin(t *testing.T) {
+	r, err := Audit(logrus.NewEntry(logrus.New()), &Params{
+		Paths: []string{StdinPath},
+		Stdin: strings.NewReader("select 1;\nselect * from t1 where id = 1;"),
+	})
+	if !assert.NoError(t, err) || !assert.Len(t, r.SQLs, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, "stdin", r.SQLs[1].FilePath)
+	assert.Equal(t, 2, r.SQLs[1].Line)
+	assert.Equal(t, len(driver.AllRules()[driver.DriverTypeMySQL]), len(r.Rules))
+
+	_, err = Audit(logrus.NewEntry(logrus.New()), &Params{
+		RuleTemplateFile: "./testdata/not-exist.yaml",
+		Stdin:            &bytes.Buffer{},
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,9 @@
+CREATE TABLE t1 (
+  id INT NOT NULL,
+  name VARCHAR(32),
+  PRIMARY KEY (id),
+  KEY idx_name (name),
+  KEY idx_id_name (id, name)
+) COMMENT 'table t1';
+
+DELETE FROM t1;
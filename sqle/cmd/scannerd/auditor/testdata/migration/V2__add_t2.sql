@@ -0,0 +1,2 @@
+-- table without primary key and comment
+CREATE TABLE t2 (id INT);
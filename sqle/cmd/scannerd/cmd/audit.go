@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/auditor"
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/pkg/report"
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// exitCodeAuditFailed is the exit code when the level of audit result is
+// more than or equal to the fail level, it is different from the exit code
+// of other errors.
+const exitCodeAuditFailed = 2
+
+var (
+	ruleTemplateFile string
+	reportFormat     string
+	reportOutput     string
+	failLevel        string
+
+	auditCmd = &cobra.Command{
+		Use:   "audit [SQL file or directory...]",
+		Short: "Audit SQL files offline by MySQL rules",
+		Long: "Audit SQL files offline by MySQL rules, it does not connect to SQLE server or database.\n" +
+			"The SQL is read from stdin if no file is specified or the file is \"-\".",
+		Annotations: map[string]string{annotationOffline: ""},
+		Run: func(cmd *cobra.Command, args []string) {
+			if _, ok := map[driver.RuleLevel]struct{}{
+				driver.RuleLevelNormal: {},
+				driver.RuleLevelNotice: {},
+				driver.RuleLevelWarn:   {},
+				driver.RuleLevelError:  {},
+			}[driver.RuleLevel(failLevel)]; !ok {
+				fmt.Fprintln(os.Stderr, color.RedString("invalid fail level %s", failLevel))
+				os.Exit(1)
+			}
+
+			log := logrus.WithField("command", "audit")
+			// the log is written to stderr, so that the report in stdout can be consumed by other tools.
+			logrus.SetOutput(os.Stderr)
+			r, err := auditor.Audit(log, &auditor.Params{
+				RuleTemplateFile: ruleTemplateFile,
+				Paths:            args,
+				Stdin:            os.Stdin,
+			})
+			if err != nil {
+				fmt.Fprintln(os.Stderr, color.RedString(err.Error()))
+				os.Exit(1)
+			}
+
+			if err := writeReport(r); err != nil {
+				fmt.Fprintln(os.Stderr, color.RedString(err.Error()))
+				os.Exit(1)
+			}
+
+			level := r.Level()
+			if level != driver.RuleLevelNull && level.MoreOrEqual(driver.RuleLevel(failLevel)) {
+				fmt.Fprintln(os.Stderr, color.RedString("audit failed, the level of audit result is %s", level))
+				os.Exit(exitCodeAuditFailed)
+			}
+		},
+	}
+)
+
+func writeReport(r *report.Report) error {
+	var w io.Writer = os.Stdout
+	if reportOutput != "" {
+		f, err := os.Create(reportOutput)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	return report.Write(w, reportFormat, r, driver.RuleLevel(failLevel))
+}
+
+func init() {
+	auditCmd.Flags().StringVarP(&ruleTemplateFile, "rule-template", "R", "", "exported rule template file in YAML or JSON, all MySQL rules are used if it is not set")
+	auditCmd.Flags().StringVarP(&reportFormat, "format", "F", report.FormatText, fmt.Sprintf("report format, one of %s", strings.Join(report.Formats, ", ")))
+	auditCmd.Flags().StringVarP(&reportOutput, "output", "O", "", "report file, the report is written to stdout if it is not set")
+	auditCmd.Flags().StringVarP(&failLevel, "fail-level", "L", string(driver.RuleLevelError), "exit with code 2 if the level of audit result is more than or equal to it, one of normal, notice, warn, error")
+	rootCmd.AddCommand(auditCmd)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners/flyway"
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners/supervisor"
+	"github.com/actiontech/sqle/sqle/pkg/scanner"
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrationDir string
+
+	flywayCmd = &cobra.Command{
+		Use:   "flyway",
+		Short: "Parse Flyway versioned migrations",
+		Run: func(cmd *cobra.Command, args []string) {
+			param := &flyway.Params{
+				MigrationDir: migrationDir,
+				APName:       rootCmdFlags.auditPlanName,
+			}
+			log := logrus.WithField("scanner", "flyway")
+			client := scanner.NewSQLEClient(time.Second, rootCmdFlags.host, rootCmdFlags.port).WithToken(rootCmdFlags.token)
+			scanner, err := flyway.New(param, log, client)
+			if err != nil {
+				fmt.Println(color.RedString(err.Error()))
+				os.Exit(1)
+			}
+
+			err = supervisor.Start(context.TODO(), scanner, 30, 1024)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func init() {
+	flywayCmd.Flags().StringVarP(&migrationDir, "dir", "D", "", "migration directory, such as db/migration")
+	_ = flywayCmd.MarkFlagRequired("dir")
+	rootCmd.AddCommand(flywayCmd)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners/golang"
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners/supervisor"
+	"github.com/actiontech/sqle/sqle/pkg/scanner"
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	sourceDir string
+
+	golangCmd = &cobra.Command{
+		Use:   "golang",
+		Short: "Parse Go source files using database/sql",
+		Run: func(cmd *cobra.Command, args []string) {
+			param := &golang.Params{
+				SourceDir: sourceDir,
+				APName:    rootCmdFlags.auditPlanName,
+			}
+			log := logrus.WithField("scanner", "golang")
+			client := scanner.NewSQLEClient(time.Second, rootCmdFlags.host, rootCmdFlags.port).WithToken(rootCmdFlags.token)
+			scanner, err := golang.New(param, log, client)
+			if err != nil {
+				fmt.Println(color.RedString(err.Error()))
+				os.Exit(1)
+			}
+
+			err = supervisor.Start(context.TODO(), scanner, 30, 1024)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func init() {
+	golangCmd.Flags().StringVarP(&sourceDir, "dir", "D", "", "source code directory")
+	_ = golangCmd.MarkFlagRequired("dir")
+	rootCmd.AddCommand(golangCmd)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners/liquibase"
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners/supervisor"
+	"github.com/actiontech/sqle/sqle/pkg/scanner"
+	"github.com/fatih/color"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var (
+	changelogFile string
+
+	liquibaseCmd = &cobra.Command{
+		Use:   "liquibase",
+		Short: "Parse Liquibase changelog in XML, YAML or formatted SQL",
+		Run: func(cmd *cobra.Command, args []string) {
+			param := &liquibase.Params{
+				ChangelogFile: changelogFile,
+				APName:        rootCmdFlags.auditPlanName,
+			}
+			log := logrus.WithField("scanner", "liquibase")
+			client := scanner.NewSQLEClient(time.Second, rootCmdFlags.host, rootCmdFlags.port).WithToken(rootCmdFlags.token)
+			scanner, err := liquibase.New(param, log, client)
+			if err != nil {
+				fmt.Println(color.RedString(err.Error()))
+				os.Exit(1)
+			}
+
+			err = supervisor.Start(context.TODO(), scanner, 30, 1024)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+		},
+	}
+)
+
+func init() {
+	liquibaseCmd.Flags().StringVarP(&changelogFile, "changelog", "C", "", "root changelog file, such as db.changelog-master.xml")
+	_ = liquibaseCmd.MarkFlagRequired("changelog")
+	rootCmd.AddCommand(liquibaseCmd)
+}
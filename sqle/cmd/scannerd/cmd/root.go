@@ -1,6 +1,14 @@
 package cmd
 
-import "github.com/spf13/cobra"
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// annotationOffline marks the command which does not connect to SQLE
+// server, the flags of SQLE server are not required by it.
+const annotationOffline = "offline"
 
 var (
 	rootCmdFlags struct {
@@ -13,6 +21,17 @@ var (
 	rootCmd = &cobra.Command{
 		Use:   "SQLE Scanner",
 		Short: "SQLE Scanner",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if _, ok := cmd.Annotations[annotationOffline]; ok {
+				return nil
+			}
+			for _, name := range []string{"name", "token"} {
+				if !cmd.Flags().Changed(name) {
+					return fmt.Errorf("required flag(s) \"%s\" not set", name)
+				}
+			}
+			return nil
+		},
 	}
 )
 
@@ -21,8 +40,6 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&rootCmdFlags.port, "port", "P", "10000", "sqle port")
 	rootCmd.PersistentFlags().StringVarP(&rootCmdFlags.auditPlanName, "name", "N", "", "audit plan name")
 	rootCmd.PersistentFlags().StringVarP(&rootCmdFlags.token, "token", "A", "", "sqle token")
-	_ = rootCmd.MarkPersistentFlagRequired("name")
-	_ = rootCmd.MarkPersistentFlagRequired("token")
 }
 
 func Execute() error {
@@ -5,15 +5,20 @@ import (
 	"os"
 
 	"github.com/actiontech/sqle/sqle/cmd/scannerd/cmd"
+	"github.com/actiontech/sqle/sqle/config"
 
 	"github.com/fatih/color"
 )
 
+// version is set by ldflags on build.
+var version string
+
 func main() {
+	config.Version = version
 	var code int
 	err := cmd.Execute()
 	if err != nil {
-		fmt.Println(color.RedString("Error: %v", err))
+		fmt.Fprintln(os.Stderr, color.RedString("Error: %v", err))
 		code = 1
 	}
 
@@ -0,0 +1,110 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners"
+	"github.com/actiontech/sqle/sqle/pkg/scanner"
+	"github.com/sirupsen/logrus"
+)
+
+// CollectFunc collects all SQLs from the source files.
+type CollectFunc func() ([]scanners.SQL, error)
+
+// FileScanner collects SQLs from source files once, then uploads all of them
+// and triggers the audit when the last batch is received. The scanners of
+// different source files only need to implement CollectFunc.
+type FileScanner struct {
+	l *logrus.Entry
+	c *scanner.Client
+
+	apName  string
+	collect CollectFunc
+
+	allSQL []scanners.SQL
+	getAll chan struct{}
+	sqls   []scanners.SQL
+}
+
+func NewFileScanner(apName string, collect CollectFunc, l *logrus.Entry, c *scanner.Client) *FileScanner {
+	return &FileScanner{
+		l:       l,
+		c:       c,
+		apName:  apName,
+		collect: collect,
+		getAll:  make(chan struct{}),
+	}
+}
+
+func (fs *FileScanner) Run(ctx context.Context) error {
+	sqls, err := fs.collect()
+	if err != nil {
+		return err
+	}
+	fs.l.Infof("collected %d SQLs", len(sqls))
+
+	fs.allSQL = sqls
+	close(fs.getAll)
+
+	<-ctx.Done()
+	return nil
+}
+
+func (fs *FileScanner) SQLs() <-chan scanners.SQL {
+	// todo: channel size configurable
+	sqlCh := make(chan scanners.SQL, 10240)
+
+	go func() {
+		<-fs.getAll
+		for _, sql := range fs.allSQL {
+			sqlCh <- sql
+		}
+		close(sqlCh)
+	}()
+	return sqlCh
+}
+
+func (fs *FileScanner) Upload(ctx context.Context, sqls []scanners.SQL) error {
+	fs.sqls = append(fs.sqls, sqls...)
+
+	// the audit is triggered after all SQLs are uploaded.
+	if len(fs.sqls) < len(fs.allSQL) {
+		return nil
+	}
+
+	// the location of SQL is the first one which matches the fingerprint.
+	counterMap := make(map[string]uint, len(fs.sqls))
+	sqlList := make([]scanners.SQL, 0, len(fs.sqls))
+	for _, sql := range fs.sqls {
+		counterMap[sql.Fingerprint]++
+		if counterMap[sql.Fingerprint] <= 1 {
+			sqlList = append(sqlList, sql)
+		}
+	}
+
+	reqBody := make([]scanner.AuditPlanSQLReq, 0, len(sqlList))
+	now := time.Now().Format(time.RFC3339)
+	for _, sql := range sqlList {
+		reqBody = append(reqBody, scanner.AuditPlanSQLReq{
+			Fingerprint:          sql.Fingerprint,
+			Counter:              fmt.Sprintf("%v", counterMap[sql.Fingerprint]),
+			LastReceiveText:      sql.RawText,
+			LastReceiveTimestamp: now,
+			FilePath:             sql.FilePath,
+			Line:                 sql.Line,
+		})
+	}
+
+	err := fs.c.UploadReq(scanner.FullUpload, fs.apName, reqBody)
+	if err != nil {
+		return err
+	}
+
+	reportID, err := fs.c.TriggerAuditReq(fs.apName)
+	if err != nil {
+		return err
+	}
+	return fs.c.GetAuditReportReq(fs.apName, reportID)
+}
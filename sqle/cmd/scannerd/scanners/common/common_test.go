@@ -0,0 +1,75 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners"
+	"github.com/actiontech/sqle/sqle/pkg/scanner"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileScanner(t *testing.T) {
+	var uploaded scanner.FullSyncAuditPlanSQLsReq
+	triggered := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/v1/audit_plans/ap1/sqls/full":
+			body, _ := ioutil.ReadAll(r.Body)
+			assert.NoError(t, json.Unmarshal(body, &uploaded))
+			_, _ = w.Write([]byte(`{"code":0,"message":"ok"}`))
+		case r.URL.Path == "/v1/audit_plans/ap1/trigger":
+			triggered++
+			_, _ = w.Write([]byte(`{"code":0,"message":"ok","data":{"audit_plan_report_id":"1"}}`))
+		case strings.HasPrefix(r.URL.Path, "/v2/audit_plans/ap1/report/1"):
+			_, _ = w.Write([]byte(`{"code":0,"message":"ok","data":[],"total_nums":0}`))
+		default:
+			t.Errorf("unexpected request %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	addr := strings.Split(server.URL, ":")
+	client := scanner.NewSQLEClient(time.Second, addr[0]+":"+addr[1], addr[2])
+
+	sqls, err := ParseSQL("V1__init.sql", "-- init\nselect * from t1 where id = 1;\n\nselect * from t1 where id = 2;\ndelete from t1;", 1)
+	if !assert.NoError(t, err) || !assert.Len(t, sqls, 3) {
+		t.FailNow()
+	}
+	assert.Equal(t, []int{2, 4, 5}, []int{sqls[0].Line, sqls[1].Line, sqls[2].Line})
+
+	fs := NewFileScanner("ap1", func() ([]scanners.SQL, error) {
+		return sqls, nil
+	}, logrus.NewEntry(logrus.New()), client)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		assert.NoError(t, fs.Run(ctx))
+	}()
+
+	var received []scanners.SQL
+	for sql := range fs.SQLs() {
+		received = append(received, sql)
+	}
+
+	// the audit is not triggered until all SQLs are uploaded.
+	assert.NoError(t, fs.Upload(context.TODO(), received[:1]))
+	assert.Equal(t, 0, triggered)
+	assert.NoError(t, fs.Upload(context.TODO(), received[1:]))
+	assert.Equal(t, 1, triggered)
+
+	if !assert.Len(t, uploaded.SQLs, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, "SELECT * FROM `t1` WHERE `id`=?", uploaded.SQLs[0].Fingerprint)
+	assert.Equal(t, "2", uploaded.SQLs[0].Counter)
+	assert.Equal(t, "V1__init.sql", uploaded.SQLs[0].FilePath)
+	assert.Equal(t, 2, uploaded.SQLs[0].Line)
+	assert.Equal(t, 5, uploaded.SQLs[1].Line)
+}
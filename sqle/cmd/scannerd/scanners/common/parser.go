@@ -0,0 +1,37 @@
+package common
+
+import (
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners"
+	"github.com/actiontech/sqle/sqle/driver/mysql/util"
+)
+
+// ParseSQL splits the SQL text in source file into statements. The text
+// starts at the line startLine of file, the line of each statement is
+// calculated from its position in the text.
+func ParseSQL(filePath, text string, startLine int) ([]scanners.SQL, error) {
+	stmts, err := util.SplitSqlWithLine(text, startLine)
+	if err != nil {
+		return nil, err
+	}
+
+	sqls := make([]scanners.SQL, 0, len(stmts))
+	for _, stmt := range stmts {
+		sqls = append(sqls, scanners.SQL{
+			Fingerprint: Fingerprint(stmt.Text),
+			RawText:     stmt.Text,
+			FilePath:    filePath,
+			Line:        stmt.Line,
+		})
+	}
+	return sqls, nil
+}
+
+// Fingerprint returns the fingerprint of SQL, it returns the SQL itself if
+// the SQL can not be parsed, such as the SQL is not written in MySQL syntax.
+func Fingerprint(sql string) string {
+	fp, err := util.Fingerprint(sql, true)
+	if err != nil {
+		return sql
+	}
+	return fp
+}
@@ -0,0 +1,62 @@
+package flyway
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners"
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners/common"
+	"github.com/actiontech/sqle/sqle/pkg/scanner"
+	"github.com/sirupsen/logrus"
+)
+
+type Params struct {
+	MigrationDir string
+	APName       string
+}
+
+// versionedMigrationReg matches the file name of Flyway versioned migration,
+// such as "V1__init.sql" and "V1.1__add_column.sql".
+var versionedMigrationReg = regexp.MustCompile(`^V[^_]+__.*\.sql$`)
+
+// New returns a scanner which extracts SQLs from the versioned migrations of
+// Flyway, the migrations in subdirectories are also scanned.
+func New(params *Params, l *logrus.Entry, c *scanner.Client) (*common.FileScanner, error) {
+	return common.NewFileScanner(params.APName, func() ([]scanners.SQL, error) {
+		return GetSQLFromPath(params.MigrationDir)
+	}, l, c), nil
+}
+
+// GetSQLFromPath returns the SQLs of all versioned migrations in directory,
+// the file path of SQL is relative to the directory.
+func GetSQLFromPath(dir string) ([]scanners.SQL, error) {
+	var allSQL []scanners.SQL
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || !versionedMigrationReg.MatchString(info.Name()) {
+			return nil
+		}
+		content, err := ioutil.ReadFile(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		sqls, err := common.ParseSQL(filepath.ToSlash(relPath), string(content), 1)
+		if err != nil {
+			return err
+		}
+		allSQL = append(allSQL, sqls...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return allSQL, nil
+}
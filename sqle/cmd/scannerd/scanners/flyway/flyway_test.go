@@ -0,0 +1,28 @@
+package flyway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSQLFromPath(t *testing.T) {
+	_, err := GetSQLFromPath("./not-exist-directory/")
+	assert.Error(t, err)
+
+	sqls, err := GetSQLFromPath("./testdata/db/migration")
+	if !assert.NoError(t, err) || !assert.Len(t, sqls, 3) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, "V1__init.sql", sqls[0].FilePath)
+	assert.Equal(t, 2, sqls[0].Line)
+	assert.Contains(t, sqls[0].RawText, "CREATE TABLE users")
+
+	assert.Equal(t, "V1__init.sql", sqls[1].FilePath)
+	assert.Equal(t, 8, sqls[1].Line)
+	assert.Equal(t, "INSERT INTO `users` (`name`) VALUES (?)", sqls[1].Fingerprint)
+
+	assert.Equal(t, "v2/V1.1__add_email.sql", sqls[2].FilePath)
+	assert.Equal(t, 1, sqls[2].Line)
+}
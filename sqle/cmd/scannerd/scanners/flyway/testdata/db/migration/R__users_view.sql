@@ -0,0 +1 @@
+CREATE OR REPLACE VIEW v_users AS SELECT id, name FROM users;
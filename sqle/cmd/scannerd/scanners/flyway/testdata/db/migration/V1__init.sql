@@ -0,0 +1,8 @@
+-- the initial schema
+CREATE TABLE users (
+  id BIGINT NOT NULL AUTO_INCREMENT,
+  name VARCHAR(64) NOT NULL,
+  PRIMARY KEY (id)
+);
+
+INSERT INTO users (name) VALUES ('admin');
@@ -0,0 +1,222 @@
+package golang
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners"
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners/common"
+	"github.com/actiontech/sqle/sqle/pkg/scanner"
+	"github.com/sirupsen/logrus"
+)
+
+type Params struct {
+	SourceDir string
+	APName    string
+}
+
+// New returns a scanner which extracts SQLs from the Go source files using
+// "database/sql", the test files and vendor directories are skipped.
+func New(params *Params, l *logrus.Entry, c *scanner.Client) (*common.FileScanner, error) {
+	return common.NewFileScanner(params.APName, func() ([]scanners.SQL, error) {
+		return GetSQLFromPath(l, params.SourceDir)
+	}, l, c), nil
+}
+
+// sqlArgIndex is the index of SQL argument of the methods of *sql.DB,
+// *sql.Tx and *sql.Conn.
+var sqlArgIndex = map[string]int{
+	"Exec":            0,
+	"Query":           0,
+	"QueryRow":        0,
+	"Prepare":         0,
+	"ExecContext":     1,
+	"QueryContext":    1,
+	"QueryRowContext": 1,
+	"PrepareContext":  1,
+}
+
+// sqlKeywords is used to filter out the calls of other methods with the same
+// name, such as "Exec" of a command executor.
+var sqlKeywords = map[string]struct{}{
+	"SELECT": {}, "INSERT": {}, "UPDATE": {}, "DELETE": {}, "REPLACE": {},
+	"CREATE": {}, "ALTER": {}, "DROP": {}, "TRUNCATE": {}, "RENAME": {},
+	"WITH": {}, "CALL": {}, "SHOW": {}, "SET": {}, "GRANT": {}, "REVOKE": {},
+	"LOCK": {}, "UNLOCK": {}, "EXPLAIN": {}, "DESC": {}, "DESCRIBE": {},
+}
+
+// GetSQLFromPath returns the constant SQLs passed to "database/sql" in the Go
+// source files of directory, the file path of SQL is relative to the directory.
+// The files which can not be parsed are logged and skipped.
+func GetSQLFromPath(l *logrus.Entry, dir string) ([]scanners.SQL, error) {
+	pkgFiles := map[string][]string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != dir && (info.Name() == "vendor" || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(info.Name(), ".go") && !strings.HasSuffix(info.Name(), "_test.go") {
+			pkgDir := filepath.Dir(path)
+			pkgFiles[pkgDir] = append(pkgFiles[pkgDir], path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pkgDirs := make([]string, 0, len(pkgFiles))
+	for pkgDir := range pkgFiles {
+		pkgDirs = append(pkgDirs, pkgDir)
+	}
+	sort.Strings(pkgDirs)
+
+	var allSQL []scanners.SQL
+	for _, pkgDir := range pkgDirs {
+		sqls, err := getSQLFromPackage(l, dir, pkgFiles[pkgDir])
+		if err != nil {
+			return nil, err
+		}
+		allSQL = append(allSQL, sqls...)
+	}
+	return allSQL, nil
+}
+
+// getSQLFromPackage parses the files of a package together, so that the
+// constants declared in other files of package can be resolved.
+func getSQLFromPackage(l *logrus.Entry, rootDir string, files []string) ([]scanners.SQL, error) {
+	fset := token.NewFileSet()
+	astFiles := make([]*ast.File, 0, len(files))
+	parsedFiles := make([]string, 0, len(files))
+	for _, file := range files {
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			l.Warnf("skip Go file %s, parse failed: %v", file, err)
+			continue
+		}
+		astFiles = append(astFiles, f)
+		parsedFiles = append(parsedFiles, file)
+	}
+
+	consts := map[string]ast.Expr{}
+	for _, f := range astFiles {
+		ast.Inspect(f, func(n ast.Node) bool {
+			decl, ok := n.(*ast.GenDecl)
+			if !ok || decl.Tok != token.CONST {
+				return true
+			}
+			for _, spec := range decl.Specs {
+				//nolint:forcetypeassert
+				vs := spec.(*ast.ValueSpec)
+				for i, name := range vs.Names {
+					if i < len(vs.Values) {
+						consts[name.Name] = vs.Values[i]
+					}
+				}
+			}
+			return true
+		})
+	}
+	r := &constResolver{consts: consts, resolving: map[string]bool{}}
+
+	var sqls []scanners.SQL
+	for i, f := range astFiles {
+		relPath, err := filepath.Rel(rootDir, parsedFiles[i])
+		if err != nil {
+			return nil, err
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			idx, ok := sqlArgIndex[sel.Sel.Name]
+			if !ok || idx >= len(call.Args) {
+				return true
+			}
+			sql, ok := r.resolve(call.Args[idx])
+			if !ok || !isSQL(sql) {
+				return true
+			}
+			sql = strings.TrimSpace(sql)
+			sqls = append(sqls, scanners.SQL{
+				Fingerprint: common.Fingerprint(sql),
+				RawText:     sql,
+				FilePath:    filepath.ToSlash(relPath),
+				Line:        fset.Position(call.Args[idx].Pos()).Line,
+			})
+			return true
+		})
+	}
+	return sqls, nil
+}
+
+// constResolver resolves the value of constant string expression, which is
+// a string literal, a constant, or the concatenation of them.
+type constResolver struct {
+	consts map[string]ast.Expr
+	// resolving is used to avoid the endless recursion of invalid code.
+	resolving map[string]bool
+}
+
+func (r *constResolver) resolve(expr ast.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(e.Value)
+		if err != nil {
+			return "", false
+		}
+		return s, true
+	case *ast.Ident:
+		value, ok := r.consts[e.Name]
+		if !ok || r.resolving[e.Name] {
+			return "", false
+		}
+		r.resolving[e.Name] = true
+		defer delete(r.resolving, e.Name)
+		return r.resolve(value)
+	case *ast.ParenExpr:
+		return r.resolve(e.X)
+	case *ast.BinaryExpr:
+		if e.Op != token.ADD {
+			return "", false
+		}
+		x, ok := r.resolve(e.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := r.resolve(e.Y)
+		if !ok {
+			return "", false
+		}
+		return x + y, true
+	}
+	return "", false
+}
+
+func isSQL(s string) bool {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return false
+	}
+	_, ok := sqlKeywords[strings.ToUpper(fields[0])]
+	return ok
+}
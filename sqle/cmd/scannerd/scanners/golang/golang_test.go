@@ -0,0 +1,31 @@
+package golang
+
+import (
+	"testing"
+
+	"github.com/actiontech/sqle/sqle/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSQLFromPath(t *testing.T) {
+	_, err := GetSQLFromPath(log.NewEntry(), "./not-exist-directory/")
+	assert.Error(t, err)
+
+	// the file which can not be parsed is skipped.
+	sqls, err := GetSQLFromPath(log.NewEntry(), "./testdata")
+	if !assert.NoError(t, err) || !assert.Len(t, sqls, 3) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, "dao/user.go", sqls[0].FilePath)
+	assert.Equal(t, 10, sqls[0].Line)
+	assert.Equal(t, "SELECT id, name FROM users WHERE id = ?", sqls[0].RawText)
+
+	assert.Equal(t, "dao/user.go", sqls[1].FilePath)
+	assert.Equal(t, 18, sqls[1].Line)
+	assert.Equal(t, "UPDATE `users` SET `name`=? WHERE `id`=?", sqls[1].Fingerprint)
+
+	assert.Equal(t, "dao/user.go", sqls[2].FilePath)
+	assert.Equal(t, 28, sqls[2].Line)
+	assert.Equal(t, "DELETE FROM users WHERE id > ?", sqls[2].RawText)
+}
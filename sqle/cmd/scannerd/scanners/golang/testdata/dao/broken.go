@@ -0,0 +1,8 @@
+package dao
+
+import "database/sql"
+
+func DeleteAll(db *sql.DB) error {
+	_, err := db.Exec("DELETE FROM users"
+	return err
+}
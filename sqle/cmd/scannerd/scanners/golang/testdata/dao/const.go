@@ -0,0 +1,7 @@
+package dao
+
+const (
+	tableUsers = "users"
+
+	selectUserByID = "SELECT id, name FROM " + tableUsers + " WHERE id = ?"
+)
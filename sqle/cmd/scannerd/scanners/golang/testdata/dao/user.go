@@ -0,0 +1,34 @@
+package dao
+
+import (
+	"context"
+	"database/sql"
+	"os/exec"
+)
+
+func GetUser(ctx context.Context, db *sql.DB, id int64) error {
+	row := db.QueryRowContext(ctx, selectUserByID, id)
+	return row.Err()
+}
+
+func UpdateUser(tx *sql.Tx, name string, id int64) error {
+	const updateUser = `UPDATE users
+SET name = ?
+WHERE id = ?`
+	_, err := tx.Exec(updateUser, name, id)
+	return err
+}
+
+func DeleteUsers(db *sql.DB, query string) error {
+	// the SQL which is not constant can not be extracted.
+	_, err := db.Exec(query)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec("DELETE FROM users WHERE id > ?", 100)
+	return err
+}
+
+func Run() error {
+	return exec.Command("ls").Run()
+}
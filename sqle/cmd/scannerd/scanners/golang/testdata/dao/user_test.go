@@ -0,0 +1,7 @@
+package dao
+
+const testSQL = "SELECT 1"
+
+func init() {
+	_ = testSQL
+}
@@ -0,0 +1,208 @@
+package liquibase
+
+import (
+	"fmt"
+	"strings"
+)
+
+// change is a change type in changeset, such as "createTable" and "sql".
+type change struct {
+	name    string
+	attrs   map[string]string
+	columns []*column
+	// text is the SQL of "sql" and the query of "createView".
+	text string
+
+	// line is the line of change in changelog, textLine is the line where the text starts.
+	line     int
+	textLine int
+}
+
+type column struct {
+	attrs       map[string]string
+	constraints map[string]string
+}
+
+func newChange(name string, line int) *change {
+	return &change{
+		name:  name,
+		attrs: map[string]string{},
+		line:  line,
+	}
+}
+
+func newColumn() *column {
+	return &column{
+		attrs:       map[string]string{},
+		constraints: map[string]string{},
+	}
+}
+
+// generateSQL generates the MySQL statement of change, it returns false if
+// the change type is not supported.
+func generateSQL(c *change) (string, bool) {
+	table := tableName(c.attrs["schemaName"], c.attrs["tableName"])
+	switch c.name {
+	case "createTable":
+		defs := make([]string, 0, len(c.columns)+1)
+		var pks []string
+		for _, col := range c.columns {
+			defs = append(defs, columnDefinition(col))
+			if isTrue(col.constraints["primaryKey"]) {
+				pks = append(pks, quoteName(col.attrs["name"]))
+			}
+		}
+		if len(pks) > 0 {
+			defs = append(defs, fmt.Sprintf("PRIMARY KEY (%s)", strings.Join(pks, ", ")))
+		}
+		sql := fmt.Sprintf("CREATE TABLE %s (\n  %s\n)", table, strings.Join(defs, ",\n  "))
+		if remarks, ok := c.attrs["remarks"]; ok {
+			sql += " COMMENT=" + quoteString(remarks)
+		}
+		return sql, true
+	case "dropTable":
+		return fmt.Sprintf("DROP TABLE %s", table), true
+	case "renameTable":
+		return fmt.Sprintf("RENAME TABLE %s TO %s",
+			tableName(c.attrs["schemaName"], c.attrs["oldTableName"]),
+			tableName(c.attrs["schemaName"], c.attrs["newTableName"])), true
+	case "addColumn":
+		specs := make([]string, 0, len(c.columns))
+		for _, col := range c.columns {
+			spec := "ADD COLUMN " + columnDefinition(col)
+			if after, ok := col.attrs["afterColumn"]; ok {
+				spec += " AFTER " + quoteName(after)
+			}
+			specs = append(specs, spec)
+		}
+		return alterTable(table, specs)
+	case "dropColumn":
+		var specs []string
+		if name, ok := c.attrs["columnName"]; ok {
+			specs = append(specs, "DROP COLUMN "+quoteName(name))
+		}
+		for _, col := range c.columns {
+			specs = append(specs, "DROP COLUMN "+quoteName(col.attrs["name"]))
+		}
+		return alterTable(table, specs)
+	case "renameColumn":
+		if dataType, ok := c.attrs["columnDataType"]; ok {
+			return fmt.Sprintf("ALTER TABLE %s CHANGE COLUMN %s %s %s", table,
+				quoteName(c.attrs["oldColumnName"]), quoteName(c.attrs["newColumnName"]), dataType), true
+		}
+		return fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table,
+			quoteName(c.attrs["oldColumnName"]), quoteName(c.attrs["newColumnName"])), true
+	case "modifyDataType":
+		return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s", table,
+			quoteName(c.attrs["columnName"]), c.attrs["newDataType"]), true
+	case "addNotNullConstraint":
+		dataType, ok := c.attrs["columnDataType"]
+		if !ok {
+			return "", false
+		}
+		return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s %s NOT NULL", table,
+			quoteName(c.attrs["columnName"]), dataType), true
+	case "createIndex":
+		columns := make([]string, 0, len(c.columns))
+		for _, col := range c.columns {
+			columns = append(columns, quoteName(col.attrs["name"]))
+		}
+		unique := ""
+		if isTrue(c.attrs["unique"]) {
+			unique = "UNIQUE "
+		}
+		return fmt.Sprintf("CREATE %sINDEX %s ON %s (%s)", unique,
+			quoteName(c.attrs["indexName"]), table, strings.Join(columns, ", ")), true
+	case "dropIndex":
+		return fmt.Sprintf("DROP INDEX %s ON %s", quoteName(c.attrs["indexName"]), table), true
+	case "addPrimaryKey":
+		return fmt.Sprintf("ALTER TABLE %s ADD %sPRIMARY KEY (%s)", table,
+			constraintName(c.attrs["constraintName"]), quoteNames(c.attrs["columnNames"])), true
+	case "addUniqueConstraint":
+		return fmt.Sprintf("ALTER TABLE %s ADD %sUNIQUE (%s)", table,
+			constraintName(c.attrs["constraintName"]), quoteNames(c.attrs["columnNames"])), true
+	case "addForeignKeyConstraint":
+		return fmt.Sprintf("ALTER TABLE %s ADD %sFOREIGN KEY (%s) REFERENCES %s (%s)",
+			tableName(c.attrs["baseTableSchemaName"], c.attrs["baseTableName"]),
+			constraintName(c.attrs["constraintName"]), quoteNames(c.attrs["baseColumnNames"]),
+			tableName(c.attrs["referencedTableSchemaName"], c.attrs["referencedTableName"]),
+			quoteNames(c.attrs["referencedColumnNames"])), true
+	case "dropForeignKeyConstraint":
+		return fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s",
+			tableName(c.attrs["baseTableSchemaName"], c.attrs["baseTableName"]),
+			quoteName(c.attrs["constraintName"])), true
+	case "createView":
+		view := tableName(c.attrs["schemaName"], c.attrs["viewName"])
+		replace := ""
+		if isTrue(c.attrs["replaceIfExists"]) {
+			replace = "OR REPLACE "
+		}
+		return fmt.Sprintf("CREATE %sVIEW %s AS %s", replace, view, strings.TrimSpace(c.text)), true
+	case "dropView":
+		return fmt.Sprintf("DROP VIEW %s", tableName(c.attrs["schemaName"], c.attrs["viewName"])), true
+	}
+	return "", false
+}
+
+func alterTable(table string, specs []string) (string, bool) {
+	if len(specs) == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("ALTER TABLE %s %s", table, strings.Join(specs, ", ")), true
+}
+
+func columnDefinition(col *column) string {
+	def := quoteName(col.attrs["name"]) + " " + col.attrs["type"]
+	if v, ok := col.constraints["nullable"]; ok && !isTrue(v) {
+		def += " NOT NULL"
+	}
+	if v, ok := col.attrs["defaultValue"]; ok {
+		def += " DEFAULT " + quoteString(v)
+	}
+	for _, key := range []string{"defaultValueNumeric", "defaultValueBoolean", "defaultValueComputed"} {
+		if v, ok := col.attrs[key]; ok {
+			def += " DEFAULT " + v
+		}
+	}
+	if isTrue(col.attrs["autoIncrement"]) {
+		def += " AUTO_INCREMENT"
+	}
+	if isTrue(col.constraints["unique"]) {
+		def += " UNIQUE"
+	}
+	if v, ok := col.attrs["remarks"]; ok {
+		def += " COMMENT " + quoteString(v)
+	}
+	return def
+}
+
+func constraintName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return "CONSTRAINT " + quoteName(name) + " "
+}
+
+func tableName(schema, table string) string {
+	if schema == "" {
+		return quoteName(table)
+	}
+	return quoteName(schema) + "." + quoteName(table)
+}
+
+func quoteName(name string) string {
+	return "`" + strings.ReplaceAll(strings.TrimSpace(name), "`", "``") + "`"
+}
+
+// quoteNames quotes the names separated by comma, such as "id, name".
+func quoteNames(names string) string {
+	parts := strings.Split(names, ",")
+	for i, part := range parts {
+		parts[i] = quoteName(part)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func quoteString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,182 @@
+package liquibase
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners"
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners/common"
+	"github.com/actiontech/sqle/sqle/pkg/scanner"
+	"github.com/sirupsen/logrus"
+)
+
+type Params struct {
+	ChangelogFile string
+	APName        string
+}
+
+// New returns a scanner which extracts SQLs from the Liquibase changelog, the
+// changelogs included by "include" and "includeAll" are also scanned.
+func New(params *Params, l *logrus.Entry, c *scanner.Client) (*common.FileScanner, error) {
+	return common.NewFileScanner(params.APName, func() ([]scanners.SQL, error) {
+		return GetSQLFromChangelog(params.ChangelogFile)
+	}, l, c), nil
+}
+
+// GetSQLFromChangelog returns the SQLs of changelog in XML, YAML or formatted
+// SQL. The file path of SQL is relative to the directory of the changelog.
+func GetSQLFromChangelog(changelogFile string) ([]scanners.SQL, error) {
+	p := &changelogParser{
+		rootDir: filepath.Dir(changelogFile),
+		visited: map[string]struct{}{},
+	}
+	if err := p.parseFile(changelogFile); err != nil {
+		return nil, err
+	}
+	return p.sqls, nil
+}
+
+type changelogParser struct {
+	rootDir string
+	// visited is used to avoid including a changelog repeatedly.
+	visited map[string]struct{}
+	sqls    []scanners.SQL
+}
+
+func (p *changelogParser) parseFile(file string) error {
+	absPath, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+	if _, ok := p.visited[absPath]; ok {
+		return nil
+	}
+	p.visited[absPath] = struct{}{}
+
+	content, err := ioutil.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".xml":
+		err = p.parseXML(file, content)
+	case ".yaml", ".yml":
+		err = p.parseYAML(file, content)
+	case ".sql":
+		err = p.parseSQLFile(file, content)
+	default:
+		return fmt.Errorf("unsupported changelog format: %s", file)
+	}
+	if err != nil {
+		return fmt.Errorf("parse changelog %s failed: %v", file, err)
+	}
+	return nil
+}
+
+func (p *changelogParser) relPath(file string) string {
+	relPath, err := filepath.Rel(p.rootDir, file)
+	if err != nil {
+		return filepath.ToSlash(file)
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// resolvePath returns the path of file which is referenced in changelog. The
+// path is relative to the changelog if relativeToChangelogFile is true,
+// otherwise it is relative to the search path, which is the directory of the
+// root changelog here.
+func (p *changelogParser) resolvePath(changelogFile, path string, relativeToChangelogFile bool) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	if relativeToChangelogFile {
+		return filepath.Join(filepath.Dir(changelogFile), path)
+	}
+	rootPath := filepath.Join(p.rootDir, path)
+	if _, err := os.Stat(rootPath); err == nil {
+		return rootPath
+	}
+	return filepath.Join(filepath.Dir(changelogFile), path)
+}
+
+func (p *changelogParser) include(changelogFile string, attrs map[string]string) error {
+	return p.parseFile(p.resolvePath(changelogFile, attrs["file"], isTrue(attrs["relativeToChangelogFile"])))
+}
+
+// includeAll includes all changelogs in directory in alphabetical order.
+func (p *changelogParser) includeAll(changelogFile string, attrs map[string]string) error {
+	dir := p.resolvePath(changelogFile, attrs["path"], isTrue(attrs["relativeToChangelogFile"]))
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".xml", ".yaml", ".yml", ".sql":
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		if err := p.parseFile(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseSQLFile parses the formatted SQL changelog, the changeset is defined
+// by comments, so it can be parsed as a normal SQL file.
+func (p *changelogParser) parseSQLFile(file string, content []byte) error {
+	sqls, err := common.ParseSQL(p.relPath(file), string(content), 1)
+	if err != nil {
+		return err
+	}
+	p.sqls = append(p.sqls, sqls...)
+	return nil
+}
+
+func (p *changelogParser) addChange(changelogFile string, c *change) error {
+	switch c.name {
+	case "sql":
+		sqls, err := common.ParseSQL(p.relPath(changelogFile), c.text, c.textLine)
+		if err != nil {
+			return err
+		}
+		p.sqls = append(p.sqls, sqls...)
+	case "sqlFile":
+		file := p.resolvePath(changelogFile, c.attrs["path"], isTrue(c.attrs["relativeToChangelogFile"]))
+		content, err := ioutil.ReadFile(filepath.Clean(file))
+		if err != nil {
+			return err
+		}
+		return p.parseSQLFile(file, content)
+	default:
+		sql, ok := generateSQL(c)
+		if !ok {
+			return nil
+		}
+		p.sqls = append(p.sqls, scanners.SQL{
+			Fingerprint: common.Fingerprint(sql),
+			RawText:     sql,
+			FilePath:    p.relPath(changelogFile),
+			Line:        c.line,
+		})
+	}
+	return nil
+}
+
+func isTrue(v string) bool {
+	return strings.EqualFold(strings.TrimSpace(v), "true")
+}
@@ -0,0 +1,65 @@
+package liquibase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSQLFromChangelog(t *testing.T) {
+	_, err := GetSQLFromChangelog("./testdata/not-exist.xml")
+	assert.Error(t, err)
+
+	sqls, err := GetSQLFromChangelog("./testdata/db.changelog-master.xml")
+	if !assert.NoError(t, err) || !assert.Len(t, sqls, 8) {
+		t.FailNow()
+	}
+
+	expected := []struct {
+		file string
+		line int
+		sql  string
+	}{
+		{"db.changelog-master.xml", 9, "CREATE TABLE `users` (\n" +
+			"  `id` BIGINT NOT NULL AUTO_INCREMENT,\n" +
+			"  `name` VARCHAR(64) NOT NULL UNIQUE COMMENT 'user name',\n" +
+			"  PRIMARY KEY (`id`)\n" +
+			") COMMENT='user table'"},
+		{"db.changelog-master.xml", 22, "UPDATE users SET name = 'admin' WHERE id = 1;"},
+		{"db.changelog-master.xml", 23, "DELETE FROM users WHERE id > 100;"},
+		{"db.changelog-master.xml", 33, "CREATE INDEX `idx_name` ON `users` (`name`)"},
+		{"changes/data.sql", 1, "INSERT INTO users (name) VALUES ('a');"},
+		{"changes/v2.yaml", 7, "ALTER TABLE `users` ADD COLUMN `email` VARCHAR(128) AFTER `name`"},
+		{"changes/v2.yaml", 15, "SELECT 1;"},
+		{"changes/v2.yaml", 16, "SELECT * FROM users WHERE email IS NULL;"},
+	}
+	for i, e := range expected {
+		assert.Equal(t, e.file, sqls[i].FilePath)
+		assert.Equal(t, e.line, sqls[i].Line)
+		assert.Equal(t, e.sql, sqls[i].RawText)
+	}
+	assert.Equal(t, "SELECT * FROM `users` WHERE `email` IS NULL", sqls[7].Fingerprint)
+}
+
+func TestGenerateSQL(t *testing.T) {
+	c := newChange("addForeignKeyConstraint", 1)
+	c.attrs = map[string]string{
+		"baseTableName":         "orders",
+		"baseColumnNames":       "user_id",
+		"constraintName":        "fk_orders_user",
+		"referencedTableName":   "users",
+		"referencedColumnNames": "id",
+	}
+	sql, ok := generateSQL(c)
+	assert.True(t, ok)
+	assert.Equal(t, "ALTER TABLE `orders` ADD CONSTRAINT `fk_orders_user` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`)", sql)
+
+	c = newChange("renameColumn", 1)
+	c.attrs = map[string]string{"tableName": "users", "oldColumnName": "name", "newColumnName": "nick", "columnDataType": "VARCHAR(32)"}
+	sql, ok = generateSQL(c)
+	assert.True(t, ok)
+	assert.Equal(t, "ALTER TABLE `users` CHANGE COLUMN `name` `nick` VARCHAR(32)", sql)
+
+	_, ok = generateSQL(newChange("tagDatabase", 1))
+	assert.False(t, ok)
+}
@@ -0,0 +1,125 @@
+package liquibase
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"sort"
+)
+
+// parseXML parses the XML changelog, such as:
+//
+//	<databaseChangeLog>
+//	    <include file="changes/v1.yaml"/>
+//	    <changeSet id="1" author="sqle">
+//	        <createTable tableName="t1">
+//	            <column name="id" type="bigint">
+//	                <constraints primaryKey="true"/>
+//	            </column>
+//	        </createTable>
+//	        <sql>UPDATE t1 SET c1 = 1</sql>
+//	    </changeSet>
+//	</databaseChangeLog>
+//
+// The changes in "rollback" are ignored.
+func (p *changelogParser) parseXML(file string, content []byte) error {
+	lines := newLineIndex(content)
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+
+	var stack []string
+	var current *change
+	changeDepth := 0
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			name := t.Name.Local
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			stack = append(stack, name)
+			attrs := xmlAttrs(t.Attr)
+
+			switch {
+			case current != nil:
+				if name == "column" && len(stack) == changeDepth+1 {
+					col := newColumn()
+					col.attrs = attrs
+					current.columns = append(current.columns, col)
+				}
+				if name == "constraints" && parent == "column" && len(current.columns) > 0 {
+					current.columns[len(current.columns)-1].constraints = attrs
+				}
+			case parent == "databaseChangeLog" && name == "include":
+				if err := p.include(file, attrs); err != nil {
+					return err
+				}
+			case parent == "databaseChangeLog" && name == "includeAll":
+				if err := p.includeAll(file, attrs); err != nil {
+					return err
+				}
+			case parent == "changeSet":
+				current = newChange(name, lines.lineOf(offset))
+				current.attrs = attrs
+				changeDepth = len(stack)
+			}
+		case xml.CharData:
+			if current != nil && len(stack) == changeDepth {
+				if current.text == "" {
+					current.textLine = lines.lineOf(offset)
+				}
+				current.text += string(t)
+			}
+		case xml.EndElement:
+			if current != nil && len(stack) == changeDepth {
+				if err := p.addChange(file, current); err != nil {
+					return err
+				}
+				current = nil
+			}
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+}
+
+func xmlAttrs(attrs []xml.Attr) map[string]string {
+	m := make(map[string]string, len(attrs))
+	for _, attr := range attrs {
+		m[attr.Name.Local] = attr.Value
+	}
+	return m
+}
+
+// lineIndex converts the offset of content to line number.
+type lineIndex struct {
+	// starts is the offset of the first byte of each line.
+	starts []int
+}
+
+func newLineIndex(content []byte) *lineIndex {
+	starts := []int{0}
+	for i, c := range content {
+		if c == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &lineIndex{starts: starts}
+}
+
+// lineOf returns the line number (1-based) of offset.
+func (l *lineIndex) lineOf(offset int64) int {
+	return sort.Search(len(l.starts), func(i int) bool {
+		return int64(l.starts[i]) > offset
+	})
+}
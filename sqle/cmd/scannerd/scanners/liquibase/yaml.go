@@ -0,0 +1,143 @@
+package liquibase
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// parseYAML parses the YAML changelog, such as:
+//
+//	databaseChangeLog:
+//	  - include:
+//	      file: changes/v1.xml
+//	  - changeSet:
+//	      id: 1
+//	      author: sqle
+//	      changes:
+//	        - createTable:
+//	            tableName: t1
+//	            columns:
+//	              - column:
+//	                  name: id
+//	                  type: bigint
+//	                  constraints:
+//	                    primaryKey: true
+//	        - sql:
+//	            sql: UPDATE t1 SET c1 = 1
+func (p *changelogParser) parseYAML(file string, content []byte) error {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return err
+	}
+	if len(doc.Content) == 0 {
+		return nil
+	}
+	changelog := yamlMappingValue(doc.Content[0], "databaseChangeLog")
+	if changelog == nil || changelog.Kind != yaml.SequenceNode {
+		return fmt.Errorf("databaseChangeLog is not found")
+	}
+
+	for _, item := range changelog.Content {
+		if err := yamlEachPair(item, func(key string, value *yaml.Node) error {
+			switch key {
+			case "include":
+				return p.include(file, yamlScalars(value))
+			case "includeAll":
+				return p.includeAll(file, yamlScalars(value))
+			case "changeSet":
+				return p.parseYAMLChangeSet(file, value)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *changelogParser) parseYAMLChangeSet(file string, changeSet *yaml.Node) error {
+	changes := yamlMappingValue(changeSet, "changes")
+	if changes == nil {
+		return nil
+	}
+	for _, item := range changes.Content {
+		if err := yamlEachPair(item, func(name string, value *yaml.Node) error {
+			c := newChange(name, value.Line)
+			c.attrs = yamlScalars(value)
+			if value.Kind == yaml.ScalarNode {
+				// such as "- sql: UPDATE t1 SET c1 = 1"
+				setYAMLText(c, value)
+			}
+			if text := yamlMappingValue(value, "sql"); text != nil && name == "sql" {
+				setYAMLText(c, text)
+			}
+			if text := yamlMappingValue(value, "selectQuery"); text != nil && name == "createView" {
+				setYAMLText(c, text)
+			}
+			if columns := yamlMappingValue(value, "columns"); columns != nil {
+				for _, columnItem := range columns.Content {
+					columnNode := yamlMappingValue(columnItem, "column")
+					if columnNode == nil {
+						continue
+					}
+					col := newColumn()
+					col.attrs = yamlScalars(columnNode)
+					if constraints := yamlMappingValue(columnNode, "constraints"); constraints != nil {
+						col.constraints = yamlScalars(constraints)
+					}
+					c.columns = append(c.columns, col)
+				}
+			}
+			return p.addChange(file, c)
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setYAMLText(c *change, node *yaml.Node) {
+	c.text = node.Value
+	c.textLine = node.Line
+	// the text of block scalar starts at the next line of indicator "|" or ">".
+	if node.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		c.textLine++
+	}
+}
+
+func yamlEachPair(node *yaml.Node, fn func(key string, value *yaml.Node) error) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if err := fn(node.Content[i].Value, node.Content[i+1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func yamlMappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// yamlScalars returns the scalar values of mapping node.
+func yamlScalars(node *yaml.Node) map[string]string {
+	m := map[string]string{}
+	_ = yamlEachPair(node, func(key string, value *yaml.Node) error {
+		if value.Kind == yaml.ScalarNode {
+			m[key] = value.Value
+		}
+		return nil
+	})
+	return m
+}
@@ -10,9 +10,8 @@ import (
 	"strings"
 	"time"
 
-	mybatisParser "github.com/actiontech/mybatis-mapper-2-sql"
 	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners"
-	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/pkg/mybatis"
 	"github.com/actiontech/sqle/sqle/pkg/scanner"
 	"github.com/sirupsen/logrus"
 )
@@ -24,7 +23,7 @@ type MyBatis struct {
 	needTrigger bool
 	sqls        []scanners.SQL
 
-	allSQL []driver.Node
+	allSQL []scanners.SQL
 	getAll chan struct{}
 
 	apName         string
@@ -69,10 +68,7 @@ func (mb *MyBatis) SQLs() <-chan scanners.SQL {
 	go func() {
 		<-mb.getAll
 		for _, sql := range mb.allSQL {
-			sqlCh <- scanners.SQL{
-				Fingerprint: sql.Fingerprint,
-				RawText:     sql.Text,
-			}
+			sqlCh <- sql
 		}
 		mb.needTrigger = true
 		close(sqlCh)
@@ -106,6 +102,8 @@ func (mb *MyBatis) Upload(ctx context.Context, sqls []scanners.SQL) error {
 			Counter:              fmt.Sprintf("%v", counterMap[sql.Fingerprint]),
 			LastReceiveText:      sql.RawText,
 			LastReceiveTimestamp: now,
+			FilePath:             sql.FilePath,
+			Line:                 sql.Line,
 		})
 	}
 
@@ -121,7 +119,9 @@ func (mb *MyBatis) Upload(ctx context.Context, sqls []scanners.SQL) error {
 	return mb.c.GetAuditReportReq(mb.apName, reportID)
 }
 
-func GetSQLFromPath(pathName string, skipErrorQuery bool) (allSQL []driver.Node, err error) {
+// GetSQLFromPath returns the SQLs of all XML files in directory, the file
+// path of SQL is relative to the directory.
+func GetSQLFromPath(pathName string, skipErrorQuery bool) ([]scanners.SQL, error) {
 	if !path.IsAbs(pathName) {
 		pwd, err := os.Getwd()
 		if err != nil {
@@ -129,15 +129,27 @@ func GetSQLFromPath(pathName string, skipErrorQuery bool) (allSQL []driver.Node,
 		}
 		pathName = path.Join(pwd, pathName)
 	}
+	allSQL, err := getSQLFromPath(pathName, skipErrorQuery)
+	if err != nil {
+		return nil, err
+	}
+	for i := range allSQL {
+		if rel, err := filepath.Rel(pathName, allSQL[i].FilePath); err == nil {
+			allSQL[i].FilePath = filepath.ToSlash(rel)
+		}
+	}
+	return allSQL, nil
+}
 
+func getSQLFromPath(pathName string, skipErrorQuery bool) (allSQL []scanners.SQL, err error) {
 	fileInfos, err := ioutil.ReadDir(pathName)
 	if err != nil {
 		return nil, err
 	}
 	for _, fi := range fileInfos {
-		var sqlList []driver.Node
+		var sqlList []scanners.SQL
 		if fi.IsDir() {
-			sqlList, err = GetSQLFromPath(path.Join(pathName, fi.Name()), skipErrorQuery)
+			sqlList, err = getSQLFromPath(path.Join(pathName, fi.Name()), skipErrorQuery)
 		} else if strings.HasSuffix(fi.Name(), "xml") {
 			sqlList, err = GetSQLFromFile(path.Join(pathName, fi.Name()), skipErrorQuery)
 		}
@@ -150,21 +162,30 @@ func GetSQLFromPath(pathName string, skipErrorQuery bool) (allSQL []driver.Node,
 	return allSQL, err
 }
 
-func GetSQLFromFile(file string, skipErrorQuery bool) (r []driver.Node, err error) {
+// GetSQLFromFile returns the SQLs in XML file, the line of SQL is the line
+// of its query element.
+func GetSQLFromFile(file string, skipErrorQuery bool) (r []scanners.SQL, err error) {
 	content, err := ReadFileContent(file)
 	if err != nil {
 		return nil, err
 	}
-	sqls, err := mybatisParser.ParseXMLQuery(content, skipErrorQuery)
+	queries, err := mybatis.ParseXMLQuery(content, skipErrorQuery)
 	if err != nil {
 		return nil, err
 	}
-	for _, sql := range sqls {
-		n, err := Parse(context.TODO(), sql)
+	for _, query := range queries {
+		nodes, err := Parse(context.TODO(), query.SQL)
 		if err != nil {
 			return nil, err
 		}
-		r = append(r, n...)
+		for _, n := range nodes {
+			r = append(r, scanners.SQL{
+				Fingerprint: n.Fingerprint,
+				RawText:     n.Text,
+				FilePath:    file,
+				Line:        query.Line,
+			})
+		}
 	}
 	return r, nil
 }
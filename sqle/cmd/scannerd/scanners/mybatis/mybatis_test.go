@@ -58,3 +58,17 @@ func TestMyBatis(t *testing.T) {
 	_, ok = <-exitCh
 	assert.False(t, ok)
 }
+
+func TestGetSQLFromPath(t *testing.T) {
+	sqls, err := GetSQLFromPath("./testdata/", false)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, sqls, 10) {
+		t.FailNow()
+	}
+	for _, sql := range sqls {
+		assert.Equal(t, "test.xml", sql.FilePath)
+		assert.NotZero(t, sql.Line)
+	}
+}
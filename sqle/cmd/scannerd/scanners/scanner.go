@@ -8,6 +8,16 @@ type SQL struct {
 	Fingerprint string
 	RawText     string
 	Counter     int
+
+	// QueryTime and LockTime are in seconds, they are only set by slow query scanner.
+	QueryTime    float64
+	LockTime     float64
+	RowsExamined int64
+
+	// FilePath and Line are the location of SQL in source file, they are only
+	// set by the scanners which extract SQL from source files.
+	FilePath string
+	Line     int
 }
 
 // Scanner is a interface for all Scanners.
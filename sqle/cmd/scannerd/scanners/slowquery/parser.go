@@ -0,0 +1,168 @@
+package slowquery
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry is a query in MySQL slow log, such as:
+//
+//	# Time: 2021-06-01T10:00:00.123456Z
+//	# User@Host: root[root] @ localhost []  Id:     8
+//	# Query_time: 2.000213  Lock_time: 0.000100 Rows_sent: 1  Rows_examined: 10
+//	use db1;
+//	SET timestamp=1622541600;
+//	select sleep(2);
+type Entry struct {
+	Time         time.Time
+	Schema       string
+	Query        string
+	QueryTime    float64
+	LockTime     float64
+	RowsExamined int64
+}
+
+var (
+	// the attribute in header, such as "Query_time: 2.000213".
+	headerAttrReg = regexp.MustCompile(`([A-Za-z_]+):\s+(\S+)`)
+	useSchemaReg  = regexp.MustCompile(`(?i)^use\s+` + "`?" + `([^` + "`" + `;\s]+)` + "`?" + `\s*;\s*$`)
+	setTimeReg    = regexp.MustCompile(`(?i)^SET\s+timestamp\s*=\s*\d+\s*;\s*$`)
+)
+
+// the lines which are written by mysqld when the slow log is opened.
+var serverHeaderPrefixes = []string{"Tcp port:", "Time                 Id Command"}
+
+// Parser parses the lines of slow log to entries. Each entry is completed
+// when the header of next entry is found, or Flush is called.
+type Parser struct {
+	current *Entry
+	// inQuery is true if the query text of current entry is being read.
+	inQuery bool
+	query   strings.Builder
+	schema  string
+}
+
+func NewParser() *Parser {
+	return &Parser{}
+}
+
+// Feed parses a line without line break, it returns the previous entry if
+// the line starts a new entry.
+func (p *Parser) Feed(line string) *Entry {
+	line = strings.TrimRight(line, "\r\n")
+	if p.isServerHeader(line) {
+		return p.Flush()
+	}
+	if strings.HasPrefix(line, "# ") && (!p.inQuery || isEntryHeader(line)) {
+		var completed *Entry
+		if p.inQuery || p.current == nil {
+			completed = p.Flush()
+			p.current = &Entry{Schema: p.schema}
+		}
+		p.parseHeader(line)
+		return completed
+	}
+	if p.current == nil {
+		return nil
+	}
+	if !p.inQuery {
+		trimmed := strings.TrimSpace(line)
+		if matches := useSchemaReg.FindStringSubmatch(trimmed); matches != nil {
+			p.current.Schema = matches[1]
+			p.schema = matches[1]
+			return nil
+		}
+		if setTimeReg.MatchString(trimmed) || trimmed == "" {
+			return nil
+		}
+		p.inQuery = true
+	}
+	if p.query.Len() > 0 {
+		p.query.WriteString("\n")
+	}
+	p.query.WriteString(line)
+	return nil
+}
+
+// Pending returns true if the query text of current entry is completed,
+// which means it ends with ";". Caller may flush the pending entry when the
+// end of file is reached.
+func (p *Parser) Pending() bool {
+	return p.inQuery && strings.HasSuffix(strings.TrimSpace(p.query.String()), ";")
+}
+
+// Flush returns the current entry, it returns nil if there is no query in the
+// current entry.
+func (p *Parser) Flush() *Entry {
+	entry := p.current
+	query := strings.TrimSpace(p.query.String())
+	p.current = nil
+	p.inQuery = false
+	p.query.Reset()
+	if entry == nil || query == "" {
+		return nil
+	}
+	// the "administrator command" is not a query, such as "# administrator command: Quit;".
+	if strings.HasPrefix(query, "# administrator command") {
+		return nil
+	}
+	entry.Query = query
+	return entry
+}
+
+func (p *Parser) isServerHeader(line string) bool {
+	if strings.Contains(line, ", Version: ") && strings.Contains(line, "started with:") {
+		return true
+	}
+	for _, prefix := range serverHeaderPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func isEntryHeader(line string) bool {
+	return strings.HasPrefix(line, "# Time:") || strings.HasPrefix(line, "# User@Host:")
+}
+
+func (p *Parser) parseHeader(line string) {
+	if strings.HasPrefix(line, "# administrator command") {
+		p.inQuery = true
+		p.query.WriteString(line)
+		return
+	}
+	if strings.HasPrefix(line, "# Time:") {
+		p.current.Time = parseTime(strings.TrimSpace(strings.TrimPrefix(line, "# Time:")))
+		return
+	}
+	if strings.HasPrefix(line, "# User@Host:") {
+		return
+	}
+	for _, matches := range headerAttrReg.FindAllStringSubmatch(line, -1) {
+		switch matches[1] {
+		case "Query_time":
+			p.current.QueryTime, _ = strconv.ParseFloat(matches[2], 64)
+		case "Lock_time":
+			p.current.LockTime, _ = strconv.ParseFloat(matches[2], 64)
+		case "Rows_examined":
+			p.current.RowsExamined, _ = strconv.ParseInt(matches[2], 10, 64)
+		case "Schema":
+			// Percona Server writes the schema in header, such as "# Schema: db1  Last_errno: 0".
+			p.current.Schema = matches[2]
+		}
+	}
+}
+
+// parseTime parses the time of MySQL 5.7+ ("2021-06-01T10:00:00.123456Z")
+// and MySQL 5.6 ("210601 10:00:00").
+func parseTime(s string) time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "060102 15:04:05", "060102  15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,76 @@
+package slowquery
+
+import (
+	"bufio"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseFile(t *testing.T, path string) []*Entry {
+	f, err := os.Open(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer f.Close()
+
+	var entries []*Entry
+	p := NewParser()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if entry := p.Feed(scanner.Text()); entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	if entry := p.Flush(); entry != nil {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestParser(t *testing.T) {
+	entries := parseFile(t, "./testdata/slow.log")
+	if !assert.Len(t, entries, 3) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, &Entry{
+		Time:         time.Date(2021, 6, 1, 10, 0, 0, 123456000, time.UTC),
+		Schema:       "db1",
+		Query:        "select * from t1\nwhere id = 1;",
+		QueryTime:    2.000213,
+		LockTime:     0.0001,
+		RowsExamined: 10,
+	}, entries[0])
+
+	// the schema is inherited from the previous entry.
+	assert.Equal(t, "db1", entries[1].Schema)
+	assert.Equal(t, "select * from t1 where id = 2;", entries[1].Query)
+	assert.Equal(t, 4.0, entries[1].QueryTime)
+
+	// the administrator command is skipped.
+	assert.Equal(t, "db2", entries[2].Schema)
+	assert.Equal(t, "update t2 set v = 'a' where id in (1, 2, 3);", entries[2].Query)
+	assert.Equal(t, int64(100), entries[2].RowsExamined)
+}
+
+func TestParserPending(t *testing.T) {
+	p := NewParser()
+	assert.Nil(t, p.Feed("# Time: 210601 10:00:00"))
+	assert.Nil(t, p.Feed("# Query_time: 1.1  Lock_time: 0.0 Rows_sent: 1  Rows_examined: 1"))
+	assert.False(t, p.Pending())
+	assert.Nil(t, p.Feed("select 1"))
+	assert.False(t, p.Pending())
+	assert.Nil(t, p.Feed("from dual;"))
+	assert.True(t, p.Pending())
+
+	entry := p.Flush()
+	if !assert.NotNil(t, entry) {
+		t.FailNow()
+	}
+	assert.Equal(t, time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC), entry.Time)
+	assert.Equal(t, "select 1\nfrom dual;", entry.Query)
+	assert.Nil(t, p.Flush())
+}
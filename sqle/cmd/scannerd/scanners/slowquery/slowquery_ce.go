@@ -5,34 +5,157 @@ package slowquery
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners"
-	"github.com/actiontech/sqle/sqle/errors"
 	"github.com/actiontech/sqle/sqle/pkg/scanner"
+	"github.com/percona/go-mysql/query"
 	"github.com/sirupsen/logrus"
 )
 
-var errSlowQueryNotImplemented = errors.NewNotImplemented("SlowQuery Scanner")
+// SlowQuery follows the MySQL slow log, and uploads the queries aggregated
+// by fingerprint. Only the metrics of new queries are uploaded each time and
+// they are merged by SQLE, so the file is read from the end when the scanner
+// started, the queries which are uploaded before are not counted again.
+type SlowQuery struct {
+	l *logrus.Entry
+	c *scanner.Client
 
-type SlowQuery struct{}
+	apName      string
+	logFilePath string
+	// logFileOffset is the size of log file when the scanner is created.
+	logFileOffset int64
+	interval      time.Duration
+
+	sqlCh chan scanners.SQL
+}
 
 type Params struct {
 	LogFilePath string
 	APName      string
 }
 
+// sqlMetric is the aggregated metrics of the queries with same fingerprint.
+type sqlMetric struct {
+	fingerprint          string
+	lastReceiveText      string
+	lastReceiveTimestamp string
+
+	counter         uint64
+	queryTimeSum    float64
+	queryTimeMax    float64
+	lockTimeSum     float64
+	rowsExaminedSum int64
+}
+
 func New(params *Params, l *logrus.Entry, c *scanner.Client) (*SlowQuery, error) {
-	return &SlowQuery{}, errSlowQueryNotImplemented
+	info, err := os.Stat(params.LogFilePath)
+	if err != nil {
+		return nil, err
+	}
+	return &SlowQuery{
+		l:             l,
+		c:             c,
+		apName:        params.APName,
+		logFilePath:   params.LogFilePath,
+		logFileOffset: info.Size(),
+		interval:      time.Second,
+		// todo: channel size configurable
+		sqlCh: make(chan scanners.SQL, 10240),
+	}, nil
 }
 
-func (s *SlowQuery) Run(ctx context.Context) error {
-	return errSlowQueryNotImplemented
+func (sq *SlowQuery) Run(ctx context.Context) error {
+	parser := NewParser()
+	send := func(entry *Entry) {
+		if entry == nil {
+			return
+		}
+		select {
+		case sq.sqlCh <- scanners.SQL{
+			Fingerprint:  query.Fingerprint(strings.TrimSuffix(entry.Query, ";")),
+			RawText:      entry.Query,
+			Counter:      1,
+			QueryTime:    entry.QueryTime,
+			LockTime:     entry.LockTime,
+			RowsExamined: entry.RowsExamined,
+		}:
+		case <-ctx.Done():
+		}
+	}
+
+	fw := newFollower(sq.logFilePath, sq.interval)
+	fw.start = sq.logFileOffset
+	err := fw.Run(ctx,
+		func(line string) {
+			send(parser.Feed(line))
+		},
+		func() {
+			// the last entry can't be completed by the header of next entry
+			// until a new slow query happened, flush it if the query is completed.
+			if parser.Pending() {
+				send(parser.Flush())
+			}
+		})
+	if err != nil {
+		sq.l.Errorf("follow slow log file %s failed, error: %v", sq.logFilePath, err)
+	}
+	return err
 }
 
 func (sq *SlowQuery) SQLs() <-chan scanners.SQL {
-	return nil
+	return sq.sqlCh
 }
 
+// Upload uploads the metrics of sqls aggregated by fingerprint, they are
+// merged into the metrics of audit plan SQLs by SQLE.
 func (sq *SlowQuery) Upload(ctx context.Context, sqls []scanners.SQL) error {
-	return errSlowQueryNotImplemented
+	now := time.Now().Format(time.RFC3339)
+	metrics := map[string]*sqlMetric{}
+	for _, sql := range sqls {
+		m, ok := metrics[sql.Fingerprint]
+		if !ok {
+			m = &sqlMetric{fingerprint: sql.Fingerprint}
+			metrics[sql.Fingerprint] = m
+		}
+		m.lastReceiveText = sql.RawText
+		m.lastReceiveTimestamp = now
+		m.counter++
+		m.queryTimeSum += sql.QueryTime
+		m.lockTimeSum += sql.LockTime
+		m.rowsExaminedSum += sql.RowsExamined
+		if sql.QueryTime > m.queryTimeMax {
+			m.queryTimeMax = sql.QueryTime
+		}
+	}
+
+	fingerprints := make([]string, 0, len(metrics))
+	for fp := range metrics {
+		fingerprints = append(fingerprints, fp)
+	}
+	sort.Strings(fingerprints)
+
+	reqBody := make([]scanner.AuditPlanSQLReq, 0, len(fingerprints))
+	for _, fp := range fingerprints {
+		m := metrics[fp]
+		queryTimeAvg := m.queryTimeSum / float64(m.counter)
+		queryTimeMax := m.queryTimeMax
+		lockTimeAvg := m.lockTimeSum / float64(m.counter)
+		rowsExaminedAvg := float64(m.rowsExaminedSum) / float64(m.counter)
+		reqBody = append(reqBody, scanner.AuditPlanSQLReq{
+			Fingerprint:          m.fingerprint,
+			Counter:              fmt.Sprintf("%v", m.counter),
+			LastReceiveText:      m.lastReceiveText,
+			LastReceiveTimestamp: m.lastReceiveTimestamp,
+			QueryTimeAvg:         &queryTimeAvg,
+			QueryTimeMax:         &queryTimeMax,
+			LockTimeAvg:          &lockTimeAvg,
+			RowsExaminedAvg:      &rowsExaminedAvg,
+		})
+	}
+	return sq.c.UploadReq(scanner.PartialUpload, sq.apName, reqBody)
 }
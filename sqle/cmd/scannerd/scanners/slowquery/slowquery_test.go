@@ -0,0 +1,98 @@
+package slowquery
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/actiontech/sqle/sqle/cmd/scannerd/scanners"
+	"github.com/actiontech/sqle/sqle/pkg/scanner"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlowQuery(t *testing.T) {
+	var uploaded scanner.FullSyncAuditPlanSQLsReq
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/audit_plans/ap1/sqls/partial", r.URL.Path)
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &uploaded))
+		_, _ = w.Write([]byte(`{"code":0,"message":"ok"}`))
+	}))
+	defer server.Close()
+	addr := strings.Split(server.URL, ":")
+	client := scanner.NewSQLEClient(time.Second, addr[0]+":"+addr[1], addr[2])
+
+	_, err := New(&Params{LogFilePath: "./testdata/not-exist.log", APName: "ap1"}, logrus.NewEntry(logrus.New()), client)
+	assert.Error(t, err)
+
+	// the queries in log file before the scanner is created are skipped.
+	dir, err := ioutil.TempDir("", "slowquery")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "slow.log")
+	data, err := ioutil.ReadFile("./testdata/slow.log")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.NoError(t, ioutil.WriteFile(path, data, 0644)) {
+		t.FailNow()
+	}
+	sq, err := New(&Params{LogFilePath: path, APName: "ap1"}, logrus.NewEntry(logrus.New()), client)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	_, err = f.Write(data)
+	assert.NoError(t, err)
+	f.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- sq.Run(ctx)
+	}()
+
+	var sqls []scanners.SQL
+	for len(sqls) < 3 {
+		select {
+		case sql := <-sq.SQLs():
+			sqls = append(sqls, sql)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("only %d sqls are received", len(sqls))
+		}
+	}
+	cancel()
+	assert.NoError(t, <-done)
+
+	assert.NoError(t, sq.Upload(context.TODO(), sqls))
+	if !assert.Len(t, uploaded.SQLs, 2) {
+		t.FailNow()
+	}
+	selectSQL := uploaded.SQLs[0]
+	assert.Equal(t, "select * from t1 where id = ?", selectSQL.Fingerprint)
+	assert.Equal(t, "2", selectSQL.Counter)
+	assert.Equal(t, "select * from t1 where id = 2;", selectSQL.LastReceiveText)
+	assert.InDelta(t, 3.0001065, *selectSQL.QueryTimeAvg, 0.000001)
+	assert.Equal(t, 4.0, *selectSQL.QueryTimeMax)
+	assert.InDelta(t, 0.0002, *selectSQL.LockTimeAvg, 0.000001)
+	assert.Equal(t, 20.0, *selectSQL.RowsExaminedAvg)
+
+	// only the metrics of new queries are uploaded.
+	assert.NoError(t, sq.Upload(context.TODO(), sqls[:1]))
+	if !assert.Len(t, uploaded.SQLs, 1) {
+		t.FailNow()
+	}
+	assert.Equal(t, "1", uploaded.SQLs[0].Counter)
+}
@@ -0,0 +1,143 @@
+package slowquery
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"time"
+)
+
+// follower reads the lines of file like "tail -F", it reopens the file when
+// the file is rotated (renamed or removed, and a new file is created), and
+// reads from the beginning when the file is truncated.
+type follower struct {
+	path     string
+	interval time.Duration
+	// start is the offset where the file is read from when it is opened at
+	// first, the file is read from the beginning if it is shorter than start.
+	start int64
+
+	f      *os.File
+	info   os.FileInfo
+	r      *bufio.Reader
+	offset int64
+	// partial is the last line which has no line break yet.
+	partial string
+}
+
+func newFollower(path string, interval time.Duration) *follower {
+	return &follower{path: path, interval: interval}
+}
+
+// Run calls onLine for each line in file, and calls onIdle when there is no
+// more data to read. It blocks until ctx is canceled.
+func (fw *follower) Run(ctx context.Context, onLine func(line string), onIdle func()) error {
+	if err := fw.open(); err != nil {
+		return err
+	}
+	defer func() {
+		if fw.f != nil {
+			fw.f.Close()
+		}
+	}()
+	if fw.start > 0 && fw.start <= fw.info.Size() {
+		if _, err := fw.f.Seek(fw.start, io.SeekStart); err != nil {
+			return err
+		}
+		fw.r.Reset(fw.f)
+		fw.offset = fw.start
+	}
+
+	for {
+		if err := fw.readLines(onLine); err != nil {
+			return err
+		}
+		onIdle()
+
+		rotated, err := fw.checkRotate(onLine)
+		if err != nil {
+			return err
+		}
+		if rotated {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(fw.interval):
+		}
+	}
+}
+
+func (fw *follower) open() error {
+	f, err := os.Open(fw.path)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	if fw.f != nil {
+		fw.f.Close()
+	}
+	fw.f = f
+	fw.info = info
+	fw.r = bufio.NewReader(f)
+	fw.offset = 0
+	fw.partial = ""
+	return nil
+}
+
+// readLines reads the file until EOF.
+func (fw *follower) readLines(onLine func(line string)) error {
+	for {
+		line, err := fw.r.ReadString('\n')
+		fw.offset += int64(len(line))
+		if err == io.EOF {
+			fw.partial += line
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		onLine(fw.partial + line)
+		fw.partial = ""
+	}
+}
+
+// checkRotate reopens the file if it is rotated or truncated. It returns
+// false if the file is not changed, or the new file is not created yet.
+func (fw *follower) checkRotate(onLine func(line string)) (bool, error) {
+	info, err := os.Stat(fw.path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if !os.SameFile(fw.info, info) {
+		// the data may be written to the old file after the last read, read
+		// it to EOF before reopening, the partial line will never be completed.
+		if err := fw.readLines(onLine); err != nil {
+			return false, err
+		}
+		if fw.partial != "" {
+			onLine(fw.partial)
+		}
+		return true, fw.open()
+	}
+	if info.Size() < fw.offset {
+		if _, err := fw.f.Seek(0, io.SeekStart); err != nil {
+			return false, err
+		}
+		fw.r.Reset(fw.f)
+		fw.offset = 0
+		fw.partial = ""
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,119 @@
+package slowquery
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollower(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slowquery")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "slow.log")
+	if !assert.NoError(t, ioutil.WriteFile(path, []byte("line1\nline2\nli"), 0644)) {
+		t.FailNow()
+	}
+
+	var mu sync.Mutex
+	var lines []string
+	getLines := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, lines...)
+	}
+	waitLines := func(n int) {
+		for i := 0; i < 100 && len(getLines()) < n; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- newFollower(path, 10*time.Millisecond).Run(ctx, func(line string) {
+			mu.Lock()
+			lines = append(lines, line)
+			mu.Unlock()
+		}, func() {})
+	}()
+
+	waitLines(2)
+	assert.Equal(t, []string{"line1\n", "line2\n"}, getLines())
+
+	// append the partial line.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	_, err = f.WriteString("ne3\n")
+	assert.NoError(t, err)
+	f.Close()
+	waitLines(3)
+	assert.Equal(t, "line3\n", getLines()[2])
+
+	// rotate file.
+	assert.NoError(t, os.Rename(path, path+".1"))
+	assert.NoError(t, ioutil.WriteFile(path, []byte("line4\n"), 0644))
+	waitLines(4)
+	assert.Equal(t, "line4\n", getLines()[3])
+
+	// truncate file.
+	assert.NoError(t, ioutil.WriteFile(path, []byte("5\n"), 0644))
+	waitLines(5)
+	assert.Equal(t, "5\n", getLines()[4])
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestFollowerRotate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "slowquery")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "slow.log")
+	if !assert.NoError(t, ioutil.WriteFile(path, []byte("line1\nline2\n"), 0644)) {
+		t.FailNow()
+	}
+
+	lines := []string{}
+	onLine := func(line string) {
+		lines = append(lines, line)
+	}
+	fw := newFollower(path, time.Millisecond)
+	if !assert.NoError(t, fw.open()) {
+		t.FailNow()
+	}
+	defer func() {
+		fw.f.Close()
+	}()
+	assert.NoError(t, fw.readLines(onLine))
+	assert.Equal(t, []string{"line1\n", "line2\n"}, lines)
+
+	// the lines written before rotation are not lost.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	_, err = f.WriteString("line3\nline4")
+	assert.NoError(t, err)
+	f.Close()
+	assert.NoError(t, os.Rename(path, path+".1"))
+	assert.NoError(t, ioutil.WriteFile(path, []byte("line5\n"), 0644))
+
+	rotated, err := fw.checkRotate(onLine)
+	assert.NoError(t, err)
+	assert.True(t, rotated)
+	assert.NoError(t, fw.readLines(onLine))
+	assert.Equal(t, []string{"line1\n", "line2\n", "line3\n", "line4", "line5\n"}, lines)
+}
@@ -32,6 +32,8 @@ var httpsEnable bool
 var certFilePath string
 var keyFilePath string
 var pluginPath string
+var driverPoolMaxSize int
+var driverPoolIdleTimeout int
 
 func init() {
 	config.Version = version
@@ -64,6 +66,8 @@ func main() {
 	rootCmd.Flags().StringVarP(&certFilePath, "cert-file-path", "", "", "https cert file path")
 	rootCmd.Flags().StringVarP(&keyFilePath, "key-file-path", "", "", "https key file path")
 	rootCmd.Flags().StringVarP(&pluginPath, "plugin-path", "", "", "plugin path")
+	rootCmd.Flags().IntVarP(&driverPoolMaxSize, "driver-pool-max-size", "", 0, "max reused drivers count for each instance and schema, 0 is default size 10, negative disables driver pool")
+	rootCmd.Flags().IntVarP(&driverPoolIdleTimeout, "driver-pool-idle-timeout", "", 300, "seconds that idle driver is kept in pool")
 
 	rootCmd.AddCommand(genSecretPasswordCmd())
 	if err := rootCmd.Execute(); err != nil {
@@ -102,6 +106,9 @@ func run(cmd *cobra.Command, _ []string) error {
 					CertFilePath:     certFilePath,
 					KeyFilePath:      keyFilePath,
 					PluginPath:       pluginPath,
+
+					DriverPoolMaxSize:     driverPoolMaxSize,
+					DriverPoolIdleTimeout: driverPoolIdleTimeout,
 				},
 				DBCnf: config.DatabaseConfig{
 					MysqlCnf: config.MysqlConfig{
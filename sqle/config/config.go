@@ -19,6 +19,11 @@ type SqleConfig struct {
 	LogPath          string `yaml:"log_path"`
 	PluginPath       string `yaml:"plugin_path"`
 	SecretKey        string `yaml:"secret_key"`
+	// DriverPoolMaxSize limits the reused drivers count for each instance and schema,
+	// 0 means using the default size, negative means disabling the driver pool.
+	DriverPoolMaxSize int `yaml:"driver_pool_max_size"`
+	// DriverPoolIdleTimeout is the seconds that idle driver is kept in pool.
+	DriverPoolIdleTimeout int `yaml:"driver_pool_idle_timeout"`
 }
 
 type DatabaseConfig struct {
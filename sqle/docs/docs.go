@@ -473,6 +473,67 @@ var doc = `{
                 }
             }
         },
+        "/v1/audit_plans/{audit_plan_name}/reports/{audit_plan_report_id}/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "export audit plan report in SARIF 2.1.0 or JUnit XML format, which can be consumed by code scanning of GitHub or GitLab",
+                "tags": [
+                    "audit_plan"
+                ],
+                "summary": "导出指定审核计划的SQL审核报告",
+                "operationId": "exportAuditPlanReportV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "audit plan name",
+                        "name": "audit_plan_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "audit plan report id",
+                        "name": "audit_plan_report_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "sarif",
+                            "junit"
+                        ],
+                        "type": "string",
+                        "description": "report format",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "notice",
+                            "warn",
+                            "error"
+                        ],
+                        "type": "string",
+                        "description": "the SQLs whose audit level is higher or equal than it are failed test cases in JUnit XML, default is error",
+                        "name": "fail_level",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "audit plan report file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/audit_plans/{audit_plan_name}/sqls": {
             "get": {
                 "security": [
@@ -796,6 +857,91 @@ var doc = `{
                 }
             }
         },
+        "/v1/configurations/bundle": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "export the rule templates, workflow templates, instances, roles, user groups and audit plans\nas a declarative configuration bundle, the passwords of instances are not exported.",
+                "tags": [
+                    "configuration"
+                ],
+                "summary": "导出配置包",
+                "operationId": "exportConfigurationBundleV1",
+                "parameters": [
+                    {
+                        "enum": [
+                            "yaml",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "file format, default is yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "configuration bundle file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/configurations/bundle/apply": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "apply the declarative configuration bundle, the differences between the bundle and SQLE are planned\nand applied in a transaction. The resources which are not in bundle are deleted if prune is true,\nbut only the kinds of resource present in bundle are pruned, and the default templates are never pruned.\nIf dry_run is true, the plan is validated and returned, but not applied.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "configuration"
+                ],
+                "summary": "应用配置包",
+                "operationId": "applyConfigurationBundleV1",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "configuration bundle file in YAML or JSON",
+                        "name": "bundle_file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only plan the changes",
+                        "name": "dry_run",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "delete the resources which are not in bundle",
+                        "name": "prune",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.ApplyConfigurationBundleResV1"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/configurations/drivers": {
             "get": {
                 "security": [
@@ -1067,6 +1213,29 @@ var doc = `{
                 }
             }
         },
+        "/v1/configurations/plugins": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get status of driver plugins",
+                "tags": [
+                    "configuration"
+                ],
+                "summary": "获取插件运行状态",
+                "operationId": "getPluginsV1",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.GetPluginsResV1"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/configurations/smtp": {
             "get": {
                 "security": [
@@ -1315,6 +1484,176 @@ var doc = `{
                 }
             }
         },
+        "/v1/custom_rules": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get the rules defined by user",
+                "tags": [
+                    "rule_template"
+                ],
+                "summary": "自定义规则列表",
+                "operationId": "getCustomRuleListV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "filter db type",
+                        "name": "filter_db_type",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.GetCustomRulesResV1"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "create a rule defined by user, the rule name must start with \"custom_\". The rule is matched by\nthe regular expression over SQL text or fingerprint, or by the predicate over AST, the AST predicate\nis matched if all of the non-empty conditions are satisfied. The rule of match type \"expr\" is matched\nif the expression over the JSON view of statement is true, it is supported by the plugins built with\nthe plugin adaptor. The rule can be added to rule templates like the rules of driver, and it is\nevaluated by the driver which supports custom rules.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rule_template"
+                ],
+                "summary": "添加自定义规则",
+                "operationId": "createCustomRuleV1",
+                "parameters": [
+                    {
+                        "description": "create custom rule request",
+                        "name": "instance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.CreateCustomRuleReqV1"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.BaseRes"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/custom_rules/{rule_name}/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get the rule defined by user",
+                "tags": [
+                    "rule_template"
+                ],
+                "summary": "获取自定义规则",
+                "operationId": "getCustomRuleV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "custom rule name",
+                        "name": "rule_name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.GetCustomRuleResV1"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "delete the rule defined by user, it is also removed from rule templates.",
+                "tags": [
+                    "rule_template"
+                ],
+                "summary": "删除自定义规则",
+                "operationId": "deleteCustomRuleV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "custom rule name",
+                        "name": "rule_name",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.BaseRes"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "update the rule defined by user, the matcher is replaced if it is present.\nThe level of rule in rule templates is not changed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rule_template"
+                ],
+                "summary": "更新自定义规则",
+                "operationId": "updateCustomRuleV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "custom rule name",
+                        "name": "rule_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update custom rule request",
+                        "name": "instance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.UpdateCustomRuleReqV1"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.BaseRes"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/dashboard": {
             "get": {
                 "security": [
@@ -2089,6 +2428,184 @@ var doc = `{
                 }
             }
         },
+        "/v1/rule_exemptions": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get the rule exemptions, including the expired ones",
+                "tags": [
+                    "rule_exemption"
+                ],
+                "summary": "获取规则豁免列表",
+                "operationId": "getRuleExemptionListV1",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page index",
+                        "name": "page_index",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "size of per page",
+                        "name": "page_size",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.GetRuleExemptionsResV1"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "create a rule exemption, the findings of the rules are exempted on the SQLs in scope until it is expired.\nThe scope is the non-empty ones of instance, schema, table and fingerprint, the SQL is in scope if all\nof them are matched. The table is matched if the SQL references it, and the fingerprint is matched if\nthe SQL has the same fingerprint with fingerprint_sql. The exempted findings are still shown in audit\nresults and reports as \"exempted\", but they do not affect the audit level of SQL.\nThe current user is recorded as the approver.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rule_exemption"
+                ],
+                "summary": "添加规则豁免",
+                "operationId": "createRuleExemptionV1",
+                "parameters": [
+                    {
+                        "description": "create rule exemption request",
+                        "name": "instance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.CreateRuleExemptionReqV1"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.BaseRes"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/rule_exemptions/{rule_exemption_id}/": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "get the rule exemption",
+                "tags": [
+                    "rule_exemption"
+                ],
+                "summary": "获取规则豁免",
+                "operationId": "getRuleExemptionV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rule exemption id",
+                        "name": "rule_exemption_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.GetRuleExemptionResV1"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "delete the rule exemption, the audited SQLs are not changed.",
+                "tags": [
+                    "rule_exemption"
+                ],
+                "summary": "删除规则豁免",
+                "operationId": "deleteRuleExemptionV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rule exemption id",
+                        "name": "rule_exemption_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.BaseRes"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "update the rule exemption, the current user is recorded as the approver of the updated exemption.",
+                "consumes": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rule_exemption"
+                ],
+                "summary": "更新规则豁免",
+                "operationId": "updateRuleExemptionV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rule exemption id",
+                        "name": "rule_exemption_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "update rule exemption request",
+                        "name": "instance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.UpdateRuleExemptionReqV1"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.BaseRes"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/rule_template_tips": {
             "get": {
                 "security": [
@@ -2175,24 +2692,68 @@ var doc = `{
                 "tags": [
                     "rule_template"
                 ],
-                "summary": "添加规则模板",
-                "operationId": "createRuleTemplateV1",
+                "summary": "添加规则模板",
+                "operationId": "createRuleTemplateV1",
+                "parameters": [
+                    {
+                        "description": "add rule template request",
+                        "name": "instance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.CreateRuleTemplateReqV1"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.BaseRes"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/rule_templates/import": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "import rule template from the file exported by SQLE, the rules are validated by the rules of driver.\nThe unknown or removed rules and the not exist instances are skipped and reported in response.\nIf the rule template is exist, it is overwritten only when overwrite is true.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "rule_template"
+                ],
+                "summary": "导入规则模板",
+                "operationId": "importRuleTemplateV1",
                 "parameters": [
                     {
-                        "description": "add rule template request",
-                        "name": "instance",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/v1.CreateRuleTemplateReqV1"
-                        }
+                        "type": "file",
+                        "description": "rule template file in YAML or JSON",
+                        "name": "rule_template_file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "overwrite the rule template if it is exist",
+                        "name": "overwrite",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/controller.BaseRes"
+                            "$ref": "#/definitions/v1.ImportRuleTemplateResV1"
                         }
                     }
                 }
@@ -2343,6 +2904,48 @@ var doc = `{
                 }
             }
         },
+        "/v1/rule_templates/{rule_template_name}/export": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "export rule template as a versioned YAML or JSON file, which contains the rules with level and params,\nthe bound instance names, the db type and the SQLE version. The file can be imported by other SQLE servers.",
+                "tags": [
+                    "rule_template"
+                ],
+                "summary": "导出规则模板",
+                "operationId": "exportRuleTemplateV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "rule template name",
+                        "name": "rule_template_name",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "yaml",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "file format, default is yaml",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "rule template file",
+                        "schema": {
+                            "type": "file"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/rules": {
             "get": {
                 "security": [
@@ -2468,6 +3071,105 @@ var doc = `{
                 }
             }
         },
+        "/v1/tasks/audits/{task_id}/cancel": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "cancel the running execution, dry run or rollback of task, the running SQL is killed and the remaining SQLs are skipped",
+                "tags": [
+                    "task"
+                ],
+                "summary": "取消执行中的审核任务",
+                "operationId": "cancelAuditTaskV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.BaseRes"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/audits/{task_id}/dry_run": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "dry run the SQLs of task, DMLs are executed in transaction which is rolled back and DDLs are executed by gh-ost in noop mode.\nThe DMLs on the tables of non-transactional engine, such as MyISAM, are skipped.",
+                "tags": [
+                    "task"
+                ],
+                "summary": "试运行审核任务的SQL",
+                "operationId": "dryRunAuditTaskV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.GetAuditTaskResV1"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/tasks/audits/{task_id}/progress": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "stream the progress of the running task by server-sent events, a \"progress\" event is sent every second and a \"done\" event is sent when the task is not running",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "task"
+                ],
+                "summary": "获取审核任务执行进度",
+                "operationId": "getAuditTaskProgressV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "task id",
+                        "name": "task_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/v1.TaskProgressResV1"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/tasks/audits/{task_id}/sql_content": {
             "get": {
                 "security": [
@@ -2539,7 +3241,7 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "download report file of all SQLs information belong to the specified audit task",
+                "description": "download report file of all SQLs information belong to the specified audit task,\nthe format can be csv (default), SARIF 2.1.0 or JUnit XML, which can be consumed by code scanning of GitHub or GitLab.",
                 "tags": [
                     "task"
                 ],
@@ -2558,11 +3260,33 @@ var doc = `{
                         "description": "select unique (fingerprint and audit result) for task sql",
                         "name": "no_duplicate",
                         "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "csv",
+                            "sarif",
+                            "junit"
+                        ],
+                        "type": "string",
+                        "description": "report format",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "notice",
+                            "warn",
+                            "error"
+                        ],
+                        "type": "string",
+                        "description": "the SQLs whose audit level is higher or equal than it are failed test cases in JUnit XML, default is error",
+                        "name": "fail_level",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "sql report csv file",
+                        "description": "sql report file",
                         "schema": {
                             "type": "file"
                         }
@@ -3516,6 +4240,7 @@ var doc = `{
                             "exec_scheduled",
                             "executing",
                             "exec_failed",
+                            "exec_partial_success",
                             "finished"
                         ],
                         "type": "string",
@@ -3563,7 +4288,7 @@ var doc = `{
                         "ApiKeyAuth": []
                     }
                 ],
-                "description": "create workflow",
+                "description": "create workflow, the SQLs of task will be executed on the task instance and all targets.",
                 "consumes": [
                     "application/json"
                 ],
@@ -3905,6 +4630,53 @@ var doc = `{
                 }
             }
         },
+        "/v1/workflows/{workflow_id}/task/rollback": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "rollback the executed SQLs of task on workflow. If the workflow has sql_rollback step, the rollback is\nwaiting for approval of the step, and the requested SQLs are rolled back only after it is approved.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "workflow"
+                ],
+                "summary": "工单提交 SQL 回滚",
+                "operationId": "rollbackTaskOnWorkflowV1",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "workflow id",
+                        "name": "workflow_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "rollback task on workflow request",
+                        "name": "rollback",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/v1.RollbackTaskOnWorkflowReqV1"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/controller.BaseRes"
+                        }
+                    }
+                }
+            }
+        },
         "/v2/audit_plans/{audit_plan_name}/report/{audit_plan_report_id}/": {
             "get": {
                 "security": [
@@ -4213,6 +4985,37 @@ var doc = `{
                 }
             }
         },
+        "v1.ApplyConfigurationBundleResDataV1": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.ConfigurationBundleChangeResV1"
+                    }
+                }
+            }
+        },
+        "v1.ApplyConfigurationBundleResV1": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "data": {
+                    "type": "object",
+                    "$ref": "#/definitions/v1.ApplyConfigurationBundleResDataV1"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
         "v1.AuditPlanMetaV1": {
             "type": "object",
             "properties": {
@@ -4356,6 +5159,11 @@ var doc = `{
                     "type": "string",
                     "example": "6"
                 },
+                "audit_plan_sql_file_path": {
+                    "description": "the location of SQL is uploaded by the scanners which extract SQL from source files.",
+                    "type": "string",
+                    "example": "db/migration/V1__init.sql"
+                },
                 "audit_plan_sql_fingerprint": {
                     "type": "string",
                     "example": "select * from t1 where id = ?"
@@ -4367,6 +5175,27 @@ var doc = `{
                 "audit_plan_sql_last_receive_timestamp": {
                     "type": "string",
                     "example": "RFC3339"
+                },
+                "audit_plan_sql_line": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "audit_plan_sql_lock_time_avg": {
+                    "type": "number",
+                    "example": 0.01
+                },
+                "audit_plan_sql_query_time_avg": {
+                    "description": "the following metrics are uploaded by slow query scanner, the time is in seconds.",
+                    "type": "number",
+                    "example": 3.22
+                },
+                "audit_plan_sql_query_time_max": {
+                    "type": "number",
+                    "example": 5.12
+                },
+                "audit_plan_sql_rows_examined_avg": {
+                    "type": "number",
+                    "example": 1000
                 }
             }
         },
@@ -4391,6 +5220,39 @@ var doc = `{
                 }
             }
         },
+        "v1.AuditResultResV1": {
+            "type": "object",
+            "properties": {
+                "category": {
+                    "type": "string"
+                },
+                "exempted": {
+                    "type": "boolean"
+                },
+                "exemption_id": {
+                    "type": "integer"
+                },
+                "level": {
+                    "type": "string",
+                    "example": "warn"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "position_line": {
+                    "type": "integer"
+                },
+                "position_offset": {
+                    "type": "integer"
+                },
+                "rule_name": {
+                    "type": "string"
+                },
+                "suggestion": {
+                    "type": "string"
+                }
+            }
+        },
         "v1.AuditTaskResV1": {
             "type": "object",
             "properties": {
@@ -4404,9 +5266,35 @@ var doc = `{
                         ""
                     ]
                 },
+                "capabilities": {
+                    "description": "Capabilities are the capabilities of task driver, the actions which\nneed the missing capabilities are not supported by task, such as rollback.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "rollback",
+                        "tx",
+                        "dry_run"
+                    ]
+                },
+                "dry_run_time": {
+                    "type": "string"
+                },
                 "exec_end_time": {
                     "type": "string"
                 },
+                "exec_failure_result": {
+                    "type": "string"
+                },
+                "exec_failure_strategy": {
+                    "type": "string",
+                    "enum": [
+                        "stop",
+                        "continue",
+                        "rollback_executed"
+                    ]
+                },
                 "exec_start_time": {
                     "type": "string"
                 },
@@ -4465,12 +5353,33 @@ var doc = `{
                 "audit_result": {
                     "type": "string"
                 },
+                "audit_results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.AuditResultResV1"
+                    }
+                },
                 "audit_status": {
                     "type": "string"
                 },
                 "description": {
                     "type": "string"
                 },
+                "dry_run_result": {
+                    "type": "string"
+                },
+                "dry_run_row_affects": {
+                    "type": "integer"
+                },
+                "dry_run_status": {
+                    "type": "string",
+                    "enum": [
+                        "initialized",
+                        "succeeded",
+                        "failed",
+                        "skipped"
+                    ]
+                },
                 "exec_result": {
                     "type": "string"
                 },
@@ -4485,6 +5394,12 @@ var doc = `{
                 },
                 "rollback_sql": {
                     "type": "string"
+                },
+                "source_file": {
+                    "type": "string"
+                },
+                "source_line": {
+                    "type": "integer"
                 }
             }
         },
@@ -4594,6 +5509,39 @@ var doc = `{
                 }
             }
         },
+        "v1.ConfigurationBundleChangeResV1": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "fields": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "kind": {
+                    "type": "string",
+                    "enum": [
+                        "rule_template",
+                        "workflow_template",
+                        "instance",
+                        "role",
+                        "user_group",
+                        "audit_plan"
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
         "v1.CreateAuditPlanReqV1": {
             "type": "object",
             "properties": {
@@ -4650,6 +5598,39 @@ var doc = `{
                 }
             }
         },
+        "v1.CreateCustomRuleReqV1": {
+            "type": "object",
+            "properties": {
+                "db_type": {
+                    "type": "string",
+                    "example": "mysql"
+                },
+                "desc": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "normal",
+                        "notice",
+                        "warn",
+                        "error"
+                    ]
+                },
+                "matcher": {
+                    "type": "object",
+                    "$ref": "#/definitions/v1.CustomRuleMatcherV1"
+                },
+                "rule_name": {
+                    "type": "string",
+                    "example": "custom_update_without_tenant"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "自定义规范"
+                }
+            }
+        },
         "v1.CreateInstanceReqV1": {
             "type": "object",
             "properties": {
@@ -4733,6 +5714,44 @@ var doc = `{
                 }
             }
         },
+        "v1.CreateRuleExemptionReqV1": {
+            "type": "object",
+            "properties": {
+                "expired_at": {
+                    "type": "string",
+                    "example": "2026-12-31T00:00:00+08:00"
+                },
+                "fingerprint_sql": {
+                    "type": "string",
+                    "example": "select * from legacy_orders where id = 1"
+                },
+                "instance_name": {
+                    "type": "string",
+                    "example": "inst_1"
+                },
+                "justification": {
+                    "type": "string",
+                    "example": "the legacy table will be dropped"
+                },
+                "rule_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ddl_check_pk_not_exist"
+                    ]
+                },
+                "schema_name": {
+                    "type": "string",
+                    "example": "db1"
+                },
+                "table_name": {
+                    "type": "string",
+                    "example": "legacy_orders"
+                }
+            }
+        },
         "v1.CreateRuleTemplateReqV1": {
             "type": "object",
             "properties": {
@@ -4823,6 +5842,13 @@ var doc = `{
                 "desc": {
                     "type": "string"
                 },
+                "targets": {
+                    "description": "Targets fan out the SQLs of task to other instances, a task is created\nand audited for each target.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.WorkflowTargetReqV1"
+                    }
+                },
                 "task_id": {
                     "type": "string"
                 },
@@ -4846,6 +5872,14 @@ var doc = `{
                 "desc": {
                     "type": "string"
                 },
+                "exec_failure_strategy": {
+                    "type": "string",
+                    "enum": [
+                        "stop",
+                        "continue",
+                        "rollback_executed"
+                    ]
+                },
                 "instance_name_list": {
                     "type": "array",
                     "items": {
@@ -4863,6 +5897,92 @@ var doc = `{
                 }
             }
         },
+        "v1.CustomRuleMatcherV1": {
+            "type": "object",
+            "properties": {
+                "column_pattern": {
+                    "type": "string",
+                    "example": "^is_"
+                },
+                "expr": {
+                    "type": "string",
+                    "example": "ast.type == 'update' \u0026\u0026 ast.where == null"
+                },
+                "match_type": {
+                    "type": "string",
+                    "enum": [
+                        "sql",
+                        "fingerprint",
+                        "ast",
+                        "expr"
+                    ]
+                },
+                "message_template": {
+                    "type": "string",
+                    "example": "表 {{.Table}} 的 WHERE 条件必须包含 {{.Column}}"
+                },
+                "pattern": {
+                    "type": "string",
+                    "example": "(?i)sql_no_cache"
+                },
+                "stmt_types": {
+                    "type": "array",
+                    "items": {
+                        "type": "string",
+                        "enum": [
+                            "select",
+                            "insert",
+                            "update",
+                            "delete",
+                            "create_table",
+                            "alter_table",
+                            "drop_table",
+                            "create_index",
+                            "drop_index",
+                            "truncate"
+                        ]
+                    }
+                },
+                "table_pattern": {
+                    "type": "string",
+                    "example": "^order"
+                },
+                "where_without_column": {
+                    "type": "string",
+                    "example": "tenant_id"
+                }
+            }
+        },
+        "v1.CustomRuleResV1": {
+            "type": "object",
+            "properties": {
+                "db_type": {
+                    "type": "string"
+                },
+                "desc": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "normal",
+                        "notice",
+                        "warn",
+                        "error"
+                    ]
+                },
+                "matcher": {
+                    "type": "object",
+                    "$ref": "#/definitions/v1.CustomRuleMatcherV1"
+                },
+                "rule_name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
         "v1.DashboardResV1": {
             "type": "object",
             "properties": {
@@ -4872,9 +5992,36 @@ var doc = `{
                 }
             }
         },
+        "v1.DriverResV1": {
+            "type": "object",
+            "properties": {
+                "capabilities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "rollback",
+                        "tx",
+                        "explain",
+                        "schemas",
+                        "online_ddl"
+                    ]
+                },
+                "driver_name": {
+                    "type": "string"
+                }
+            }
+        },
         "v1.DriversResV1": {
             "type": "object",
             "properties": {
+                "driver_list": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.DriverResV1"
+                    }
+                },
                 "driver_name_list": {
                     "type": "array",
                     "items": {
@@ -5153,6 +6300,42 @@ var doc = `{
                 }
             }
         },
+        "v1.GetCustomRuleResV1": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "data": {
+                    "type": "object",
+                    "$ref": "#/definitions/v1.CustomRuleResV1"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "v1.GetCustomRulesResV1": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.CustomRuleResV1"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
         "v1.GetDashboardResV1": {
             "type": "object",
             "properties": {
@@ -5423,7 +6606,52 @@ var doc = `{
                 }
             }
         },
-        "v1.GetOauth2ConfigurationResV1": {
+        "v1.GetOauth2ConfigurationResV1": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "data": {
+                    "type": "object",
+                    "$ref": "#/definitions/v1.GetOauth2ConfigurationResDataV1"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "v1.GetOauth2TipsResDataV1": {
+            "type": "object",
+            "properties": {
+                "enable_oauth2": {
+                    "type": "boolean"
+                },
+                "login_tip": {
+                    "type": "string"
+                }
+            }
+        },
+        "v1.GetOauth2TipsResV1": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "data": {
+                    "type": "object",
+                    "$ref": "#/definitions/v1.GetOauth2TipsResDataV1"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "v1.GetOperationsResV1": {
             "type": "object",
             "properties": {
                 "code": {
@@ -5431,8 +6659,10 @@ var doc = `{
                     "example": 0
                 },
                 "data": {
-                    "type": "object",
-                    "$ref": "#/definitions/v1.GetOauth2ConfigurationResDataV1"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.OperationResV1"
+                    }
                 },
                 "message": {
                     "type": "string",
@@ -5440,18 +6670,26 @@ var doc = `{
                 }
             }
         },
-        "v1.GetOauth2TipsResDataV1": {
+        "v1.GetPluginsResV1": {
             "type": "object",
             "properties": {
-                "enable_oauth2": {
-                    "type": "boolean"
+                "code": {
+                    "type": "integer",
+                    "example": 0
                 },
-                "login_tip": {
-                    "type": "string"
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.PluginResV1"
+                    }
+                },
+                "message": {
+                    "type": "string",
+                    "example": "ok"
                 }
             }
         },
-        "v1.GetOauth2TipsResV1": {
+        "v1.GetRoleTipsResV1": {
             "type": "object",
             "properties": {
                 "code": {
@@ -5459,8 +6697,10 @@ var doc = `{
                     "example": 0
                 },
                 "data": {
-                    "type": "object",
-                    "$ref": "#/definitions/v1.GetOauth2TipsResDataV1"
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.RoleTipResV1"
+                    }
                 },
                 "message": {
                     "type": "string",
@@ -5468,7 +6708,7 @@ var doc = `{
                 }
             }
         },
-        "v1.GetOperationsResV1": {
+        "v1.GetRolesResV1": {
             "type": "object",
             "properties": {
                 "code": {
@@ -5478,16 +6718,19 @@ var doc = `{
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/v1.OperationResV1"
+                        "$ref": "#/definitions/v1.RoleResV1"
                     }
                 },
                 "message": {
                     "type": "string",
                     "example": "ok"
+                },
+                "total_nums": {
+                    "type": "integer"
                 }
             }
         },
-        "v1.GetRoleTipsResV1": {
+        "v1.GetRuleExemptionResV1": {
             "type": "object",
             "properties": {
                 "code": {
@@ -5495,10 +6738,8 @@ var doc = `{
                     "example": 0
                 },
                 "data": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/v1.RoleTipResV1"
-                    }
+                    "type": "object",
+                    "$ref": "#/definitions/v1.RuleExemptionResV1"
                 },
                 "message": {
                     "type": "string",
@@ -5506,7 +6747,7 @@ var doc = `{
                 }
             }
         },
-        "v1.GetRolesResV1": {
+        "v1.GetRuleExemptionsResV1": {
             "type": "object",
             "properties": {
                 "code": {
@@ -5516,7 +6757,7 @@ var doc = `{
                 "data": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/v1.RoleResV1"
+                        "$ref": "#/definitions/v1.RuleExemptionResV1"
                     }
                 },
                 "message": {
@@ -5885,6 +7126,48 @@ var doc = `{
                 }
             }
         },
+        "v1.ImportRuleTemplateResDataV1": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "boolean"
+                },
+                "not_exist_instance_names": {
+                    "description": "NotExistInstanceNames are the bound instances which are not exist in\nthis SQLE server, they are skipped when importing.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "rule_template_name": {
+                    "type": "string"
+                },
+                "unknown_rule_names": {
+                    "description": "UnknownRuleNames are the rules which are not supported by this SQLE\nserver, they may be removed or added by a newer version, and they are\nskipped when importing.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "v1.ImportRuleTemplateResV1": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "data": {
+                    "type": "object",
+                    "$ref": "#/definitions/v1.ImportRuleTemplateResDataV1"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
         "v1.InstanceAdditionalMetaV1": {
             "type": "object",
             "properties": {
@@ -5951,6 +7234,19 @@ var doc = `{
                         "$ref": "#/definitions/v1.InstanceAdditionalParamResV1"
                     }
                 },
+                "capabilities": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "rollback",
+                        "tx",
+                        "explain",
+                        "schemas",
+                        "online_ddl"
+                    ]
+                },
                 "db_host": {
                     "type": "string",
                     "example": "10.10.10.10"
@@ -6201,6 +7497,39 @@ var doc = `{
                 }
             }
         },
+        "v1.PluginResV1": {
+            "type": "object",
+            "properties": {
+                "last_error": {
+                    "type": "string"
+                },
+                "pid": {
+                    "type": "integer"
+                },
+                "plugin_name": {
+                    "type": "string"
+                },
+                "plugin_path": {
+                    "type": "string"
+                },
+                "restart_count": {
+                    "type": "integer"
+                },
+                "started_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "running",
+                        "restarting"
+                    ]
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
         "v1.RejectWorkflowReqV1": {
             "type": "object",
             "properties": {
@@ -6240,6 +7569,61 @@ var doc = `{
                 }
             }
         },
+        "v1.RollbackTaskOnWorkflowReqV1": {
+            "type": "object",
+            "properties": {
+                "sql_number_list": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "task_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "v1.RuleExemptionResV1": {
+            "type": "object",
+            "properties": {
+                "approved_at": {
+                    "type": "string"
+                },
+                "approver": {
+                    "type": "string"
+                },
+                "expired_at": {
+                    "type": "string"
+                },
+                "fingerprint_sql": {
+                    "type": "string"
+                },
+                "instance_name": {
+                    "type": "string"
+                },
+                "is_expired": {
+                    "type": "boolean"
+                },
+                "justification": {
+                    "type": "string"
+                },
+                "rule_exemption_id": {
+                    "type": "integer"
+                },
+                "rule_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "schema_name": {
+                    "type": "string"
+                },
+                "table_name": {
+                    "type": "string"
+                }
+            }
+        },
         "v1.RuleParamReqV1": {
             "type": "object",
             "properties": {
@@ -6302,6 +7686,9 @@ var doc = `{
                 "desc": {
                     "type": "string"
                 },
+                "is_custom": {
+                    "type": "boolean"
+                },
                 "level": {
                     "type": "string",
                     "enum": [
@@ -6409,6 +7796,50 @@ var doc = `{
                 }
             }
         },
+        "v1.TaskProgressResV1": {
+            "type": "object",
+            "properties": {
+                "canceled": {
+                    "type": "boolean"
+                },
+                "current_sql": {
+                    "type": "string"
+                },
+                "current_sql_elapsed_seconds": {
+                    "type": "integer"
+                },
+                "current_sql_numbers": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "elapsed_seconds": {
+                    "type": "integer"
+                },
+                "finished_sql_count": {
+                    "type": "integer"
+                },
+                "rollback": {
+                    "description": "Rollback is the progress of rolling back the executed SQLs after the\nexecution failed.",
+                    "type": "object",
+                    "$ref": "#/definitions/v1.TaskProgressResV1"
+                },
+                "row_affects": {
+                    "type": "integer"
+                },
+                "running": {
+                    "type": "boolean"
+                },
+                "task_status": {
+                    "description": "TaskStatus is only set in the \"done\" event.",
+                    "type": "string"
+                },
+                "total_sql_count": {
+                    "type": "integer"
+                }
+            }
+        },
         "v1.TestAuditPlanNotifyConfigResDataV1": {
             "type": "object",
             "properties": {
@@ -6654,6 +8085,30 @@ var doc = `{
                 }
             }
         },
+        "v1.UpdateCustomRuleReqV1": {
+            "type": "object",
+            "properties": {
+                "desc": {
+                    "type": "string"
+                },
+                "level": {
+                    "type": "string",
+                    "enum": [
+                        "normal",
+                        "notice",
+                        "warn",
+                        "error"
+                    ]
+                },
+                "matcher": {
+                    "type": "object",
+                    "$ref": "#/definitions/v1.CustomRuleMatcherV1"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
         "v1.UpdateInstanceReqV1": {
             "type": "object",
             "properties": {
@@ -6738,6 +8193,35 @@ var doc = `{
                 }
             }
         },
+        "v1.UpdateRuleExemptionReqV1": {
+            "type": "object",
+            "properties": {
+                "expired_at": {
+                    "type": "string"
+                },
+                "fingerprint_sql": {
+                    "type": "string"
+                },
+                "instance_name": {
+                    "type": "string"
+                },
+                "justification": {
+                    "type": "string"
+                },
+                "rule_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "schema_name": {
+                    "type": "string"
+                },
+                "table_name": {
+                    "type": "string"
+                }
+            }
+        },
         "v1.UpdateRuleTemplateReqV1": {
             "type": "object",
             "properties": {
@@ -6847,6 +8331,13 @@ var doc = `{
         "v1.UpdateWorkflowReqV1": {
             "type": "object",
             "properties": {
+                "targets": {
+                    "description": "Targets is the same as CreateWorkflowReqV1.Targets, the targets of the\nrejected workflow are used if it is empty.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.WorkflowTargetReqV1"
+                    }
+                },
                 "task_id": {
                     "type": "string"
                 }
@@ -6875,6 +8366,14 @@ var doc = `{
                 "desc": {
                     "type": "string"
                 },
+                "exec_failure_strategy": {
+                    "type": "string",
+                    "enum": [
+                        "stop",
+                        "continue",
+                        "rollback_executed"
+                    ]
+                },
                 "instance_name_list": {
                     "type": "array",
                     "items": {
@@ -7059,7 +8558,8 @@ var doc = `{
                     "type": "string",
                     "enum": [
                         "sql_review",
-                        "sql_execute"
+                        "sql_execute",
+                        "sql_rollback"
                     ]
                 }
             }
@@ -7124,6 +8624,7 @@ var doc = `{
                         "exec_scheduled",
                         "executing",
                         "exec_failed",
+                        "exec_partial_success",
                         "finished"
                     ]
                 },
@@ -7168,12 +8669,20 @@ var doc = `{
                         "exec_scheduled",
                         "executing",
                         "exec_failed",
+                        "exec_partial_success",
                         "finished"
                     ]
                 },
                 "task_id": {
                     "type": "integer"
                 },
+                "task_list": {
+                    "description": "Tasks is the tasks of all targets, each target is executed with its own status.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/v1.AuditTaskResV1"
+                    }
+                },
                 "workflow_step_list": {
                     "type": "array",
                     "items": {
@@ -7281,6 +8790,17 @@ var doc = `{
                 }
             }
         },
+        "v1.WorkflowTargetReqV1": {
+            "type": "object",
+            "properties": {
+                "instance_name": {
+                    "type": "string"
+                },
+                "instance_schema": {
+                    "type": "string"
+                }
+            }
+        },
         "v1.WorkflowTemplateDetailResV1": {
             "type": "object",
             "properties": {
@@ -7296,6 +8816,14 @@ var doc = `{
                 "desc": {
                     "type": "string"
                 },
+                "exec_failure_strategy": {
+                    "type": "string",
+                    "enum": [
+                        "stop",
+                        "continue",
+                        "rollback_executed"
+                    ]
+                },
                 "instance_name_list": {
                     "type": "array",
                     "items": {
@@ -0,0 +1,210 @@
+package driver
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/actiontech/sqle/sqle/pkg/expr"
+)
+
+// CapabilityCustomRule means driver evaluates the rules defined by user, see CustomRule.
+const CapabilityCustomRule Capability = "custom_rule"
+
+// CapabilityExprRule means driver evaluates the custom rules of
+// CustomRuleMatchTypeExpr, see package pkg/expr.
+const CapabilityExprRule Capability = "expr_rule"
+
+// CustomRuleNamePrefix is the prefix of custom rule name, it prevents the
+// custom rules from conflicting with the rules of driver.
+const CustomRuleNamePrefix = "custom_"
+
+// The match types of custom rule.
+const (
+	// CustomRuleMatchTypeSQL matches the regular expression against SQL text.
+	CustomRuleMatchTypeSQL = "sql"
+	// CustomRuleMatchTypeFingerprint matches the regular expression against
+	// the fingerprint of SQL, the literals are replaced by "?".
+	CustomRuleMatchTypeFingerprint = "fingerprint"
+	// CustomRuleMatchTypeAST matches the predicate against the syntax tree.
+	CustomRuleMatchTypeAST = "ast"
+	// CustomRuleMatchTypeExpr evaluates the expression of package pkg/expr
+	// against the JSON view of statement, the statement is matched if the
+	// result is true.
+	CustomRuleMatchTypeExpr = "expr"
+)
+
+// The statement types of AST predicate.
+const (
+	CustomRuleStmtSelect      = "select"
+	CustomRuleStmtInsert      = "insert"
+	CustomRuleStmtUpdate      = "update"
+	CustomRuleStmtDelete      = "delete"
+	CustomRuleStmtCreateTable = "create_table"
+	CustomRuleStmtAlterTable  = "alter_table"
+	CustomRuleStmtDropTable   = "drop_table"
+	CustomRuleStmtCreateIndex = "create_index"
+	CustomRuleStmtDropIndex   = "drop_index"
+	CustomRuleStmtTruncate    = "truncate"
+)
+
+var customRuleStmtTypes = []string{
+	CustomRuleStmtSelect, CustomRuleStmtInsert, CustomRuleStmtUpdate, CustomRuleStmtDelete,
+	CustomRuleStmtCreateTable, CustomRuleStmtAlterTable, CustomRuleStmtDropTable,
+	CustomRuleStmtCreateIndex, CustomRuleStmtDropIndex, CustomRuleStmtTruncate,
+}
+
+// CustomRule is the definition of rule which is defined by user and stored in
+// SQLE, it is evaluated by the driver with CapabilityCustomRule. The name,
+// level and category of the rule are kept in Rule.
+type CustomRule struct {
+	MatchType string `json:"match_type"`
+
+	// Pattern is the regular expression for CustomRuleMatchTypeSQL and
+	// CustomRuleMatchTypeFingerprint.
+	Pattern string `json:"pattern,omitempty"`
+
+	// The following fields are the AST predicate for CustomRuleMatchTypeAST,
+	// the statement is matched if all of the non-empty conditions are satisfied.
+
+	// StmtTypes are the types of statement, such as "update".
+	StmtTypes []string `json:"stmt_types,omitempty"`
+	// TablePattern is the regular expression matched against the names of
+	// tables in statement, it is satisfied if any table name is matched.
+	TablePattern string `json:"table_pattern,omitempty"`
+	// WhereWithoutColumn is satisfied if the WHERE clause of UPDATE, DELETE
+	// or SELECT does not use the column, or there is no WHERE clause.
+	WhereWithoutColumn string `json:"where_without_column,omitempty"`
+	// ColumnPattern is the regular expression matched against the names of
+	// columns in statement, it is satisfied if any column name is matched.
+	ColumnPattern string `json:"column_pattern,omitempty"`
+
+	// Expr is the expression for CustomRuleMatchTypeExpr, the variables of
+	// expression are provided by the driver, such as "sql" and "ast".
+	Expr string `json:"expr,omitempty"`
+
+	// MessageTemplate is the text/template of finding message, the fields of
+	// CustomRuleMessageData can be used, such as "{{.Table}}". Rule.Desc is
+	// used if it is empty.
+	MessageTemplate string `json:"message_template,omitempty"`
+}
+
+// CustomRuleMessageData is the data of CustomRule.MessageTemplate.
+type CustomRuleMessageData struct {
+	Rule string
+	SQL  string
+	// Match is the text matched by Pattern.
+	Match string
+	// Table and Column are the first table and column matched by the AST
+	// predicate, Table is the first table of statement if there is no
+	// TablePattern.
+	Table  string
+	Column string
+}
+
+// Validate checks the match type, regular expressions and message template.
+func (r *CustomRule) Validate() error {
+	patterns := map[string]string{}
+	switch r.MatchType {
+	case CustomRuleMatchTypeSQL, CustomRuleMatchTypeFingerprint:
+		if r.Pattern == "" {
+			return fmt.Errorf("pattern is required for match type %s", r.MatchType)
+		}
+		patterns["pattern"] = r.Pattern
+	case CustomRuleMatchTypeAST:
+		if len(r.StmtTypes) == 0 && r.TablePattern == "" && r.WhereWithoutColumn == "" && r.ColumnPattern == "" {
+			return fmt.Errorf("at least one condition is required for match type %s", r.MatchType)
+		}
+		for _, typ := range r.StmtTypes {
+			if !isCustomRuleStmtType(typ) {
+				return fmt.Errorf("statement type %s is invalid, it should be one of %s",
+					typ, strings.Join(customRuleStmtTypes, ", "))
+			}
+		}
+		patterns["table_pattern"] = r.TablePattern
+		patterns["column_pattern"] = r.ColumnPattern
+	case CustomRuleMatchTypeExpr:
+		if r.Expr == "" {
+			return fmt.Errorf("expr is required for match type %s", r.MatchType)
+		}
+		if _, err := expr.Compile(r.Expr); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("match type %s is invalid, it should be one of %s, %s, %s, %s", r.MatchType,
+			CustomRuleMatchTypeSQL, CustomRuleMatchTypeFingerprint, CustomRuleMatchTypeAST, CustomRuleMatchTypeExpr)
+	}
+	for name, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%s is invalid: %v", name, err)
+		}
+	}
+	if _, err := template.New("message").Parse(r.MessageTemplate); err != nil {
+		return fmt.Errorf("message template is invalid: %v", err)
+	}
+	return nil
+}
+
+// Capability returns the capability of driver which evaluates the rule.
+func (r *CustomRule) Capability() Capability {
+	if r.MatchType == CustomRuleMatchTypeExpr {
+		return CapabilityExprRule
+	}
+	return CapabilityCustomRule
+}
+
+func isCustomRuleStmtType(typ string) bool {
+	for _, t := range customRuleStmtTypes {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// Message renders the finding message of rule by MessageTemplate.
+func (r *CustomRule) Message(rule *Rule, data *CustomRuleMessageData) string {
+	if r.MessageTemplate == "" {
+		return rule.Desc
+	}
+	tpl, err := template.New("message").Parse(r.MessageTemplate)
+	if err != nil {
+		return rule.Desc
+	}
+	buf := bytes.Buffer{}
+	if err := tpl.Execute(&buf, data); err != nil {
+		return rule.Desc
+	}
+	return buf.String()
+}
+
+// Scan impl sql.Scanner interface
+func (r *CustomRule) Scan(value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal json value: %v", value)
+	}
+	if len(bytes) == 0 {
+		return nil
+	}
+	return json.Unmarshal(bytes, r)
+}
+
+// Value impl sql.driver.Valuer interface
+func (r *CustomRule) Value() (driver.Value, error) {
+	if r == nil {
+		return nil, nil
+	}
+	v, err := json.Marshal(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal json value: %v", v)
+	}
+	return v, err
+}
@@ -26,6 +26,10 @@ var (
 	// additionalParams store driver additional params
 	additionalParams   map[string]params.Params
 	additionalParamsMu sync.RWMutex
+
+	// capabilities store optional features which driver supports.
+	capabilities   map[string]Capabilities
+	capabilitiesMu sync.RWMutex
 )
 
 const (
@@ -85,6 +89,49 @@ func RuleLevelLessOrEqual(a, b string) bool {
 	return RuleLevel(a).LessOrEqual(RuleLevel(b))
 }
 
+// Capability is an optional feature of driver. The host should check it
+// before calling the optional method of Driver, and hide the unsupported actions.
+type Capability string
+
+const (
+	// CapabilityRollback means Driver.GenRollbackSQL can generate rollback SQL.
+	CapabilityRollback Capability = "rollback"
+	// CapabilityTx means Driver.Tx can execute DMLs in one transaction.
+	CapabilityTx Capability = "tx"
+	// CapabilityExplain means driver checks SQL by explain on audit.
+	CapabilityExplain Capability = "explain"
+	// CapabilitySchemas means Driver.Schemas can list schemas of instance.
+	CapabilitySchemas Capability = "schemas"
+	// CapabilityOnlineDDL means driver executes DDL by online DDL tools, such as gh-ost.
+	CapabilityOnlineDDL Capability = "online_ddl"
+	// CapabilityDryRun means driver implements DryRunner.
+	CapabilityDryRun Capability = "dry_run"
+)
+
+type Capabilities []Capability
+
+func (cs Capabilities) Has(c Capability) bool {
+	for _, capability := range cs {
+		if capability == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Strings returns the names of capabilities.
+func (cs Capabilities) Strings() []string {
+	names := make([]string, 0, len(cs))
+	for _, c := range cs {
+		names = append(names, string(c))
+	}
+	return names
+}
+
+// LegacyPluginCapabilities is used for the plugins which do not report capabilities.
+// These plugins are built before capability negotiation, they behave as before.
+var LegacyPluginCapabilities = Capabilities{CapabilityRollback, CapabilityTx, CapabilitySchemas}
+
 type Rule struct {
 	Name string
 	Desc string
@@ -94,6 +141,11 @@ type Rule struct {
 	Category string
 	Level    RuleLevel
 	Params   params.Params
+
+	// Custom is the definition of rule which is defined by user, it is nil
+	// for the rules of driver. It is only evaluated by the driver with the
+	// capability of CustomRule.Capability().
+	Custom *CustomRule
 }
 
 //func (r *Rule) GetValueInt(defaultRule *Rule) int64 {
@@ -157,13 +209,24 @@ type handler func(log *logrus.Entry, c *Config) (Driver, error)
 // Register like sql.Register.
 //
 // Register makes a database driver available by the provided driver name.
-// Driver's initialize handler and audit rules register by Register.
-func Register(name string, h handler, rs []*Rule, ap params.Params) {
-	_, exist := drivers[name]
-	if exist {
+// Driver's initialize handler, audit rules and capabilities register by Register.
+func Register(name string, h handler, rs []*Rule, ap params.Params, cs Capabilities) {
+	if isRegistered(name) {
 		panic("duplicated driver name")
 	}
+	register(name, h, rs, ap, cs)
+}
+
+func isRegistered(name string) bool {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	_, exist := drivers[name]
+	return exist
+}
 
+// register is same as Register, but it replaces the driver which has the same name.
+// It is used by plugin supervisor when plugin binary is reloaded.
+func register(name string, h handler, rs []*Rule, ap params.Params, cs Capabilities) {
 	driversMu.Lock()
 	drivers[name] = h
 	driversMu.Unlock()
@@ -181,6 +244,25 @@ func Register(name string, h handler, rs []*Rule, ap params.Params) {
 	}
 	additionalParams[name] = ap
 	additionalParamsMu.Unlock()
+
+	capabilitiesMu.Lock()
+	if capabilities == nil {
+		capabilities = make(map[string]Capabilities)
+	}
+	capabilities[name] = cs
+	capabilitiesMu.Unlock()
+}
+
+// unregister makes the driver unavailable, the rules of driver are kept for
+// the rule templates which are created before.
+func unregister(name string) {
+	driversMu.Lock()
+	delete(drivers, name)
+	driversMu.Unlock()
+
+	capabilitiesMu.Lock()
+	delete(capabilities, name)
+	capabilitiesMu.Unlock()
 }
 
 type DriverNotSupportedError struct {
@@ -191,12 +273,21 @@ func (e *DriverNotSupportedError) Error() string {
 	return fmt.Sprintf("driver type %v is not supported", e.DriverTyp)
 }
 
-// NewDriver return a new instantiated Driver.
+// NewDriver return a new instantiated Driver. The driver connected to instance is
+// taken from pool if the driver pool is initialized, Close puts it back to pool.
 func NewDriver(log *logrus.Entry, dbType string, cfg *Config) (Driver, error) {
-	driversMu.RLock()
-	defer driversMu.RUnlock()
+	if p := getDriverPool(); p != nil && cfg.DSN != nil {
+		return p.get(log, dbType, cfg)
+	}
+	return newDriver(log, dbType, cfg)
+}
 
+func newDriver(log *logrus.Entry, dbType string, cfg *Config) (Driver, error) {
+	// the handler is called without the lock, since the handler of plugin
+	// may take the lock of plugin supervisor which registers drivers.
+	driversMu.RLock()
 	d, exist := drivers[dbType]
+	driversMu.RUnlock()
 	if !exist {
 		return nil, fmt.Errorf("driver type %v is not supported", dbType)
 	}
@@ -204,15 +295,22 @@ func NewDriver(log *logrus.Entry, dbType string, cfg *Config) (Driver, error) {
 	return d(log, cfg)
 }
 
-func AllRules() map[string][]*Rule {
+// AllRules returns a copy of the rules of all drivers, since the rules of
+// plugin are replaced when the plugin is reloaded.
+func AllRules() map[string] /*driver name*/ []*Rule {
 	rulesMu.RLock()
 	defer rulesMu.RUnlock()
-	return rules
+
+	newRules := make(map[string][]*Rule, len(rules))
+	for k, v := range rules {
+		newRules[k] = append([]*Rule{}, v...)
+	}
+	return newRules
 }
 
 func AllDrivers() []string {
-	rulesMu.RLock()
-	defer rulesMu.RUnlock()
+	driversMu.RLock()
+	defer driversMu.RUnlock()
 
 	driverNames := make([]string, 0, len(drivers))
 	for n := range drivers {
@@ -232,6 +330,31 @@ func AllAdditionalParams() map[string] /*driver name*/ params.Params {
 	return newParams
 }
 
+func AllCapabilities() map[string] /*driver name*/ Capabilities {
+	capabilitiesMu.RLock()
+	defer capabilitiesMu.RUnlock()
+
+	newCapabilities := map[string]Capabilities{}
+	for k, v := range capabilities {
+		newCapabilities[k] = append(Capabilities{}, v...)
+	}
+	return newCapabilities
+}
+
+// GetCapabilities return the capabilities of driver.
+func GetCapabilities(dbType string) Capabilities {
+	capabilitiesMu.RLock()
+	defer capabilitiesMu.RUnlock()
+	return append(Capabilities{}, capabilities[dbType]...)
+}
+
+// HasCapability return whether the driver supports capability c or not.
+func HasCapability(dbType string, c Capability) bool {
+	capabilitiesMu.RLock()
+	defer capabilitiesMu.RUnlock()
+	return capabilities[dbType].Has(c)
+}
+
 var ErrNodesCountExceedOne = errors.New("after parse, nodes count exceed one")
 
 // Driver is a interface that must be implemented by a database.
@@ -268,10 +391,32 @@ type Driver interface {
 	// driver should keep SQL context during it's lifecycle.
 	Audit(ctx context.Context, sql string) (*AuditResult, error)
 
+	// AuditBatch audit SQLs with rules in order, it returns one AuditResult for each SQL.
+	//
+	// It is same as calling Audit for each SQL in the same context, but driver
+	// over gRPC can audit all SQLs in one round trip.
+	AuditBatch(ctx context.Context, sqls []string) ([]*AuditResult, error)
+
 	// GenRollbackSQL generate sql's rollback SQL.
 	GenRollbackSQL(ctx context.Context, sql string) (string, string, error)
 }
 
+// ErrDryRunSkipped is returned by DryRunner when the SQL can not be dry run,
+// such as the DDL which can not be rolled back.
+var ErrDryRunSkipped = errors.New("SQL is skipped in dry run since it can not be rolled back")
+
+// DryRunner is an optional interface for Driver, it runs SQL without changing
+// the data and structure of database, so the impact of SQL is known before
+// executing it.
+type DryRunner interface {
+	// DryRunExec runs single SQL, it returns ErrDryRunSkipped if the SQL can
+	// not be dry run.
+	DryRunExec(ctx context.Context, query string) (driver.Result, error)
+
+	// DryRunTx runs DMLs in one transaction and rolls it back.
+	DryRunTx(ctx context.Context, queries ...string) ([]driver.Result, error)
+}
+
 // Registerer is the interface that all SQLe plugins must support.
 type Registerer interface {
 	// Name returns plugin name.
@@ -284,6 +429,18 @@ type Registerer interface {
 	AdditionalParams() params.Params
 }
 
+// CapabilityRegisterer is an optional interface for Registerer, the plugin
+// without it has LegacyPluginCapabilities.
+type CapabilityRegisterer interface {
+	// Capabilities returns all optional features that plugin supported.
+	Capabilities() Capabilities
+}
+
+// Versioner is an optional interface for Registerer, the version is shown in plugin status.
+type Versioner interface {
+	Version() string
+}
+
 // Node is a interface which unify SQL ast tree. It produce by Driver.Parse.
 type Node struct {
 	// Text is the raw SQL text of Node.
@@ -308,17 +465,59 @@ type Node struct {
 // }
 
 type AuditResult struct {
-	results []*auditResult
+	results []*AuditResultItem
+}
+
+// AuditResultItem is a single finding produced by auditing one SQL.
+type AuditResultItem struct {
+	Level   RuleLevel
+	Message string
+
+	// RuleName is the name of rule which produces the finding. It is empty
+	// when the finding is not produced by rule, such as the base validation.
+	RuleName string
+	Category string
+
+	// Position is the location of the offending node in the audited SQL.
+	// It is nil if driver can not locate the node.
+	Position *Position
+
+	// Suggestion is an optional rewrite of the audited SQL which fixes the finding.
+	Suggestion string
+}
+
+// Position is the location in SQL text. Offset is the byte offset from the
+// beginning of SQL text, Line starts from 1.
+type Position struct {
+	Offset int
+	Line   int
 }
 
-type auditResult struct {
-	level   RuleLevel
-	message string
+// NewPosition return the position of offset in sqlText.
+func NewPosition(sqlText string, offset int) *Position {
+	if offset < 0 || offset > len(sqlText) {
+		return nil
+	}
+	return &Position{
+		Offset: offset,
+		Line:   strings.Count(sqlText[:offset], "\n") + 1,
+	}
+}
+
+// NewStmtPosition return the position where stmt begins in sqlText, the
+// leading blanks of stmt are skipped. It is the beginning of sqlText if stmt
+// is not found.
+func NewStmtPosition(sqlText, stmt string) *Position {
+	offset := strings.Index(sqlText, strings.TrimSpace(stmt))
+	if offset < 0 {
+		offset = 0
+	}
+	return NewPosition(sqlText, offset)
 }
 
 func NewInspectResults() *AuditResult {
 	return &AuditResult{
-		results: []*auditResult{},
+		results: []*AuditResultItem{},
 	}
 }
 
@@ -326,8 +525,8 @@ func NewInspectResults() *AuditResult {
 func (rs *AuditResult) Level() RuleLevel {
 	level := RuleLevelNull
 	for _, curr := range rs.results {
-		if ruleLevelMap[curr.level] > ruleLevelMap[level] {
-			level = curr.level
+		if ruleLevelMap[curr.Level] > ruleLevelMap[level] {
+			level = curr.Level
 		}
 	}
 	return level
@@ -336,35 +535,86 @@ func (rs *AuditResult) Level() RuleLevel {
 func (rs *AuditResult) Message() string {
 	messages := make([]string, len(rs.results))
 	for n, result := range rs.results {
-		var message string
-		match, _ := regexp.MatchString(fmt.Sprintf(`^\[%s|%s|%s|%s|%s\]`,
-			RuleLevelError, RuleLevelWarn, RuleLevelNotice, RuleLevelNormal, "osc"),
-			result.message)
-		if match {
-			message = result.message
-		} else {
-			message = fmt.Sprintf("[%s]%s", result.level, result.message)
-		}
-		messages[n] = message
+		messages[n] = result.String()
 	}
 	return strings.Join(messages, "\n")
 }
 
+// String return the message with level prefix, such as "[error]message".
+func (r *AuditResultItem) String() string {
+	match, _ := regexp.MatchString(fmt.Sprintf(`^\[%s|%s|%s|%s|%s\]`,
+		RuleLevelError, RuleLevelWarn, RuleLevelNotice, RuleLevelNormal, "osc"),
+		r.Message)
+	if match {
+		return r.Message
+	}
+	return fmt.Sprintf("[%s]%s", r.Level, r.Message)
+}
+
 func (rs *AuditResult) Add(level RuleLevel, message string, args ...interface{}) {
 	if level == "" || message == "" {
 		return
 	}
 
-	rs.results = append(rs.results, &auditResult{
-		level:   level,
-		message: fmt.Sprintf(message, args...),
+	rs.AddResult(&AuditResultItem{
+		Level:   level,
+		Message: fmt.Sprintf(message, args...),
 	})
+}
+
+// AddResult add a finding with rule name, position and suggestion to result.
+// Finding without level or message will be ignored.
+func (rs *AuditResult) AddResult(result *AuditResultItem) {
+	if result == nil || result.Level == "" || result.Message == "" {
+		return
+	}
+
+	rs.results = append(rs.results, result)
 	rs.SortByLevel()
 }
 
+// SetDefaultPosition sets pos to the findings which are not located by driver.
+func (rs *AuditResult) SetDefaultPosition(pos *Position) {
+	if pos == nil {
+		return
+	}
+	for _, result := range rs.results {
+		if result.Position == nil {
+			p := *pos
+			result.Position = &p
+		}
+	}
+}
+
+// SetStmtPosition locates the findings of stmt in sqlText. The positions set by
+// driver are relative to the beginning of stmt, and the findings which are not
+// located by driver point at the beginning of stmt.
+func (rs *AuditResult) SetStmtPosition(sqlText, stmt string) {
+	stmtPos := NewStmtPosition(sqlText, stmt)
+	if stmtPos == nil {
+		return
+	}
+	for _, result := range rs.results {
+		var pos *Position
+		if result.Position != nil {
+			pos = NewPosition(sqlText, stmtPos.Offset+result.Position.Offset)
+		}
+		if pos == nil {
+			p := *stmtPos
+			pos = &p
+		}
+		result.Position = pos
+	}
+}
+
+// Results return all findings which sorted by level.
+func (rs *AuditResult) Results() []*AuditResultItem {
+	return rs.results
+}
+
 func (rs *AuditResult) SortByLevel() {
-	sort.Slice(rs.results, func(i, j int) bool {
-		return rs.results[i].level.More(rs.results[j].level)
+	sort.SliceStable(rs.results, func(i, j int) bool {
+		return rs.results[i].Level.More(rs.results[j].Level)
 	})
 }
 
@@ -987,6 +987,10 @@ func (i *Inspect) checkInvalidSelect(stmt *ast.SelectStmt) error {
 
 // checkUnparsedStmt might add more check in future.
 func (i *Inspect) checkUnparsedStmt(stmt *ast.UnparsedStmt) error {
+	// the placement policy statement of TiDB is not supported by parser, but it is valid.
+	if i.isTiDBMode && util.IsTiDBPlacementPolicyStmt(stmt.Text()) {
+		return nil
+	}
 	i.result.Add(driver.RuleLevelError, "语法错误或者解析器不支持")
 	return nil
 }
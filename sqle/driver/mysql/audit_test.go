@@ -66,7 +66,8 @@ func DefaultMysqlInspect() *Inspect {
 			Password:     "123456",
 			DatabaseName: "mysql",
 		},
-		Ctx: session.NewMockContext(nil),
+		Ctx:          session.NewMockContext(nil),
+		capabilities: instanceCapabilities(false),
 		cnf: &Config{
 			DDLOSCMinSize:      16,
 			DDLGhostMinSize:    -1,
@@ -86,7 +87,8 @@ func NewMockInspect(e *executor.Executor) *Inspect {
 			Password:     "123456",
 			DatabaseName: "mysql",
 		},
-		Ctx: session.NewMockContext(e),
+		Ctx:          session.NewMockContext(e),
+		capabilities: instanceCapabilities(false),
 		cnf: &Config{
 			DDLOSCMinSize:      16,
 			DDLGhostMinSize:    16,
@@ -165,6 +167,34 @@ func TestMessage(t *testing.T) {
 		"use no_exist_db", newTestResult().add(driver.RuleLevelError, "schema no_exist_db 不存在"))
 }
 
+func TestAuditResultPosition(t *testing.T) {
+	cases := []struct {
+		ruleName string
+		sql      string
+		expect   *driver.Position
+	}{
+		// located at the select field
+		{rulepkg.DMLDisableSelectAllColumn, "\n\nselect * from exist_db.exist_tb_1", &driver.Position{Offset: 9, Line: 3}},
+		// located at the column referenced by the expression
+		{rulepkg.DMLWhereExistNull, "select id from exist_db.exist_tb_1\nwhere v1 is null", &driver.Position{Offset: 41, Line: 2}},
+		// located at the column definition, "id" in "idx" is not matched
+		{rulepkg.DDLCheckColumnWithoutComment,
+			"create table exist_db.t1 (\nidx int comment 'x',\nid int\n)", &driver.Position{Offset: 48, Line: 3}},
+		// the finding without offending node falls back to the beginning of statement
+		{rulepkg.DMLCheckWhereIsInvalid, "\nselect id from exist_db.exist_tb_1", &driver.Position{Offset: 1, Line: 2}},
+	}
+	for _, c := range cases {
+		i := DefaultMysqlInspect()
+		rule := rulepkg.RuleHandlerMap[c.ruleName].Rule
+		i.rules = []*driver.Rule{&rule}
+		result, err := i.Audit(context.TODO(), c.sql)
+		if !assert.NoError(t, err) || !assert.Len(t, result.Results(), 1, c.sql) {
+			t.FailNow()
+		}
+		assert.Equal(t, c.expect, result.Results()[0].Position, c.sql)
+	}
+}
+
 func TestCheckInvalidUse(t *testing.T) {
 	runDefaultRulesInspectCase(t, "use_database: database not exist", DefaultMysqlInspect(),
 		"use no_exist_db",
@@ -3968,3 +3998,99 @@ func TestInspect_CheckColumn(t *testing.T) {
 	`,
 		newTestResult())
 }
+
+func DefaultTiDBInspect() *Inspect {
+	i := DefaultMysqlInspect()
+	i.isTiDBMode = true
+	i.capabilities = instanceCapabilities(true)
+	i.cnf.DDLOSCMinSize = -1
+	i.cnf.DDLGhostMinSize = -1
+	return i
+}
+
+func TestTiDBCheckAutoIncrementPK(t *testing.T) {
+	rule := rulepkg.RuleHandlerMap[rulepkg.TiDBCheckAutoIncrementPK].Rule
+	runSingleRuleInspectCase(rule, t, "tidb: auto increment primary key", DefaultTiDBInspect(),
+		`CREATE TABLE exist_db.not_exist_tb_1 (id bigint unsigned NOT NULL AUTO_INCREMENT, PRIMARY KEY (id));`,
+		newTestResult().addResult(rulepkg.TiDBCheckAutoIncrementPK, "id"))
+	runSingleRuleInspectCase(rule, t, "tidb: auto random primary key", DefaultTiDBInspect(),
+		`CREATE TABLE exist_db.not_exist_tb_1 (id bigint AUTO_RANDOM(5) PRIMARY KEY CLUSTERED) PLACEMENT POLICY=p1;`,
+		newTestResult())
+	runSingleRuleInspectCase(rule, t, "tidb: shard row id bits", DefaultTiDBInspect(),
+		`CREATE TABLE exist_db.not_exist_tb_1 (id bigint AUTO_INCREMENT, PRIMARY KEY (id) NONCLUSTERED) SHARD_ROW_ID_BITS=4;`,
+		newTestResult())
+	runSingleRuleInspectCase(rule, t, "mysql: auto increment primary key", DefaultMysqlInspect(),
+		`CREATE TABLE exist_db.not_exist_tb_1 (id bigint unsigned NOT NULL AUTO_INCREMENT, PRIMARY KEY (id));`,
+		newTestResult())
+}
+
+func TestTiDBModePKWithoutAutoIncrement(t *testing.T) {
+	rule := rulepkg.RuleHandlerMap[rulepkg.DDLCheckPKWithoutAutoIncrement].Rule
+	tidbRule := rulepkg.RuleHandlerMap[rulepkg.TiDBCheckAutoIncrementPK].Rule
+	i := DefaultTiDBInspect()
+	i.rules = []*driver.Rule{&rule, &tidbRule}
+	inspectCase(t, "tidb: auto random primary key", i,
+		`CREATE TABLE exist_db.not_exist_tb_1 (id bigint AUTO_RANDOM PRIMARY KEY);`,
+		newTestResult())
+	i = DefaultTiDBInspect()
+	i.rules = []*driver.Rule{&rule, &tidbRule}
+	inspectCase(t, "tidb: auto increment primary key", i,
+		`CREATE TABLE exist_db.not_exist_tb_1 (id bigint unsigned NOT NULL AUTO_INCREMENT, PRIMARY KEY (id));`,
+		newTestResult().addResult(rulepkg.TiDBCheckAutoIncrementPK, "id"))
+
+	runSingleRuleInspectCase(rule, t, "mysql: primary key without auto increment", DefaultMysqlInspect(),
+		`CREATE TABLE exist_db.not_exist_tb_1 (id bigint unsigned NOT NULL, PRIMARY KEY (id));`,
+		newTestResult().addResult(rulepkg.DDLCheckPKWithoutAutoIncrement))
+}
+
+func TestTiDBDisableFK(t *testing.T) {
+	rule := rulepkg.RuleHandlerMap[rulepkg.TiDBDisableFK].Rule
+	sql := `CREATE TABLE exist_db.not_exist_tb_1 (id bigint PRIMARY KEY, v1 bigint unsigned NOT NULL,
+FOREIGN KEY (v1) REFERENCES exist_db.exist_tb_1(id));`
+	runSingleRuleInspectCase(rule, t, "tidb: foreign key", DefaultTiDBInspect(), sql,
+		newTestResult().addResult(rulepkg.TiDBDisableFK))
+	runSingleRuleInspectCase(rule, t, "mysql: foreign key", DefaultMysqlInspect(), sql,
+		newTestResult())
+}
+
+func TestTiDBModeSkipInnoDBRules(t *testing.T) {
+	rule := rulepkg.RuleHandlerMap[rulepkg.DDLCheckTableDBEngine].Rule
+	sql := `CREATE TABLE exist_db.not_exist_tb_1 (id bigint PRIMARY KEY) ENGINE=MyISAM;`
+	runSingleRuleInspectCase(rule, t, "mysql: engine", DefaultMysqlInspect(), sql,
+		newTestResult().addResult(rulepkg.DDLCheckTableDBEngine, "Innodb"))
+	runSingleRuleInspectCase(rule, t, "tidb: engine", DefaultTiDBInspect(), sql,
+		newTestResult())
+}
+
+func TestTiDBModePlacementPolicy(t *testing.T) {
+	rule := rulepkg.RuleHandlerMap[rulepkg.DDLCheckCreateTrigger].Rule
+	sql := `CREATE PLACEMENT POLICY p1 PRIMARY_REGION="us-east-1" REGIONS="us-east-1,us-west-1";`
+	runSingleRuleInspectCase(rule, t, "tidb: create placement policy", DefaultTiDBInspect(), sql,
+		newTestResult())
+	runSingleRuleInspectCase(rule, t, "mysql: create placement policy", DefaultMysqlInspect(), sql,
+		newTestResult().add(driver.RuleLevelError, "语法错误或者解析器不支持"))
+}
+
+func TestTiDBModeOnlineDDL(t *testing.T) {
+	oscRule := rulepkg.RuleHandlerMap[rulepkg.ConfigDDLOSCMinSize].Rule
+	ghostRule := rulepkg.RuleHandlerMap[rulepkg.ConfigDDLGhostMinSize].Rule
+	rules := []*driver.Rule{&oscRule, &ghostRule}
+	cnf := newInspectConfig(rules, instanceCapabilities(false))
+	assert.NotEqual(t, int64(-1), cnf.DDLOSCMinSize)
+	assert.NotEqual(t, int64(-1), cnf.DDLGhostMinSize)
+
+	cnf = newInspectConfig(rules, instanceCapabilities(true))
+	assert.Equal(t, int64(-1), cnf.DDLOSCMinSize)
+	assert.Equal(t, int64(-1), cnf.DDLGhostMinSize)
+}
+
+func TestInspectCapabilities(t *testing.T) {
+	explainRule := rulepkg.RuleHandlerMap[rulepkg.ConfigDMLExplainPreCheckEnable].Rule
+	rules := []*driver.Rule{&explainRule}
+	assert.True(t, newInspectConfig(rules, instanceCapabilities(false)).dmlExplainPreCheckEnable)
+	assert.False(t, newInspectConfig(rules, driver.Capabilities{}).dmlExplainPreCheckEnable)
+
+	handler := rulepkg.RuleHandlerMap[rulepkg.DMLCheckExplainAccessTypeAll]
+	assert.True(t, handler.IsAllowCapabilities(instanceCapabilities(false)))
+	assert.False(t, handler.IsAllowCapabilities(driver.Capabilities{}))
+}
@@ -0,0 +1,101 @@
+package executor
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// StatementDigest is the row of performance_schema.events_statements_summary_by_digest,
+// ref to https://dev.mysql.com/doc/refman/8.0/en/performance-schema-statement-summary-tables.html
+type StatementDigest struct {
+	Schema     string `json:"schema_name"`
+	DigestText string `json:"digest_text"`
+	// SampleSQL is QUERY_SAMPLE_TEXT, which is supported since MySQL 8.0.3,
+	// it is empty on the lower version.
+	SampleSQL string `json:"sample_sql"`
+
+	CountStar          int64  `json:"count_star"`
+	SumTimerWait       uint64 `json:"sum_timer_wait"`
+	SumRowsExamined    int64  `json:"sum_rows_examined"`
+	SumRowsSent        int64  `json:"sum_rows_sent"`
+	SumNoIndexUsed     int64  `json:"sum_no_index_used"`
+	SumNoGoodIndexUsed int64  `json:"sum_no_good_index_used"`
+	LastSeen           string `json:"last_seen"`
+}
+
+// Note: the timer columns are in picoseconds.
+const (
+	StatementDigestTpl = `
+SELECT
+	COALESCE(SCHEMA_NAME, '') AS schema_name,
+	DIGEST_TEXT AS digest_text,
+	%v AS sample_sql,
+	COUNT_STAR AS count_star,
+	SUM_TIMER_WAIT AS sum_timer_wait,
+	SUM_ROWS_EXAMINED AS sum_rows_examined,
+	SUM_ROWS_SENT AS sum_rows_sent,
+	SUM_NO_INDEX_USED AS sum_no_index_used,
+	SUM_NO_GOOD_INDEX_USED AS sum_no_good_index_used,
+	LAST_SEEN AS last_seen
+FROM performance_schema.events_statements_summary_by_digest
+WHERE DIGEST_TEXT IS NOT NULL
+AND (SCHEMA_NAME IS NULL OR SCHEMA_NAME NOT IN ('mysql', 'sys', 'performance_schema', 'information_schema'))
+ORDER BY %v DESC
+LIMIT %v`
+
+	StatementDigestColumnCountStar       = "count_star"
+	StatementDigestColumnSumTimerWait    = "sum_timer_wait"
+	StatementDigestColumnSumRowsExamined = "sum_rows_examined"
+	StatementDigestColumnSumNoIndexUsed  = "sum_no_index_used"
+)
+
+// StatementDigestOrderByColumns is the columns which can be used to sort the top digests.
+var StatementDigestOrderByColumns = map[string]struct{}{
+	StatementDigestColumnCountStar:       {},
+	StatementDigestColumnSumTimerWait:    {},
+	StatementDigestColumnSumRowsExamined: {},
+	StatementDigestColumnSumNoIndexUsed:  {},
+}
+
+// ShowTopStatementDigests returns the top N statement digests order by the column in StatementDigestOrderByColumns.
+func (c *Executor) ShowTopStatementDigests(topN int, orderBy string) ([]*StatementDigest, error) {
+	if _, ok := StatementDigestOrderByColumns[orderBy]; !ok {
+		return nil, fmt.Errorf("invalid order by column: %s", orderBy)
+	}
+
+	result, err := c.Db.Query(`SELECT COUNT(*) AS count FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = 'performance_schema' AND TABLE_NAME = 'events_statements_summary_by_digest' AND COLUMN_NAME = 'QUERY_SAMPLE_TEXT'`)
+	if err != nil {
+		return nil, err
+	}
+	sampleColumn := "''"
+	if len(result) == 1 && result[0]["count"].String != "0" {
+		sampleColumn = "COALESCE(QUERY_SAMPLE_TEXT, '')"
+	}
+
+	result, err = c.Db.Query(fmt.Sprintf(StatementDigestTpl, sampleColumn, orderBy, topN))
+	if err != nil {
+		return nil, err
+	}
+	digests := make([]*StatementDigest, 0, len(result))
+	for _, record := range result {
+		digest := &StatementDigest{
+			Schema:     record["schema_name"].String,
+			DigestText: record["digest_text"].String,
+			SampleSQL:  record["sample_sql"].String,
+			LastSeen:   record["last_seen"].String,
+		}
+		digest.SumTimerWait, _ = strconv.ParseUint(record["sum_timer_wait"].String, 10, 64)
+		for column, value := range map[string]*int64{
+			"count_star":             &digest.CountStar,
+			"sum_rows_examined":      &digest.SumRowsExamined,
+			"sum_rows_sent":          &digest.SumRowsSent,
+			"sum_no_index_used":      &digest.SumNoIndexUsed,
+			"sum_no_good_index_used": &digest.SumNoGoodIndexUsed,
+		} {
+			*value, _ = strconv.ParseInt(record[column].String, 10, 64)
+		}
+		digests = append(digests, digest)
+	}
+	return digests, nil
+}
@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShowTopStatementDigests(t *testing.T) {
+	e, mock, err := NewMockExecutor()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	_, err = e.ShowTopStatementDigests(3, "sum_lock_time")
+	assert.Error(t, err)
+
+	columns := []string{"schema_name", "digest_text", "sample_sql", "count_star", "sum_timer_wait", "sum_rows_examined",
+		"sum_rows_sent", "sum_no_index_used", "sum_no_good_index_used", "last_seen"}
+
+	// MySQL 8.0 supports QUERY_SAMPLE_TEXT.
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) AS count FROM information_schema.COLUMNS").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow("1"))
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(StatementDigestTpl, "COALESCE(QUERY_SAMPLE_TEXT, '')", "sum_rows_examined", 2))).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("db1", "SELECT * FROM `t1` WHERE `id` = ?", "select * from t1 where id = 1", "10", "18446744073709551615", "1000", "10", "10", "0", "2022-01-01 10:00:00").
+			AddRow("", "SELECT ?", "select 1", "5", "100", "0", "5", "0", "0", "2022-01-01 10:00:01"))
+	digests, err := e.ShowTopStatementDigests(2, StatementDigestColumnSumRowsExamined)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []*StatementDigest{
+		{
+			Schema:          "db1",
+			DigestText:      "SELECT * FROM `t1` WHERE `id` = ?",
+			SampleSQL:       "select * from t1 where id = 1",
+			CountStar:       10,
+			SumTimerWait:    18446744073709551615,
+			SumRowsExamined: 1000,
+			SumRowsSent:     10,
+			SumNoIndexUsed:  10,
+			LastSeen:        "2022-01-01 10:00:00",
+		},
+		{
+			DigestText:   "SELECT ?",
+			SampleSQL:    "select 1",
+			CountStar:    5,
+			SumTimerWait: 100,
+			SumRowsSent:  5,
+			LastSeen:     "2022-01-01 10:00:01",
+		},
+	}, digests)
+
+	// MySQL 5.7 does not support QUERY_SAMPLE_TEXT.
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) AS count FROM information_schema.COLUMNS").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow("0"))
+	mock.ExpectQuery(regexp.QuoteMeta(fmt.Sprintf(StatementDigestTpl, "''", "sum_timer_wait", 1))).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow("db1", "SELECT ?", "", "5", "100", "0", "5", "0", "0", "2022-01-01 10:00:01"))
+	digests, err = e.ShowTopStatementDigests(1, StatementDigestColumnSumTimerWait)
+	assert.NoError(t, err)
+	if assert.Len(t, digests, 1) {
+		assert.Equal(t, "SELECT ?", digests[0].DigestText)
+		assert.Equal(t, "", digests[0].SampleSQL)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -20,7 +20,10 @@ type Db interface {
 	Close()
 	Ping() error
 	Exec(query string) (driver.Result, error)
+	ExecContext(ctx context.Context, query string) (driver.Result, error)
 	Transact(qs ...string) ([]driver.Result, error)
+	TransactContext(ctx context.Context, qs ...string) ([]driver.Result, error)
+	TransactWithRollback(ctx context.Context, qs ...string) ([]driver.Result, error)
 	Query(query string, args ...interface{}) ([]map[string]sql.NullString, error)
 	Logger() *logrus.Entry
 }
@@ -32,6 +35,10 @@ type BaseConn struct {
 	user string
 	db   *sql.DB
 	conn *sql.Conn
+
+	// inst is used to connect to MySQL again for killing the running query.
+	inst         *mdriver.DSN
+	connectionId string
 }
 
 func newConn(entry *logrus.Entry, instance *mdriver.DSN, schema string) (*BaseConn, error) {
@@ -61,6 +68,7 @@ func newConn(entry *logrus.Entry, instance *mdriver.DSN, schema string) (*BaseCo
 		user: instance.User,
 		db:   db,
 		conn: conn,
+		inst: instance,
 	}, nil
 }
 
@@ -83,7 +91,18 @@ func (c *BaseConn) Ping() error {
 }
 
 func (c *BaseConn) Exec(query string) (driver.Result, error) {
+	return c.ExecContext(context.Background(), query)
+}
+
+// ExecContext executes query, the query is killed by "KILL QUERY" if ctx is
+// canceled before it is finished.
+func (c *BaseConn) ExecContext(ctx context.Context, query string) (driver.Result, error) {
+	stop, err := c.killQueryOnCancel(ctx)
+	if err != nil {
+		return nil, errors.New(errors.ConnectRemoteDatabaseError, err)
+	}
 	result, err := c.conn.ExecContext(context.Background(), query)
+	stop()
 	if err != nil {
 		c.Logger().Errorf("exec sql failed; host: %s, port: %s, user: %s, query: %s, error: %s",
 			c.host, c.port, c.user, query, err.Error())
@@ -94,11 +113,81 @@ func (c *BaseConn) Exec(query string) (driver.Result, error) {
 	return result, errors.New(errors.ConnectRemoteDatabaseError, err)
 }
 
+// killQueryOnCancel kills the running query of connection on another connection
+// when ctx is canceled, since MySQL keeps running the query even if the client
+// gives up waiting. The query is executed without ctx, so the connection is still
+// available after the query is killed. The returned function must be called when
+// the query is finished.
+func (c *BaseConn) killQueryOnCancel(ctx context.Context) (func(), error) {
+	if ctx.Done() == nil {
+		return func() {}, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := c.loadConnectionId(ctx); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+		case <-ctx.Done():
+			c.killQuery()
+		}
+	}()
+	return func() { close(done) }, nil
+}
+
+func (c *BaseConn) loadConnectionId(ctx context.Context) error {
+	if c.connectionId != "" {
+		return nil
+	}
+	return c.conn.QueryRowContext(ctx, "SELECT CONNECTION_ID()").Scan(&c.connectionId)
+}
+
+func (c *BaseConn) killQuery() {
+	c.Logger().Infof("kill query of connection %s; host: %s, port: %s", c.connectionId, c.host, c.port)
+	conn, err := newConn(c.log, c.inst, "")
+	if err != nil {
+		c.Logger().Errorf("connect to kill query failed, error: %v", err)
+		return
+	}
+	defer conn.Close()
+	if _, err := conn.conn.ExecContext(context.Background(), fmt.Sprintf("KILL QUERY %s", c.connectionId)); err != nil {
+		c.Logger().Errorf("kill query of connection %s failed, error: %v", c.connectionId, err)
+	}
+}
+
 func (c *BaseConn) Transact(qs ...string) ([]driver.Result, error) {
+	return c.transact(context.Background(), true, qs...)
+}
+
+// TransactContext executes queries in transaction, the running query is killed
+// and the transaction is rolled back if ctx is canceled.
+func (c *BaseConn) TransactContext(ctx context.Context, qs ...string) ([]driver.Result, error) {
+	return c.transact(ctx, true, qs...)
+}
+
+// TransactWithRollback executes queries in transaction and always rolls it back,
+// it is used to get the affected rows of queries without changing the data. The
+// running query is killed if ctx is canceled.
+func (c *BaseConn) TransactWithRollback(ctx context.Context, qs ...string) ([]driver.Result, error) {
+	return c.transact(ctx, false, qs...)
+}
+
+func (c *BaseConn) transact(ctx context.Context, commit bool, qs ...string) ([]driver.Result, error) {
 	var err error
 	var tx *sql.Tx
 	var results []driver.Result
 	c.Logger().Infof("doing sql transact, host: %s, port: %s, user: %s", c.host, c.port, c.user)
+	// the connection id is required to kill the query in transaction.
+	if ctx.Done() != nil {
+		if err = c.loadConnectionId(ctx); err != nil {
+			return results, err
+		}
+	}
 	tx, err = c.conn.BeginTx(context.Background(), nil)
 	if err != nil {
 		return results, err
@@ -118,6 +207,13 @@ func (c *BaseConn) Transact(qs ...string) ([]driver.Result, error) {
 			}
 			return
 		}
+		if !commit {
+			c.Logger().Info("rollback sql transact without commit")
+			if err := tx.Rollback(); err != nil {
+				c.Logger().Error("rollback sql transact failed, err:", err)
+			}
+			return
+		}
 		err = tx.Commit()
 		if err != nil {
 			c.Logger().Error("transact commit failed")
@@ -127,7 +223,12 @@ func (c *BaseConn) Transact(qs ...string) ([]driver.Result, error) {
 	}()
 	for _, query := range qs {
 		var txResult driver.Result
+		var stop func()
+		if stop, err = c.killQueryOnCancel(ctx); err != nil {
+			return results, err
+		}
 		txResult, err = tx.Exec(query)
+		stop()
 		if err != nil {
 			c.Logger().Errorf("exec sql failed, error: %s, query: %s", err, query)
 			return results, err
@@ -353,6 +454,11 @@ func (c *Executor) Explain(query string) ([]*ExplainRecord, error) {
 	ret := make([]*ExplainRecord, len(records))
 	for i, record := range records {
 		rows, _ := strconv.ParseInt(record["rows"].String, 10, 64)
+		// TiDB returns the estimated rows in column "estRows", such as "10000.00".
+		if estRows, ok := record["estRows"]; ok {
+			f, _ := strconv.ParseFloat(estRows.String, 64)
+			rows = int64(f)
+		}
 		ret[i] = &ExplainRecord{
 			Id:           record["id"].String,
 			SelectType:   record["select_type"].String,
@@ -424,6 +530,21 @@ where table_schema = '%s' and table_name = '%s'`, schema, table)
 	}
 	return size, nil
 }
+
+// ShowTableEngine returns the storage engine of table, it returns "" if the
+// table is not exist.
+func (c *Executor) ShowTableEngine(schema, table string) (string, error) {
+	result, err := c.Db.Query("select ENGINE as Engine from information_schema.tables where table_schema = ? and table_name = ?",
+		schema, table)
+	if err != nil {
+		return "", err
+	}
+	if len(result) == 0 {
+		return "", nil
+	}
+	return result[0]["Engine"].String, nil
+}
+
 func (c *Executor) ShowDefaultConfiguration(sql, column string) (string, error) {
 	result, err := c.Db.Query(sql)
 	if err != nil {
@@ -27,13 +27,32 @@ func init() {
 		allRules[i] = &rulepkg.RuleHandlers[i].Rule
 	}
 
-	driver.Register(driver.DriverTypeMySQL, newInspect, allRules, params.Params{})
+	driver.Register(driver.DriverTypeMySQL, newInspect, allRules, params.Params{
+		&params.Param{
+			Key:   paramKeyTiDBMode,
+			Desc:  "TiDB模式(实例为TiDB时开启)",
+			Value: "false",
+			Type:  params.ParamTypeBool,
+		},
+	}, driver.Capabilities{
+		driver.CapabilityRollback,
+		driver.CapabilityTx,
+		driver.CapabilityExplain,
+		driver.CapabilitySchemas,
+		driver.CapabilityOnlineDDL,
+		driver.CapabilityDryRun,
+		driver.CapabilityCustomRule,
+	})
 
 	if err := LoadPtTemplateFromFile("./scripts/pt-online-schema-change.template"); err != nil {
 		panic(err)
 	}
 }
 
+// paramKeyTiDBMode is the additional param of instance, the instance is
+// audited as TiDB if it is true.
+const paramKeyTiDBMode = "tidb_mode"
+
 // Inspect implements driver.Driver interface
 type Inspect struct {
 	// Ctx is SQL session.
@@ -58,6 +77,12 @@ type Inspect struct {
 	isConnected bool
 	// isOfflineAudit represent Audit without instance.
 	isOfflineAudit bool
+	// isTiDBMode represent the instance is TiDB, the TiDB specific syntax and
+	// rules are enabled, and the InnoDB only rules are skipped.
+	isTiDBMode bool
+	// capabilities are the capabilities of instance, the features and rules
+	// which need the missing capabilities are skipped.
+	capabilities driver.Capabilities
 }
 
 func newInspect(log *logrus.Entry, cfg *driver.Config) (driver.Driver, error) {
@@ -71,6 +96,7 @@ func newInspect(log *logrus.Entry, cfg *driver.Config) (driver.Driver, error) {
 		inspect.isConnected = true
 		inspect.dbConn = conn
 		inspect.inst = cfg.DSN
+		inspect.isTiDBMode = cfg.DSN.AdditionalParams.GetParam(paramKeyTiDBMode).Bool()
 
 		ctx := session.NewContext(nil, session.WithExecutor(conn))
 		ctx.SetCurrentSchema(cfg.DSN.DatabaseName)
@@ -85,36 +111,149 @@ func newInspect(log *logrus.Entry, cfg *driver.Config) (driver.Driver, error) {
 	inspect.rules = cfg.Rules
 	inspect.result = driver.NewInspectResults()
 	inspect.isOfflineAudit = cfg.DSN == nil
+	inspect.capabilities = instanceCapabilities(inspect.isTiDBMode)
+
+	inspect.cnf = newInspectConfig(cfg.Rules, inspect.capabilities)
+
+	return inspect, nil
+}
+
+// instanceCapabilities returns the capabilities of MySQL driver. gh-ost and
+// pt-osc are not supported by TiDB, so TiDB instance has no online DDL, the
+// DDL is always executed directly in TiDB mode.
+func instanceCapabilities(isTiDBMode bool) driver.Capabilities {
+	capabilities := driver.Capabilities{}
+	for _, c := range driver.GetCapabilities(driver.DriverTypeMySQL) {
+		if isTiDBMode && c == driver.CapabilityOnlineDDL {
+			continue
+		}
+		capabilities = append(capabilities, c)
+	}
+	return capabilities
+}
 
-	inspect.cnf = &Config{
+// newInspectConfig read task cnf from config rules. The config of online DDL
+// and explain are ignored if the instance does not have the capabilities.
+func newInspectConfig(rules []*driver.Rule, capabilities driver.Capabilities) *Config {
+	cnf := &Config{
 		DMLRollbackMaxRows: -1,
 		DDLOSCMinSize:      -1,
 		DDLGhostMinSize:    -1,
 	}
-	for _, rule := range cfg.Rules {
+	for _, rule := range rules {
 		if rule.Name == rulepkg.ConfigDMLRollbackMaxRows {
 			max := rule.Params.GetParam(rulepkg.DefaultSingleParamKeyName).Int()
-			inspect.cnf.DMLRollbackMaxRows = int64(max)
+			cnf.DMLRollbackMaxRows = int64(max)
 		}
-		if rule.Name == rulepkg.ConfigDDLOSCMinSize {
+		if rule.Name == rulepkg.ConfigDDLOSCMinSize && capabilities.Has(driver.CapabilityOnlineDDL) {
 			min := rule.Params.GetParam(rulepkg.DefaultSingleParamKeyName).Int()
-			inspect.cnf.DDLOSCMinSize = int64(min)
+			cnf.DDLOSCMinSize = int64(min)
 		}
-		if rule.Name == rulepkg.ConfigDDLGhostMinSize {
+		if rule.Name == rulepkg.ConfigDDLGhostMinSize && capabilities.Has(driver.CapabilityOnlineDDL) {
 			min := rule.Params.GetParam(rulepkg.DefaultSingleParamKeyName).Int()
-			inspect.cnf.DDLGhostMinSize = int64(min)
+			cnf.DDLGhostMinSize = int64(min)
 		}
 		if rule.Name == rulepkg.ConfigOptimizeIndexEnabled {
-			inspect.cnf.optimizeIndexEnabled = true
-			inspect.cnf.calculateCardinalityMaxRow = rule.Params.GetParam(rulepkg.DefaultMultiParamsFirstKeyName).Int()
-			inspect.cnf.compositeIndexMaxColumn = rule.Params.GetParam(rulepkg.DefaultMultiParamsSecondKeyName).Int()
+			cnf.optimizeIndexEnabled = true
+			cnf.calculateCardinalityMaxRow = rule.Params.GetParam(rulepkg.DefaultMultiParamsFirstKeyName).Int()
+			cnf.compositeIndexMaxColumn = rule.Params.GetParam(rulepkg.DefaultMultiParamsSecondKeyName).Int()
 		}
-		if rule.Name == rulepkg.ConfigDMLExplainPreCheckEnable {
-			inspect.cnf.dmlExplainPreCheckEnable = true
+		if rule.Name == rulepkg.ConfigDMLExplainPreCheckEnable && capabilities.Has(driver.CapabilityExplain) {
+			cnf.dmlExplainPreCheckEnable = true
 		}
 	}
+	return cnf
+}
 
-	return inspect, nil
+// Reset implements driver.Resetter. It drops the SQL context and the audit
+// result of the previous task, so that the driver can be reused by the next
+// task. The connection is kept if it is still alive, the session of it is
+// reset by resetSession.
+func (i *Inspect) Reset(ctx context.Context, rules []*driver.Rule) error {
+	i.rules = rules
+	i.cnf = newInspectConfig(rules, i.capabilities)
+	i.result = driver.NewInspectResults()
+	i.HasInvalidSql = false
+
+	if i.IsOfflineAudit() {
+		i.Ctx = session.NewContext(nil)
+		return nil
+	}
+
+	if err := i.resetDbConn(); err != nil {
+		return errors.Wrap(err, "reset connection in inspect")
+	}
+	i.Ctx = session.NewContext(nil, session.WithExecutor(i.dbConn))
+	i.Ctx.SetCurrentSchema(i.inst.DatabaseName)
+	return nil
+}
+
+// resetSessionQueries restores the session state which may be changed by the
+// SQL of the previous task. The user variables and temporary tables are kept,
+// since they can not be listed in all MySQL versions.
+var resetSessionQueries = []string{
+	"ROLLBACK",
+	"SET SESSION autocommit = DEFAULT",
+	"SET SESSION sql_mode = DEFAULT",
+	"SET SESSION foreign_key_checks = DEFAULT",
+	"SET SESSION unique_checks = DEFAULT",
+	"SET SESSION time_zone = DEFAULT",
+	"SET NAMES utf8",
+}
+
+// resetDbConn reuses the connection of the previous task, it reconnects only if
+// the connection is not alive or the session can not be reset.
+func (i *Inspect) resetDbConn() error {
+	if i.isConnected {
+		err := i.dbConn.Db.Ping()
+		if err == nil {
+			err = i.resetSession()
+		}
+		if err == nil {
+			return nil
+		}
+		i.log.Warnf("reuse connection failed, reconnect: %v", err)
+	}
+
+	i.closeDbConn()
+	conn, err := executor.NewExecutor(i.log, i.inst, i.inst.DatabaseName)
+	if err != nil {
+		return errors.Wrap(err, "new executor in inspect")
+	}
+	i.isConnected = true
+	i.dbConn = conn
+	return nil
+}
+
+// resetSession resets the session variables and switches back to the schema
+// of instance if the previous task has changed it by "USE".
+func (i *Inspect) resetSession() error {
+	for _, query := range resetSessionQueries {
+		if _, err := i.dbConn.Db.Exec(query); err != nil {
+			return err
+		}
+	}
+	result, err := i.dbConn.Db.Query("SELECT DATABASE() AS current_schema")
+	if err != nil {
+		return err
+	}
+	var schema string
+	if len(result) > 0 {
+		schema = result[0]["current_schema"].String
+	}
+	if schema == i.inst.DatabaseName {
+		return nil
+	}
+	if i.inst.DatabaseName == "" {
+		return fmt.Errorf("schema %s is selected by the previous task", schema)
+	}
+	_, err = i.dbConn.Db.Exec(fmt.Sprintf("USE `%s`", i.inst.DatabaseName))
+	return err
+}
+
+// SetLogger implements driver.LoggerSetter.
+func (i *Inspect) SetLogger(log *logrus.Entry) {
+	i.log = log
 }
 
 func (i *Inspect) IsOfflineAudit() bool {
@@ -181,7 +320,7 @@ func (i *Inspect) Exec(ctx context.Context, query string) (_driver.Result, error
 	if err != nil {
 		return nil, err
 	}
-	return conn.Db.Exec(query)
+	return conn.Db.ExecContext(ctx, query)
 }
 
 func (i *Inspect) onlineddlWithGhost(query string) (bool, error) {
@@ -215,7 +354,100 @@ func (i *Inspect) Tx(ctx context.Context, queries ...string) ([]_driver.Result,
 	if err != nil {
 		return nil, err
 	}
-	return conn.Db.Transact(queries...)
+	return conn.Db.TransactContext(ctx, queries...)
+}
+
+// DryRunExec implements driver.DryRunner. DML is executed in transaction which
+// is rolled back, DDL is run by gh-ost in noop mode if it is executed by gh-ost,
+// the other DDLs are skipped since MySQL can not roll back them.
+func (i *Inspect) DryRunExec(ctx context.Context, query string) (_driver.Result, error) {
+	if i.IsOfflineAudit() {
+		return nil, nil
+	}
+
+	nodes, err := i.ParseSql(query)
+	if err != nil {
+		return nil, errors.Wrap(err, "parse SQL")
+	}
+	if _, ok := nodes[0].(ast.DMLNode); ok {
+		results, err := i.DryRunTx(ctx, query)
+		if err != nil {
+			return nil, err
+		}
+		return results[0], nil
+	}
+
+	useGhost, err := i.onlineddlWithGhost(query)
+	if err != nil {
+		return nil, errors.Wrap(err, "check whether use ghost or not")
+	}
+	if useGhost {
+		return i.executeByGhost(ctx, query, true)
+	}
+	return nil, driver.ErrDryRunSkipped
+}
+
+// DryRunTx implements driver.DryRunner.
+func (i *Inspect) DryRunTx(ctx context.Context, queries ...string) ([]_driver.Result, error) {
+	if i.IsOfflineAudit() {
+		return nil, nil
+	}
+	conn, err := i.getDbConn()
+	if err != nil {
+		return nil, err
+	}
+	if err := i.checkDryRunTables(conn, queries); err != nil {
+		return nil, err
+	}
+	return conn.Db.TransactWithRollback(ctx, queries...)
+}
+
+// transactionalEngines are the storage engines which roll back the changes of
+// transaction.
+var transactionalEngines = map[string]struct{}{
+	"innodb":     {},
+	"tokudb":     {},
+	"rocksdb":    {},
+	"ndbcluster": {},
+}
+
+// checkDryRunTables returns driver.ErrDryRunSkipped if the DMLs change the
+// tables of non-transactional engine, such as MyISAM. The changes on these
+// tables are kept after the transaction is rolled back.
+func (i *Inspect) checkDryRunTables(conn *executor.Executor, queries []string) error {
+	for _, query := range queries {
+		nodes, err := i.ParseSql(query)
+		if err != nil {
+			return errors.Wrap(err, "parse SQL")
+		}
+		for _, table := range getDMLTargetTables(nodes[0]) {
+			schema := i.Ctx.GetSchemaName(table)
+			engine, err := conn.ShowTableEngine(schema, table.Name.String())
+			if err != nil {
+				return err
+			}
+			if _, ok := transactionalEngines[strings.ToLower(engine)]; engine != "" && !ok {
+				return fmt.Errorf("%w: table %s.%s uses %s engine which does not support transaction",
+					driver.ErrDryRunSkipped, schema, table.Name.String(), engine)
+			}
+		}
+	}
+	return nil
+}
+
+// getDMLTargetTables returns the tables changed by DML. All tables of the
+// update and delete with join are returned, since the changed ones are not
+// distinguished.
+func getDMLTargetTables(node ast.Node) []*ast.TableName {
+	switch stmt := node.(type) {
+	case *ast.InsertStmt:
+		return util.GetTables(stmt.Table.TableRefs)
+	case *ast.UpdateStmt:
+		return util.GetTables(stmt.TableRefs.TableRefs)
+	case *ast.DeleteStmt:
+		return util.GetTables(stmt.TableRefs.TableRefs)
+	}
+	return nil
 }
 
 func (i *Inspect) Query(ctx context.Context, query string, args ...interface{}) ([]map[string]sql.NullString, error) {
@@ -290,10 +522,22 @@ func (i *Inspect) Audit(ctx context.Context, sql string) (*driver.AuditResult, e
 		i.Logger().Warnf("SQL %s invalid, %s", nodes[0].Text(), i.result.Message())
 	}
 
-	var ghostRule *driver.Rule
+	var ghostRule, oscRule, optimizeIndexRule *driver.Rule
 	for _, rule := range i.rules {
-		if rule.Name == rulepkg.ConfigDDLGhostMinSize {
+		switch rule.Name {
+		case rulepkg.ConfigDDLGhostMinSize:
 			ghostRule = rule
+		case rulepkg.ConfigDDLOSCMinSize:
+			oscRule = rule
+		case rulepkg.ConfigOptimizeIndexEnabled:
+			optimizeIndexRule = rule
+		}
+
+		if rule.Custom != nil {
+			if err := rulepkg.CheckCustomRule(i.Ctx, *rule, i.result, nodes[0]); err != nil {
+				return nil, err
+			}
+			continue
 		}
 
 		handler, ok := rulepkg.RuleHandlerMap[rule.Name]
@@ -303,6 +547,9 @@ func (i *Inspect) Audit(ctx context.Context, sql string) (*driver.AuditResult, e
 		if i.IsOfflineAudit() && !handler.IsAllowOfflineRule(nodes[0]) {
 			continue
 		}
+		if !handler.IsAllowTiDBMode(i.isTiDBMode) || !handler.IsAllowCapabilities(i.capabilities) {
+			continue
+		}
 		if err := handler.Func(i.Ctx, *rule, i.result, nodes[0]); err != nil {
 			return nil, err
 		}
@@ -322,13 +569,16 @@ func (i *Inspect) Audit(ctx context.Context, sql string) (*driver.AuditResult, e
 		}
 
 		var buf strings.Builder
+		suggestions := make([]string, 0, len(advices))
 		for _, advice := range advices {
 			buf.WriteString(fmt.Sprintf("建议为表 %s 列 %s 添加索引", advice.TableName, strings.Join(advice.IndexedColumns, ",")))
 			if advice.Reason != "" {
 				buf.WriteString(fmt.Sprintf(", 原因(%s)", advice.Reason))
 			}
+			suggestions = append(suggestions, genAddIndexSQL(advice))
 		}
-		i.result.Add(driver.RuleLevelNotice, buf.String())
+		i.result.AddResult(newConfigRuleResult(optimizeIndexRule, driver.RuleLevelNotice, buf.String(),
+			strings.Join(suggestions, "\n")))
 	}
 
 	// dry run gh-ost
@@ -338,9 +588,11 @@ func (i *Inspect) Audit(ctx context.Context, sql string) (*driver.AuditResult, e
 	}
 	if useGhost {
 		if _, err := i.executeByGhost(ctx, sql, true); err != nil {
-			i.result.Add(ghostRule.Level, fmt.Sprintf("表空间大小超过%vMB, 将使用gh-ost进行上线, 但是dry-run抛出如下错误: %v", i.cnf.DDLGhostMinSize, err))
+			i.result.AddResult(newConfigRuleResult(ghostRule, ghostRule.Level,
+				fmt.Sprintf("表空间大小超过%vMB, 将使用gh-ost进行上线, 但是dry-run抛出如下错误: %v", i.cnf.DDLGhostMinSize, err), ""))
 		} else {
-			i.result.Add(ghostRule.Level, fmt.Sprintf("表空间大小超过%vMB, 将使用gh-ost进行上线", i.cnf.DDLGhostMinSize))
+			i.result.AddResult(newConfigRuleResult(ghostRule, ghostRule.Level,
+				fmt.Sprintf("表空间大小超过%vMB, 将使用gh-ost进行上线", i.cnf.DDLGhostMinSize), ""))
 		}
 	}
 
@@ -350,12 +602,53 @@ func (i *Inspect) Audit(ctx context.Context, sql string) (*driver.AuditResult, e
 		return nil, err
 	}
 	if oscCommandLine != "" {
-		i.result.Add(driver.RuleLevelNotice, fmt.Sprintf("[osc]%s", oscCommandLine))
+		i.result.AddResult(newConfigRuleResult(oscRule, driver.RuleLevelNotice,
+			fmt.Sprintf("[osc]%s", oscCommandLine), oscCommandLine))
 	}
 	i.Ctx.UpdateContext(nodes[0])
+	// the rules locate the findings in statement, the others are located at
+	// the beginning of statement.
+	i.result.SetStmtPosition(sql, nodes[0].Text())
 	return i.result, nil
 }
 
+// newConfigRuleResult return a finding which produced by global config rule.
+// rule may be nil, then the finding has no rule name.
+func newConfigRuleResult(rule *driver.Rule, level driver.RuleLevel, message, suggestion string) *driver.AuditResultItem {
+	result := &driver.AuditResultItem{
+		Level:      level,
+		Message:    message,
+		Suggestion: suggestion,
+	}
+	if rule != nil {
+		result.RuleName = rule.Name
+		result.Category = rule.Category
+	}
+	return result
+}
+
+// genAddIndexSQL generate the DDL which creates the index advised by optimizer.
+func genAddIndexSQL(advice *index.OptimizeResult) string {
+	columns := make([]string, 0, len(advice.IndexedColumns))
+	for _, column := range advice.IndexedColumns {
+		columns = append(columns, fmt.Sprintf("`%s`", column))
+	}
+	indexName := fmt.Sprintf("idx_%s_%s", advice.TableName, strings.Join(advice.IndexedColumns, "_"))
+	return fmt.Sprintf("ALTER TABLE `%s` ADD INDEX `%s` (%s);", advice.TableName, indexName, strings.Join(columns, ","))
+}
+
+func (i *Inspect) AuditBatch(ctx context.Context, sqls []string) ([]*driver.AuditResult, error) {
+	results := make([]*driver.AuditResult, 0, len(sqls))
+	for _, sql := range sqls {
+		result, err := i.Audit(ctx, sql)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
 func (i *Inspect) GenRollbackSQL(ctx context.Context, sql string) (string, string, error) {
 	if i.IsOfflineAudit() {
 		return "", "", nil
@@ -422,7 +715,11 @@ func (i *Inspect) Context() *session.Context {
 }
 
 func (i *Inspect) ParseSql(sql string) ([]ast.Node, error) {
-	stmts, err := util.ParseSql(sql)
+	parse := util.ParseSql
+	if i.isTiDBMode {
+		parse = util.ParseTiDBSql
+	}
+	stmts, err := parse(sql)
 	if err != nil {
 		i.Logger().Errorf("parse sql failed, error: %v, sql: %s", err, sql)
 		return nil, err
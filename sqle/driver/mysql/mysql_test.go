@@ -2,9 +2,13 @@ package mysql
 
 import (
 	"context"
+	"errors"
+	"regexp"
 	"testing"
 
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/driver/mysql/executor"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -162,3 +166,64 @@ func TestInspect_GenRollbackSQL(t *testing.T) {
 	assert.Equal(t, "", reason)
 	assert.Equal(t, "ALTER TABLE `exist_db`.`t1`\nDROP COLUMN `c1`;", rollback)
 }
+
+func TestInspect_Reset(t *testing.T) {
+	e, handler, err := executor.NewMockExecutor()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	i := NewMockInspect(e)
+	i.isConnected = true
+
+	expectResetSession := func(schema string) {
+		for _, query := range resetSessionQueries {
+			handler.ExpectExec(regexp.QuoteMeta(query)).WillReturnResult(sqlmock.NewResult(0, 0))
+		}
+		handler.ExpectQuery(regexp.QuoteMeta("SELECT DATABASE() AS current_schema")).
+			WillReturnRows(sqlmock.NewRows([]string{"current_schema"}).AddRow(schema))
+	}
+
+	// the schema is changed by "USE" of the previous task.
+	expectResetSession("exist_db")
+	handler.ExpectExec(regexp.QuoteMeta("USE `mysql`")).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.NoError(t, i.Reset(context.TODO(), nil))
+	assert.Equal(t, e, i.dbConn)
+	assert.Equal(t, "mysql", i.Ctx.CurrentSchema())
+
+	expectResetSession("mysql")
+	assert.NoError(t, i.Reset(context.TODO(), nil))
+	assert.Equal(t, e, i.dbConn)
+	assert.NoError(t, handler.ExpectationsWereMet())
+}
+
+func TestInspect_DryRunTx(t *testing.T) {
+	e, handler, err := executor.NewMockExecutor()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	i := NewMockInspect(e)
+	i.isConnected = true
+	query := "update exist_tb_1 set v1='1' where id=1"
+	expectEngine := func(engine string) {
+		handler.ExpectQuery(regexp.QuoteMeta("select ENGINE as Engine from information_schema.tables")).
+			WithArgs("exist_db", "exist_tb_1").
+			WillReturnRows(sqlmock.NewRows([]string{"Engine"}).AddRow(engine))
+	}
+
+	// the changes on MyISAM table can not be rolled back.
+	expectEngine("MyISAM")
+	_, err = i.DryRunTx(context.TODO(), query)
+	assert.True(t, errors.Is(err, driver.ErrDryRunSkipped))
+
+	expectEngine("InnoDB")
+	handler.ExpectBegin()
+	handler.ExpectExec(regexp.QuoteMeta(query)).WillReturnResult(sqlmock.NewResult(0, 1))
+	handler.ExpectRollback()
+	results, err := i.DryRunTx(context.TODO(), query)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	rowAffects, _ := results[0].RowsAffected()
+	assert.Equal(t, int64(1), rowAffects)
+	assert.NoError(t, handler.ExpectationsWereMet())
+}
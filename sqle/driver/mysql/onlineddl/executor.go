@@ -2,16 +2,20 @@ package onlineddl
 
 import (
 	"context"
+	gosql "database/sql"
 	"fmt"
 	"math/rand"
 	"os"
+	"reflect"
 	"strconv"
 	"strings"
+	"unsafe"
 
 	"github.com/actiontech/sqle/sqle/driver"
 
 	"github.com/github/gh-ost/go/base"
 	"github.com/github/gh-ost/go/logic"
+	ghostsql "github.com/github/gh-ost/go/sql"
 	"github.com/go-ini/ini"
 	"github.com/pingcap/parser"
 	"github.com/pingcap/parser/ast"
@@ -22,7 +26,7 @@ import (
 )
 
 type Executor struct {
-	l  base.Logger
+	l  *logAdaptor
 	mc *base.MigrationContext
 }
 
@@ -96,11 +100,95 @@ func (e *Executor) Execute(ctx context.Context, dryRun bool) error {
 	}
 
 	m := logic.NewMigrator(e.mc)
-	err := m.Migrate()
-	if err != nil {
-		return errors.Wrapf(err, "migrate table, dry-run(%v)", dryRun)
+	finished := make(chan struct{})
+	var migrateErr error
+	go func() {
+		defer close(finished)
+		migrateErr = m.Migrate()
+	}()
+
+	var abortErr error
+	select {
+	case <-finished:
+		if migrateErr != nil {
+			return errors.Wrapf(migrateErr, "migrate table, dry-run(%v)", dryRun)
+		}
+		return nil
+	case <-ctx.Done():
+		abortErr = errors.Wrap(ctx.Err(), "abort gh-ost")
+		// the same as the "panic" command of gh-ost.
+		go func() {
+			select {
+			case e.mc.PanicAbort <- abortErr:
+			case <-finished:
+			}
+		}()
+	case abortErr = <-e.l.fatal:
+	}
+	e.abort(m, finished)
+	return abortErr
+}
+
+// abort aborts the running migration on the panic-abort path of gh-ost. The
+// vendored gh-ost can not stop the migration without exiting the process, so
+// the migration is finished as a dry-run: the row copy is completed at once,
+// the cut-over is skipped, and the ghost table and the changelog table are
+// dropped by the final cleanup of gh-ost. It returns after the migration is
+// finished.
+func (e *Executor) abort(m *logic.Migrator, finished <-chan struct{}) {
+	e.mc.Noop = true
+
+	// the goroutines of gh-ost may send their errors to the panic-abort path
+	// until the migration is finished, they are consumed here since gh-ost
+	// only receives the first one.
+	go func() {
+		for {
+			select {
+			case err := <-e.mc.PanicAbort:
+				e.l.Errorf("gh-ost is aborting, ignore error: %v", err)
+			case <-finished:
+				return
+			}
+		}
+	}()
+	go func() {
+		select {
+		case migratorRowCopyComplete(m) <- nil:
+		case <-finished:
+		}
+	}()
+	<-finished
+
+	// the migration may fail before the final cleanup of gh-ost.
+	if err := e.dropGhostTables(); err != nil {
+		e.l.Errorf("drop the ghost table and changelog table failed, they should be dropped by DBA: %v", err)
 	}
+}
 
+// migratorRowCopyComplete returns the channel of migrator which notifies the
+// migrator that the row copy is completed.
+func migratorRowCopyComplete(m *logic.Migrator) chan error {
+	f := reflect.ValueOf(m).Elem().FieldByName("rowCopyComplete")
+	return reflect.NewAt(f.Type(), unsafe.Pointer(f.UnsafeAddr())).Elem().Interface().(chan error)
+}
+
+func (e *Executor) dropGhostTables() error {
+	// the ghost table is not created before the master is found by gh-ost.
+	if e.mc.ApplierConnectionConfig == nil || e.mc.ApplierConnectionConfig.Key.Hostname == "" {
+		return nil
+	}
+	db, err := gosql.Open("mysql", e.mc.ApplierConnectionConfig.GetDBUri(e.mc.DatabaseName))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	for _, table := range []string{e.mc.GetGhostTableName(), e.mc.GetChangelogTableName()} {
+		query := fmt.Sprintf("DROP TABLE IF EXISTS %s.%s",
+			ghostsql.EscapeName(e.mc.DatabaseName), ghostsql.EscapeName(table))
+		if _, err := db.Exec(query); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
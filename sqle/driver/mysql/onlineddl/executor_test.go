@@ -1,9 +1,13 @@
 package onlineddl
 
 import (
+	"errors"
 	"testing"
 
+	"github.com/github/gh-ost/go/base"
+	"github.com/github/gh-ost/go/logic"
 	_ "github.com/pingcap/tidb/types/parser_driver"
+	"github.com/sirupsen/logrus"
 )
 
 func Test_parseAlterTableOptions(t *testing.T) {
@@ -65,3 +69,29 @@ func Test_parseAlterTableOptions(t *testing.T) {
 		})
 	}
 }
+
+func Test_migratorRowCopyComplete(t *testing.T) {
+	m := logic.NewMigrator(base.NewMigrationContext())
+	if migratorRowCopyComplete(m) == nil {
+		t.Errorf("migratorRowCopyComplete() got nil channel")
+	}
+}
+
+func Test_logAdaptorFatal(t *testing.T) {
+	l := newLogAdaptor(logrus.NewEntry(logrus.New()))
+	err1 := errors.New("critical-load met")
+	if err := l.Fatale(err1); err != err1 {
+		t.Errorf("Fatale() got %v, want %v", err, err1)
+	}
+	if err := l.Fatalf("unknown cut-over type: %d", 3); err == nil {
+		t.Errorf("Fatalf() got nil error")
+	}
+	select {
+	case err := <-l.fatal:
+		if err != err1 {
+			t.Errorf("fatal got %v, want %v", err, err1)
+		}
+	default:
+		t.Errorf("fatal got no error")
+	}
+}
@@ -1,18 +1,36 @@
 package onlineddl
 
 import (
+	"errors"
+	"fmt"
+
 	"github.com/openark/golib/log"
 	"github.com/sirupsen/logrus"
 )
 
 type logAdaptor struct {
 	inner *logrus.Entry
+
+	// fatal receives the first fatal error of gh-ost. gh-ost logs the fatal
+	// error on its panic-abort path and expects the process to exit, instead
+	// the executor aborts the migration, see Executor.abort().
+	fatal chan error
 }
 
 func newLogAdaptor(l *logrus.Entry) *logAdaptor {
 	return &logAdaptor{
 		inner: l,
+		fatal: make(chan error, 1),
+	}
+}
+
+func (l *logAdaptor) abort(err error) error {
+	l.inner.Error(err)
+	select {
+	case l.fatal <- err:
+	default:
 	}
+	return err
 }
 
 func (l *logAdaptor) Debug(args ...interface{}) {
@@ -57,18 +75,15 @@ func (l *logAdaptor) Errore(err error) error {
 }
 
 func (l *logAdaptor) Fatal(args ...interface{}) error {
-	l.inner.Fatal(args...)
-	return nil
+	return l.abort(errors.New(fmt.Sprint(args...)))
 }
 
 func (l *logAdaptor) Fatalf(format string, args ...interface{}) error {
-	l.inner.Fatalf(format, args...)
-	return nil
+	return l.abort(fmt.Errorf(format, args...))
 }
 
 func (l *logAdaptor) Fatale(err error) error {
-	l.inner.Fatalln(err)
-	return nil
+	return l.abort(err)
 }
 
 func (l *logAdaptor) SetLevel(level log.LogLevel) {
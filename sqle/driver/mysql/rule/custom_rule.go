@@ -0,0 +1,215 @@
+package rule
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/driver/mysql/session"
+	"github.com/actiontech/sqle/sqle/driver/mysql/util"
+	"github.com/actiontech/sqle/sqle/log"
+
+	"github.com/pingcap/parser/ast"
+)
+
+// customRuleRegexps caches the compiled regular expressions of custom rules,
+// since the same rules are checked on every audited SQL.
+var customRuleRegexps sync.Map
+
+func compileCustomRuleRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := customRuleRegexps.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	customRuleRegexps.Store(pattern, re)
+	return re, nil
+}
+
+// CheckCustomRule checks the node by the rule defined by user, see driver.CustomRule.
+// The rule is skipped if it can not be checked, such as the invalid pattern,
+// the rule is validated before it is saved.
+func CheckCustomRule(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, node ast.Node) error {
+	if rule.Custom == nil {
+		return nil
+	}
+	data := &driver.CustomRuleMessageData{
+		Rule: rule.Name,
+		SQL:  node.Text(),
+	}
+	var matched bool
+	var err error
+	switch rule.Custom.MatchType {
+	case driver.CustomRuleMatchTypeSQL:
+		matched, err = matchCustomRulePattern(rule.Custom.Pattern, node.Text(), data)
+	case driver.CustomRuleMatchTypeFingerprint:
+		var fingerprint string
+		fingerprint, err = util.Fingerprint(node.Text(), true)
+		if err == nil {
+			matched, err = matchCustomRulePattern(rule.Custom.Pattern, fingerprint, data)
+		}
+	case driver.CustomRuleMatchTypeAST:
+		matched, err = matchCustomRulePredicate(rule.Custom, node, data)
+	}
+	if err != nil {
+		log.NewEntry().Warnf("skip custom rule %s, error: %v", rule.Name, err)
+		return nil
+	}
+	if matched {
+		res.AddResult(&driver.AuditResultItem{
+			Level:    rule.Level,
+			Message:  rule.Custom.Message(&rule, data),
+			RuleName: rule.Name,
+			Category: rule.Category,
+		})
+	}
+	return nil
+}
+
+func matchCustomRulePattern(pattern, text string, data *driver.CustomRuleMessageData) (bool, error) {
+	re, err := compileCustomRuleRegexp(pattern)
+	if err != nil {
+		return false, err
+	}
+	loc := re.FindStringIndex(text)
+	if loc == nil {
+		return false, nil
+	}
+	data.Match = text[loc[0]:loc[1]]
+	return true, nil
+}
+
+// matchCustomRulePredicate returns true if all of the non-empty conditions of
+// AST predicate are satisfied.
+func matchCustomRulePredicate(r *driver.CustomRule, node ast.Node, data *driver.CustomRuleMessageData) (bool, error) {
+	if len(r.StmtTypes) > 0 {
+		stmtType := getCustomRuleStmtType(node)
+		matched := false
+		for _, typ := range r.StmtTypes {
+			if typ == stmtType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+
+	collector := &customRuleNameCollector{}
+	node.Accept(collector)
+
+	if len(collector.tables) > 0 {
+		data.Table = collector.tables[0]
+	}
+	if r.TablePattern != "" {
+		table, err := matchCustomRuleNames(r.TablePattern, collector.tables)
+		if err != nil || table == "" {
+			return false, err
+		}
+		data.Table = table
+	}
+	if r.ColumnPattern != "" {
+		column, err := matchCustomRuleNames(r.ColumnPattern, collector.columns)
+		if err != nil || column == "" {
+			return false, err
+		}
+		data.Column = column
+	}
+	if r.WhereWithoutColumn != "" {
+		where, ok := getCustomRuleWhere(node)
+		if !ok {
+			return false, nil
+		}
+		if where != nil {
+			whereCollector := &customRuleNameCollector{}
+			where.Accept(whereCollector)
+			for _, column := range whereCollector.columns {
+				if strings.EqualFold(column, r.WhereWithoutColumn) {
+					return false, nil
+				}
+			}
+		}
+		if data.Column == "" {
+			data.Column = r.WhereWithoutColumn
+		}
+	}
+	return true, nil
+}
+
+func matchCustomRuleNames(pattern string, names []string) (string, error) {
+	re, err := compileCustomRuleRegexp(pattern)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range names {
+		if re.MatchString(name) {
+			return name, nil
+		}
+	}
+	return "", nil
+}
+
+func getCustomRuleStmtType(node ast.Node) string {
+	switch node.(type) {
+	case *ast.SelectStmt, *ast.UnionStmt:
+		return driver.CustomRuleStmtSelect
+	case *ast.InsertStmt:
+		return driver.CustomRuleStmtInsert
+	case *ast.UpdateStmt:
+		return driver.CustomRuleStmtUpdate
+	case *ast.DeleteStmt:
+		return driver.CustomRuleStmtDelete
+	case *ast.CreateTableStmt:
+		return driver.CustomRuleStmtCreateTable
+	case *ast.AlterTableStmt:
+		return driver.CustomRuleStmtAlterTable
+	case *ast.DropTableStmt:
+		return driver.CustomRuleStmtDropTable
+	case *ast.CreateIndexStmt:
+		return driver.CustomRuleStmtCreateIndex
+	case *ast.DropIndexStmt:
+		return driver.CustomRuleStmtDropIndex
+	case *ast.TruncateTableStmt:
+		return driver.CustomRuleStmtTruncate
+	}
+	return ""
+}
+
+// getCustomRuleWhere returns the WHERE clause of UPDATE, DELETE and SELECT,
+// the clause is nil if it is absent. ok is false for the other statements.
+func getCustomRuleWhere(node ast.Node) (where ast.ExprNode, ok bool) {
+	switch stmt := node.(type) {
+	case *ast.UpdateStmt:
+		return stmt.Where, true
+	case *ast.DeleteStmt:
+		return stmt.Where, true
+	case *ast.SelectStmt:
+		return stmt.Where, true
+	}
+	return nil, false
+}
+
+// customRuleNameCollector implements ast.Visitor interface, it collects the
+// names of tables and columns in the order of appearance.
+type customRuleNameCollector struct {
+	tables  []string
+	columns []string
+}
+
+func (c *customRuleNameCollector) Enter(in ast.Node) (node ast.Node, skipChildren bool) {
+	switch n := in.(type) {
+	case *ast.TableName:
+		c.tables = append(c.tables, n.Name.O)
+	case *ast.ColumnName:
+		c.columns = append(c.columns, n.Name.O)
+	}
+	return in, false
+}
+
+func (c *customRuleNameCollector) Leave(in ast.Node) (node ast.Node, ok bool) {
+	return in, true
+}
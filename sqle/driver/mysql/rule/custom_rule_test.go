@@ -0,0 +1,92 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/driver/mysql/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCustomRule(t *testing.T) {
+	updateWithoutTenant := &driver.CustomRule{
+		MatchType:          driver.CustomRuleMatchTypeAST,
+		StmtTypes:          []string{driver.CustomRuleStmtUpdate, driver.CustomRuleStmtDelete},
+		TablePattern:       "^order",
+		WhereWithoutColumn: "tenant_id",
+		MessageTemplate:    "表 {{.Table}} 的 WHERE 条件必须包含 {{.Column}}",
+	}
+	columnName := &driver.CustomRule{
+		MatchType:       driver.CustomRuleMatchTypeAST,
+		ColumnPattern:   "^(?i)is_",
+		MessageTemplate: "列名 {{.Column}} 不允许使用 is_ 前缀",
+	}
+	sqlText := &driver.CustomRule{
+		MatchType: driver.CustomRuleMatchTypeSQL,
+		Pattern:   `(?i)sql_no_cache`,
+	}
+	fingerprint := &driver.CustomRule{
+		MatchType:       driver.CustomRuleMatchTypeFingerprint,
+		Pattern:         `LIMIT \?,\?`,
+		MessageTemplate: "禁止使用 {{.Match}}",
+	}
+
+	for _, c := range []struct {
+		custom  *driver.CustomRule
+		sql     string
+		message string
+	}{
+		{updateWithoutTenant, "update orders set status=1 where id=1", "[warn]表 orders 的 WHERE 条件必须包含 tenant_id"},
+		{updateWithoutTenant, "delete from order_items", "[warn]表 order_items 的 WHERE 条件必须包含 tenant_id"},
+		{updateWithoutTenant, "update orders set status=1 where id=1 and TENANT_ID=2", ""},
+		{updateWithoutTenant, "update users set status=1 where id=1", ""},
+		{updateWithoutTenant, "select * from orders where id=1", ""},
+		{columnName, "create table t1(id int, is_deleted tinyint)", "[warn]列名 is_deleted 不允许使用 is_ 前缀"},
+		{columnName, "alter table t1 add column IS_VALID int", "[warn]列名 IS_VALID 不允许使用 is_ 前缀"},
+		{columnName, "create table t1(id int, deleted tinyint)", ""},
+		{sqlText, "select SQL_NO_CACHE * from t1", "[warn]custom rule desc"},
+		{sqlText, "select * from t1", ""},
+		{fingerprint, "select * from t1 limit 10, 20", "[warn]禁止使用 LIMIT ?,?"},
+		{fingerprint, "select * from t1 limit 10", ""},
+	} {
+		node, err := util.ParseOneSql(c.sql)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		res := driver.NewInspectResults()
+		rule := driver.Rule{
+			Name:     "custom_test",
+			Desc:     "custom rule desc",
+			Category: "自定义规范",
+			Level:    driver.RuleLevelWarn,
+			Custom:   c.custom,
+		}
+		assert.NoError(t, CheckCustomRule(nil, rule, res, node))
+		assert.Equal(t, c.message, res.Message(), c.sql)
+	}
+}
+
+func TestCustomRuleValidate(t *testing.T) {
+	for _, r := range []*driver.CustomRule{
+		{MatchType: "unknown"},
+		{MatchType: driver.CustomRuleMatchTypeSQL},
+		{MatchType: driver.CustomRuleMatchTypeSQL, Pattern: "("},
+		{MatchType: driver.CustomRuleMatchTypeAST},
+		{MatchType: driver.CustomRuleMatchTypeAST, StmtTypes: []string{"merge"}},
+		{MatchType: driver.CustomRuleMatchTypeAST, ColumnPattern: "["},
+		{MatchType: driver.CustomRuleMatchTypeSQL, Pattern: "a", MessageTemplate: "{{.Table"},
+		{MatchType: driver.CustomRuleMatchTypeExpr},
+		{MatchType: driver.CustomRuleMatchTypeExpr, Expr: "ast.where =="},
+	} {
+		assert.Error(t, r.Validate(), r)
+	}
+	assert.NoError(t, (&driver.CustomRule{
+		MatchType:       driver.CustomRuleMatchTypeAST,
+		StmtTypes:       []string{driver.CustomRuleStmtUpdate},
+		MessageTemplate: "{{.Table}}",
+	}).Validate())
+	assert.NoError(t, (&driver.CustomRule{
+		MatchType: driver.CustomRuleMatchTypeExpr,
+		Expr:      `ast.type == "update" && ast.where == null`,
+	}).Validate())
+}
@@ -0,0 +1,101 @@
+package rule
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/pingcap/parser/ast"
+)
+
+// nodePosition return the position of node in the text of stmt, the offset is
+// relative to the beginning of stmt. It returns nil if node is not found, then
+// the finding falls back to the beginning of stmt.
+func nodePosition(stmt, node ast.Node) *driver.Position {
+	if stmt == nil || node == nil {
+		return nil
+	}
+	text := nodeText(node)
+	if text == "" {
+		return nil
+	}
+	pattern := "(" + regexp.QuoteMeta(text) + ")"
+	// the identifier should not be matched in the middle of the other words,
+	// e.g. column "id" in "WHERE uid = 1".
+	if isIdentByte(text[0]) {
+		pattern = `(?:^|[^\w$])` + pattern
+	}
+	if isIdentByte(text[len(text)-1]) {
+		pattern = pattern + `(?:[^\w$]|$)`
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil
+	}
+	// the leading blanks are skipped as driver.NewStmtPosition does.
+	stmtText := strings.TrimSpace(stmt.Text())
+	loc := re.FindStringSubmatchIndex(stmtText)
+	if loc == nil {
+		return nil
+	}
+	return driver.NewPosition(stmtText, loc[2])
+}
+
+// nodeText return the text which is used to search node in the statement.
+// Only the statements, subqueries and select fields keep their origin text in
+// AST, the names are used for the columns and tables, and the other expressions
+// are located at the first column they reference.
+func nodeText(node ast.Node) string {
+	switch n := node.(type) {
+	case *ast.ColumnDef:
+		return n.Name.Name.O
+	case *ast.ColumnName:
+		return n.Name.O
+	case *ast.ColumnNameExpr:
+		return n.Name.Name.O
+	case *ast.TableName:
+		return n.Name.O
+	}
+	if text := strings.TrimSpace(node.Text()); text != "" {
+		return text
+	}
+	// the parser may not keep the text of the last select field.
+	if field, ok := node.(*ast.SelectField); ok && field.WildCard != nil {
+		if field.WildCard.Table.O != "" {
+			return field.WildCard.Table.O + ".*"
+		}
+		return "*"
+	}
+	v := &firstColumnVisitor{}
+	node.Accept(v)
+	if v.column == nil {
+		return ""
+	}
+	return v.column.Name.O
+}
+
+func isIdentByte(b byte) bool {
+	return b == '_' || b == '$' ||
+		(b >= '0' && b <= '9') || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z')
+}
+
+// firstColumnVisitor implements ast.Visitor interface, it finds the first
+// column referenced by node.
+type firstColumnVisitor struct {
+	column *ast.ColumnName
+}
+
+func (v *firstColumnVisitor) Enter(in ast.Node) (node ast.Node, skipChildren bool) {
+	if v.column != nil {
+		return in, true
+	}
+	if col, ok := in.(*ast.ColumnName); ok {
+		v.column = col
+		return in, true
+	}
+	return in, false
+}
+
+func (v *firstColumnVisitor) Leave(in ast.Node) (node ast.Node, ok bool) {
+	return in, true
+}
@@ -33,6 +33,7 @@ const (
 	RuleTypeDMLConvention      = "DML规范"
 	RuleTypeUsageSuggestion    = "使用建议"
 	RuleTypeIndexOptimization  = "索引优化"
+	RuleTypeTiDBConvention     = "TiDB规范"
 )
 
 // inspector DDL rules
@@ -109,6 +110,13 @@ const (
 	DMLCheckTableSize                    = "dml_check_table_size"
 )
 
+// inspector TiDB rules, they only work on the instance in TiDB mode.
+const (
+	TiDBCheckAutoIncrementPK = "tidb_check_auto_increment_pk"
+	TiDBCheckTransactionSize = "tidb_check_transaction_size"
+	TiDBDisableFK            = "tidb_disable_fk"
+)
+
 // inspector config code
 const (
 	ConfigDMLRollbackMaxRows       = "dml_rollback_max_rows"
@@ -124,6 +132,15 @@ type RuleHandler struct {
 	Func                 func(*session.Context, driver.Rule, *driver.AuditResult, ast.Node) error
 	AllowOffline         bool
 	NotAllowOfflineStmts []ast.Node
+	// OnlyTiDB represent the rule only works in TiDB mode.
+	OnlyTiDB bool
+	// NotAllowTiDB represent the rule checks InnoDB only feature, such as
+	// engine and character set, or conflicts with the TiDB rules, it is
+	// skipped in TiDB mode.
+	NotAllowTiDB bool
+	// Capability is the capability of instance which the rule needs, the
+	// rule is skipped if the instance does not have it.
+	Capability driver.Capability
 }
 
 // In order to reuse some code, some rules use the same rule handler.
@@ -132,13 +149,23 @@ type RuleHandler struct {
 // It's not a good idea to use the same rule handler for different rules.
 // FIXME: once we map one rule to one rule handler, we should remove the side effect.
 func addResult(result *driver.AuditResult, currentRule driver.Rule, ruleName string, args ...interface{}) {
+	addResultAt(result, currentRule, ruleName, nil, nil, args...)
+}
+
+// addResultAt is like addResult, but the finding is located at node which is
+// the offending part of stmt.
+func addResultAt(result *driver.AuditResult, currentRule driver.Rule, ruleName string, stmt, node ast.Node, args ...interface{}) {
 	// if rule is not current rule, ignore save the message.
 	if ruleName != currentRule.Name {
 		return
 	}
-	level := currentRule.Level
-	message := RuleHandlerMap[ruleName].Message
-	result.Add(level, message, args...)
+	result.AddResult(&driver.AuditResultItem{
+		Level:    currentRule.Level,
+		Message:  fmt.Sprintf(RuleHandlerMap[ruleName].Message, args...),
+		RuleName: currentRule.Name,
+		Category: currentRule.Category,
+		Position: nodePosition(stmt, node),
+	})
 }
 
 func (rh *RuleHandler) IsAllowOfflineRule(node ast.Node) bool {
@@ -153,6 +180,17 @@ func (rh *RuleHandler) IsAllowOfflineRule(node ast.Node) bool {
 	return true
 }
 
+func (rh *RuleHandler) IsAllowTiDBMode(isTiDBMode bool) bool {
+	if isTiDBMode {
+		return !rh.NotAllowTiDB
+	}
+	return !rh.OnlyTiDB
+}
+
+func (rh *RuleHandler) IsAllowCapabilities(cs driver.Capabilities) bool {
+	return rh.Capability == "" || cs.Has(rh.Capability)
+}
+
 var (
 	RuleHandlerMap = map[string]RuleHandler{}
 )
@@ -372,7 +410,9 @@ var RuleHandlers = []RuleHandler{
 		Message:              "主键建议使用自增",
 		AllowOffline:         true,
 		NotAllowOfflineStmts: []ast.Node{&ast.AlterTableStmt{}},
-		Func:                 checkPrimaryKey,
+		// the auto increment primary key is not suggested in TiDB, see TiDBCheckAutoIncrementPK.
+		NotAllowTiDB: true,
+		Func:         checkPrimaryKey,
 	},
 	{
 		Rule: driver.Rule{
@@ -490,6 +530,7 @@ var RuleHandlers = []RuleHandler{
 		},
 		Message:      "必须使用%v数据库引擎",
 		AllowOffline: false,
+		NotAllowTiDB: true,
 		Func:         checkEngine,
 	},
 	{
@@ -510,6 +551,7 @@ var RuleHandlers = []RuleHandler{
 		},
 		Message:      "必须使用%v数据库字符集",
 		AllowOffline: false,
+		NotAllowTiDB: true,
 		Func:         checkCharacterSet,
 	},
 	{
@@ -853,8 +895,9 @@ var RuleHandlers = []RuleHandler{
 				},
 			},
 		},
-		Message: "建议使用规定的数据库排序规则为%s",
-		Func:    checkCollationDatabase,
+		Message:      "建议使用规定的数据库排序规则为%s",
+		Func:         checkCollationDatabase,
+		NotAllowTiDB: true,
 	},
 	{
 		Rule: driver.Rule{
@@ -1064,6 +1107,7 @@ var RuleHandlers = []RuleHandler{
 		},
 		Message:      "该查询的扫描行数为%v",
 		AllowOffline: false,
+		Capability:   driver.CapabilityExplain,
 		Func:         checkExplain,
 	},
 	{
@@ -1075,6 +1119,7 @@ var RuleHandlers = []RuleHandler{
 		},
 		Message:      "该查询使用了文件排序",
 		AllowOffline: false,
+		Capability:   driver.CapabilityExplain,
 		Func:         checkExplain,
 	},
 	{
@@ -1086,6 +1131,7 @@ var RuleHandlers = []RuleHandler{
 		},
 		Message:      "该查询使用了临时表",
 		AllowOffline: false,
+		Capability:   driver.CapabilityExplain,
 		Func:         checkExplain,
 	},
 	{
@@ -1132,6 +1178,49 @@ var RuleHandlers = []RuleHandler{
 		AllowOffline: true,
 		Func:         checkCreateProcedure,
 	},
+	{
+		Rule: driver.Rule{
+			Name:     TiDBCheckAutoIncrementPK,
+			Desc:     "TiDB不建议使用自增主键",
+			Level:    driver.RuleLevelWarn,
+			Category: RuleTypeTiDBConvention,
+		},
+		Message:      "自增主键 %v 在TiDB中会产生写入热点, 建议使用AUTO_RANDOM或者SHARD_ROW_ID_BITS打散写入",
+		AllowOffline: true,
+		OnlyTiDB:     true,
+		Func:         checkTiDBAutoIncrementPK,
+	},
+	{
+		Rule: driver.Rule{
+			Name:     TiDBCheckTransactionSize,
+			Desc:     "TiDB单个事务大小不建议超过阈值",
+			Level:    driver.RuleLevelError,
+			Category: RuleTypeTiDBConvention,
+			Params: params.Params{
+				&params.Param{
+					Key:   DefaultSingleParamKeyName,
+					Value: "100",
+					Desc:  "事务大小（MB）",
+					Type:  params.ParamTypeInt,
+				},
+			},
+		},
+		Message:  "预计写入的数据量约为 %.2fMB, 超过TiDB事务大小限制 %vMB, 建议分批执行",
+		OnlyTiDB: true,
+		Func:     checkTiDBTransactionSize,
+	},
+	{
+		Rule: driver.Rule{
+			Name:     TiDBDisableFK,
+			Desc:     "TiDB不支持外键",
+			Level:    driver.RuleLevelError,
+			Category: RuleTypeTiDBConvention,
+		},
+		Message:      "TiDB不支持外键, 外键约束不会生效",
+		AllowOffline: true,
+		OnlyTiDB:     true,
+		Func:         checkTiDBForeignKey,
+	},
 }
 
 func init() {
@@ -1147,7 +1236,7 @@ func checkSelectAll(ctx *session.Context, rule driver.Rule, res *driver.AuditRes
 		if stmt.Fields != nil && stmt.Fields.Fields != nil {
 			for _, field := range stmt.Fields.Fields {
 				if field.WildCard != nil {
-					addResult(res, rule, DMLDisableSelectAllColumn)
+					addResultAt(res, rule, DMLDisableSelectAllColumn, stmt, field)
 				}
 			}
 		}
@@ -1162,15 +1251,15 @@ func checkSelectWhere(ctx *session.Context, rule driver.Rule, res *driver.AuditR
 		if stmt.From == nil { //If from is null skip check. EX: select 1;select version
 			return nil
 		}
-		checkWhere(rule, res, stmt.Where)
+		checkWhere(rule, res, stmt, stmt.Where)
 
 	case *ast.UpdateStmt:
-		checkWhere(rule, res, stmt.Where)
+		checkWhere(rule, res, stmt, stmt.Where)
 	case *ast.DeleteStmt:
-		checkWhere(rule, res, stmt.Where)
+		checkWhere(rule, res, stmt, stmt.Where)
 	case *ast.UnionStmt:
 		for _, ss := range stmt.SelectList.Selects {
-			if checkWhere(rule, res, ss.Where) {
+			if checkWhere(rule, res, stmt, ss.Where) {
 				break
 			}
 		}
@@ -1181,7 +1270,7 @@ func checkSelectWhere(ctx *session.Context, rule driver.Rule, res *driver.AuditR
 	return nil
 }
 
-func checkWhere(rule driver.Rule, res *driver.AuditResult, where ast.ExprNode) bool {
+func checkWhere(rule driver.Rule, res *driver.AuditResult, stmt ast.Node, where ast.ExprNode) bool {
 	isAddResult := false
 
 	if where == nil || !util.WhereStmtHasOneColumn(where) {
@@ -1189,31 +1278,33 @@ func checkWhere(rule driver.Rule, res *driver.AuditResult, where ast.ExprNode) b
 		isAddResult = true
 	}
 	if where != nil && util.WhereStmtExistNot(where) {
-		addResult(res, rule, DMLCheckWhereExistNot)
+		addResultAt(res, rule, DMLCheckWhereExistNot, stmt, where)
 		isAddResult = true
 	}
 	if where != nil && util.WhereStmtExistScalarSubQueries(where) {
-		addResult(res, rule, DMLCheckWhereExistScalarSubquery)
+		addResultAt(res, rule, DMLCheckWhereExistScalarSubquery, stmt, where)
 		isAddResult = true
 	}
 	if where != nil && util.CheckWhereFuzzySearch(where) {
-		addResult(res, rule, DMLCheckFuzzySearch)
+		addResultAt(res, rule, DMLCheckFuzzySearch, stmt, where)
 		isAddResult = true
 	}
 	return isAddResult
 }
 func checkWhereExistNull(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, node ast.Node) error {
 	if where := getWhereExpr(node); where != nil {
-		var existNull bool
+		var nullExpr *ast.IsNullExpr
 		util.ScanWhereStmt(func(expr ast.ExprNode) (skip bool) {
-			if _, ok := expr.(*ast.IsNullExpr); ok {
-				existNull = true
+			if e, ok := expr.(*ast.IsNullExpr); ok {
+				if nullExpr == nil {
+					nullExpr = e
+				}
 				return true
 			}
 			return false
 		}, where)
-		if existNull {
-			addResult(res, rule, rule.Name)
+		if nullExpr != nil {
+			addResultAt(res, rule, rule.Name, node, nullExpr)
 		}
 	}
 	return nil
@@ -1998,14 +2089,14 @@ func checkStringType(ctx *session.Context, rule driver.Rule, res *driver.AuditRe
 		// if char length >20 using varchar.
 		for _, col := range stmt.Cols {
 			if col.Tp != nil && col.Tp.Tp == mysql.TypeString && col.Tp.Flen > 20 {
-				addResult(res, rule, DDLCheckColumnCharLength)
+				addResultAt(res, rule, DDLCheckColumnCharLength, stmt, col)
 			}
 		}
 	case *ast.AlterTableStmt:
 		for _, spec := range stmt.Specs {
 			for _, col := range spec.NewColumns {
 				if col.Tp != nil && col.Tp.Tp == mysql.TypeString && col.Tp.Flen > 20 {
-					addResult(res, rule, DDLCheckColumnCharLength)
+					addResultAt(res, rule, DDLCheckColumnCharLength, stmt, col)
 				}
 			}
 		}
@@ -2146,7 +2237,7 @@ func checkColumnWithoutComment(ctx *session.Context, rule driver.Rule, res *driv
 				}
 			}
 			if !columnHasComment {
-				addResult(res, rule, DDLCheckColumnWithoutComment)
+				addResultAt(res, rule, DDLCheckColumnWithoutComment, stmt, col)
 				return nil
 			}
 		}
@@ -2163,7 +2254,7 @@ func checkColumnWithoutComment(ctx *session.Context, rule driver.Rule, res *driv
 					}
 				}
 				if !columnHasComment {
-					addResult(res, rule, DDLCheckColumnWithoutComment)
+					addResultAt(res, rule, DDLCheckColumnWithoutComment, stmt, col)
 					return nil
 				}
 			}
@@ -2292,7 +2383,7 @@ func checkColumnWithoutDefault(ctx *session.Context, rule driver.Rule, res *driv
 				continue
 			}
 			if !columnHasDefault {
-				addResult(res, rule, DDLCheckColumnWithoutDefault)
+				addResultAt(res, rule, DDLCheckColumnWithoutDefault, stmt, col)
 				return nil
 			}
 		}
@@ -2321,7 +2412,7 @@ func checkColumnWithoutDefault(ctx *session.Context, rule driver.Rule, res *driv
 					continue
 				}
 				if !columnHasDefault {
-					addResult(res, rule, DDLCheckColumnWithoutDefault)
+					addResultAt(res, rule, DDLCheckColumnWithoutDefault, stmt, col)
 					return nil
 				}
 			}
@@ -2344,7 +2435,7 @@ func checkColumnTimestampWithoutDefault(ctx *session.Context, rule driver.Rule,
 				}
 			}
 			if !columnHasDefault && (col.Tp.Tp == mysql.TypeTimestamp || col.Tp.Tp == mysql.TypeDatetime) {
-				addResult(res, rule, DDLCheckColumnTimestampWithoutDefault)
+				addResultAt(res, rule, DDLCheckColumnTimestampWithoutDefault, stmt, col)
 				return nil
 			}
 		}
@@ -2361,7 +2452,7 @@ func checkColumnTimestampWithoutDefault(ctx *session.Context, rule driver.Rule,
 					}
 				}
 				if !columnHasDefault && (col.Tp.Tp == mysql.TypeTimestamp || col.Tp.Tp == mysql.TypeDatetime) {
-					addResult(res, rule, DDLCheckColumnTimestampWithoutDefault)
+					addResultAt(res, rule, DDLCheckColumnTimestampWithoutDefault, stmt, col)
 					return nil
 				}
 			}
@@ -2384,7 +2475,7 @@ func checkColumnBlobNotNull(ctx *session.Context, rule driver.Rule, res *driver.
 			case mysql.TypeBlob, mysql.TypeMediumBlob, mysql.TypeTinyBlob, mysql.TypeLongBlob:
 				for _, opt := range col.Options {
 					if opt.Tp == ast.ColumnOptionNotNull {
-						addResult(res, rule, DDLCheckColumnBlobWithNotNull)
+						addResultAt(res, rule, DDLCheckColumnBlobWithNotNull, stmt, col)
 						return nil
 					}
 				}
@@ -2404,7 +2495,7 @@ func checkColumnBlobNotNull(ctx *session.Context, rule driver.Rule, res *driver.
 				case mysql.TypeBlob, mysql.TypeMediumBlob, mysql.TypeTinyBlob, mysql.TypeLongBlob:
 					for _, opt := range col.Options {
 						if opt.Tp == ast.ColumnOptionNotNull {
-							addResult(res, rule, DDLCheckColumnBlobWithNotNull)
+							addResultAt(res, rule, DDLCheckColumnBlobWithNotNull, stmt, col)
 							return nil
 						}
 					}
@@ -2435,7 +2526,7 @@ func checkColumnShouldNotBeType(rule driver.Rule, res *driver.AuditResult, node
 				continue
 			}
 			if bytes.Contains(colTypes, []byte{col.Tp.Tp}) {
-				addResult(res, rule, rule.Name)
+				addResultAt(res, rule, rule.Name, stmt, col)
 				return nil
 			}
 		}
@@ -2455,7 +2546,7 @@ func checkColumnShouldNotBeType(rule driver.Rule, res *driver.AuditResult, node
 				}
 
 				if bytes.Contains(colTypes, []byte{newCol.Tp.Tp}) {
-					addResult(res, rule, rule.Name)
+					addResultAt(res, rule, rule.Name, stmt, newCol)
 					return nil
 				}
 			}
@@ -2479,7 +2570,7 @@ func checkColumnBlobDefaultNull(ctx *session.Context, rule driver.Rule, res *dri
 			case mysql.TypeBlob, mysql.TypeMediumBlob, mysql.TypeTinyBlob, mysql.TypeLongBlob:
 				for _, opt := range col.Options {
 					if opt.Tp == ast.ColumnOptionDefaultValue && opt.Expr.GetType().Tp != mysql.TypeNull {
-						addResult(res, rule, DDLCheckColumnBlobDefaultIsNotNull)
+						addResultAt(res, rule, DDLCheckColumnBlobDefaultIsNotNull, stmt, col)
 						return nil
 					}
 				}
@@ -2499,7 +2590,7 @@ func checkColumnBlobDefaultNull(ctx *session.Context, rule driver.Rule, res *dri
 				case mysql.TypeBlob, mysql.TypeMediumBlob, mysql.TypeTinyBlob, mysql.TypeLongBlob:
 					for _, opt := range col.Options {
 						if opt.Tp == ast.ColumnOptionDefaultValue && opt.Expr.GetType().Tp != mysql.TypeNull {
-							addResult(res, rule, DDLCheckColumnBlobDefaultIsNotNull)
+							addResultAt(res, rule, DDLCheckColumnBlobDefaultIsNotNull, stmt, col)
 							return nil
 						}
 					}
@@ -2592,7 +2683,7 @@ func checkWhereExistFunc(ctx *session.Context, rule driver.Rule, res *driver.Aud
 					tables = append(tables, source)
 				}
 			}
-			checkExistFunc(ctx, rule, res, tables, stmt.Where)
+			checkExistFunc(ctx, rule, res, stmt, tables, stmt.Where)
 		}
 	case *ast.UpdateStmt:
 		if stmt.Where != nil {
@@ -2603,11 +2694,11 @@ func checkWhereExistFunc(ctx *session.Context, rule driver.Rule, res *driver.Aud
 					tables = append(tables, source)
 				}
 			}
-			checkExistFunc(ctx, rule, res, tables, stmt.Where)
+			checkExistFunc(ctx, rule, res, stmt, tables, stmt.Where)
 		}
 	case *ast.DeleteStmt:
 		if stmt.Where != nil {
-			checkExistFunc(ctx, rule, res, util.GetTables(stmt.TableRefs.TableRefs), stmt.Where)
+			checkExistFunc(ctx, rule, res, stmt, util.GetTables(stmt.TableRefs.TableRefs), stmt.Where)
 		}
 	case *ast.UnionStmt:
 		for _, ss := range stmt.SelectList.Selects {
@@ -2621,7 +2712,7 @@ func checkWhereExistFunc(ctx *session.Context, rule driver.Rule, res *driver.Aud
 					tables = append(tables, source)
 				}
 			}
-			if checkExistFunc(ctx, rule, res, tables, ss.Where) {
+			if checkExistFunc(ctx, rule, res, stmt, tables, ss.Where) {
 				break
 			}
 		}
@@ -2631,7 +2722,7 @@ func checkWhereExistFunc(ctx *session.Context, rule driver.Rule, res *driver.Aud
 	return nil
 }
 
-func checkExistFunc(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, tables []*ast.TableName, where ast.ExprNode) bool {
+func checkExistFunc(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, stmt ast.Node, tables []*ast.TableName, where ast.ExprNode) bool {
 	if where == nil {
 		return false
 	}
@@ -2647,7 +2738,7 @@ func checkExistFunc(ctx *session.Context, rule driver.Rule, res *driver.AuditRes
 		colMap[col.Name.String()] = struct{}{}
 	}
 	if util.IsFuncUsedOnColumnInWhereStmt(colMap, where) {
-		addResult(res, rule, DMLCheckWhereExistFunc)
+		addResultAt(res, rule, DMLCheckWhereExistFunc, stmt, where)
 		return true
 	}
 	return false
@@ -2669,7 +2760,7 @@ func checkWhereColumnImplicitConversion(ctx *session.Context, rule driver.Rule,
 					tables = append(tables, source)
 				}
 			}
-			checkWhereColumnImplicitConversionFunc(ctx, rule, res, tables, stmt.Where)
+			checkWhereColumnImplicitConversionFunc(ctx, rule, res, stmt, tables, stmt.Where)
 		}
 	case *ast.UpdateStmt:
 		if stmt.Where != nil {
@@ -2680,11 +2771,11 @@ func checkWhereColumnImplicitConversion(ctx *session.Context, rule driver.Rule,
 					tables = append(tables, source)
 				}
 			}
-			checkWhereColumnImplicitConversionFunc(ctx, rule, res, tables, stmt.Where)
+			checkWhereColumnImplicitConversionFunc(ctx, rule, res, stmt, tables, stmt.Where)
 		}
 	case *ast.DeleteStmt:
 		if stmt.Where != nil {
-			checkWhereColumnImplicitConversionFunc(ctx, rule, res, util.GetTables(stmt.TableRefs.TableRefs), stmt.Where)
+			checkWhereColumnImplicitConversionFunc(ctx, rule, res, stmt, util.GetTables(stmt.TableRefs.TableRefs), stmt.Where)
 		}
 	case *ast.UnionStmt:
 		for _, ss := range stmt.SelectList.Selects {
@@ -2698,7 +2789,7 @@ func checkWhereColumnImplicitConversion(ctx *session.Context, rule driver.Rule,
 					tables = append(tables, source)
 				}
 			}
-			if checkWhereColumnImplicitConversionFunc(ctx, rule, res, tables, ss.Where) {
+			if checkWhereColumnImplicitConversionFunc(ctx, rule, res, stmt, tables, ss.Where) {
 				break
 			}
 		}
@@ -2707,7 +2798,7 @@ func checkWhereColumnImplicitConversion(ctx *session.Context, rule driver.Rule,
 	}
 	return nil
 }
-func checkWhereColumnImplicitConversionFunc(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, tables []*ast.TableName, where ast.ExprNode) bool {
+func checkWhereColumnImplicitConversionFunc(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, stmt ast.Node, tables []*ast.TableName, where ast.ExprNode) bool {
 	if where == nil {
 		return false
 	}
@@ -2736,7 +2827,7 @@ func checkWhereColumnImplicitConversionFunc(ctx *session.Context, rule driver.Ru
 
 	}
 	if util.IsColumnImplicitConversionInWhereStmt(colMap, where) {
-		addResult(res, rule, DMLCheckWhereExistImplicitConversion)
+		addResultAt(res, rule, DMLCheckWhereExistImplicitConversion, stmt, where)
 		return true
 	}
 	return false
@@ -2854,14 +2945,14 @@ func checkDecimalTypeColumn(ctx *session.Context, rule driver.Rule, res *driver.
 	case *ast.CreateTableStmt:
 		for _, col := range stmt.Cols {
 			if col.Tp != nil && (col.Tp.Tp == mysql.TypeFloat || col.Tp.Tp == mysql.TypeDouble) {
-				addResult(res, rule, DDLCheckDecimalTypeColumn)
+				addResultAt(res, rule, DDLCheckDecimalTypeColumn, stmt, col)
 			}
 		}
 	case *ast.AlterTableStmt:
 		for _, spec := range stmt.Specs {
 			for _, col := range spec.NewColumns {
 				if col.Tp != nil && (col.Tp.Tp == mysql.TypeFloat || col.Tp.Tp == mysql.TypeDouble) {
-					addResult(res, rule, DDLCheckDecimalTypeColumn)
+					addResultAt(res, rule, DDLCheckDecimalTypeColumn, stmt, col)
 				}
 			}
 		}
@@ -3173,3 +3264,126 @@ func checkCreateProcedure(ctx *session.Context, rule driver.Rule, res *driver.Au
 	}
 	return nil
 }
+
+func checkTiDBAutoIncrementPK(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, node ast.Node) error {
+	var columns []string
+	switch stmt := node.(type) {
+	case *ast.CreateTableStmt:
+		// SHARD_ROW_ID_BITS scatters the row id, the hotspot is avoided by user.
+		for _, op := range stmt.Options {
+			if op.Tp == ast.TableOptionShardRowID && op.UintValue > 0 {
+				return nil
+			}
+		}
+		pkColumns, _ := util.GetPrimaryKey(stmt)
+		for _, col := range stmt.Cols {
+			if !util.HasOneInOptions(col.Options, ast.ColumnOptionAutoIncrement) {
+				continue
+			}
+			if _, ok := pkColumns[col.Name.Name.L]; ok {
+				columns = append(columns, col.Name.Name.O)
+			}
+		}
+	case *ast.AlterTableStmt:
+		for _, spec := range util.GetAlterTableSpecByTp(stmt.Specs, ast.AlterTableAddColumns) {
+			for _, col := range spec.NewColumns {
+				if util.IsAllInOptions(col.Options, ast.ColumnOptionAutoIncrement, ast.ColumnOptionPrimaryKey) {
+					columns = append(columns, col.Name.Name.O)
+				}
+			}
+		}
+	default:
+		return nil
+	}
+	if len(columns) > 0 {
+		addResult(res, rule, TiDBCheckAutoIncrementPK, strings.Join(columns, ","))
+	}
+	return nil
+}
+
+// checkTiDBTransactionSize estimates the size of data written by the DML,
+// the size is the affected rows in execution plan multiplied by the average
+// row size of table.
+func checkTiDBTransactionSize(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, node ast.Node) error {
+	var tables []*ast.TableName
+	switch stmt := node.(type) {
+	case *ast.UpdateStmt:
+		tables = util.GetTables(stmt.TableRefs.TableRefs)
+	case *ast.DeleteStmt:
+		tables = util.GetTables(stmt.TableRefs.TableRefs)
+	case *ast.InsertStmt:
+		if stmt.Select == nil {
+			return nil
+		}
+		tables = util.GetTables(stmt.Table.TableRefs)
+	default:
+		return nil
+	}
+	// the rows of each table is unknown for multi-table DML.
+	if len(tables) != 1 {
+		return nil
+	}
+
+	epRecords, err := ctx.GetExecutionPlan(node.Text())
+	if err != nil {
+		log.NewEntry().Errorf("get execution plan failed, sqle: %v, error: %v", node.Text(), err)
+		return nil
+	}
+	var rows int64
+	for _, record := range epRecords {
+		if record.Rows > rows {
+			rows = record.Rows
+		}
+	}
+	if rows == 0 {
+		return nil
+	}
+
+	tableSize, err := ctx.GetTableSize(tables[0])
+	if err != nil {
+		return err
+	}
+	tableRows, err := ctx.GetTableRowCount(tables[0])
+	if err != nil {
+		return err
+	}
+	if tableSize == 0 || tableRows == 0 {
+		return nil
+	}
+
+	txnSize := float64(rows) * tableSize / float64(tableRows)
+	max := rule.Params.GetParam(DefaultSingleParamKeyName).Int()
+	if txnSize > float64(max) {
+		addResult(res, rule, TiDBCheckTransactionSize, txnSize, max)
+	}
+	return nil
+}
+
+func checkTiDBForeignKey(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, node ast.Node) error {
+	hasFk := false
+	switch stmt := node.(type) {
+	case *ast.CreateTableStmt:
+		for _, constraint := range stmt.Constraints {
+			if constraint.Tp == ast.ConstraintForeignKey {
+				hasFk = true
+			}
+		}
+		for _, col := range stmt.Cols {
+			if util.HasOneInOptions(col.Options, ast.ColumnOptionReference) {
+				hasFk = true
+			}
+		}
+	case *ast.AlterTableStmt:
+		for _, spec := range stmt.Specs {
+			if spec.Constraint != nil && spec.Constraint.Tp == ast.ConstraintForeignKey {
+				hasFk = true
+			}
+		}
+	default:
+		return nil
+	}
+	if hasFk {
+		addResult(res, rule, TiDBDisableFK)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package util
+
+import "strings"
+
+// StmtLine is a statement split from SQL text, Text has no leading comments
+// and Line is the line of its first keyword.
+type StmtLine struct {
+	Text string
+	Line int
+}
+
+// SplitSqlWithLine splits the SQL text into statements. The text starts at
+// the line startLine of file, the line of each statement is calculated from
+// its position in the text.
+func SplitSqlWithLine(sql string, startLine int) ([]StmtLine, error) {
+	stmts, err := ParseSql(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]StmtLine, 0, len(stmts))
+	offset := 0
+	line := startLine
+	for _, stmt := range stmts {
+		text := trimLeadingComments(stmt.Text())
+		if text == "" {
+			continue
+		}
+		if idx := strings.Index(sql[offset:], text); idx >= 0 {
+			line += strings.Count(sql[offset:offset+idx], "\n")
+			offset += idx
+		}
+		result = append(result, StmtLine{Text: text, Line: line})
+	}
+	return result, nil
+}
+
+// trimLeadingComments removes the comments and spaces before the statement,
+// so that the line of statement is the line of its first keyword.
+func trimLeadingComments(sql string) string {
+	for {
+		sql = strings.TrimSpace(sql)
+		switch {
+		case strings.HasPrefix(sql, "#") || strings.HasPrefix(sql, "--"):
+			idx := strings.IndexByte(sql, '\n')
+			if idx < 0 {
+				return ""
+			}
+			sql = sql[idx+1:]
+		case strings.HasPrefix(sql, "/*") && !strings.HasPrefix(sql, "/*!"):
+			idx := strings.Index(sql, "*/")
+			if idx < 0 {
+				return sql
+			}
+			sql = sql[idx+2:]
+		default:
+			return sql
+		}
+	}
+}
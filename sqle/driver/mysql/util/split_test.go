@@ -0,0 +1,18 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSqlWithLine(t *testing.T) {
+	stmts, err := SplitSqlWithLine("-- init\nCREATE TABLE t1 (id int);\n\n/* seed */\nINSERT INTO t1 VALUES (1);\n# end\n", 3)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []StmtLine{
+		{Text: "CREATE TABLE t1 (id int);", Line: 4},
+		{Text: "INSERT INTO t1 VALUES (1);", Line: 7},
+	}, stmts)
+}
@@ -0,0 +1,191 @@
+package util
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pingcap/parser/ast"
+)
+
+// ParseTiDBSql parses the SQL which may contain TiDB specific syntax. The
+// pingcap parser used by SQLE does not support some syntax of newer TiDB,
+// such as "AUTO_RANDOM" and placement policy. These syntax do not affect the
+// audit rules, so they are replaced by the same length of spaces before
+// parsing, and the text of parsed statements is restored to the origin SQL.
+func ParseTiDBSql(sql string) ([]ast.StmtNode, error) {
+	cleanSql := BlankTiDBSyntax(sql)
+	stmts, err := ParseSql(cleanSql)
+	if err != nil {
+		return nil, err
+	}
+	if cleanSql == sql {
+		return stmts, nil
+	}
+	offset := 0
+	for _, stmt := range stmts {
+		text := stmt.Text()
+		idx := strings.Index(cleanSql[offset:], text)
+		if idx < 0 {
+			continue
+		}
+		start := offset + idx
+		offset = start + len(text)
+		stmt.SetText(sql[start:offset])
+	}
+	return stmts, nil
+}
+
+var tidbPlacementPolicyStmtReg = regexp.MustCompile(`(?is)^\s*(create|alter|drop)\s+placement\s+policy\s`)
+
+// IsTiDBPlacementPolicyStmt returns true if the SQL is "CREATE/ALTER/DROP PLACEMENT POLICY".
+func IsTiDBPlacementPolicyStmt(sql string) bool {
+	return tidbPlacementPolicyStmtReg.MatchString(sql)
+}
+
+// BlankTiDBSyntax replaces the TiDB specific syntax in SQL with spaces, the
+// length of the result is the same as the input. The replaced syntax are:
+//  1. column option "AUTO_RANDOM[(n[, m])]";
+//  2. table option "AUTO_RANDOM_BASE [=] n";
+//  3. table or database option "[DEFAULT] PLACEMENT POLICY [=] name";
+//  4. primary key option "CLUSTERED" and "NONCLUSTERED".
+func BlankTiDBSyntax(sql string) string {
+	tokens := tokenizeTiDB(sql)
+	if len(tokens) == 0 {
+		return sql
+	}
+	buf := []byte(sql)
+	blank := func(start, end int) {
+		for i := start; i < end; i++ {
+			// keep the line break, so that the line number of SQL is not changed.
+			if buf[i] != '\n' {
+				buf[i] = ' '
+			}
+		}
+	}
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.isWord("AUTO_RANDOM"):
+			end := t.end
+			if i+1 < len(tokens) && tokens[i+1].isPunct('(') {
+				j := i + 1
+				for j < len(tokens) && !tokens[j].isPunct(')') {
+					j++
+				}
+				if j < len(tokens) {
+					end = tokens[j].end
+					i = j
+				}
+			}
+			blank(t.start, end)
+		case t.isWord("AUTO_RANDOM_BASE"):
+			j := i + 1
+			if j < len(tokens) && tokens[j].isPunct('=') {
+				j++
+			}
+			if j < len(tokens) {
+				blank(t.start, tokens[j].end)
+				i = j
+			}
+		case t.isWord("PLACEMENT") && i+1 < len(tokens) && tokens[i+1].isWord("POLICY"):
+			// "CREATE/ALTER/DROP PLACEMENT POLICY" is a statement, not a option.
+			if i > 0 && (tokens[i-1].isWord("CREATE") || tokens[i-1].isWord("ALTER") || tokens[i-1].isWord("DROP")) {
+				continue
+			}
+			start := t.start
+			if i > 0 && tokens[i-1].isWord("DEFAULT") {
+				start = tokens[i-1].start
+			}
+			j := i + 2
+			if j < len(tokens) && tokens[j].isPunct('=') {
+				j++
+			}
+			if j < len(tokens) {
+				blank(start, tokens[j].end)
+				i = j
+			}
+		case (t.isWord("CLUSTERED") || t.isWord("NONCLUSTERED")) &&
+			i > 0 && (tokens[i-1].isWord("KEY") || tokens[i-1].isPunct(')')):
+			blank(t.start, t.end)
+		}
+	}
+	return string(buf)
+}
+
+type tidbToken struct {
+	// value is the text of token, the word is folded to upper case.
+	value string
+	word  bool
+	start int
+	end   int
+}
+
+func (t tidbToken) isWord(w string) bool {
+	return t.word && t.value == w
+}
+
+func (t tidbToken) isPunct(c byte) bool {
+	return !t.word && len(t.value) == 1 && t.value[0] == c
+}
+
+// tokenizeTiDB splits SQL into words and other tokens, it skips the comments
+// and quoted strings, which is enough to find the TiDB specific syntax.
+func tokenizeTiDB(sql string) []tidbToken {
+	var tokens []tidbToken
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '#' || (c == '-' && strings.HasPrefix(sql[i:], "-- ")):
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(sql[i:], "/*"):
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '\'' || c == '"' || c == '`':
+			j := i + 1
+			for j < len(sql) {
+				if sql[j] == '\\' && c != '`' {
+					j += 2
+					continue
+				}
+				if sql[j] == c {
+					if j+1 < len(sql) && sql[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			if j >= len(sql) {
+				return tokens
+			}
+			tokens = append(tokens, tidbToken{value: sql[i : j+1], start: i, end: j + 1})
+			i = j + 1
+		case isTiDBWordChar(c):
+			j := i
+			for j < len(sql) && isTiDBWordChar(sql[j]) {
+				j++
+			}
+			tokens = append(tokens, tidbToken{value: strings.ToUpper(sql[i:j]), word: true, start: i, end: j})
+			i = j
+		default:
+			tokens = append(tokens, tidbToken{value: sql[i : i+1], start: i, end: i + 1})
+			i++
+		}
+	}
+	return tokens
+}
+
+func isTiDBWordChar(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
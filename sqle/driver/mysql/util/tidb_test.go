@@ -0,0 +1,65 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pingcap/parser/ast"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlankTiDBSyntax(t *testing.T) {
+	cases := []struct {
+		sql string
+		// fragments are the TiDB syntax which should be replaced by spaces.
+		fragments []string
+	}{
+		{
+			sql:       "create table t(id bigint auto_random(5, 54) primary key)",
+			fragments: []string{"auto_random(5, 54)"},
+		},
+		{
+			sql:       "create table t(id bigint primary key clustered) auto_random_base = 100",
+			fragments: []string{"clustered", "auto_random_base = 100"},
+		},
+		{
+			sql:       "create table t(id int, primary key(id) NONCLUSTERED) placement policy=`p1`",
+			fragments: []string{"NONCLUSTERED", "placement policy=`p1`"},
+		},
+		{
+			sql:       "alter database db1 default placement policy = default",
+			fragments: []string{"default placement policy = default"},
+		},
+		{
+			sql: "create placement policy p1 primary_region=\"us-east-1\"",
+		},
+		{
+			sql: "select 'auto_random' from t /* placement policy p1 */ where clustered = 1",
+		},
+	}
+	for _, c := range cases {
+		expect := c.sql
+		for _, fragment := range c.fragments {
+			expect = strings.Replace(expect, fragment, strings.Repeat(" ", len(fragment)), 1)
+		}
+		assert.Equal(t, expect, BlankTiDBSyntax(c.sql))
+	}
+}
+
+func TestParseTiDBSql(t *testing.T) {
+	sql := "create table t(id bigint auto_random primary key) placement policy=p1;\nselect 1;"
+	stmts, err := ParseTiDBSql(sql)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, stmts, 2) {
+		t.FailNow()
+	}
+	assert.IsType(t, &ast.CreateTableStmt{}, stmts[0])
+	assert.Equal(t, "create table t(id bigint auto_random primary key) placement policy=p1;", stmts[0].Text())
+	assert.Equal(t, "select 1;", stmts[1].Text())
+
+	assert.True(t, IsTiDBPlacementPolicyStmt("CREATE PLACEMENT POLICY p1 FOLLOWERS=4"))
+	assert.True(t, IsTiDBPlacementPolicyStmt("drop placement policy if exists p1"))
+	assert.False(t, IsTiDBPlacementPolicyStmt("alter table t placement policy=p1"))
+}
@@ -3,17 +3,13 @@ package driver
 import (
 	"context"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
 
 	"github.com/actiontech/sqle/sqle/driver/proto"
-	"github.com/actiontech/sqle/sqle/log"
 	"github.com/actiontech/sqle/sqle/pkg/params"
 	goPlugin "github.com/hashicorp/go-plugin"
 	"github.com/pingcap/errors"
-	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 )
 
@@ -27,13 +23,22 @@ func convertRuleFromProtoToDriver(rule *proto.Rule) *Rule {
 			Type:  params.ParamType(p.Type),
 		})
 	}
-	return &Rule{
+	r := &Rule{
 		Name:     rule.Name,
 		Category: rule.Category,
 		Desc:     rule.Desc,
 		Level:    RuleLevel(rule.Level),
 		Params:   ps,
 	}
+	if rule.Custom != "" {
+		// the custom rule is validated when it is saved, so it is ignored
+		// only if the JSON is broken.
+		custom := &CustomRule{}
+		if err := json.Unmarshal([]byte(rule.Custom), custom); err == nil {
+			r.Custom = custom
+		}
+	}
+	return r
 }
 
 func convertRuleFromDriverToProto(rule *Rule) *proto.Rule {
@@ -46,129 +51,67 @@ func convertRuleFromDriverToProto(rule *Rule) *proto.Rule {
 			Type:  string(p.Type),
 		})
 	}
+	var custom []byte
+	if rule.Custom != nil {
+		custom, _ = json.Marshal(rule.Custom)
+	}
 	return &proto.Rule{
 		Name:     rule.Name,
 		Desc:     rule.Desc,
 		Level:    string(rule.Level),
 		Category: rule.Category,
 		Params:   params,
+		Custom:   string(custom),
 	}
 }
 
-// InitPlugins init plugins at plugins directory. It should be called on host process.
-func InitPlugins(pluginDir string) error {
-	if pluginDir == "" {
-		return nil
-	}
-
-	getServerHandle := func(path string, closeCh <-chan struct{}) (proto.DriverClient, error) {
-		client := goPlugin.NewClient(&goPlugin.ClientConfig{
-			HandshakeConfig: handshakeConfig,
-			Plugins: goPlugin.PluginSet{
-				filepath.Base(path): &driverPlugin{},
-			},
-			Cmd:              exec.Command(path),
-			AllowedProtocols: []goPlugin.Protocol{goPlugin.ProtocolGRPC},
-		})
-		go func() {
-			<-closeCh
-			client.Kill()
-		}()
-
-		gRPCClient, err := client.Client()
-		if err != nil {
-			return nil, err
-		}
-		rawI, err := gRPCClient.Dispense(filepath.Base(path))
-		if err != nil {
-			return nil, err
+func convertAuditResultFromProtoToDriver(result *proto.AuditResult) *AuditResultItem {
+	item := &AuditResultItem{
+		Level:      RuleLevel(result.GetLevel()),
+		Message:    result.GetMessage(),
+		RuleName:   result.GetRuleName(),
+		Category:   result.GetCategory(),
+		Suggestion: result.GetSuggestion(),
+	}
+	if result.GetPosition() != nil {
+		item.Position = &Position{
+			Offset: int(result.GetPosition().GetOffset()),
+			Line:   int(result.GetPosition().GetLine()),
 		}
-		// srv can only be proto.DriverClient
-		//nolint:forcetypeassert
-		srv := rawI.(proto.DriverClient)
-		return srv, nil
 	}
+	return item
+}
 
-	var plugins []os.FileInfo
-	if err := filepath.Walk(pluginDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return errors.Wrap(err, "init plugin")
-		}
-
-		if info.IsDir() || info.Mode()&0111 == 0 {
-			return nil
+func convertAuditResultFromDriverToProto(result *AuditResultItem) *proto.AuditResult {
+	ret := &proto.AuditResult{
+		Level:      string(result.Level),
+		Message:    result.Message,
+		RuleName:   result.RuleName,
+		Category:   result.Category,
+		Suggestion: result.Suggestion,
+	}
+	if result.Position != nil {
+		ret.Position = &proto.Position{
+			Offset: int64(result.Position.Offset),
+			Line:   int64(result.Position.Line),
 		}
-		plugins = append(plugins, info)
-		return nil
-	}); err != nil {
-		return err
 	}
+	return ret
+}
 
-	for _, p := range plugins {
-		binaryPath := filepath.Join(pluginDir, p.Name())
-
-		closeCh := make(chan struct{})
-		srv, err := getServerHandle(binaryPath, closeCh)
-		if err != nil {
-			return err
-		}
-		pluginMeta, err := srv.Metas(context.TODO(), &proto.Empty{})
-		if err != nil {
-			return err
-		}
-		close(closeCh)
-
-		// driverRules get from plugin when plugin initialize.
-		var driverRules = make([]*Rule, 0, len(pluginMeta.Rules))
-		for _, rule := range pluginMeta.Rules {
-			driverRules = append(driverRules, convertRuleFromProtoToDriver(rule))
-		}
-
-		handler := func(log *logrus.Entry, config *Config) (Driver, error) {
-			pluginCloseCh := make(chan struct{})
-			srv, err := getServerHandle(binaryPath, pluginCloseCh)
-			if err != nil {
-				return nil, err
-			}
-
-			// protoRules send to plugin for Audit.
-			var protoRules []*proto.Rule
-			for _, rule := range config.Rules {
-				protoRules = append(protoRules, convertRuleFromDriverToProto(rule))
-			}
-
-			initRequest := &proto.InitRequest{
-				Rules: protoRules,
-			}
-			if config.DSN != nil {
-				initRequest.Dsn = &proto.DSN{
-					Host:             config.DSN.Host,
-					Port:             config.DSN.Port,
-					User:             config.DSN.User,
-					Password:         config.DSN.Password,
-					AdditionalParams: proto.ConvertParamToProtoParam(config.DSN.AdditionalParams),
-
-					// database is to open.
-					Database: config.DSN.DatabaseName,
-				}
-			}
-
-			_, err = srv.Init(context.TODO(), initRequest)
-			if err != nil {
-				return nil, err
-			}
-			return &driverPluginClient{srv, pluginCloseCh}, nil
-
-		}
-
-		Register(pluginMeta.Name, handler, driverRules, proto.ConvertProtoParamToParam(pluginMeta.GetAdditionalParams()))
-
-		log.Logger().WithFields(logrus.Fields{
-			"plugin_name": pluginMeta.Name,
-		}).Infoln("plugin inited")
+func convertCapabilitiesFromProtoToDriver(cs []string) Capabilities {
+	if len(cs) == 0 {
+		return LegacyPluginCapabilities
+	}
+	capabilities := make(Capabilities, 0, len(cs))
+	for _, c := range cs {
+		capabilities = append(capabilities, Capability(c))
 	}
+	return capabilities
+}
 
-	return nil
+func convertCapabilitiesFromDriverToProto(cs Capabilities) []string {
+	return cs.Strings()
 }
 
 // ServePlugin start plugin process service. It should be called on plugin process.
@@ -198,6 +141,9 @@ type driverPluginClient struct {
 
 	// driverQuitCh produce a singal for telling caller that it's time to Client.Kill() plugin process.
 	driverQuitCh chan struct{}
+
+	// supportAuditBatch is false for the plugins which are built before AuditBatch is added.
+	supportAuditBatch bool
 }
 
 func (s *driverPluginClient) Close(ctx context.Context) {
@@ -205,6 +151,25 @@ func (s *driverPluginClient) Close(ctx context.Context) {
 	close(s.driverQuitCh)
 }
 
+// resettableDriverPluginClient is used for the plugins which reset the driver on
+// repeated Init, the old plugins replace it and leak the connection.
+type resettableDriverPluginClient struct {
+	*driverPluginClient
+	initRequest *proto.InitRequest
+}
+
+func (s *resettableDriverPluginClient) Reset(ctx context.Context, rules []*Rule) error {
+	protoRules := make([]*proto.Rule, 0, len(rules))
+	for _, rule := range rules {
+		protoRules = append(protoRules, convertRuleFromDriverToProto(rule))
+	}
+	_, err := s.plugin.Init(ctx, &proto.InitRequest{
+		Dsn:   s.initRequest.GetDsn(),
+		Rules: protoRules,
+	})
+	return err
+}
+
 func (s *driverPluginClient) Ping(ctx context.Context) error {
 	_, err := s.plugin.Ping(ctx, &proto.Empty{})
 	return err
@@ -295,10 +260,50 @@ func (s *driverPluginClient) Audit(ctx context.Context, sql string) (*AuditResul
 
 	ret := &AuditResult{}
 	for _, result := range resp.Results {
-		ret.results = append(ret.results, &auditResult{
-			level:   RuleLevel(result.Level),
-			message: result.Message,
-		})
+		ret.results = append(ret.results, convertAuditResultFromProtoToDriver(result))
+	}
+	ret.SetDefaultPosition(NewStmtPosition(sql, sql))
+	return ret, nil
+}
+
+// auditBatchSize limits the SQLs count in one AuditBatch request, avoid the
+// request exceed the max message size of gRPC.
+const auditBatchSize = 500
+
+func (s *driverPluginClient) AuditBatch(ctx context.Context, sqls []string) ([]*AuditResult, error) {
+	ret := make([]*AuditResult, 0, len(sqls))
+	if !s.supportAuditBatch {
+		for _, sql := range sqls {
+			result, err := s.Audit(ctx, sql)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, result)
+		}
+		return ret, nil
+	}
+
+	for start := 0; start < len(sqls); start += auditBatchSize {
+		end := start + auditBatchSize
+		if end > len(sqls) {
+			end = len(sqls)
+		}
+		resp, err := s.plugin.AuditBatch(ctx, &proto.AuditBatchRequest{Sqls: sqls[start:end]})
+		if err != nil {
+			return nil, err
+		}
+		if len(resp.Results) != end-start {
+			return nil, fmt.Errorf("audit batch expect %d results, but got %d", end-start, len(resp.Results))
+		}
+		for i, auditResp := range resp.Results {
+			result := &AuditResult{}
+			for _, r := range auditResp.Results {
+				result.results = append(result.results, convertAuditResultFromProtoToDriver(r))
+			}
+			sql := sqls[start+i]
+			result.SetDefaultPosition(NewStmtPosition(sql, sql))
+			ret = append(ret, result)
+		}
 	}
 	return ret, nil
 }
@@ -340,6 +345,16 @@ func (d *driverGRPCServer) Init(ctx context.Context, req *proto.InitRequest) (*p
 		}
 	}
 
+	// Init is called again when host reuses the plugin process for another task,
+	// the driver is reset with the new rules, or replaced if it can not be reset.
+	if d.impl != nil {
+		if r, ok := d.impl.(Resetter); ok {
+			return &proto.Empty{}, r.Reset(ctx, driverRules)
+		}
+		d.impl.Close(ctx)
+		d.impl = nil
+	}
+
 	cfg, err := NewConfig(dsn, driverRules)
 	if err != nil {
 		return nil, errors.Wrap(err, "init config")
@@ -433,10 +448,24 @@ func (d *driverGRPCServer) Audit(ctx context.Context, req *proto.AuditRequest) (
 
 	resp := &proto.AuditResponse{}
 	for _, result := range auditResults.results {
-		resp.Results = append(resp.Results, &proto.AuditResult{
-			Level:   string(result.level),
-			Message: result.message,
-		})
+		resp.Results = append(resp.Results, convertAuditResultFromDriverToProto(result))
+	}
+	return resp, nil
+}
+
+func (d *driverGRPCServer) AuditBatch(ctx context.Context, req *proto.AuditBatchRequest) (*proto.AuditBatchResponse, error) {
+	auditResults, err := d.impl.AuditBatch(ctx, req.GetSqls())
+	if err != nil {
+		return &proto.AuditBatchResponse{}, err
+	}
+
+	resp := &proto.AuditBatchResponse{}
+	for _, auditResult := range auditResults {
+		auditResp := &proto.AuditResponse{}
+		for _, result := range auditResult.results {
+			auditResp.Results = append(auditResp.Results, convertAuditResultFromDriverToProto(result))
+		}
+		resp.Results = append(resp.Results, auditResp)
 	}
 	return resp, nil
 }
@@ -450,6 +479,15 @@ func (d *driverGRPCServer) GenRollbackSQL(ctx context.Context, req *proto.GenRol
 }
 
 func (d *driverGRPCServer) Metas(ctx context.Context, req *proto.Empty) (*proto.MetasResponse, error) {
+	var version string
+	if v, ok := d.r.(Versioner); ok {
+		version = v.Version()
+	}
+	var capabilities Capabilities
+	if c, ok := d.r.(CapabilityRegisterer); ok {
+		capabilities = c.Capabilities()
+	}
+
 	protoRules := make([]*proto.Rule, len(d.r.Rules()))
 
 	for i, r := range d.r.Rules() {
@@ -460,6 +498,11 @@ func (d *driverGRPCServer) Metas(ctx context.Context, req *proto.Empty) (*proto.
 		Name:             d.r.Name(),
 		Rules:            protoRules,
 		AdditionalParams: proto.ConvertParamToProtoParam(d.r.AdditionalParams()),
+
+		SupportAuditBatch: true,
+		SupportReset:      true,
+		Capabilities:      convertCapabilitiesFromDriverToProto(capabilities),
+		Version:           version,
 	}, nil
 }
 
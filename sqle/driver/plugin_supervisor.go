@@ -0,0 +1,715 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/actiontech/sqle/sqle/driver/proto"
+	"github.com/actiontech/sqle/sqle/log"
+	goPlugin "github.com/hashicorp/go-plugin"
+	"github.com/pingcap/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+)
+
+const (
+	PluginStatusRunning    = "running"
+	PluginStatusRestarting = "restarting"
+)
+
+var (
+	// pluginCheckInterval is the interval of checking plugin binaries and plugin processes.
+	pluginCheckInterval = 10 * time.Second
+	pluginCheckTimeout  = 5 * time.Second
+
+	pluginRestartMinBackoff = time.Second
+	pluginRestartMaxBackoff = 5 * time.Minute
+)
+
+// PluginStatus is the runtime status of plugin which is supervised by host process.
+type PluginStatus struct {
+	Name         string
+	Path         string
+	Version      string
+	PID          int
+	Status       string
+	LastError    string
+	RestartCount int
+	StartedAt    time.Time
+}
+
+// pluginProcess is the supervised process of plugin binary. It is used for
+// health checking, the drivers created by NewDriver use their own processes,
+// see pluginConn.
+type pluginProcess struct {
+	path    string
+	modTime time.Time
+	size    int64
+
+	meta   *proto.MetasResponse
+	client pluginClient
+	srv    proto.DriverClient
+
+	status       string
+	lastError    string
+	restartCount int
+	startedAt    time.Time
+
+	// backoff is the waiting time before next restart, it is doubled after
+	// each failed restart until pluginRestartMaxBackoff.
+	backoff       time.Duration
+	nextRestartAt time.Time
+}
+
+func (p *pluginProcess) pid() int {
+	if p.client == nil {
+		return 0
+	}
+	if rc := p.client.ReattachConfig(); rc != nil {
+		return rc.Pid
+	}
+	return 0
+}
+
+// pluginSupervisor loads plugins from plugin directory, restarts the crashed
+// plugin processes and reloads the plugin binaries which are added or updated.
+type pluginSupervisor struct {
+	sync.Mutex
+	dir string
+
+	// plugins key is the path of plugin binary.
+	plugins map[string]*pluginProcess
+	// skipped are the plugin binaries whose driver names are registered by
+	// the built-in drivers, key is the path of plugin binary. They are loaded
+	// again only if they are updated.
+	skipped map[string]os.FileInfo
+
+	// newClient starts the plugin process, it is used by both the supervised
+	// processes and the processes of drivers.
+	newClient func(path string) (pluginClient, proto.DriverClient, error)
+
+	quit     chan struct{}
+	stopOnce sync.Once
+}
+
+var supervisor *pluginSupervisor
+
+// InitPlugins init plugins at plugins directory, and starts a supervisor to
+// watch them. It should be called on host process.
+func InitPlugins(pluginDir string) error {
+	if pluginDir == "" {
+		return nil
+	}
+
+	sv := &pluginSupervisor{
+		dir:       pluginDir,
+		plugins:   map[string]*pluginProcess{},
+		skipped:   map[string]os.FileInfo{},
+		newClient: newPluginClient,
+		quit:      make(chan struct{}),
+	}
+
+	files, err := sv.scan()
+	if err != nil {
+		return err
+	}
+	for _, path := range sortedPaths(files) {
+		if err := sv.load(path, files[path]); err != nil {
+			sv.stop()
+			return err
+		}
+	}
+
+	supervisor = sv
+	go sv.loop()
+	return nil
+}
+
+// StopPlugins kills all supervised plugin processes.
+func StopPlugins() {
+	if supervisor != nil {
+		supervisor.stop()
+	}
+}
+
+// AllPluginStatuses returns the status of all loaded plugins, order by plugin name.
+func AllPluginStatuses() []*PluginStatus {
+	if supervisor == nil {
+		return []*PluginStatus{}
+	}
+
+	supervisor.Lock()
+	defer supervisor.Unlock()
+
+	statuses := make([]*PluginStatus, 0, len(supervisor.plugins))
+	for _, p := range supervisor.plugins {
+		statuses = append(statuses, &PluginStatus{
+			Name:         p.meta.GetName(),
+			Path:         p.path,
+			Version:      p.meta.GetVersion(),
+			PID:          p.pid(),
+			Status:       p.status,
+			LastError:    p.lastError,
+			RestartCount: p.restartCount,
+			StartedAt:    p.startedAt,
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// pluginClient is the client which manages the plugin process, it is
+// implemented by *goPlugin.Client.
+type pluginClient interface {
+	Kill()
+	Exited() bool
+	ReattachConfig() *goPlugin.ReattachConfig
+}
+
+func newPluginClient(path string) (pluginClient, proto.DriverClient, error) {
+	client := goPlugin.NewClient(&goPlugin.ClientConfig{
+		HandshakeConfig: handshakeConfig,
+		Plugins: goPlugin.PluginSet{
+			filepath.Base(path): &driverPlugin{},
+		},
+		Cmd:              exec.Command(path),
+		AllowedProtocols: []goPlugin.Protocol{goPlugin.ProtocolGRPC},
+	})
+
+	gRPCClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return nil, nil, err
+	}
+	rawI, err := gRPCClient.Dispense(filepath.Base(path))
+	if err != nil {
+		client.Kill()
+		return nil, nil, err
+	}
+	// srv can only be proto.DriverClient
+	//nolint:forcetypeassert
+	srv := rawI.(proto.DriverClient)
+	return client, srv, nil
+}
+
+func (sv *pluginSupervisor) scan() (map[string]os.FileInfo, error) {
+	files := map[string]os.FileInfo{}
+	if err := filepath.Walk(sv.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return errors.Wrap(err, "init plugin")
+		}
+
+		// the subdirectories are not scanned, such as the script rule directory
+		// "<plugin>.rules" of plugin.
+		if info.IsDir() && path != sv.dir {
+			return filepath.SkipDir
+		}
+		if info.IsDir() || info.Mode()&0111 == 0 {
+			return nil
+		}
+		files[path] = info
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+func sortedPaths(files map[string]os.FileInfo) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// load starts the plugin binary and registers it as driver. If the binary
+// has been loaded, the old process is replaced after the new one started.
+// The plugin process is started and the driver is registered without
+// holding the lock of supervisor, so that the slow plugin does not block the
+// others, and the lock of drivers is not taken under it.
+func (sv *pluginSupervisor) load(path string, info os.FileInfo) error {
+	client, srv, err := sv.newClient(path)
+	if err != nil {
+		return errors.Wrapf(err, "start plugin %s", path)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pluginCheckTimeout)
+	defer cancel()
+	meta, err := srv.Metas(ctx, &proto.Empty{})
+	if err != nil {
+		client.Kill()
+		return errors.Wrapf(err, "get metas from plugin %s", path)
+	}
+
+	sv.Lock()
+	if sv.isStopped() {
+		sv.Unlock()
+		client.Kill()
+		return fmt.Errorf("plugin supervisor is stopped")
+	}
+
+	old, reload := sv.plugins[path]
+	if !reload || old.meta.GetName() != meta.GetName() {
+		if isRegistered(meta.GetName()) {
+			client.Kill()
+			// the built-in driver takes precedence over the plugin, such as the
+			// PostgreSQL plugin which is shipped before the built-in driver.
+			if !sv.isPluginDriver(meta.GetName()) {
+				sv.skipped[path] = info
+				sv.Unlock()
+				log.NewEntry().Warnf("skip plugin %s, driver %s is built in", path, meta.GetName())
+				return nil
+			}
+			sv.Unlock()
+			return fmt.Errorf("duplicated driver name %s in plugin %s", meta.GetName(), path)
+		}
+	}
+	delete(sv.skipped, path)
+	unregisterName := ""
+	if reload {
+		old.client.Kill()
+		if old.meta.GetName() != meta.GetName() {
+			unregisterName = old.meta.GetName()
+		}
+	}
+
+	p := &pluginProcess{
+		path:      path,
+		modTime:   info.ModTime(),
+		size:      info.Size(),
+		meta:      meta,
+		client:    client,
+		srv:       srv,
+		status:    PluginStatusRunning,
+		startedAt: time.Now(),
+		backoff:   pluginRestartMinBackoff,
+	}
+	if reload {
+		p.restartCount = old.restartCount
+	}
+	sv.plugins[path] = p
+	sv.Unlock()
+
+	if unregisterName != "" {
+		unregister(unregisterName)
+	}
+	// driverRules get from plugin when plugin initialize.
+	var driverRules = make([]*Rule, 0, len(meta.Rules))
+	for _, rule := range meta.Rules {
+		driverRules = append(driverRules, convertRuleFromProtoToDriver(rule))
+	}
+	capabilities := convertCapabilitiesFromProtoToDriver(meta.GetCapabilities())
+
+	register(meta.GetName(), sv.newDriverHandler(path, meta), driverRules,
+		proto.ConvertProtoParamToParam(meta.GetAdditionalParams()), capabilities)
+
+	log.Logger().WithFields(logrus.Fields{
+		"plugin_name":    meta.GetName(),
+		"plugin_version": meta.GetVersion(),
+		"capabilities":   capabilities,
+		"reload":         reload,
+	}).Infoln("plugin inited")
+	return nil
+}
+
+// isPluginDriver returns whether the driver is registered by the loaded plugins.
+func (sv *pluginSupervisor) isPluginDriver(name string) bool {
+	for _, p := range sv.plugins {
+		if p.meta.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (sv *pluginSupervisor) newDriverHandler(path string, meta *proto.MetasResponse) handler {
+	return func(log *logrus.Entry, config *Config) (Driver, error) {
+		client, srv, err := sv.newClient(path)
+		if err != nil {
+			sv.recordError(path, err)
+			return nil, err
+		}
+		conn := &pluginConn{sv: sv, path: path, client: client, srv: srv}
+		pluginCloseCh := make(chan struct{})
+		go func() {
+			<-pluginCloseCh
+			conn.kill()
+		}()
+
+		// protoRules send to plugin for Audit.
+		var protoRules []*proto.Rule
+		for _, rule := range config.Rules {
+			protoRules = append(protoRules, convertRuleFromDriverToProto(rule))
+		}
+
+		initRequest := &proto.InitRequest{
+			Rules: protoRules,
+		}
+		if config.DSN != nil {
+			initRequest.Dsn = &proto.DSN{
+				Host:             config.DSN.Host,
+				Port:             config.DSN.Port,
+				User:             config.DSN.User,
+				Password:         config.DSN.Password,
+				AdditionalParams: proto.ConvertParamToProtoParam(config.DSN.AdditionalParams),
+
+				// database is to open.
+				Database: config.DSN.DatabaseName,
+			}
+		}
+
+		_, err = conn.Init(context.TODO(), initRequest)
+		if err != nil {
+			close(pluginCloseCh)
+			return nil, err
+		}
+		pluginClient := &driverPluginClient{
+			plugin:            conn,
+			driverQuitCh:      pluginCloseCh,
+			supportAuditBatch: meta.GetSupportAuditBatch(),
+		}
+		if meta.GetSupportReset() {
+			return &resettableDriverPluginClient{driverPluginClient: pluginClient, initRequest: initRequest}, nil
+		}
+		return pluginClient, nil
+	}
+}
+
+// pluginConn is the plugin process of driver, it implements proto.DriverClient.
+// The crashed process is restarted by the client factory of supervisor on the
+// next call, and initialized by the last Init request, so the driver is still
+// available after the plugin crashed. The SQL context of driver is lost.
+type pluginConn struct {
+	sync.Mutex
+	sv   *pluginSupervisor
+	path string
+
+	client      pluginClient
+	srv         proto.DriverClient
+	initRequest *proto.InitRequest
+}
+
+func (c *pluginConn) get(ctx context.Context) (proto.DriverClient, error) {
+	c.Lock()
+	defer c.Unlock()
+	if !c.client.Exited() {
+		return c.srv, nil
+	}
+
+	c.client.Kill()
+	client, srv, err := c.sv.newClient(c.path)
+	if err != nil {
+		c.sv.recordError(c.path, err)
+		return nil, errors.Wrap(err, "restart crashed plugin")
+	}
+	if c.initRequest != nil {
+		if _, err := srv.Init(ctx, c.initRequest); err != nil {
+			client.Kill()
+			return nil, errors.Wrap(err, "init restarted plugin")
+		}
+	}
+	c.client, c.srv = client, srv
+	log.NewEntry().Infof("plugin %s of driver is restarted", c.path)
+	return srv, nil
+}
+
+func (c *pluginConn) kill() {
+	c.Lock()
+	defer c.Unlock()
+	c.client.Kill()
+}
+
+func (c *pluginConn) Metas(ctx context.Context, in *proto.Empty, opts ...grpc.CallOption) (*proto.MetasResponse, error) {
+	srv, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.Metas(ctx, in, opts...)
+}
+
+// Init keeps the request for initializing the restarted plugin.
+func (c *pluginConn) Init(ctx context.Context, in *proto.InitRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
+	srv, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := srv.Init(ctx, in, opts...)
+	if err != nil {
+		return nil, err
+	}
+	c.Lock()
+	c.initRequest = in
+	c.Unlock()
+	return resp, nil
+}
+
+// Close does not restart the crashed plugin, it is killed after Close.
+func (c *pluginConn) Close(ctx context.Context, in *proto.Empty, opts ...grpc.CallOption) (*proto.Empty, error) {
+	c.Lock()
+	exited, srv := c.client.Exited(), c.srv
+	c.Unlock()
+	if exited {
+		return &proto.Empty{}, nil
+	}
+	return srv.Close(ctx, in, opts...)
+}
+
+func (c *pluginConn) Ping(ctx context.Context, in *proto.Empty, opts ...grpc.CallOption) (*proto.Empty, error) {
+	srv, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.Ping(ctx, in, opts...)
+}
+
+func (c *pluginConn) Exec(ctx context.Context, in *proto.ExecRequest, opts ...grpc.CallOption) (*proto.ExecResponse, error) {
+	srv, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.Exec(ctx, in, opts...)
+}
+
+func (c *pluginConn) Tx(ctx context.Context, in *proto.TxRequest, opts ...grpc.CallOption) (*proto.TxResponse, error) {
+	srv, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.Tx(ctx, in, opts...)
+}
+
+func (c *pluginConn) Databases(ctx context.Context, in *proto.Empty, opts ...grpc.CallOption) (*proto.DatabasesResponse, error) {
+	srv, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.Databases(ctx, in, opts...)
+}
+
+func (c *pluginConn) Parse(ctx context.Context, in *proto.ParseRequest, opts ...grpc.CallOption) (*proto.ParseResponse, error) {
+	srv, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.Parse(ctx, in, opts...)
+}
+
+func (c *pluginConn) Audit(ctx context.Context, in *proto.AuditRequest, opts ...grpc.CallOption) (*proto.AuditResponse, error) {
+	srv, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.Audit(ctx, in, opts...)
+}
+
+func (c *pluginConn) GenRollbackSQL(ctx context.Context, in *proto.GenRollbackSQLRequest, opts ...grpc.CallOption) (*proto.GenRollbackSQLResponse, error) {
+	srv, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.GenRollbackSQL(ctx, in, opts...)
+}
+
+func (c *pluginConn) AuditBatch(ctx context.Context, in *proto.AuditBatchRequest, opts ...grpc.CallOption) (*proto.AuditBatchResponse, error) {
+	srv, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return srv.AuditBatch(ctx, in, opts...)
+}
+
+func (sv *pluginSupervisor) recordError(path string, err error) {
+	sv.Lock()
+	defer sv.Unlock()
+	if p, ok := sv.plugins[path]; ok {
+		p.lastError = err.Error()
+	}
+}
+
+func (sv *pluginSupervisor) loop() {
+	ticker := time.NewTicker(pluginCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-sv.quit:
+			return
+		case <-ticker.C:
+			sv.check()
+		}
+	}
+}
+
+// check reloads the added, updated or removed plugin binaries, and restarts
+// the unhealthy plugin processes. The lock of supervisor is only held to read
+// and update the plugins, the plugin processes are started and checked
+// without it, so the lookup of drivers is not blocked by the slow plugin.
+func (sv *pluginSupervisor) check() {
+	l := log.NewEntry().WithField("plugin_dir", sv.dir)
+
+	files, err := sv.scan()
+	if err != nil {
+		l.Errorf("scan plugin directory failed, error: %v", err)
+	} else {
+		for _, path := range sortedPaths(files) {
+			info := files[path]
+			if !sv.isChanged(path, info) {
+				continue
+			}
+			if err := sv.load(path, info); err != nil {
+				l.Errorf("load plugin %s failed, error: %v", path, err)
+				sv.recordError(path, err)
+			}
+		}
+		sv.removeNotExist(l, files)
+	}
+
+	sv.Lock()
+	processes := make([]*pluginProcess, 0, len(sv.plugins))
+	for _, p := range sv.plugins {
+		processes = append(processes, p)
+	}
+	sv.Unlock()
+	for _, p := range processes {
+		sv.checkHealth(l, p)
+	}
+}
+
+// isChanged returns whether the plugin binary is added or updated.
+func (sv *pluginSupervisor) isChanged(path string, info os.FileInfo) bool {
+	sv.Lock()
+	defer sv.Unlock()
+	if skipped, ok := sv.skipped[path]; ok {
+		return !skipped.ModTime().Equal(info.ModTime()) || skipped.Size() != info.Size()
+	}
+	p, ok := sv.plugins[path]
+	return !ok || !p.modTime.Equal(info.ModTime()) || p.size != info.Size()
+}
+
+// removeNotExist unregisters the plugins whose binaries are removed, the
+// drivers are unregistered without holding the lock of supervisor.
+func (sv *pluginSupervisor) removeNotExist(l *logrus.Entry, files map[string]os.FileInfo) {
+	removed := []string{}
+	defer func() {
+		for _, name := range removed {
+			unregister(name)
+			l.Infof("plugin %s is removed", name)
+		}
+	}()
+
+	sv.Lock()
+	defer sv.Unlock()
+	for path := range sv.skipped {
+		if _, ok := files[path]; !ok {
+			delete(sv.skipped, path)
+		}
+	}
+	for path, p := range sv.plugins {
+		if _, ok := files[path]; ok {
+			continue
+		}
+		p.client.Kill()
+		delete(sv.plugins, path)
+		removed = append(removed, p.meta.GetName())
+	}
+}
+
+func (sv *pluginSupervisor) checkHealth(l *logrus.Entry, p *pluginProcess) {
+	sv.Lock()
+	name, status, client, srv, nextRestartAt := p.meta.GetName(), p.status, p.client, p.srv, p.nextRestartAt
+	sv.Unlock()
+
+	now := time.Now()
+	if status == PluginStatusRunning {
+		err := ping(client, srv)
+		if err == nil {
+			return
+		}
+		l.Errorf("plugin %s is unhealthy, error: %v", name, err)
+		sv.Lock()
+		p.status = PluginStatusRestarting
+		p.lastError = err.Error()
+		p.nextRestartAt = now
+		sv.Unlock()
+		nextRestartAt = now
+	}
+	if now.Before(nextRestartAt) {
+		return
+	}
+
+	client.Kill()
+	newClient, newSrv, err := sv.newClient(p.path)
+	if err == nil {
+		if err = ping(newClient, newSrv); err != nil {
+			newClient.Kill()
+		}
+	}
+
+	sv.Lock()
+	defer sv.Unlock()
+	if sv.plugins[p.path] != p || sv.isStopped() {
+		// the plugin is reloaded or removed during restarting.
+		if err == nil {
+			newClient.Kill()
+		}
+		return
+	}
+	if err != nil {
+		l.Errorf("restart plugin %s failed, retry after %v, error: %v", name, p.backoff, err)
+		p.lastError = err.Error()
+		p.nextRestartAt = now.Add(p.backoff)
+		p.backoff *= 2
+		if p.backoff > pluginRestartMaxBackoff {
+			p.backoff = pluginRestartMaxBackoff
+		}
+		return
+	}
+
+	p.client, p.srv = newClient, newSrv
+	p.status = PluginStatusRunning
+	p.restartCount++
+	p.startedAt = now
+	p.backoff = pluginRestartMinBackoff
+	l.Infof("plugin %s is restarted", name)
+}
+
+// ping checks plugin process by Metas, Ping can not be used because plugin
+// driver is not initialized in supervised process.
+func ping(client pluginClient, srv proto.DriverClient) error {
+	if client.Exited() {
+		return fmt.Errorf("plugin process exited")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pluginCheckTimeout)
+	defer cancel()
+	_, err := srv.Metas(ctx, &proto.Empty{})
+	return err
+}
+
+func (sv *pluginSupervisor) isStopped() bool {
+	select {
+	case <-sv.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+func (sv *pluginSupervisor) stop() {
+	sv.stopOnce.Do(func() {
+		close(sv.quit)
+
+		sv.Lock()
+		defer sv.Unlock()
+		for _, p := range sv.plugins {
+			p.client.Kill()
+		}
+	})
+}
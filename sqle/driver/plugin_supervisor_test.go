@@ -0,0 +1,226 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/actiontech/sqle/sqle/driver/proto"
+	"github.com/actiontech/sqle/sqle/log"
+
+	goPlugin "github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+)
+
+type fakePluginClient struct {
+	sync.Mutex
+	exited bool
+}
+
+func (c *fakePluginClient) Kill() {
+	c.Lock()
+	defer c.Unlock()
+	c.exited = true
+}
+
+func (c *fakePluginClient) Exited() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.exited
+}
+
+func (c *fakePluginClient) ReattachConfig() *goPlugin.ReattachConfig { return nil }
+
+type fakeDriverClient struct {
+	proto.DriverClient
+	name  string
+	inits int
+}
+
+func (c *fakeDriverClient) Metas(ctx context.Context, in *proto.Empty, opts ...grpc.CallOption) (*proto.MetasResponse, error) {
+	return &proto.MetasResponse{Name: c.name}, nil
+}
+
+func (c *fakeDriverClient) Init(ctx context.Context, in *proto.InitRequest, opts ...grpc.CallOption) (*proto.Empty, error) {
+	c.inits++
+	return &proto.Empty{}, nil
+}
+
+func (c *fakeDriverClient) Ping(ctx context.Context, in *proto.Empty, opts ...grpc.CallOption) (*proto.Empty, error) {
+	return &proto.Empty{}, nil
+}
+
+func (c *fakeDriverClient) Close(ctx context.Context, in *proto.Empty, opts ...grpc.CallOption) (*proto.Empty, error) {
+	return &proto.Empty{}, nil
+}
+
+// fakePlugins records the processes started by supervisor.
+type fakePlugins struct {
+	name    string
+	clients []*fakePluginClient
+	srvs    []*fakeDriverClient
+}
+
+func (f *fakePlugins) newClient(path string) (pluginClient, proto.DriverClient, error) {
+	client, srv := &fakePluginClient{}, &fakeDriverClient{name: f.name}
+	f.clients = append(f.clients, client)
+	f.srvs = append(f.srvs, srv)
+	return client, srv, nil
+}
+
+func newFakeSupervisor(f *fakePlugins) *pluginSupervisor {
+	return &pluginSupervisor{
+		plugins:   map[string]*pluginProcess{},
+		skipped:   map[string]os.FileInfo{},
+		newClient: f.newClient,
+		quit:      make(chan struct{}),
+	}
+}
+
+func TestPluginDriverRestart(t *testing.T) {
+	f := &fakePlugins{name: "fake"}
+	sv := newFakeSupervisor(f)
+	d, err := sv.newDriverHandler("/plugins/fake", &proto.MetasResponse{Name: "fake"})(log.NewEntry(), &Config{})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Len(t, f.clients, 1)
+	assert.Equal(t, 1, f.srvs[0].inits)
+
+	// the plugin process of driver crashed.
+	f.clients[0].Kill()
+	assert.NoError(t, d.Ping(context.TODO()))
+	if !assert.Len(t, f.clients, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, 1, f.srvs[1].inits)
+
+	d.Close(context.TODO())
+	assert.Eventually(t, f.clients[1].Exited, time.Second, 10*time.Millisecond)
+}
+
+func TestPluginSupervisorLoadBuiltinName(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugins")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "fake")
+	if !assert.NoError(t, ioutil.WriteFile(path, []byte("fake"), 0755)) {
+		t.FailNow()
+	}
+	info, err := os.Stat(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	// the built-in driver has the same name with plugin.
+	Register("fake_builtin", nil, nil, nil, nil)
+	defer unregister("fake_builtin")
+
+	f := &fakePlugins{name: "fake_builtin"}
+	sv := newFakeSupervisor(f)
+	assert.NoError(t, sv.load(path, info))
+	assert.Len(t, sv.plugins, 0)
+	assert.True(t, f.clients[0].Exited())
+	assert.False(t, sv.isChanged(path, info))
+
+	// the plugins have the same name.
+	f.name = "fake_plugin"
+	assert.NoError(t, sv.load(path, info))
+	defer unregister("fake_plugin")
+	assert.Len(t, sv.plugins, 1)
+	assert.Len(t, sv.skipped, 0)
+	assert.Error(t, sv.load(filepath.Join(dir, "fake2"), info))
+	assert.Len(t, sv.plugins, 1)
+}
+
+func TestPluginSupervisorScan(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugins")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]os.FileMode{
+		"plugin":                 0755,
+		"plugin.yaml":            0644,
+		"plugin.rules/rule.yaml": 0755,
+	}
+	for name, mode := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		assert.NoError(t, ioutil.WriteFile(path, []byte("fake"), mode))
+	}
+
+	sv := newFakeSupervisor(&fakePlugins{})
+	sv.dir = dir
+	scanned, err := sv.scan()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "plugin")}, sortedPaths(scanned))
+}
+
+func TestPluginSupervisorNewDriverWhileReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "plugins")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "fake")
+	if !assert.NoError(t, ioutil.WriteFile(path, []byte("fake"), 0755)) {
+		t.FailNow()
+	}
+	info, err := os.Stat(path)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	var mu sync.Mutex
+	f := &fakePlugins{name: "fake_reload"}
+	sv := newFakeSupervisor(f)
+	failPath := filepath.Join(dir, "fail")
+	sv.newClient = func(path string) (pluginClient, proto.DriverClient, error) {
+		if path == failPath {
+			return nil, nil, fmt.Errorf("start plugin failed")
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return f.newClient(path)
+	}
+	register("fake_fail", sv.newDriverHandler(failPath, &proto.MetasResponse{Name: "fake_fail"}), nil, nil, nil)
+	defer unregister("fake_fail")
+	defer unregister("fake_reload")
+
+	// the failed start of driver records error in supervisor, while the
+	// reload of plugin registers driver.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wg := sync.WaitGroup{}
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				_, err := newDriver(log.NewEntry(), "fake_fail", &Config{})
+				assert.Error(t, err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				assert.NoError(t, sv.load(path, info))
+			}
+		}()
+		wg.Wait()
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("new driver and reload plugin are deadlocked")
+	}
+}
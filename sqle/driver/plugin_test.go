@@ -0,0 +1,55 @@
+package driver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/actiontech/sqle/sqle/driver/proto"
+	"github.com/actiontech/sqle/sqle/pkg/params"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRegisterer struct{}
+
+func (r *testRegisterer) Name() string                    { return "test" }
+func (r *testRegisterer) Rules() []*Rule                  { return nil }
+func (r *testRegisterer) AdditionalParams() params.Params { return nil }
+
+type testCapabilityRegisterer struct {
+	testRegisterer
+}
+
+func (r *testCapabilityRegisterer) Capabilities() Capabilities {
+	return Capabilities{CapabilityRollback}
+}
+
+func TestMetasCapabilities(t *testing.T) {
+	resp, err := (&driverGRPCServer{r: &testRegisterer{}}).Metas(context.TODO(), &proto.Empty{})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, LegacyPluginCapabilities, convertCapabilitiesFromProtoToDriver(resp.GetCapabilities()))
+
+	resp, err = (&driverGRPCServer{r: &testCapabilityRegisterer{}}).Metas(context.TODO(), &proto.Empty{})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, Capabilities{CapabilityRollback}, convertCapabilitiesFromProtoToDriver(resp.GetCapabilities()))
+}
+
+func TestAllRules(t *testing.T) {
+	register("all_rules_test", nil, []*Rule{{Name: "rule_1"}}, nil, nil)
+	defer unregister("all_rules_test")
+
+	rules := AllRules()
+	done := make(chan struct{})
+	go func() {
+		// the plugin is reloaded while the rules are read.
+		register("all_rules_test", nil, []*Rule{{Name: "rule_2"}}, nil, nil)
+		close(done)
+	}()
+	assert.Equal(t, "rule_1", rules["all_rules_test"][0].Name)
+	<-done
+	assert.Equal(t, "rule_2", AllRules()["all_rules_test"][0].Name)
+}
@@ -0,0 +1,312 @@
+package driver
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Resetter is implemented by the drivers which can be reused after Close. Reset
+// drops the SQL context kept by the previous audit and audits with the rules
+// from now on, the connection to database is kept.
+type Resetter interface {
+	Reset(ctx context.Context, rules []*Rule) error
+}
+
+// LoggerSetter is an optional interface for Resetter, the driver taken from
+// pool logs with the logger of the new caller, such as the task id.
+type LoggerSetter interface {
+	SetLogger(log *logrus.Entry)
+}
+
+const (
+	// driverPoolWaitTimeout is the max time to wait for an idle driver when
+	// the drivers connected to the instance reach the pool max size.
+	driverPoolWaitTimeout   = 30 * time.Second
+	driverPoolResetTimeout  = 5 * time.Second
+	driverPoolCleanInterval = 30 * time.Second
+)
+
+var (
+	pool   *driverPool
+	poolMu sync.RWMutex
+)
+
+// InitDriverPool enables reusing the drivers connected to the same instance and
+// schema. maxSize limits the drivers count for each instance and schema, the idle
+// drivers are closed after idleTimeout. NewDriver does not reuse driver if pool
+// is not initialized or maxSize <= 0.
+func InitDriverPool(maxSize int, idleTimeout time.Duration) {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if pool != nil {
+		pool.stop()
+		pool = nil
+	}
+	if maxSize <= 0 {
+		return
+	}
+	pool = newDriverPool(maxSize, idleTimeout)
+}
+
+// StopDriverPool closes all idle drivers, the drivers in use are closed when
+// they are released.
+func StopDriverPool() {
+	poolMu.Lock()
+	defer poolMu.Unlock()
+	if pool != nil {
+		pool.stop()
+		pool = nil
+	}
+}
+
+func getDriverPool() *driverPool {
+	poolMu.RLock()
+	defer poolMu.RUnlock()
+	return pool
+}
+
+type idleDriver struct {
+	driver Driver
+	idleAt time.Time
+}
+
+type driverPool struct {
+	sync.Mutex
+	cond *sync.Cond
+
+	maxSize     int
+	idleTimeout time.Duration
+
+	// size is the count of drivers in use and idle for each key.
+	size map[string]int
+	idle map[string][]*idleDriver
+
+	stopped bool
+	quitCh  chan struct{}
+}
+
+func newDriverPool(maxSize int, idleTimeout time.Duration) *driverPool {
+	p := &driverPool{
+		maxSize:     maxSize,
+		idleTimeout: idleTimeout,
+		size:        map[string]int{},
+		idle:        map[string][]*idleDriver{},
+		quitCh:      make(chan struct{}),
+	}
+	p.cond = sync.NewCond(p)
+	go p.loop()
+	return p
+}
+
+// driverPoolKey identifies the instance and schema which driver connects to.
+func driverPoolKey(dbType string, dsn *DSN) string {
+	ps := make([]string, 0, len(dsn.AdditionalParams))
+	for _, p := range dsn.AdditionalParams {
+		ps = append(ps, fmt.Sprintf("%s=%s", p.Key, p.Value))
+	}
+	sort.Strings(ps)
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%s\x00%s", dsn.Host, dsn.Port, dsn.User,
+		dsn.Password, dsn.DatabaseName, strings.Join(ps, "&"))
+	return fmt.Sprintf("%s:%x", dbType, h.Sum(nil))
+}
+
+func (p *driverPool) get(log *logrus.Entry, dbType string, cfg *Config) (Driver, error) {
+	key := driverPoolKey(dbType, cfg.DSN)
+	deadline := time.Now().Add(driverPoolWaitTimeout)
+
+	p.Lock()
+	for {
+		if p.stopped {
+			p.Unlock()
+			return newDriver(log, dbType, cfg)
+		}
+
+		if ds := p.idle[key]; len(ds) > 0 {
+			d := ds[len(ds)-1].driver
+			p.idle[key] = ds[:len(ds)-1]
+			p.Unlock()
+
+			if err := resetDriver(log, d, cfg.Rules); err != nil {
+				log.Warnf("reset idle driver failed, close it: %v", err)
+				d.Close(context.TODO())
+				p.Lock()
+				p.release(key)
+				continue
+			}
+			return &pooledDriver{Driver: d, pool: p, key: key}, nil
+		}
+
+		if p.size[key] < p.maxSize {
+			p.size[key]++
+			p.Unlock()
+
+			d, err := newDriver(log, dbType, cfg)
+			if err != nil {
+				p.Lock()
+				p.release(key)
+				p.Unlock()
+				return nil, err
+			}
+			return &pooledDriver{Driver: d, pool: p, key: key}, nil
+		}
+
+		if !time.Now().Before(deadline) {
+			p.Unlock()
+			return nil, fmt.Errorf("wait for idle driver timeout, the drivers connected to the instance reach the max size %d", p.maxSize)
+		}
+		p.wait(deadline)
+	}
+}
+
+// wait blocks until a driver is put back or deadline is exceeded. It must be
+// called with the pool locked.
+func (p *driverPool) wait(deadline time.Time) {
+	t := time.AfterFunc(time.Until(deadline), func() {
+		p.Lock()
+		p.cond.Broadcast()
+		p.Unlock()
+	})
+	p.cond.Wait()
+	t.Stop()
+}
+
+// release decreases the drivers count and wakes up the waiters. It must be
+// called with the pool locked.
+func (p *driverPool) release(key string) {
+	p.size[key]--
+	if p.size[key] <= 0 {
+		delete(p.size, key)
+	}
+	p.cond.Broadcast()
+}
+
+func (p *driverPool) put(key string, d Driver) {
+	_, canReset := d.(Resetter)
+
+	p.Lock()
+	if !canReset || p.stopped {
+		p.release(key)
+		p.Unlock()
+		d.Close(context.TODO())
+		return
+	}
+	p.idle[key] = append(p.idle[key], &idleDriver{driver: d, idleAt: time.Now()})
+	p.cond.Broadcast()
+	p.Unlock()
+}
+
+func (p *driverPool) loop() {
+	ticker := time.NewTicker(driverPoolCleanInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.quitCh:
+			return
+		case <-ticker.C:
+			p.closeIdle(time.Now().Add(-p.idleTimeout))
+		}
+	}
+}
+
+// closeIdle closes the drivers which are idle before the deadline.
+func (p *driverPool) closeIdle(deadline time.Time) {
+	var expired []Driver
+
+	p.Lock()
+	for key, ds := range p.idle {
+		// the idle drivers are appended in order, the front ones are idle longer.
+		n := 0
+		for n < len(ds) && ds[n].idleAt.Before(deadline) {
+			expired = append(expired, ds[n].driver)
+			p.release(key)
+			n++
+		}
+		if n == len(ds) {
+			delete(p.idle, key)
+		} else {
+			p.idle[key] = ds[n:]
+		}
+	}
+	p.Unlock()
+
+	for _, d := range expired {
+		d.Close(context.TODO())
+	}
+}
+
+func (p *driverPool) stop() {
+	p.Lock()
+	p.stopped = true
+	p.cond.Broadcast()
+	p.Unlock()
+
+	close(p.quitCh)
+	// all drivers are idle before the far future.
+	p.closeIdle(time.Now().Add(time.Hour))
+}
+
+func resetDriver(log *logrus.Entry, d Driver, rules []*Rule) error {
+	if s, ok := d.(LoggerSetter); ok {
+		s.SetLogger(log)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), driverPoolResetTimeout)
+	defer cancel()
+	if err := d.Ping(ctx); err != nil {
+		return err
+	}
+	return d.(Resetter).Reset(ctx, rules)
+}
+
+// pooledDriver puts the driver back to pool on Close instead of closing it.
+type pooledDriver struct {
+	Driver
+	pool *driverPool
+	key  string
+
+	closeOnce sync.Once
+}
+
+func (d *pooledDriver) Close(ctx context.Context) {
+	d.closeOnce.Do(func() {
+		d.pool.put(d.key, d.Driver)
+	})
+}
+
+// Reset implements Resetter, so the caller is able to audit with other rules
+// on the same driver. It returns error if the pooled driver can not be reset.
+func (d *pooledDriver) Reset(ctx context.Context, rules []*Rule) error {
+	r, ok := d.Driver.(Resetter)
+	if !ok {
+		return fmt.Errorf("driver does not support reset")
+	}
+	return r.Reset(ctx, rules)
+}
+
+// DryRunExec implements DryRunner, it returns error if the pooled driver can not
+// dry run.
+func (d *pooledDriver) DryRunExec(ctx context.Context, query string) (driver.Result, error) {
+	r, ok := d.Driver.(DryRunner)
+	if !ok {
+		return nil, fmt.Errorf("driver does not support dry run")
+	}
+	return r.DryRunExec(ctx, query)
+}
+
+// DryRunTx implements DryRunner, it returns error if the pooled driver can not
+// dry run.
+func (d *pooledDriver) DryRunTx(ctx context.Context, queries ...string) ([]driver.Result, error) {
+	r, ok := d.Driver.(DryRunner)
+	if !ok {
+		return nil, fmt.Errorf("driver does not support dry run")
+	}
+	return r.DryRunTx(ctx, queries...)
+}
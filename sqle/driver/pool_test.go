@@ -0,0 +1,75 @@
+package driver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type poolTestDriver struct {
+	Driver
+	closed bool
+	rules  []*Rule
+	log    *logrus.Entry
+}
+
+func (d *poolTestDriver) Close(ctx context.Context)      { d.closed = true }
+func (d *poolTestDriver) Ping(ctx context.Context) error { return nil }
+func (d *poolTestDriver) Reset(ctx context.Context, rules []*Rule) error {
+	d.rules = rules
+	return nil
+}
+func (d *poolTestDriver) SetLogger(log *logrus.Entry) { d.log = log }
+
+func TestDriverPool(t *testing.T) {
+	created := 0
+	register("pool_test", func(log *logrus.Entry, c *Config) (Driver, error) {
+		created++
+		return &poolTestDriver{rules: c.Rules}, nil
+	}, nil, nil, nil)
+	defer unregister("pool_test")
+
+	p := newDriverPool(1, time.Minute)
+	defer p.stop()
+
+	entry := logrus.NewEntry(logrus.New())
+	dsn := &DSN{Host: "127.0.0.1", Port: "3306", DatabaseName: "db1"}
+	rules := []*Rule{{Name: "rule1"}}
+
+	d1, err := p.get(entry, "pool_test", &Config{DSN: dsn})
+	assert.NoError(t, err)
+	d1.Close(context.TODO())
+
+	// the idle driver is reused and reset with the new rules and logger.
+	entry2 := entry.WithField("task_id", 2)
+	d2, err := p.get(entry2, "pool_test", &Config{DSN: dsn, Rules: rules})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created)
+	assert.Equal(t, rules, d2.(*pooledDriver).Driver.(*poolTestDriver).rules)
+	assert.Equal(t, entry2, d2.(*pooledDriver).Driver.(*poolTestDriver).log)
+
+	// the other schema does not share the driver.
+	d3, err := p.get(entry, "pool_test", &Config{DSN: &DSN{Host: "127.0.0.1", Port: "3306", DatabaseName: "db2"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, created)
+	d3.Close(context.TODO())
+
+	// wait until the driver in use is put back when pool is full.
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		d2.Close(context.TODO())
+	}()
+	d4, err := p.get(entry, "pool_test", &Config{DSN: dsn})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, created)
+	d4.Close(context.TODO())
+
+	// close the drivers idle timeout.
+	p.closeIdle(time.Now().Add(time.Second))
+	assert.True(t, d4.(*pooledDriver).Driver.(*poolTestDriver).closed)
+	assert.Empty(t, p.size)
+	assert.Empty(t, p.idle)
+}
@@ -0,0 +1,277 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stmt is a parsed PostgreSQL statement. The statements which are not needed by
+// the audit rules are parsed to UnknownStmt, only the leading keywords are kept.
+type Stmt interface {
+	// Text returns the raw SQL text of statement, without the trailing ";".
+	Text() string
+	// IsDML returns true if statement is query or changes data.
+	IsDML() bool
+}
+
+type stmt struct {
+	text string
+}
+
+func (s *stmt) Text() string {
+	return s.text
+}
+
+func (s *stmt) setText(text string) {
+	s.text = text
+}
+
+type ddlStmt struct{ stmt }
+
+func (*ddlStmt) IsDML() bool { return false }
+
+type dmlStmt struct{ stmt }
+
+func (*dmlStmt) IsDML() bool { return true }
+
+// ObjectName is the name of table, index or other object, Schema is empty if
+// the name is not qualified.
+type ObjectName struct {
+	Schema string
+	Name   string
+}
+
+// String returns the quoted name which can be used in SQL.
+func (n *ObjectName) String() string {
+	if n.Schema == "" {
+		return QuoteIdent(n.Name)
+	}
+	return fmt.Sprintf("%s.%s", QuoteIdent(n.Schema), QuoteIdent(n.Name))
+}
+
+type ConstraintType int
+
+const (
+	ConstraintNotNull ConstraintType = iota
+	ConstraintNull
+	ConstraintDefault
+	ConstraintPrimaryKey
+	ConstraintUnique
+	ConstraintForeignKey
+	ConstraintCheck
+	ConstraintIdentity
+	ConstraintGenerated
+	ConstraintExclude
+)
+
+// Constraint is the column constraint or table constraint.
+type Constraint struct {
+	// Name is empty if the constraint is not named by "CONSTRAINT name".
+	Name string
+	Type ConstraintType
+	// Columns is the key columns of table constraint.
+	Columns []string
+	// Expr is the expression of DEFAULT, CHECK or GENERATED constraint.
+	Expr string
+	// Text is the raw text of constraint.
+	Text string
+	// Def is the raw text of constraint without "CONSTRAINT name".
+	Def string
+}
+
+type ColumnDef struct {
+	Name string
+	// Type is the raw text of data type, such as "varchar(20)".
+	Type        string
+	Constraints []*Constraint
+}
+
+// Constraint returns the first constraint of column with the type.
+func (c *ColumnDef) Constraint(typ ConstraintType) *Constraint {
+	for _, constraint := range c.Constraints {
+		if constraint.Type == typ {
+			return constraint
+		}
+	}
+	return nil
+}
+
+type CreateTableStmt struct {
+	ddlStmt
+	Table       *ObjectName
+	IfNotExists bool
+	Temporary   bool
+	Unlogged    bool
+	Columns     []*ColumnDef
+	Constraints []*Constraint
+	// PartitionOf is the parent table of "CREATE TABLE ... PARTITION OF parent".
+	PartitionOf *ObjectName
+	// AsQuery is true for "CREATE TABLE ... AS query".
+	AsQuery bool
+}
+
+type CreateIndexStmt struct {
+	ddlStmt
+	// Name is empty if the index name is generated by PostgreSQL.
+	Name         string
+	Table        *ObjectName
+	Unique       bool
+	Concurrently bool
+	IfNotExists  bool
+	// Columns is the raw text of index elements, such as "lower(name)".
+	Columns []string
+}
+
+type AlterTableCmdType int
+
+const (
+	AlterTableOther AlterTableCmdType = iota
+	AlterTableAddColumn
+	AlterTableDropColumn
+	AlterTableAlterColumnType
+	AlterTableSetDefault
+	AlterTableDropDefault
+	AlterTableSetNotNull
+	AlterTableDropNotNull
+	AlterTableAddConstraint
+	AlterTableDropConstraint
+	AlterTableRenameColumn
+	AlterTableRenameTable
+	AlterTableRenameConstraint
+	AlterTableSetTablespace
+	AlterTableSetLogged
+	AlterTableSetUnlogged
+)
+
+type AlterTableCmd struct {
+	Type AlterTableCmdType
+	// Column is the new column of AlterTableAddColumn.
+	Column *ColumnDef
+	// ColumnName is the column altered or dropped, and the old name of renamed column.
+	ColumnName string
+	// NewName is the new name of renamed column, table or constraint.
+	NewName string
+	// DataType is the new type of AlterTableAlterColumnType.
+	DataType string
+	// Using is the expression of "ALTER COLUMN ... TYPE ... USING expr".
+	Using string
+	// Expr is the new default value of AlterTableSetDefault.
+	Expr string
+	// Constraint is the new constraint of AlterTableAddConstraint.
+	Constraint *Constraint
+	// ConstraintName is the dropped or renamed constraint.
+	ConstraintName string
+	IfExists       bool
+	Cascade        bool
+	// Text is the raw text of command.
+	Text string
+}
+
+type AlterTableStmt struct {
+	ddlStmt
+	Table    *ObjectName
+	IfExists bool
+	Only     bool
+	Cmds     []*AlterTableCmd
+}
+
+type DropStmt struct {
+	ddlStmt
+	// ObjectType is the upper case type of objects, such as "TABLE" or "INDEX".
+	ObjectType   string
+	Objects      []*ObjectName
+	IfExists     bool
+	Concurrently bool
+	Cascade      bool
+}
+
+type TruncateStmt struct {
+	ddlStmt
+	Tables []*ObjectName
+}
+
+type SelectStmt struct {
+	dmlStmt
+	// SelectStar is true if "*" or "t.*" is in the target list of any SELECT
+	// in statement, including sub query.
+	SelectStar bool
+	From       []*ObjectName
+	HasWhere   bool
+}
+
+type InsertStmt struct {
+	dmlStmt
+	Table      *ObjectName
+	Columns    []string
+	SelectStar bool
+}
+
+type UpdateStmt struct {
+	dmlStmt
+	Table    *ObjectName
+	HasWhere bool
+	// Where is the raw text of WHERE condition.
+	Where      string
+	SelectStar bool
+}
+
+type DeleteStmt struct {
+	dmlStmt
+	Table      *ObjectName
+	HasWhere   bool
+	Where      string
+	SelectStar bool
+}
+
+// UnknownStmt is the statement which is not parsed to structure.
+type UnknownStmt struct {
+	stmt
+	// Keywords is the leading keywords in upper case, such as "CREATE VIEW".
+	Keywords string
+}
+
+func (s *UnknownStmt) IsDML() bool {
+	switch strings.SplitN(s.Keywords, " ", 2)[0] {
+	case "SELECT", "WITH", "INSERT", "UPDATE", "DELETE", "VALUES", "TABLE", "MERGE", "COPY":
+		return true
+	}
+	return false
+}
+
+// QuoteIdent quotes the identifier if it is not a lower case name.
+func QuoteIdent(name string) string {
+	if name == "" {
+		return `""`
+	}
+	simple := !isReservedKeyword(name)
+	for i, c := range name {
+		if !(c == '_' || (c >= 'a' && c <= 'z') || (i > 0 && ((c >= '0' && c <= '9') || c == '$'))) {
+			simple = false
+			break
+		}
+	}
+	if simple {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+var reservedKeywords = map[string]struct{}{}
+
+func init() {
+	for _, kw := range strings.Fields(`all analyse analyze and any array as asc asymmetric both case cast
+		check collate column constraint create current_catalog current_date current_role current_time
+		current_timestamp current_user default deferrable desc distinct do else end except false fetch
+		for foreign from grant group having in initially intersect into lateral leading limit localtime
+		localtimestamp not null offset on only or order placing primary references returning select
+		session_user some symmetric table then to trailing true union unique user using variadic when
+		where window with authorization binary collation concurrently cross current_schema freeze full
+		ilike inner is isnull join left like natural notnull outer overlaps right similar tablesample verbose`) {
+		reservedKeywords[kw] = struct{}{}
+	}
+}
+
+func isReservedKeyword(s string) bool {
+	_, ok := reservedKeywords[s]
+	return ok
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"strings"
+)
+
+// Fingerprint returns the normalized SQL, the constants are replaced by "?",
+// the constant lists such as "IN (1, 2)" and "VALUES (1, 'a'), (2, 'b')" are
+// collapsed to "(?+)", the comments are removed and the keywords are in lower case.
+func Fingerprint(sql string) string {
+	tokens, err := Tokenize(sql)
+	if err != nil {
+		return strings.ToLower(strings.Join(strings.Fields(sql), " "))
+	}
+
+	words := make([]string, 0, len(tokens))
+	for _, t := range tokens {
+		switch t.Type {
+		case TokenString, TokenNumber, TokenParam:
+			words = append(words, "?")
+		case TokenQuotedIdent:
+			words = append(words, QuoteIdent(t.Value))
+		case TokenPunct:
+			if t.Value == ";" {
+				continue
+			}
+			words = append(words, t.Value)
+		default:
+			words = append(words, t.Value)
+		}
+	}
+	words = collapseConstantLists(words)
+
+	var b strings.Builder
+	for i, w := range words {
+		if i > 0 && !noSpaceBefore(w) && !noSpaceAfter(words[i-1]) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
+
+// collapseConstantLists replaces "(?, ?)" by "(?+)", and the repeated "(?+)"
+// separated by "," are collapsed into one.
+func collapseConstantLists(words []string) []string {
+	var out []string
+	for i := 0; i < len(words); i++ {
+		if words[i] == "(" {
+			j := i + 1
+			for j+1 < len(words) && words[j] == "?" && words[j+1] == "," {
+				j += 2
+			}
+			if j+1 < len(words) && words[j] == "?" && words[j+1] == ")" {
+				if n := len(out); n >= 2 && out[n-1] == "," && out[n-2] == "(?+)" {
+					out = out[:n-1]
+				} else {
+					out = append(out, "(?+)")
+				}
+				i = j + 1
+				continue
+			}
+		}
+		out = append(out, words[i])
+	}
+	return out
+}
+
+func noSpaceBefore(w string) bool {
+	return w == "," || w == ")" || w == "." || w == "]" || w == "::" || w == "["
+}
+
+func noSpaceAfter(w string) bool {
+	return w == "(" || w == "." || w == "[" || w == "::"
+}
@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/pkg/tokenizer"
+)
+
+type TokenType = tokenizer.TokenType
+
+// Token is the token of PostgreSQL, the unquoted identifier is folded to lower
+// case.
+type Token = tokenizer.Token
+
+const (
+	TokenEOF         = tokenizer.TokenEOF
+	TokenIdent       = tokenizer.TokenIdent
+	TokenQuotedIdent = tokenizer.TokenQuotedIdent
+	// TokenString is string constant, including E'', B'', X'', U&'' and dollar-quoted string.
+	TokenString = tokenizer.TokenString
+	TokenNumber = tokenizer.TokenNumber
+	// TokenParam is positional parameter, such as $1.
+	TokenParam    = tokenizer.TokenVariable
+	TokenOperator = tokenizer.TokenOperator
+	TokenPunct    = tokenizer.TokenPunct
+)
+
+var dialect = &tokenizer.Dialect{
+	FoldIdent:        strings.ToLower,
+	IdentStart:       "_",
+	IdentPart:        "_$",
+	NestedComment:    true,
+	Puncts:           "()[],;.:",
+	NumberUnderscore: true,
+	Scan:             scan,
+}
+
+func Tokenize(sql string) ([]Token, error) {
+	return tokenizer.Tokenize(sql, dialect)
+}
+
+func scan(s *tokenizer.Scanner) (Token, bool, error) {
+	start := s.Pos()
+	c := s.Peek(0)
+	switch {
+	case (c == 'e' || c == 'E') && s.Peek(1) == '\'':
+		s.Skip(1)
+		t, err := s.ScanQuoted(start, TokenString, '\'', true)
+		return t, true, err
+	case strings.IndexByte("bBxXnN", c) >= 0 && s.Peek(1) == '\'':
+		s.Skip(1)
+		t, err := s.ScanQuoted(start, TokenString, '\'', false)
+		return t, true, err
+	case (c == 'u' || c == 'U') && s.Peek(1) == '&' && (s.Peek(2) == '\'' || s.Peek(2) == '"'):
+		s.Skip(2)
+		typ := TokenString
+		if s.Peek(0) == '"' {
+			typ = TokenQuotedIdent
+		}
+		t, err := s.ScanQuoted(start, typ, s.Peek(0), false)
+		return t, true, err
+	case c == '$':
+		t, err := scanDollar(s)
+		return t, true, err
+	case c == ':' && s.Peek(1) == ':':
+		s.Skip(2)
+		return s.Token(TokenOperator, "::", start), true, nil
+	case isOperatorChar(c):
+		return scanOperator(s), true, nil
+	}
+	return Token{}, false, nil
+}
+
+// scanDollar scans the positional parameter, dollar-quoted string such as
+// $body$...$body$, or the operator "$".
+func scanDollar(s *tokenizer.Scanner) (Token, error) {
+	start := s.Pos()
+	sql := s.SQL()
+	if isDigit(s.Peek(1)) {
+		s.Skip(1)
+		for isDigit(s.Peek(0)) {
+			s.Skip(1)
+		}
+		return s.Token(TokenParam, sql[start:s.Pos()], start), nil
+	}
+	i := start + 1
+	for i < len(sql) && sql[i] != '$' && s.IsIdentPart(sql[i:]) && !(i == start+1 && isDigit(sql[i])) {
+		i++
+	}
+	if i >= len(sql) || sql[i] != '$' {
+		s.Skip(1)
+		return s.Token(TokenOperator, "$", start), nil
+	}
+	tag := sql[start : i+1]
+	s.Skip(len(tag))
+	end := strings.Index(sql[s.Pos():], tag)
+	if end < 0 {
+		return Token{}, fmt.Errorf("unterminated dollar-quoted string at position %d", start)
+	}
+	value := sql[s.Pos() : s.Pos()+end]
+	s.Skip(end + len(tag))
+	return s.Token(TokenString, value, start), nil
+}
+
+// scanOperator scans the operator, which is a sequence of operator characters
+// in PostgreSQL.
+func scanOperator(s *tokenizer.Scanner) Token {
+	start := s.Pos()
+	sql := s.SQL()
+	end := start
+	for end < len(sql) && isOperatorChar(sql[end]) {
+		// the comment starts inside operator, such as "*/*comment*/".
+		if end > start && (strings.HasPrefix(sql[end:], "--") || strings.HasPrefix(sql[end:], "/*")) {
+			break
+		}
+		end++
+	}
+	// the multi-character operator can not end in "+" or "-" unless it
+	// contains one of "~!@#%^&|`?", so "a=-1" is "a", "=", "-", "1".
+	for end-start > 1 && (sql[end-1] == '+' || sql[end-1] == '-') &&
+		!strings.ContainsAny(sql[start:end], "~!@#%^&|`?") {
+		end--
+	}
+	s.Skip(end - start)
+	return s.Token(TokenOperator, sql[start:end], start)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isOperatorChar(c byte) bool {
+	return strings.IndexByte("+-*/<>=~!@#%^&|`?", c) >= 0
+}
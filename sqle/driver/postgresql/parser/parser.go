@@ -0,0 +1,1028 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parse splits sql into statements by ";" and parses each of them.
+//
+// The statements used by audit rules and rollback, such as CREATE TABLE, CREATE
+// INDEX, ALTER TABLE, DROP, SELECT, INSERT, UPDATE and DELETE, are parsed to
+// structure. The expressions are kept as raw text. The other statements are
+// parsed to UnknownStmt.
+func Parse(sql string) ([]Stmt, error) {
+	tokens, err := Tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	var stmts []Stmt
+	for _, stmtTokens := range splitTokens(tokens, ";") {
+		if len(stmtTokens) == 0 {
+			continue
+		}
+		p := &parser{sql: sql, tokens: stmtTokens}
+		s, err := p.parseStmt()
+		if err != nil {
+			return nil, fmt.Errorf("parse SQL \"%s\" failed: %v", p.text(stmtTokens), err)
+		}
+		stmts = append(stmts, s)
+	}
+	return stmts, nil
+}
+
+// splitTokens splits tokens by the punctuation which is not in parentheses.
+func splitTokens(tokens []Token, sep string) [][]Token {
+	var parts [][]Token
+	depth, start := 0, 0
+	for i, t := range tokens {
+		switch {
+		case t.IsPunct("(") || t.IsPunct("["):
+			depth++
+		case t.IsPunct(")") || t.IsPunct("]"):
+			depth--
+		case t.IsPunct(sep) && depth == 0:
+			parts = append(parts, tokens[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, tokens[start:])
+}
+
+type parser struct {
+	sql    string
+	tokens []Token
+	pos    int
+}
+
+func (p *parser) text(tokens []Token) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return p.sql[tokens[0].Pos:tokens[len(tokens)-1].End]
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() Token {
+	return p.peekN(0)
+}
+
+func (p *parser) peekN(n int) Token {
+	if p.pos+n < len(p.tokens) {
+		return p.tokens[p.pos+n]
+	}
+	return Token{Type: TokenEOF}
+}
+
+func (p *parser) next() Token {
+	t := p.peek()
+	if !p.eof() {
+		p.pos++
+	}
+	return t
+}
+
+// acceptKeywords consumes the keywords if all of them are matched in order.
+func (p *parser) acceptKeywords(kws ...string) bool {
+	for i, kw := range kws {
+		if !p.peekN(i).IsKeyword(kw) {
+			return false
+		}
+	}
+	p.pos += len(kws)
+	return true
+}
+
+func (p *parser) expectKeywords(kws ...string) error {
+	if !p.acceptKeywords(kws...) {
+		return p.unexpected(strings.ToUpper(strings.Join(kws, " ")))
+	}
+	return nil
+}
+
+func (p *parser) acceptPunct(punct string) bool {
+	if p.peek().IsPunct(punct) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expectPunct(punct string) error {
+	if !p.acceptPunct(punct) {
+		return p.unexpected(fmt.Sprintf("\"%s\"", punct))
+	}
+	return nil
+}
+
+func (p *parser) unexpected(expected string) error {
+	if p.eof() {
+		return fmt.Errorf("expect %s, but got end of statement", expected)
+	}
+	t := p.peek()
+	return fmt.Errorf("expect %s, but got \"%s\"", expected, p.sql[t.Pos:t.End])
+}
+
+func (p *parser) parseName() (string, error) {
+	t := p.peek()
+	if !t.IsName() {
+		return "", p.unexpected("name")
+	}
+	p.pos++
+	return t.Value, nil
+}
+
+// parseObjectName parses "name", "schema.name" or "database.schema.name".
+func (p *parser) parseObjectName() (*ObjectName, error) {
+	names := []string{}
+	for {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.acceptPunct(".") {
+			break
+		}
+	}
+	n := &ObjectName{Name: names[len(names)-1]}
+	if len(names) > 1 {
+		n.Schema = names[len(names)-2]
+	}
+	return n, nil
+}
+
+// parseNameList parses "(name, name ...)".
+func (p *parser) parseNameList() ([]string, error) {
+	if err := p.expectPunct("("); err != nil {
+		return nil, err
+	}
+	var names []string
+	for {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if p.acceptPunct(")") {
+			return names, nil
+		}
+		if err := p.expectPunct(","); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// skipParens consumes the tokens in parentheses if the next token is "(".
+func (p *parser) skipParens() []Token {
+	if !p.peek().IsPunct("(") {
+		return nil
+	}
+	start := p.pos
+	depth := 0
+	for !p.eof() {
+		t := p.next()
+		if t.IsPunct("(") {
+			depth++
+		} else if t.IsPunct(")") {
+			depth--
+			if depth == 0 {
+				break
+			}
+		}
+	}
+	return p.tokens[start:p.pos]
+}
+
+// consumeUntil consumes the tokens until stop returns true for the token which
+// is not in parentheses, the consumed tokens are returned.
+func (p *parser) consumeUntil(stop func(t Token) bool) []Token {
+	start := p.pos
+	depth := 0
+	for !p.eof() {
+		t := p.peek()
+		if depth == 0 && stop(t) {
+			break
+		}
+		if t.IsPunct("(") || t.IsPunct("[") {
+			depth++
+		} else if t.IsPunct(")") || t.IsPunct("]") {
+			depth--
+		}
+		p.pos++
+	}
+	return p.tokens[start:p.pos]
+}
+
+func (p *parser) rest() []Token {
+	tokens := p.tokens[p.pos:]
+	p.pos = len(p.tokens)
+	return tokens
+}
+
+func keywordIn(kws ...string) func(t Token) bool {
+	return func(t Token) bool {
+		for _, kw := range kws {
+			if t.IsKeyword(kw) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func (p *parser) parseStmt() (Stmt, error) {
+	var (
+		s   Stmt
+		err error
+	)
+	switch first := p.peek(); {
+	case first.IsKeyword("create"):
+		s, err = p.parseCreate()
+	case first.IsKeyword("alter") && p.peekN(1).IsKeyword("table"):
+		s, err = p.parseAlterTable()
+	case first.IsKeyword("drop"):
+		s, err = p.parseDrop()
+	case first.IsKeyword("truncate"):
+		s, err = p.parseTruncate()
+	case first.IsKeyword("select") || first.IsKeyword("values") || first.IsPunct("("):
+		s, err = p.parseSelect()
+	case first.IsKeyword("with"):
+		s, err = p.parseWith()
+	case first.IsKeyword("insert"):
+		s, err = p.parseInsert()
+	case first.IsKeyword("update"):
+		s, err = p.parseUpdate()
+	case first.IsKeyword("delete"):
+		s, err = p.parseDelete()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		s = p.parseUnknown()
+	}
+	s.(interface{ setText(string) }).setText(p.text(p.tokens))
+	return s, nil
+}
+
+func (p *parser) parseUnknown() *UnknownStmt {
+	var keywords []string
+	for _, t := range p.tokens {
+		if t.Type != TokenIdent || len(keywords) == 2 {
+			break
+		}
+		keywords = append(keywords, strings.ToUpper(t.Value))
+	}
+	return &UnknownStmt{Keywords: strings.Join(keywords, " ")}
+}
+
+func (p *parser) parseCreate() (Stmt, error) {
+	p.next() // CREATE
+	p.acceptKeywords("or", "replace")
+
+	start := p.pos
+	temporary, unlogged := false, false
+	p.acceptKeywords("global")
+	p.acceptKeywords("local")
+	switch {
+	case p.acceptKeywords("temporary"), p.acceptKeywords("temp"):
+		temporary = true
+	case p.acceptKeywords("unlogged"):
+		unlogged = true
+	}
+
+	switch {
+	case p.peek().IsKeyword("table"):
+		s, err := p.parseCreateTable()
+		if s != nil {
+			s.Temporary, s.Unlogged = temporary, unlogged
+		}
+		return s, err
+	case p.peek().IsKeyword("unique") || p.peek().IsKeyword("index"):
+		return p.parseCreateIndex()
+	}
+	p.pos = start
+	return nil, nil
+}
+
+// tableConstraintStart returns true if t starts a table constraint.
+func tableConstraintStart(t Token) bool {
+	return keywordIn("constraint", "primary", "unique", "foreign", "check", "exclude")(t)
+}
+
+func (p *parser) parseCreateTable() (*CreateTableStmt, error) {
+	p.next() // TABLE
+	s := &CreateTableStmt{}
+	s.IfNotExists = p.acceptKeywords("if", "not", "exists")
+	table, err := p.parseObjectName()
+	if err != nil {
+		return nil, err
+	}
+	s.Table = table
+
+	switch {
+	case p.acceptKeywords("partition", "of"):
+		if s.PartitionOf, err = p.parseObjectName(); err != nil {
+			return nil, err
+		}
+	case p.peek().IsKeyword("of"):
+		// typed table, the columns are from the composite type.
+		p.rest()
+		return s, nil
+	case p.peek().IsPunct("("):
+	default:
+		// CREATE TABLE name [(column_name, ...)] [WITH (...)] AS query
+		p.consumeUntil(keywordIn("as"))
+		if p.acceptKeywords("as") {
+			s.AsQuery = true
+		}
+		p.rest()
+		return s, nil
+	}
+
+	if !p.peek().IsPunct("(") {
+		p.rest()
+		return s, nil
+	}
+	elements := p.skipParens()
+	for _, element := range splitTokens(elements[1:len(elements)-1], ",") {
+		if len(element) == 0 {
+			continue
+		}
+		ep := &parser{sql: p.sql, tokens: element}
+		switch {
+		case tableConstraintStart(element[0]):
+			constraint, err := ep.parseTableConstraint()
+			if err != nil {
+				return nil, err
+			}
+			s.Constraints = append(s.Constraints, constraint)
+		case element[0].IsKeyword("like"):
+		default:
+			column, err := ep.parseColumnDef(s.PartitionOf != nil)
+			if err != nil {
+				return nil, err
+			}
+			s.Columns = append(s.Columns, column)
+		}
+	}
+	p.rest()
+	return s, nil
+}
+
+// columnConstraintStart returns true if t starts a column constraint.
+var columnConstraintStart = keywordIn("constraint", "not", "null", "default", "primary", "unique",
+	"references", "check", "generated", "collate", "deferrable", "initially", "compression", "storage")
+
+// parseColumnDef parses column definition, the column of partition table
+// has no data type, such as "c1 WITH OPTIONS NOT NULL".
+func (p *parser) parseColumnDef(withoutType bool) (*ColumnDef, error) {
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	column := &ColumnDef{Name: name}
+	if withoutType {
+		p.acceptKeywords("with", "options")
+	} else {
+		typ := p.consumeUntil(columnConstraintStart)
+		if len(typ) == 0 {
+			return nil, p.unexpected("data type")
+		}
+		column.Type = p.text(typ)
+	}
+
+	for !p.eof() {
+		constraint, err := p.parseColumnConstraint()
+		if err != nil {
+			return nil, err
+		}
+		if constraint != nil {
+			column.Constraints = append(column.Constraints, constraint)
+		}
+	}
+	return column, nil
+}
+
+// parseColumnConstraint parses one column constraint, it returns nil for the
+// constraint attributes, such as DEFERRABLE and COLLATE.
+func (p *parser) parseColumnConstraint() (*Constraint, error) {
+	start := p.pos
+	c := &Constraint{}
+	if p.acceptKeywords("constraint") {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		c.Name = name
+	}
+	defStart := p.pos
+
+	// consumeOptions consumes the tokens until next column constraint.
+	consumeOptions := func() []Token {
+		return p.consumeUntil(columnConstraintStart)
+	}
+
+	switch t := p.next(); {
+	case t.IsKeyword("not") && p.acceptKeywords("null"):
+		c.Type = ConstraintNotNull
+	case t.IsKeyword("not") && p.acceptKeywords("deferrable"):
+		return nil, nil
+	case t.IsKeyword("null"):
+		c.Type = ConstraintNull
+	case t.IsKeyword("default"):
+		c.Type = ConstraintDefault
+		// the default expression has one token at least, such as "DEFAULT NULL".
+		if p.eof() {
+			return nil, p.unexpected("default expression")
+		}
+		exprStart := p.pos
+		p.next()
+		consumeOptions()
+		c.Expr = p.text(p.tokens[exprStart:p.pos])
+	case t.IsKeyword("primary"):
+		if err := p.expectKeywords("key"); err != nil {
+			return nil, err
+		}
+		c.Type = ConstraintPrimaryKey
+		consumeOptions()
+	case t.IsKeyword("unique"):
+		c.Type = ConstraintUnique
+		consumeOptions()
+	case t.IsKeyword("references"):
+		c.Type = ConstraintForeignKey
+		// the "NULL" and "DEFAULT" of "ON DELETE SET NULL" are not constraints.
+		p.consumeUntil(func(t Token) bool {
+			return columnConstraintStart(t) && !p.tokens[p.pos-1].IsKeyword("set")
+		})
+	case t.IsKeyword("check"):
+		c.Type = ConstraintCheck
+		expr := p.skipParens()
+		if len(expr) > 2 {
+			c.Expr = p.text(expr[1 : len(expr)-1])
+		}
+		consumeOptions()
+	case t.IsKeyword("generated"):
+		c.Type = ConstraintGenerated
+		if p.acceptKeywords("by", "default") || p.acceptKeywords("always") {
+			if p.acceptKeywords("as", "identity") {
+				c.Type = ConstraintIdentity
+			}
+		}
+		if c.Type == ConstraintGenerated {
+			p.acceptKeywords("as")
+			expr := p.skipParens()
+			if len(expr) > 2 {
+				c.Expr = p.text(expr[1 : len(expr)-1])
+			}
+		}
+		consumeOptions()
+	case t.IsKeyword("collate") || t.IsKeyword("compression") || t.IsKeyword("storage"):
+		p.next()
+		consumeOptions()
+		return nil, nil
+	case t.IsKeyword("deferrable") || t.IsKeyword("initially"):
+		consumeOptions()
+		return nil, nil
+	default:
+		p.pos--
+		return nil, p.unexpected("column constraint")
+	}
+	c.Text = p.text(p.tokens[start:p.pos])
+	c.Def = p.text(p.tokens[defStart:p.pos])
+	return c, nil
+}
+
+func (p *parser) parseTableConstraint() (*Constraint, error) {
+	start := p.pos
+	c := &Constraint{}
+	if p.acceptKeywords("constraint") {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		c.Name = name
+	}
+	defStart := p.pos
+
+	var err error
+	switch t := p.next(); {
+	case t.IsKeyword("primary"):
+		if err := p.expectKeywords("key"); err != nil {
+			return nil, err
+		}
+		c.Type = ConstraintPrimaryKey
+		// "PRIMARY KEY USING INDEX index_name" of ALTER TABLE has no columns.
+		if !p.acceptKeywords("using", "index") {
+			c.Columns, err = p.parseNameList()
+		}
+	case t.IsKeyword("unique"):
+		c.Type = ConstraintUnique
+		p.acceptKeywords("nulls", "not", "distinct")
+		p.acceptKeywords("nulls", "distinct")
+		if !p.acceptKeywords("using", "index") {
+			c.Columns, err = p.parseNameList()
+		}
+	case t.IsKeyword("foreign"):
+		if err := p.expectKeywords("key"); err != nil {
+			return nil, err
+		}
+		c.Type = ConstraintForeignKey
+		c.Columns, err = p.parseNameList()
+	case t.IsKeyword("check"):
+		c.Type = ConstraintCheck
+		expr := p.skipParens()
+		if len(expr) > 2 {
+			c.Expr = p.text(expr[1 : len(expr)-1])
+		}
+	case t.IsKeyword("exclude"):
+		c.Type = ConstraintExclude
+	default:
+		p.pos--
+		return nil, p.unexpected("table constraint")
+	}
+	if err != nil {
+		return nil, err
+	}
+	p.rest()
+	c.Text = p.text(p.tokens[start:p.pos])
+	c.Def = p.text(p.tokens[defStart:p.pos])
+	return c, nil
+}
+
+func (p *parser) parseCreateIndex() (*CreateIndexStmt, error) {
+	s := &CreateIndexStmt{}
+	s.Unique = p.acceptKeywords("unique")
+	if err := p.expectKeywords("index"); err != nil {
+		return nil, err
+	}
+	s.Concurrently = p.acceptKeywords("concurrently")
+	s.IfNotExists = p.acceptKeywords("if", "not", "exists")
+	if !p.peek().IsKeyword("on") {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		s.Name = name
+	}
+	if err := p.expectKeywords("on"); err != nil {
+		return nil, err
+	}
+	p.acceptKeywords("only")
+	table, err := p.parseObjectName()
+	if err != nil {
+		return nil, err
+	}
+	s.Table = table
+	if p.acceptKeywords("using") {
+		if _, err := p.parseName(); err != nil {
+			return nil, err
+		}
+	}
+	elements := p.skipParens()
+	if len(elements) < 2 {
+		return nil, p.unexpected("\"(\"")
+	}
+	for _, element := range splitTokens(elements[1:len(elements)-1], ",") {
+		s.Columns = append(s.Columns, p.text(element))
+	}
+	p.rest()
+	return s, nil
+}
+
+func (p *parser) parseAlterTable() (Stmt, error) {
+	p.pos += 2 // ALTER TABLE
+	s := &AlterTableStmt{}
+	if p.peek().IsKeyword("all") {
+		// ALTER TABLE ALL IN TABLESPACE ...
+		p.pos = 0
+		return nil, nil
+	}
+	s.IfExists = p.acceptKeywords("if", "exists")
+	s.Only = p.acceptKeywords("only")
+	table, err := p.parseObjectName()
+	if err != nil {
+		return nil, err
+	}
+	s.Table = table
+	if p.peek().Is(TokenOperator, "*") {
+		p.next()
+	}
+
+	for _, cmdTokens := range splitTokens(p.rest(), ",") {
+		if len(cmdTokens) == 0 {
+			return nil, fmt.Errorf("empty ALTER TABLE command")
+		}
+		cp := &parser{sql: p.sql, tokens: cmdTokens}
+		cmd, err := cp.parseAlterTableCmd()
+		if err != nil {
+			return nil, err
+		}
+		cmd.Text = p.text(cmdTokens)
+		s.Cmds = append(s.Cmds, cmd)
+	}
+	if len(s.Cmds) == 0 {
+		return nil, p.unexpected("ALTER TABLE command")
+	}
+	return s, nil
+}
+
+func (p *parser) parseAlterTableCmd() (*AlterTableCmd, error) {
+	cmd := &AlterTableCmd{Type: AlterTableOther}
+	var err error
+	switch {
+	case p.acceptKeywords("rename", "constraint"):
+		cmd.Type = AlterTableRenameConstraint
+		if cmd.ConstraintName, err = p.parseName(); err != nil {
+			return nil, err
+		}
+		if err = p.expectKeywords("to"); err != nil {
+			return nil, err
+		}
+		cmd.NewName, err = p.parseName()
+	case p.acceptKeywords("rename", "to"):
+		cmd.Type = AlterTableRenameTable
+		cmd.NewName, err = p.parseName()
+	case p.acceptKeywords("rename"):
+		cmd.Type = AlterTableRenameColumn
+		p.acceptKeywords("column")
+		if cmd.ColumnName, err = p.parseName(); err != nil {
+			return nil, err
+		}
+		if err = p.expectKeywords("to"); err != nil {
+			return nil, err
+		}
+		cmd.NewName, err = p.parseName()
+	case p.peek().IsKeyword("add") && tableConstraintStart(p.peekN(1)):
+		p.next()
+		cmd.Type = AlterTableAddConstraint
+		cmd.Constraint, err = p.parseTableConstraint()
+	case p.acceptKeywords("add"):
+		cmd.Type = AlterTableAddColumn
+		p.acceptKeywords("column")
+		cmd.IfExists = p.acceptKeywords("if", "not", "exists")
+		cmd.Column, err = p.parseColumnDef(false)
+	case p.acceptKeywords("drop", "constraint"):
+		cmd.Type = AlterTableDropConstraint
+		cmd.IfExists = p.acceptKeywords("if", "exists")
+		if cmd.ConstraintName, err = p.parseName(); err != nil {
+			return nil, err
+		}
+		cmd.Cascade = p.acceptKeywords("cascade")
+	case p.acceptKeywords("drop"):
+		cmd.Type = AlterTableDropColumn
+		p.acceptKeywords("column")
+		cmd.IfExists = p.acceptKeywords("if", "exists")
+		if cmd.ColumnName, err = p.parseName(); err != nil {
+			return nil, err
+		}
+		cmd.Cascade = p.acceptKeywords("cascade")
+	case p.acceptKeywords("alter"):
+		p.acceptKeywords("column")
+		if cmd.ColumnName, err = p.parseName(); err != nil {
+			return nil, err
+		}
+		err = p.parseAlterColumn(cmd)
+	case p.acceptKeywords("set", "tablespace"):
+		cmd.Type = AlterTableSetTablespace
+	case p.acceptKeywords("set", "logged"):
+		cmd.Type = AlterTableSetLogged
+	case p.acceptKeywords("set", "unlogged"):
+		cmd.Type = AlterTableSetUnlogged
+	}
+	if err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+func (p *parser) parseAlterColumn(cmd *AlterTableCmd) error {
+	switch {
+	case p.acceptKeywords("set", "data", "type"), p.acceptKeywords("type"):
+		cmd.Type = AlterTableAlterColumnType
+		typ := p.consumeUntil(keywordIn("collate", "using"))
+		if len(typ) == 0 {
+			return p.unexpected("data type")
+		}
+		cmd.DataType = p.text(typ)
+		if p.acceptKeywords("collate") {
+			if _, err := p.parseObjectName(); err != nil {
+				return err
+			}
+		}
+		if p.acceptKeywords("using") {
+			cmd.Using = p.text(p.rest())
+		}
+	case p.acceptKeywords("set", "default"):
+		cmd.Type = AlterTableSetDefault
+		cmd.Expr = p.text(p.rest())
+	case p.acceptKeywords("drop", "default"):
+		cmd.Type = AlterTableDropDefault
+	case p.acceptKeywords("set", "not", "null"):
+		cmd.Type = AlterTableSetNotNull
+	case p.acceptKeywords("drop", "not", "null"):
+		cmd.Type = AlterTableDropNotNull
+	}
+	return nil
+}
+
+// dropObjectTypes is the objects which can be dropped by names.
+var dropObjectTypes = map[string]bool{
+	"table": true, "index": true, "view": true, "sequence": true, "schema": true,
+	"type": true, "domain": true, "extension": true,
+}
+
+func (p *parser) parseDrop() (Stmt, error) {
+	p.next() // DROP
+	s := &DropStmt{}
+	switch {
+	case p.acceptKeywords("materialized", "view"):
+		s.ObjectType = "MATERIALIZED VIEW"
+	case p.acceptKeywords("foreign", "table"):
+		s.ObjectType = "FOREIGN TABLE"
+	case dropObjectTypes[p.peek().Value] && p.peek().Type == TokenIdent:
+		s.ObjectType = strings.ToUpper(p.next().Value)
+	default:
+		p.pos = 0
+		return nil, nil
+	}
+	if s.ObjectType == "INDEX" {
+		s.Concurrently = p.acceptKeywords("concurrently")
+	}
+	s.IfExists = p.acceptKeywords("if", "exists")
+	for {
+		name, err := p.parseObjectName()
+		if err != nil {
+			return nil, err
+		}
+		s.Objects = append(s.Objects, name)
+		if !p.acceptPunct(",") {
+			break
+		}
+	}
+	s.Cascade = p.acceptKeywords("cascade")
+	p.acceptKeywords("restrict")
+	if !p.eof() {
+		return nil, p.unexpected("end of statement")
+	}
+	return s, nil
+}
+
+func (p *parser) parseTruncate() (Stmt, error) {
+	p.next() // TRUNCATE
+	p.acceptKeywords("table")
+	s := &TruncateStmt{}
+	for {
+		p.acceptKeywords("only")
+		name, err := p.parseObjectName()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().Is(TokenOperator, "*") {
+			p.next()
+		}
+		s.Tables = append(s.Tables, name)
+		if !p.acceptPunct(",") {
+			break
+		}
+	}
+	p.rest()
+	return s, nil
+}
+
+// parseWith skips the common table expressions, and parses the main statement.
+func (p *parser) parseWith() (Stmt, error) {
+	p.next() // WITH
+	p.acceptKeywords("recursive")
+	for {
+		if _, err := p.parseName(); err != nil {
+			return nil, err
+		}
+		p.skipParens()
+		if err := p.expectKeywords("as"); err != nil {
+			return nil, err
+		}
+		p.acceptKeywords("not")
+		p.acceptKeywords("materialized")
+		if len(p.skipParens()) == 0 {
+			return nil, p.unexpected("\"(\"")
+		}
+		if !p.acceptPunct(",") {
+			break
+		}
+	}
+
+	var (
+		s   Stmt
+		err error
+	)
+	switch first := p.peek(); {
+	case first.IsKeyword("insert"):
+		s, err = p.parseInsert()
+	case first.IsKeyword("update"):
+		s, err = p.parseUpdate()
+	case first.IsKeyword("delete"):
+		s, err = p.parseDelete()
+	default:
+		s, err = p.parseSelect()
+	}
+	if err != nil {
+		return nil, err
+	}
+	// the SELECT * in common table expressions is also checked.
+	star := hasSelectStar(p.tokens)
+	switch stmt := s.(type) {
+	case *SelectStmt:
+		stmt.SelectStar = star
+	case *InsertStmt:
+		stmt.SelectStar = star
+	case *UpdateStmt:
+		stmt.SelectStar = star
+	case *DeleteStmt:
+		stmt.SelectStar = star
+	}
+	return s, nil
+}
+
+func (p *parser) parseSelect() (Stmt, error) {
+	tokens := p.rest()
+	s := &SelectStmt{SelectStar: hasSelectStar(tokens)}
+
+	// the FROM and WHERE of the first SELECT which is not in parentheses.
+	sp := &parser{sql: p.sql, tokens: tokens}
+	sp.consumeUntil(keywordIn("from", "where", "union", "intersect", "except"))
+	if sp.acceptKeywords("from") {
+		for {
+			sp.acceptKeywords("only")
+			if sp.peek().IsName() && !sp.peek().IsKeyword("lateral") {
+				name, err := sp.parseObjectName()
+				if err != nil {
+					return nil, err
+				}
+				s.From = append(s.From, name)
+			}
+			sp.consumeUntil(func(t Token) bool {
+				return t.IsPunct(",") || keywordIn("join", "where", "group", "having", "window", "order",
+					"limit", "offset", "fetch", "for", "union", "intersect", "except")(t)
+			})
+			if sp.acceptPunct(",") || sp.acceptKeywords("join") {
+				continue
+			}
+			break
+		}
+	}
+	s.HasWhere = sp.acceptKeywords("where")
+	return s, nil
+}
+
+func (p *parser) parseInsert() (Stmt, error) {
+	tokens := p.tokens[p.pos:]
+	p.next() // INSERT
+	if err := p.expectKeywords("into"); err != nil {
+		return nil, err
+	}
+	s := &InsertStmt{SelectStar: hasSelectStar(tokens)}
+	table, err := p.parseObjectName()
+	if err != nil {
+		return nil, err
+	}
+	s.Table = table
+	if p.acceptKeywords("as") {
+		if _, err := p.parseName(); err != nil {
+			return nil, err
+		}
+	}
+	// the column list or the query in parentheses, such as "INSERT INTO t1 (SELECT ...)".
+	if p.peek().IsPunct("(") && !p.peekN(1).IsKeyword("select") && !p.peekN(1).IsKeyword("with") {
+		if s.Columns, err = p.parseNameList(); err != nil {
+			return nil, err
+		}
+	}
+	p.rest()
+	return s, nil
+}
+
+// parseTarget parses "[ONLY] table [*] [[AS] alias]" of UPDATE and DELETE.
+func (p *parser) parseTarget() (*ObjectName, error) {
+	p.acceptKeywords("only")
+	table, err := p.parseObjectName()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().Is(TokenOperator, "*") {
+		p.next()
+	}
+	return table, nil
+}
+
+func (p *parser) parseUpdate() (Stmt, error) {
+	tokens := p.tokens[p.pos:]
+	p.next() // UPDATE
+	s := &UpdateStmt{SelectStar: hasSelectStar(tokens)}
+	table, err := p.parseTarget()
+	if err != nil {
+		return nil, err
+	}
+	s.Table = table
+	p.consumeUntil(keywordIn("set"))
+	if err := p.expectKeywords("set"); err != nil {
+		return nil, err
+	}
+	p.consumeUntil(keywordIn("where", "returning"))
+	if p.acceptKeywords("where") {
+		s.HasWhere = true
+		s.Where = p.text(p.consumeUntil(keywordIn("returning")))
+	}
+	p.rest()
+	return s, nil
+}
+
+func (p *parser) parseDelete() (Stmt, error) {
+	tokens := p.tokens[p.pos:]
+	p.next() // DELETE
+	if err := p.expectKeywords("from"); err != nil {
+		return nil, err
+	}
+	s := &DeleteStmt{SelectStar: hasSelectStar(tokens)}
+	table, err := p.parseTarget()
+	if err != nil {
+		return nil, err
+	}
+	s.Table = table
+	p.consumeUntil(keywordIn("where", "returning"))
+	if p.acceptKeywords("where") {
+		s.HasWhere = true
+		s.Where = p.text(p.consumeUntil(keywordIn("returning")))
+	}
+	p.rest()
+	return s, nil
+}
+
+// selectTargetEnd is the keywords after the target list of SELECT.
+var selectTargetEnd = keywordIn("from", "into", "where", "group", "having", "window", "order",
+	"limit", "offset", "fetch", "for", "union", "intersect", "except")
+
+// hasSelectStar returns true if "*" or "t.*" is in the target list of any SELECT
+// in tokens, the "*" in function arguments, such as "count(*)", is ignored.
+func hasSelectStar(tokens []Token) bool {
+	for i, t := range tokens {
+		if !t.IsKeyword("select") {
+			continue
+		}
+		j := i + 1
+		if j < len(tokens) && tokens[j].IsKeyword("all") {
+			j++
+		} else if j < len(tokens) && tokens[j].IsKeyword("distinct") {
+			j++
+			if j+1 < len(tokens) && tokens[j].IsKeyword("on") && tokens[j+1].IsPunct("(") {
+				p := &parser{tokens: tokens, pos: j + 1}
+				p.skipParens()
+				j = p.pos
+			}
+		}
+
+		depth, itemStart := 0, j
+		for k := j; k <= len(tokens); k++ {
+			end := k == len(tokens)
+			if !end {
+				tk := tokens[k]
+				switch {
+				case tk.IsPunct("(") || tk.IsPunct("["):
+					depth++
+					continue
+				case (tk.IsPunct(")") || tk.IsPunct("]")) && depth > 0:
+					depth--
+					continue
+				case depth > 0:
+					continue
+				case tk.IsPunct(")") || tk.IsPunct(";") || selectTargetEnd(tk):
+					end = true
+				case !tk.IsPunct(","):
+					continue
+				}
+			}
+			item := tokens[itemStart:k]
+			if isStarTarget(item) {
+				return true
+			}
+			if end {
+				break
+			}
+			itemStart = k + 1
+		}
+	}
+	return false
+}
+
+func isStarTarget(item []Token) bool {
+	n := len(item)
+	if n == 0 || !item[n-1].Is(TokenOperator, "*") {
+		return false
+	}
+	return n == 1 || item[n-2].IsPunct(".")
+}
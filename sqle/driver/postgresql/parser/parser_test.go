@@ -0,0 +1,225 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func parseOne(t *testing.T, sql string) Stmt {
+	stmts, err := Parse(sql)
+	if !assert.NoError(t, err) || !assert.Len(t, stmts, 1) {
+		t.FailNow()
+	}
+	return stmts[0]
+}
+
+func TestParse_Split(t *testing.T) {
+	stmts, err := Parse(`
+-- comment; not split
+CREATE FUNCTION f1() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql;
+/* block /* nested; */ comment */ INSERT INTO t1 VALUES ('a;b', E'c\';d');
+select 1`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, stmts, 3) {
+		t.FailNow()
+	}
+	assert.Equal(t, "CREATE FUNCTION f1() RETURNS int AS $body$ SELECT 1; $body$ LANGUAGE sql", stmts[0].Text())
+	assert.Equal(t, "CREATE FUNCTION", stmts[0].(*UnknownStmt).Keywords)
+	assert.False(t, stmts[0].IsDML())
+	assert.Equal(t, `INSERT INTO t1 VALUES ('a;b', E'c\';d')`, stmts[1].Text())
+	assert.True(t, stmts[2].IsDML())
+
+	_, err = Parse("select 'a")
+	assert.Error(t, err)
+}
+
+func TestParse_CreateTable(t *testing.T) {
+	s := parseOne(t, `CREATE TABLE IF NOT EXISTS public."User" (
+	id bigint GENERATED ALWAYS AS IDENTITY,
+	name character varying(20) NOT NULL DEFAULT '',
+	tags text[] DEFAULT NULL,
+	price numeric(10, 2) CHECK (price > 0),
+	org_id int REFERENCES org (id) ON DELETE SET NULL,
+	created_at timestamp with time zone DEFAULT now() NOT NULL,
+	CONSTRAINT user_pk PRIMARY KEY (id),
+	UNIQUE (name, org_id)
+)`).(*CreateTableStmt)
+
+	assert.True(t, s.IfNotExists)
+	assert.Equal(t, &ObjectName{Schema: "public", Name: "User"}, s.Table)
+	assert.Equal(t, `public."User"`, s.Table.String())
+	if !assert.Len(t, s.Columns, 6) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, "bigint", s.Columns[0].Type)
+	assert.NotNil(t, s.Columns[0].Constraint(ConstraintIdentity))
+
+	assert.Equal(t, "character varying(20)", s.Columns[1].Type)
+	assert.NotNil(t, s.Columns[1].Constraint(ConstraintNotNull))
+	assert.Equal(t, "''", s.Columns[1].Constraint(ConstraintDefault).Expr)
+
+	assert.Equal(t, "text[]", s.Columns[2].Type)
+	assert.Equal(t, "NULL", s.Columns[2].Constraint(ConstraintDefault).Expr)
+	assert.Equal(t, "price > 0", s.Columns[3].Constraint(ConstraintCheck).Expr)
+
+	assert.NotNil(t, s.Columns[4].Constraint(ConstraintForeignKey))
+	assert.Nil(t, s.Columns[4].Constraint(ConstraintNull))
+
+	assert.Equal(t, "timestamp with time zone", s.Columns[5].Type)
+	assert.Equal(t, "now()", s.Columns[5].Constraint(ConstraintDefault).Expr)
+	assert.NotNil(t, s.Columns[5].Constraint(ConstraintNotNull))
+
+	if !assert.Len(t, s.Constraints, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, "user_pk", s.Constraints[0].Name)
+	assert.Equal(t, ConstraintPrimaryKey, s.Constraints[0].Type)
+	assert.Equal(t, []string{"id"}, s.Constraints[0].Columns)
+	assert.Equal(t, []string{"name", "org_id"}, s.Constraints[1].Columns)
+
+	s = parseOne(t, "create table t2 partition of t1 for values in (1)").(*CreateTableStmt)
+	assert.Equal(t, "t1", s.PartitionOf.Name)
+	s = parseOne(t, "create temp table t2 as select * from t1").(*CreateTableStmt)
+	assert.True(t, s.AsQuery)
+	assert.True(t, s.Temporary)
+}
+
+func TestParse_CreateIndex(t *testing.T) {
+	s := parseOne(t, "CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_1 ON ONLY s1.t1 USING btree (lower(name), id DESC) WHERE id > 0").(*CreateIndexStmt)
+	assert.True(t, s.Unique)
+	assert.True(t, s.Concurrently)
+	assert.True(t, s.IfNotExists)
+	assert.Equal(t, "idx_1", s.Name)
+	assert.Equal(t, &ObjectName{Schema: "s1", Name: "t1"}, s.Table)
+	assert.Equal(t, []string{"lower(name)", "id DESC"}, s.Columns)
+
+	s = parseOne(t, "create index on t1 (id)").(*CreateIndexStmt)
+	assert.False(t, s.Concurrently)
+	assert.Empty(t, s.Name)
+}
+
+func TestParse_AlterTable(t *testing.T) {
+	s := parseOne(t, `ALTER TABLE IF EXISTS ONLY t1
+	ADD COLUMN IF NOT EXISTS c1 int DEFAULT 0,
+	DROP COLUMN c2 CASCADE,
+	ALTER COLUMN c3 SET DATA TYPE numeric(10, 2) USING c3::numeric,
+	ALTER c4 SET DEFAULT 'a',
+	ALTER COLUMN c5 DROP NOT NULL,
+	ADD CONSTRAINT t1_pk PRIMARY KEY (id),
+	DROP CONSTRAINT IF EXISTS t1_c6_check,
+	SET LOGGED,
+	OWNER TO u1`).(*AlterTableStmt)
+
+	assert.True(t, s.IfExists)
+	assert.True(t, s.Only)
+	assert.Equal(t, "t1", s.Table.Name)
+	if !assert.Len(t, s.Cmds, 9) {
+		t.FailNow()
+	}
+
+	assert.Equal(t, AlterTableAddColumn, s.Cmds[0].Type)
+	assert.Equal(t, "c1", s.Cmds[0].Column.Name)
+	assert.Equal(t, "0", s.Cmds[0].Column.Constraint(ConstraintDefault).Expr)
+	assert.Equal(t, AlterTableDropColumn, s.Cmds[1].Type)
+	assert.True(t, s.Cmds[1].Cascade)
+	assert.Equal(t, AlterTableAlterColumnType, s.Cmds[2].Type)
+	assert.Equal(t, "numeric(10, 2)", s.Cmds[2].DataType)
+	assert.Equal(t, "c3::numeric", s.Cmds[2].Using)
+	assert.Equal(t, AlterTableSetDefault, s.Cmds[3].Type)
+	assert.Equal(t, "'a'", s.Cmds[3].Expr)
+	assert.Equal(t, AlterTableDropNotNull, s.Cmds[4].Type)
+	assert.Equal(t, AlterTableAddConstraint, s.Cmds[5].Type)
+	assert.Equal(t, "t1_pk", s.Cmds[5].Constraint.Name)
+	assert.Equal(t, AlterTableDropConstraint, s.Cmds[6].Type)
+	assert.Equal(t, "t1_c6_check", s.Cmds[6].ConstraintName)
+	assert.Equal(t, AlterTableSetLogged, s.Cmds[7].Type)
+	assert.Equal(t, AlterTableOther, s.Cmds[8].Type)
+	assert.Equal(t, "OWNER TO u1", s.Cmds[8].Text)
+
+	s = parseOne(t, `alter table t1 rename column "C1" to c2`).(*AlterTableStmt)
+	assert.Equal(t, AlterTableRenameColumn, s.Cmds[0].Type)
+	assert.Equal(t, "C1", s.Cmds[0].ColumnName)
+	assert.Equal(t, "c2", s.Cmds[0].NewName)
+
+	s = parseOne(t, `alter table t1 rename to t2`).(*AlterTableStmt)
+	assert.Equal(t, AlterTableRenameTable, s.Cmds[0].Type)
+	assert.Equal(t, "t2", s.Cmds[0].NewName)
+}
+
+func TestParse_Drop(t *testing.T) {
+	s := parseOne(t, "DROP INDEX CONCURRENTLY IF EXISTS s1.idx_1, idx_2 CASCADE").(*DropStmt)
+	assert.Equal(t, "INDEX", s.ObjectType)
+	assert.True(t, s.Concurrently)
+	assert.True(t, s.IfExists)
+	assert.True(t, s.Cascade)
+	assert.Equal(t, []*ObjectName{{Schema: "s1", Name: "idx_1"}, {Name: "idx_2"}}, s.Objects)
+
+	_, ok := parseOne(t, "drop function f1(int)").(*UnknownStmt)
+	assert.True(t, ok)
+
+	truncate := parseOne(t, "truncate table only t1, t2 restart identity").(*TruncateStmt)
+	assert.Len(t, truncate.Tables, 2)
+}
+
+func TestParse_DML(t *testing.T) {
+	cases := []struct {
+		sql        string
+		selectStar bool
+		hasWhere   bool
+	}{
+		{"select * from t1", true, false},
+		{"select t1.* from t1 join t2 on t1.id = t2.id where t2.id = 1", true, true},
+		{"select count(*), max(id) from t1", false, false},
+		{"select id, (select * from t2 limit 1) from t1", true, false},
+		{"select distinct on (id) id, name from t1", false, false},
+		{"select a * b from t1", false, false},
+		{"with cte as (select * from t1) select id from cte", true, false},
+	}
+	for _, c := range cases {
+		s, ok := parseOne(t, c.sql).(*SelectStmt)
+		if !assert.True(t, ok, c.sql) {
+			continue
+		}
+		assert.Equal(t, c.selectStar, s.SelectStar, c.sql)
+		assert.Equal(t, c.hasWhere, s.HasWhere, c.sql)
+	}
+
+	s := parseOne(t, "select id from s1.t1 a, t2 left join t3 on true").(*SelectStmt)
+	assert.Equal(t, []*ObjectName{{Schema: "s1", Name: "t1"}, {Name: "t2"}, {Name: "t3"}}, s.From)
+
+	insert := parseOne(t, "insert into t1 (id, name) select * from t2").(*InsertStmt)
+	assert.Equal(t, []string{"id", "name"}, insert.Columns)
+	assert.True(t, insert.SelectStar)
+
+	update := parseOne(t, "update only t1 as a set name = (select name from t2 where t2.id = a.id) returning *").(*UpdateStmt)
+	assert.False(t, update.HasWhere)
+	assert.False(t, update.SelectStar)
+	update = parseOne(t, "UPDATE t1 SET name = 'a' FROM t2 WHERE t1.id = t2.id RETURNING id").(*UpdateStmt)
+	assert.True(t, update.HasWhere)
+	assert.Equal(t, "t1.id = t2.id", update.Where)
+
+	del := parseOne(t, "delete from t1 using t2 where t1.id = t2.id").(*DeleteStmt)
+	assert.True(t, del.HasWhere)
+	del = parseOne(t, "with ids as (select id from t2) delete from t1").(*DeleteStmt)
+	assert.False(t, del.HasWhere)
+	assert.Equal(t, "t1", del.Table.Name)
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Equal(t, "select * from t1 where id in (?+) and name = ?",
+		Fingerprint("SELECT *  FROM t1 /* c */ WHERE id IN (1, 2, 3) AND name = 'a';"))
+	assert.Equal(t, `insert into "T1" (id, name) values (?+)`,
+		Fingerprint(`INSERT INTO "T1" (id, name) VALUES (1, 'a'), (2, E'b')`))
+	assert.Equal(t, "select ?::int", Fingerprint("select $1::int"))
+}
+
+func TestQuoteIdent(t *testing.T) {
+	assert.Equal(t, "t1", QuoteIdent("t1"))
+	assert.Equal(t, `"T1"`, QuoteIdent("T1"))
+	assert.Equal(t, `"user"`, QuoteIdent("user"))
+	assert.Equal(t, `"a""b"`, QuoteIdent(`a"b`))
+}
@@ -0,0 +1,386 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	_driver "database/sql/driver"
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/driver/postgresql/parser"
+	rulepkg "github.com/actiontech/sqle/sqle/driver/postgresql/rule"
+	"github.com/actiontech/sqle/sqle/driver/postgresql/session"
+	"github.com/actiontech/sqle/sqle/pkg/params"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+func init() {
+	allRules := make([]*driver.Rule, len(rulepkg.RuleHandlers))
+	for i := range rulepkg.RuleHandlers {
+		allRules[i] = &rulepkg.RuleHandlers[i].Rule
+	}
+
+	driver.Register(driver.DriverTypePostgreSQL, newInspect, allRules, params.Params{}, driver.Capabilities{
+		driver.CapabilityRollback,
+		driver.CapabilityTx,
+		driver.CapabilitySchemas,
+	})
+}
+
+// Inspect implements driver.Driver interface for PostgreSQL.
+type Inspect struct {
+	// Ctx is SQL session.
+	Ctx *session.Context
+
+	rules []*driver.Rule
+
+	// result keep inspect result for single audited SQL.
+	// It refresh on every Audit.
+	result *driver.AuditResult
+	// HasInvalidSql represent one of the commit sql base-validation failed.
+	HasInvalidSql bool
+
+	inst *driver.DSN
+
+	log *logrus.Entry
+	// db is opened lazily, all SQLs are executed on conn, so that the
+	// session state such as "SET search_path" is kept between SQLs.
+	db   *sql.DB
+	conn *sql.Conn
+	// isOfflineAudit represent Audit without instance.
+	isOfflineAudit bool
+}
+
+func newInspect(log *logrus.Entry, cfg *driver.Config) (driver.Driver, error) {
+	i := &Inspect{
+		log:            log,
+		inst:           cfg.DSN,
+		rules:          cfg.Rules,
+		result:         driver.NewInspectResults(),
+		isOfflineAudit: cfg.DSN == nil,
+	}
+	i.Ctx = i.newContext()
+	return i, nil
+}
+
+func (i *Inspect) newContext() *session.Context {
+	if i.IsOfflineAudit() {
+		return session.NewContext(nil)
+	}
+	return session.NewContext(i)
+}
+
+// dataSourceName returns the DSN of pgx, the default database is "postgres".
+func dataSourceName(inst *driver.DSN) string {
+	database := inst.DatabaseName
+	if database == "" {
+		database = "postgres"
+	}
+	u := url.URL{
+		Scheme: "postgres",
+		User:   url.UserPassword(inst.User, inst.Password),
+		Host:   net.JoinHostPort(inst.Host, inst.Port),
+		Path:   "/" + database,
+	}
+	return u.String()
+}
+
+func (i *Inspect) IsOfflineAudit() bool {
+	return i.isOfflineAudit
+}
+
+// getConn get db conn and just connect once.
+func (i *Inspect) getConn(ctx context.Context) (*sql.Conn, error) {
+	if i.conn != nil {
+		return i.conn, nil
+	}
+	if i.db == nil {
+		db, err := sql.Open("pgx", dataSourceName(i.inst))
+		if err != nil {
+			return nil, errors.Wrap(err, "open database")
+		}
+		i.db = db
+	}
+	conn, err := i.db.Conn(ctx)
+	if err != nil {
+		i.log.Errorf("connect to %s:%s failed, error: %v", i.inst.Host, i.inst.Port, err)
+		return nil, errors.Wrap(err, "connect to database")
+	}
+	i.conn = conn
+	return conn, nil
+}
+
+// QueryContext implements session.Queryer.
+func (i *Inspect) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	conn, err := i.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn.QueryContext(ctx, query, args...)
+}
+
+// Reset implements driver.Resetter. It drops the SQL context and the audit
+// result of the previous task, and discards the session state such as
+// search_path, so that the driver can be reused by the next task.
+func (i *Inspect) Reset(ctx context.Context, rules []*driver.Rule) error {
+	i.rules = rules
+	i.result = driver.NewInspectResults()
+	i.HasInvalidSql = false
+	i.Ctx = i.newContext()
+
+	if i.IsOfflineAudit() || i.conn == nil {
+		return nil
+	}
+	if _, err := i.conn.ExecContext(ctx, "DISCARD ALL"); err != nil {
+		return errors.Wrap(err, "reset session")
+	}
+	return nil
+}
+
+// SetLogger implements driver.LoggerSetter.
+func (i *Inspect) SetLogger(log *logrus.Entry) {
+	i.log = log
+}
+
+func (i *Inspect) Close(ctx context.Context) {
+	if i.conn != nil {
+		if err := i.conn.Close(); err != nil {
+			i.log.Errorf("close connection failed, error: %v", err)
+		}
+		i.conn = nil
+	}
+	if i.db != nil {
+		if err := i.db.Close(); err != nil {
+			i.log.Errorf("close database failed, error: %v", err)
+		}
+		i.db = nil
+	}
+}
+
+func (i *Inspect) Ping(ctx context.Context) error {
+	if i.IsOfflineAudit() {
+		return nil
+	}
+	conn, err := i.getConn(ctx)
+	if err != nil {
+		return err
+	}
+	return conn.PingContext(ctx)
+}
+
+func (i *Inspect) Exec(ctx context.Context, query string) (_driver.Result, error) {
+	if i.IsOfflineAudit() {
+		return nil, nil
+	}
+	conn, err := i.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return conn.ExecContext(ctx, query)
+}
+
+func (i *Inspect) Tx(ctx context.Context, queries ...string) ([]_driver.Result, error) {
+	if i.IsOfflineAudit() {
+		return nil, nil
+	}
+	conn, err := i.getConn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]_driver.Result, 0, len(queries))
+	for _, query := range queries {
+		result, err := tx.ExecContext(ctx, query)
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				i.log.Errorf("rollback transaction failed, error: %v", rollbackErr)
+			}
+			return nil, errors.Wrapf(err, "exec %s", query)
+		}
+		results = append(results, result)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "commit transaction")
+	}
+	return results, nil
+}
+
+func (i *Inspect) Schemas(ctx context.Context) ([]string, error) {
+	if i.IsOfflineAudit() {
+		return nil, nil
+	}
+	rows, err := i.QueryContext(ctx, "SELECT datname FROM pg_catalog.pg_database WHERE NOT datistemplate")
+	if err != nil {
+		return nil, errors.Wrap(err, "show databases")
+	}
+	defer rows.Close()
+	var schemas []string
+	for rows.Next() {
+		var schema string
+		if err := rows.Scan(&schema); err != nil {
+			return nil, errors.Wrap(err, "show databases")
+		}
+		schemas = append(schemas, schema)
+	}
+	return schemas, rows.Err()
+}
+
+func (i *Inspect) Parse(ctx context.Context, sqlText string) ([]driver.Node, error) {
+	stmts, err := i.parseSql(sqlText)
+	if err != nil {
+		return nil, err
+	}
+	nodes := make([]driver.Node, len(stmts))
+	for idx, stmt := range stmts {
+		nodes[idx] = driver.Node{
+			Text:        stmt.Text(),
+			Type:        driver.SQLTypeDDL,
+			Fingerprint: parser.Fingerprint(stmt.Text()),
+		}
+		if stmt.IsDML() {
+			nodes[idx].Type = driver.SQLTypeDML
+		}
+	}
+	return nodes, nil
+}
+
+func (i *Inspect) parseSql(sql string) ([]parser.Stmt, error) {
+	stmts, err := parser.Parse(sql)
+	if err != nil {
+		i.log.Errorf("parse sql failed, error: %v, sql: %s", err, sql)
+		return nil, err
+	}
+	return stmts, nil
+}
+
+// parseOneSql parses sql which has one statement exactly.
+func (i *Inspect) parseOneSql(sql string) (parser.Stmt, error) {
+	stmts, err := i.parseSql(sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(stmts) != 1 {
+		return nil, driver.ErrNodesCountExceedOne
+	}
+	return stmts[0], nil
+}
+
+func (i *Inspect) Audit(ctx context.Context, sql string) (*driver.AuditResult, error) {
+	i.result = driver.NewInspectResults()
+
+	if sql == "" {
+		return nil, errors.New("sql is empty")
+	}
+	stmt, err := i.parseOneSql(sql)
+	if err != nil {
+		return nil, err
+	}
+
+	if !i.IsOfflineAudit() {
+		if err := i.checkInvalid(stmt); err != nil {
+			return nil, err
+		}
+		if i.result.HasResult() {
+			i.HasInvalidSql = true
+			i.log.Warnf("SQL %s invalid, %s", stmt.Text(), i.result.Message())
+		}
+	}
+
+	for _, rule := range i.rules {
+		handler, ok := rulepkg.RuleHandlerMap[rule.Name]
+		if !ok || handler.Func == nil {
+			continue
+		}
+		if i.IsOfflineAudit() && !handler.AllowOffline {
+			continue
+		}
+		if err := handler.Func(i.Ctx, *rule, i.result, stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	i.Ctx.UpdateContext(stmt)
+	return i.result, nil
+}
+
+// checkInvalid checks whether the objects of DDL exist or not.
+func (i *Inspect) checkInvalid(stmt parser.Stmt) error {
+	switch stmt := stmt.(type) {
+	case *parser.CreateTableStmt:
+		_, exist, err := i.Ctx.GetTable(stmt.Table)
+		if err != nil {
+			return err
+		}
+		if exist && !stmt.IfNotExists {
+			i.result.Add(driver.RuleLevelError, fmt.Sprintf("表 %s 已存在", stmt.Table))
+		}
+		if stmt.PartitionOf != nil {
+			return i.checkTableExist(stmt.PartitionOf)
+		}
+	case *parser.CreateIndexStmt:
+		return i.checkTableExist(stmt.Table)
+	case *parser.AlterTableStmt:
+		if !stmt.IfExists {
+			return i.checkTableExist(stmt.Table)
+		}
+	case *parser.DropStmt:
+		if stmt.ObjectType != "TABLE" || stmt.IfExists {
+			return nil
+		}
+		for _, table := range stmt.Objects {
+			if err := i.checkTableExist(table); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (i *Inspect) checkTableExist(table *parser.ObjectName) error {
+	_, exist, err := i.Ctx.GetTable(table)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		i.result.Add(driver.RuleLevelError, fmt.Sprintf("表 %s 不存在", table))
+	}
+	return nil
+}
+
+func (i *Inspect) AuditBatch(ctx context.Context, sqls []string) ([]*driver.AuditResult, error) {
+	results := make([]*driver.AuditResult, 0, len(sqls))
+	for _, sql := range sqls {
+		result, err := i.Audit(ctx, sql)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func (i *Inspect) GenRollbackSQL(ctx context.Context, sql string) (string, string, error) {
+	if i.IsOfflineAudit() || i.HasInvalidSql {
+		return "", "", nil
+	}
+	stmt, err := i.parseOneSql(sql)
+	if err != nil {
+		return "", "", err
+	}
+
+	rollback, reason, err := i.GenerateRollbackSql(stmt)
+	if err != nil {
+		return "", "", err
+	}
+
+	i.Ctx.UpdateContext(stmt)
+	return rollback, reason, nil
+}
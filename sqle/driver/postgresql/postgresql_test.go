@@ -0,0 +1,323 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	_driver "github.com/actiontech/sqle/sqle/driver"
+	rulepkg "github.com/actiontech/sqle/sqle/driver/postgresql/rule"
+	"github.com/actiontech/sqle/sqle/driver/postgresql/session"
+	"github.com/actiontech/sqle/sqle/log"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	queryColumns = `SELECT a.attname, pg_catalog.format_type(a.atttypid, a.atttypmod), a.attnotnull,
+COALESCE(pg_catalog.pg_get_expr(d.adbin, d.adrelid), ''), a.attidentity <> ''
+FROM pg_catalog.pg_attribute a
+JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY a.attnum`
+	queryConstraints = `SELECT con.conname, con.contype, pg_catalog.pg_get_constraintdef(con.oid),
+COALESCE((SELECT string_agg(a.attname, ',' ORDER BY k.ord)
+	FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+	JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum), '')
+FROM pg_catalog.pg_constraint con
+JOIN pg_catalog.pg_class c ON c.oid = con.conrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = $1 AND c.relname = $2
+ORDER BY con.conname`
+	queryIndexes = `SELECT indexname, indexdef FROM pg_catalog.pg_indexes
+WHERE schemaname = $1 AND tablename = $2
+ORDER BY indexname`
+)
+
+func newTestInspect(t *testing.T, rules ...string) (*Inspect, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	log.Logger().SetLevel(logrus.ErrorLevel)
+	i := &Inspect{
+		log:    log.NewEntry(),
+		inst:   &_driver.DSN{},
+		db:     db,
+		result: _driver.NewInspectResults(),
+	}
+	for _, name := range rules {
+		rule := rulepkg.RuleHandlerMap[name].Rule
+		i.rules = append(i.rules, &rule)
+	}
+	i.Ctx = i.newContext()
+	i.Ctx.SetCurrentSchema("public")
+	return i, mock
+}
+
+func newOfflineTestInspect(rules ...string) *Inspect {
+	log.Logger().SetLevel(logrus.ErrorLevel)
+	i := &Inspect{
+		log:            log.NewEntry(),
+		result:         _driver.NewInspectResults(),
+		isOfflineAudit: true,
+	}
+	for _, name := range rules {
+		rule := rulepkg.RuleHandlerMap[name].Rule
+		i.rules = append(i.rules, &rule)
+	}
+	i.Ctx = i.newContext()
+	return i
+}
+
+// expectTable mocks the catalog queries of table public.t1:
+//
+//	CREATE TABLE t1 (id bigint PRIMARY KEY, name varchar(20) NOT NULL DEFAULT 'a', age int);
+//	CREATE INDEX idx_name ON t1 (name);
+func expectTable(mock sqlmock.Sqlmock) {
+	mock.ExpectQuery(queryColumns).WithArgs("public", "t1").WillReturnRows(
+		sqlmock.NewRows([]string{"attname", "format_type", "attnotnull", "default", "identity"}).
+			AddRow("id", "bigint", true, "", false).
+			AddRow("name", "character varying(20)", true, "'a'::character varying", false).
+			AddRow("age", "integer", false, "", false))
+	mock.ExpectQuery(queryConstraints).WithArgs("public", "t1").WillReturnRows(
+		sqlmock.NewRows([]string{"conname", "contype", "def", "columns"}).
+			AddRow("t1_pkey", "p", "PRIMARY KEY (id)", "id"))
+	mock.ExpectQuery(queryIndexes).WithArgs("public", "t1").WillReturnRows(
+		sqlmock.NewRows([]string{"indexname", "indexdef"}).
+			AddRow("idx_name", "CREATE INDEX idx_name ON public.t1 USING btree (name)").
+			AddRow("t1_pkey", "CREATE UNIQUE INDEX t1_pkey ON public.t1 USING btree (id)"))
+}
+
+func expectTableNotExist(mock sqlmock.Sqlmock, table string) {
+	mock.ExpectQuery(queryColumns).WithArgs("public", table).WillReturnRows(
+		sqlmock.NewRows([]string{"attname", "format_type", "attnotnull", "default", "identity"}))
+}
+
+func auditRuleNames(t *testing.T, i *Inspect, sql string) []string {
+	result, err := i.Audit(context.TODO(), sql)
+	if !assert.NoError(t, err, sql) {
+		return nil
+	}
+	var names []string
+	for _, item := range result.Results() {
+		names = append(names, item.RuleName)
+	}
+	return names
+}
+
+func TestAuditOffline(t *testing.T) {
+	cases := []struct {
+		sql   string
+		rules []string
+	}{
+		{"create table t1 (id int)", []string{rulepkg.DDLCheckPKNotExist}},
+		{"create table t1 (id int primary key)", nil},
+		{"create table t1 (id int, constraint t1_pk primary key (id))", nil},
+		{"create table t1_p1 partition of t1 for values in (1)", nil},
+		{"create index idx_1 on t2 (id)", []string{rulepkg.DDLCheckCreateIndexWithoutConcurrently}},
+		{"create index concurrently idx_1 on t2 (id)", nil},
+		{"alter table t2 alter column c1 type int", []string{rulepkg.DDLCheckAlterTableRewrite}},
+		{"alter table t2 add column c1 timestamptz default now()", nil},
+		{"alter table t2 add column c1 uuid default gen_random_uuid()", []string{rulepkg.DDLCheckAlterTableRewrite}},
+		{"alter table t2 add column c1 bigserial", []string{rulepkg.DDLCheckAlterTableRewrite}},
+		{"alter table t2 set logged", []string{rulepkg.DDLCheckAlterTableRewrite}},
+		{"select * from t2", []string{rulepkg.DMLDisableSelectAllColumn}},
+		{"select count(*) from t2", nil},
+		{"insert into t3 select * from t2", []string{rulepkg.DMLDisableSelectAllColumn}},
+		{"update t2 set c1 = 1", []string{rulepkg.DMLCheckWhereIsInvalid}},
+		{"update t2 set c1 = 1 where 1 = 1", []string{rulepkg.DMLCheckWhereIsInvalid}},
+		{"delete from t2 where true", []string{rulepkg.DMLCheckWhereIsInvalid}},
+		{"delete from t2 where id = 1", nil},
+	}
+	allRules := []string{}
+	for _, rh := range rulepkg.RuleHandlers {
+		allRules = append(allRules, rh.Rule.Name)
+	}
+	for _, c := range cases {
+		i := newOfflineTestInspect(allRules...)
+		assert.Equal(t, c.rules, auditRuleNames(t, i, c.sql), c.sql)
+	}
+
+	// the index created on the new table is not blocking.
+	i := newOfflineTestInspect(allRules...)
+	assert.Nil(t, auditRuleNames(t, i, "create table t2 (id int primary key, c1 int)"))
+	assert.Nil(t, auditRuleNames(t, i, "create index idx_1 on t2 (c1)"))
+	assert.Nil(t, auditRuleNames(t, i, "alter table t2 alter column c1 type bigint"))
+}
+
+func TestAuditWithCatalog(t *testing.T) {
+	i, mock := newTestInspect(t, rulepkg.DDLCheckAlterTableRewrite, rulepkg.DDLCheckPKNotExist)
+	expectTable(mock)
+
+	assert.Nil(t, auditRuleNames(t, i, "alter table t1 alter column name type varchar(30)"))
+	assert.Nil(t, auditRuleNames(t, i, "alter table t1 alter column name type text"))
+	assert.Equal(t, []string{rulepkg.DDLCheckAlterTableRewrite},
+		auditRuleNames(t, i, "alter table t1 alter column age type bigint"))
+	assert.Equal(t, []string{rulepkg.DDLCheckPKNotExist},
+		auditRuleNames(t, i, "alter table t1 drop constraint t1_pkey"))
+
+	expectTableNotExist(mock, "t2")
+	result, err := i.Audit(context.TODO(), "alter table t2 add column c1 int")
+	assert.NoError(t, err)
+	assert.Equal(t, "[error]表 t2 不存在", result.Message())
+	assert.True(t, i.HasInvalidSql)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsBinaryCoercible(t *testing.T) {
+	cases := []struct {
+		oldType, newType string
+		expected         bool
+	}{
+		{"character varying(20)", "varchar(30)", true},
+		{"character varying(20)", "varchar(10)", false},
+		{"character varying(20)", "varchar", true},
+		{"character varying(20)", "text", true},
+		{"text", "varchar", true},
+		{"text", "varchar(10)", false},
+		{"numeric(10,2)", "decimal(12, 2)", true},
+		{"numeric(10,2)", "numeric(12, 3)", false},
+		{"integer", "bigint", false},
+		{"integer", "int4", true},
+		{"", "text", false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, rulepkg.IsBinaryCoercible(c.oldType, c.newType), "%s -> %s", c.oldType, c.newType)
+	}
+}
+
+func TestGenRollbackSQL(t *testing.T) {
+	cases := []struct {
+		desc     string
+		sql      string
+		rollback string
+		reason   string
+	}{
+		{
+			desc:     "add column",
+			sql:      "alter table t1 add column c1 int, alter column name set default 'b'",
+			rollback: `ALTER TABLE t1 ALTER COLUMN name SET DEFAULT 'a'::character varying, DROP COLUMN c1`,
+		},
+		{
+			desc:     "drop column",
+			sql:      "alter table t1 drop column name",
+			rollback: `ALTER TABLE t1 ADD COLUMN name character varying(20) DEFAULT 'a'::character varying`,
+		},
+		{
+			desc:     "alter column",
+			sql:      "alter table t1 alter column age type bigint, alter column age set not null, alter column name drop not null",
+			rollback: `ALTER TABLE t1 ALTER COLUMN name SET NOT NULL, ALTER COLUMN age DROP NOT NULL, ALTER COLUMN age TYPE integer`,
+		},
+		{
+			desc:     "constraint",
+			sql:      "alter table t1 drop constraint t1_pkey, add constraint t1_age_check check (age > 0), add unique (name)",
+			rollback: `ALTER TABLE t1 DROP CONSTRAINT t1_name_key, DROP CONSTRAINT t1_age_check, ADD CONSTRAINT t1_pkey PRIMARY KEY (id)`,
+		},
+		{
+			desc:   "unnamed check",
+			sql:    "alter table t1 add check (age > 0)",
+			reason: "暂不支持回滚未指定名称的约束: add check (age > 0)",
+		},
+		{
+			desc:     "rename",
+			sql:      `alter table t1 rename column age to "Age"`,
+			rollback: `ALTER TABLE t1 RENAME COLUMN "Age" TO age`,
+		},
+		{
+			desc:   "not supported",
+			sql:    "alter table t1 owner to u1",
+			reason: "暂不支持回滚 ALTER TABLE 操作: owner to u1",
+		},
+		{
+			desc:     "drop index",
+			sql:      "drop index idx_name",
+			rollback: "CREATE INDEX idx_name ON public.t1 USING btree (name)",
+		},
+		{
+			desc:   "drop constraint index",
+			sql:    "drop index t1_pkey",
+			reason: "暂不支持回滚约束 t1_pkey 对应的索引",
+		},
+		{
+			desc:     "create index",
+			sql:      "create index concurrently idx_age on t1 (age)",
+			rollback: "DROP INDEX CONCURRENTLY IF EXISTS idx_age",
+		},
+		{
+			desc: "drop table",
+			sql:  "drop table t1",
+			rollback: `CREATE TABLE t1 (
+    id bigint NOT NULL,
+    name character varying(20) DEFAULT 'a'::character varying NOT NULL,
+    age integer,
+    CONSTRAINT t1_pkey PRIMARY KEY (id)
+);
+CREATE INDEX idx_name ON public.t1 USING btree (name)`,
+		},
+		{
+			desc:   "truncate",
+			sql:    "truncate t1",
+			reason: NotSupportStatementRollback,
+		},
+	}
+	for _, c := range cases {
+		i, mock := newTestInspect(t)
+		expectTable(mock)
+		if c.desc == "drop index" || c.desc == "drop constraint index" {
+			// the index is searched in pg_indexes if its table is not cached.
+			i.Ctx = session.NewContext(i)
+			i.Ctx.SetCurrentSchema("public")
+			mock = resetExpectations(t, i)
+			mock.ExpectQuery("SELECT tablename FROM pg_catalog.pg_indexes WHERE schemaname = $1 AND indexname = $2").
+				WithArgs("public", c.sql[len("drop index "):]).
+				WillReturnRows(sqlmock.NewRows([]string{"tablename"}).AddRow("t1"))
+			expectTable(mock)
+		}
+		rollback, reason, err := i.GenRollbackSQL(context.TODO(), c.sql)
+		assert.NoError(t, err, c.desc)
+		assert.Equal(t, c.rollback, rollback, c.desc)
+		assert.Equal(t, c.reason, reason, c.desc)
+	}
+
+	i, mock := newTestInspect(t)
+	expectTableNotExist(mock, "t2")
+	rollback, _, err := i.GenRollbackSQL(context.TODO(), "create table t2 (id int primary key)")
+	assert.NoError(t, err)
+	assert.Equal(t, "DROP TABLE IF EXISTS t2", rollback)
+	// the table created before is known by context.
+	rollback, _, err = i.GenRollbackSQL(context.TODO(), "alter table t2 add column c1 int")
+	assert.NoError(t, err)
+	assert.Equal(t, "ALTER TABLE t2 DROP COLUMN c1", rollback)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// resetExpectations replaces the mocked db of i, so the expectations are in order.
+func resetExpectations(t *testing.T, i *Inspect) sqlmock.Sqlmock {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	assert.NoError(t, err)
+	i.db, i.conn = db, nil
+	return mock
+}
+
+func TestTx(t *testing.T) {
+	i, mock := newTestInspect(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("update t1 set age = 1 where id = 1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("update t1 set age = 2 where id = 2").WillReturnError(driver.ErrBadConn)
+	mock.ExpectRollback()
+	_, err := i.Tx(context.TODO(), "update t1 set age = 1 where id = 1", "update t1 set age = 2 where id = 2")
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestParse(t *testing.T) {
+	i := newOfflineTestInspect()
+	nodes, err := i.Parse(context.TODO(), "create table t1 (id int);\nSELECT * FROM t1 WHERE id = 1;")
+	assert.NoError(t, err)
+	assert.Equal(t, []_driver.Node{
+		{Text: "create table t1 (id int)", Type: _driver.SQLTypeDDL, Fingerprint: "create table t1 (id int)"},
+		{Text: "SELECT * FROM t1 WHERE id = 1", Type: _driver.SQLTypeDML, Fingerprint: "select * from t1 where id = ?"},
+	}, nodes)
+}
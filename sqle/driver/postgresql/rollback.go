@@ -0,0 +1,254 @@
+package postgresql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/driver/postgresql/parser"
+	"github.com/actiontech/sqle/sqle/driver/postgresql/session"
+)
+
+const (
+	NotSupportStatementRollback         = "暂不支持回滚该类型的语句"
+	NotSupportAlterTableCmdRollback     = "暂不支持回滚 ALTER TABLE 操作: %s"
+	NotSupportUnnamedIndexRollback      = "暂不支持回滚未指定名称的索引"
+	NotSupportUnnamedConstraintRollback = "暂不支持回滚未指定名称的约束: %s"
+	NotSupportConstraintIndexRollback   = "暂不支持回滚约束 %s 对应的索引"
+	UnknownColumnRollback               = "列 %s 不存在, 无法生成回滚语句"
+	UnknownConstraintRollback           = "约束 %s 不存在, 无法生成回滚语句"
+)
+
+// GenerateRollbackSql generates the rollback SQL of DDL, the rollback SQL is
+// generated before the stmt is applied to context. The multiple rollback SQLs
+// are separated by ";\n".
+func (i *Inspect) GenerateRollbackSql(stmt parser.Stmt) (rollbackSql, unableRollbackReason string, err error) {
+	var sqls []string
+	switch stmt := stmt.(type) {
+	case *parser.CreateTableStmt:
+		sqls, unableRollbackReason, err = i.generateCreateTableRollbackSql(stmt)
+	case *parser.CreateIndexStmt:
+		sqls, unableRollbackReason, err = i.generateCreateIndexRollbackSql(stmt)
+	case *parser.DropStmt:
+		sqls, unableRollbackReason, err = i.generateDropRollbackSql(stmt)
+	case *parser.AlterTableStmt:
+		sqls, unableRollbackReason, err = i.generateAlterTableRollbackSql(stmt)
+	case *parser.TruncateStmt:
+		unableRollbackReason = NotSupportStatementRollback
+	}
+	if err != nil || unableRollbackReason != "" {
+		return "", unableRollbackReason, err
+	}
+	return strings.Join(sqls, ";\n"), "", nil
+}
+
+func (i *Inspect) generateCreateTableRollbackSql(stmt *parser.CreateTableStmt) ([]string, string, error) {
+	_, exist, err := i.Ctx.GetTable(stmt.Table)
+	if err != nil || exist {
+		return nil, "", err
+	}
+	return []string{fmt.Sprintf("DROP TABLE IF EXISTS %s", stmt.Table)}, "", nil
+}
+
+func (i *Inspect) generateCreateIndexRollbackSql(stmt *parser.CreateIndexStmt) ([]string, string, error) {
+	if stmt.Name == "" {
+		return nil, NotSupportUnnamedIndexRollback, nil
+	}
+	table, exist, err := i.Ctx.GetTable(stmt.Table)
+	if err != nil || !exist {
+		return nil, "", err
+	}
+	for _, index := range table.Indexes {
+		if index.Name == stmt.Name {
+			// the index exists before, "IF NOT EXISTS" does nothing.
+			return nil, "", nil
+		}
+	}
+
+	// the index is always created in the schema of table.
+	name := &parser.ObjectName{Schema: stmt.Table.Schema, Name: stmt.Name}
+	if stmt.Concurrently {
+		return []string{fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", name)}, "", nil
+	}
+	return []string{fmt.Sprintf("DROP INDEX IF EXISTS %s", name)}, "", nil
+}
+
+func (i *Inspect) generateDropRollbackSql(stmt *parser.DropStmt) ([]string, string, error) {
+	var sqls []string
+	switch stmt.ObjectType {
+	case "TABLE":
+		for _, name := range stmt.Objects {
+			table, exist, err := i.Ctx.GetTable(name)
+			if err != nil {
+				return nil, "", err
+			}
+			if !exist {
+				continue
+			}
+			sqls = append(sqls, genCreateTableSqls(name, table)...)
+		}
+	case "INDEX":
+		for _, name := range stmt.Objects {
+			index, table, exist, err := i.Ctx.GetIndex(name)
+			if err != nil {
+				return nil, "", err
+			}
+			if !exist {
+				continue
+			}
+			if table.IsConstraintIndex(index.Name) {
+				return nil, fmt.Sprintf(NotSupportConstraintIndexRollback, index.Name), nil
+			}
+			sqls = append(sqls, index.Def)
+		}
+	default:
+		return nil, NotSupportStatementRollback, nil
+	}
+	return sqls, "", nil
+}
+
+// genCreateTableSqls generates CREATE TABLE and CREATE INDEX from the table
+// information, the data of table can not be restored.
+func genCreateTableSqls(name *parser.ObjectName, table *session.Table) []string {
+	var defs []string
+	for _, column := range table.Columns {
+		defs = append(defs, columnDefinition(column, true))
+	}
+	for _, constraint := range table.Constraints {
+		if constraint.Name == "" {
+			defs = append(defs, constraint.Def)
+			continue
+		}
+		defs = append(defs, fmt.Sprintf("CONSTRAINT %s %s", parser.QuoteIdent(constraint.Name), constraint.Def))
+	}
+	sqls := []string{fmt.Sprintf("CREATE TABLE %s (\n    %s\n)", name, strings.Join(defs, ",\n    "))}
+	for _, index := range table.Indexes {
+		if table.IsConstraintIndex(index.Name) {
+			continue
+		}
+		sqls = append(sqls, index.Def)
+	}
+	return sqls
+}
+
+// columnDefinition returns the definition of column, "NOT NULL" is ignored
+// if withNotNull is false.
+func columnDefinition(column *session.Column, withNotNull bool) string {
+	def := fmt.Sprintf("%s %s", parser.QuoteIdent(column.Name), column.Type)
+	if column.Identity {
+		def += " GENERATED BY DEFAULT AS IDENTITY"
+	} else if column.Default != "" {
+		def += fmt.Sprintf(" DEFAULT %s", column.Default)
+	}
+	if withNotNull && column.NotNull && !column.Identity {
+		def += " NOT NULL"
+	}
+	return def
+}
+
+// generateAlterTableRollbackSql generates the reverse commands in reverse order.
+// The RENAME can not be combined with other commands in PostgreSQL, so the
+// rollback of RENAME is a separated ALTER TABLE.
+func (i *Inspect) generateAlterTableRollbackSql(stmt *parser.AlterTableStmt) ([]string, string, error) {
+	table, exist, err := i.Ctx.GetTable(stmt.Table)
+	if err != nil || !exist {
+		return nil, "", err
+	}
+
+	tableName := stmt.Table
+	var cmds, sqls []string
+	for _, cmd := range stmt.Cmds {
+		var rollbackCmd string
+		switch cmd.Type {
+		case parser.AlterTableAddColumn:
+			if table.GetColumn(cmd.Column.Name) != nil {
+				// "ADD COLUMN IF NOT EXISTS" does nothing.
+				continue
+			}
+			rollbackCmd = fmt.Sprintf("DROP COLUMN %s", parser.QuoteIdent(cmd.Column.Name))
+		case parser.AlterTableDropColumn:
+			column := table.GetColumn(cmd.ColumnName)
+			if column == nil {
+				if cmd.IfExists {
+					continue
+				}
+				return nil, fmt.Sprintf(UnknownColumnRollback, cmd.ColumnName), nil
+			}
+			// the column is filled with NULL, so it can not be NOT NULL.
+			rollbackCmd = fmt.Sprintf("ADD COLUMN %s", columnDefinition(column, false))
+		case parser.AlterTableAlterColumnType, parser.AlterTableSetDefault, parser.AlterTableDropDefault,
+			parser.AlterTableSetNotNull, parser.AlterTableDropNotNull:
+			column := table.GetColumn(cmd.ColumnName)
+			if column == nil {
+				return nil, fmt.Sprintf(UnknownColumnRollback, cmd.ColumnName), nil
+			}
+			rollbackCmd = alterColumnRollbackCmd(cmd, column)
+		case parser.AlterTableAddConstraint:
+			constraint := session.NewConstraint(table.Name, cmd.Constraint)
+			if constraint.Name == "" {
+				return nil, fmt.Sprintf(NotSupportUnnamedConstraintRollback, cmd.Text), nil
+			}
+			rollbackCmd = fmt.Sprintf("DROP CONSTRAINT %s", parser.QuoteIdent(constraint.Name))
+		case parser.AlterTableDropConstraint:
+			constraint := table.GetConstraint(cmd.ConstraintName)
+			if constraint == nil {
+				if cmd.IfExists {
+					continue
+				}
+				return nil, fmt.Sprintf(UnknownConstraintRollback, cmd.ConstraintName), nil
+			}
+			rollbackCmd = fmt.Sprintf("ADD CONSTRAINT %s %s", parser.QuoteIdent(constraint.Name), constraint.Def)
+		case parser.AlterTableSetLogged:
+			rollbackCmd = "SET UNLOGGED"
+		case parser.AlterTableSetUnlogged:
+			rollbackCmd = "SET LOGGED"
+		case parser.AlterTableRenameColumn:
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", tableName,
+				parser.QuoteIdent(cmd.NewName), parser.QuoteIdent(cmd.ColumnName)))
+			continue
+		case parser.AlterTableRenameConstraint:
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s RENAME CONSTRAINT %s TO %s", tableName,
+				parser.QuoteIdent(cmd.NewName), parser.QuoteIdent(cmd.ConstraintName)))
+			continue
+		case parser.AlterTableRenameTable:
+			newName := &parser.ObjectName{Schema: tableName.Schema, Name: cmd.NewName}
+			sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s RENAME TO %s", newName, parser.QuoteIdent(tableName.Name)))
+			continue
+		default:
+			return nil, fmt.Sprintf(NotSupportAlterTableCmdRollback, cmd.Text), nil
+		}
+		if rollbackCmd != "" {
+			cmds = append([]string{rollbackCmd}, cmds...)
+		}
+	}
+	if len(cmds) > 0 {
+		sqls = append(sqls, fmt.Sprintf("ALTER TABLE %s %s", tableName, strings.Join(cmds, ", ")))
+	}
+	return sqls, "", nil
+}
+
+func alterColumnRollbackCmd(cmd *parser.AlterTableCmd, column *session.Column) string {
+	name := parser.QuoteIdent(column.Name)
+	switch cmd.Type {
+	case parser.AlterTableAlterColumnType:
+		return fmt.Sprintf("ALTER COLUMN %s TYPE %s", name, column.Type)
+	case parser.AlterTableSetDefault, parser.AlterTableDropDefault:
+		if column.Default == "" {
+			if cmd.Type == parser.AlterTableDropDefault {
+				return ""
+			}
+			return fmt.Sprintf("ALTER COLUMN %s DROP DEFAULT", name)
+		}
+		return fmt.Sprintf("ALTER COLUMN %s SET DEFAULT %s", name, column.Default)
+	case parser.AlterTableSetNotNull:
+		if column.NotNull {
+			return ""
+		}
+		return fmt.Sprintf("ALTER COLUMN %s DROP NOT NULL", name)
+	case parser.AlterTableDropNotNull:
+		if !column.NotNull {
+			return ""
+		}
+		return fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", name)
+	}
+	return ""
+}
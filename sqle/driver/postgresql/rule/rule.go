@@ -0,0 +1,386 @@
+package rule
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/driver/postgresql/parser"
+	"github.com/actiontech/sqle/sqle/driver/postgresql/session"
+)
+
+// rule type
+const (
+	RuleTypeIndexingConvention = "索引规范"
+	RuleTypeDDLConvention      = "DDL规范"
+	RuleTypeDMLConvention      = "DML规范"
+)
+
+const (
+	DDLCheckPKNotExist                     = "ddl_check_pk_not_exist"
+	DDLCheckCreateIndexWithoutConcurrently = "ddl_check_create_index_without_concurrently"
+	DDLCheckAlterTableRewrite              = "ddl_check_alter_table_rewrite"
+	DMLDisableSelectAllColumn              = "dml_disable_select_all_column"
+	DMLCheckWhereIsInvalid                 = "all_check_where_is_invalid"
+)
+
+type RuleHandler struct {
+	Rule    driver.Rule
+	Message string
+	Func    func(*session.Context, driver.Rule, *driver.AuditResult, parser.Stmt) error
+	// AllowOffline is true if the rule can be checked without instance.
+	AllowOffline bool
+}
+
+func addResult(result *driver.AuditResult, currentRule driver.Rule, args ...interface{}) {
+	result.AddResult(&driver.AuditResultItem{
+		Level:    currentRule.Level,
+		Message:  fmt.Sprintf(RuleHandlerMap[currentRule.Name].Message, args...),
+		RuleName: currentRule.Name,
+		Category: currentRule.Category,
+	})
+}
+
+var RuleHandlerMap = map[string]RuleHandler{}
+
+var RuleHandlers = []RuleHandler{
+	{
+		Rule: driver.Rule{
+			Name:     DDLCheckPKNotExist,
+			Desc:     "表必须有主键",
+			Level:    driver.RuleLevelError,
+			Category: RuleTypeIndexingConvention,
+		},
+		Message:      "表必须有主键",
+		AllowOffline: true,
+		Func:         checkPrimaryKey,
+	},
+	{
+		Rule: driver.Rule{
+			Name:     DDLCheckCreateIndexWithoutConcurrently,
+			Desc:     "在已有表上创建索引时建议使用 CONCURRENTLY",
+			Level:    driver.RuleLevelWarn,
+			Category: RuleTypeIndexingConvention,
+		},
+		Message:      "在已有表 %s 上创建索引未使用 CONCURRENTLY, 创建期间将阻塞表的写入",
+		AllowOffline: true,
+		Func:         checkCreateIndexConcurrently,
+	},
+	{
+		Rule: driver.Rule{
+			Name:     DDLCheckAlterTableRewrite,
+			Desc:     "不建议使用会重写表的 ALTER TABLE 操作",
+			Level:    driver.RuleLevelWarn,
+			Category: RuleTypeDDLConvention,
+		},
+		Message:      "ALTER TABLE 操作会重写表, 执行期间将锁表: %s",
+		AllowOffline: true,
+		Func:         checkAlterTableRewrite,
+	},
+	{
+		Rule: driver.Rule{
+			Name:     DMLDisableSelectAllColumn,
+			Desc:     "不建议使用select *",
+			Level:    driver.RuleLevelNotice,
+			Category: RuleTypeDMLConvention,
+		},
+		Message:      "不建议使用select *",
+		AllowOffline: true,
+		Func:         checkSelectAll,
+	},
+	{
+		Rule: driver.Rule{
+			Name:     DMLCheckWhereIsInvalid,
+			Desc:     "禁止使用没有where条件的UPDATE/DELETE语句或者使用where 1=1等变相没有条件的sql",
+			Level:    driver.RuleLevelError,
+			Category: RuleTypeDMLConvention,
+		},
+		Message:      "禁止使用没有where条件的UPDATE/DELETE语句或者使用where 1=1等变相没有条件的sql",
+		AllowOffline: true,
+		Func:         checkWhere,
+	},
+}
+
+func init() {
+	for _, rh := range RuleHandlers {
+		RuleHandlerMap[rh.Rule.Name] = rh
+	}
+}
+
+func checkPrimaryKey(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, stmt parser.Stmt) error {
+	switch stmt := stmt.(type) {
+	case *parser.CreateTableStmt:
+		// the partition has the primary key of parent table.
+		if stmt.PartitionOf != nil {
+			return nil
+		}
+		for _, constraint := range stmt.Constraints {
+			if constraint.Type == parser.ConstraintPrimaryKey {
+				return nil
+			}
+		}
+		for _, column := range stmt.Columns {
+			if column.Constraint(parser.ConstraintPrimaryKey) != nil {
+				return nil
+			}
+		}
+		addResult(res, rule)
+	case *parser.AlterTableStmt:
+		table, exist, err := ctx.GetTable(stmt.Table)
+		if err != nil || !exist {
+			return err
+		}
+		if !table.HasPrimaryKey() {
+			return nil
+		}
+		// the primary key is dropped and not added again.
+		dropped := false
+		for _, cmd := range stmt.Cmds {
+			switch {
+			case cmd.Type == parser.AlterTableDropConstraint && table.GetConstraint(cmd.ConstraintName) != nil &&
+				table.GetConstraint(cmd.ConstraintName).Type == session.ConstraintTypePrimaryKey:
+				dropped = true
+			case cmd.Type == parser.AlterTableAddConstraint && cmd.Constraint.Type == parser.ConstraintPrimaryKey,
+				cmd.Type == parser.AlterTableAddColumn && cmd.Column.Constraint(parser.ConstraintPrimaryKey) != nil:
+				dropped = false
+			}
+		}
+		if dropped {
+			addResult(res, rule)
+		}
+	}
+	return nil
+}
+
+func checkCreateIndexConcurrently(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, stmt parser.Stmt) error {
+	s, ok := stmt.(*parser.CreateIndexStmt)
+	if !ok || s.Concurrently {
+		return nil
+	}
+	table, exist, err := ctx.GetTable(s.Table)
+	if err != nil {
+		return err
+	}
+	// the table created in the same task is empty, it is not blocked for long time.
+	if exist && table.CreatedInContext {
+		return nil
+	}
+	addResult(res, rule, s.Table.String())
+	return nil
+}
+
+func checkAlterTableRewrite(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, stmt parser.Stmt) error {
+	s, ok := stmt.(*parser.AlterTableStmt)
+	if !ok {
+		return nil
+	}
+	table, exist, err := ctx.GetTable(s.Table)
+	if err != nil {
+		return err
+	}
+	if exist && table.CreatedInContext {
+		return nil
+	}
+
+	var reasons []string
+	for _, cmd := range s.Cmds {
+		switch cmd.Type {
+		case parser.AlterTableAlterColumnType:
+			if cmd.Using != "" {
+				reasons = append(reasons, fmt.Sprintf("修改列 %s 的类型并使用 USING 转换", cmd.ColumnName))
+				continue
+			}
+			oldType := ""
+			if exist {
+				if column := table.GetColumn(cmd.ColumnName); column != nil {
+					oldType = column.Type
+				}
+			}
+			if !IsBinaryCoercible(oldType, cmd.DataType) {
+				reasons = append(reasons, fmt.Sprintf("修改列 %s 的类型为 %s", cmd.ColumnName, cmd.DataType))
+			}
+		case parser.AlterTableAddColumn:
+			if reason := addColumnRewriteReason(cmd.Column); reason != "" {
+				reasons = append(reasons, fmt.Sprintf("添加列 %s %s", cmd.Column.Name, reason))
+			}
+		case parser.AlterTableSetLogged:
+			reasons = append(reasons, "SET LOGGED")
+		case parser.AlterTableSetUnlogged:
+			reasons = append(reasons, "SET UNLOGGED")
+		case parser.AlterTableSetTablespace:
+			reasons = append(reasons, "SET TABLESPACE")
+		}
+	}
+	if len(reasons) > 0 {
+		addResult(res, rule, strings.Join(reasons, "; "))
+	}
+	return nil
+}
+
+// volatileFunctions is the common volatile functions which are used as default
+// value, the column with volatile default is filled by rewriting the table.
+var volatileFunctions = []string{
+	"random", "clock_timestamp", "timeofday", "nextval", "gen_random_uuid",
+	"uuid_generate_v1", "uuid_generate_v1mc", "uuid_generate_v4",
+}
+
+var volatileFunctionRegexp = regexp.MustCompile(`(?i)\b(` + strings.Join(volatileFunctions, "|") + `)\s*\(`)
+
+// addColumnRewriteReason returns the reason why adding column rewrites the
+// table, it is empty if table is not rewritten. Since PostgreSQL 11, the
+// column with non-volatile default is added without rewriting.
+func addColumnRewriteReason(column *parser.ColumnDef) string {
+	switch strings.ToLower(column.Type) {
+	case "serial", "serial4", "bigserial", "serial8", "smallserial", "serial2":
+		return fmt.Sprintf("的类型为 %s", column.Type)
+	}
+	if column.Constraint(parser.ConstraintIdentity) != nil {
+		return "为 IDENTITY 列"
+	}
+	if column.Constraint(parser.ConstraintGenerated) != nil {
+		return "为 GENERATED 列"
+	}
+	if d := column.Constraint(parser.ConstraintDefault); d != nil && volatileFunctionRegexp.MatchString(d.Expr) {
+		return fmt.Sprintf("的默认值 %s 是易变函数", d.Expr)
+	}
+	return ""
+}
+
+var typeWithModifierRegexp = regexp.MustCompile(`^([a-z][a-z0-9 ]*?)\s*(?:\(\s*(\d+)\s*(?:,\s*(\d+)\s*)?\))?$`)
+
+var typeAliases = map[string]string{
+	"varchar":     "character varying",
+	"char":        "character",
+	"bpchar":      "character",
+	"decimal":     "numeric",
+	"varbit":      "bit varying",
+	"int":         "integer",
+	"int4":        "integer",
+	"int8":        "bigint",
+	"int2":        "smallint",
+	"timestamp":   "timestamp without time zone",
+	"timestamptz": "timestamp with time zone",
+	"time":        "time without time zone",
+	"timetz":      "time with time zone",
+	"bool":        "boolean",
+	"float8":      "double precision",
+	"float4":      "real",
+}
+
+// normalizeType splits the type into name and modifiers, the alias of type is
+// replaced by the name of format_type(), such as "varchar(20)" is "character varying", 20.
+func normalizeType(typ string) (name string, modifiers []int, ok bool) {
+	matches := typeWithModifierRegexp.FindStringSubmatch(strings.ToLower(strings.TrimSpace(typ)))
+	if matches == nil {
+		return "", nil, false
+	}
+	name = strings.Join(strings.Fields(matches[1]), " ")
+	if alias, ok := typeAliases[name]; ok {
+		name = alias
+	}
+	for _, m := range matches[2:] {
+		if m == "" {
+			continue
+		}
+		i, err := strconv.Atoi(m)
+		if err != nil {
+			return "", nil, false
+		}
+		modifiers = append(modifiers, i)
+	}
+	return name, modifiers, true
+}
+
+// IsBinaryCoercible returns true if the column type can be changed from oldType
+// to newType without rewriting the table, oldType is empty if it is unknown.
+func IsBinaryCoercible(oldType, newType string) bool {
+	newName, newModifiers, ok := normalizeType(newType)
+	if !ok {
+		return false
+	}
+	if oldType == "" {
+		return false
+	}
+	oldName, oldModifiers, ok := normalizeType(oldType)
+	if !ok {
+		return false
+	}
+
+	switch {
+	case oldName == newName && len(newModifiers) == 0:
+		// remove the length limit, such as "varchar(20)" to "varchar".
+		return oldName == "character varying" || oldName == "numeric" || oldName == "bit varying" ||
+			len(oldModifiers) == 0
+	case oldName == newName && len(oldModifiers) == 0:
+		return false
+	case oldName == newName:
+		switch oldName {
+		case "character varying", "bit varying":
+			return newModifiers[0] >= oldModifiers[0]
+		case "numeric":
+			// the precision can be increased with the same scale.
+			oldScale, newScale := 0, 0
+			if len(oldModifiers) > 1 {
+				oldScale = oldModifiers[1]
+			}
+			if len(newModifiers) > 1 {
+				newScale = newModifiers[1]
+			}
+			return newScale == oldScale && newModifiers[0] >= oldModifiers[0]
+		}
+		return false
+	case newName == "text":
+		return oldName == "character varying"
+	case newName == "character varying" && len(newModifiers) == 0:
+		return oldName == "text"
+	}
+	return false
+}
+
+func checkSelectAll(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, stmt parser.Stmt) error {
+	selectStar := false
+	switch stmt := stmt.(type) {
+	case *parser.SelectStmt:
+		selectStar = stmt.SelectStar
+	case *parser.InsertStmt:
+		selectStar = stmt.SelectStar
+	case *parser.UpdateStmt:
+		selectStar = stmt.SelectStar
+	case *parser.DeleteStmt:
+		selectStar = stmt.SelectStar
+	}
+	if selectStar {
+		addResult(res, rule)
+	}
+	return nil
+}
+
+var alwaysTrueWhereRegexp = regexp.MustCompile(`(?i)^\(*\s*(true|'t'|1\s*=\s*1|(\d+)\s*=\s*(\d+)|'([^']*)'\s*=\s*'([^']*)')\s*\)*$`)
+
+// isAlwaysTrue returns true if the WHERE condition is always true, such as "1=1".
+func isAlwaysTrue(where string) bool {
+	matches := alwaysTrueWhereRegexp.FindStringSubmatch(strings.TrimSpace(where))
+	if matches == nil {
+		return false
+	}
+	return matches[2] == matches[3] && matches[4] == matches[5]
+}
+
+func checkWhere(ctx *session.Context, rule driver.Rule, res *driver.AuditResult, stmt parser.Stmt) error {
+	var hasWhere bool
+	var where string
+	switch stmt := stmt.(type) {
+	case *parser.UpdateStmt:
+		hasWhere, where = stmt.HasWhere, stmt.Where
+	case *parser.DeleteStmt:
+		hasWhere, where = stmt.HasWhere, stmt.Where
+	default:
+		return nil
+	}
+	if !hasWhere || isAlwaysTrue(where) {
+		addResult(res, rule)
+	}
+	return nil
+}
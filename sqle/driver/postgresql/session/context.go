@@ -0,0 +1,525 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/driver/postgresql/parser"
+	"github.com/pkg/errors"
+	"github.com/ungerik/go-dry"
+)
+
+const DefaultSchema = "public"
+
+// Queryer is the connection which is used to query pg_catalog, it is
+// implemented by *sql.DB and *sql.Conn.
+type Queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+type Column struct {
+	Name string
+	// Type is the type in format of format_type(), such as "character varying(20)".
+	Type    string
+	NotNull bool
+	// Default is the default expression, it is empty if column has no default.
+	Default  string
+	Identity bool
+}
+
+const (
+	ConstraintTypePrimaryKey = "p"
+	ConstraintTypeUnique     = "u"
+	ConstraintTypeForeignKey = "f"
+	ConstraintTypeCheck      = "c"
+	ConstraintTypeExclude    = "x"
+)
+
+type Constraint struct {
+	Name string
+	// Type is the contype in pg_constraint, such as "p" for primary key.
+	Type    string
+	Columns []string
+	// Def is the definition of constraint, such as "PRIMARY KEY (id)".
+	Def string
+}
+
+type Index struct {
+	Name string
+	// Def is the CREATE INDEX statement of index.
+	Def string
+}
+
+type Table struct {
+	Schema      string
+	Name        string
+	Columns     []*Column
+	Constraints []*Constraint
+	Indexes     []*Index
+
+	// CreatedInContext is true if the table is created by the SQL in context,
+	// it is empty before the SQLs are executed.
+	CreatedInContext bool
+}
+
+func (t *Table) GetColumn(name string) *Column {
+	for _, column := range t.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+	return nil
+}
+
+func (t *Table) GetConstraint(name string) *Constraint {
+	for _, constraint := range t.Constraints {
+		if constraint.Name == name {
+			return constraint
+		}
+	}
+	return nil
+}
+
+func (t *Table) HasPrimaryKey() bool {
+	for _, constraint := range t.Constraints {
+		if constraint.Type == ConstraintTypePrimaryKey {
+			return true
+		}
+	}
+	return false
+}
+
+// IsConstraintIndex returns true if the index is created by primary key,
+// unique or exclusion constraint.
+func (t *Table) IsConstraintIndex(name string) bool {
+	constraint := t.GetConstraint(name)
+	return constraint != nil && (constraint.Type == ConstraintTypePrimaryKey ||
+		constraint.Type == ConstraintTypeUnique || constraint.Type == ConstraintTypeExclude)
+}
+
+// Context is a database information cache.
+//
+// It lazy loads the table information from pg_catalog if queryer is provided,
+// otherwise it only returns from the cache. The cache is updated by
+// UpdateContext after the SQL is audited, so the later SQLs see the change of
+// the former SQLs in the same task.
+type Context struct {
+	q Queryer
+
+	currentSchema     string
+	currentSchemaLoad bool
+
+	// tables is keyed by "schema.table", the value is nil if the table does not exist.
+	tables map[string]*Table
+}
+
+func NewContext(q Queryer) *Context {
+	return &Context{
+		q:      q,
+		tables: map[string]*Table{},
+	}
+}
+
+func (c *Context) SetCurrentSchema(schema string) {
+	c.currentSchema = schema
+	c.currentSchemaLoad = true
+}
+
+// CurrentSchema returns the first existing schema in search_path, it is
+// "public" on offline audit.
+func (c *Context) CurrentSchema() (string, error) {
+	if c.currentSchemaLoad {
+		return c.currentSchema, nil
+	}
+	if c.q == nil {
+		c.SetCurrentSchema(DefaultSchema)
+		return c.currentSchema, nil
+	}
+	rows, err := c.q.QueryContext(context.TODO(), "SELECT current_schema()")
+	if err != nil {
+		return "", errors.Wrap(err, "get current schema")
+	}
+	defer rows.Close()
+	var schema sql.NullString
+	for rows.Next() {
+		if err := rows.Scan(&schema); err != nil {
+			return "", errors.Wrap(err, "get current schema")
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return "", errors.Wrap(err, "get current schema")
+	}
+	if !schema.Valid {
+		schema.String = DefaultSchema
+	}
+	c.SetCurrentSchema(schema.String)
+	return c.currentSchema, nil
+}
+
+// GetSchemaName returns the schema of name, it is the current schema if name is not qualified.
+func (c *Context) GetSchemaName(name *parser.ObjectName) (string, error) {
+	if name.Schema != "" {
+		return name.Schema, nil
+	}
+	return c.CurrentSchema()
+}
+
+func tableKey(schema, table string) string {
+	return fmt.Sprintf("%s.%s", schema, table)
+}
+
+// GetTable returns the table information, exist is false if the table does not exist.
+func (c *Context) GetTable(name *parser.ObjectName) (table *Table, exist bool, err error) {
+	schema, err := c.GetSchemaName(name)
+	if err != nil {
+		return nil, false, err
+	}
+	key := tableKey(schema, name.Name)
+	if table, ok := c.tables[key]; ok {
+		return table, table != nil, nil
+	}
+	if c.q == nil {
+		return nil, false, nil
+	}
+	table, err = c.loadTable(schema, name.Name)
+	if err != nil {
+		return nil, false, err
+	}
+	c.tables[key] = table
+	return table, table != nil, nil
+}
+
+const queryColumns = `SELECT a.attname, pg_catalog.format_type(a.atttypid, a.atttypmod), a.attnotnull,
+COALESCE(pg_catalog.pg_get_expr(d.adbin, d.adrelid), ''), a.attidentity <> ''
+FROM pg_catalog.pg_attribute a
+JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE n.nspname = $1 AND c.relname = $2 AND c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped
+ORDER BY a.attnum`
+
+const queryConstraints = `SELECT con.conname, con.contype, pg_catalog.pg_get_constraintdef(con.oid),
+COALESCE((SELECT string_agg(a.attname, ',' ORDER BY k.ord)
+	FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+	JOIN pg_catalog.pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum), '')
+FROM pg_catalog.pg_constraint con
+JOIN pg_catalog.pg_class c ON c.oid = con.conrelid
+JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = $1 AND c.relname = $2
+ORDER BY con.conname`
+
+const queryIndexes = `SELECT indexname, indexdef FROM pg_catalog.pg_indexes
+WHERE schemaname = $1 AND tablename = $2
+ORDER BY indexname`
+
+// loadTable loads table from pg_catalog, it returns nil if the table does
+// not exist. The table without any column is treated as not exist.
+func (c *Context) loadTable(schema, name string) (*Table, error) {
+	table := &Table{Schema: schema, Name: name}
+
+	err := c.query(func(rows *sql.Rows) error {
+		column := &Column{}
+		if err := rows.Scan(&column.Name, &column.Type, &column.NotNull, &column.Default, &column.Identity); err != nil {
+			return err
+		}
+		table.Columns = append(table.Columns, column)
+		return nil
+	}, queryColumns, schema, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get columns of table %s.%s", schema, name)
+	}
+	if len(table.Columns) == 0 {
+		return nil, nil
+	}
+
+	err = c.query(func(rows *sql.Rows) error {
+		constraint := &Constraint{}
+		var columns string
+		if err := rows.Scan(&constraint.Name, &constraint.Type, &constraint.Def, &columns); err != nil {
+			return err
+		}
+		if columns != "" {
+			constraint.Columns = strings.Split(columns, ",")
+		}
+		table.Constraints = append(table.Constraints, constraint)
+		return nil
+	}, queryConstraints, schema, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get constraints of table %s.%s", schema, name)
+	}
+
+	err = c.query(func(rows *sql.Rows) error {
+		index := &Index{}
+		if err := rows.Scan(&index.Name, &index.Def); err != nil {
+			return err
+		}
+		table.Indexes = append(table.Indexes, index)
+		return nil
+	}, queryIndexes, schema, name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "get indexes of table %s.%s", schema, name)
+	}
+	return table, nil
+}
+
+func (c *Context) query(scan func(rows *sql.Rows) error, query string, args ...interface{}) error {
+	rows, err := c.q.QueryContext(context.TODO(), query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetIndex returns the index and its table, exist is false if the index does not exist.
+func (c *Context) GetIndex(name *parser.ObjectName) (index *Index, table *Table, exist bool, err error) {
+	schema, err := c.GetSchemaName(name)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	for _, t := range c.tables {
+		if t == nil || t.Schema != schema {
+			continue
+		}
+		for _, index := range t.Indexes {
+			if index.Name == name.Name {
+				return index, t, true, nil
+			}
+		}
+	}
+	if c.q == nil {
+		return nil, nil, false, nil
+	}
+
+	var tableName string
+	err = c.query(func(rows *sql.Rows) error {
+		return rows.Scan(&tableName)
+	}, "SELECT tablename FROM pg_catalog.pg_indexes WHERE schemaname = $1 AND indexname = $2", schema, name.Name)
+	if err != nil {
+		return nil, nil, false, errors.Wrapf(err, "get index %s.%s", schema, name.Name)
+	}
+	if tableName == "" {
+		return nil, nil, false, nil
+	}
+	table, exist, err = c.GetTable(&parser.ObjectName{Schema: schema, Name: tableName})
+	if err != nil || !exist {
+		return nil, nil, false, err
+	}
+	for _, index := range table.Indexes {
+		if index.Name == name.Name {
+			return index, table, true, nil
+		}
+	}
+	return nil, nil, false, nil
+}
+
+// UpdateContext applies the change of DDL to the cache.
+func (c *Context) UpdateContext(stmt parser.Stmt) {
+	switch stmt := stmt.(type) {
+	case *parser.CreateTableStmt:
+		schema, err := c.GetSchemaName(stmt.Table)
+		if err != nil {
+			return
+		}
+		if _, exist, err := c.GetTable(stmt.Table); err != nil || exist {
+			return
+		}
+		table := newTableFromStmt(schema, stmt)
+		table.CreatedInContext = true
+		c.tables[tableKey(schema, stmt.Table.Name)] = table
+	case *parser.CreateIndexStmt:
+		table, exist, err := c.GetTable(stmt.Table)
+		if err != nil || !exist || stmt.Name == "" {
+			return
+		}
+		table.Indexes = append(table.Indexes, &Index{Name: stmt.Name, Def: stmt.Text()})
+	case *parser.DropStmt:
+		for _, object := range stmt.Objects {
+			switch stmt.ObjectType {
+			case "TABLE":
+				schema, err := c.GetSchemaName(object)
+				if err != nil {
+					return
+				}
+				c.tables[tableKey(schema, object.Name)] = nil
+			case "INDEX":
+				index, table, exist, err := c.GetIndex(object)
+				if err != nil || !exist {
+					continue
+				}
+				table.Indexes = removeIndex(table.Indexes, index.Name)
+			}
+		}
+	case *parser.AlterTableStmt:
+		table, exist, err := c.GetTable(stmt.Table)
+		if err != nil || !exist {
+			return
+		}
+		for _, cmd := range stmt.Cmds {
+			c.alterTable(table, cmd)
+		}
+	}
+}
+
+func (c *Context) alterTable(table *Table, cmd *parser.AlterTableCmd) {
+	switch cmd.Type {
+	case parser.AlterTableAddColumn:
+		if table.GetColumn(cmd.Column.Name) != nil {
+			return
+		}
+		table.Columns = append(table.Columns, newColumnFromDef(cmd.Column))
+		table.Constraints = append(table.Constraints, newColumnConstraints(table.Name, cmd.Column)...)
+	case parser.AlterTableDropColumn:
+		for i, column := range table.Columns {
+			if column.Name == cmd.ColumnName {
+				table.Columns = append(table.Columns[:i], table.Columns[i+1:]...)
+				break
+			}
+		}
+		// the indexes and constraints on the column are dropped with column.
+		constraints := table.Constraints[:0]
+		for _, constraint := range table.Constraints {
+			if dry.StringInSlice(cmd.ColumnName, constraint.Columns) {
+				table.Indexes = removeIndex(table.Indexes, constraint.Name)
+				continue
+			}
+			constraints = append(constraints, constraint)
+		}
+		table.Constraints = constraints
+	case parser.AlterTableAlterColumnType:
+		if column := table.GetColumn(cmd.ColumnName); column != nil {
+			column.Type = cmd.DataType
+		}
+	case parser.AlterTableSetDefault, parser.AlterTableDropDefault:
+		if column := table.GetColumn(cmd.ColumnName); column != nil {
+			column.Default = cmd.Expr
+		}
+	case parser.AlterTableSetNotNull, parser.AlterTableDropNotNull:
+		if column := table.GetColumn(cmd.ColumnName); column != nil {
+			column.NotNull = cmd.Type == parser.AlterTableSetNotNull
+		}
+	case parser.AlterTableAddConstraint:
+		table.Constraints = append(table.Constraints, NewConstraint(table.Name, cmd.Constraint))
+	case parser.AlterTableDropConstraint:
+		for i, constraint := range table.Constraints {
+			if constraint.Name == cmd.ConstraintName {
+				table.Constraints = append(table.Constraints[:i], table.Constraints[i+1:]...)
+				break
+			}
+		}
+		table.Indexes = removeIndex(table.Indexes, cmd.ConstraintName)
+	case parser.AlterTableRenameColumn:
+		if column := table.GetColumn(cmd.ColumnName); column != nil {
+			column.Name = cmd.NewName
+		}
+	case parser.AlterTableRenameConstraint:
+		if constraint := table.GetConstraint(cmd.ConstraintName); constraint != nil {
+			constraint.Name = cmd.NewName
+		}
+	case parser.AlterTableRenameTable:
+		c.tables[tableKey(table.Schema, table.Name)] = nil
+		table.Name = cmd.NewName
+		c.tables[tableKey(table.Schema, table.Name)] = table
+	}
+}
+
+func removeIndex(indexes []*Index, name string) []*Index {
+	for i, index := range indexes {
+		if index.Name == name {
+			return append(indexes[:i], indexes[i+1:]...)
+		}
+	}
+	return indexes
+}
+
+func newTableFromStmt(schema string, stmt *parser.CreateTableStmt) *Table {
+	table := &Table{Schema: schema, Name: stmt.Table.Name}
+	for _, def := range stmt.Columns {
+		table.Columns = append(table.Columns, newColumnFromDef(def))
+		table.Constraints = append(table.Constraints, newColumnConstraints(table.Name, def)...)
+	}
+	for _, constraint := range stmt.Constraints {
+		table.Constraints = append(table.Constraints, NewConstraint(table.Name, constraint))
+	}
+	return table
+}
+
+func newColumnFromDef(def *parser.ColumnDef) *Column {
+	column := &Column{Name: def.Name, Type: def.Type}
+	for _, constraint := range def.Constraints {
+		switch constraint.Type {
+		case parser.ConstraintNotNull, parser.ConstraintPrimaryKey:
+			column.NotNull = true
+		case parser.ConstraintDefault:
+			column.Default = constraint.Expr
+		case parser.ConstraintIdentity:
+			column.NotNull = true
+			column.Identity = true
+		}
+	}
+	return column
+}
+
+// newColumnConstraints returns the primary key and unique constraints of
+// column, the name is generated as PostgreSQL does if it is not named.
+func newColumnConstraints(table string, def *parser.ColumnDef) []*Constraint {
+	var constraints []*Constraint
+	for _, constraint := range def.Constraints {
+		var typ, suffix string
+		switch constraint.Type {
+		case parser.ConstraintPrimaryKey:
+			typ, suffix = ConstraintTypePrimaryKey, "pkey"
+		case parser.ConstraintUnique:
+			typ, suffix = ConstraintTypeUnique, "key"
+		default:
+			continue
+		}
+		name := constraint.Name
+		if name == "" {
+			name = fmt.Sprintf("%s_%s_%s", table, def.Name, suffix)
+			if typ == ConstraintTypePrimaryKey {
+				name = fmt.Sprintf("%s_%s", table, suffix)
+			}
+		}
+		constraints = append(constraints, &Constraint{
+			Name:    name,
+			Type:    typ,
+			Columns: []string{def.Name},
+			Def:     constraint.Def,
+		})
+	}
+	return constraints
+}
+
+func NewConstraint(table string, constraint *parser.Constraint) *Constraint {
+	c := &Constraint{Name: constraint.Name, Columns: constraint.Columns, Def: constraint.Def}
+	switch constraint.Type {
+	case parser.ConstraintPrimaryKey:
+		c.Type = ConstraintTypePrimaryKey
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("%s_pkey", table)
+		}
+	case parser.ConstraintUnique:
+		c.Type = ConstraintTypeUnique
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("%s_%s_key", table, strings.Join(c.Columns, "_"))
+		}
+	case parser.ConstraintForeignKey:
+		c.Type = ConstraintTypeForeignKey
+		if c.Name == "" {
+			c.Name = fmt.Sprintf("%s_%s_fkey", table, strings.Join(c.Columns, "_"))
+		}
+	case parser.ConstraintCheck:
+		c.Type = ConstraintTypeCheck
+	case parser.ConstraintExclude:
+		c.Type = ConstraintTypeExclude
+	}
+	return c
+}
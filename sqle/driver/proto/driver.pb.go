@@ -27,6 +27,9 @@ It has these top-level messages:
 	GenRollbackSQLRequest
 	GenRollbackSQLResponse
 	MetasResponse
+	Position
+	AuditBatchRequest
+	AuditBatchResponse
 */
 package proto
 
@@ -113,6 +116,7 @@ type Rule struct {
 	Level    string   `protobuf:"bytes,4,opt,name=level" json:"level,omitempty"`
 	Category string   `protobuf:"bytes,5,opt,name=category" json:"category,omitempty"`
 	Params   []*Param `protobuf:"bytes,6,rep,name=params" json:"params,omitempty"`
+	Custom   string   `protobuf:"bytes,7,opt,name=custom" json:"custom,omitempty"`
 }
 
 func (m *Rule) Reset()                    { *m = Rule{} }
@@ -162,6 +166,13 @@ func (m *Rule) GetParams() []*Param {
 	return nil
 }
 
+func (m *Rule) GetCustom() string {
+	if m != nil {
+		return m.Custom
+	}
+	return ""
+}
+
 type Param struct {
 	Key   string `protobuf:"bytes,1,opt,name=key" json:"key,omitempty"`
 	Value string `protobuf:"bytes,2,opt,name=value" json:"value,omitempty"`
@@ -419,8 +430,12 @@ func (m *AuditRequest) GetSql() string {
 }
 
 type AuditResult struct {
-	Message string `protobuf:"bytes,1,opt,name=message" json:"message,omitempty"`
-	Level   string `protobuf:"bytes,2,opt,name=level" json:"level,omitempty"`
+	Message    string    `protobuf:"bytes,1,opt,name=message" json:"message,omitempty"`
+	Level      string    `protobuf:"bytes,2,opt,name=level" json:"level,omitempty"`
+	RuleName   string    `protobuf:"bytes,3,opt,name=ruleName" json:"ruleName,omitempty"`
+	Category   string    `protobuf:"bytes,4,opt,name=category" json:"category,omitempty"`
+	Position   *Position `protobuf:"bytes,5,opt,name=position" json:"position,omitempty"`
+	Suggestion string    `protobuf:"bytes,6,opt,name=suggestion" json:"suggestion,omitempty"`
 }
 
 func (m *AuditResult) Reset()                    { *m = AuditResult{} }
@@ -442,6 +457,34 @@ func (m *AuditResult) GetLevel() string {
 	return ""
 }
 
+func (m *AuditResult) GetRuleName() string {
+	if m != nil {
+		return m.RuleName
+	}
+	return ""
+}
+
+func (m *AuditResult) GetCategory() string {
+	if m != nil {
+		return m.Category
+	}
+	return ""
+}
+
+func (m *AuditResult) GetPosition() *Position {
+	if m != nil {
+		return m.Position
+	}
+	return nil
+}
+
+func (m *AuditResult) GetSuggestion() string {
+	if m != nil {
+		return m.Suggestion
+	}
+	return ""
+}
+
 type AuditResponse struct {
 	Results []*AuditResult `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
 }
@@ -499,9 +542,13 @@ func (m *GenRollbackSQLResponse) GetReason() string {
 }
 
 type MetasResponse struct {
-	Name             string   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
-	Rules            []*Rule  `protobuf:"bytes,2,rep,name=rules" json:"rules,omitempty"`
-	AdditionalParams []*Param `protobuf:"bytes,3,rep,name=additionalParams" json:"additionalParams,omitempty"`
+	Name              string   `protobuf:"bytes,1,opt,name=name" json:"name,omitempty"`
+	Rules             []*Rule  `protobuf:"bytes,2,rep,name=rules" json:"rules,omitempty"`
+	AdditionalParams  []*Param `protobuf:"bytes,3,rep,name=additionalParams" json:"additionalParams,omitempty"`
+	SupportAuditBatch bool     `protobuf:"varint,4,opt,name=supportAuditBatch" json:"supportAuditBatch,omitempty"`
+	Capabilities      []string `protobuf:"bytes,5,rep,name=capabilities" json:"capabilities,omitempty"`
+	Version           string   `protobuf:"bytes,6,opt,name=version" json:"version,omitempty"`
+	SupportReset      bool     `protobuf:"varint,7,opt,name=supportReset" json:"supportReset,omitempty"`
 }
 
 func (m *MetasResponse) Reset()                    { *m = MetasResponse{} }
@@ -530,6 +577,90 @@ func (m *MetasResponse) GetAdditionalParams() []*Param {
 	return nil
 }
 
+func (m *MetasResponse) GetSupportAuditBatch() bool {
+	if m != nil {
+		return m.SupportAuditBatch
+	}
+	return false
+}
+
+func (m *MetasResponse) GetCapabilities() []string {
+	if m != nil {
+		return m.Capabilities
+	}
+	return nil
+}
+
+func (m *MetasResponse) GetVersion() string {
+	if m != nil {
+		return m.Version
+	}
+	return ""
+}
+
+func (m *MetasResponse) GetSupportReset() bool {
+	if m != nil {
+		return m.SupportReset
+	}
+	return false
+}
+
+type Position struct {
+	Offset int64 `protobuf:"varint,1,opt,name=offset" json:"offset,omitempty"`
+	Line   int64 `protobuf:"varint,2,opt,name=line" json:"line,omitempty"`
+}
+
+func (m *Position) Reset()                    { *m = Position{} }
+func (m *Position) String() string            { return proto1.CompactTextString(m) }
+func (*Position) ProtoMessage()               {}
+func (*Position) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{19} }
+
+func (m *Position) GetOffset() int64 {
+	if m != nil {
+		return m.Offset
+	}
+	return 0
+}
+
+func (m *Position) GetLine() int64 {
+	if m != nil {
+		return m.Line
+	}
+	return 0
+}
+
+type AuditBatchRequest struct {
+	Sqls []string `protobuf:"bytes,1,rep,name=sqls" json:"sqls,omitempty"`
+}
+
+func (m *AuditBatchRequest) Reset()                    { *m = AuditBatchRequest{} }
+func (m *AuditBatchRequest) String() string            { return proto1.CompactTextString(m) }
+func (*AuditBatchRequest) ProtoMessage()               {}
+func (*AuditBatchRequest) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{20} }
+
+func (m *AuditBatchRequest) GetSqls() []string {
+	if m != nil {
+		return m.Sqls
+	}
+	return nil
+}
+
+type AuditBatchResponse struct {
+	Results []*AuditResponse `protobuf:"bytes,1,rep,name=results" json:"results,omitempty"`
+}
+
+func (m *AuditBatchResponse) Reset()                    { *m = AuditBatchResponse{} }
+func (m *AuditBatchResponse) String() string            { return proto1.CompactTextString(m) }
+func (*AuditBatchResponse) ProtoMessage()               {}
+func (*AuditBatchResponse) Descriptor() ([]byte, []int) { return fileDescriptor0, []int{21} }
+
+func (m *AuditBatchResponse) GetResults() []*AuditResponse {
+	if m != nil {
+		return m.Results
+	}
+	return nil
+}
+
 func init() {
 	proto1.RegisterType((*DSN)(nil), "proto.DSN")
 	proto1.RegisterType((*Rule)(nil), "proto.Rule")
@@ -550,6 +681,9 @@ func init() {
 	proto1.RegisterType((*GenRollbackSQLRequest)(nil), "proto.GenRollbackSQLRequest")
 	proto1.RegisterType((*GenRollbackSQLResponse)(nil), "proto.GenRollbackSQLResponse")
 	proto1.RegisterType((*MetasResponse)(nil), "proto.MetasResponse")
+	proto1.RegisterType((*Position)(nil), "proto.Position")
+	proto1.RegisterType((*AuditBatchRequest)(nil), "proto.AuditBatchRequest")
+	proto1.RegisterType((*AuditBatchResponse)(nil), "proto.AuditBatchResponse")
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -578,6 +712,9 @@ type DriverClient interface {
 	Parse(ctx context.Context, in *ParseRequest, opts ...grpc.CallOption) (*ParseResponse, error)
 	Audit(ctx context.Context, in *AuditRequest, opts ...grpc.CallOption) (*AuditResponse, error)
 	GenRollbackSQL(ctx context.Context, in *GenRollbackSQLRequest, opts ...grpc.CallOption) (*GenRollbackSQLResponse, error)
+	// AuditBatch audits SQLs in order in one call, it keeps the SQL context
+	// just like calling Audit one by one.
+	AuditBatch(ctx context.Context, in *AuditBatchRequest, opts ...grpc.CallOption) (*AuditBatchResponse, error)
 }
 
 type driverClient struct {
@@ -678,6 +815,15 @@ func (c *driverClient) GenRollbackSQL(ctx context.Context, in *GenRollbackSQLReq
 	return out, nil
 }
 
+func (c *driverClient) AuditBatch(ctx context.Context, in *AuditBatchRequest, opts ...grpc.CallOption) (*AuditBatchResponse, error) {
+	out := new(AuditBatchResponse)
+	err := grpc.Invoke(ctx, "/proto.Driver/AuditBatch", in, out, c.cc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // Server API for Driver service
 
 type DriverServer interface {
@@ -696,6 +842,9 @@ type DriverServer interface {
 	Parse(context.Context, *ParseRequest) (*ParseResponse, error)
 	Audit(context.Context, *AuditRequest) (*AuditResponse, error)
 	GenRollbackSQL(context.Context, *GenRollbackSQLRequest) (*GenRollbackSQLResponse, error)
+	// AuditBatch audits SQLs in order in one call, it keeps the SQL context
+	// just like calling Audit one by one.
+	AuditBatch(context.Context, *AuditBatchRequest) (*AuditBatchResponse, error)
 }
 
 func RegisterDriverServer(s *grpc.Server, srv DriverServer) {
@@ -882,6 +1031,24 @@ func _Driver_GenRollbackSQL_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Driver_AuditBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AuditBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DriverServer).AuditBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/proto.Driver/AuditBatch",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DriverServer).AuditBatch(ctx, req.(*AuditBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _Driver_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.Driver",
 	HandlerType: (*DriverServer)(nil),
@@ -926,6 +1093,10 @@ var _Driver_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GenRollbackSQL",
 			Handler:    _Driver_GenRollbackSQL_Handler,
 		},
+		{
+			MethodName: "AuditBatch",
+			Handler:    _Driver_AuditBatch_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "driver.proto",
@@ -934,57 +1105,69 @@ var _Driver_serviceDesc = grpc.ServiceDesc{
 func init() { proto1.RegisterFile("driver.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 822 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x54, 0xdb, 0x6a, 0xe4, 0x46,
-	0x10, 0x45, 0x23, 0x69, 0xbc, 0x53, 0x1a, 0x07, 0xbb, 0xd7, 0x59, 0xc4, 0xe0, 0xc0, 0x6c, 0x6f,
-	0x02, 0xb3, 0x64, 0xe3, 0x25, 0xb3, 0x2f, 0x81, 0x65, 0x1f, 0xd6, 0xb1, 0x09, 0x86, 0xac, 0x71,
-	0x64, 0x43, 0x20, 0x6f, 0xed, 0x51, 0xd9, 0x11, 0x2b, 0x4b, 0x9a, 0xee, 0x96, 0x2f, 0x6f, 0xf9,
-	0x94, 0x7c, 0x43, 0xf2, 0x63, 0xf9, 0x84, 0xd0, 0x37, 0x5d, 0xe6, 0x12, 0xf2, 0xa4, 0xaa, 0x53,
-	0xd5, 0xd5, 0xa7, 0x4b, 0x75, 0x0a, 0xc6, 0x29, 0xcf, 0xee, 0x91, 0x1f, 0x55, 0xbc, 0x94, 0x25,
-	0x09, 0xf5, 0x87, 0xfe, 0xed, 0x81, 0x7f, 0x72, 0x79, 0x4e, 0x08, 0x04, 0xbf, 0x97, 0x42, 0xc6,
-	0xde, 0xd4, 0x9b, 0x8d, 0x12, 0x6d, 0x2b, 0xac, 0x2a, 0xb9, 0x8c, 0x07, 0x06, 0x53, 0xb6, 0xc2,
-	0x6a, 0x81, 0x3c, 0xf6, 0x0d, 0xa6, 0x6c, 0x32, 0x81, 0x67, 0x15, 0x13, 0xe2, 0xa1, 0xe4, 0x69,
-	0x1c, 0x68, 0xbc, 0xf1, 0x55, 0x2c, 0x65, 0x92, 0x5d, 0x33, 0x81, 0x71, 0x68, 0x62, 0xce, 0x27,
-	0x3f, 0xc0, 0x1e, 0x4b, 0xd3, 0x4c, 0x66, 0x65, 0xc1, 0xf2, 0x0b, 0xc6, 0xd9, 0x9d, 0x88, 0x87,
-	0x53, 0x7f, 0x16, 0xcd, 0xc7, 0x86, 0xe4, 0x91, 0x06, 0x93, 0xb5, 0x2c, 0xfa, 0xa7, 0x07, 0x41,
-	0x52, 0xe7, 0xa8, 0xe8, 0x14, 0xec, 0x0e, 0x1d, 0x6d, 0x65, 0x2b, 0x2c, 0x45, 0xb1, 0x70, 0xb4,
-	0x95, 0x4d, 0x62, 0x08, 0xef, 0x59, 0x5e, 0xa3, 0xe1, 0x7d, 0x3c, 0x88, 0xbd, 0xc4, 0x00, 0xe4,
-	0x00, 0xc2, 0x1c, 0xef, 0x31, 0xb7, 0xcc, 0x8d, 0xa3, 0x68, 0x2f, 0x98, 0xc4, 0xdb, 0x92, 0x3f,
-	0x39, 0xda, 0xce, 0x27, 0x5f, 0xc3, 0xb0, 0xda, 0x4e, 0xd6, 0xc6, 0xe8, 0xaf, 0x10, 0x6a, 0x80,
-	0xec, 0x81, 0xff, 0x19, 0x9f, 0x2c, 0x43, 0x65, 0xaa, 0x2b, 0x0d, 0x19, 0xc3, 0xd0, 0x12, 0x71,
-	0xb4, 0xfd, 0x0e, 0x6d, 0x02, 0x81, 0x7c, 0xaa, 0xd0, 0x72, 0xd3, 0x36, 0x3d, 0x87, 0xe8, 0xac,
-	0xc8, 0x64, 0x82, 0xcb, 0x1a, 0x85, 0x24, 0x87, 0xe0, 0xa7, 0xa2, 0xd0, 0xe5, 0xa3, 0x39, 0x58,
-	0x2a, 0x27, 0x97, 0xe7, 0x89, 0x82, 0xc9, 0x4b, 0x08, 0x79, 0x9d, 0xa3, 0x88, 0x7d, 0x4d, 0x35,
-	0xb2, 0x71, 0xd5, 0xbb, 0xc4, 0x44, 0xe8, 0x0e, 0x84, 0xa7, 0x77, 0x95, 0x7c, 0xa2, 0xaf, 0x20,
-	0x3a, 0x7d, 0xc4, 0x85, 0x2b, 0x7c, 0x00, 0xe1, 0xb2, 0x46, 0xee, 0x98, 0x1b, 0x87, 0xfe, 0xe5,
-	0xc1, 0xd8, 0x64, 0x89, 0xaa, 0x2c, 0x04, 0x12, 0x0a, 0xe3, 0x9c, 0x09, 0x79, 0x56, 0x08, 0xe4,
-	0xf2, 0x2c, 0xd5, 0xd9, 0x7e, 0xd2, 0xc3, 0xc8, 0x1b, 0xd8, 0xef, 0xfa, 0xa7, 0x9c, 0x97, 0xdc,
-	0x3e, 0x7e, 0x3d, 0xa0, 0x2a, 0xf2, 0xf2, 0x41, 0x7c, 0xbc, 0xb9, 0xc1, 0x85, 0xc4, 0x54, 0x37,
-	0xc4, 0x4f, 0x7a, 0x98, 0xaa, 0xd8, 0xf5, 0x4d, 0x45, 0xd3, 0xa5, 0xf5, 0x00, 0xfd, 0x06, 0x46,
-	0x57, 0x8f, 0xee, 0x5d, 0x31, 0xec, 0xa8, 0xa7, 0x64, 0x28, 0x62, 0x6f, 0xea, 0xcf, 0x46, 0x89,
-	0x73, 0xe9, 0x7b, 0x00, 0x95, 0x66, 0x1f, 0xf6, 0x1d, 0xec, 0x70, 0x14, 0x75, 0x2e, 0x4d, 0x5e,
-	0x34, 0x7f, 0x6e, 0x9b, 0xd7, 0x7d, 0x7e, 0xe2, 0x72, 0xe8, 0xf7, 0xb0, 0x7f, 0x62, 0x07, 0x5b,
-	0x34, 0x35, 0x0e, 0x61, 0xe4, 0xa6, 0xdd, 0xdd, 0xd6, 0x02, 0x74, 0x06, 0xe3, 0x0b, 0xc6, 0x05,
-	0x76, 0x98, 0x89, 0x65, 0x7e, 0x85, 0x8f, 0x4e, 0x86, 0xce, 0xa5, 0x17, 0x10, 0x9c, 0x97, 0xa9,
-	0x9e, 0x11, 0xd9, 0x86, 0xb5, 0xdd, 0xcc, 0xc8, 0xa0, 0x9d, 0x11, 0x32, 0x85, 0xe8, 0x26, 0x2b,
-	0x6e, 0x91, 0x57, 0x3c, 0x2b, 0xa4, 0x1d, 0xa9, 0x2e, 0x44, 0xe7, 0xb0, 0x6b, 0xef, 0xb6, 0x54,
-	0x5f, 0x42, 0x58, 0x94, 0x29, 0xba, 0xc7, 0xba, 0x49, 0x51, 0xd7, 0x26, 0x26, 0x42, 0xa7, 0x30,
-	0xfe, 0x58, 0xa7, 0xed, 0xe8, 0xed, 0x81, 0x2f, 0x96, 0xb9, 0x9b, 0x6c, 0xb1, 0xcc, 0xe9, 0x07,
-	0x88, 0x6c, 0x86, 0x6a, 0x8a, 0x7a, 0xd0, 0x1d, 0x0a, 0xc1, 0x6e, 0x9d, 0x40, 0x9d, 0xdb, 0xaa,
-	0x6e, 0xd0, 0x51, 0x1d, 0xfd, 0x00, 0xbb, 0xee, 0xb8, 0x21, 0xf5, 0x66, 0xf5, 0x1f, 0x10, 0x4b,
-	0xab, 0x73, 0x4b, 0xfb, 0x0b, 0x5e, 0xc3, 0x97, 0x3f, 0x61, 0x91, 0x94, 0x79, 0x7e, 0xcd, 0x16,
-	0x9f, 0x2f, 0x7f, 0xf9, 0x79, 0x3b, 0xd1, 0x63, 0x78, 0xb1, 0x9a, 0x6a, 0xaf, 0x5c, 0xcb, 0x25,
-	0x2f, 0x60, 0xc8, 0x91, 0x89, 0xb2, 0xb0, 0x64, 0xad, 0x47, 0xff, 0xf0, 0x60, 0xf7, 0x13, 0x4a,
-	0xd6, 0xfe, 0xee, 0x4d, 0xdb, 0xa8, 0x51, 0xe0, 0x60, 0x9b, 0x02, 0x37, 0xee, 0x41, 0xff, 0xff,
-	0xec, 0xc1, 0xf9, 0x3f, 0x3e, 0x0c, 0x4f, 0xf4, 0x56, 0x27, 0xdf, 0x42, 0xa8, 0xc9, 0x10, 0x77,
-	0x46, 0x8b, 0x7a, 0x72, 0x60, 0xbd, 0x3e, 0xd1, 0x19, 0x04, 0x6a, 0x87, 0x10, 0xd7, 0xce, 0xce,
-	0x42, 0x99, 0xf4, 0xce, 0x93, 0x57, 0x10, 0xfe, 0x98, 0x97, 0x02, 0x57, 0xca, 0xf6, 0x93, 0x28,
-	0x04, 0x17, 0x59, 0x71, 0xfb, 0x9f, 0x39, 0x6f, 0x21, 0x50, 0xc2, 0x69, 0xae, 0xec, 0xac, 0x9a,
-	0xc9, 0x26, 0x65, 0x91, 0xd7, 0x30, 0xb8, 0x7a, 0x24, 0x7b, 0x36, 0xd4, 0xe8, 0x77, 0xb2, 0xdf,
-	0x41, 0x6c, 0xea, 0x3b, 0x18, 0x35, 0xda, 0x5b, 0x21, 0x11, 0xbb, 0x8d, 0xb8, 0xa6, 0xcd, 0xb9,
-	0x5e, 0xd0, 0x02, 0xc9, 0xf3, 0xb6, 0xc9, 0x8d, 0x16, 0x9b, 0xbe, 0xf5, 0x45, 0x32, 0x87, 0x50,
-	0x4f, 0x5e, 0x73, 0xa6, 0xab, 0x87, 0xe6, 0x4c, 0x7f, 0x86, 0x3f, 0xc1, 0x17, 0xfd, 0x51, 0x23,
-	0x87, 0x36, 0x6f, 0xe3, 0xb0, 0x4e, 0xbe, 0xda, 0x12, 0x35, 0xe5, 0x8e, 0xe1, 0xb7, 0x67, 0x47,
-	0x6f, 0xdf, 0xeb, 0x94, 0xeb, 0xa1, 0xfe, 0xbc, 0xfb, 0x37, 0x00, 0x00, 0xff, 0xff, 0x12, 0x6c,
-	0xb3, 0x02, 0xda, 0x07, 0x00, 0x00,
+	// 1009 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x55, 0xdb, 0x6e, 0xdb, 0x46,
+	0x13, 0x86, 0x44, 0x52, 0x96, 0x46, 0xf2, 0xff, 0xdb, 0x1b, 0x37, 0x60, 0x05, 0xb7, 0x50, 0x36,
+	0x2d, 0xaa, 0x20, 0xa9, 0x83, 0x2a, 0x40, 0x51, 0x20, 0xe8, 0x85, 0x5d, 0x19, 0x85, 0x81, 0x46,
+	0x70, 0x69, 0x03, 0x05, 0x7a, 0xb7, 0x16, 0x57, 0x0a, 0x11, 0x8a, 0x4b, 0xed, 0x2e, 0x1d, 0xf9,
+	0x2d, 0xfa, 0x2c, 0xcd, 0x4d, 0xaf, 0xfb, 0x64, 0xc5, 0x9e, 0x78, 0x90, 0xe4, 0xa2, 0x57, 0x9c,
+	0x13, 0x67, 0xbf, 0x99, 0x9d, 0x6f, 0x16, 0x06, 0x31, 0x4f, 0xee, 0x29, 0x3f, 0xcb, 0x39, 0x93,
+	0x0c, 0x05, 0xfa, 0x83, 0x3f, 0xb5, 0xc0, 0x9b, 0xde, 0xcc, 0x10, 0x02, 0xff, 0x3d, 0x13, 0x32,
+	0x6c, 0x8d, 0x5a, 0xe3, 0x5e, 0xa4, 0x65, 0x65, 0xcb, 0x19, 0x97, 0x61, 0xdb, 0xd8, 0x94, 0xac,
+	0x6c, 0x85, 0xa0, 0x3c, 0xf4, 0x8c, 0x4d, 0xc9, 0x68, 0x08, 0xdd, 0x9c, 0x08, 0xf1, 0x91, 0xf1,
+	0x38, 0xf4, 0xb5, 0xbd, 0xd4, 0x95, 0x2f, 0x26, 0x92, 0xdc, 0x11, 0x41, 0xc3, 0xc0, 0xf8, 0x9c,
+	0x8e, 0x7e, 0x80, 0x23, 0x12, 0xc7, 0x89, 0x4c, 0x58, 0x46, 0xd2, 0x6b, 0xc2, 0xc9, 0x4a, 0x84,
+	0x9d, 0x91, 0x37, 0xee, 0x4f, 0x06, 0x06, 0xe4, 0x99, 0x36, 0x46, 0x3b, 0x51, 0xf8, 0xaf, 0x16,
+	0xf8, 0x51, 0x91, 0x52, 0x05, 0x27, 0x23, 0x2b, 0xea, 0x60, 0x2b, 0x59, 0xd9, 0x62, 0x2a, 0xe6,
+	0x0e, 0xb6, 0x92, 0x51, 0x08, 0xc1, 0x3d, 0x49, 0x0b, 0x6a, 0x70, 0x5f, 0xb4, 0xc3, 0x56, 0x64,
+	0x0c, 0xe8, 0x04, 0x82, 0x94, 0xde, 0xd3, 0xd4, 0x22, 0x37, 0x8a, 0x82, 0x3d, 0x27, 0x92, 0x2e,
+	0x19, 0x7f, 0x70, 0xb0, 0x9d, 0x8e, 0xbe, 0x82, 0x4e, 0xfe, 0x38, 0x58, 0xeb, 0x43, 0x4f, 0xa1,
+	0x33, 0x2f, 0x84, 0x64, 0xab, 0xf0, 0x40, 0xff, 0x6f, 0x35, 0xfc, 0x1b, 0x04, 0x3a, 0x10, 0x1d,
+	0x81, 0xf7, 0x81, 0x3e, 0x58, 0xe4, 0x4a, 0x54, 0x50, 0x0c, 0x48, 0x83, 0xdc, 0x02, 0x74, 0xe5,
+	0x78, 0xb5, 0x72, 0x10, 0xf8, 0xf2, 0x21, 0xa7, 0x16, 0xb3, 0x96, 0xf1, 0x0c, 0xfa, 0x57, 0x59,
+	0x22, 0x23, 0xba, 0x2e, 0xa8, 0x90, 0xe8, 0x14, 0xbc, 0x58, 0x64, 0x3a, 0x7d, 0x7f, 0x02, 0x16,
+	0xe2, 0xf4, 0x66, 0x16, 0x29, 0x33, 0x7a, 0x06, 0x01, 0x2f, 0x52, 0x2a, 0x42, 0x4f, 0x97, 0xd0,
+	0xb7, 0x7e, 0xd5, 0xd3, 0xc8, 0x78, 0xf0, 0x01, 0x04, 0x97, 0xab, 0x5c, 0x3e, 0xe0, 0xe7, 0xd0,
+	0xbf, 0xdc, 0xd0, 0xb9, 0x4b, 0x7c, 0x02, 0xc1, 0xba, 0xa0, 0xdc, 0x21, 0x37, 0x0a, 0xfe, 0xb3,
+	0x05, 0x03, 0x13, 0x25, 0x72, 0x96, 0x09, 0x8a, 0x30, 0x0c, 0x52, 0x22, 0xe4, 0x55, 0x26, 0x28,
+	0x97, 0x57, 0xb1, 0x8e, 0xf6, 0xa2, 0x86, 0x0d, 0xbd, 0x82, 0xe3, 0xba, 0x7e, 0xc9, 0x39, 0xe3,
+	0xb6, 0xf8, 0x5d, 0x87, 0xca, 0xc8, 0xd9, 0x47, 0x71, 0xbe, 0x58, 0xd0, 0xb9, 0xa4, 0xb1, 0x6e,
+	0x88, 0x17, 0x35, 0x6c, 0x2a, 0x63, 0x5d, 0x37, 0x19, 0x4d, 0x97, 0x76, 0x1d, 0xf8, 0x6b, 0xe8,
+	0xdd, 0x6e, 0x5c, 0x5d, 0x21, 0x1c, 0xa8, 0x52, 0x12, 0x2a, 0xc2, 0xd6, 0xc8, 0x1b, 0xf7, 0x22,
+	0xa7, 0xe2, 0xb7, 0x00, 0xb7, 0x9b, 0xb2, 0xb0, 0x6f, 0xe1, 0x80, 0x53, 0x51, 0xa4, 0xd2, 0xc4,
+	0xf5, 0x27, 0x4f, 0x6c, 0xf3, 0xea, 0xe5, 0x47, 0x2e, 0x06, 0x7f, 0x07, 0xc7, 0x53, 0x3b, 0xf0,
+	0xa2, 0xcc, 0x71, 0x0a, 0x3d, 0xc7, 0x02, 0x77, 0x5a, 0x65, 0xc0, 0x63, 0x18, 0x5c, 0x13, 0x2e,
+	0x68, 0x0d, 0x99, 0x58, 0xa7, 0xb7, 0x74, 0xe3, 0xe8, 0xe9, 0x54, 0x7c, 0x0d, 0xfe, 0x8c, 0xc5,
+	0x7a, 0x46, 0x64, 0xe5, 0xd6, 0x72, 0x39, 0x23, 0xed, 0x6a, 0x46, 0xd0, 0x08, 0xfa, 0x8b, 0x24,
+	0x5b, 0x52, 0x9e, 0xf3, 0x24, 0x93, 0x76, 0xa4, 0xea, 0x26, 0x3c, 0x81, 0x43, 0x7b, 0xb6, 0x85,
+	0xfa, 0x0c, 0x82, 0x8c, 0xc5, 0xd4, 0x15, 0xeb, 0x26, 0x45, 0x1d, 0x1b, 0x19, 0x0f, 0x1e, 0xc1,
+	0xe0, 0xbc, 0x88, 0xab, 0xd1, 0x3b, 0x02, 0x4f, 0xac, 0x53, 0x37, 0xd9, 0x62, 0x9d, 0xe2, 0xbf,
+	0x5b, 0xd0, 0xb7, 0x21, 0xaa, 0x2b, 0xaa, 0xa2, 0x15, 0x15, 0x82, 0x2c, 0x1d, 0x73, 0x9d, 0x5a,
+	0xd1, 0xb1, 0xbd, 0x45, 0x47, 0x35, 0x94, 0x33, 0x45, 0x75, 0x03, 0xba, 0xd4, 0x1b, 0x54, 0xf5,
+	0xb7, 0xa8, 0xfa, 0x12, 0xba, 0x39, 0x13, 0x7a, 0x77, 0x68, 0x1a, 0xf7, 0x27, 0xff, 0x77, 0x64,
+	0xb5, 0xe6, 0xa8, 0x0c, 0x40, 0x5f, 0x02, 0x88, 0x62, 0xb9, 0xa4, 0x42, 0x87, 0x77, 0x74, 0xaa,
+	0x9a, 0x05, 0xff, 0x08, 0x87, 0xae, 0x06, 0xd3, 0x9a, 0x57, 0xdb, 0x93, 0x80, 0x6c, 0xf2, 0x5a,
+	0xa9, 0xd5, 0x20, 0xbc, 0x80, 0xcf, 0x7e, 0xa6, 0x59, 0xc4, 0xd2, 0xf4, 0x8e, 0xcc, 0x3f, 0xdc,
+	0xfc, 0xfa, 0xcb, 0xe3, 0xed, 0xba, 0x80, 0xa7, 0xdb, 0xa1, 0xf6, 0xc8, 0x9d, 0x58, 0xb5, 0x67,
+	0x38, 0x25, 0x82, 0x65, 0xb6, 0x63, 0x56, 0xc3, 0x7f, 0xb4, 0xe1, 0xf0, 0x1d, 0x95, 0xa4, 0x1a,
+	0xba, 0x7d, 0xbb, 0xb2, 0xdc, 0x03, 0xed, 0xc7, 0xf6, 0xc0, 0xde, 0x2d, 0xed, 0xfd, 0x97, 0x2d,
+	0xad, 0xc8, 0x28, 0x8a, 0x5c, 0x3d, 0x1b, 0xba, 0x21, 0x17, 0x44, 0xce, 0xdf, 0xeb, 0x2b, 0xea,
+	0x46, 0xbb, 0x0e, 0x45, 0xef, 0x39, 0xc9, 0xc9, 0x5d, 0x92, 0x26, 0x52, 0x91, 0x30, 0xd0, 0xb4,
+	0x68, 0xd8, 0xd4, 0xdc, 0xdc, 0x53, 0x2e, 0xaa, 0xfb, 0x71, 0xaa, 0xfa, 0xdb, 0xa6, 0x8c, 0xa8,
+	0xa0, 0x52, 0x2f, 0xdd, 0x6e, 0xd4, 0xb0, 0xe1, 0xef, 0xa1, 0xeb, 0xae, 0x5d, 0xb5, 0x8d, 0x2d,
+	0x16, 0x2a, 0xd2, 0x2c, 0x26, 0xab, 0xa9, 0x26, 0xa5, 0x49, 0x66, 0x58, 0xe3, 0x45, 0x5a, 0xc6,
+	0xdf, 0xc0, 0x71, 0x85, 0xd3, 0xdd, 0x1a, 0x02, 0x5f, 0xac, 0x53, 0xc7, 0x5e, 0x2d, 0xe3, 0x29,
+	0xa0, 0x7a, 0xa0, 0xed, 0xfb, 0xd9, 0xf6, 0x98, 0x9c, 0x6c, 0x8d, 0x49, 0x73, 0x63, 0x4c, 0x3e,
+	0xf9, 0xd0, 0x99, 0xea, 0xa7, 0x1a, 0xbd, 0x84, 0x40, 0xdf, 0x21, 0x72, 0xad, 0xd6, 0x1b, 0x79,
+	0xe8, 0x12, 0x34, 0xef, 0x77, 0x0c, 0xbe, 0x7a, 0x00, 0x90, 0x9b, 0xc2, 0xda, 0x6b, 0x30, 0x6c,
+	0xfc, 0x8f, 0x9e, 0x43, 0xf0, 0x53, 0xca, 0x04, 0xdd, 0x4a, 0xdb, 0x0c, 0xc2, 0xe0, 0x5f, 0x27,
+	0xd9, 0xf2, 0x5f, 0x63, 0x5e, 0x83, 0xaf, 0xb6, 0x5e, 0x79, 0x64, 0xed, 0x9d, 0x18, 0xee, 0x5b,
+	0x8b, 0xe8, 0x05, 0xb4, 0x6f, 0x37, 0xe8, 0xc8, 0xba, 0xca, 0xe5, 0x3b, 0x3c, 0xae, 0x59, 0x6c,
+	0xe8, 0x1b, 0xe8, 0x95, 0x8b, 0x73, 0x0b, 0x44, 0xe8, 0x9e, 0xb3, 0x9d, 0xc5, 0x3a, 0xd1, 0xaf,
+	0xab, 0xa0, 0xe8, 0x49, 0x35, 0x9b, 0xe5, 0x22, 0x1d, 0x9e, 0x34, 0x8d, 0xd5, 0x3f, 0xfa, 0x26,
+	0xca, 0x7f, 0xea, 0xcb, 0x6c, 0xb8, 0xf7, 0xb2, 0xd0, 0x3b, 0xf8, 0x5f, 0x93, 0xa1, 0xe8, 0xd4,
+	0xc6, 0xed, 0xe5, 0xf8, 0xf0, 0x8b, 0x47, 0xbc, 0x36, 0xdd, 0x39, 0x40, 0x8d, 0x09, 0x61, 0xfd,
+	0xc8, 0xfa, 0xd0, 0x0d, 0x3f, 0xdf, 0xe3, 0x31, 0x29, 0x2e, 0xe0, 0xf7, 0xee, 0xd9, 0xeb, 0xb7,
+	0xda, 0x7d, 0xd7, 0xd1, 0x9f, 0x37, 0xff, 0x0c, 0x00, 0x9c, 0x83, 0x0b, 0x5b, 0xf2, 0x09, 0x00,
+	0x00,
 }
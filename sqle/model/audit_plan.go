@@ -29,6 +29,11 @@ type AuditPlan struct {
 	WebHookURL          string `json:"web_hook_url"`
 	WebHookTemplate     string `json:"web_hook_template"`
 
+	// LastAuditedCommit is the commit of git repository which is audited
+	// last time, it is used by the audit plan of git repository to audit
+	// the changed SQLs only.
+	LastAuditedCommit string `json:"last_audited_commit"`
+
 	CreateUser    *User             `gorm:"foreignkey:CreateUserId"`
 	Instance      *Instance         `gorm:"foreignkey:InstanceName;association_foreignkey:Name"`
 	AuditPlanSQLs []*AuditPlanSQLV2 `gorm:"foreignkey:AuditPlanID"`
@@ -87,6 +92,12 @@ func (s *Storage) GetAuditPlanReportByID(id uint) (*AuditPlanReportV2, bool, err
 	return ap, true, errors.New(errors.ConnectStorageError, err)
 }
 
+func (s *Storage) GetAuditPlanReportSQLsByReportID(reportID uint) ([]*AuditPlanReportSQLV2, error) {
+	var sqls []*AuditPlanReportSQLV2
+	err := s.db.Model(AuditPlanReportSQLV2{}).Where("audit_plan_report_id = ?", reportID).Order("id").Find(&sqls).Error
+	return sqls, errors.New(errors.ConnectStorageError, err)
+}
+
 func (s *Storage) GetAuditPlanSQLs(name string) ([]*AuditPlanSQLV2, error) {
 	ap, exist, err := s.GetAuditPlanByName(name)
 	if err != nil {
@@ -132,6 +143,34 @@ func (s *Storage) UpdateDefaultAuditPlanSQLs(apName string, sqls []*AuditPlanSQL
 	return errors.New(errors.ConnectStorageError, s.db.Exec(raw, args...).Error)
 }
 
+// UpdateSlowLogAuditPlanSQLs is same as UpdateDefaultAuditPlanSQLs except
+// that the slow query metrics of the uploaded SQLs are merged, the averages
+// are weighted by counter and the max is the greater one.
+func (s *Storage) UpdateSlowLogAuditPlanSQLs(apName string, sqls []*AuditPlanSQLV2) error {
+	ap, _, err := s.GetAuditPlanByName(apName)
+	if err != nil {
+		return err
+	}
+
+	const (
+		oldCounter = "COALESCE(JSON_EXTRACT(info, '$.counter'), 0)"
+		newCounter = "COALESCE(JSON_EXTRACT(values(info), '$.counter'), 0)"
+	)
+	avg := func(key string) string {
+		return fmt.Sprintf("'$.%[1]s', (COALESCE(JSON_EXTRACT(info, '$.%[1]s'), 0)*%[2]s+"+
+			"COALESCE(JSON_EXTRACT(values(info), '$.%[1]s'), 0)*%[3]s)/NULLIF(%[2]s+%[3]s, 0)", key, oldCounter, newCounter)
+	}
+	raw, args := getBatchInsertRawSQL(ap, sqls)
+	raw += fmt.Sprintf(`ON DUPLICATE KEY UPDATE sql_content = VALUES(sql_content), info = JSON_SET(COALESCE(info, '{}'), 
+'$.counter', %[1]s+%[2]s,
+'$.last_receive_timestamp', JSON_EXTRACT(values(info), '$.last_receive_timestamp'),
+%[3]s,
+'$.query_time_max', GREATEST(COALESCE(JSON_EXTRACT(info, '$.query_time_max'), 0), COALESCE(JSON_EXTRACT(values(info), '$.query_time_max'), 0)),
+%[4]s,
+%[5]s);`, oldCounter, newCounter, avg("query_time_avg"), avg("lock_time_avg"), avg("rows_examined_avg"))
+	return errors.New(errors.ConnectStorageError, s.db.Exec(raw, args...).Error)
+}
+
 func getBatchInsertRawSQL(ap *AuditPlan, sqls []*AuditPlanSQLV2) (raw string, args []interface{}) {
 	pattern := make([]string, 0, len(sqls))
 	for _, sql := range sqls {
@@ -1,5 +1,7 @@
 package model
 
+import "encoding/json"
+
 type AuditPlanReportV2 struct {
 	Model
 	AuditPlanID uint `json:"audit_plan_id" gorm:"index"`
@@ -20,6 +22,11 @@ type AuditPlanReportSQLV2 struct {
 	AuditPlanReportID uint   `json:"audit_plan_report_id" gorm:"index"`
 	SQL               string `json:"sql" gorm:"type:text;not null"`
 	AuditResult       string `json:"audit_result" gorm:"type:text"`
+	// AuditResults is the structured findings of AuditResult, it is the JSON
+	// of []*AuditPlanReportSQLResult.
+	AuditResults JSON   `json:"audit_results" gorm:"type:json"`
+	SourceFile   string `json:"source_file"`
+	SourceLine   int    `json:"source_line"`
 
 	AuditPlanReport *AuditPlanReportV2 `gorm:"foreignkey:AuditPlanReportID"`
 }
@@ -27,3 +34,36 @@ type AuditPlanReportSQLV2 struct {
 func (a AuditPlanReportSQLV2) TableName() string {
 	return "audit_plan_report_sqls_v2"
 }
+
+// AuditPlanReportSQLResult is a single finding of AuditPlanReportSQLV2, the
+// fields are the same as ExecuteSQLAuditResult.
+type AuditPlanReportSQLResult struct {
+	Level        string `json:"level"`
+	Message      string `json:"message"`
+	RuleName     string `json:"rule_name"`
+	Category     string `json:"category"`
+	PositionLine int    `json:"position_line,omitempty"`
+	Suggestion   string `json:"suggestion,omitempty"`
+	ExemptionId  uint   `json:"exemption_id,omitempty"`
+}
+
+// SetAuditResults stores the structured findings in AuditResults.
+func (a *AuditPlanReportSQLV2) SetAuditResults(results []*AuditPlanReportSQLResult) error {
+	data, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	a.AuditResults = JSON(data)
+	return nil
+}
+
+// GetAuditResults returns the structured findings in AuditResults, it is
+// empty for the reports which are created before AuditResults is added.
+func (a *AuditPlanReportSQLV2) GetAuditResults() ([]*AuditPlanReportSQLResult, error) {
+	results := []*AuditPlanReportSQLResult{}
+	if len(a.AuditResults) == 0 || string(a.AuditResults) == "null" {
+		return results, nil
+	}
+	err := json.Unmarshal(a.AuditResults, &results)
+	return results, err
+}
@@ -134,3 +134,40 @@ func TestStorage_OverrideAuditPlanSQLs(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 }
+
+func TestStorage_UpdateSlowLogAuditPlanSQLs(t *testing.T) {
+	mockDB, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	InitMockStorage(mockDB)
+
+	ap := &AuditPlan{
+		Model: Model{
+			ID: 1,
+		},
+		Name: "test_ap_name",
+	}
+
+	sqls := []*AuditPlanSQLV2{
+		{
+			Fingerprint: "select * from t1 where id = ?",
+			SQLContent:  "select * from t1 where id = 1",
+			Info:        []byte(`{"counter": 1, "last_receive_timestamp": "mock time", "query_time_avg": 1.5}`),
+		},
+	}
+
+	mock.ExpectQuery("SELECT \\* FROM `audit_plans`").
+		WithArgs(ap.Name).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(ap.ID, ap.Name))
+	// the averages are weighted by counter.
+	mock.ExpectExec("INSERT INTO `audit_plan_sqls_v2` .*ON DUPLICATE KEY UPDATE .*"+
+		"'\\$.query_time_avg', \\(COALESCE\\(JSON_EXTRACT\\(info, '\\$.query_time_avg'\\), 0\\)\\*COALESCE\\(JSON_EXTRACT\\(info, '\\$.counter'\\), 0\\).*"+
+		"'\\$.query_time_max', GREATEST\\(").
+		WithArgs(ap.ID, sqls[0].GetFingerprintMD5(), sqls[0].Fingerprint, sqls[0].SQLContent, sqls[0].Info).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = GetStorage().UpdateSlowLogAuditPlanSQLs(ap.Name, sqls)
+	assert.NoError(t, err)
+
+	err = mock.ExpectationsWereMet()
+	assert.NoError(t, err)
+}
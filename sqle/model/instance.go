@@ -105,6 +105,14 @@ func (s *Storage) GetInstanceDetailByName(name string) (*Instance, bool, error)
 	return instance, true, errors.New(errors.ConnectStorageError, err)
 }
 
+// GetInstancesDetail returns all instances with the rule templates and
+// workflow template.
+func (s *Storage) GetInstancesDetail() ([]*Instance, error) {
+	instances := []*Instance{}
+	err := s.db.Preload("WorkflowTemplate").Preload("RuleTemplates").Order("name ASC").Find(&instances).Error
+	return instances, errors.New(errors.ConnectStorageError, err)
+}
+
 func (s *Storage) GetInstancesByNames(names []string) ([]*Instance, error) {
 	instances := []*Instance{}
 	err := s.db.Where("name in (?)", names).Find(&instances).Error
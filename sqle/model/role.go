@@ -36,6 +36,13 @@ func (s *Storage) GetRolesByNames(names []string) ([]*Role, error) {
 	return roles, errors.New(errors.ConnectStorageError, err)
 }
 
+// GetRolesDetail returns all roles with the users and instances.
+func (s *Storage) GetRolesDetail() ([]*Role, error) {
+	roles := []*Role{}
+	err := s.db.Preload("Users").Preload("Instances").Order("name ASC").Find(&roles).Error
+	return roles, errors.New(errors.ConnectStorageError, err)
+}
+
 func (s *Storage) UpdateRoleUsers(role *Role, users ...*User) error {
 	err := s.db.Model(role).Association("Users").Replace(users).Error
 	return errors.New(errors.ConnectStorageError, err)
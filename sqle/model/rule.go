@@ -27,6 +27,7 @@ func GenerateRuleByDriverRule(dr *driver.Rule, dbType string) *Rule {
 		Typ:    dr.Category,
 		DBType: dbType,
 		Params: dr.Params,
+		Custom: dr.Custom,
 	}
 }
 
@@ -37,6 +38,7 @@ func ConvertRuleToDriverRule(r *Rule) *driver.Rule {
 		Category: r.Typ,
 		Level:    driver.RuleLevel(r.Level),
 		Params:   r.Params,
+		Custom:   r.Custom,
 	}
 }
 
@@ -47,6 +49,9 @@ type Rule struct {
 	Level  string        `json:"level" example:"error"` // notice, warn, error
 	Typ    string        `json:"type" gorm:"column:type; not null"`
 	Params params.Params `json:"params" gorm:"type:varchar(1000)"`
+	// Custom is the definition of rule which is defined by user, it is nil
+	// for the rules of driver.
+	Custom *driver.CustomRule `json:"custom,omitempty" gorm:"type:text"`
 }
 
 func (r Rule) TableName() string {
@@ -143,6 +148,16 @@ func (s *Storage) GetRuleTemplateDetailByName(name string) (*RuleTemplate, bool,
 	return t, true, errors.New(errors.ConnectStorageError, err)
 }
 
+// GetRuleTemplatesDetail returns all rule templates with rules.
+func (s *Storage) GetRuleTemplatesDetail() ([]*RuleTemplate, error) {
+	dbOrder := func(db *gorm.DB) *gorm.DB {
+		return db.Order("rule_template_rule.rule_name ASC")
+	}
+	templates := []*RuleTemplate{}
+	err := s.db.Preload("RuleList", dbOrder).Order("name ASC").Find(&templates).Error
+	return templates, errors.New(errors.ConnectStorageError, err)
+}
+
 func (s *Storage) UpdateRuleTemplateRules(tpl *RuleTemplate, rules ...RuleTemplateRule) error {
 	if err := s.db.Where(&RuleTemplateRule{RuleTemplateId: tpl.ID}).Delete(&RuleTemplateRule{}).Error; err != nil {
 		return errors.New(errors.ConnectStorageError, err)
@@ -257,3 +272,38 @@ func (s *Storage) GetAndCheckRuleExist(ruleNames []string, dbType string) (map[s
 	}
 	return existRules, nil
 }
+
+// GetCustomRules returns the rules defined by user, all db types are returned
+// if dbType is empty.
+func (s *Storage) GetCustomRules(dbType string) ([]*Rule, error) {
+	rules := []*Rule{}
+	db := s.db.Where("custom IS NOT NULL")
+	if dbType != "" {
+		db = db.Where("db_type = ?", dbType)
+	}
+	err := db.Order("name ASC").Find(&rules).Error
+	return rules, errors.New(errors.ConnectStorageError, err)
+}
+
+func (s *Storage) GetCustomRuleByName(name string) (*Rule, bool, error) {
+	rule := &Rule{}
+	err := s.db.Where("name = ?", name).Where("custom IS NOT NULL").First(rule).Error
+	if err == gorm.ErrRecordNotFound {
+		return rule, false, nil
+	}
+	return rule, true, errors.New(errors.ConnectStorageError, err)
+}
+
+// DeleteCustomRule deletes the rule defined by user, and removes it from the
+// rule templates of the same db type.
+func (s *Storage) DeleteCustomRule(rule *Rule) error {
+	return s.Tx(func(txDB *gorm.DB) error {
+		err := txDB.Exec(`DELETE rule_template_rule FROM rule_template_rule
+JOIN rule_templates ON rule_template_rule.rule_template_id = rule_templates.id
+WHERE rule_template_rule.rule_name = ? AND rule_templates.db_type = ?`, rule.Name, rule.DBType).Error
+		if err != nil {
+			return err
+		}
+		return txDB.Where("name = ? AND db_type = ?", rule.Name, rule.DBType).Delete(&Rule{}).Error
+	})
+}
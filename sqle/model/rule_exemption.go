@@ -0,0 +1,113 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/actiontech/sqle/sqle/errors"
+
+	"github.com/jinzhu/gorm"
+)
+
+// RuleExemption exempts the findings of rules on the SQLs in scope until it
+// is expired. The scope is the non-empty ones of InstanceName, Schema, Table
+// and FingerprintSQL, the SQL is in scope if all of them are matched. Unlike
+// SqlWhitelist, the SQL is still audited and the exempted findings are kept
+// in audit results, but they do not affect the audit level of SQL.
+type RuleExemption struct {
+	Model
+	// RuleNames are the names of exempted rules joined by comma.
+	RuleNames    string `json:"rule_names" gorm:"type:text;not null"`
+	InstanceName string `json:"instance_name"`
+	Schema       string `json:"schema_name" gorm:"column:schema_name"`
+	// Table is matched if the SQL references the table.
+	Table string `json:"table_name" gorm:"column:table_name"`
+	// FingerprintSQL is matched if the SQL has the same fingerprint with it.
+	FingerprintSQL string    `json:"fingerprint_sql" gorm:"type:text"`
+	ExpiredAt      time.Time `json:"expired_at"`
+	Justification  string    `json:"justification" gorm:"type:text;not null"`
+	ApproverId     uint      `json:"approver_id"`
+
+	Approver *User `json:"-" gorm:"foreignkey:ApproverId"`
+}
+
+func (e RuleExemption) TableName() string {
+	return "rule_exemptions"
+}
+
+func (e *RuleExemption) GetRuleNames() []string {
+	if e.RuleNames == "" {
+		return []string{}
+	}
+	return strings.Split(e.RuleNames, ",")
+}
+
+func (e *RuleExemption) SetRuleNames(names []string) {
+	e.RuleNames = strings.Join(names, ",")
+}
+
+// HasRule returns whether the rule is exempted.
+func (e *RuleExemption) HasRule(name string) bool {
+	for _, n := range e.GetRuleNames() {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *RuleExemption) ApproverName() string {
+	if e.Approver != nil {
+		return e.Approver.Name
+	}
+	return ""
+}
+
+// GetUnexpiredRuleExemptions returns the exemptions which are used by audit.
+func (s *Storage) GetUnexpiredRuleExemptions() ([]*RuleExemption, error) {
+	exemptions := []*RuleExemption{}
+	err := s.db.Where("expired_at > ?", time.Now()).Order("id").Find(&exemptions).Error
+	return exemptions, errors.New(errors.ConnectStorageError, err)
+}
+
+func (s *Storage) GetRuleExemptionById(id string) (*RuleExemption, bool, error) {
+	exemption := &RuleExemption{}
+	err := s.db.Preload("Approver").Where("id = ?", id).First(exemption).Error
+	if err == gorm.ErrRecordNotFound {
+		return exemption, false, nil
+	}
+	return exemption, true, errors.New(errors.ConnectStorageError, err)
+}
+
+// GetNotExistRuleNames returns the names which are not the rules of any db
+// type, since the exemption is not bound to db type.
+func (s *Storage) GetNotExistRuleNames(names []string) ([]string, error) {
+	existNames := []string{}
+	err := s.db.Model(&Rule{}).Where("name in (?)", names).Pluck("DISTINCT name", &existNames).Error
+	if err != nil {
+		return nil, errors.New(errors.ConnectStorageError, err)
+	}
+	exist := make(map[string]struct{}, len(existNames))
+	for _, name := range existNames {
+		exist[name] = struct{}{}
+	}
+	notExistNames := []string{}
+	for _, name := range names {
+		if _, ok := exist[name]; !ok {
+			notExistNames = append(notExistNames, name)
+		}
+	}
+	return notExistNames, nil
+}
+
+func (s *Storage) GetRuleExemptions(pageIndex, pageSize uint32) ([]*RuleExemption, uint32, error) {
+	var count uint32
+	exemptions := []*RuleExemption{}
+	err := s.db.Model(&RuleExemption{}).Count(&count).Error
+	if err != nil {
+		return exemptions, 0, errors.New(errors.ConnectStorageError, err)
+	}
+	err = s.db.Preload("Approver").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Order("id desc").
+		Find(&exemptions).Error
+	return exemptions, count, errors.New(errors.ConnectStorageError, err)
+}
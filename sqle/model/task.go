@@ -42,6 +42,12 @@ type Task struct {
 	CreateUserId uint
 	ExecStartAt  *time.Time
 	ExecEndAt    *time.Time
+	DryRunAt     *time.Time
+
+	// ExecFailureStrategy is copied from the workflow template when the task
+	// is executed, ExecFailureResult records how the failure is handled.
+	ExecFailureStrategy string `json:"exec_failure_strategy" gorm:"default:\"stop\""`
+	ExecFailureResult   string `json:"exec_failure_result" gorm:"type:text"`
 
 	CreateUser   *User          `gorm:"foreignkey:CreateUserId"`
 	Instance     *Instance      `json:"-" gorm:"foreignkey:InstanceId"`
@@ -67,6 +73,9 @@ const (
 	SQLExecuteStatusDoing       = "doing"
 	SQLExecuteStatusFailed      = "failed"
 	SQLExecuteStatusSucceeded   = "succeeded"
+	// SQLExecuteStatusSkipped is the status of SQLs which are not executed
+	// since the execution is canceled or stopped at the failed SQL.
+	SQLExecuteStatusSkipped = "skipped"
 )
 
 type BaseSQL struct {
@@ -99,11 +108,20 @@ func (s *BaseSQL) GetExecStatusDesc() string {
 		return "执行失败"
 	case SQLExecuteStatusSucceeded:
 		return "执行成功"
+	case SQLExecuteStatusSkipped:
+		return "已跳过"
 	default:
 		return "未知"
 	}
 }
 
+const (
+	SQLDryRunStatusInitialized = "initialized"
+	SQLDryRunStatusSucceeded   = "succeeded"
+	SQLDryRunStatusFailed      = "failed"
+	SQLDryRunStatusSkipped     = "skipped"
+)
+
 type ExecuteSQL struct {
 	BaseSQL
 	AuditStatus string `json:"audit_status" gorm:"default:\"initialized\""`
@@ -113,6 +131,21 @@ type ExecuteSQL struct {
 	AuditFingerprint string `json:"audit_fingerprint" gorm:"index;type:char(32)"`
 	// AuditLevel has four level: error, warn, notice, normal.
 	AuditLevel string `json:"audit_level"`
+	// SourceFile and SourceLine locate the SQL in the file which the SQL is
+	// read from, such as the uploaded SQL file or MyBatis XML file, they are
+	// empty if the SQL is not read from file.
+	SourceFile string `json:"source_file"`
+	SourceLine int    `json:"source_line"`
+
+	// AuditResults is the structured findings of AuditResult, they are stored
+	// in table "execute_sql_audit_results" by UpdateExecuteSQLAuditResults.
+	AuditResults []*ExecuteSQLAuditResult `json:"-" gorm:"-"`
+
+	// DryRunStatus, DryRunResult and DryRunRowAffects are the result of the
+	// last dry run, the changes of dry run are rolled back.
+	DryRunStatus     string `json:"dry_run_status" gorm:"default:\"initialized\""`
+	DryRunResult     string `json:"dry_run_result" gorm:"type:text"`
+	DryRunRowAffects int64  `json:"dry_run_row_affects"`
 }
 
 func (s ExecuteSQL) TableName() string {
@@ -139,6 +172,28 @@ func (s *ExecuteSQL) GetAuditResultDesc() string {
 	return s.AuditResult
 }
 
+// ExecuteSQLAuditResult is a single finding produced by auditing ExecuteSQL.
+type ExecuteSQLAuditResult struct {
+	Model
+	TaskId       uint   `json:"-" gorm:"index"`
+	ExecuteSQLId uint   `json:"-" gorm:"index;column:execute_sql_id"`
+	Level        string `json:"level"`
+	Message      string `json:"message" gorm:"type:text"`
+	RuleName     string `json:"rule_name"`
+	Category     string `json:"category"`
+	// PositionLine is 0 when driver can not locate the finding in SQL.
+	PositionOffset int    `json:"position_offset"`
+	PositionLine   int    `json:"position_line"`
+	Suggestion     string `json:"suggestion" gorm:"type:text"`
+	// ExemptionId is the id of RuleExemption which exempts the finding, it is
+	// 0 if the finding is not exempted.
+	ExemptionId uint `json:"exemption_id"`
+}
+
+func (r ExecuteSQLAuditResult) TableName() string {
+	return "execute_sql_audit_results"
+}
+
 type RollbackSQL struct {
 	BaseSQL
 	ExecuteSQLId uint `gorm:"index;column:execute_sql_id"`
@@ -251,6 +306,33 @@ func (s *Storage) UpdateExecuteSQLs(ExecuteSQLs []*ExecuteSQL) error {
 	return errors.New(errors.ConnectStorageError, tx.Commit().Error)
 }
 
+// UpdateExecuteSQLAuditResults replaces the audit results of ExecuteSQLs with ExecuteSQL.AuditResults.
+func (s *Storage) UpdateExecuteSQLAuditResults(executeSQLs []*ExecuteSQL) error {
+	tx := s.db.Begin()
+	for _, executeSQL := range executeSQLs {
+		err := tx.Unscoped().Where("execute_sql_id = ?", executeSQL.ID).Delete(&ExecuteSQLAuditResult{}).Error
+		if err != nil {
+			tx.Rollback()
+			return errors.New(errors.ConnectStorageError, err)
+		}
+		for _, result := range executeSQL.AuditResults {
+			result.TaskId = executeSQL.TaskId
+			result.ExecuteSQLId = executeSQL.ID
+			if err := tx.Save(result).Error; err != nil {
+				tx.Rollback()
+				return errors.New(errors.ConnectStorageError, err)
+			}
+		}
+	}
+	return errors.New(errors.ConnectStorageError, tx.Commit().Error)
+}
+
+func (s *Storage) GetExecuteSQLAuditResultsByTaskId(taskId string) ([]*ExecuteSQLAuditResult, error) {
+	results := []*ExecuteSQLAuditResult{}
+	err := s.db.Where("task_id = ?", taskId).Order("id").Find(&results).Error
+	return results, errors.New(errors.ConnectStorageError, err)
+}
+
 func (s *Storage) UpdateRollbackSQLs(rollbackSQLs []*RollbackSQL) error {
 	tx := s.db.Begin()
 	for _, rollbackSQL := range rollbackSQLs {
@@ -335,6 +417,7 @@ func (s *Storage) GetTaskByInstanceId(instanceId uint) ([]Task, error) {
 }
 
 type TaskSQLDetail struct {
+	Id          uint           `json:"id"`
 	Number      uint           `json:"number"`
 	Description string         `json:"description"`
 	ExecSQL     string         `json:"exec_sql"`
@@ -344,10 +427,17 @@ type TaskSQLDetail struct {
 	ExecResult  string         `json:"exec_result"`
 	ExecStatus  string         `json:"exec_status"`
 	RollbackSQL sql.NullString `json:"rollback_sql"`
+	SourceFile  string         `json:"source_file"`
+	SourceLine  int            `json:"source_line"`
+
+	DryRunStatus     string         `json:"dry_run_status"`
+	DryRunResult     sql.NullString `json:"dry_run_result"`
+	DryRunRowAffects int64          `json:"dry_run_row_affects"`
 }
 
-var taskSQLsQueryTpl = `SELECT e_sql.number, e_sql.description, e_sql.content AS exec_sql, r_sql.content AS rollback_sql,
-e_sql.audit_result, e_sql.audit_level, e_sql.audit_status, e_sql.exec_result, e_sql.exec_status
+var taskSQLsQueryTpl = `SELECT e_sql.id, e_sql.number, e_sql.description, e_sql.content AS exec_sql, r_sql.content AS rollback_sql,
+e_sql.audit_result, e_sql.audit_level, e_sql.audit_status, e_sql.exec_result, e_sql.exec_status,
+e_sql.dry_run_status, e_sql.dry_run_result, e_sql.dry_run_row_affects, e_sql.source_file, e_sql.source_line
 
 {{- template "body" . -}}
 
@@ -416,6 +506,10 @@ func (s *Storage) DeleteTask(task *Task) error {
 		if err != nil {
 			return err
 		}
+		_, err = tx.Exec("DELETE FROM execute_sql_audit_results WHERE task_id = ?", task.ID)
+		if err != nil {
+			return err
+		}
 		return nil
 	})
 }
@@ -424,8 +518,10 @@ func (s *Storage) GetExpiredTasks(start time.Time) ([]*Task, error) {
 	tasks := []*Task{}
 	err := s.db.Model(&Task{}).Select("tasks.id").
 		Joins("LEFT JOIN workflow_records ON tasks.id = workflow_records.task_id").
+		Joins("LEFT JOIN workflow_instance_records ON tasks.id = workflow_instance_records.task_id").
 		Where("tasks.created_at < ?", start).
 		Where("workflow_records.id is NULL").
+		Where("workflow_instance_records.id is NULL").
 		Scan(&tasks).Error
 
 	return tasks, errors.New(errors.ConnectStorageError, err)
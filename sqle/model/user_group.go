@@ -40,6 +40,13 @@ func (s *Storage) GetUserGroupByName(name string) (
 	return userGroup, true, err
 }
 
+// GetUserGroupsDetail returns all user groups with the users and roles.
+func (s *Storage) GetUserGroupsDetail() ([]*UserGroup, error) {
+	ugs := []*UserGroup{}
+	err := s.db.Preload("Users").Preload("Roles").Order("name ASC").Find(&ugs).Error
+	return ugs, errors.ConnectStorageErrWrapper(err)
+}
+
 // NOTE: parameter: us([]*Users) and rs([]*Role) need to be distinguished as nil or zero length slice.
 func (s *Storage) SaveUserGroupAndAssociations(
 	ug *UserGroup, us []*User, rs []*Role) (err error) {
@@ -100,6 +100,9 @@ func NewStorage(user, password, host, port, schema string, debug bool) (*Storage
 
 type Storage struct {
 	db *gorm.DB
+	// inTx is true if the storage is created by TxStorage, the transactions
+	// started by Tx and TxExec are joined to it.
+	inTx bool
 }
 
 func (s *Storage) AutoMigrate() error {
@@ -109,6 +112,7 @@ func (s *Storage) AutoMigrate() error {
 		&AuditPlanSQLV2{},
 		&AuditPlan{},
 		&ExecuteSQL{},
+		&ExecuteSQLAuditResult{},
 		&Instance{},
 		&WeChatConfiguration{},
 		&LDAPConfiguration{},
@@ -117,6 +121,7 @@ func (s *Storage) AutoMigrate() error {
 		&RoleOperation{},
 		&Role{},
 		&RollbackSQL{},
+		&RuleExemption{},
 		&RuleTemplateRule{},
 		&RuleTemplate{},
 		&Rule{},
@@ -127,6 +132,7 @@ func (s *Storage) AutoMigrate() error {
 		&UserGroup{},
 		&User{},
 		&WorkflowRecord{},
+		&WorkflowInstanceRecord{},
 		&WorkflowStepTemplate{},
 		&WorkflowStep{},
 		&WorkflowTemplate{},
@@ -266,6 +272,7 @@ func (s *Storage) CreateDefaultWorkflowTemplate() error {
 			Name:                          DefaultWorkflowTemplate,
 			Desc:                          "默认模板",
 			AllowSubmitWhenLessAuditLevel: string(driver.RuleLevelWarn),
+			ExecFailureStrategy:           ExecFailureStrategyStop,
 			Steps: []*WorkflowStepTemplate{
 				{
 					Number: 1,
@@ -320,6 +327,13 @@ func (s *Storage) HardDelete(model interface{}) error {
 }
 
 func (s *Storage) TxExec(fn func(tx *sql.Tx) error) error {
+	if s.inTx {
+		tx, ok := s.db.CommonDB().(*sql.Tx)
+		if !ok {
+			return errors.New(errors.ConnectStorageError, fmt.Errorf("storage is not in transaction"))
+		}
+		return errors.ConnectStorageErrWrapper(fn(tx))
+	}
 	db := s.db.DB()
 	tx, err := db.Begin()
 	if err != nil {
@@ -343,6 +357,9 @@ func (s *Storage) TxExec(fn func(tx *sql.Tx) error) error {
 }
 
 func (s *Storage) Tx(fn func(txDB *gorm.DB) error) (err error) {
+	if s.inTx {
+		return errors.ConnectStorageErrWrapper(fn(s.db))
+	}
 	txDB := s.db.Begin()
 	err = fn(txDB)
 	if err != nil {
@@ -358,6 +375,29 @@ func (s *Storage) Tx(fn func(txDB *gorm.DB) error) (err error) {
 	return nil
 }
 
+// TxStorage calls fn with the storage in a transaction, all the methods of
+// txStorage are executed in the transaction, include the methods which start
+// transaction by Tx and TxExec. The transaction is committed if fn returns nil.
+func (s *Storage) TxStorage(fn func(txStorage *Storage) error) error {
+	if s.inTx {
+		return fn(s)
+	}
+	txDB := s.db.Begin()
+	if err := txDB.Error; err != nil {
+		return errors.ConnectStorageErrWrapper(err)
+	}
+	// the error of fn is returned as it is, since it may be not a storage error.
+	if err := fn(&Storage{db: txDB, inTx: true}); err != nil {
+		txDB.Rollback()
+		return err
+	}
+	if err := txDB.Commit().Error; err != nil {
+		txDB.Rollback()
+		return errors.ConnectStorageErrWrapper(err)
+	}
+	return nil
+}
+
 type RowList []string
 
 func (r *RowList) Scan(src interface{}) error {
@@ -3,6 +3,7 @@ package model
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/actiontech/sqle/sqle/errors"
@@ -14,14 +15,28 @@ type WorkflowTemplate struct {
 	Name                          string
 	Desc                          string
 	AllowSubmitWhenLessAuditLevel string
+	ExecFailureStrategy           string `gorm:"default:\"stop\""`
 
 	Steps     []*WorkflowStepTemplate `json:"-" gorm:"foreignkey:workflowTemplateId"`
 	Instances []*Instance             `gorm:"foreignkey:WorkflowTemplateId"`
 }
 
+// ExecFailureStrategy decides how to handle the remaining SQLs when a SQL of
+// task fails on execution.
+const (
+	// ExecFailureStrategyStop stops the execution at the failed SQL.
+	ExecFailureStrategyStop = "stop"
+	// ExecFailureStrategyContinue executes the remaining SQLs.
+	ExecFailureStrategyContinue = "continue"
+	// ExecFailureStrategyRollbackExecuted stops the execution at the failed SQL,
+	// and executes the rollback SQLs of the succeeded SQLs in reverse order.
+	ExecFailureStrategyRollbackExecuted = "rollback_executed"
+)
+
 const (
 	WorkflowStepTypeSQLReview      = "sql_review"
 	WorkflowStepTypeSQLExecute     = "sql_execute"
+	WorkflowStepTypeSQLRollback    = "sql_rollback" // optional, it just be used after sql_execute
 	WorkflowStepTypeCreateWorkflow = "create_workflow"
 	WorkflowStepTypeUpdateWorkflow = "update_workflow"
 )
@@ -67,10 +82,21 @@ func (s *Storage) GetWorkflowStepsDetailByTemplateId(id uint) ([]*WorkflowStepTe
 	return steps, errors.New(errors.ConnectStorageError, err)
 }
 
+// GetWorkflowTemplatesDetail returns all workflow templates with steps and
+// the assignees of step.
+func (s *Storage) GetWorkflowTemplatesDetail() ([]*WorkflowTemplate, error) {
+	dbOrder := func(db *gorm.DB) *gorm.DB {
+		return db.Order("step_number ASC")
+	}
+	templates := []*WorkflowTemplate{}
+	err := s.db.Preload("Steps", dbOrder).Preload("Steps.Users").Order("name ASC").Find(&templates).Error
+	return templates, errors.New(errors.ConnectStorageError, err)
+}
+
 func (s *Storage) SaveWorkflowTemplate(template *WorkflowTemplate) error {
 	return s.TxExec(func(tx *sql.Tx) error {
-		result, err := tx.Exec("INSERT INTO workflow_templates (name, `desc`, `allow_submit_when_less_audit_level`) values (?, ?, ?)",
-			template.Name, template.Desc, template.AllowSubmitWhenLessAuditLevel)
+		result, err := tx.Exec("INSERT INTO workflow_templates (name, `desc`, `allow_submit_when_less_audit_level`, `exec_failure_strategy`) values (?, ?, ?, ?)",
+			template.Name, template.Desc, template.AllowSubmitWhenLessAuditLevel, template.ExecFailureStrategy)
 		if err != nil {
 			return err
 		}
@@ -164,18 +190,117 @@ const (
 	WorkflowStatusExecuting     = "executing"
 	WorkflowStatusExecFailed    = "exec_failed"
 	WorkflowStatusFinish        = "finished"
+	// WorkflowStatusExecPartialSuccess means some targets of multi-instance
+	// workflow are executed successfully, and the others are failed.
+	WorkflowStatusExecPartialSuccess = "exec_partial_success"
 )
 
 type WorkflowRecord struct {
 	Model
+	// TaskId is the task of the first target. It is the only target of the
+	// workflows which are created before multi-instance workflow is supported.
 	TaskId                uint `gorm:"index"`
 	CurrentWorkflowStepId uint
 	Status                string `gorm:"default:\"on_process\""`
 	ScheduledAt           *time.Time
 	ScheduleUserId        uint
+	// RollbackTaskIds and RollbackSQLNumbers are the rollback requested on the
+	// executed workflow, they are rolled back after sql_rollback step is approved.
+	RollbackTaskIds    RowList `gorm:"type:text"`
+	RollbackSQLNumbers RowList `gorm:"type:text"`
+
+	CurrentStep     *WorkflowStep             `gorm:"foreignkey:CurrentWorkflowStepId"`
+	Steps           []*WorkflowStep           `gorm:"foreignkey:WorkflowRecordId"`
+	InstanceRecords []*WorkflowInstanceRecord `gorm:"foreignkey:WorkflowRecordId"`
+}
+
+// TaskIds returns the tasks of all targets in workflow record.
+func (r *WorkflowRecord) TaskIds() []uint {
+	if len(r.InstanceRecords) == 0 {
+		return []uint{r.TaskId}
+	}
+	ids := make([]uint, 0, len(r.InstanceRecords))
+	for _, ir := range r.InstanceRecords {
+		ids = append(ids, ir.TaskId)
+	}
+	return ids
+}
+
+// WorkflowInstanceRecord is an execution target of workflow. Each target has
+// its own task, so it is audited, executed and rolled back independently.
+// RollbackRequest returns the tasks and the SQL numbers requested to roll back.
+func (r *WorkflowRecord) RollbackRequest() (taskIds []uint, sqlNumbers []uint) {
+	return parseUintRowList(r.RollbackTaskIds), parseUintRowList(r.RollbackSQLNumbers)
+}
+
+// SetRollbackRequest saves the tasks and the SQL numbers requested to roll back,
+// the request is cleared if taskIds is empty.
+func (r *WorkflowRecord) SetRollbackRequest(taskIds []uint, sqlNumbers []uint) {
+	if len(taskIds) == 0 {
+		sqlNumbers = nil
+	}
+	r.RollbackTaskIds = formatUintRowList(taskIds)
+	r.RollbackSQLNumbers = formatUintRowList(sqlNumbers)
+}
+
+func parseUintRowList(l RowList) []uint {
+	ids := make([]uint, 0, len(l))
+	for _, v := range l {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
+
+func formatUintRowList(ids []uint) RowList {
+	l := make(RowList, 0, len(ids))
+	for _, id := range ids {
+		l = append(l, strconv.FormatUint(uint64(id), 10))
+	}
+	return l
+}
 
-	CurrentStep *WorkflowStep   `gorm:"foreignkey:CurrentWorkflowStepId"`
-	Steps       []*WorkflowStep `gorm:"foreignkey:WorkflowRecordId"`
+type WorkflowInstanceRecord struct {
+	Model
+	WorkflowRecordId uint `gorm:"index; not null"`
+	TaskId           uint `gorm:"index; not null"`
+	InstanceId       uint
+}
+
+// AggregateTaskStatus aggregates the status of tasks which are executed by
+// workflow, it returns "" if none of the tasks is executed.
+func AggregateTaskStatus(taskStatuses []string) string {
+	var executing, succeeded, failed, waiting bool
+	for _, status := range taskStatuses {
+		switch status {
+		case TaskStatusExecuting:
+			executing = true
+		case TaskStatusExecuteSucceeded:
+			succeeded = true
+		case TaskStatusExecuteFailed:
+			failed = true
+		default:
+			waiting = true
+		}
+	}
+	switch {
+	case executing:
+		return WorkflowStatusExecuting
+	case !succeeded && !failed:
+		return ""
+	case waiting:
+		// the other targets are waiting to execute.
+		return WorkflowStatusExecuting
+	case succeeded && failed:
+		return WorkflowStatusExecPartialSuccess
+	case failed:
+		return WorkflowStatusExecFailed
+	default:
+		return WorkflowStatusFinish
+	}
 }
 
 const (
@@ -199,7 +324,7 @@ type WorkflowStep struct {
 	OperationUser *User                 `gorm:"foreignkey:OperationUserId"`
 }
 
-func generateWorkflowStepByTemplate(stepsTemplate []*WorkflowStepTemplate, allInspector []*User) []*WorkflowStep {
+func generateWorkflowStepByTemplate(stepsTemplate []*WorkflowStepTemplate, allInspector, allRollbackUser []*User) []*WorkflowStep {
 	steps := make([]*WorkflowStep, 0, len(stepsTemplate))
 	for _, st := range stepsTemplate {
 		step := &WorkflowStep{
@@ -208,6 +333,9 @@ func generateWorkflowStepByTemplate(stepsTemplate []*WorkflowStepTemplate, allIn
 		}
 		if st.ApprovedByAuthorized.Bool {
 			step.Assignees = allInspector
+			if st.Typ == WorkflowStepTypeSQLRollback {
+				step.Assignees = allRollbackUser
+			}
 		}
 		steps = append(steps, step)
 	}
@@ -259,10 +387,28 @@ func (w *Workflow) NextStep() *WorkflowStep {
 	return nil
 }
 
-func (w *Workflow) FinalStep() *WorkflowStep {
+// ExecuteStep returns the sql_execute step of workflow, it is the final step
+// unless the workflow has a sql_rollback step.
+func (w *Workflow) ExecuteStep() *WorkflowStep {
+	for _, step := range w.Record.Steps {
+		if step.Template.Typ == WorkflowStepTypeSQLExecute {
+			return step
+		}
+	}
 	return w.Record.Steps[len(w.Record.Steps)-1]
 }
 
+// RollbackStep returns the sql_rollback step of workflow, it returns nil if
+// workflow has no sql_rollback step.
+func (w *Workflow) RollbackStep() *WorkflowStep {
+	for _, step := range w.Record.Steps {
+		if step.Template.Typ == WorkflowStepTypeSQLRollback {
+			return step
+		}
+	}
+	return nil
+}
+
 func (w *Workflow) IsOperationUser(user *User) bool {
 	if w.CurrentStep() == nil {
 		return false
@@ -287,8 +433,56 @@ func (w *Workflow) IsFirstRecord(record *WorkflowRecord) bool {
 	return false
 }
 
-func (s *Storage) CreateWorkflow(subject, desc string, user *User, task *Task,
+// getWorkflowAuthorizedUsers returns the users who have the operation on all
+// instances of the tasks.
+func (s *Storage) getWorkflowAuthorizedUsers(tasks []*Task, opCode int) ([]*User, error) {
+	var inspectors []*User
+	for i, task := range tasks {
+		users, err := s.GetUsersByOperationCode(task.Instance, opCode)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			inspectors = users
+			continue
+		}
+		userIds := map[uint]struct{}{}
+		for _, user := range users {
+			userIds[user.ID] = struct{}{}
+		}
+		intersection := []*User{}
+		for _, inspector := range inspectors {
+			if _, ok := userIds[inspector.ID]; ok {
+				intersection = append(intersection, inspector)
+			}
+		}
+		inspectors = intersection
+	}
+	return inspectors, nil
+}
+
+func saveWorkflowInstanceRecords(tx *gorm.DB, record *WorkflowRecord, tasks []*Task) error {
+	for _, task := range tasks {
+		ir := &WorkflowInstanceRecord{
+			WorkflowRecordId: record.ID,
+			TaskId:           task.ID,
+			InstanceId:       task.InstanceId,
+		}
+		if err := tx.Save(ir).Error; err != nil {
+			return err
+		}
+		record.InstanceRecords = append(record.InstanceRecords, ir)
+	}
+	return nil
+}
+
+// CreateWorkflow creates workflow with tasks, each task is a target of workflow.
+// The first task is the main task which is recorded by WorkflowRecord.TaskId.
+func (s *Storage) CreateWorkflow(subject, desc string, user *User, tasks []*Task,
 	stepTemplates []*WorkflowStepTemplate) error {
+	if len(tasks) == 0 {
+		return errors.New(errors.DataInvalid, fmt.Errorf("workflow has no task"))
+	}
 
 	workflow := &Workflow{
 		Subject:      subject,
@@ -296,15 +490,19 @@ func (s *Storage) CreateWorkflow(subject, desc string, user *User, task *Task,
 		CreateUserId: user.ID,
 	}
 	record := &WorkflowRecord{
-		TaskId: task.ID,
+		TaskId: tasks[0].ID,
 	}
 
-	inspector, err := s.GetUsersByOperationCode(task.Instance, OP_WORKFLOW_AUDIT)
+	inspector, err := s.getWorkflowAuthorizedUsers(tasks, OP_WORKFLOW_AUDIT)
+	if err != nil {
+		return err
+	}
+	rollbackUser, err := s.getWorkflowAuthorizedUsers(tasks, OP_WORKFLOW_ROLLBACK)
 	if err != nil {
 		return err
 	}
 
-	steps := generateWorkflowStepByTemplate(stepTemplates, inspector)
+	steps := generateWorkflowStepByTemplate(stepTemplates, inspector, rollbackUser)
 
 	tx := s.db.Begin()
 
@@ -314,6 +512,12 @@ func (s *Storage) CreateWorkflow(subject, desc string, user *User, task *Task,
 		return errors.New(errors.ConnectStorageError, err)
 	}
 
+	err = saveWorkflowInstanceRecords(tx, record, tasks)
+	if err != nil {
+		tx.Rollback()
+		return errors.New(errors.ConnectStorageError, err)
+	}
+
 	workflow.WorkflowRecordId = record.ID
 	err = tx.Save(workflow).Error
 	if err != nil {
@@ -348,9 +552,12 @@ func (s *Storage) CreateWorkflow(subject, desc string, user *User, task *Task,
 	return errors.New(errors.ConnectStorageError, tx.Commit().Error)
 }
 
-func (s *Storage) UpdateWorkflowRecord(w *Workflow, task *Task) error {
+func (s *Storage) UpdateWorkflowRecord(w *Workflow, tasks []*Task) error {
+	if len(tasks) == 0 {
+		return errors.New(errors.DataInvalid, fmt.Errorf("workflow has no task"))
+	}
 	record := &WorkflowRecord{
-		TaskId: task.ID,
+		TaskId: tasks[0].ID,
 	}
 	steps := w.cloneWorkflowStep()
 
@@ -361,6 +568,12 @@ func (s *Storage) UpdateWorkflowRecord(w *Workflow, task *Task) error {
 		return errors.New(errors.ConnectStorageError, err)
 	}
 
+	err = saveWorkflowInstanceRecords(tx, record, tasks)
+	if err != nil {
+		tx.Rollback()
+		return errors.New(errors.ConnectStorageError, err)
+	}
+
 	for _, step := range steps {
 		currentStep := step
 		currentStep.WorkflowRecordId = record.ID
@@ -421,6 +634,21 @@ func (s *Storage) UpdateWorkflowStatus(w *Workflow, operateStep *WorkflowStep) e
 	})
 }
 
+// UpdateWorkflowRollback saves the rollback request of workflow and the state
+// of sql_rollback step.
+func (s *Storage) UpdateWorkflowRollback(w *Workflow, rollbackStep *WorkflowStep) error {
+	return s.TxExec(func(tx *sql.Tx) error {
+		_, err := tx.Exec("UPDATE workflow_records SET current_workflow_step_id = ?, rollback_task_ids = ?, rollback_sql_numbers = ? WHERE id = ?",
+			w.Record.CurrentWorkflowStepId, w.Record.RollbackTaskIds, w.Record.RollbackSQLNumbers, w.Record.ID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("UPDATE workflow_steps SET operation_user_id = ?, operate_at = ?, state = ?, reason = ? WHERE id = ?",
+			rollbackStep.OperationUserId, rollbackStep.OperateAt, rollbackStep.State, rollbackStep.Reason, rollbackStep.ID)
+		return err
+	})
+}
+
 func (s *Storage) UpdateWorkflowSchedule(w *Workflow, userId uint, scheduleTime *time.Time) error {
 	err := s.db.Model(&WorkflowRecord{}).Where("id = ?", w.Record.ID).Update(map[string]interface{}{
 		"scheduled_at":     scheduleTime,
@@ -480,9 +708,20 @@ func (s *Storage) GetWorkflowDetailById(id string) (*Workflow, bool, error) {
 			workflow.Record.CurrentStep = step
 		}
 	}
+	instanceRecords, err := s.getWorkflowInstanceRecordsByRecordIds([]uint{workflow.Record.ID})
+	if err != nil {
+		return nil, false, err
+	}
+	workflow.Record.InstanceRecords = instanceRecords
 	return workflow, true, nil
 }
 
+func (s *Storage) getWorkflowInstanceRecordsByRecordIds(ids []uint) ([]*WorkflowInstanceRecord, error) {
+	records := []*WorkflowInstanceRecord{}
+	err := s.db.Where("workflow_record_id in (?)", ids).Order("id").Find(&records).Error
+	return records, errors.New(errors.ConnectStorageError, err)
+}
+
 func (s *Storage) GetWorkflowHistoryById(id string) ([]*WorkflowRecord, error) {
 	records := []*WorkflowRecord{}
 	err := s.db.Model(&WorkflowRecord{}).Select("workflow_records.*").
@@ -502,6 +741,10 @@ func (s *Storage) GetWorkflowHistoryById(id string) ([]*WorkflowRecord, error) {
 	if err != nil {
 		return nil, errors.New(errors.ConnectStorageError, err)
 	}
+	instanceRecords, err := s.getWorkflowInstanceRecordsByRecordIds(recordIds)
+	if err != nil {
+		return nil, err
+	}
 	for _, record := range records {
 		record.Steps = []*WorkflowStep{}
 		for _, step := range steps {
@@ -509,6 +752,11 @@ func (s *Storage) GetWorkflowHistoryById(id string) ([]*WorkflowRecord, error) {
 				record.Steps = append(record.Steps, step)
 			}
 		}
+		for _, ir := range instanceRecords {
+			if ir.WorkflowRecordId == record.ID {
+				record.InstanceRecords = append(record.InstanceRecords, ir)
+			}
+		}
 	}
 	return records, nil
 }
@@ -517,7 +765,9 @@ func (s *Storage) GetWorkflowHistoryById(id string) ([]*WorkflowRecord, error) {
 func (s *Storage) GetWorkflowRecordByTaskId(id string) (*WorkflowRecord, bool, error) {
 	record := &WorkflowRecord{}
 	err := s.db.Model(&WorkflowRecord{}).Select("workflow_records.id").
-		Where("workflow_records.task_id = ?", id).Scan(record).Error
+		Joins("LEFT JOIN workflow_instance_records AS wir ON workflow_records.id = wir.workflow_record_id").
+		Where("workflow_records.task_id = ? OR wir.task_id = ?", id, id).
+		Limit(1).Scan(record).Error
 	if err == gorm.ErrRecordNotFound {
 		return nil, false, nil
 	}
@@ -536,7 +786,9 @@ func (s *Storage) GetWorkflowByTaskId(id uint) (*Workflow, bool, error) {
 			"workflows.id = workflow_record_history.workflow_id").
 		Joins("LEFT JOIN workflow_records AS h_wr ON "+
 			"workflow_record_history.workflow_record_id = h_wr.id").
-		Where("wr.task_id = ? OR h_wr.task_id = ? AND workflows.id IS NOT NULL", id, id).
+		Joins("LEFT JOIN workflow_instance_records AS wir ON "+
+			"wr.id = wir.workflow_record_id OR h_wr.id = wir.workflow_record_id").
+		Where("(wr.task_id = ? OR h_wr.task_id = ? OR wir.task_id = ?) AND workflows.id IS NOT NULL", id, id, id).
 		Limit(1).Group("workflows.id").Scan(workflow).Error
 	if err == gorm.ErrRecordNotFound {
 		return nil, false, nil
@@ -570,6 +822,12 @@ func (s *Storage) DeleteWorkflow(workflow *Workflow) error {
 		if err != nil {
 			return err
 		}
+		_, err = tx.Exec("DELETE FROM workflow_instance_records WHERE workflow_record_id = ? "+
+			"OR workflow_record_id IN (SELECT workflow_record_id FROM workflow_record_history WHERE workflow_id = ?)",
+			workflow.WorkflowRecordId, workflow.ID)
+		if err != nil {
+			return err
+		}
 		_, err = tx.Exec("DELETE FROM workflow_record_history WHERE workflow_id = ?", workflow.ID)
 		if err != nil {
 			return err
@@ -612,24 +870,27 @@ func (s *Storage) GetWorkflowBySubject(subject string) (*Workflow, bool, error)
 
 func (s *Storage) TaskWorkflowIsRunning(taskIds []uint) (bool, error) {
 	var workflowRecords []*WorkflowRecord
-	err := s.db.Where("status = ? AND task_id IN (?)", WorkflowStatusRunning, taskIds).Find(&workflowRecords).Error
+	err := s.db.Where("status = ? AND (task_id IN (?) OR id IN (?))", WorkflowStatusRunning, taskIds,
+		s.db.Model(&WorkflowInstanceRecord{}).Select("workflow_record_id").Where("task_id IN (?)", taskIds).QueryExpr()).
+		Find(&workflowRecords).Error
 	return len(workflowRecords) > 0, errors.New(errors.ConnectStorageError, err)
 }
 
-func (s *Storage) GetInstanceByWorkflowID(workflowID uint) (*Instance, error) {
+// GetInstancesByWorkflowID returns the instances of all targets in workflow.
+func (s *Storage) GetInstancesByWorkflowID(workflowID uint) ([]*Instance, error) {
 	query := `
-SELECT instances.id ,instances.maintenance_period
+SELECT DISTINCT instances.id, instances.name, instances.maintenance_period
 FROM workflows AS w
 LEFT JOIN workflow_records AS wr ON wr.id = w.workflow_record_id
-LEFT JOIN tasks ON tasks.id = wr.task_id
+LEFT JOIN workflow_instance_records AS wir ON wir.workflow_record_id = wr.id
+LEFT JOIN tasks ON tasks.id = wr.task_id OR tasks.id = wir.task_id
 LEFT JOIN instances ON instances.id = tasks.instance_id
-WHERE 
-w.id = ?
-LIMIT 1`
-	instance := &Instance{}
-	err := s.db.Raw(query, workflowID).Scan(instance).Error
+WHERE
+w.id = ? AND instances.id IS NOT NULL`
+	instances := []*Instance{}
+	err := s.db.Raw(query, workflowID).Scan(&instances).Error
 	if err != nil {
 		return nil, errors.ConnectStorageErrWrapper(err)
 	}
-	return instance, err
+	return instances, nil
 }
@@ -17,6 +17,7 @@ type WorkflowListDetail struct {
 	TaskInstanceDeletedAt   *time.Time     `json:"task_instance_deleted_at"`
 	TaskInstanceSchema      string         `json:"task_instance_schema"`
 	TaskStatus              string         `json:"task_status"`
+	TargetTaskStatusList    RowList        `json:"target_task_status_list"`
 	CreateUser              sql.NullString `json:"create_user_name"`
 	CreateUserDeletedAt     *time.Time     `json:"create_user_deleted_at"`
 	CreateTime              *time.Time     `json:"create_time"`
@@ -26,8 +27,17 @@ type WorkflowListDetail struct {
 	ScheduleTime            *time.Time     `json:"schedule_time"`
 }
 
+// TaskStatuses returns the distinct task statuses of all targets in workflow.
+func (w *WorkflowListDetail) TaskStatuses() []string {
+	if len(w.TargetTaskStatusList) == 0 {
+		return []string{w.TaskStatus}
+	}
+	return w.TargetTaskStatusList
+}
+
 var workflowsQueryTpl = `SELECT w.id AS workflow_id, w.subject, w.desc, wr.status,
-tasks.status AS task_status, tasks.pass_rate AS task_pass_rate, tasks.score AS task_score, tasks.instance_schema AS task_instance_schema,
+tasks.status AS task_status, GROUP_CONCAT(DISTINCT COALESCE(target_tasks.status,'')) AS target_task_status_list,
+tasks.pass_rate AS task_pass_rate, tasks.score AS task_score, tasks.instance_schema AS task_instance_schema,
 inst.name AS task_instance_name, inst.deleted_at AS task_instance_deleted_at,
 create_user.login_name AS create_user_name, create_user.deleted_at AS create_user_deleted_at,
 w.created_at AS create_time, curr_wst.type AS current_step_type, 
@@ -37,23 +47,45 @@ wr.scheduled_at AS schedule_time
 {{- template "body" . -}} 
 
 GROUP BY w.id
+{{- template "having" . }}
 ORDER BY w.id DESC
 {{- if .limit }}
 LIMIT :limit OFFSET :offset
 {{- end -}}
 `
 
-var workflowsCountTpl = `SELECT COUNT(DISTINCT w.id)
+var workflowsCountTpl = `SELECT COUNT(*) FROM (
+SELECT w.id
 
 {{- template "body" . -}}
+
+GROUP BY w.id
+{{- template "having" . }}
+) AS filtered_workflows
 `
 
+// workflowStatusExpr is the status of workflow shown in list, the same as the
+// status converted from WorkflowListDetail in API. It is the aggregate status
+// of target tasks (see AggregateTaskStatus) if any of them is executed,
+// otherwise it is the status of workflow record.
+var workflowStatusExpr = `CASE
+WHEN MAX(COALESCE(target_tasks.status, tasks.status, '') = 'executing') = 1 THEN 'executing'
+WHEN MAX(COALESCE(target_tasks.status, tasks.status, '') IN ('exec_succeeded', 'exec_failed')) = 0 THEN
+	CASE WHEN wr.status = 'on_process' AND wr.scheduled_at IS NOT NULL THEN 'exec_scheduled' ELSE wr.status END
+WHEN MIN(COALESCE(target_tasks.status, tasks.status, '') IN ('exec_succeeded', 'exec_failed')) = 0 THEN 'executing'
+WHEN MIN(COALESCE(target_tasks.status, tasks.status, '') = 'exec_failed') = 1 THEN 'exec_failed'
+WHEN MAX(COALESCE(target_tasks.status, tasks.status, '') = 'exec_failed') = 1 THEN 'exec_partial_success'
+ELSE 'finished'
+END`
+
 var workflowsQueryBodyTpl = `
 {{ define "body" }}
 FROM workflows AS w
 LEFT JOIN users AS create_user ON w.create_user_id = create_user.id
 LEFT JOIN workflow_records AS wr ON w.workflow_record_id = wr.id
 LEFT JOIN tasks ON wr.task_id = tasks.id
+LEFT JOIN workflow_instance_records AS wir ON wr.id = wir.workflow_record_id
+LEFT JOIN tasks AS target_tasks ON wir.task_id = target_tasks.id
 LEFT JOIN instances AS inst ON tasks.instance_id = inst.id
 LEFT JOIN workflow_steps AS curr_ws ON wr.current_workflow_step_id = curr_ws.id
 LEFT JOIN workflow_step_templates AS curr_wst ON curr_ws.workflow_step_template_id = curr_wst.id
@@ -77,6 +109,7 @@ OR all_ass_user.id = :current_user_id
 
 {{- if .viewable_instance_ids }} 
 OR inst.id IN ( {{ .viewable_instance_ids }})
+OR target_tasks.instance_id IN ( {{ .viewable_instance_ids }})
 {{- end }}
 
 )
@@ -106,27 +139,20 @@ AND curr_wst.type = :filter_current_step_type
 AND wr.status = :filter_status
 {{- end }}
 
-{{- if .is_scheduled }}
-AND wr.scheduled_at IS NOT NULL
-{{- end }}
-
-{{- if .not_scheduled }}
-AND wr.scheduled_at IS NULL
-{{- end }}
-
 {{- if .filter_current_step_assignee_user_name }}
 AND curr_ass_user.login_name = :filter_current_step_assignee_user_name
 {{- end }}
 
-{{- if .filter_task_status }}
-AND tasks.status = :filter_task_status
-{{- end }}
-
 {{- if .filter_task_instance_name }}
 AND inst.name = :filter_task_instance_name
 {{- end }}
 {{ end }}
 
+{{ define "having" }}
+{{- if .filter_workflow_status }}
+HAVING ` + workflowStatusExpr + ` = :filter_workflow_status
+{{- end }}
+{{ end }}
 `
 
 func (s *Storage) GetWorkflowsByReq(data map[string]interface{}, user *User) (
@@ -60,3 +60,50 @@ func TestStorage_UpdateWorkflowSchedule(t *testing.T) {
 	err = mock.ExpectationsWereMet()
 	assert.NoError(t, err)
 }
+
+func TestAggregateTaskStatus(t *testing.T) {
+	assert.Equal(t, "", AggregateTaskStatus([]string{TaskStatusAudited}))
+	assert.Equal(t, "", AggregateTaskStatus([]string{TaskStatusAudited, TaskStatusAudited}))
+	assert.Equal(t, WorkflowStatusExecuting, AggregateTaskStatus([]string{TaskStatusExecuting}))
+	assert.Equal(t, WorkflowStatusExecuting, AggregateTaskStatus([]string{TaskStatusExecuteSucceeded, TaskStatusExecuting}))
+	assert.Equal(t, WorkflowStatusExecuting, AggregateTaskStatus([]string{TaskStatusExecuteSucceeded, TaskStatusAudited}))
+	assert.Equal(t, WorkflowStatusFinish, AggregateTaskStatus([]string{TaskStatusExecuteSucceeded, TaskStatusExecuteSucceeded}))
+	assert.Equal(t, WorkflowStatusExecFailed, AggregateTaskStatus([]string{TaskStatusExecuteFailed}))
+	assert.Equal(t, WorkflowStatusExecPartialSuccess, AggregateTaskStatus([]string{TaskStatusExecuteSucceeded, TaskStatusExecuteFailed}))
+}
+
+func TestWorkflowRecord_RollbackRequest(t *testing.T) {
+	record := &WorkflowRecord{}
+	record.SetRollbackRequest([]uint{1, 2}, []uint{3})
+	taskIds, sqlNumbers := record.RollbackRequest()
+	assert.Equal(t, []uint{1, 2}, taskIds)
+	assert.Equal(t, []uint{3}, sqlNumbers)
+
+	record.SetRollbackRequest(nil, []uint{3})
+	taskIds, sqlNumbers = record.RollbackRequest()
+	assert.Empty(t, taskIds)
+	assert.Empty(t, sqlNumbers)
+}
+
+func TestWorkflowsQueryFilterStatus(t *testing.T) {
+	data := map[string]interface{}{"filter_workflow_status": WorkflowStatusExecPartialSuccess}
+	having := "GROUP BY w.id\nHAVING " + workflowStatusExpr + " = :filter_workflow_status"
+
+	query, err := getSelectQuery(workflowsQueryBodyTpl, workflowsQueryTpl, data)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, query, having)
+
+	query, err = getCountQuery(workflowsQueryBodyTpl, workflowsCountTpl, data)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Contains(t, query, having)
+
+	query, err = getCountQuery(workflowsQueryBodyTpl, workflowsCountTpl, map[string]interface{}{})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.NotContains(t, query, "HAVING")
+}
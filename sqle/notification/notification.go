@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/actiontech/sqle/sqle/driver"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -41,6 +42,8 @@ const (
 	WorkflowNotifyTypeReject
 	WorkflowNotifyTypeExecuteSuccess
 	WorkflowNotifyTypeExecuteFail
+	WorkflowNotifyTypeRollbackSuccess
+	WorkflowNotifyTypeRollbackFail
 )
 
 type WorkflowNotification struct {
@@ -59,11 +62,24 @@ func GetWorkflowStepTypeDesc(s string) string {
 	switch s {
 	case model.WorkflowStepTypeSQLExecute:
 		return "上线"
+	case model.WorkflowStepTypeSQLRollback:
+		return "回滚"
 	default:
 		return "审批"
 	}
 }
 
+func GetExecFailureStrategyDesc(s string) string {
+	switch s {
+	case model.ExecFailureStrategyContinue:
+		return "继续执行"
+	case model.ExecFailureStrategyRollbackExecuted:
+		return "回滚已执行SQL"
+	default:
+		return "停止执行"
+	}
+}
+
 func (w *WorkflowNotification) NotificationSubject() string {
 	switch w.notifyType {
 	case WorkflowNotifyTypeApprove, WorkflowNotifyTypeCreate:
@@ -74,6 +90,10 @@ func (w *WorkflowNotification) NotificationSubject() string {
 		return "SQL工单上线成功"
 	case WorkflowNotifyTypeExecuteFail:
 		return "SQL工单上线失败"
+	case WorkflowNotifyTypeRollbackSuccess:
+		return "SQL工单回滚成功"
+	case WorkflowNotifyTypeRollbackFail:
+		return "SQL工单回滚失败"
 	default:
 		return "SQL工单未知请求"
 	}
@@ -87,6 +107,9 @@ func (w *WorkflowNotification) NotificationBody() string {
 		passRate       float64
 		executeStartAt *time.Time
 		executeEndAt   *time.Time
+
+		execFailureStrategy string
+		execFailureResult   string
 	)
 	s := model.GetStorage()
 	task, exist, err := s.GetTaskById(strconv.Itoa(int(w.workflow.Record.TaskId)))
@@ -98,8 +121,31 @@ func (w *WorkflowNotification) NotificationBody() string {
 		executeStartAt = task.ExecStartAt
 		executeEndAt = task.ExecEndAt
 	}
+	if w.notifyType == WorkflowNotifyTypeExecuteFail {
+		execFailureStrategy, execFailureResult = w.execFailures()
+	}
 	switch w.notifyType {
-	case WorkflowNotifyTypeExecuteSuccess, WorkflowNotifyTypeExecuteFail:
+	case WorkflowNotifyTypeExecuteSuccess:
+		return fmt.Sprintf(`
+- 工单主题: %v
+- 工单描述: %v
+- 申请人: %v
+- 创建时间: %v
+- 数据源: %v
+- schema: %v
+- 上线开始时间: %v
+- 上线结束时间: %v
+`,
+			w.workflow.Subject,
+			w.workflow.Desc,
+			w.workflow.CreateUserName(),
+			w.workflow.CreatedAt,
+			instanceName,
+			schema,
+			executeStartAt,
+			executeEndAt,
+		)
+	case WorkflowNotifyTypeExecuteFail:
 		return fmt.Sprintf(`
 - 工单主题: %v
 - 工单描述: %v
@@ -109,6 +155,8 @@ func (w *WorkflowNotification) NotificationBody() string {
 - schema: %v
 - 上线开始时间: %v
 - 上线结束时间: %v
+- 失败处理策略: %v
+- 失败处理结果: %v
 `,
 			w.workflow.Subject,
 			w.workflow.Desc,
@@ -118,6 +166,30 @@ func (w *WorkflowNotification) NotificationBody() string {
 			schema,
 			executeStartAt,
 			executeEndAt,
+			execFailureStrategy,
+			execFailureResult,
+		)
+	case WorkflowNotifyTypeRollbackSuccess, WorkflowNotifyTypeRollbackFail:
+		var operator string
+		if step := w.workflow.RollbackStep(); step != nil && step.OperationUser != nil {
+			operator = step.OperationUser.Name
+		}
+		return fmt.Sprintf(`
+- 工单主题: %v
+- 工单描述: %v
+- 申请人: %v
+- 创建时间: %v
+- 数据源: %v
+- schema: %v
+- 回滚人: %v
+`,
+			w.workflow.Subject,
+			w.workflow.Desc,
+			w.workflow.CreateUserName(),
+			w.workflow.CreatedAt,
+			instanceName,
+			schema,
+			operator,
 		)
 	case WorkflowNotifyTypeReject:
 		var reason string
@@ -167,6 +239,29 @@ func (w *WorkflowNotification) NotificationBody() string {
 	}
 }
 
+// execFailures returns the failure strategies and results of the execute failed
+// tasks of workflow. They are prefixed by the instance and schema of task if the
+// workflow has multiple targets.
+func (w *WorkflowNotification) execFailures() (strategy, result string) {
+	s := model.GetStorage()
+	taskIds := w.workflow.Record.TaskIds()
+	strategies := make([]string, 0, len(taskIds))
+	results := make([]string, 0, len(taskIds))
+	for _, taskId := range taskIds {
+		task, exist, err := s.GetTaskById(strconv.Itoa(int(taskId)))
+		if err != nil || !exist || task.ExecFailureResult == "" {
+			continue
+		}
+		var prefix string
+		if len(taskIds) > 1 {
+			prefix = fmt.Sprintf("%s(%s): ", task.InstanceName(), task.Schema)
+		}
+		strategies = append(strategies, prefix+GetExecFailureStrategyDesc(task.ExecFailureStrategy))
+		results = append(results, prefix+task.ExecFailureResult)
+	}
+	return strings.Join(strategies, "; "), strings.Join(results, "; ")
+}
+
 func (w *WorkflowNotification) notifyUser() []*model.User {
 	switch w.notifyType {
 	case WorkflowNotifyTypeApprove, WorkflowNotifyTypeCreate:
@@ -182,10 +277,23 @@ func (w *WorkflowNotification) notifyUser() []*model.User {
 		users := []*model.User{
 			w.workflow.CreateUser,
 		}
-		if executeUser := w.workflow.FinalStep().OperationUser; executeUser != nil {
+		if executeUser := w.workflow.ExecuteStep().OperationUser; executeUser != nil {
 			users = append(users, executeUser)
 		}
 		return users
+		// if workflow is rolled back, the creator and the rollback operator needs to be notified.
+	case WorkflowNotifyTypeRollbackSuccess, WorkflowNotifyTypeRollbackFail:
+		users := []*model.User{
+			w.workflow.CreateUser,
+		}
+		step := w.workflow.RollbackStep()
+		if step == nil {
+			step = w.workflow.ExecuteStep()
+		}
+		if operationUser := step.OperationUser; operationUser != nil {
+			users = append(users, operationUser)
+		}
+		return users
 	default:
 		return []*model.User{}
 	}
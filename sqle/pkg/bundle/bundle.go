@@ -0,0 +1,350 @@
+// Package bundle defines the declarative configuration bundle of SQLE, which
+// contains the rule templates, workflow templates, instances, roles, user
+// groups and audit plans. The bundle is kept in git and applied to the SQLE
+// server, the differences between the bundle and the server are planned by
+// Diff.
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/pkg/ruletemplate"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FormatVersion is the version of bundle format, it is changed when the
+// format is changed incompatibly.
+const FormatVersion = "v1"
+
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// The kinds of resource in bundle.
+const (
+	KindRuleTemplate     = "rule_template"
+	KindWorkflowTemplate = "workflow_template"
+	KindInstance         = "instance"
+	KindRole             = "role"
+	KindUserGroup        = "user_group"
+	KindAuditPlan        = "audit_plan"
+)
+
+// Bundle is the declarative configuration. The resource list which is absent
+// (not an empty list) in bundle is not managed by it, the resources of this
+// kind are never deleted even if prune is enabled. The empty resource list is
+// omitted when the bundle is marshaled, so it becomes absent.
+type Bundle struct {
+	FormatVersion string `json:"format_version" yaml:"format_version"`
+	// SQLEVersion is the version of SQLE which exports the bundle.
+	SQLEVersion       string              `json:"sqle_version,omitempty" yaml:"sqle_version,omitempty"`
+	RuleTemplates     []*RuleTemplate     `json:"rule_templates,omitempty" yaml:"rule_templates,omitempty"`
+	WorkflowTemplates []*WorkflowTemplate `json:"workflow_templates,omitempty" yaml:"workflow_templates,omitempty"`
+	Instances         []*Instance         `json:"instances,omitempty" yaml:"instances,omitempty"`
+	Roles             []*Role             `json:"roles,omitempty" yaml:"roles,omitempty"`
+	UserGroups        []*UserGroup        `json:"user_groups,omitempty" yaml:"user_groups,omitempty"`
+	AuditPlans        []*AuditPlan        `json:"audit_plans,omitempty" yaml:"audit_plans,omitempty"`
+}
+
+type RuleTemplate struct {
+	Name   string              `json:"name" yaml:"name"`
+	Desc   string              `json:"desc" yaml:"desc"`
+	DBType string              `json:"db_type" yaml:"db_type"`
+	Rules  []ruletemplate.Rule `json:"rules" yaml:"rules"`
+}
+
+type WorkflowTemplate struct {
+	Name string `json:"name" yaml:"name"`
+	Desc string `json:"desc" yaml:"desc"`
+	// AllowSubmitWhenLessAuditLevel is "warn" by default.
+	AllowSubmitWhenLessAuditLevel string `json:"allow_submit_when_less_audit_level" yaml:"allow_submit_when_less_audit_level"`
+	// ExecFailureStrategy is "stop" by default.
+	ExecFailureStrategy string          `json:"exec_failure_strategy" yaml:"exec_failure_strategy"`
+	Steps               []*WorkflowStep `json:"steps" yaml:"steps"`
+}
+
+type WorkflowStep struct {
+	Type                 string   `json:"type" yaml:"type"`
+	Desc                 string   `json:"desc" yaml:"desc"`
+	ApprovedByAuthorized bool     `json:"approved_by_authorized" yaml:"approved_by_authorized"`
+	AssigneeUserNames    []string `json:"assignee_user_names,omitempty" yaml:"assignee_user_names,omitempty"`
+}
+
+type Instance struct {
+	Name   string `json:"name" yaml:"name"`
+	DBType string `json:"db_type" yaml:"db_type"`
+	Host   string `json:"host" yaml:"host"`
+	Port   string `json:"port" yaml:"port"`
+	User   string `json:"user" yaml:"user"`
+	// Password is required when the instance is created, the password of
+	// exist instance is not changed if it is empty. It is never exported.
+	Password             string              `json:"password,omitempty" yaml:"password,omitempty"`
+	Desc                 string              `json:"desc" yaml:"desc"`
+	WorkflowTemplateName string              `json:"workflow_template_name" yaml:"workflow_template_name"`
+	RuleTemplateName     string              `json:"rule_template_name" yaml:"rule_template_name"`
+	MaintenancePeriods   []MaintenancePeriod `json:"maintenance_periods" yaml:"maintenance_periods"`
+	AdditionalParams     []Param             `json:"additional_params" yaml:"additional_params"`
+}
+
+type MaintenancePeriod struct {
+	StartHour   int `json:"start_hour" yaml:"start_hour"`
+	StartMinute int `json:"start_minute" yaml:"start_minute"`
+	EndHour     int `json:"end_hour" yaml:"end_hour"`
+	EndMinute   int `json:"end_minute" yaml:"end_minute"`
+}
+
+type Param struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// Role binds the instances and users, the user groups of role are bound by
+// UserGroup.RoleNames.
+type Role struct {
+	Name           string   `json:"name" yaml:"name"`
+	Desc           string   `json:"desc" yaml:"desc"`
+	InstanceNames  []string `json:"instance_names" yaml:"instance_names"`
+	UserNames      []string `json:"user_names" yaml:"user_names"`
+	OperationCodes []uint   `json:"operation_codes" yaml:"operation_codes"`
+}
+
+type UserGroup struct {
+	Name      string   `json:"name" yaml:"name"`
+	Desc      string   `json:"desc" yaml:"desc"`
+	UserNames []string `json:"user_names" yaml:"user_names"`
+	RoleNames []string `json:"role_names" yaml:"role_names"`
+}
+
+type AuditPlan struct {
+	Name             string  `json:"name" yaml:"name"`
+	Type             string  `json:"type" yaml:"type"`
+	DBType           string  `json:"db_type" yaml:"db_type"`
+	InstanceName     string  `json:"instance_name" yaml:"instance_name"`
+	InstanceDatabase string  `json:"instance_database" yaml:"instance_database"`
+	Cron             string  `json:"cron" yaml:"cron"`
+	Params           []Param `json:"params" yaml:"params"`
+
+	NotifyInterval      int    `json:"notify_interval" yaml:"notify_interval"`
+	NotifyLevel         string `json:"notify_level" yaml:"notify_level"`
+	EnableEmailNotify   bool   `json:"enable_email_notify" yaml:"enable_email_notify"`
+	EnableWebHookNotify bool   `json:"enable_web_hook_notify" yaml:"enable_web_hook_notify"`
+	WebHookURL          string `json:"web_hook_url" yaml:"web_hook_url"`
+	WebHookTemplate     string `json:"web_hook_template" yaml:"web_hook_template"`
+}
+
+const (
+	defaultAllowSubmitWhenLessAuditLevel = string(driver.RuleLevelWarn)
+	defaultExecFailureStrategy           = "stop"
+	defaultNotifyInterval                = 10
+	defaultNotifyLevel                   = string(driver.RuleLevelWarn)
+)
+
+var validExecFailureStrategies = map[string]struct{}{
+	"stop":              {},
+	"continue":          {},
+	"rollback_executed": {},
+}
+
+var validWorkflowStepTypes = map[string]struct{}{
+	"sql_review":   {},
+	"sql_execute":  {},
+	"sql_rollback": {},
+}
+
+var validRuleLevels = map[string]struct{}{
+	string(driver.RuleLevelNormal): {},
+	string(driver.RuleLevelNotice): {},
+	string(driver.RuleLevelWarn):   {},
+	string(driver.RuleLevelError):  {},
+}
+
+// Unmarshal parses the bundle in YAML or JSON, JSON is parsed as YAML since
+// it is a subset of YAML. The bundle is validated and normalized, so it can
+// be compared with the bundle loaded from SQLE server.
+func Unmarshal(data []byte) (*Bundle, error) {
+	b := &Bundle{}
+	if err := yaml.Unmarshal(data, b); err != nil {
+		return nil, err
+	}
+	if b.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported format version \"%s\" of bundle, expect \"%s\"",
+			b.FormatVersion, FormatVersion)
+	}
+	b.setDefault()
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	b.Normalize()
+	return b, nil
+}
+
+// Marshal encodes the bundle in YAML or JSON.
+func Marshal(b *Bundle, format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(b)
+	case FormatJSON:
+		return json.MarshalIndent(b, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported format \"%s\" of bundle", format)
+	}
+}
+
+func (b *Bundle) setDefault() {
+	for _, t := range b.WorkflowTemplates {
+		if t.AllowSubmitWhenLessAuditLevel == "" {
+			t.AllowSubmitWhenLessAuditLevel = defaultAllowSubmitWhenLessAuditLevel
+		}
+		if t.ExecFailureStrategy == "" {
+			t.ExecFailureStrategy = defaultExecFailureStrategy
+		}
+	}
+	for _, ap := range b.AuditPlans {
+		if ap.NotifyInterval == 0 {
+			ap.NotifyInterval = defaultNotifyInterval
+		}
+		if ap.NotifyLevel == "" {
+			ap.NotifyLevel = defaultNotifyLevel
+		}
+	}
+}
+
+// Validate checks the bundle itself, the references to the resources which
+// are not in bundle are checked when it is applied.
+func (b *Bundle) Validate() error {
+	names := map[string]map[string]struct{}{}
+	checkName := func(kind, name string) error {
+		if name == "" {
+			return fmt.Errorf("name of %s is empty", kind)
+		}
+		if _, ok := names[kind]; !ok {
+			names[kind] = map[string]struct{}{}
+		}
+		if _, ok := names[kind][name]; ok {
+			return fmt.Errorf("duplicate %s %s", kind, name)
+		}
+		names[kind][name] = struct{}{}
+		return nil
+	}
+
+	for _, t := range b.RuleTemplates {
+		if err := checkName(KindRuleTemplate, t.Name); err != nil {
+			return err
+		}
+		if t.DBType == "" {
+			return fmt.Errorf("db type of rule template %s is empty", t.Name)
+		}
+		ruleNames := map[string]struct{}{}
+		for _, rule := range t.Rules {
+			if _, ok := validRuleLevels[rule.Level]; !ok {
+				return fmt.Errorf("invalid level \"%s\" of rule %s in rule template %s", rule.Level, rule.Name, t.Name)
+			}
+			if _, ok := ruleNames[rule.Name]; ok {
+				return fmt.Errorf("duplicate rule %s in rule template %s", rule.Name, t.Name)
+			}
+			ruleNames[rule.Name] = struct{}{}
+		}
+	}
+	for _, t := range b.WorkflowTemplates {
+		if err := checkName(KindWorkflowTemplate, t.Name); err != nil {
+			return err
+		}
+		if _, ok := validRuleLevels[t.AllowSubmitWhenLessAuditLevel]; !ok {
+			return fmt.Errorf("invalid allow submit when less audit level \"%s\" of workflow template %s",
+				t.AllowSubmitWhenLessAuditLevel, t.Name)
+		}
+		if _, ok := validExecFailureStrategies[t.ExecFailureStrategy]; !ok {
+			return fmt.Errorf("invalid exec failure strategy \"%s\" of workflow template %s",
+				t.ExecFailureStrategy, t.Name)
+		}
+		for _, step := range t.Steps {
+			if _, ok := validWorkflowStepTypes[step.Type]; !ok {
+				return fmt.Errorf("invalid step type \"%s\" of workflow template %s", step.Type, t.Name)
+			}
+		}
+	}
+	for _, inst := range b.Instances {
+		if err := checkName(KindInstance, inst.Name); err != nil {
+			return err
+		}
+		if inst.DBType == "" || inst.Host == "" || inst.Port == "" || inst.User == "" {
+			return fmt.Errorf("db type, host, port and user of instance %s are required", inst.Name)
+		}
+	}
+	for _, r := range b.Roles {
+		if err := checkName(KindRole, r.Name); err != nil {
+			return err
+		}
+	}
+	for _, ug := range b.UserGroups {
+		if err := checkName(KindUserGroup, ug.Name); err != nil {
+			return err
+		}
+	}
+	for _, ap := range b.AuditPlans {
+		if err := checkName(KindAuditPlan, ap.Name); err != nil {
+			return err
+		}
+		if ap.Type == "" || ap.DBType == "" || ap.Cron == "" {
+			return fmt.Errorf("type, db type and cron of audit plan %s are required", ap.Name)
+		}
+		if ap.InstanceDatabase != "" && ap.InstanceName == "" {
+			return fmt.Errorf("instance name of audit plan %s can not be empty while instance database is not empty", ap.Name)
+		}
+		if _, ok := validRuleLevels[ap.NotifyLevel]; !ok {
+			return fmt.Errorf("invalid notify level \"%s\" of audit plan %s", ap.NotifyLevel, ap.Name)
+		}
+	}
+	return nil
+}
+
+// Normalize sorts the resources by name, and sorts the unordered lists of
+// resource, such as the user names of role.
+func (b *Bundle) Normalize() {
+	sort.Slice(b.RuleTemplates, func(i, j int) bool { return b.RuleTemplates[i].Name < b.RuleTemplates[j].Name })
+	for _, t := range b.RuleTemplates {
+		sort.Slice(t.Rules, func(i, j int) bool { return t.Rules[i].Name < t.Rules[j].Name })
+		for _, rule := range t.Rules {
+			sort.Slice(rule.Params, func(i, j int) bool { return rule.Params[i].Key < rule.Params[j].Key })
+		}
+	}
+
+	sort.Slice(b.WorkflowTemplates, func(i, j int) bool { return b.WorkflowTemplates[i].Name < b.WorkflowTemplates[j].Name })
+	for _, t := range b.WorkflowTemplates {
+		for _, step := range t.Steps {
+			sort.Strings(step.AssigneeUserNames)
+		}
+	}
+
+	sort.Slice(b.Instances, func(i, j int) bool { return b.Instances[i].Name < b.Instances[j].Name })
+	for _, inst := range b.Instances {
+		sortParams(inst.AdditionalParams)
+	}
+
+	sort.Slice(b.Roles, func(i, j int) bool { return b.Roles[i].Name < b.Roles[j].Name })
+	for _, r := range b.Roles {
+		sort.Strings(r.InstanceNames)
+		sort.Strings(r.UserNames)
+		sort.Slice(r.OperationCodes, func(i, j int) bool { return r.OperationCodes[i] < r.OperationCodes[j] })
+	}
+
+	sort.Slice(b.UserGroups, func(i, j int) bool { return b.UserGroups[i].Name < b.UserGroups[j].Name })
+	for _, ug := range b.UserGroups {
+		sort.Strings(ug.UserNames)
+		sort.Strings(ug.RoleNames)
+	}
+
+	sort.Slice(b.AuditPlans, func(i, j int) bool { return b.AuditPlans[i].Name < b.AuditPlans[j].Name })
+	for _, ap := range b.AuditPlans {
+		sortParams(ap.Params)
+	}
+}
+
+func sortParams(params []Param) {
+	sort.Slice(params, func(i, j int) bool { return params[i].Key < params[j].Key })
+}
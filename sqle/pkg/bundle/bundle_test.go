@@ -0,0 +1,144 @@
+package bundle
+
+import (
+	"testing"
+
+	"github.com/actiontech/sqle/sqle/pkg/ruletemplate"
+	"github.com/stretchr/testify/assert"
+)
+
+const testBundle = `
+format_version: v1
+rule_templates:
+  - name: t1
+    db_type: mysql
+    rules:
+      - name: rule2
+        level: warn
+      - name: rule1
+        level: error
+        params:
+          - key: max
+            value: "10"
+workflow_templates:
+  - name: w1
+    steps:
+      - type: sql_review
+        assignee_user_names: [u2, u1]
+      - type: sql_execute
+        approved_by_authorized: true
+instances:
+  - name: inst1
+    db_type: mysql
+    host: 10.0.0.1
+    port: "3306"
+    user: root
+    password: secret
+    rule_template_name: t1
+    workflow_template_name: w1
+roles:
+  - name: r1
+    instance_names: [inst1]
+    user_names: [u2, u1]
+    operation_codes: [30100, 20100]
+user_groups: []
+`
+
+func TestUnmarshal(t *testing.T) {
+	b, err := Unmarshal([]byte(testBundle))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []ruletemplate.Rule{
+		{Name: "rule1", Level: "error", Params: []ruletemplate.Param{{Key: "max", Value: "10"}}},
+		{Name: "rule2", Level: "warn"},
+	}, b.RuleTemplates[0].Rules)
+	assert.Equal(t, "warn", b.WorkflowTemplates[0].AllowSubmitWhenLessAuditLevel)
+	assert.Equal(t, "stop", b.WorkflowTemplates[0].ExecFailureStrategy)
+	assert.Equal(t, []string{"u1", "u2"}, b.WorkflowTemplates[0].Steps[0].AssigneeUserNames)
+	assert.Equal(t, []string{"u1", "u2"}, b.Roles[0].UserNames)
+	assert.Equal(t, []uint{20100, 30100}, b.Roles[0].OperationCodes)
+	assert.NotNil(t, b.UserGroups)
+	assert.Nil(t, b.AuditPlans)
+
+	for _, data := range []string{
+		`{"format_version": "v2"}`,
+		`{"format_version": "v1", "roles": [{"name": "r1"}, {"name": "r1"}]}`,
+		`{"format_version": "v1", "instances": [{"name": "inst1", "db_type": "mysql"}]}`,
+		`{"format_version": "v1", "rule_templates": [{"name": "t1", "db_type": "mysql", "rules": [{"name": "rule1", "level": "fatal"}]}]}`,
+		`{"format_version": "v1", "workflow_templates": [{"name": "w1", "exec_failure_strategy": "retry"}]}`,
+		`{"format_version": "v1", "workflow_templates": [{"name": "w1", "steps": [{"type": "sql_check"}]}]}`,
+		`{"format_version": "v1", "audit_plans": [{"name": "ap1", "type": "default", "db_type": "mysql", "cron": "* * * * *", "instance_database": "db1"}]}`,
+	} {
+		_, err = Unmarshal([]byte(data))
+		assert.Error(t, err, data)
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	b, err := Unmarshal([]byte(testBundle))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	for _, format := range []string{FormatYAML, FormatJSON} {
+		data, err := Marshal(b, format)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		actual, err := Unmarshal(data)
+		assert.NoError(t, err)
+		assert.Empty(t, Diff(actual, b, true))
+		assert.Equal(t, "secret", actual.Instances[0].Password)
+	}
+	_, err = Marshal(b, "xml")
+	assert.Error(t, err)
+}
+
+func TestDiff(t *testing.T) {
+	desired, err := Unmarshal([]byte(testBundle))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	current := &Bundle{
+		RuleTemplates: []*RuleTemplate{
+			{Name: "t1", DBType: "mysql", Rules: []ruletemplate.Rule{
+				{Name: "rule1", Level: "error", Params: []ruletemplate.Param{{Key: "max", Value: "10"}}},
+				{Name: "rule2", Level: "warn"},
+			}},
+			{Name: "t2", DBType: "mysql"},
+		},
+		Instances: []*Instance{
+			{Name: "inst1", DBType: "mysql", Host: "10.0.0.2", Port: "3306", User: "root", Password: "secret",
+				RuleTemplateName: "t1", MaintenancePeriods: []MaintenancePeriod{}},
+			{Name: "inst2", DBType: "mysql"},
+		},
+		UserGroups: []*UserGroup{{Name: "g1"}},
+		AuditPlans: []*AuditPlan{{Name: "ap1"}},
+	}
+
+	assert.Equal(t, []*Change{
+		{Kind: KindWorkflowTemplate, Name: "w1", Action: ActionCreate},
+		{Kind: KindInstance, Name: "inst1", Action: ActionUpdate, Fields: []string{"host", "workflow_template_name"}},
+		{Kind: KindRole, Name: "r1", Action: ActionCreate},
+	}, Diff(desired, current, false))
+
+	// the audit plans are absent in bundle, so they are not pruned.
+	assert.Equal(t, []*Change{
+		{Kind: KindWorkflowTemplate, Name: "w1", Action: ActionCreate},
+		{Kind: KindInstance, Name: "inst1", Action: ActionUpdate, Fields: []string{"host", "workflow_template_name"}},
+		{Kind: KindRole, Name: "r1", Action: ActionCreate},
+		{Kind: KindUserGroup, Name: "g1", Action: ActionDelete},
+		{Kind: KindInstance, Name: "inst2", Action: ActionDelete},
+		{Kind: KindRuleTemplate, Name: "t2", Action: ActionDelete},
+	}, Diff(desired, current, true))
+
+	// the empty password means the password is not changed.
+	desired.Instances[0].Password = ""
+	desired.Instances[0].Host = "10.0.0.2"
+	desired.Instances[0].WorkflowTemplateName = ""
+	current.Instances[0].Password = "other"
+	assert.Equal(t, []*Change{
+		{Kind: KindWorkflowTemplate, Name: "w1", Action: ActionCreate},
+		{Kind: KindRole, Name: "r1", Action: ActionCreate},
+	}, Diff(desired, current, false))
+}
@@ -0,0 +1,114 @@
+package bundle
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Change is a step of plan, Fields are the changed fields of updated resource.
+type Change struct {
+	Kind   string   `json:"kind"`
+	Name   string   `json:"name"`
+	Action string   `json:"action"`
+	Fields []string `json:"fields,omitempty"`
+}
+
+// resource is the resource list of a kind in bundle.
+type resource struct {
+	kind string
+	list func(b *Bundle) interface{}
+}
+
+// resources are in the order of dependency, the resource can only refer to
+// the resources before it.
+var resources = []resource{
+	{KindRuleTemplate, func(b *Bundle) interface{} { return b.RuleTemplates }},
+	{KindWorkflowTemplate, func(b *Bundle) interface{} { return b.WorkflowTemplates }},
+	{KindInstance, func(b *Bundle) interface{} { return b.Instances }},
+	{KindRole, func(b *Bundle) interface{} { return b.Roles }},
+	{KindUserGroup, func(b *Bundle) interface{} { return b.UserGroups }},
+	{KindAuditPlan, func(b *Bundle) interface{} { return b.AuditPlans }},
+}
+
+// Diff returns the changes which make current to be desired. The resources
+// are created and updated in the order of dependency, and then deleted in
+// the reverse order. The resources which are not in desired are deleted only
+// when prune is true and the resource list of the kind is present in desired.
+func Diff(desired, current *Bundle, prune bool) []*Change {
+	changes := []*Change{}
+	deletes := make([][]*Change, len(resources))
+	for k, r := range resources {
+		desiredList := reflect.ValueOf(r.list(desired))
+		currentMap := map[string]reflect.Value{}
+		currentList := reflect.ValueOf(r.list(current))
+		for i := 0; i < currentList.Len(); i++ {
+			currentMap[resourceName(currentList.Index(i))] = currentList.Index(i)
+		}
+
+		desiredNames := map[string]struct{}{}
+		for i := 0; i < desiredList.Len(); i++ {
+			d := desiredList.Index(i)
+			name := resourceName(d)
+			desiredNames[name] = struct{}{}
+			c, ok := currentMap[name]
+			if !ok {
+				changes = append(changes, &Change{Kind: r.kind, Name: name, Action: ActionCreate})
+				continue
+			}
+			if fields := diffFields(r.kind, d.Elem(), c.Elem()); len(fields) > 0 {
+				changes = append(changes, &Change{Kind: r.kind, Name: name, Action: ActionUpdate, Fields: fields})
+			}
+		}
+
+		if !prune || desiredList.IsNil() {
+			continue
+		}
+		for i := 0; i < currentList.Len(); i++ {
+			name := resourceName(currentList.Index(i))
+			if _, ok := desiredNames[name]; !ok {
+				deletes[k] = append(deletes[k], &Change{Kind: r.kind, Name: name, Action: ActionDelete})
+			}
+		}
+	}
+	for k := len(deletes) - 1; k >= 0; k-- {
+		changes = append(changes, deletes[k]...)
+	}
+	return changes
+}
+
+func resourceName(v reflect.Value) string {
+	return v.Elem().FieldByName("Name").String()
+}
+
+// diffFields returns the names of the changed fields, the empty list is equal
+// to nil. The empty password of instance means the password is not changed.
+func diffFields(kind string, desired, current reflect.Value) []string {
+	fields := []string{}
+	t := desired.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "name" {
+			continue
+		}
+		if kind == KindInstance && name == "password" && desired.Field(i).String() == "" {
+			continue
+		}
+		d, c := desired.Field(i), current.Field(i)
+		if d.Kind() == reflect.Slice && d.Len() == 0 && c.Len() == 0 {
+			continue
+		}
+		dData, _ := json.Marshal(d.Interface())
+		cData, _ := json.Marshal(c.Interface())
+		if string(dData) != string(cData) {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
@@ -27,7 +27,8 @@ type Adaptor struct {
 
 	rules            []*driver.Rule
 	ruleToRawHandler map[string] /*rule name*/ rawSQLRuleHandler
-	ruleToASTHandler map[string] /*rule name*/ astSQLRuleHandler
+	ruleToASTHandler map[string] /*rule name*/ locatedASTSQLRuleHandler
+	ruleToScript     map[string] /*rule name*/ *scriptRule
 
 	additionalParams params.Params
 
@@ -35,11 +36,17 @@ type Adaptor struct {
 }
 
 type adaptorOptions struct {
-	sqlParser func(string) (interface{}, error)
+	sqlParser            func(string) (interface{}, error)
+	sqlSplitter          func(string) ([]string, error)
+	rollbackSQLGenerator rollbackSQLGenerator
+	version              string
+	scriptRuleDir        *string
 }
 
 type rawSQLRuleHandler func(ctx context.Context, rule *driver.Rule, rawSQL string) (string, error)
 type astSQLRuleHandler func(ctx context.Context, rule *driver.Rule, astSQL interface{}) (string, error)
+type locatedASTSQLRuleHandler func(ctx context.Context, rule *driver.Rule, astSQL interface{}) (msg string, offset int, err error)
+type rollbackSQLGenerator func(ctx context.Context, conn *sql.Conn, astSQL interface{}) (rollbackSQL, reason string, err error)
 
 // NewAdaptor create a database plugin Adaptor with dialector.
 func NewAdaptor(dt Dialector) *Adaptor {
@@ -53,7 +60,8 @@ func NewAdaptor(dt Dialector) *Adaptor {
 			Level:      hclog.Trace,
 		}),
 		ruleToRawHandler: make(map[string]rawSQLRuleHandler),
-		ruleToASTHandler: make(map[string]astSQLRuleHandler),
+		ruleToASTHandler: make(map[string]locatedASTSQLRuleHandler),
+		ruleToScript:     make(map[string]*scriptRule),
 		additionalParams: params.Params{},
 	}
 }
@@ -68,10 +76,24 @@ func (a *Adaptor) AddAdditionalParams(p *params.Param) {
 }
 
 func (a *Adaptor) AddRuleWithSQLParser(r *driver.Rule, h astSQLRuleHandler) {
+	a.AddLocatedRuleWithSQLParser(r, func(ctx context.Context, rule *driver.Rule, astSQL interface{}) (string, int, error) {
+		msg, err := h(ctx, rule, astSQL)
+		return msg, -1, err
+	})
+}
+
+// AddLocatedRuleWithSQLParser is like AddRuleWithSQLParser, and the handler
+// returns the byte offset of the offending node in the audited SQL as well.
+// The finding is located at the beginning of SQL if the offset is negative.
+func (a *Adaptor) AddLocatedRuleWithSQLParser(r *driver.Rule, h locatedASTSQLRuleHandler) {
 	a.rules = append(a.rules, r)
 	a.ruleToASTHandler[r.Name] = h
 }
 
+// Serve serves the plugin with the rules added by AddRule() and
+// AddRuleWithSQLParser(), and the script rules in the directory of
+// WithScriptRuleDir(), see ScriptRuleFile. The custom rules of match type
+// "expr" from SQLE are also evaluated like the script rules.
 func (a *Adaptor) Serve(opts ...AdaptorOption) {
 	defer func() {
 		if err := recover(); err != nil {
@@ -83,18 +105,30 @@ func (a *Adaptor) Serve(opts ...AdaptorOption) {
 		opt.apply(a.ao)
 	}
 
+	scriptRuleDir := defaultScriptRuleDir()
+	if a.ao.scriptRuleDir != nil {
+		scriptRuleDir = *a.ao.scriptRuleDir
+	}
+	a.loadScriptRules(scriptRuleDir)
+
 	if len(a.rules) == 0 {
 		a.l.Info("no rule in plugin adaptor", "name", a.dt)
 	}
 
 	if len(a.ruleToASTHandler) != 0 && a.ao.sqlParser == nil {
-		panic("Add rule by AddRuleWithSQLParser(), but no SQL parser provided.")
+		panic("Add rule by AddRuleWithSQLParser() or AddLocatedRuleWithSQLParser(), but no SQL parser provided.")
+	}
+
+	if a.ao.rollbackSQLGenerator != nil && a.ao.sqlParser == nil {
+		panic("Rollback SQL generator provided by WithRollbackSQLGenerator(), but no SQL parser provided.")
 	}
 
 	r := &registererImpl{
 		dt:               a.dt,
 		rules:            a.rules,
 		additionalParams: a.additionalParams,
+		version:          a.ao.version,
+		rollback:         a.ao.rollbackSQLGenerator != nil,
 	}
 
 	newDriver := func(cfg *driver.Config) driver.Driver {
@@ -157,6 +191,40 @@ func WithSQLParser(parser func(sql string) (ast interface{}, err error)) Adaptor
 	})
 }
 
+// WithSQLSplitter define custom SQL splitter. If set, the adaptor will use
+// it to split the SQLs instead of the MySQL style splitter, it is useful
+// for the database which has its own delimiter, such as "/" of Oracle.
+func WithSQLSplitter(splitter func(sql string) (sqls []string, err error)) AdaptorOption {
+	return newOptionFunc(func(a *adaptorOptions) {
+		a.sqlSplitter = splitter
+	})
+}
+
+// WithRollbackSQLGenerator define custom rollback SQL generator. The generator
+// receives the ast parsed by the SQL parser of WithSQLParser(), and conn is nil
+// if the driver is used without instance. If set, the plugin supports rollback.
+func WithRollbackSQLGenerator(generator func(ctx context.Context, conn *sql.Conn, ast interface{}) (rollbackSQL, reason string, err error)) AdaptorOption {
+	return newOptionFunc(func(a *adaptorOptions) {
+		a.rollbackSQLGenerator = generator
+	})
+}
+
+// WithVersion define the plugin version, it is shown in plugin status of SQLE.
+func WithVersion(version string) AdaptorOption {
+	return newOptionFunc(func(a *adaptorOptions) {
+		a.version = version
+	})
+}
+
+// WithScriptRuleDir define the directory of script rule files, see
+// ScriptRuleFile. The default directory is "<plugin executable>.rules", and
+// the empty dir disables the script rules.
+func WithScriptRuleDir(dir string) AdaptorOption {
+	return newOptionFunc(func(a *adaptorOptions) {
+		a.scriptRuleDir = &dir
+	})
+}
+
 var _ driver.Driver = (*driverImpl)(nil)
 var _ driver.Registerer = (*registererImpl)(nil)
 
@@ -164,6 +232,8 @@ type registererImpl struct {
 	dt               Dialector
 	rules            []*driver.Rule
 	additionalParams params.Params
+	version          string
+	rollback         bool
 }
 
 func (r *registererImpl) Name() string {
@@ -178,6 +248,21 @@ func (r *registererImpl) AdditionalParams() params.Params {
 	return r.additionalParams
 }
 
+func (r *registererImpl) Version() string {
+	return r.version
+}
+
+// Capabilities returns the capabilities of driverImpl. driverImpl can only
+// generate rollback SQL by the generator of WithRollbackSQLGenerator(), so
+// rollback is not supported without it.
+func (r *registererImpl) Capabilities() driver.Capabilities {
+	capabilities := driver.Capabilities{driver.CapabilityTx, driver.CapabilitySchemas, driver.CapabilityExprRule}
+	if r.rollback {
+		capabilities = append(capabilities, driver.CapabilityRollback)
+	}
+	return capabilities
+}
+
 type driverImpl struct {
 	a    *Adaptor
 	db   *sql.DB
@@ -200,6 +285,13 @@ func (d *driverImpl) Ping(ctx context.Context) error {
 	return nil
 }
 
+// Reset implements driver.Resetter. driverImpl keeps no SQL context, so only
+// the rules are replaced.
+func (d *driverImpl) Reset(ctx context.Context, rules []*driver.Rule) error {
+	d.a.cfg = &driver.Config{DSN: d.a.cfg.DSN, Rules: rules}
+	return nil
+}
+
 func (d *driverImpl) Exec(ctx context.Context, sql string) (_driver.Result, error) {
 	res, err := d.conn.ExecContext(ctx, sql)
 	if err != nil {
@@ -270,12 +362,13 @@ func (d *driverImpl) Schemas(ctx context.Context) ([]string, error) {
 }
 
 func (d *driverImpl) Parse(ctx context.Context, sql string) ([]driver.Node, error) {
-	sqls, err := sqlparser.SplitStatementToPieces(sql)
-	if err != nil {
-		return nil, errors.Wrap(err, "split sql")
+	split := sqlparser.SplitStatementToPieces
+	if d.a.ao.sqlSplitter != nil {
+		split = d.a.ao.sqlSplitter
 	}
+	sqls, err := split(sql)
 	if err != nil {
-		return nil, errors.Wrapf(err, "split sql %s error", sql)
+		return nil, errors.Wrap(err, "split sql")
 	}
 
 	nodes := make([]driver.Node, 0, len(sqls))
@@ -311,22 +404,29 @@ func (d *driverImpl) Audit(ctx context.Context, sql string) (*driver.AuditResult
 	}
 
 	result := driver.NewInspectResults()
+	env := &scriptEnv{sql: sql, ast: ast}
 	for _, rule := range d.a.cfg.Rules {
+		if msg, ok := d.auditScriptRule(rule, env); ok {
+			result.AddResult(newRuleResult(rule, msg))
+			continue
+		}
 		handler, ok := d.a.ruleToRawHandler[rule.Name]
 		if ok {
 			msg, err := handler(ctx, rule, sql)
 			if err != nil {
 				return nil, errors.Wrapf(err, "audit SQL %s in driver adaptor", sql)
 			}
-			result.Add(rule.Level, msg)
+			result.AddResult(newRuleResult(rule, msg))
 		} else {
 			handler, ok := d.a.ruleToASTHandler[rule.Name]
 			if ok {
-				msg, err := handler(ctx, rule, ast)
+				msg, offset, err := handler(ctx, rule, ast)
 				if err != nil {
 					return nil, errors.Wrapf(err, "audit SQL %s in driver adaptor", sql)
 				}
-				result.Add(rule.Level, msg)
+				item := newRuleResult(rule, msg)
+				item.Position = driver.NewPosition(sql, offset)
+				result.AddResult(item)
 			}
 		}
 	}
@@ -334,6 +434,38 @@ func (d *driverImpl) Audit(ctx context.Context, sql string) (*driver.AuditResult
 	return result, nil
 }
 
+func (d *driverImpl) AuditBatch(ctx context.Context, sqls []string) ([]*driver.AuditResult, error) {
+	results := make([]*driver.AuditResult, 0, len(sqls))
+	for _, sql := range sqls {
+		result, err := d.Audit(ctx, sql)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func newRuleResult(rule *driver.Rule, msg string) *driver.AuditResultItem {
+	return &driver.AuditResultItem{
+		Level:    rule.Level,
+		Message:  msg,
+		RuleName: rule.Name,
+		Category: rule.Category,
+	}
+}
+
 func (d *driverImpl) GenRollbackSQL(ctx context.Context, sql string) (string, string, error) {
-	return "", "", nil
+	if d.a.ao.rollbackSQLGenerator == nil {
+		return "", "", nil
+	}
+	ast, err := d.a.ao.sqlParser(sql)
+	if err != nil {
+		return "", "", errors.Wrap(err, "parse sql")
+	}
+	rollbackSQL, reason, err := d.a.ao.rollbackSQLGenerator(ctx, d.conn, ast)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "generate rollback SQL of %s in driver adaptor", sql)
+	}
+	return rollbackSQL, reason, nil
 }
@@ -0,0 +1,294 @@
+package driver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/pkg/expr"
+	"github.com/actiontech/sqle/sqle/pkg/params"
+
+	"github.com/percona/go-mysql/query"
+	"gopkg.in/yaml.v3"
+)
+
+// ScriptRuleFile is the file of script rules in the script rule directory of
+// plugin, see WithScriptRuleDir(). It can be written in YAML or JSON, such as:
+//
+//	rules:
+//	  - name: pg_update_without_where
+//	    desc: UPDATE 语句必须带 WHERE 条件
+//	    level: warn
+//	    category: DML规范
+//	    expr: type == "dml" && sql =~ "(?i)^update" && sql !~ "(?i)\\bwhere\\b"
+//	    message: "{{.sql}} 没有 WHERE 条件"
+//
+// The rule is evaluated by the expression of package pkg/expr, it is matched
+// if the result is true. The variables of expression are:
+//
+//	sql:         the SQL text
+//	fingerprint: the fingerprint of SQL, the literals are replaced by "?"
+//	type:        the SQL type, "dml" or "ddl"
+//	ast:         the JSON view of AST parsed by the parser of WithSQLParser(),
+//	             it is null if there is no parser
+//	params:      the params of rule, such as params.max_length
+//
+// Message is the text/template of finding message with the variables above,
+// Desc is used if it is empty.
+type ScriptRuleFile struct {
+	Rules []ScriptRule `json:"rules" yaml:"rules"`
+}
+
+type ScriptRule struct {
+	Name     string        `json:"name" yaml:"name"`
+	Desc     string        `json:"desc" yaml:"desc"`
+	Level    string        `json:"level" yaml:"level"`
+	Category string        `json:"category" yaml:"category"`
+	Params   []ScriptParam `json:"params,omitempty" yaml:"params,omitempty"`
+	Expr     string        `json:"expr" yaml:"expr"`
+	Message  string        `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+type ScriptParam struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+	Desc  string `json:"desc" yaml:"desc"`
+	Type  string `json:"type" yaml:"type"`
+}
+
+// scriptRule is the compiled script rule.
+type scriptRule struct {
+	program *expr.Program
+	message *template.Template
+}
+
+var scriptRuleLevels = map[driver.RuleLevel]struct{}{
+	driver.RuleLevelNormal: {},
+	driver.RuleLevelNotice: {},
+	driver.RuleLevelWarn:   {},
+	driver.RuleLevelError:  {},
+}
+
+func (r *ScriptRule) compile() (*driver.Rule, *scriptRule, error) {
+	if r.Name == "" {
+		return nil, nil, fmt.Errorf("name of script rule is required")
+	}
+	if _, ok := scriptRuleLevels[driver.RuleLevel(r.Level)]; !ok {
+		return nil, nil, fmt.Errorf("level %s of script rule %s is invalid", r.Level, r.Name)
+	}
+	ps := make(params.Params, 0, len(r.Params))
+	for _, p := range r.Params {
+		ps = append(ps, &params.Param{
+			Key:   p.Key,
+			Value: p.Value,
+			Desc:  p.Desc,
+			Type:  params.ParamType(p.Type),
+		})
+	}
+	program, err := expr.Compile(r.Expr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("script rule %s: %v", r.Name, err)
+	}
+	sr := &scriptRule{program: program}
+	if r.Message != "" {
+		sr.message, err = template.New(r.Name).Parse(r.Message)
+		if err != nil {
+			return nil, nil, fmt.Errorf("message of script rule %s is invalid: %v", r.Name, err)
+		}
+	}
+	rule := &driver.Rule{
+		Name:     r.Name,
+		Desc:     r.Desc,
+		Category: r.Category,
+		Level:    driver.RuleLevel(r.Level),
+		Params:   ps,
+	}
+	return rule, sr, nil
+}
+
+// defaultScriptRuleDir returns the directory "<plugin executable>.rules", it
+// is in the plugin directory of SQLE, the plugin scanning of SQLE does not
+// enter the subdirectories.
+func defaultScriptRuleDir() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	return exe + ".rules"
+}
+
+// loadScriptRules adds the script rules in the YAML and JSON files of dir,
+// the files are loaded by name. The invalid rule is skipped, so the other
+// rules of plugin are still available.
+func (a *Adaptor) loadScriptRules(dir string) {
+	if dir == "" {
+		return
+	}
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			a.l.Error("failed to read script rule directory", "dir", dir, "err", err)
+		}
+		return
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name() < infos[j].Name() })
+	for _, info := range infos {
+		ext := strings.ToLower(filepath.Ext(info.Name()))
+		if info.IsDir() || (ext != ".yaml" && ext != ".yml" && ext != ".json") {
+			continue
+		}
+		path := filepath.Join(dir, info.Name())
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			a.l.Error("failed to read script rule file", "file", path, "err", err)
+			continue
+		}
+		// JSON is parsed as YAML since it is a subset of YAML.
+		f := &ScriptRuleFile{}
+		if err := yaml.Unmarshal(data, f); err != nil {
+			a.l.Error("failed to parse script rule file", "file", path, "err", err)
+			continue
+		}
+		for i := range f.Rules {
+			rule, sr, err := f.Rules[i].compile()
+			if err != nil {
+				a.l.Error("skip invalid script rule", "file", path, "err", err)
+				continue
+			}
+			if a.hasRule(rule.Name) {
+				a.l.Error("skip duplicate script rule", "file", path, "rule", rule.Name)
+				continue
+			}
+			a.rules = append(a.rules, rule)
+			a.ruleToScript[rule.Name] = sr
+		}
+	}
+	a.l.Info("load script rules", "dir", dir, "count", len(a.ruleToScript))
+}
+
+func (a *Adaptor) hasRule(name string) bool {
+	for _, r := range a.rules {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// scriptEnv is the variables of script rules, the JSON view of AST is only
+// built once for all rules of SQL.
+type scriptEnv struct {
+	sql   string
+	ast   interface{}
+	vars  map[string]interface{}
+	built bool
+}
+
+func (e *scriptEnv) env(rule *driver.Rule) map[string]interface{} {
+	if !e.built {
+		e.built = true
+		e.vars = map[string]interface{}{
+			"sql":         e.sql,
+			"fingerprint": query.Fingerprint(e.sql),
+			"type":        classifySQL(e.sql),
+			"ast":         jsonView(e.ast),
+		}
+	}
+	ps := make(map[string]interface{}, len(rule.Params))
+	for _, p := range rule.Params {
+		ps[p.Key] = p.Value
+	}
+	env := make(map[string]interface{}, len(e.vars)+1)
+	for k, v := range e.vars {
+		env[k] = v
+	}
+	env["params"] = ps
+	return env
+}
+
+// jsonView converts the AST to the values of JSON, the fields of AST are
+// named by their JSON tags or Go names.
+func jsonView(ast interface{}) interface{} {
+	if ast == nil {
+		return nil
+	}
+	data, err := json.Marshal(ast)
+	if err != nil {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil
+	}
+	return v
+}
+
+// exprPrograms caches the compiled expressions of custom rules from SQLE.
+var exprPrograms = sync.Map{}
+
+func compileExpr(src string) (*expr.Program, error) {
+	if p, ok := exprPrograms.Load(src); ok {
+		return p.(*expr.Program), nil
+	}
+	p, err := expr.Compile(src)
+	if err != nil {
+		return nil, err
+	}
+	exprPrograms.Store(src, p)
+	return p, nil
+}
+
+// auditScriptRule evaluates the script rule of plugin or the custom rule of
+// CustomRuleMatchTypeExpr from SQLE. ok is false if rule is not the script
+// rule. The rule which fails to evaluate is skipped with error log, since it
+// is not the fault of SQL.
+func (d *driverImpl) auditScriptRule(rule *driver.Rule, e *scriptEnv) (msg string, ok bool) {
+	var (
+		program *expr.Program
+		message func(env map[string]interface{}) string
+	)
+	if sr, exist := d.a.ruleToScript[rule.Name]; exist {
+		program = sr.program
+		message = func(env map[string]interface{}) string {
+			if sr.message == nil {
+				return rule.Desc
+			}
+			buf := bytes.Buffer{}
+			if err := sr.message.Execute(&buf, env); err != nil {
+				return rule.Desc
+			}
+			return buf.String()
+		}
+	} else if rule.Custom != nil && rule.Custom.MatchType == driver.CustomRuleMatchTypeExpr {
+		var err error
+		program, err = compileExpr(rule.Custom.Expr)
+		if err != nil {
+			d.a.l.Warn("skip invalid custom rule", "rule", rule.Name, "err", err)
+			return "", true
+		}
+		message = func(env map[string]interface{}) string {
+			return rule.Custom.Message(rule, &driver.CustomRuleMessageData{Rule: rule.Name, SQL: e.sql})
+		}
+	} else {
+		return "", false
+	}
+
+	env := e.env(rule)
+	matched, err := program.EvalBool(env)
+	if err != nil {
+		d.a.l.Warn("skip script rule which fails to evaluate", "rule", rule.Name, "err", err)
+		return "", true
+	}
+	if !matched {
+		return "", true
+	}
+	return message(env), true
+}
@@ -0,0 +1,546 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+func (n *literalNode) eval(s *scope) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n *identNode) eval(s *scope) (interface{}, error) {
+	return s.lookup(n.name), nil
+}
+
+func (n *listNode) eval(s *scope) (interface{}, error) {
+	items := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		v, err := item.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+func (n *memberNode) eval(s *scope) (interface{}, error) {
+	target, err := n.target.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := target.(map[string]interface{}); ok {
+		return normalize(m[n.name]), nil
+	}
+	return nil, nil
+}
+
+func (n *indexNode) eval(s *scope) (interface{}, error) {
+	target, err := n.target.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	switch t := target.(type) {
+	case map[string]interface{}:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("map index must be string, but got %s", typeName(index))
+		}
+		return normalize(t[key]), nil
+	case []interface{}:
+		i, ok := index.(float64)
+		if !ok {
+			return nil, fmt.Errorf("list index must be number, but got %s", typeName(index))
+		}
+		if i < 0 {
+			i += float64(len(t))
+		}
+		if i < 0 || int(i) >= len(t) {
+			return nil, nil
+		}
+		return normalize(t[int(i)]), nil
+	}
+	return nil, nil
+}
+
+func (n *callNode) eval(s *scope) (interface{}, error) {
+	if m, ok := macros[n.name]; ok {
+		list, err := n.args[0].eval(s)
+		if err != nil {
+			return nil, err
+		}
+		items, ok := list.([]interface{})
+		if !ok && list != nil {
+			return nil, fmt.Errorf("%s expects list, but got %s", n.name, typeName(list))
+		}
+		return m.fn(items, func(item interface{}) (bool, error) {
+			v, err := n.args[1].eval(&scope{vars: map[string]interface{}{"it": item}, parent: s})
+			return truthy(v), err
+		})
+	}
+	args := make([]interface{}, 0, len(n.args))
+	for _, arg := range n.args {
+		v, err := arg.eval(s)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	v, err := functions[n.name].fn(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", n.name, err)
+	}
+	return v, nil
+}
+
+func (n *unaryNode) eval(s *scope) (interface{}, error) {
+	v, err := n.operand.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "!" {
+		return !truthy(v), nil
+	}
+	f, ok := v.(float64)
+	if !ok {
+		return nil, fmt.Errorf("operator - expects number, but got %s", typeName(v))
+	}
+	return -f, nil
+}
+
+func (n *binaryNode) eval(s *scope) (interface{}, error) {
+	left, err := n.left.eval(s)
+	if err != nil {
+		return nil, err
+	}
+	// && and || are short circuit.
+	switch n.op {
+	case "&&":
+		if !truthy(left) {
+			return false, nil
+		}
+		right, err := n.right.eval(s)
+		return truthy(right), err
+	case "||":
+		if truthy(left) {
+			return true, nil
+		}
+		right, err := n.right.eval(s)
+		return truthy(right), err
+	}
+	right, err := n.right.eval(s)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		return compare(n.op, left, right)
+	case "=~", "!~":
+		text, ok1 := left.(string)
+		pattern, ok2 := right.(string)
+		if !ok1 || !ok2 {
+			if left == nil {
+				return n.op == "!~", nil
+			}
+			return nil, fmt.Errorf("operator %s expects string, but got %s and %s", n.op, typeName(left), typeName(right))
+		}
+		re, err := compileRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(text) == (n.op == "=~"), nil
+	case "in":
+		return contains(right, left)
+	case "+":
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
+		}
+	}
+
+	l, ok1 := left.(float64)
+	r, ok2 := right.(float64)
+	if !ok1 || !ok2 {
+		return nil, fmt.Errorf("operator %s expects number, but got %s and %s", n.op, typeName(left), typeName(right))
+	}
+	switch n.op {
+	case "+":
+		return l + r, nil
+	case "-":
+		return l - r, nil
+	case "*":
+		return l * r, nil
+	case "/":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	case "%":
+		if r == 0 {
+			return nil, fmt.Errorf("division by zero")
+		}
+		return math.Mod(l, r), nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+type function struct {
+	args int
+	fn   func(args []interface{}) (interface{}, error)
+}
+
+var functions = map[string]function{
+	"len": {1, func(args []interface{}) (interface{}, error) {
+		switch v := args[0].(type) {
+		case nil:
+			return float64(0), nil
+		case string:
+			return float64(len([]rune(v))), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		}
+		return nil, fmt.Errorf("expects string, list or map, but got %s", typeName(args[0]))
+	}},
+	"lower":      {1, stringFunc(strings.ToLower)},
+	"upper":      {1, stringFunc(strings.ToUpper)},
+	"trim":       {1, stringFunc(strings.TrimSpace)},
+	"startsWith": {2, stringPredicate(strings.HasPrefix)},
+	"endsWith":   {2, stringPredicate(strings.HasSuffix)},
+	"contains": {2, func(args []interface{}) (interface{}, error) {
+		return contains(args[0], args[1])
+	}},
+	"matches": {2, func(args []interface{}) (interface{}, error) {
+		text, ok1 := args[0].(string)
+		pattern, ok2 := args[1].(string)
+		if !ok1 || !ok2 {
+			return nil, fmt.Errorf("expects string, but got %s and %s", typeName(args[0]), typeName(args[1]))
+		}
+		re, err := compileRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(text), nil
+	}},
+	"keys": {1, func(args []interface{}) (interface{}, error) {
+		m, ok := args[0].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expects map, but got %s", typeName(args[0]))
+		}
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		result := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			result = append(result, k)
+		}
+		return result, nil
+	}},
+	"find": {2, func(args []interface{}) (interface{}, error) {
+		key, ok := args[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("expects string key, but got %s", typeName(args[1]))
+		}
+		result := []interface{}{}
+		find(args[0], key, &result)
+		return result, nil
+	}},
+}
+
+func stringFunc(f func(string) string) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			if args[0] == nil {
+				return "", nil
+			}
+			return nil, fmt.Errorf("expects string, but got %s", typeName(args[0]))
+		}
+		return f(s), nil
+	}
+}
+
+func stringPredicate(f func(string, string) bool) func(args []interface{}) (interface{}, error) {
+	return func(args []interface{}) (interface{}, error) {
+		s, ok1 := args[0].(string)
+		p, ok2 := args[1].(string)
+		if !ok1 || !ok2 {
+			if args[0] == nil {
+				return false, nil
+			}
+			return nil, fmt.Errorf("expects string, but got %s and %s", typeName(args[0]), typeName(args[1]))
+		}
+		return f(s, p), nil
+	}
+}
+
+// find appends the values of key in v to result recursively, the children
+// of found value are also searched.
+func find(v interface{}, key string, result *[]interface{}) {
+	switch t := normalize(v).(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if k == key {
+				*result = append(*result, normalize(t[k]))
+			}
+			find(t[k], key, result)
+		}
+	case []interface{}:
+		for _, item := range t {
+			find(item, key, result)
+		}
+	}
+}
+
+type macro struct {
+	args int
+	fn   func(items []interface{}, pred func(item interface{}) (bool, error)) (interface{}, error)
+}
+
+var macros = map[string]macro{
+	"any": {2, func(items []interface{}, pred func(interface{}) (bool, error)) (interface{}, error) {
+		for _, item := range items {
+			ok, err := pred(normalize(item))
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	}},
+	"all": {2, func(items []interface{}, pred func(interface{}) (bool, error)) (interface{}, error) {
+		for _, item := range items {
+			ok, err := pred(normalize(item))
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	}},
+	"filter": {2, func(items []interface{}, pred func(interface{}) (bool, error)) (interface{}, error) {
+		result := []interface{}{}
+		for _, item := range items {
+			item = normalize(item)
+			ok, err := pred(item)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				result = append(result, item)
+			}
+		}
+		return result, nil
+	}},
+	"count": {2, func(items []interface{}, pred func(interface{}) (bool, error)) (interface{}, error) {
+		count := 0
+		for _, item := range items {
+			ok, err := pred(normalize(item))
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				count++
+			}
+		}
+		return float64(count), nil
+	}},
+}
+
+var regexps sync.Map
+
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := regexps.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexps.Store(pattern, re)
+	return re, nil
+}
+
+// normalize converts the Go numbers and string lists to the values of
+// expression, the other values are expected to be JSON like.
+func normalize(v interface{}) interface{} {
+	switch t := v.(type) {
+	case int:
+		return float64(t)
+	case int32:
+		return float64(t)
+	case int64:
+		return float64(t)
+	case uint:
+		return float64(t)
+	case uint32:
+		return float64(t)
+	case uint64:
+		return float64(t)
+	case float32:
+		return float64(t)
+	case []string:
+		items := make([]interface{}, 0, len(t))
+		for _, s := range t {
+			items = append(items, s)
+		}
+		return items
+	case map[string]string:
+		m := make(map[string]interface{}, len(t))
+		for k, s := range t {
+			m[k] = s
+		}
+		return m
+	}
+	return v
+}
+
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case float64:
+		return t != 0
+	case string:
+		return t != ""
+	case []interface{}:
+		return len(t) > 0
+	case map[string]interface{}:
+		return len(t) > 0
+	}
+	return true
+}
+
+func equal(a, b interface{}) bool {
+	a, b = normalize(a), normalize(b)
+	switch x := a.(type) {
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k := range x {
+			if !equal(x[k], y[k]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func compare(op string, a, b interface{}) (bool, error) {
+	var c int
+	switch x := a.(type) {
+	case float64:
+		y, ok := b.(float64)
+		if !ok {
+			return false, fmt.Errorf("can not compare %s with %s", typeName(a), typeName(b))
+		}
+		switch {
+		case x < y:
+			c = -1
+		case x > y:
+			c = 1
+		}
+	case string:
+		y, ok := b.(string)
+		if !ok {
+			return false, fmt.Errorf("can not compare %s with %s", typeName(a), typeName(b))
+		}
+		c = strings.Compare(x, y)
+	default:
+		return false, fmt.Errorf("can not compare %s with %s", typeName(a), typeName(b))
+	}
+	switch op {
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	}
+	return c >= 0, nil
+}
+
+// contains returns whether the list contains item, the map contains key or
+// the string contains substring.
+func contains(container, item interface{}) (bool, error) {
+	switch c := container.(type) {
+	case nil:
+		return false, nil
+	case []interface{}:
+		for _, v := range c {
+			if equal(v, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case map[string]interface{}:
+		key, ok := item.(string)
+		if !ok {
+			return false, fmt.Errorf("map key must be string, but got %s", typeName(item))
+		}
+		_, ok = c[key]
+		return ok, nil
+	case string:
+		sub, ok := item.(string)
+		if !ok {
+			return false, fmt.Errorf("substring must be string, but got %s", typeName(item))
+		}
+		return strings.Contains(c, sub), nil
+	}
+	return false, fmt.Errorf("expects list, map or string, but got %s", typeName(container))
+}
+
+func typeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "bool"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "map"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,80 @@
+// Package expr is a small expression language which is evaluated over the
+// JSON like values, such as the JSON view of parsed SQL. It is used by the
+// script rules of plugins, so the rules can be added without recompiling.
+//
+// The values are null, bool, number (float64), string, list and map. The
+// expression supports:
+//
+//	literals:    null, true, false, 10, 1.5, "text", 'text', [1, 2]
+//	variables:   sql, ast.where, ast["where"], ast.tables[0]
+//	operators:   || && == != < <= > >= =~ !~ in + - * / % ! (by precedence)
+//	functions:   len(x), lower(s), upper(s), trim(s), contains(x, v),
+//	             startsWith(s, p), endsWith(s, p), matches(s, re), keys(m),
+//	             find(x, key)
+//	macros:      any(list, pred), all(list, pred), filter(list, pred),
+//	             count(list, pred), pred is evaluated with the item as "it"
+//
+// The missing field, key or index is null instead of error, so the optional
+// part of statement can be checked by "ast.where == null". "=~" matches the
+// regular expression, "in" checks the item of list, the key of map or the
+// substring of string. find returns all values of the key in x recursively,
+// it is useful to find the nodes of syntax tree, such as the table names.
+package expr
+
+import (
+	"fmt"
+)
+
+// Program is the compiled expression, it is safe for concurrent use.
+type Program struct {
+	src  string
+	root node
+}
+
+// Compile parses the expression.
+func Compile(src string) (*Program, error) {
+	root, err := parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("compile expression %q: %v", src, err)
+	}
+	return &Program{src: src, root: root}, nil
+}
+
+func (p *Program) String() string {
+	return p.src
+}
+
+// Eval evaluates the expression with the variables of env.
+func (p *Program) Eval(env map[string]interface{}) (interface{}, error) {
+	v, err := p.root.eval(&scope{vars: env})
+	if err != nil {
+		return nil, fmt.Errorf("evaluate expression %q: %v", p.src, err)
+	}
+	return v, nil
+}
+
+// EvalBool evaluates the expression and returns the truth of result, null,
+// false, 0, "" and the empty list or map are false.
+func (p *Program) EvalBool(env map[string]interface{}) (bool, error) {
+	v, err := p.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	return truthy(v), nil
+}
+
+// scope is the variables of expression, the macro evaluates its predicate in
+// the child scope with the variable "it".
+type scope struct {
+	vars   map[string]interface{}
+	parent *scope
+}
+
+func (s *scope) lookup(name string) interface{} {
+	for ; s != nil; s = s.parent {
+		if v, ok := s.vars[name]; ok {
+			return normalize(v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package expr
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testAST = `{
+	"type": "update",
+	"tables": [{"name": "orders", "schema": "shop"}, {"name": "users"}],
+	"where": {"op": "=", "left": {"column": "id"}, "right": {"value": 1}},
+	"limit": null,
+	"columns": ["status", "updated_at"]
+}`
+
+func TestEval(t *testing.T) {
+	ast := map[string]interface{}{}
+	if !assert.NoError(t, json.Unmarshal([]byte(testAST), &ast)) {
+		t.FailNow()
+	}
+	env := map[string]interface{}{
+		"sql":    "UPDATE orders SET status = 1 WHERE id = 1",
+		"ast":    ast,
+		"params": map[string]string{"max": "10"},
+		"count":  3,
+	}
+	for src, expected := range map[string]interface{}{
+		`1 + 2 * 3`:                                             float64(7),
+		`(1 + 2) * 3 % 4`:                                       float64(1),
+		`-count + 1`:                                            float64(-2),
+		`"a" + 'b'`:                                             "ab",
+		`ast.type == "update"`:                                  true,
+		`ast.type != "update" || count >= 3`:                    true,
+		`ast.where != null && ast.limit == null`:                true,
+		`ast.tables[0].name`:                                    "orders",
+		`ast.tables[-1]["name"]`:                                "users",
+		`ast.tables[5].name`:                                    nil,
+		`ast.missing.field`:                                     nil,
+		`len(ast.tables)`:                                       float64(2),
+		`sql =~ "(?i)^update\\s+orders"`:                        true,
+		`sql !~ "(?i)limit"`:                                    true,
+		`"status" in ast.columns`:                               true,
+		`"where" in ast`:                                        true,
+		`"SET" in sql`:                                          true,
+		`any(ast.tables, it.name == "users")`:                   true,
+		`all(ast.tables, startsWith(it.name, "o"))`:             false,
+		`count(ast.tables, it.schema == null)`:                  float64(1),
+		`filter(ast.tables, it.schema != null)[0].name`:         "orders",
+		`find(ast, "column")`:                                   []interface{}{"id"},
+		`find(ast, "name")`:                                     []interface{}{"orders", "users"},
+		`keys(ast.tables[0])`:                                   []interface{}{"name", "schema"},
+		`lower("ABC") == "abc" && upper(trim(" a ")) == "A"`:    true,
+		`contains(ast.columns, "status") && endsWith(sql, "1")`: true,
+		`matches(ast.tables[0].name, "^ord")`:                   true,
+		`params.max == "10"`:                                    true,
+		`[1, "a", null] == [1, "a", null]`:                      true,
+		`!ast.limit`:                                            true,
+		`'it\'s'`:                                               "it's",
+	} {
+		p, err := Compile(src)
+		if !assert.NoError(t, err, src) {
+			continue
+		}
+		actual, err := p.Eval(env)
+		assert.NoError(t, err, src)
+		assert.Equal(t, expected, actual, src)
+	}
+
+	ok, err := mustCompile(t, `ast.where`).EvalBool(env)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = mustCompile(t, `ast.tables[0].missing`).EvalBool(env)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	for _, src := range []string{
+		`1 + "a"`,
+		`1 / 0`,
+		`ast < 1`,
+		`ast.tables["a"]`,
+		`sql =~ "("`,
+		`len(1)`,
+		`any(sql, it)`,
+	} {
+		_, err := mustCompile(t, src).Eval(env)
+		assert.Error(t, err, src)
+	}
+}
+
+func TestCompile(t *testing.T) {
+	for _, src := range []string{
+		``,
+		`1 +`,
+		`(1`,
+		`[1, 2`,
+		`a.`,
+		`a.1`,
+		`"abc`,
+		`a # b`,
+		`unknown(1)`,
+		`len(1, 2)`,
+		`any(a)`,
+		`1 2`,
+	} {
+		_, err := Compile(src)
+		assert.Error(t, err, src)
+	}
+}
+
+func mustCompile(t *testing.T, src string) *Program {
+	p, err := Compile(src)
+	if !assert.NoError(t, err, src) {
+		t.FailNow()
+	}
+	return p
+}
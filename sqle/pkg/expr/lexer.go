@@ -0,0 +1,116 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	// value is the parsed value of number and string.
+	value interface{}
+	pos   int
+}
+
+// operators are sorted by length, so the longest operator is matched first.
+var operators = []string{
+	"||", "&&", "==", "!=", "<=", ">=", "=~", "!~",
+	"<", ">", "+", "-", "*", "/", "%", "!", "(", ")", "[", "]", ",", ".",
+}
+
+func tokenize(src string) ([]token, error) {
+	tokens := []token{}
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(src) && (src[i] == '_' || unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i]))) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: src[start:i], pos: start})
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			v, err := strconv.ParseFloat(src[start:i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %s at %d", src[start:i], start)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: src[start:i], value: v, pos: start})
+		case c == '"' || c == '\'':
+			start := i
+			s, n, err := scanString(src[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%v at %d", err, start)
+			}
+			i += n
+			tokens = append(tokens, token{kind: tokenString, text: src[start:i], value: s, pos: start})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(src[i:], op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at %d", c, i)
+			}
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(src)}), nil
+}
+
+// scanString scans the quoted string at the beginning of src, it returns the
+// unquoted string and the length of quoted string.
+func scanString(src string) (string, int, error) {
+	quote := src[0]
+	buf := strings.Builder{}
+	for i := 1; i < len(src); i++ {
+		switch src[i] {
+		case quote:
+			return buf.String(), i + 1, nil
+		case '\\':
+			i++
+			if i >= len(src) {
+				break
+			}
+			switch src[i] {
+			case 'n':
+				buf.WriteByte('\n')
+			case 't':
+				buf.WriteByte('\t')
+			case 'r':
+				buf.WriteByte('\r')
+			case '\\', '"', '\'':
+				buf.WriteByte(src[i])
+			default:
+				// the other escapes are kept, such as "\s" of regular expression.
+				buf.WriteByte('\\')
+				buf.WriteByte(src[i])
+			}
+		default:
+			buf.WriteByte(src[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
@@ -0,0 +1,251 @@
+package expr
+
+import (
+	"fmt"
+)
+
+// node is the node of syntax tree, it is evaluated in scope.
+type node interface {
+	eval(s *scope) (interface{}, error)
+}
+
+type (
+	literalNode struct {
+		value interface{}
+	}
+	identNode struct {
+		name string
+	}
+	listNode struct {
+		items []node
+	}
+	memberNode struct {
+		target node
+		name   string
+	}
+	indexNode struct {
+		target node
+		index  node
+	}
+	callNode struct {
+		name string
+		args []node
+	}
+	unaryNode struct {
+		op      string
+		operand node
+	}
+	binaryNode struct {
+		op          string
+		left, right node
+	}
+)
+
+// binaryPrecedence is the precedence of binary operators, the operator with
+// higher precedence binds tighter.
+var binaryPrecedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3, "=~": 3, "!~": 3, "in": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func parse(src string) (node, error) {
+	tokens, err := tokenize(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+	n, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at %d", t.text, t.pos)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isOperator(op string) bool {
+	t := p.peek()
+	return t.kind == tokenOperator && t.text == op
+}
+
+func (p *parser) expect(op string) error {
+	t := p.next()
+	if t.kind != tokenOperator || t.text != op {
+		if t.kind == tokenEOF {
+			return fmt.Errorf("expect %s, but got end of expression", op)
+		}
+		return fmt.Errorf("expect %s, but got %s at %d", op, t.text, t.pos)
+	}
+	return nil
+}
+
+// binaryOperator returns the binary operator of next token, "in" is the only
+// binary operator which is an identifier.
+func (p *parser) binaryOperator() (string, bool) {
+	t := p.peek()
+	if t.kind == tokenOperator || (t.kind == tokenIdent && t.text == "in") {
+		_, ok := binaryPrecedence[t.text]
+		return t.text, ok
+	}
+	return "", false
+}
+
+// parseBinary parses the binary expression whose operators have precedence
+// not less than minPrecedence, the operators are left associative.
+func (p *parser) parseBinary(minPrecedence int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.binaryOperator()
+		if !ok || binaryPrecedence[op] < minPrecedence {
+			return left, nil
+		}
+		p.next()
+		right, err := p.parseBinary(binaryPrecedence[op] + 1)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.isOperator("!") || p.isOperator("-") {
+		op := p.next().text
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePostfix()
+}
+
+func (p *parser) parsePostfix() (node, error) {
+	n, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.isOperator("."):
+			p.next()
+			t := p.next()
+			if t.kind != tokenIdent {
+				return nil, fmt.Errorf("expect field name after . at %d", t.pos)
+			}
+			n = &memberNode{target: n, name: t.text}
+		case p.isOperator("["):
+			p.next()
+			index, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			n = &indexNode{target: n, index: index}
+		default:
+			return n, nil
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenNumber, tokenString:
+		return &literalNode{value: t.value}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null", "nil":
+			return &literalNode{value: nil}, nil
+		}
+		if !p.isOperator("(") {
+			return &identNode{name: t.text}, nil
+		}
+		p.next()
+		argsCount := 0
+		if f, ok := functions[t.text]; ok {
+			argsCount = f.args
+		} else if m, ok := macros[t.text]; ok {
+			argsCount = m.args
+		} else {
+			return nil, fmt.Errorf("unknown function %s at %d", t.text, t.pos)
+		}
+		args, err := p.parseList(")")
+		if err != nil {
+			return nil, err
+		}
+		if len(args) != argsCount {
+			return nil, fmt.Errorf("function %s expects %d arguments, but got %d", t.text, argsCount, len(args))
+		}
+		return &callNode{name: t.text, args: args}, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			n, err := p.parseBinary(1)
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{items: items}, nil
+		}
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %s at %d", t.text, t.pos)
+}
+
+// parseList parses the comma separated expressions until the end operator.
+func (p *parser) parseList(end string) ([]node, error) {
+	items := []node{}
+	if p.isOperator(end) {
+		p.next()
+		return items, nil
+	}
+	for {
+		item, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if p.isOperator(",") {
+			p.next()
+			continue
+		}
+		return items, p.expect(end)
+	}
+}
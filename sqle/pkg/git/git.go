@@ -0,0 +1,130 @@
+// Package git reads the local git repository by the git command, the
+// repository can be a working copy or a bare mirror.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type Repository struct {
+	path string
+}
+
+func NewRepository(path string) (*Repository, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, errors.Wrap(err, "git command is not found")
+	}
+	r := &Repository{path: path}
+	if _, err := r.run("rev-parse", "--git-dir"); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Commit is the commit which changes a file last time.
+type Commit struct {
+	Hash   string
+	Author string
+	Time   time.Time
+}
+
+func (r *Repository) run(args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", r.path}, args...)...)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %v, %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// ResolveCommit returns the commit hash of revision, such as branch name. The
+// revision is given by user, it must be a valid ref name.
+func (r *Repository) ResolveCommit(rev string) (string, error) {
+	if _, err := r.run("check-ref-format", "--allow-onelevel", rev); err != nil {
+		return "", fmt.Errorf("revision %s is invalid", rev)
+	}
+	out, err := r.run("rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// HasCommit returns true if the commit exists in repository.
+func (r *Repository) HasCommit(commit string) bool {
+	_, err := r.run("cat-file", "-e", "--end-of-options", commit+"^{commit}")
+	return err == nil
+}
+
+// ListFiles returns the path of all files in the commit.
+func (r *Repository) ListFiles(commit string) ([]string, error) {
+	out, err := r.run("ls-tree", "-r", "-z", "--name-only", "--end-of-options", commit)
+	if err != nil {
+		return nil, err
+	}
+	return splitNul(out), nil
+}
+
+// ChangedFiles returns the path of files which are added or modified from
+// commit "from" to commit "to", the deleted files are ignored.
+func (r *Repository) ChangedFiles(from, to string) ([]string, error) {
+	out, err := r.run("diff", "--name-only", "-z", "--no-renames", "--diff-filter=AM", "--end-of-options", from, to)
+	if err != nil {
+		return nil, err
+	}
+	return splitNul(out), nil
+}
+
+// ReadFile returns the content of file in the commit, exist is false if the
+// file is not in the commit.
+func (r *Repository) ReadFile(commit, path string) (content string, exist bool, err error) {
+	if _, err := r.run("cat-file", "-e", "--end-of-options", commit+":"+path); err != nil {
+		return "", false, nil
+	}
+	out, err := r.run("cat-file", "blob", "--end-of-options", commit+":"+path)
+	if err != nil {
+		return "", false, err
+	}
+	return out, true, nil
+}
+
+// LastCommit returns the last commit which changes the file before commit
+// "to", the commits before commit "from" are ignored if "from" is not empty.
+func (r *Repository) LastCommit(from, to, path string) (*Commit, error) {
+	rev := to
+	if from != "" {
+		rev = from + ".." + to
+	}
+	out, err := r.run("log", "-1", "--format=%H%x00%an <%ae>%x00%ct", "--end-of-options", rev, "--", path)
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Split(strings.TrimSpace(out), "\x00")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("no commit changes %s in %s", path, rev)
+	}
+	commit := &Commit{Hash: fields[0], Author: fields[1]}
+	if sec, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+		commit.Time = time.Unix(sec, 0)
+	}
+	return commit, nil
+}
+
+func splitNul(out string) []string {
+	files := []string{}
+	for _, f := range strings.Split(out, "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return files
+}
@@ -0,0 +1,105 @@
+package git
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRepository creates a git repository in temp directory, the files
+// of each commit are written in order.
+func newTestRepository(t *testing.T, commits ...map[string]string) (string, []string) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git command is not found")
+	}
+	dir, err := ioutil.TempDir("", "sqle_git_test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	run := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=tester", "GIT_AUTHOR_EMAIL=tester@example.com",
+			"GIT_COMMITTER_NAME=tester", "GIT_COMMITTER_EMAIL=tester@example.com")
+		out, err := cmd.CombinedOutput()
+		if !assert.NoError(t, err, string(out)) {
+			t.FailNow()
+		}
+		return string(out)
+	}
+	run("init", "-q")
+	hashes := []string{}
+	for _, files := range commits {
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			if !assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755)) {
+				t.FailNow()
+			}
+			if !assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644)) {
+				t.FailNow()
+			}
+		}
+		run("add", "-A")
+		run("commit", "-q", "-m", "test")
+		hashes = append(hashes, run("rev-parse", "HEAD")[:40])
+	}
+	return dir, hashes
+}
+
+func TestRepository(t *testing.T) {
+	dir, hashes := newTestRepository(t,
+		map[string]string{"a.sql": "select 1;", "db/b.sql": "select 2;"},
+		map[string]string{"db/b.sql": "select 3;", "c.xml": "<mapper/>"},
+	)
+	r, err := NewRepository(dir)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+
+	head, err := r.ResolveCommit("HEAD")
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[1], head)
+	_, err = r.ResolveCommit("not-exist-branch")
+	assert.Error(t, err)
+	output := filepath.Join(dir, "output")
+	_, err = r.ResolveCommit("--output=" + output)
+	assert.Error(t, err)
+	_, err = os.Stat(output)
+	assert.True(t, os.IsNotExist(err))
+	_, err = r.ResolveCommit("HEAD..HEAD")
+	assert.Error(t, err)
+	assert.True(t, r.HasCommit(hashes[0]))
+	assert.False(t, r.HasCommit("0000000000000000000000000000000000000000"))
+
+	files, err := r.ListFiles(hashes[0])
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a.sql", "db/b.sql"}, files)
+
+	files, err = r.ChangedFiles(hashes[0], hashes[1])
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"c.xml", "db/b.sql"}, files)
+
+	content, exist, err := r.ReadFile(hashes[0], "db/b.sql")
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	assert.Equal(t, "select 2;", content)
+	_, exist, err = r.ReadFile(hashes[0], "c.xml")
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	commit, err := r.LastCommit("", hashes[1], "a.sql")
+	assert.NoError(t, err)
+	assert.Equal(t, hashes[0], commit.Hash)
+	assert.Equal(t, "tester <tester@example.com>", commit.Author)
+	_, err = r.LastCommit(hashes[0], hashes[1], "a.sql")
+	assert.Error(t, err)
+
+	_, err = NewRepository(filepath.Join(dir, "not-exist-directory"))
+	assert.Error(t, err)
+}
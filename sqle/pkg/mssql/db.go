@@ -0,0 +1,83 @@
+package mssql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+
+	"github.com/pkg/errors"
+
+	_ "github.com/denisenkom/go-mssqldb"
+)
+
+type DSN struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Database string
+}
+
+func (d *DSN) String() string {
+	return fmt.Sprintf("%s:%s", d.Host, d.Port)
+}
+
+type DB struct {
+	db *sql.DB
+}
+
+func NewDB(dsn *DSN) (*DB, error) {
+	u := &url.URL{
+		Scheme: "sqlserver",
+		User:   url.UserPassword(dsn.User, dsn.Password),
+		Host:   fmt.Sprintf("%s:%s", dsn.Host, dsn.Port),
+	}
+	if dsn.Database != "" {
+		u.RawQuery = url.Values{"database": {dsn.Database}}.Encode()
+	}
+
+	sqlDB, err := sql.Open("sqlserver", u.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to %s", dsn.String())
+	}
+	err = sqlDB.Ping()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to ping %s", dsn.String())
+	}
+
+	return &DB{db: sqlDB}, nil
+}
+
+func (o *DB) Close() error {
+	return o.db.Close()
+}
+
+func (o *DB) QueryTopSQLs(ctx context.Context, topN int, orderBy string) ([]*DynExecQueryStats, error) {
+	if _, ok := DynExecQueryStatsOrderByColumns[orderBy]; !ok {
+		return nil, fmt.Errorf("unsupported order by column %s of sys.dm_exec_query_stats", orderBy)
+	}
+	query := fmt.Sprintf(DynExecQueryStatsTpl, topN, orderBy)
+	rows, err := o.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query %s", query)
+	}
+	defer rows.Close()
+
+	var ret []*DynExecQueryStats
+	for rows.Next() {
+		res := DynExecQueryStats{}
+		err = rows.Scan(&res.SQLText, &res.ExecutionCount, &res.TotalElapsedTime, &res.TotalWorkerTime,
+			&res.TotalLogicalReads, &res.TotalPhysicalReads, &res.TotalLogicalWrites)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to scan %s", query)
+		}
+		ret = append(ret, &res)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to iterate %s", query)
+	}
+
+	return ret, nil
+}
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/pkg/tokenizer"
+)
+
+type TokenType = tokenizer.TokenType
+
+// Token is the token of T-SQL, the unquoted identifier is folded to upper
+// case, TokenQuotedIdent includes [identifier] and TokenNumber includes the
+// binary literal such as 0x1F.
+type Token = tokenizer.Token
+
+const (
+	TokenEOF         = tokenizer.TokenEOF
+	TokenIdent       = tokenizer.TokenIdent
+	TokenQuotedIdent = tokenizer.TokenQuotedIdent
+	TokenString      = tokenizer.TokenString
+	TokenNumber      = tokenizer.TokenNumber
+	// TokenVariable is local variable or system function, such as @id or @@ROWCOUNT.
+	TokenVariable = tokenizer.TokenVariable
+	TokenOperator = tokenizer.TokenOperator
+	TokenPunct    = tokenizer.TokenPunct
+	// TokenGo is the batch separator "GO" in a line alone, it is not T-SQL
+	// statement but the command of sqlcmd and SSMS. The value is "GO n" if the
+	// batch is repeated n times.
+	TokenGo = tokenizer.TokenDelimiter
+)
+
+var dialect = &tokenizer.Dialect{
+	FoldIdent: strings.ToUpper,
+	// "#" starts the name of temporary table.
+	IdentStart:    "_#",
+	IdentPart:     "_$#@",
+	NestedComment: true,
+	Puncts:        "(),;.",
+	Operators:     "+-*/%<>=!^~|&:",
+	TwoCharOperators: []string{
+		"<=", ">=", "<>", "!=", "!<", "!>", "+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "::",
+	},
+	Scan: scan,
+}
+
+func Tokenize(sql string) ([]Token, error) {
+	return tokenizer.Tokenize(sql, dialect)
+}
+
+func scan(s *tokenizer.Scanner) (Token, bool, error) {
+	start := s.Pos()
+	sql := s.SQL()
+	c := s.Peek(0)
+	switch {
+	case (c == 'n' || c == 'N') && s.Peek(1) == '\'':
+		s.Skip(1)
+		t, err := s.ScanQuoted(start, TokenString, '\'', false)
+		return t, true, err
+	case c == '[':
+		t, err := s.ScanQuoted(start, TokenQuotedIdent, ']', false)
+		return t, true, err
+	case c == '@' && (s.Peek(1) == '@' || s.IsIdentPart(sql[start+1:])):
+		s.Skip(1)
+		if s.Peek(0) == '@' {
+			s.Skip(1)
+		}
+		s.ScanIdent()
+		return s.Token(TokenVariable, strings.ToUpper(sql[start:s.Pos()]), start), true, nil
+	case c == '0' && (s.Peek(1) == 'x' || s.Peek(1) == 'X'):
+		s.Skip(2)
+		for isHexDigit(s.Peek(0)) {
+			s.Skip(1)
+		}
+		return s.Token(TokenNumber, sql[start:s.Pos()], start), true, nil
+	case strings.EqualFold(sql[start:minInt(start+2, len(sql))], "GO") &&
+		!s.IsIdentPart(sql[start+2:]) && isBatchSeparator(s, start, start+2):
+		s.Skip(2)
+		for c := s.Peek(0); c == ' ' || c == '\t' || isDigit(c); c = s.Peek(0) {
+			s.Skip(1)
+		}
+		value := "GO"
+		if count := strings.TrimSpace(sql[start+2 : s.Pos()]); count != "" {
+			value = value + " " + count
+		}
+		return s.Token(TokenGo, value, start), true, nil
+	}
+	return Token{}, false, nil
+}
+
+// isBatchSeparator returns true if the "GO" at pos is in a line alone, the
+// optional count such as "GO 5" and the trailing comment are allowed.
+func isBatchSeparator(s *tokenizer.Scanner, pos, end int) bool {
+	if !s.IsLineStart(pos) {
+		return false
+	}
+	rest := s.SQL()[end:]
+	if i := strings.IndexByte(rest, '\n'); i >= 0 {
+		rest = rest[:i]
+	}
+	if i := strings.Index(rest, "--"); i >= 0 {
+		rest = rest[:i]
+	}
+	rest = strings.TrimSpace(rest)
+	for i := 0; i < len(rest); i++ {
+		if !isDigit(rest[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isHexDigit(c byte) bool {
+	return isDigit(c) || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,339 @@
+package parser
+
+import (
+	"fmt"
+)
+
+// Stmt is a parsed T-SQL statement. A batch which creates procedure, function,
+// trigger or view is one statement, since it must be alone in the batch.
+type Stmt struct {
+	// Text is the raw SQL text of statement, without the trailing ";".
+	Text string
+	// Keyword is the leading keyword in upper case, such as "SELECT".
+	Keyword string
+	// SelectStar is true if "*" or "t.*" is in the select list of any query
+	// block in statement, except the sub query of EXISTS.
+	SelectStar bool
+	// SelectStarPos is the byte offset of the first "*" or "t.*" in SQL text,
+	// it is valid only if SelectStar is true.
+	SelectStarPos int
+	// NoLocks is the hints and isolation level which read uncommitted data,
+	// such as "NOLOCK" and "READ UNCOMMITTED".
+	NoLocks []string
+	// MissingWhere is true if there is UPDATE or DELETE without WHERE in statement.
+	MissingWhere bool
+	// MissingWherePos is the byte offset of the first UPDATE or DELETE without
+	// WHERE in SQL text, it is valid only if MissingWhere is true.
+	MissingWherePos int
+	// Cursors is the names of declared cursors in statement.
+	Cursors []string
+}
+
+// Split splits sql into statements. The batches are separated by "GO", and the
+// batch followed by "GO n" is repeated n times. The statements in batch are
+// separated by ";" or the keyword which starts another statement, the
+// statements in BEGIN...END and the body of IF or WHILE are not separated.
+func Split(sql string) ([]string, error) {
+	tokens, err := Tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	groups := splitTokens(tokens)
+	sqls := make([]string, 0, len(groups))
+	for _, group := range groups {
+		sqls = append(sqls, text(sql, group))
+	}
+	return sqls, nil
+}
+
+// Parse parses sql into statements.
+func Parse(sql string) ([]*Stmt, error) {
+	tokens, err := Tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	groups := splitTokens(tokens)
+	stmts := make([]*Stmt, 0, len(groups))
+	for _, group := range groups {
+		stmts = append(stmts, parseStmt(sql, group))
+	}
+	return stmts, nil
+}
+
+// ParseOne parses sql which has one statement exactly.
+func ParseOne(sql string) (*Stmt, error) {
+	stmts, err := Parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(stmts) != 1 {
+		return nil, fmt.Errorf("expected one statement, but got %d", len(stmts))
+	}
+	return stmts[0], nil
+}
+
+func text(sql string, tokens []Token) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return sql[tokens[0].Pos:tokens[len(tokens)-1].End]
+}
+
+var moduleTypes = []string{"PROCEDURE", "PROC", "FUNCTION", "TRIGGER", "VIEW"}
+
+// isModule returns true if the batch creates or alters procedure, function,
+// trigger or view, the ";" in it does not separate statements.
+func isModule(tokens []Token) bool {
+	if len(tokens) < 2 || !keywordIn("CREATE", "ALTER")(tokens[0]) {
+		return false
+	}
+	i := 1
+	if tokens[0].IsKeyword("CREATE") && len(tokens) > 3 && tokens[1].IsKeyword("OR") && tokens[2].IsKeyword("ALTER") {
+		i = 3
+	}
+	return keywordIn(moduleTypes...)(tokens[i])
+}
+
+// isBlockBegin returns true if tokens[i] begins BEGIN...END or CASE...END.
+func isBlockBegin(tokens []Token, i int) bool {
+	if tokens[i].IsKeyword("CASE") {
+		return true
+	}
+	if !tokens[i].IsKeyword("BEGIN") {
+		return false
+	}
+	// BEGIN TRANSACTION is not a block.
+	return i+1 >= len(tokens) || !keywordIn("TRAN", "TRANSACTION", "DISTRIBUTED", "DIALOG", "CONVERSATION")(tokens[i+1])
+}
+
+func isBlockEnd(tokens []Token, i int) bool {
+	return tokens[i].IsKeyword("END") && (i+1 >= len(tokens) || !tokens[i+1].IsKeyword("CONVERSATION"))
+}
+
+func keywordIn(kws ...string) func(t Token) bool {
+	return func(t Token) bool {
+		for _, kw := range kws {
+			if t.IsKeyword(kw) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// statementStart is the keywords which start a new statement, it is used to
+// find the end of statement if ";" is omitted.
+var statementStart = keywordIn("SELECT", "INSERT", "UPDATE", "DELETE", "MERGE", "DECLARE", "IF", "WHILE",
+	"BEGIN", "END", "RETURN", "EXEC", "EXECUTE", "PRINT", "RAISERROR", "THROW", "COMMIT", "ROLLBACK", "CREATE",
+	"ALTER", "DROP", "TRUNCATE", "FETCH", "OPEN", "CLOSE", "DEALLOCATE", "GOTO", "WAITFOR", "USE", "ELSE", "WITH",
+	"SET", "GRANT", "DENY", "REVOKE", "BREAK", "CONTINUE", "SAVE", "DBCC")
+
+func parseStmt(sql string, tokens []Token) *Stmt {
+	s := &Stmt{Text: text(sql, tokens)}
+	if len(tokens) > 0 && tokens[0].Type == TokenIdent {
+		s.Keyword = tokens[0].Value
+	}
+	for i, t := range tokens {
+		switch {
+		case t.IsKeyword("SELECT"):
+			if pos, ok := selectStarPos(tokens, i); ok && !s.SelectStar {
+				s.SelectStar, s.SelectStarPos = true, pos
+			}
+		case keywordIn("UPDATE", "DELETE")(t):
+			if isDMLStart(tokens, i) && !hasWhere(tokens, i) && !s.MissingWhere {
+				s.MissingWhere, s.MissingWherePos = true, t.Pos
+			}
+		case keywordIn("NOLOCK", "READUNCOMMITTED")(t):
+			// the table hint, such as "WITH (NOLOCK)" or "(NOLOCK)".
+			if i > 0 && (tokens[i-1].IsPunct("(") || tokens[i-1].IsPunct(",")) {
+				s.NoLocks = appendUnique(s.NoLocks, t.Value)
+			}
+		case t.IsKeyword("LEVEL"):
+			if i+2 < len(tokens) && tokens[i+1].IsKeyword("READ") && tokens[i+2].IsKeyword("UNCOMMITTED") {
+				s.NoLocks = appendUnique(s.NoLocks, "READ UNCOMMITTED")
+			}
+		case t.IsKeyword("CURSOR"):
+			if name := cursorName(sql, tokens, i); name != "" {
+				s.Cursors = append(s.Cursors, name)
+			}
+		}
+	}
+	return s
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, l := range list {
+		if l == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// clause returns the tokens after tokens[start] until stop returns true for
+// the token in the same parentheses, or the parentheses is closed.
+func clause(tokens []Token, start int, stop func(t Token) bool) []Token {
+	depth := 0
+	i := start + 1
+	for ; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.IsPunct("(") {
+			depth++
+		} else if t.IsPunct(")") {
+			depth--
+			if depth < 0 {
+				break
+			}
+		} else if depth == 0 && (t.IsPunct(";") || stop(t)) {
+			break
+		}
+	}
+	return tokens[start+1 : i]
+}
+
+// splitByComma splits tokens by "," which is not in parentheses.
+func splitByComma(tokens []Token) [][]Token {
+	var items [][]Token
+	depth, start := 0, 0
+	for i, t := range tokens {
+		switch {
+		case t.IsPunct("("):
+			depth++
+		case t.IsPunct(")"):
+			depth--
+		case t.IsPunct(",") && depth == 0:
+			items = append(items, tokens[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		items = append(items, tokens[start:])
+	}
+	return items
+}
+
+var selectListEnd = keywordIn("FROM", "INTO", "WHERE", "GROUP", "HAVING", "ORDER", "UNION", "EXCEPT",
+	"INTERSECT", "OPTION", "FOR")
+
+// selectStarPos returns the offset of "*" item in the select list of SELECT at
+// tokens[i], ok is false if there is no "*" item.
+func selectStarPos(tokens []Token, i int) (pos int, ok bool) {
+	// "EXISTS (SELECT * ...)" does not read the columns.
+	if i > 1 && tokens[i-1].IsPunct("(") && tokens[i-2].IsKeyword("EXISTS") {
+		return 0, false
+	}
+	list := clause(tokens, i, selectListEnd)
+	for len(list) > 0 {
+		switch {
+		case keywordIn("DISTINCT", "ALL", "PERCENT")(list[0]):
+			list = list[1:]
+		case list[0].IsKeyword("WITH") && len(list) > 1 && list[1].IsKeyword("TIES"):
+			list = list[2:]
+		case list[0].IsKeyword("TOP") && len(list) > 1:
+			// "TOP n" or "TOP (expression)".
+			if list[1].IsPunct("(") {
+				list = list[skipParens(list, 1):]
+			} else {
+				list = list[2:]
+			}
+		default:
+			for _, item := range splitByComma(list) {
+				if isStarItem(item) {
+					return item[0].Pos, true
+				}
+			}
+			return 0, false
+		}
+	}
+	return 0, false
+}
+
+// isStarItem returns true if item is "*", "t.*" or "s.t.*".
+func isStarItem(item []Token) bool {
+	if len(item) == 0 || !item[len(item)-1].Is(TokenOperator, "*") {
+		return false
+	}
+	for i := len(item) - 2; i >= 0; i -= 2 {
+		if !item[i].IsPunct(".") || i == 0 || !item[i-1].IsName() {
+			return false
+		}
+		if i == 1 {
+			return true
+		}
+	}
+	return len(item) == 1
+}
+
+// isDMLStart returns true if the UPDATE or DELETE at tokens[i] starts a
+// statement, it is not a part of trigger, permission, MERGE or cursor.
+func isDMLStart(tokens []Token, i int) bool {
+	if i+1 < len(tokens) && (tokens[i+1].IsKeyword("STATISTICS") || tokens[i+1].IsPunct("(")) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	prev := tokens[i-1]
+	return !prev.IsPunct(",") && !prev.IsPunct("(") &&
+		!keywordIn("ON", "FOR", "AFTER", "OF", "THEN", "GRANT", "DENY", "REVOKE", "ALL")(prev)
+}
+
+// skipParens returns the index after the parentheses which begins at tokens[i].
+func skipParens(tokens []Token, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		if tokens[i].IsPunct("(") {
+			depth++
+		} else if tokens[i].IsPunct(")") {
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+// hasWhere returns true if the UPDATE or DELETE at tokens[i] has WHERE, the
+// statement ends at ";" or the keyword which starts another statement.
+func hasWhere(tokens []Token, i int) bool {
+	setClause := tokens[i].IsKeyword("UPDATE")
+	caseDepth := 0
+	for i++; i < len(tokens); i++ {
+		t := tokens[i]
+		switch {
+		case t.IsPunct("("):
+			i = skipParens(tokens, i) - 1
+		case t.IsPunct(")"), t.IsPunct(";"):
+			return false
+		case t.IsKeyword("WHERE"):
+			return true
+		case t.IsKeyword("CASE"):
+			caseDepth++
+		case t.IsKeyword("END") && caseDepth > 0:
+			caseDepth--
+		case t.IsKeyword("SET") && setClause:
+			setClause = false
+		case caseDepth == 0 && (t.IsKeyword("SET") || statementStart(t) && !t.IsKeyword("WITH")):
+			return false
+		}
+	}
+	return false
+}
+
+// cursorName returns the name of cursor if the CURSOR at tokens[i] declares
+// cursor, such as "DECLARE c CURSOR", "DECLARE @c CURSOR" or "SET @c = CURSOR".
+func cursorName(sql string, tokens []Token, i int) string {
+	j := i - 1
+	for j >= 0 && keywordIn("INSENSITIVE", "SCROLL")(tokens[j]) {
+		j--
+	}
+	switch {
+	case j >= 1 && (tokens[j].IsName() || tokens[j].Type == TokenVariable) &&
+		(tokens[j-1].IsKeyword("DECLARE") || tokens[j-1].IsPunct(",")):
+		return sql[tokens[j].Pos:tokens[j].End]
+	case j >= 2 && tokens[j].Is(TokenOperator, "=") && tokens[j-1].Type == TokenVariable && tokens[j-2].IsKeyword("SET"):
+		return sql[tokens[j-1].Pos:tokens[j-1].End]
+	}
+	return ""
+}
@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	sqls, err := Split(`use db1
+GO
+create procedure p1 as
+begin
+  update t1 set a = 1 where id = 1;
+  select 1;
+end
+go 2
+select 'go;' from t1; select case when a = 1 then 'x' else 'y' end from t2
+/* GO */ if @a = 1 begin delete from t1 where id = 1; delete from t2 where id = 1; end
+begin tran; update t1 set a = 1 where id = 2; commit
+GO`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{
+		"use db1",
+		"create procedure p1 as\nbegin\n  update t1 set a = 1 where id = 1;\n  select 1;\nend",
+		"create procedure p1 as\nbegin\n  update t1 set a = 1 where id = 1;\n  select 1;\nend",
+		"select 'go;' from t1",
+		"select case when a = 1 then 'x' else 'y' end from t2",
+		"if @a = 1 begin delete from t1 where id = 1; delete from t2 where id = 1; end",
+		"begin tran",
+		"update t1 set a = 1 where id = 2",
+		"commit",
+	}, sqls)
+}
+
+func TestSplitWithoutSemicolon(t *testing.T) {
+	cases := []struct {
+		sql    string
+		expect []string
+	}{
+		{"UPDATE t SET a=1\nDELETE FROM t WHERE id=1", []string{"UPDATE t SET a=1", "DELETE FROM t WHERE id=1"}},
+		{"SELECT 1\nGO 5\nSELECT 2", []string{"SELECT 1", "SELECT 1", "SELECT 1", "SELECT 1", "SELECT 1", "SELECT 2"}},
+		{"SET NOCOUNT ON\nDECLARE @a int\nSET @a = 1\nUPDATE t SET a = @a WHERE id = 1\nSELECT @a",
+			[]string{"SET NOCOUNT ON", "DECLARE @a int", "SET @a = 1", "UPDATE t SET a = @a WHERE id = 1", "SELECT @a"}},
+		{"INSERT INTO t (a) SELECT a FROM t2 UNION ALL SELECT a FROM t3\nINSERT INTO t EXEC p1",
+			[]string{"INSERT INTO t (a) SELECT a FROM t2 UNION ALL SELECT a FROM t3", "INSERT INTO t EXEC p1"}},
+		{"WITH c AS (SELECT a FROM t) SELECT a FROM c WITH (NOLOCK)\nSELECT 1",
+			[]string{"WITH c AS (SELECT a FROM t) SELECT a FROM c WITH (NOLOCK)", "SELECT 1"}},
+		{"IF @a = 1 SELECT 1 ELSE IF @a = 2 SELECT 2 ELSE BEGIN SELECT 3 END\nSELECT 4",
+			[]string{"IF @a = 1 SELECT 1 ELSE IF @a = 2 SELECT 2 ELSE BEGIN SELECT 3 END", "SELECT 4"}},
+		{"ALTER TABLE t ALTER COLUMN a int\nDROP TABLE IF EXISTS t2\nCREATE TABLE t3 (id int REFERENCES t (id) ON DELETE SET NULL)",
+			[]string{"ALTER TABLE t ALTER COLUMN a int", "DROP TABLE IF EXISTS t2", "CREATE TABLE t3 (id int REFERENCES t (id) ON DELETE SET NULL)"}},
+		{"MERGE t USING s ON t.id = s.id WHEN MATCHED THEN UPDATE SET a = s.a WHEN NOT MATCHED THEN INSERT (a) VALUES (s.a);\nDECLARE c CURSOR FOR SELECT a FROM t FOR UPDATE OF a",
+			[]string{"MERGE t USING s ON t.id = s.id WHEN MATCHED THEN UPDATE SET a = s.a WHEN NOT MATCHED THEN INSERT (a) VALUES (s.a)", "DECLARE c CURSOR FOR SELECT a FROM t FOR UPDATE OF a"}},
+	}
+	for _, c := range cases {
+		sqls, err := Split(c.sql)
+		if !assert.NoError(t, err, c.sql) {
+			continue
+		}
+		assert.Equal(t, c.expect, sqls, c.sql)
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		sql          string
+		selectStar   bool
+		noLocks      []string
+		missingWhere bool
+		cursors      []string
+	}{
+		{sql: "select a from t1"},
+		{sql: "select * from t1", selectStar: true},
+		{sql: "select top (10) t.* from t1 as t", selectStar: true},
+		{sql: "select distinct top 5 a, b from t1"},
+		{sql: "select count(*) from t1 where exists (select * from t2 where t2.id = t1.id)"},
+		{sql: "select a from t1 with (nolock) join t2 (nolock, index(ix_1)) on t1.id = t2.id", noLocks: []string{"NOLOCK"}},
+		{sql: "select a from t1 with (readuncommitted)", noLocks: []string{"READUNCOMMITTED"}},
+		{sql: "set transaction isolation level read uncommitted", noLocks: []string{"READ UNCOMMITTED"}},
+		{sql: "update t1 set a = 1", missingWhere: true},
+		{sql: "update t1 with (rowlock) set a = case when b = 1 then 1 else 2 end where id = 1"},
+		{sql: "update t1 set a = t2.a from t1 join t2 on t1.id = t2.id", missingWhere: true},
+		{sql: "delete from t1 where id in (select id from t2)"},
+		{sql: "delete top (10) from t1", missingWhere: true},
+		{sql: "create trigger tr1 on t1 after update, delete as begin select 1 end"},
+		{sql: "update statistics t1"},
+		{sql: "create procedure p1 as begin delete from t1 set @a = 1 end", missingWhere: true},
+		{sql: "declare c1 cursor for select a from t1", cursors: []string{"c1"}},
+		{sql: "declare @a int, @c cursor; set @c = cursor fast_forward for select a from t1", cursors: []string{"@c", "@c"}},
+		{sql: "declare c2 insensitive scroll cursor for select a from t1 for read only", cursors: []string{"c2"}},
+	}
+	for _, c := range cases {
+		stmts, err := Parse(c.sql)
+		if !assert.NoError(t, err, c.sql) || !assert.NotEmpty(t, stmts, c.sql) {
+			continue
+		}
+		s := &Stmt{}
+		for _, stmt := range stmts {
+			s.SelectStar = s.SelectStar || stmt.SelectStar
+			s.NoLocks = append(s.NoLocks, stmt.NoLocks...)
+			s.MissingWhere = s.MissingWhere || stmt.MissingWhere
+			s.Cursors = append(s.Cursors, stmt.Cursors...)
+		}
+		assert.Equal(t, c.selectStar, s.SelectStar, c.sql)
+		assert.Equal(t, c.noLocks, s.NoLocks, c.sql)
+		assert.Equal(t, c.missingWhere, s.MissingWhere, c.sql)
+		assert.Equal(t, c.cursors, s.Cursors, c.sql)
+	}
+}
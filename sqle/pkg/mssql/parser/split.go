@@ -0,0 +1,227 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+)
+
+func splitTokens(tokens []Token) [][]Token {
+	var groups [][]Token
+	for start := 0; start < len(tokens); {
+		end := start
+		for end < len(tokens) && tokens[end].Type != TokenGo {
+			end++
+		}
+		batch := tokens[start:end]
+		var stmts [][]Token
+		if isModule(batch) {
+			stmts = [][]Token{batch}
+		} else {
+			stmts = splitBatch(batch)
+		}
+		count := 1
+		if end < len(tokens) {
+			count = goCount(tokens[end])
+		}
+		for i := 0; i < count; i++ {
+			groups = append(groups, stmts...)
+		}
+		start = end + 1
+	}
+	return groups
+}
+
+// goCount returns the count n of "GO n", it is 1 for "GO".
+func goCount(t Token) int {
+	fields := strings.Fields(t.Value)
+	if len(fields) < 2 {
+		return 1
+	}
+	n, err := strconv.Atoi(fields[1])
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// splitBatch splits the tokens of batch into statements, the ";" between
+// statements is dropped.
+func splitBatch(tokens []Token) [][]Token {
+	var stmts [][]Token
+	for i := 0; i < len(tokens); {
+		if tokens[i].IsPunct(";") {
+			i++
+			continue
+		}
+		end := stmtEnd(tokens, i)
+		stmts = append(stmts, tokens[i:end])
+		i = end
+	}
+	return stmts
+}
+
+// stmtEnd returns the index after the statement which begins at tokens[i].
+func stmtEnd(tokens []Token, i int) int {
+	t := tokens[i]
+	switch {
+	case keywordIn("IF", "WHILE")(t):
+		// the condition is followed by the body, which is a statement or block.
+		s := &stmtState{lead: t.Value}
+		j := i + 1
+		for j < len(tokens) && !s.startsStatement(tokens, j) {
+			if tokens[j].IsPunct("(") {
+				j = skipParens(tokens, j)
+				continue
+			}
+			j++
+		}
+		if j >= len(tokens) {
+			return j
+		}
+		end := stmtEnd(tokens, j)
+		if !t.IsKeyword("IF") {
+			return end
+		}
+		k := end
+		if k+1 < len(tokens) && tokens[k].IsPunct(";") && tokens[k+1].IsKeyword("ELSE") {
+			k++
+		}
+		if k < len(tokens) && tokens[k].IsKeyword("ELSE") {
+			return stmtEnd(tokens, k)
+		}
+		return end
+	case t.IsKeyword("ELSE") && i+1 < len(tokens):
+		return stmtEnd(tokens, i+1)
+	case t.IsKeyword("BEGIN") && isBlockBegin(tokens, i):
+		depth := 0
+		for j := i; j < len(tokens); j++ {
+			switch {
+			case isBlockBegin(tokens, j):
+				depth++
+			case isBlockEnd(tokens, j):
+				depth--
+				if depth == 0 {
+					// "END TRY" and "END CATCH".
+					if j+1 < len(tokens) && keywordIn("TRY", "CATCH")(tokens[j+1]) {
+						j++
+					}
+					return j + 1
+				}
+			}
+		}
+		return len(tokens)
+	}
+
+	s := &stmtState{lead: t.Value}
+	caseDepth := 0
+	for j := i + 1; j < len(tokens); j++ {
+		t := tokens[j]
+		switch {
+		case t.IsPunct("("):
+			j = skipParens(tokens, j) - 1
+			continue
+		case t.IsPunct(";"):
+			return j
+		case t.IsKeyword("CASE"):
+			caseDepth++
+		case t.IsKeyword("END") && caseDepth > 0:
+			caseDepth--
+		case caseDepth > 0:
+		case t.IsKeyword("ELSE"), t.IsKeyword("END"):
+			// the ELSE of IF, or the END of block.
+			return j
+		case s.startsStatement(tokens, j):
+			return j
+		}
+		s.update(t)
+	}
+	return len(tokens)
+}
+
+// stmtState is the state of statement which is used to decide whether the
+// keyword in it starts another statement.
+type stmtState struct {
+	// lead is the leading keyword of statement, it is the main DML after the
+	// common table expressions for "WITH".
+	lead string
+	// insertSource is true if VALUES, SELECT or EXEC of INSERT is found.
+	insertSource bool
+	// setFound is true if SET of UPDATE is found.
+	setFound bool
+}
+
+func (s *stmtState) update(t Token) {
+	switch {
+	case s.lead == "WITH" && keywordIn("SELECT", "INSERT", "UPDATE", "DELETE", "MERGE")(t):
+		s.lead = t.Value
+	case s.lead == "INSERT" && keywordIn("VALUES", "SELECT", "EXEC", "EXECUTE", "DEFAULT")(t):
+		s.insertSource = true
+	case s.lead == "UPDATE" && t.IsKeyword("SET"):
+		s.setFound = true
+	}
+}
+
+var objectTypes = keywordIn("TABLE", "VIEW", "PROCEDURE", "PROC", "FUNCTION", "TRIGGER", "INDEX", "DATABASE",
+	"SCHEMA", "USER", "LOGIN", "ROLE", "SEQUENCE", "SYNONYM", "TYPE", "STATISTICS", "UNIQUE", "CLUSTERED",
+	"NONCLUSTERED", "COLUMNSTORE", "OR", "DEFAULT", "RULE", "ASSEMBLY", "XML", "FULLTEXT", "SPATIAL", "PARTITION")
+
+// startsStatement returns true if the keyword at tokens[i] starts another
+// statement, rather than a part of the current statement.
+func (s *stmtState) startsStatement(tokens []Token, i int) bool {
+	t := tokens[i]
+	if !statementStart(t) || keywordIn("ELSE", "END")(t) {
+		return false
+	}
+	prev := tokens[i-1]
+	if prev.IsPunct(",") || prev.IsPunct(".") {
+		return false
+	}
+	var next Token
+	if i+1 < len(tokens) {
+		next = tokens[i+1]
+	}
+	switch t.Value {
+	case "SELECT":
+		if s.lead == "WITH" || (s.lead == "INSERT" && !s.insertSource) {
+			return false
+		}
+		return !keywordIn("UNION", "ALL", "EXCEPT", "INTERSECT", "FOR", "GRANT", "DENY", "REVOKE", "AS")(prev)
+	case "INSERT", "UPDATE", "DELETE", "MERGE":
+		if s.lead == "WITH" || next.IsPunct("(") {
+			return false
+		}
+		// such as "ON DELETE CASCADE", "FOR UPDATE" and "WHEN MATCHED THEN UPDATE".
+		return !keywordIn("ON", "FOR", "AFTER", "OF", "THEN", "GRANT", "DENY", "REVOKE", "ALL", "BULK")(prev)
+	case "EXEC", "EXECUTE":
+		if s.lead == "INSERT" && !s.insertSource {
+			return false
+		}
+		return !keywordIn("GRANT", "DENY", "REVOKE", "WITH", "AS")(prev)
+	case "WITH":
+		// the common table expression "WITH name AS (" or "WITH name (columns) AS (",
+		// the table hint "WITH (NOLOCK)" is not.
+		if !next.IsName() || i+2 >= len(tokens) {
+			return false
+		}
+		return next.IsKeyword("XMLNAMESPACES") || tokens[i+2].IsKeyword("AS") || tokens[i+2].IsPunct("(")
+	case "CREATE", "ALTER", "DROP":
+		// "ALTER COLUMN" and "DROP CONSTRAINT" in ALTER TABLE are not.
+		return objectTypes(next)
+	case "SET":
+		if s.lead == "MERGE" || s.lead == "ALTER" || (s.lead == "UPDATE" && !s.setFound) {
+			return false
+		}
+		// such as "ON DELETE SET NULL".
+		return !keywordIn("UPDATE", "DELETE")(prev)
+	case "IF":
+		// such as "DROP TABLE IF EXISTS".
+		return !next.IsKeyword("EXISTS") || !(objectTypes(prev) || keywordIn("COLUMN", "CONSTRAINT")(prev))
+	case "GRANT":
+		// "WITH GRANT OPTION" and "REVOKE GRANT OPTION FOR".
+		return !next.IsKeyword("OPTION")
+	case "FETCH":
+		// "OFFSET n ROWS FETCH NEXT n ROWS ONLY".
+		return !keywordIn("ROW", "ROWS")(prev)
+	}
+	return true
+}
@@ -0,0 +1,54 @@
+package mssql
+
+// DynExecQueryStats ref to https://docs.microsoft.com/en-us/sql/relational-databases/system-dynamic-management-views/sys-dm-exec-query-stats-transact-sql
+type DynExecQueryStats struct {
+	SQLText            string `json:"sql_text"`
+	ExecutionCount     int64  `json:"execution_count"`
+	TotalElapsedTime   int64  `json:"total_elapsed_time"`
+	TotalWorkerTime    int64  `json:"total_worker_time"`
+	TotalLogicalReads  int64  `json:"total_logical_reads"`
+	TotalPhysicalReads int64  `json:"total_physical_reads"`
+	TotalLogicalWrites int64  `json:"total_logical_writes"`
+}
+
+// Note:
+// sys.dm_exec_query_stats has one row per statement of cached plan, so the
+// statements are grouped by query_hash, which is the same for the statements
+// that differ only in literal values. The time columns are in microseconds.
+const (
+	DynExecQueryStatsTpl = `
+	SELECT TOP (%v)
+		MAX(SUBSTRING(st.text, (qs.statement_start_offset / 2) + 1,
+			((CASE qs.statement_end_offset WHEN -1 THEN DATALENGTH(st.text)
+				ELSE qs.statement_end_offset END - qs.statement_start_offset) / 2) + 1)) AS sql_text,
+		SUM(qs.execution_count) AS execution_count,
+		SUM(qs.total_elapsed_time) AS total_elapsed_time,
+		SUM(qs.total_worker_time) AS total_worker_time,
+		SUM(qs.total_logical_reads) AS total_logical_reads,
+		SUM(qs.total_physical_reads) AS total_physical_reads,
+		SUM(qs.total_logical_writes) AS total_logical_writes
+	FROM
+		sys.dm_exec_query_stats AS qs
+		CROSS APPLY sys.dm_exec_sql_text(qs.sql_handle) AS st
+	WHERE
+		qs.execution_count > 0
+	GROUP BY qs.query_hash
+	ORDER BY %v DESC
+	`
+	DynExecQueryStatsColumnExecutionCount     = "execution_count"
+	DynExecQueryStatsColumnTotalElapsedTime   = "total_elapsed_time"
+	DynExecQueryStatsColumnTotalWorkerTime    = "total_worker_time"
+	DynExecQueryStatsColumnTotalLogicalReads  = "total_logical_reads"
+	DynExecQueryStatsColumnTotalPhysicalReads = "total_physical_reads"
+	DynExecQueryStatsColumnTotalLogicalWrites = "total_logical_writes"
+)
+
+// DynExecQueryStatsOrderByColumns is the columns which can be used to sort the top SQLs.
+var DynExecQueryStatsOrderByColumns = map[string]struct{}{
+	DynExecQueryStatsColumnExecutionCount:     {},
+	DynExecQueryStatsColumnTotalElapsedTime:   {},
+	DynExecQueryStatsColumnTotalWorkerTime:    {},
+	DynExecQueryStatsColumnTotalLogicalReads:  {},
+	DynExecQueryStatsColumnTotalPhysicalReads: {},
+	DynExecQueryStatsColumnTotalLogicalWrites: {},
+}
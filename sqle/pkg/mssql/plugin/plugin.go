@@ -0,0 +1,32 @@
+// Package plugin serves the SQL Server plugin by the driver adaptor, the main
+// package of plugin only needs to call Serve.
+package plugin
+
+import (
+	"context"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	adaptor "github.com/actiontech/sqle/sqle/pkg/driver"
+	"github.com/actiontech/sqle/sqle/pkg/mssql/parser"
+	"github.com/actiontech/sqle/sqle/pkg/mssql/rule"
+)
+
+// Serve serves the SQL Server plugin with the T-SQL rules.
+func Serve(version string) {
+	a := adaptor.NewAdaptor(&adaptor.MssqlDialector{})
+	for i := range rule.RuleHandlers {
+		rh := &rule.RuleHandlers[i]
+		a.AddLocatedRuleWithSQLParser(&rh.Rule, func(ctx context.Context, r *driver.Rule, ast interface{}) (string, int, error) {
+			msg, err := rh.Audit(r, ast)
+			if err != nil || msg == "" {
+				return msg, -1, err
+			}
+			return msg, rh.Offset(ast), nil
+		})
+	}
+	a.Serve(
+		adaptor.WithSQLParser(rule.ParseSQL),
+		adaptor.WithSQLSplitter(parser.Split),
+		adaptor.WithVersion(version),
+	)
+}
@@ -0,0 +1,130 @@
+package rule
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/pkg/mssql/parser"
+)
+
+// rule type
+const (
+	RuleTypeDMLConvention   = "DML规范"
+	RuleTypeUsageSuggestion = "使用建议"
+)
+
+const (
+	DMLDisableSelectAllColumn = "dml_disable_select_all_column"
+	DMLDisableNoLock          = "dml_disable_nolock"
+	DMLCheckWhereIsInvalid    = "all_check_where_is_invalid"
+	DMLDisableCursor          = "dml_disable_cursor"
+)
+
+type RuleHandler struct {
+	Rule    driver.Rule
+	Message string
+	// Func returns the arguments of Message if the statement violates the
+	// rule, ok is false if there is no finding.
+	Func func(rule *driver.Rule, stmt *parser.Stmt) (args []interface{}, ok bool)
+	// Position returns the byte offset of the offending token in SQL, the
+	// finding is located at the beginning of statement if it is nil.
+	Position func(stmt *parser.Stmt) int
+}
+
+var RuleHandlers = []RuleHandler{
+	{
+		Rule: driver.Rule{
+			Name:     DMLDisableSelectAllColumn,
+			Desc:     "不建议使用select *",
+			Level:    driver.RuleLevelNotice,
+			Category: RuleTypeDMLConvention,
+		},
+		Message:  "不建议使用select *",
+		Func:     checkSelectAll,
+		Position: func(stmt *parser.Stmt) int { return stmt.SelectStarPos },
+	},
+	{
+		Rule: driver.Rule{
+			Name:     DMLDisableNoLock,
+			Desc:     "不建议使用 NOLOCK 提示或 READ UNCOMMITTED 隔离级别",
+			Level:    driver.RuleLevelWarn,
+			Category: RuleTypeDMLConvention,
+		},
+		Message: "不建议使用 %s, 可能读到未提交或重复的数据",
+		Func:    checkNoLock,
+	},
+	{
+		Rule: driver.Rule{
+			Name:     DMLCheckWhereIsInvalid,
+			Desc:     "禁止使用没有where条件的UPDATE/DELETE语句",
+			Level:    driver.RuleLevelError,
+			Category: RuleTypeDMLConvention,
+		},
+		Message:  "禁止使用没有where条件的UPDATE/DELETE语句",
+		Func:     checkWhere,
+		Position: func(stmt *parser.Stmt) int { return stmt.MissingWherePos },
+	},
+	{
+		Rule: driver.Rule{
+			Name:     DMLDisableCursor,
+			Desc:     "不建议使用游标",
+			Level:    driver.RuleLevelWarn,
+			Category: RuleTypeUsageSuggestion,
+		},
+		Message: "不建议使用游标 %s, 建议改为基于集合的操作",
+		Func:    checkCursor,
+	},
+}
+
+// Audit checks the ast which is parsed by ParseSQL, it returns empty message
+// if the statement does not violate the rule.
+func (rh *RuleHandler) Audit(rule *driver.Rule, ast interface{}) (string, error) {
+	stmt, ok := ast.(*parser.Stmt)
+	if !ok {
+		return "", fmt.Errorf("unexpected ast type %T", ast)
+	}
+	args, ok := rh.Func(rule, stmt)
+	if !ok {
+		return "", nil
+	}
+	return fmt.Sprintf(rh.Message, args...), nil
+}
+
+// Offset returns the byte offset of the finding in SQL which is parsed by
+// ParseSQL, it is -1 if the rule does not locate the finding.
+func (rh *RuleHandler) Offset(ast interface{}) int {
+	stmt, ok := ast.(*parser.Stmt)
+	if !ok || rh.Position == nil {
+		return -1
+	}
+	return rh.Position(stmt)
+}
+
+// ParseSQL parses the SQL which has one statement exactly, the ast is used by
+// RuleHandler.Audit.
+func ParseSQL(sql string) (interface{}, error) {
+	return parser.ParseOne(sql)
+}
+
+func checkSelectAll(rule *driver.Rule, stmt *parser.Stmt) ([]interface{}, bool) {
+	return nil, stmt.SelectStar
+}
+
+func checkNoLock(rule *driver.Rule, stmt *parser.Stmt) ([]interface{}, bool) {
+	if len(stmt.NoLocks) == 0 {
+		return nil, false
+	}
+	return []interface{}{strings.Join(stmt.NoLocks, ", ")}, true
+}
+
+func checkWhere(rule *driver.Rule, stmt *parser.Stmt) ([]interface{}, bool) {
+	return nil, stmt.MissingWhere
+}
+
+func checkCursor(rule *driver.Rule, stmt *parser.Stmt) ([]interface{}, bool) {
+	if len(stmt.Cursors) == 0 {
+		return nil, false
+	}
+	return []interface{}{strings.Join(stmt.Cursors, ", ")}, true
+}
@@ -0,0 +1,70 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ruleHandler(t *testing.T, name string) *RuleHandler {
+	var handler *RuleHandler
+	for i := range RuleHandlers {
+		if RuleHandlers[i].Rule.Name == name {
+			handler = &RuleHandlers[i]
+		}
+	}
+	if !assert.NotNil(t, handler, name) {
+		t.FailNow()
+	}
+	return handler
+}
+
+func parseSQL(t *testing.T, sql string) interface{} {
+	ast, err := ParseSQL(sql)
+	if !assert.NoError(t, err, sql) {
+		t.FailNow()
+	}
+	return ast
+}
+
+func audit(t *testing.T, name, sql string) string {
+	handler := ruleHandler(t, name)
+	rule := handler.Rule
+	msg, err := handler.Audit(&rule, parseSQL(t, sql))
+	if !assert.NoError(t, err, sql) {
+		t.FailNow()
+	}
+	return msg
+}
+
+func offset(t *testing.T, name, sql string) int {
+	return ruleHandler(t, name).Offset(parseSQL(t, sql))
+}
+
+func TestRuleHandlerOffset(t *testing.T) {
+	assert.Equal(t, 14, offset(t, DMLDisableSelectAllColumn, "select top 10 * from t1"))
+	assert.Equal(t, 8, offset(t, DMLCheckWhereIsInvalid, "BEGIN\n  delete from t1\nEND"))
+	assert.Equal(t, -1, offset(t, DMLDisableCursor, "declare c cursor for select a from t1"))
+}
+
+func TestCheckSelectAll(t *testing.T) {
+	assert.Equal(t, "不建议使用select *", audit(t, DMLDisableSelectAllColumn, "select top 10 * from t1"))
+	assert.Equal(t, "", audit(t, DMLDisableSelectAllColumn, "select a from t1"))
+}
+
+func TestCheckNoLock(t *testing.T) {
+	assert.Equal(t, "不建议使用 NOLOCK, 可能读到未提交或重复的数据",
+		audit(t, DMLDisableNoLock, "select a from t1 with (nolock)"))
+	assert.Equal(t, "", audit(t, DMLDisableNoLock, "select a from t1 with (rowlock)"))
+}
+
+func TestCheckWhere(t *testing.T) {
+	assert.Equal(t, "禁止使用没有where条件的UPDATE/DELETE语句", audit(t, DMLCheckWhereIsInvalid, "delete from t1"))
+	assert.Equal(t, "", audit(t, DMLCheckWhereIsInvalid, "delete from t1 where id = 1"))
+}
+
+func TestCheckCursor(t *testing.T) {
+	assert.Equal(t, "不建议使用游标 c1, 建议改为基于集合的操作",
+		audit(t, DMLDisableCursor, "declare c1 cursor for select a from t1"))
+	assert.Equal(t, "", audit(t, DMLDisableCursor, "declare @a int"))
+}
@@ -0,0 +1,85 @@
+package mybatis
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	parser "github.com/actiontech/mybatis-mapper-2-sql"
+)
+
+// Query is a query element in MyBatis mapper XML, such as <select>.
+type Query struct {
+	SQL string
+	// Line is the line of the query element start tag in XML, it is 0 if the
+	// query can not be located.
+	Line int
+}
+
+// ParseXMLQuery parses all queries in MyBatis (or iBatis) mapper XML one by
+// one, it is the same as parser.ParseXMLQuery except that the line of each
+// query is returned. The queries are not located if some of them are skipped
+// by skipErrorQuery, because the skipped queries are unknown.
+func ParseXMLQuery(data string, skipErrorQuery bool) ([]Query, error) {
+	sqls, err := parser.ParseXMLQuery(data, skipErrorQuery)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := queryLines(data)
+	if err != nil || len(lines) != len(sqls) {
+		lines = make([]int, len(sqls))
+	}
+	queries := make([]Query, 0, len(sqls))
+	for i, sql := range sqls {
+		queries = append(queries, Query{SQL: sql, Line: lines[i]})
+	}
+	return queries, nil
+}
+
+// queryElements is the query elements under the root element, ref to
+// parser.scanMyBatis and parser.scanIBatis.
+var queryElements = map[string]map[string]struct{}{
+	"mapper": {"select": {}, "insert": {}, "update": {}, "delete": {}},
+	"sqlMap": {"select": {}, "insert": {}, "update": {}, "delete": {}, "statement": {}},
+}
+
+// queryLines returns the line of query elements in XML by document order.
+func queryLines(data string) ([]int, error) {
+	d := xml.NewDecoder(strings.NewReader(data))
+	var lines []int
+	// elements is nil until the root mapper element is found, depth is the
+	// depth in the root mapper element.
+	var elements map[string]struct{}
+	depth := 0
+	for {
+		// the offset is the end of previous token, that is the start of next token.
+		offset := d.InputOffset()
+		t, err := d.Token()
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch tt := t.(type) {
+		case xml.StartElement:
+			if elements == nil {
+				elements = queryElements[tt.Name.Local]
+				continue
+			}
+			depth++
+			if _, ok := elements[tt.Name.Local]; ok && depth == 1 {
+				lines = append(lines, strings.Count(data[:offset], "\n")+1)
+			}
+		case xml.EndElement:
+			if elements == nil {
+				continue
+			}
+			if depth == 0 {
+				// the parser only parses the first mapper element.
+				return lines, nil
+			}
+			depth--
+		}
+	}
+}
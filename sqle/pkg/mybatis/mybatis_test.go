@@ -0,0 +1,51 @@
+package mybatis
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseXMLQuery(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE mapper PUBLIC "-//mybatis.org//DTD Mapper 3.0//EN" "http://mybatis.org/dtd/mybatis-3-mapper.dtd">
+<mapper namespace="Test">
+	<sql id="columns">id, name</sql>
+	<select id="getUser">
+		SELECT <include refid="columns"/> FROM users WHERE id = #{id}
+	</select>
+
+	<insert id="addUser">INSERT INTO users (name) VALUES (#{name})</insert>
+	<update
+		id="updateUser">
+		UPDATE users SET name = #{name} WHERE id = #{id}
+	</update>
+</mapper>`
+	queries, err := ParseXMLQuery(data, false)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, queries, 3) {
+		t.FailNow()
+	}
+	assert.Equal(t, "SELECT `id`,`name` FROM `users` WHERE `id`=?", queries[0].SQL)
+	assert.Equal(t, 5, queries[0].Line)
+	assert.Equal(t, 9, queries[1].Line)
+	assert.Equal(t, 10, queries[2].Line)
+}
+
+func TestParseXMLQuery_IBatis(t *testing.T) {
+	data := `<sqlMap namespace="Test">
+	<statement id="getUser">SELECT * FROM users</statement>
+	<delete id="deleteUser">DELETE FROM users WHERE id = #id#</delete>
+</sqlMap>`
+	queries, err := ParseXMLQuery(data, false)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	if !assert.Len(t, queries, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, 2, queries[0].Line)
+	assert.Equal(t, 3, queries[1].Line)
+}
@@ -0,0 +1,209 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Stmt is a parsed Oracle statement. The statements which are not needed by
+// the audit rules are parsed to UnknownStmt, only the leading keywords are kept.
+type Stmt interface {
+	// Text returns the raw SQL text of statement, without the trailing ";" or "/".
+	Text() string
+	// Literals returns the count of string and number literals in statement.
+	Literals() int
+	// BindVariables returns the count of bind variables in statement.
+	BindVariables() int
+}
+
+type stmt struct {
+	text          string
+	literals      int
+	bindVariables int
+}
+
+func (s *stmt) Text() string {
+	return s.text
+}
+
+func (s *stmt) Literals() int {
+	return s.literals
+}
+
+func (s *stmt) BindVariables() int {
+	return s.bindVariables
+}
+
+func (s *stmt) setText(text string) {
+	s.text = text
+}
+
+func (s *stmt) setCounts(literals, bindVariables int) {
+	s.literals = literals
+	s.bindVariables = bindVariables
+}
+
+// ObjectName is the name of table, index or other object, Schema is empty if
+// the name is not qualified.
+type ObjectName struct {
+	Schema string
+	Name   string
+}
+
+// String returns the quoted name which can be used in SQL.
+func (n *ObjectName) String() string {
+	if n.Schema == "" {
+		return QuoteIdent(n.Name)
+	}
+	return fmt.Sprintf("%s.%s", QuoteIdent(n.Schema), QuoteIdent(n.Name))
+}
+
+type ColumnDef struct {
+	Name string
+	// Type is the raw text of data type, such as "VARCHAR2(20 CHAR)".
+	Type string
+	// Default is the raw text of default expression.
+	Default    string
+	NotNull    bool
+	PrimaryKey bool
+}
+
+type CreateTableStmt struct {
+	stmt
+	Table     *ObjectName
+	Columns   []*ColumnDef
+	Temporary bool
+	// AsSelect is true for "CREATE TABLE ... AS SELECT".
+	AsSelect      bool
+	HasPrimaryKey bool
+}
+
+type CreateIndexStmt struct {
+	stmt
+	Name   *ObjectName
+	Table  *ObjectName
+	Unique bool
+}
+
+// SequenceStmt is "CREATE SEQUENCE" or "ALTER SEQUENCE".
+type SequenceStmt struct {
+	stmt
+	Name  *ObjectName
+	Alter bool
+	// Cache is the value of "CACHE n", it is -1 if CACHE is not specified.
+	Cache   int
+	NoCache bool
+}
+
+type AlterTableCmdType int
+
+const (
+	AlterTableOther AlterTableCmdType = iota
+	AlterTableAddColumn
+	AlterTableModifyColumn
+	AlterTableDropColumn
+	AlterTableRenameColumn
+	AlterTableAddConstraint
+	AlterTableDropConstraint
+	AlterTableDropPrimaryKey
+	AlterTableRenameTable
+)
+
+type AlterTableCmd struct {
+	Type AlterTableCmdType
+	// Columns is the added or modified columns.
+	Columns []*ColumnDef
+	// ColumnNames is the dropped columns, and the old name of renamed column.
+	ColumnNames []string
+	// ConstraintName is the added or dropped constraint, it is empty if the
+	// added constraint is not named.
+	ConstraintName string
+	// PrimaryKey is true if the added constraint is primary key.
+	PrimaryKey bool
+	// NewName is the new name of renamed column or table.
+	NewName string
+	// Text is the raw text of command.
+	Text string
+}
+
+type AlterTableStmt struct {
+	stmt
+	Table *ObjectName
+	Cmds  []*AlterTableCmd
+}
+
+type DropStmt struct {
+	stmt
+	// ObjectType is the type of dropped object, such as "TABLE" or "MATERIALIZED VIEW".
+	ObjectType string
+	Name       *ObjectName
+	Purge      bool
+}
+
+type TruncateStmt struct {
+	stmt
+	Table *ObjectName
+}
+
+// DMLStmt is SELECT, INSERT, UPDATE, DELETE or MERGE.
+type DMLStmt struct {
+	stmt
+	// Keyword is the type of DML, such as "SELECT".
+	Keyword string
+	// SelectStar is true if "*" or "t.*" is in the select list of any query
+	// block in statement, including sub query.
+	SelectStar bool
+	// SelectStarPos is the byte offset of the first "*" or "t.*" in SQL text,
+	// it is valid only if SelectStar is true.
+	SelectStarPos int
+	// CartesianJoins is the tables which are joined without join condition,
+	// such as "t1, t2" for "SELECT ... FROM t1, t2".
+	CartesianJoins []string
+}
+
+// PLSQLStmt is anonymous block or stored PL/SQL unit, such as "CREATE PROCEDURE".
+type PLSQLStmt struct {
+	stmt
+	Keywords string
+}
+
+type UnknownStmt struct {
+	stmt
+	// Keywords is the leading keywords in upper case, such as "CREATE VIEW".
+	Keywords string
+}
+
+// QuoteIdent quotes the identifier if it is not an upper case name.
+func QuoteIdent(name string) string {
+	simple := name != ""
+	for i, c := range name {
+		if !((c >= 'A' && c <= 'Z') || (i > 0 && ((c >= '0' && c <= '9') || c == '_' || c == '$' || c == '#'))) {
+			simple = false
+			break
+		}
+	}
+	if simple && !isReservedWord(name) {
+		return name
+	}
+	return `"` + name + `"`
+}
+
+var reservedWords = map[string]struct{}{}
+
+func init() {
+	for _, w := range strings.Fields(`ACCESS ADD ALL ALTER AND ANY AS ASC AUDIT BETWEEN BY CHAR CHECK CLUSTER
+		COLUMN COMMENT COMPRESS CONNECT CREATE CURRENT DATE DECIMAL DEFAULT DELETE DESC DISTINCT DROP ELSE
+		EXCLUSIVE EXISTS FILE FLOAT FOR FROM GRANT GROUP HAVING IDENTIFIED IMMEDIATE IN INCREMENT INDEX
+		INITIAL INSERT INTEGER INTERSECT INTO IS LEVEL LIKE LOCK LONG MAXEXTENTS MINUS MLSLABEL MODE MODIFY
+		NOAUDIT NOCOMPRESS NOT NOWAIT NULL NUMBER OF OFFLINE ON ONLINE OPTION OR ORDER PCTFREE PRIOR PUBLIC
+		RAW RENAME RESOURCE REVOKE ROW ROWID ROWNUM ROWS SELECT SESSION SET SHARE SIZE SMALLINT START
+		SUCCESSFUL SYNONYM SYSDATE TABLE THEN TO TRIGGER UID UNION UNIQUE UPDATE USER VALIDATE VALUES
+		VARCHAR VARCHAR2 VIEW WHENEVER WHERE WITH`) {
+		reservedWords[w] = struct{}{}
+	}
+}
+
+func isReservedWord(s string) bool {
+	_, ok := reservedWords[s]
+	return ok
+}
@@ -0,0 +1,122 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/actiontech/sqle/sqle/pkg/tokenizer"
+)
+
+type TokenType = tokenizer.TokenType
+
+// Token is the token of Oracle, the unquoted identifier is folded to upper
+// case.
+type Token = tokenizer.Token
+
+const (
+	TokenEOF         = tokenizer.TokenEOF
+	TokenIdent       = tokenizer.TokenIdent
+	TokenQuotedIdent = tokenizer.TokenQuotedIdent
+	// TokenString is string literal, including N'' and q'[...]'.
+	TokenString = tokenizer.TokenString
+	TokenNumber = tokenizer.TokenNumber
+	// TokenBind is bind variable, such as :1 or :name.
+	TokenBind     = tokenizer.TokenVariable
+	TokenOperator = tokenizer.TokenOperator
+	TokenPunct    = tokenizer.TokenPunct
+	// TokenSlash is the "/" in a line alone, it terminates PL/SQL block in SQL*Plus.
+	TokenSlash = tokenizer.TokenDelimiter
+)
+
+var dialect = &tokenizer.Dialect{
+	FoldIdent: strings.ToUpper,
+	IdentPart: "_$#",
+	Puncts:    "(),;.",
+	Operators: "+-*/<>=!^~|@%:&?",
+	TwoCharOperators: []string{
+		"<=", ">=", "<>", "!=", "^=", "~=", "||", ":=", "=>", "**",
+	},
+	// the suffix of BINARY_FLOAT and BINARY_DOUBLE literal, such as 1.5f.
+	NumberSuffixes: "fFdD",
+	Scan:           scan,
+}
+
+func Tokenize(sql string) ([]Token, error) {
+	return tokenizer.Tokenize(sql, dialect)
+}
+
+func scan(s *tokenizer.Scanner) (Token, bool, error) {
+	start := s.Pos()
+	c := s.Peek(0)
+	switch {
+	case (c == 'n' || c == 'N') && s.Peek(1) == '\'':
+		s.Skip(1)
+		t, err := s.ScanQuoted(start, TokenString, '\'', false)
+		return t, true, err
+	case (c == 'n' || c == 'N') && (s.Peek(1) == 'q' || s.Peek(1) == 'Q') && s.Peek(2) == '\'':
+		s.Skip(2)
+		t, err := scanQuotedString(s, start)
+		return t, true, err
+	case (c == 'q' || c == 'Q') && s.Peek(1) == '\'':
+		s.Skip(1)
+		t, err := scanQuotedString(s, start)
+		return t, true, err
+	case c == ':' && (s.IsIdentStart(s.SQL()[start+1:]) || isDigit(s.Peek(1))):
+		s.Skip(1)
+		s.ScanIdent()
+		return s.Token(TokenBind, s.SQL()[start:s.Pos()], start), true, nil
+	case c == '/' && isAloneInLine(s, start):
+		s.Skip(1)
+		return s.Token(TokenSlash, "/", start), true, nil
+	}
+	return Token{}, false, nil
+}
+
+// isAloneInLine returns true if the character at pos is the only non-space
+// character in its line.
+func isAloneInLine(s *tokenizer.Scanner, pos int) bool {
+	if !s.IsLineStart(pos) {
+		return false
+	}
+	sql := s.SQL()
+	for i := pos + 1; i < len(sql) && sql[i] != '\n'; i++ {
+		if !unicode.IsSpace(rune(sql[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// scanQuotedString scans the alternative quoting string, such as q'[it's]'.
+func scanQuotedString(s *tokenizer.Scanner, start int) (Token, error) {
+	// the position is at the opening quote.
+	s.Skip(1)
+	delimiter := s.Peek(0)
+	if delimiter == 0 {
+		return Token{}, fmt.Errorf("unterminated quoted string at position %d", start)
+	}
+	switch delimiter {
+	case '[':
+		delimiter = ']'
+	case '{':
+		delimiter = '}'
+	case '<':
+		delimiter = '>'
+	case '(':
+		delimiter = ')'
+	}
+	s.Skip(1)
+	sql := s.SQL()
+	end := strings.Index(sql[s.Pos():], string(delimiter)+"'")
+	if end < 0 {
+		return Token{}, fmt.Errorf("unterminated quoted string at position %d", start)
+	}
+	value := sql[s.Pos() : s.Pos()+end]
+	s.Skip(end + 2)
+	return s.Token(TokenString, value, start), nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,1004 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Split splits sql into statements. The statements are separated by ";" or
+// "/" in a line alone, the PL/SQL block is only terminated by "/" or the end
+// of sql, since ";" is a part of the block.
+func Split(sql string) ([]string, error) {
+	tokens, err := Tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	groups := splitTokens(tokens)
+	sqls := make([]string, 0, len(groups))
+	for _, group := range groups {
+		sqls = append(sqls, text(sql, group))
+	}
+	return sqls, nil
+}
+
+// Parse parses sql into statements.
+func Parse(sql string) ([]Stmt, error) {
+	tokens, err := Tokenize(sql)
+	if err != nil {
+		return nil, err
+	}
+	groups := splitTokens(tokens)
+	stmts := make([]Stmt, 0, len(groups))
+	for _, group := range groups {
+		p := &parser{sql: sql, tokens: group}
+		s, err := p.parseStmt()
+		if err != nil {
+			return nil, fmt.Errorf("parse %q failed: %v", text(sql, group), err)
+		}
+		stmts = append(stmts, s)
+	}
+	return stmts, nil
+}
+
+// ParseOne parses sql which has one statement exactly.
+func ParseOne(sql string) (Stmt, error) {
+	stmts, err := Parse(sql)
+	if err != nil {
+		return nil, err
+	}
+	if len(stmts) != 1 {
+		return nil, fmt.Errorf("expected one statement, but got %d", len(stmts))
+	}
+	return stmts[0], nil
+}
+
+func text(sql string, tokens []Token) string {
+	if len(tokens) == 0 {
+		return ""
+	}
+	return sql[tokens[0].Pos:tokens[len(tokens)-1].End]
+}
+
+func splitTokens(tokens []Token) [][]Token {
+	var groups [][]Token
+	start := 0
+	plsql := false
+	emit := func(end int) {
+		if end > start {
+			groups = append(groups, tokens[start:end])
+		}
+		start = end + 1
+	}
+	for i, t := range tokens {
+		if i == start {
+			plsql = isPLSQL(tokens[i:])
+		}
+		switch {
+		case t.Type == TokenSlash:
+			emit(i)
+		case t.IsPunct(";") && !plsql:
+			emit(i)
+		}
+	}
+	emit(len(tokens))
+	return groups
+}
+
+var plsqlUnits = []string{"FUNCTION", "PROCEDURE", "PACKAGE", "TRIGGER", "TYPE", "LIBRARY"}
+
+// isPLSQL returns true if tokens start with anonymous block or stored PL/SQL unit.
+func isPLSQL(tokens []Token) bool {
+	p := &parser{tokens: tokens}
+	if p.acceptKeywords("DECLARE") || p.acceptKeywords("BEGIN") {
+		return true
+	}
+	if !p.acceptKeywords("CREATE") {
+		return false
+	}
+	p.acceptKeywords("OR", "REPLACE")
+	if !p.acceptKeywords("EDITIONABLE") && !p.acceptKeywords("NONEDITIONABLE") {
+		p.acceptKeywords("EDITIONING")
+	}
+	for _, unit := range plsqlUnits {
+		if p.peek().IsKeyword(unit) {
+			return true
+		}
+	}
+	return false
+}
+
+type parser struct {
+	sql    string
+	tokens []Token
+	pos    int
+}
+
+func (p *parser) eof() bool {
+	return p.pos >= len(p.tokens)
+}
+
+func (p *parser) peek() Token {
+	return p.peekN(0)
+}
+
+func (p *parser) peekN(n int) Token {
+	if p.pos+n >= len(p.tokens) {
+		return Token{Type: TokenEOF}
+	}
+	return p.tokens[p.pos+n]
+}
+
+func (p *parser) next() Token {
+	t := p.peek()
+	if !p.eof() {
+		p.pos++
+	}
+	return t
+}
+
+// acceptKeywords consumes the keywords if the next tokens are kws.
+func (p *parser) acceptKeywords(kws ...string) bool {
+	for i, kw := range kws {
+		if !p.peekN(i).IsKeyword(kw) {
+			return false
+		}
+	}
+	p.pos += len(kws)
+	return true
+}
+
+func (p *parser) expectKeywords(kws ...string) error {
+	if !p.acceptKeywords(kws...) {
+		return p.unexpected(strings.Join(kws, " "))
+	}
+	return nil
+}
+
+func (p *parser) acceptPunct(punct string) bool {
+	if p.peek().IsPunct(punct) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) unexpected(expected string) error {
+	if p.eof() {
+		return fmt.Errorf("expected %s, but got end of statement", expected)
+	}
+	t := p.peek()
+	return fmt.Errorf("expected %s, but got %q at position %d", expected, p.sql[t.Pos:t.End], t.Pos)
+}
+
+func (p *parser) parseName() (string, error) {
+	if !p.peek().IsName() {
+		return "", p.unexpected("name")
+	}
+	return p.next().Value, nil
+}
+
+// parseObjectName parses "name" or "schema.name", the database link such as
+// "name@link" is dropped.
+func (p *parser) parseObjectName() (*ObjectName, error) {
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	n := &ObjectName{Name: name}
+	if p.acceptPunct(".") {
+		if n.Name, err = p.parseName(); err != nil {
+			return nil, err
+		}
+		n.Schema = name
+	}
+	if p.peek().Is(TokenOperator, "@") {
+		p.next()
+		if _, err := p.parseObjectName(); err != nil {
+			return nil, err
+		}
+	}
+	return n, nil
+}
+
+func (p *parser) parseNameList() ([]string, error) {
+	if !p.acceptPunct("(") {
+		return nil, p.unexpected("(")
+	}
+	var names []string
+	for {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if p.acceptPunct(")") {
+			return names, nil
+		}
+		if !p.acceptPunct(",") {
+			return nil, p.unexpected(", or )")
+		}
+	}
+}
+
+// skipParens consumes the tokens in parentheses if the next token is "(",
+// it returns the tokens between the parentheses.
+func (p *parser) skipParens() []Token {
+	if !p.peek().IsPunct("(") {
+		return nil
+	}
+	start := p.pos
+	depth := 0
+	for !p.eof() {
+		t := p.next()
+		if t.IsPunct("(") {
+			depth++
+		} else if t.IsPunct(")") {
+			depth--
+			if depth == 0 {
+				return p.tokens[start+1 : p.pos-1]
+			}
+		}
+	}
+	return p.tokens[start+1 : p.pos]
+}
+
+// consumeUntil consumes the tokens until stop returns true for the token
+// which is not in parentheses, it returns the consumed tokens.
+func (p *parser) consumeUntil(stop func(t Token) bool) []Token {
+	start := p.pos
+	for !p.eof() {
+		if p.peek().IsPunct("(") {
+			p.skipParens()
+			continue
+		}
+		if stop(p.peek()) {
+			break
+		}
+		p.next()
+	}
+	return p.tokens[start:p.pos]
+}
+
+func keywordIn(kws ...string) func(t Token) bool {
+	return func(t Token) bool {
+		for _, kw := range kws {
+			if t.IsKeyword(kw) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// splitByComma splits tokens by "," which is not in parentheses.
+func splitByComma(tokens []Token) [][]Token {
+	var items [][]Token
+	depth, start := 0, 0
+	for i, t := range tokens {
+		switch {
+		case t.IsPunct("("):
+			depth++
+		case t.IsPunct(")"):
+			depth--
+		case t.IsPunct(",") && depth == 0:
+			items = append(items, tokens[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		items = append(items, tokens[start:])
+	}
+	return items
+}
+
+func (p *parser) parseStmt() (Stmt, error) {
+	var s Stmt
+	var err error
+	switch first := p.peek(); {
+	case isPLSQL(p.tokens):
+		s = &PLSQLStmt{Keywords: p.leadingKeywords()}
+	case first.IsKeyword("CREATE"):
+		s, err = p.parseCreate()
+	case first.IsKeyword("ALTER"):
+		s, err = p.parseAlter()
+	case first.IsKeyword("DROP"):
+		s, err = p.parseDrop()
+	case first.IsKeyword("TRUNCATE"):
+		s, err = p.parseTruncate()
+	case keywordIn("SELECT", "WITH", "INSERT", "UPDATE", "DELETE", "MERGE")(first):
+		s = p.parseDML()
+	default:
+		s = &UnknownStmt{Keywords: p.leadingKeywords()}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	literals, binds := 0, 0
+	for _, t := range p.tokens {
+		if t.IsLiteral() {
+			literals++
+		} else if t.Type == TokenBind {
+			binds++
+		}
+	}
+	s.(interface{ setCounts(int, int) }).setCounts(literals, binds)
+	s.(interface{ setText(string) }).setText(text(p.sql, p.tokens))
+	return s, nil
+}
+
+// leadingKeywords returns the leading unquoted identifiers, at most 3 words.
+func (p *parser) leadingKeywords() string {
+	var kws []string
+	for _, t := range p.tokens {
+		if t.Type != TokenIdent || len(kws) == 3 {
+			break
+		}
+		kws = append(kws, t.Value)
+	}
+	return strings.Join(kws, " ")
+}
+
+func (p *parser) parseCreate() (Stmt, error) {
+	p.next()
+	p.acceptKeywords("OR", "REPLACE")
+	switch {
+	case p.acceptKeywords("GLOBAL", "TEMPORARY", "TABLE"), p.acceptKeywords("PRIVATE", "TEMPORARY", "TABLE"):
+		s, err := p.parseCreateTable()
+		if err != nil {
+			return nil, err
+		}
+		s.Temporary = true
+		return s, nil
+	case p.acceptKeywords("TABLE"), p.acceptKeywords("SHARDED", "TABLE"), p.acceptKeywords("DUPLICATED", "TABLE"),
+		p.acceptKeywords("BLOCKCHAIN", "TABLE"), p.acceptKeywords("IMMUTABLE", "TABLE"):
+		return p.parseCreateTable()
+	case p.peek().IsKeyword("INDEX"), p.peekN(1).IsKeyword("INDEX") && keywordIn("UNIQUE", "BITMAP", "MULTIVALUE")(p.peek()):
+		return p.parseCreateIndex()
+	case p.acceptKeywords("SEQUENCE"):
+		return p.parseSequence(false)
+	}
+	return &UnknownStmt{Keywords: p.leadingKeywords()}, nil
+}
+
+func tableConstraintStart(t Token) bool {
+	return keywordIn("CONSTRAINT", "PRIMARY", "UNIQUE", "FOREIGN", "CHECK", "SUPPLEMENTAL", "PERIOD")(t)
+}
+
+func (p *parser) parseCreateTable() (*CreateTableStmt, error) {
+	s := &CreateTableStmt{}
+	p.acceptKeywords("IF", "NOT", "EXISTS")
+	table, err := p.parseObjectName()
+	if err != nil {
+		return nil, err
+	}
+	s.Table = table
+
+	if p.peek().IsPunct("(") {
+		for _, item := range splitByComma(p.skipParens()) {
+			if len(item) == 0 {
+				continue
+			}
+			if tableConstraintStart(item[0]) {
+				if hasPrimaryKey(item) {
+					s.HasPrimaryKey = true
+				}
+				continue
+			}
+			column, err := p.parseColumnDef(item)
+			if err != nil {
+				return nil, err
+			}
+			if column.PrimaryKey {
+				s.HasPrimaryKey = true
+			}
+			s.Columns = append(s.Columns, column)
+		}
+	}
+	for !p.eof() {
+		if p.peek().IsPunct("(") {
+			p.skipParens()
+			continue
+		}
+		if p.next().IsKeyword("AS") && keywordIn("SELECT", "WITH")(p.peek()) {
+			s.AsSelect = true
+			break
+		}
+	}
+	return s, nil
+}
+
+// hasPrimaryKey returns true if "PRIMARY KEY" is in tokens which are not in parentheses.
+func hasPrimaryKey(tokens []Token) bool {
+	depth := 0
+	for i, t := range tokens {
+		switch {
+		case t.IsPunct("("):
+			depth++
+		case t.IsPunct(")"):
+			depth--
+		case depth == 0 && t.IsKeyword("PRIMARY") && i+1 < len(tokens) && tokens[i+1].IsKeyword("KEY"):
+			return true
+		}
+	}
+	return false
+}
+
+func columnConstraintStart(t Token) bool {
+	return keywordIn("DEFAULT", "NOT", "NULL", "CONSTRAINT", "PRIMARY", "UNIQUE", "REFERENCES", "CHECK",
+		"COLLATE", "ENCRYPT", "GENERATED", "VISIBLE", "INVISIBLE", "SORT", "AS")(t)
+}
+
+// parseColumnDef parses the column definition in tokens.
+func (p *parser) parseColumnDef(tokens []Token) (*ColumnDef, error) {
+	cp := &parser{sql: p.sql, tokens: tokens}
+	name, err := cp.parseName()
+	if err != nil {
+		return nil, err
+	}
+	c := &ColumnDef{Name: name}
+	c.Type = text(p.sql, cp.consumeUntil(columnConstraintStart))
+
+	for !cp.eof() {
+		switch {
+		case cp.acceptKeywords("DEFAULT"):
+			cp.acceptKeywords("ON", "NULL")
+			c.Default = text(p.sql, cp.consumeUntil(keywordIn("NOT", "NULL", "CONSTRAINT", "PRIMARY", "UNIQUE",
+				"REFERENCES", "CHECK", "ENCRYPT", "VISIBLE", "INVISIBLE")))
+		case cp.acceptKeywords("NOT", "NULL"):
+			c.NotNull = true
+		case cp.acceptKeywords("PRIMARY", "KEY"):
+			c.PrimaryKey = true
+			c.NotNull = true
+		default:
+			cp.next()
+		}
+	}
+	return c, nil
+}
+
+func (p *parser) parseCreateIndex() (*CreateIndexStmt, error) {
+	s := &CreateIndexStmt{}
+	s.Unique = p.acceptKeywords("UNIQUE")
+	p.acceptKeywords("BITMAP")
+	p.acceptKeywords("MULTIVALUE")
+	if err := p.expectKeywords("INDEX"); err != nil {
+		return nil, err
+	}
+	p.acceptKeywords("IF", "NOT", "EXISTS")
+	name, err := p.parseObjectName()
+	if err != nil {
+		return nil, err
+	}
+	s.Name = name
+	if err := p.expectKeywords("ON"); err != nil {
+		return nil, err
+	}
+	p.acceptKeywords("CLUSTER")
+	if s.Table, err = p.parseObjectName(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (p *parser) parseSequence(alter bool) (*SequenceStmt, error) {
+	s := &SequenceStmt{Alter: alter, Cache: -1}
+	p.acceptKeywords("IF", "NOT", "EXISTS")
+	name, err := p.parseObjectName()
+	if err != nil {
+		return nil, err
+	}
+	s.Name = name
+	for !p.eof() {
+		t := p.next()
+		switch {
+		case t.IsKeyword("NOCACHE"):
+			s.NoCache = true
+		case t.IsKeyword("CACHE") && p.peek().Type == TokenNumber:
+			cache, err := strconv.Atoi(p.next().Value)
+			if err != nil {
+				return nil, err
+			}
+			s.Cache = cache
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) parseAlter() (Stmt, error) {
+	p.next()
+	switch {
+	case p.acceptKeywords("TABLE"):
+		return p.parseAlterTable()
+	case p.acceptKeywords("SEQUENCE"):
+		return p.parseSequence(true)
+	}
+	return &UnknownStmt{Keywords: p.leadingKeywords()}, nil
+}
+
+var alterTableClauseStart = keywordIn("ADD", "MODIFY", "DROP", "RENAME", "SET", "MOVE", "ENABLE", "DISABLE")
+
+func (p *parser) parseAlterTable() (Stmt, error) {
+	s := &AlterTableStmt{}
+	table, err := p.parseObjectName()
+	if err != nil {
+		return nil, err
+	}
+	s.Table = table
+	for !p.eof() {
+		start := p.pos
+		cmds, err := p.parseAlterTableClause()
+		if err != nil {
+			return nil, err
+		}
+		// the options of clause, such as "CASCADE CONSTRAINTS".
+		p.consumeUntil(alterTableClauseStart)
+		for _, cmd := range cmds {
+			cmd.Text = text(p.sql, p.tokens[start:p.pos])
+		}
+		s.Cmds = append(s.Cmds, cmds...)
+	}
+	return s, nil
+}
+
+func (p *parser) parseAlterTableClause() ([]*AlterTableCmd, error) {
+	switch t := p.next(); {
+	case t.IsKeyword("ADD"):
+		return p.parseAlterTableAdd()
+	case t.IsKeyword("MODIFY"):
+		if tableConstraintStart(p.peek()) || keywordIn("PARTITION", "SUBPARTITION", "DEFAULT", "LOB", "NESTED")(p.peek()) {
+			break
+		}
+		columns, err := p.parseColumnDefs()
+		if err != nil {
+			return nil, err
+		}
+		return []*AlterTableCmd{{Type: AlterTableModifyColumn, Columns: columns}}, nil
+	case t.IsKeyword("DROP"):
+		switch {
+		case p.acceptKeywords("COLUMN"):
+			name, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			return []*AlterTableCmd{{Type: AlterTableDropColumn, ColumnNames: []string{name}}}, nil
+		case p.peek().IsPunct("("):
+			names, err := p.parseNameList()
+			if err != nil {
+				return nil, err
+			}
+			return []*AlterTableCmd{{Type: AlterTableDropColumn, ColumnNames: names}}, nil
+		case p.acceptKeywords("CONSTRAINT"):
+			name, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			return []*AlterTableCmd{{Type: AlterTableDropConstraint, ConstraintName: name}}, nil
+		case p.acceptKeywords("PRIMARY", "KEY"):
+			return []*AlterTableCmd{{Type: AlterTableDropPrimaryKey}}, nil
+		}
+	case t.IsKeyword("RENAME"):
+		switch {
+		case p.acceptKeywords("COLUMN"):
+			cmd := &AlterTableCmd{Type: AlterTableRenameColumn}
+			name, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			cmd.ColumnNames = []string{name}
+			if err := p.expectKeywords("TO"); err != nil {
+				return nil, err
+			}
+			if cmd.NewName, err = p.parseName(); err != nil {
+				return nil, err
+			}
+			return []*AlterTableCmd{cmd}, nil
+		case p.acceptKeywords("TO"):
+			name, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			return []*AlterTableCmd{{Type: AlterTableRenameTable, NewName: name}}, nil
+		}
+	}
+	p.consumeUntil(alterTableClauseStart)
+	return []*AlterTableCmd{{Type: AlterTableOther}}, nil
+}
+
+func (p *parser) parseAlterTableAdd() ([]*AlterTableCmd, error) {
+	if tableConstraintStart(p.peek()) {
+		return []*AlterTableCmd{p.parseAddConstraint(p.consumeUntil(alterTableClauseStart))}, nil
+	}
+	if p.peek().IsPunct("(") && len(p.tokens) > p.pos+1 && tableConstraintStart(p.tokens[p.pos+1]) {
+		var cmds []*AlterTableCmd
+		for _, item := range splitByComma(p.skipParens()) {
+			cmds = append(cmds, p.parseAddConstraint(item))
+		}
+		return cmds, nil
+	}
+	columns, err := p.parseColumnDefs()
+	if err != nil {
+		return nil, err
+	}
+	return []*AlterTableCmd{{Type: AlterTableAddColumn, Columns: columns}}, nil
+}
+
+func (p *parser) parseAddConstraint(tokens []Token) *AlterTableCmd {
+	cmd := &AlterTableCmd{Type: AlterTableAddConstraint, PrimaryKey: hasPrimaryKey(tokens)}
+	if len(tokens) > 1 && tokens[0].IsKeyword("CONSTRAINT") && tokens[1].IsName() {
+		cmd.ConstraintName = tokens[1].Value
+	}
+	return cmd
+}
+
+// parseColumnDefs parses "(column, ...)" or one column of ADD and MODIFY clause.
+func (p *parser) parseColumnDefs() ([]*ColumnDef, error) {
+	var items [][]Token
+	if p.peek().IsPunct("(") {
+		items = splitByComma(p.skipParens())
+	} else {
+		items = [][]Token{p.consumeUntil(alterTableClauseStart)}
+	}
+	columns := make([]*ColumnDef, 0, len(items))
+	for _, item := range items {
+		column, err := p.parseColumnDef(item)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// objectTypes is the types of object in DROP statement, the longer type is in front.
+var objectTypes = [][]string{
+	{"MATERIALIZED", "VIEW", "LOG"}, {"MATERIALIZED", "VIEW"}, {"PUBLIC", "DATABASE", "LINK"},
+	{"DATABASE", "LINK"}, {"PUBLIC", "SYNONYM"}, {"PACKAGE", "BODY"}, {"TYPE", "BODY"},
+}
+
+func (p *parser) parseDrop() (Stmt, error) {
+	p.next()
+	s := &DropStmt{}
+	for _, typ := range objectTypes {
+		if p.acceptKeywords(typ...) {
+			s.ObjectType = strings.Join(typ, " ")
+			break
+		}
+	}
+	if s.ObjectType == "" {
+		t := p.next()
+		if t.Type != TokenIdent {
+			p.pos--
+			return nil, p.unexpected("object type")
+		}
+		s.ObjectType = t.Value
+	}
+	p.acceptKeywords("IF", "EXISTS")
+	if s.ObjectType == "MATERIALIZED VIEW LOG" {
+		p.acceptKeywords("ON")
+	}
+	name, err := p.parseObjectName()
+	if err != nil {
+		return nil, err
+	}
+	s.Name = name
+	for !p.eof() {
+		if p.next().IsKeyword("PURGE") {
+			s.Purge = true
+		}
+	}
+	return s, nil
+}
+
+func (p *parser) parseTruncate() (Stmt, error) {
+	p.next()
+	if !p.acceptKeywords("TABLE") {
+		return &UnknownStmt{Keywords: p.leadingKeywords()}, nil
+	}
+	table, err := p.parseObjectName()
+	if err != nil {
+		return nil, err
+	}
+	return &TruncateStmt{Table: table}, nil
+}
+
+func (p *parser) parseDML() Stmt {
+	s := &DMLStmt{Keyword: p.peek().Value}
+	if s.Keyword == "WITH" {
+		// the main statement of "WITH ... SELECT/INSERT/..." is after the CTEs.
+		for i := 1; i < len(p.tokens); i++ {
+			depth := depthAt(p.tokens, i)
+			if depth == 0 && keywordIn("SELECT", "INSERT", "UPDATE", "DELETE", "MERGE")(p.tokens[i]) {
+				s.Keyword = p.tokens[i].Value
+				break
+			}
+		}
+	}
+	s.SelectStarPos, s.SelectStar = selectStarPos(p.tokens)
+	s.CartesianJoins = cartesianJoins(p.tokens)
+	return s
+}
+
+// depthAt returns the parentheses depth of tokens[i].
+func depthAt(tokens []Token, i int) int {
+	depth := 0
+	for _, t := range tokens[:i] {
+		if t.IsPunct("(") {
+			depth++
+		} else if t.IsPunct(")") {
+			depth--
+		}
+	}
+	return depth
+}
+
+// queryClauseEnd is the keywords which terminate FROM or WHERE clause.
+var queryClauseEnd = keywordIn("WHERE", "GROUP", "ORDER", "CONNECT", "START", "HAVING", "UNION", "INTERSECT",
+	"MINUS", "EXCEPT", "FETCH", "OFFSET", "FOR", "MODEL", "WINDOW", "RETURNING", "RETURN", "LOG", "SET", "QUALIFY")
+
+// clause returns the tokens after tokens[start] until stop returns true for
+// the token in the same parentheses, or the parentheses is closed.
+func clause(tokens []Token, start int, stop func(t Token) bool) []Token {
+	depth := 0
+	i := start + 1
+	for ; i < len(tokens); i++ {
+		t := tokens[i]
+		if t.IsPunct("(") {
+			depth++
+		} else if t.IsPunct(")") {
+			depth--
+			if depth < 0 {
+				break
+			}
+		} else if depth == 0 && stop(t) {
+			break
+		}
+	}
+	return tokens[start+1 : i]
+}
+
+// selectStarPos returns the offset of the first "*" item in the select lists,
+// ok is false if there is no "*" item.
+func selectStarPos(tokens []Token) (pos int, ok bool) {
+	for i, t := range tokens {
+		if !t.IsKeyword("SELECT") {
+			continue
+		}
+		for _, item := range splitByComma(clause(tokens, i, keywordIn("FROM", "INTO"))) {
+			if item = trimSetQuantifier(item); isStarItem(item) {
+				return item[0].Pos, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// trimSetQuantifier removes the leading DISTINCT, UNIQUE or ALL of select item.
+func trimSetQuantifier(item []Token) []Token {
+	for len(item) > 0 && keywordIn("DISTINCT", "UNIQUE", "ALL")(item[0]) {
+		item = item[1:]
+	}
+	return item
+}
+
+// isStarItem returns true if item is "*", "t.*" or "s.t.*".
+func isStarItem(item []Token) bool {
+	if len(item) == 0 || !item[len(item)-1].Is(TokenOperator, "*") {
+		return false
+	}
+	for i := len(item) - 2; i >= 0; i -= 2 {
+		if !item[i].IsPunct(".") || i == 0 || !item[i-1].IsName() {
+			return false
+		}
+		if i == 1 {
+			return true
+		}
+	}
+	return len(item) == 1
+}
+
+// tableRefEnd is the keywords after table reference in FROM clause.
+var tableRefEnd = keywordIn("JOIN", "INNER", "LEFT", "RIGHT", "FULL", "CROSS", "NATURAL", "OUTER", "ON", "USING",
+	"PARTITION", "SUBPARTITION", "SAMPLE", "AS", "PIVOT", "UNPIVOT", "APPLY", "LATERAL", "VERSIONS")
+
+// tableRefNames returns the table name and alias of table reference at tokens[i].
+func tableRefNames(tokens []Token, i int) []string {
+	var names []string
+	switch {
+	case i >= len(tokens):
+		return nil
+	case tokens[i].IsPunct("("), tokens[i].IsName() && i+1 < len(tokens) && tokens[i+1].IsPunct("("):
+		// sub query or collection expression, such as "TABLE(f())".
+		if !tokens[i].IsPunct("(") {
+			i++
+		}
+		i += len(clause(tokens, i, func(Token) bool { return false })) + 2
+	case tokens[i].IsName():
+		// the name of "schema.table", the table name is used as qualifier.
+		for i+2 < len(tokens) && tokens[i+1].IsPunct(".") && tokens[i+2].IsName() {
+			i += 2
+		}
+		names = append(names, tokens[i].Value)
+		i++
+		if i+1 < len(tokens) && tokens[i].Is(TokenOperator, "@") {
+			i += 2
+		}
+	default:
+		return nil
+	}
+	if i < len(tokens) && tokens[i].IsKeyword("AS") {
+		i++
+	}
+	if i < len(tokens) && tokens[i].IsName() && !tableRefEnd(tokens[i]) && !queryClauseEnd(tokens[i]) {
+		names = append(names, tokens[i].Value)
+	}
+	return names
+}
+
+// cartesianJoins returns the tables which are joined without join condition,
+// the tables of each query block are separated by ", ".
+func cartesianJoins(tokens []Token) []string {
+	var joins []string
+	for i, t := range tokens {
+		if !t.IsKeyword("FROM") {
+			continue
+		}
+		from := clause(tokens, i, queryClauseEnd)
+		items := splitByComma(from)
+
+		// groups[k] is the names of k-th table item, the tables which are joined
+		// by JOIN ... ON are in one item.
+		groups := make([][]string, 0, len(items))
+		for _, item := range items {
+			var names []string
+			for j := range item {
+				if j == 0 || item[j-1].IsKeyword("JOIN") {
+					if j > 1 && item[j-1].IsKeyword("JOIN") && item[j-2].IsKeyword("CROSS") {
+						joins = append(joins, fmt.Sprintf("%s, %s", firstName(item), firstName(item[j:])))
+					}
+					names = append(names, tableRefNames(item, j)...)
+				}
+			}
+			groups = append(groups, names)
+		}
+		if len(groups) < 2 {
+			continue
+		}
+
+		var where []Token
+		if end := i + len(from) + 1; end < len(tokens) && tokens[end].IsKeyword("WHERE") {
+			where = clause(tokens, end, queryClauseEnd)
+		}
+		if !isConnected(groups, where) {
+			var tables []string
+			for _, item := range items {
+				tables = append(tables, firstName(item))
+			}
+			joins = append(joins, strings.Join(tables, ", "))
+		}
+	}
+	return joins
+}
+
+// firstName returns the first name of tokens, it is used to show the table in message.
+func firstName(tokens []Token) string {
+	for _, t := range tokens {
+		if t.IsName() {
+			return t.Value
+		}
+	}
+	return "(...)"
+}
+
+var comparisonOperators = map[string]struct{}{
+	"=": {}, "<": {}, ">": {}, "<=": {}, ">=": {}, "<>": {}, "!=": {}, "^=": {},
+}
+
+// isConnected returns true if the table groups are connected by the join
+// conditions in where, such as "t1.id = t2.id".
+func isConnected(groups [][]string, where []Token) bool {
+	parent := make([]int, len(groups))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(i int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	groupOf := func(name string) int {
+		for i, names := range groups {
+			for _, n := range names {
+				if n == name {
+					return i
+				}
+			}
+		}
+		return -1
+	}
+
+	for i, t := range where {
+		if _, ok := comparisonOperators[t.Value]; !ok || t.Type != TokenOperator {
+			continue
+		}
+		left, leftOk := columnQualifier(where, i, -1)
+		right, rightOk := columnQualifier(where, i, 1)
+		if !leftOk || !rightOk {
+			continue
+		}
+		// the columns are not qualified, it can not be known which tables are joined.
+		if left == "" || right == "" {
+			return true
+		}
+		l, r := groupOf(left), groupOf(right)
+		if l >= 0 && r >= 0 {
+			parent[find(l)] = find(r)
+		}
+	}
+
+	root := find(0)
+	for i := range groups {
+		if find(i) != root {
+			return false
+		}
+	}
+	return true
+}
+
+// columnQualifier returns the qualifier of column beside the operator at
+// where[i], direction is -1 for left operand and 1 for right operand. ok is
+// false if the operand is not a column, qualifier is empty if column is not qualified.
+func columnQualifier(where []Token, i, direction int) (qualifier string, ok bool) {
+	at := func(j int) Token {
+		if j < 0 || j >= len(where) {
+			return Token{Type: TokenEOF}
+		}
+		return where[j]
+	}
+	// isOuterJoin returns true if where[j:j+3] is "(+)" of Oracle outer join.
+	isOuterJoin := func(j int) bool {
+		return at(j).IsPunct("(") && at(j+1).Is(TokenOperator, "+") && at(j+2).IsPunct(")")
+	}
+
+	// first and last are the index of operand, such as "t.c" in "t.c(+)".
+	var first, last int
+	if direction < 0 {
+		last = i - 1
+		if isOuterJoin(last - 2) {
+			last -= 3
+		}
+		first = last
+		if at(last - 1).IsPunct(".") {
+			first = last - 2
+		}
+	} else {
+		first = i + 1
+		last = first
+		if at(first + 1).IsPunct(".") {
+			last = first + 2
+		}
+		if at(last+1).IsPunct("(") && !isOuterJoin(last+1) {
+			// function call, such as "a = f(b)".
+			return "", false
+		}
+	}
+	if !at(first).IsName() || !at(last).IsName() {
+		return "", false
+	}
+	if first == last {
+		_, pseudo := pseudoColumns[at(first).Value]
+		return "", !pseudo || at(first).Type == TokenQuotedIdent
+	}
+	return at(first).Value, true
+}
+
+// pseudoColumns is the keywords which may be used as operand of comparison,
+// they are not the columns of table.
+var pseudoColumns = map[string]struct{}{
+	"SYSDATE": {}, "SYSTIMESTAMP": {}, "CURRENT_DATE": {}, "CURRENT_TIMESTAMP": {}, "LOCALTIMESTAMP": {},
+	"USER": {}, "UID": {}, "ROWNUM": {}, "LEVEL": {}, "NULL": {}, "TRUE": {}, "FALSE": {}, "DATE": {},
+	"TIMESTAMP": {}, "INTERVAL": {}, "ANY": {}, "ALL": {}, "SOME": {}, "PRIOR": {},
+}
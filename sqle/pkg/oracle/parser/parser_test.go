@@ -0,0 +1,175 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplit(t *testing.T) {
+	sqls, err := Split(`select 1 from dual;
+create or replace procedure p is
+begin
+  update t set a = 1;
+  commit;
+end;
+/
+insert into t values ('a;b');
+begin null; end;
+/
+select 2 from dual`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{
+		"select 1 from dual",
+		"create or replace procedure p is\nbegin\n  update t set a = 1;\n  commit;\nend;",
+		"insert into t values ('a;b')",
+		"begin null; end;",
+		"select 2 from dual",
+	}, sqls)
+}
+
+func TestParseLiterals(t *testing.T) {
+	stmt, err := ParseOne(`select * from t where a = 'x' and b = 1 and c = q'[it's]' and d = :1`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 3, stmt.Literals())
+	assert.Equal(t, 1, stmt.BindVariables())
+}
+
+func TestParseCreateTable(t *testing.T) {
+	stmt, err := ParseOne(`CREATE TABLE scott.t1 (
+  id NUMBER(10) NOT NULL,
+  name VARCHAR2(20 CHAR) DEFAULT 'a' NOT NULL,
+  CONSTRAINT pk_t1 PRIMARY KEY (id)
+) TABLESPACE users`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	s, ok := stmt.(*CreateTableStmt)
+	if !assert.True(t, ok) {
+		t.FailNow()
+	}
+	assert.Equal(t, "SCOTT.T1", s.Table.String())
+	assert.True(t, s.HasPrimaryKey)
+	if assert.Len(t, s.Columns, 2) {
+		assert.Equal(t, "NUMBER(10)", s.Columns[0].Type)
+		assert.True(t, s.Columns[0].NotNull)
+		assert.Equal(t, "VARCHAR2(20 CHAR)", s.Columns[1].Type)
+		assert.Equal(t, "'a'", s.Columns[1].Default)
+	}
+
+	stmt, err = ParseOne(`create global temporary table "tmp" (id number primary key) on commit delete rows`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	s = stmt.(*CreateTableStmt)
+	assert.Equal(t, `"tmp"`, s.Table.String())
+	assert.True(t, s.Temporary)
+	assert.True(t, s.HasPrimaryKey)
+
+	stmt, err = ParseOne(`create table t2 as select * from t1`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	s = stmt.(*CreateTableStmt)
+	assert.True(t, s.AsSelect)
+	assert.False(t, s.HasPrimaryKey)
+}
+
+func TestParseAlterTable(t *testing.T) {
+	stmt, err := ParseOne(`alter table t1 add (c1 number, c2 date) modify c3 varchar2(10) drop column c4
+rename column c5 to c6 drop constraint ck_1 cascade add constraint pk_t1 primary key (id) drop primary key rename to t2`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	s := stmt.(*AlterTableStmt)
+	if !assert.Len(t, s.Cmds, 8) {
+		t.FailNow()
+	}
+	assert.Equal(t, AlterTableAddColumn, s.Cmds[0].Type)
+	assert.Len(t, s.Cmds[0].Columns, 2)
+	assert.Equal(t, AlterTableModifyColumn, s.Cmds[1].Type)
+	assert.Equal(t, "varchar2(10)", s.Cmds[1].Columns[0].Type)
+	assert.Equal(t, AlterTableDropColumn, s.Cmds[2].Type)
+	assert.Equal(t, []string{"C4"}, s.Cmds[2].ColumnNames)
+	assert.Equal(t, AlterTableRenameColumn, s.Cmds[3].Type)
+	assert.Equal(t, "C6", s.Cmds[3].NewName)
+	assert.Equal(t, AlterTableDropConstraint, s.Cmds[4].Type)
+	assert.Equal(t, "drop constraint ck_1 cascade", s.Cmds[4].Text)
+	assert.Equal(t, AlterTableAddConstraint, s.Cmds[5].Type)
+	assert.Equal(t, "PK_T1", s.Cmds[5].ConstraintName)
+	assert.True(t, s.Cmds[5].PrimaryKey)
+	assert.Equal(t, AlterTableDropPrimaryKey, s.Cmds[6].Type)
+	assert.Equal(t, AlterTableRenameTable, s.Cmds[7].Type)
+}
+
+func TestParseSequenceAndDrop(t *testing.T) {
+	stmt, err := ParseOne(`create sequence seq1 start with 1 increment by 1 cache 100`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 100, stmt.(*SequenceStmt).Cache)
+
+	stmt, err = ParseOne(`create sequence seq1 nocache`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, stmt.(*SequenceStmt).NoCache)
+	assert.Equal(t, -1, stmt.(*SequenceStmt).Cache)
+
+	stmt, err = ParseOne(`drop materialized view log on t1`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "MATERIALIZED VIEW LOG", stmt.(*DropStmt).ObjectType)
+
+	stmt, err = ParseOne(`drop table t1 cascade constraints purge`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.True(t, stmt.(*DropStmt).Purge)
+
+	stmt, err = ParseOne(`truncate table s.t1`)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "S.T1", stmt.(*TruncateStmt).Table.String())
+}
+
+func TestParseDML(t *testing.T) {
+	cases := []struct {
+		sql        string
+		selectStar bool
+		cartesian  []string
+	}{
+		{"select a, b from t1", false, nil},
+		{"select * from t1", true, nil},
+		{"select t1.*, t2.a from t1 join t2 on t1.id = t2.id", true, nil},
+		{"select count(*) from t1", false, nil},
+		{"select a from t1, t2", false, []string{"T1, T2"}},
+		{"select a from t1 x, t2 y where x.id = y.id", false, nil},
+		{"select a from t1 x, t2 y where x.id = y.id(+)", false, nil},
+		{"select a from t1, t2, t3 where t1.id = t2.id and t1.c = sysdate", false, []string{"T1, T2, T3"}},
+		{"select a from t1, t2 where id = t2.id", false, nil},
+		{"select a from t1 cross join t2", false, []string{"T1, T2"}},
+		{"select a from t1 where exists (select * from t2, t3 where t2.a = 1)", true, []string{"T2, T3"}},
+		{"select extract(year from d) from t1", false, nil},
+		{"insert into t2 select * from t1", true, nil},
+		{"with a as (select 1 x from dual) select x from a", false, nil},
+	}
+	for _, c := range cases {
+		stmt, err := ParseOne(c.sql)
+		if !assert.NoError(t, err, c.sql) {
+			continue
+		}
+		s, ok := stmt.(*DMLStmt)
+		if !assert.True(t, ok, c.sql) {
+			continue
+		}
+		assert.Equal(t, c.selectStar, s.SelectStar, c.sql)
+		assert.Equal(t, c.cartesian, s.CartesianJoins, c.sql)
+	}
+}
@@ -0,0 +1,34 @@
+// Package plugin serves the Oracle plugin by the driver adaptor, the main
+// package of plugin only needs to call Serve.
+package plugin
+
+import (
+	"context"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	adaptor "github.com/actiontech/sqle/sqle/pkg/driver"
+	"github.com/actiontech/sqle/sqle/pkg/oracle/parser"
+	"github.com/actiontech/sqle/sqle/pkg/oracle/rollback"
+	"github.com/actiontech/sqle/sqle/pkg/oracle/rule"
+)
+
+// Serve serves the Oracle plugin with the Oracle rules and the DDL rollback.
+func Serve(version string) {
+	a := adaptor.NewAdaptor(&adaptor.OracleDialector{})
+	for i := range rule.RuleHandlers {
+		rh := &rule.RuleHandlers[i]
+		a.AddLocatedRuleWithSQLParser(&rh.Rule, func(ctx context.Context, r *driver.Rule, ast interface{}) (string, int, error) {
+			msg, err := rh.Audit(r, ast)
+			if err != nil || msg == "" {
+				return msg, -1, err
+			}
+			return msg, rh.Offset(ast), nil
+		})
+	}
+	a.Serve(
+		adaptor.WithSQLParser(rule.ParseSQL),
+		adaptor.WithSQLSplitter(parser.Split),
+		adaptor.WithRollbackSQLGenerator(rollback.GenerateRollbackSQL),
+		adaptor.WithVersion(version),
+	)
+}
@@ -0,0 +1,325 @@
+package rollback
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/pkg/oracle/parser"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	NotSupportStatementRollback     = "暂不支持回滚该类型的语句"
+	NotSupportAlterTableCmdRollback = "暂不支持回滚 ALTER TABLE 操作: %s"
+	NotSupportUnnamedConstraint     = "暂不支持回滚未指定名称的约束: %s"
+	NotSupportOfflineRollback       = "未连接实例, 无法获取 %s 的定义"
+	NotSupportTruncateRollback      = "TRUNCATE 操作无法回滚, 请提前备份数据"
+	UnknownObjectRollback           = "%s %s 不存在, 无法生成回滚语句"
+	UnknownColumnRollback           = "列 %s 不存在, 无法生成回滚语句"
+)
+
+// metadataObjectTypes is the object types which DBMS_METADATA.GET_DDL supports
+// for the rollback of DROP statement.
+var metadataObjectTypes = map[string]string{
+	"TABLE":             "TABLE",
+	"INDEX":             "INDEX",
+	"VIEW":              "VIEW",
+	"SEQUENCE":          "SEQUENCE",
+	"SYNONYM":           "SYNONYM",
+	"MATERIALIZED VIEW": "MATERIALIZED_VIEW",
+	"PROCEDURE":         "PROCEDURE",
+	"FUNCTION":          "FUNCTION",
+	"TRIGGER":           "TRIGGER",
+}
+
+// GenerateRollbackSQL generates the rollback SQL of Oracle DDL, it is the
+// rollback SQL generator of adaptor. conn is used to query the definition of
+// dropped or modified object, it is nil if the driver is used without instance.
+// The multiple rollback SQLs are separated by ";\n".
+func GenerateRollbackSQL(ctx context.Context, conn *sql.Conn, ast interface{}) (rollbackSQL, reason string, err error) {
+	g := &generator{ctx: ctx, conn: conn}
+	var sqls []string
+	switch stmt := ast.(type) {
+	case *parser.CreateTableStmt:
+		sqls = []string{fmt.Sprintf("DROP TABLE %s", stmt.Table)}
+	case *parser.CreateIndexStmt:
+		sqls = []string{fmt.Sprintf("DROP INDEX %s", stmt.Name)}
+	case *parser.SequenceStmt:
+		if stmt.Alter {
+			sqls, reason, err = g.generateAlterSequenceRollbackSQL(stmt)
+		} else {
+			sqls = []string{fmt.Sprintf("DROP SEQUENCE %s", stmt.Name)}
+		}
+	case *parser.DropStmt:
+		objectType, ok := metadataObjectTypes[stmt.ObjectType]
+		if !ok {
+			return "", NotSupportStatementRollback, nil
+		}
+		sqls, reason, err = g.getDDL(objectType, stmt.Name)
+	case *parser.AlterTableStmt:
+		sqls, reason, err = g.generateAlterTableRollbackSQL(stmt)
+	case *parser.TruncateStmt:
+		reason = NotSupportTruncateRollback
+	}
+	if err != nil || reason != "" {
+		return "", reason, err
+	}
+	return strings.Join(sqls, ";\n"), "", nil
+}
+
+type generator struct {
+	ctx  context.Context
+	conn *sql.Conn
+}
+
+// currentSchema is used in the dictionary queries if the name is not
+// qualified, the empty string is NULL in Oracle.
+const currentSchema = "SYS_CONTEXT('USERENV', 'CURRENT_SCHEMA')"
+
+// getDDL queries the DDL of object by DBMS_METADATA.GET_DDL.
+func (g *generator) getDDL(objectType string, name *parser.ObjectName) ([]string, string, error) {
+	if g.conn == nil {
+		return nil, fmt.Sprintf(NotSupportOfflineRollback, name), nil
+	}
+	var ddl sql.NullString
+	err := g.conn.QueryRowContext(g.ctx,
+		fmt.Sprintf("SELECT DBMS_METADATA.GET_DDL(:1, :2, NVL(:3, %s)) FROM DUAL", currentSchema),
+		objectType, name.Name, name.Schema).Scan(&ddl)
+	// ORA-31603: object not found in schema.
+	if err != nil && strings.Contains(err.Error(), "ORA-31603") {
+		return nil, fmt.Sprintf(UnknownObjectRollback, strings.ReplaceAll(objectType, "_", " "), name), nil
+	}
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "get DDL of %s", name)
+	}
+	return []string{strings.TrimSpace(ddl.String)}, "", nil
+}
+
+func (g *generator) generateAlterSequenceRollbackSQL(stmt *parser.SequenceStmt) ([]string, string, error) {
+	if g.conn == nil {
+		return nil, fmt.Sprintf(NotSupportOfflineRollback, stmt.Name), nil
+	}
+	var minValue, maxValue, incrementBy, cycle, order string
+	var cacheSize int64
+	err := g.conn.QueryRowContext(g.ctx, fmt.Sprintf(`SELECT TO_CHAR(min_value), TO_CHAR(max_value),
+TO_CHAR(increment_by), cycle_flag, order_flag, cache_size FROM all_sequences
+WHERE sequence_owner = NVL(:1, %s) AND sequence_name = :2`, currentSchema),
+		stmt.Name.Schema, stmt.Name.Name).
+		Scan(&minValue, &maxValue, &incrementBy, &cycle, &order, &cacheSize)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Sprintf(UnknownObjectRollback, "SEQUENCE", stmt.Name), nil
+	}
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "get sequence %s", stmt.Name)
+	}
+
+	cache := "NOCACHE"
+	if cacheSize > 0 {
+		cache = fmt.Sprintf("CACHE %d", cacheSize)
+	}
+	options := []string{
+		fmt.Sprintf("INCREMENT BY %s", incrementBy),
+		fmt.Sprintf("MINVALUE %s", minValue),
+		fmt.Sprintf("MAXVALUE %s", maxValue),
+		map[string]string{"Y": "CYCLE", "N": "NOCYCLE"}[cycle],
+		cache,
+		map[string]string{"Y": "ORDER", "N": "NOORDER"}[order],
+	}
+	return []string{fmt.Sprintf("ALTER SEQUENCE %s %s", stmt.Name, strings.Join(options, " "))}, "", nil
+}
+
+// generateAlterTableRollbackSQL generates the rollback SQLs of ALTER TABLE,
+// the commands are rolled back in reverse order.
+func (g *generator) generateAlterTableRollbackSQL(stmt *parser.AlterTableStmt) ([]string, string, error) {
+	table := stmt.Table
+	var sqls []string
+	for i := len(stmt.Cmds) - 1; i >= 0; i-- {
+		var s []string
+		var reason string
+		var err error
+		switch cmd := stmt.Cmds[i]; cmd.Type {
+		case parser.AlterTableAddColumn:
+			names := make([]string, 0, len(cmd.Columns))
+			for _, column := range cmd.Columns {
+				names = append(names, parser.QuoteIdent(column.Name))
+			}
+			s = []string{fmt.Sprintf("ALTER TABLE %s DROP (%s)", table, strings.Join(names, ", "))}
+		case parser.AlterTableAddConstraint:
+			switch {
+			case cmd.ConstraintName != "":
+				s = []string{fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s", table, parser.QuoteIdent(cmd.ConstraintName))}
+			case cmd.PrimaryKey:
+				s = []string{fmt.Sprintf("ALTER TABLE %s DROP PRIMARY KEY", table)}
+			default:
+				reason = fmt.Sprintf(NotSupportUnnamedConstraint, cmd.Text)
+			}
+		case parser.AlterTableRenameColumn:
+			s = []string{fmt.Sprintf("ALTER TABLE %s RENAME COLUMN %s TO %s", table,
+				parser.QuoteIdent(cmd.NewName), parser.QuoteIdent(cmd.ColumnNames[0]))}
+		case parser.AlterTableRenameTable:
+			// the commands before RENAME TO are rolled back after it.
+			newTable := &parser.ObjectName{Schema: table.Schema, Name: cmd.NewName}
+			s = []string{fmt.Sprintf("ALTER TABLE %s RENAME TO %s", newTable, parser.QuoteIdent(table.Name))}
+		case parser.AlterTableDropColumn:
+			s, reason, err = g.generateDropColumnRollbackSQL(table, cmd)
+		case parser.AlterTableModifyColumn:
+			s, reason, err = g.generateModifyColumnRollbackSQL(table, cmd)
+		case parser.AlterTableDropConstraint:
+			s, reason, err = g.generateDropConstraintRollbackSQL(table, cmd.ConstraintName)
+		case parser.AlterTableDropPrimaryKey:
+			s, reason, err = g.generateDropConstraintRollbackSQL(table, "")
+		default:
+			reason = fmt.Sprintf(NotSupportAlterTableCmdRollback, cmd.Text)
+		}
+		if err != nil || reason != "" {
+			return nil, reason, err
+		}
+		sqls = append(sqls, s...)
+	}
+	return sqls, "", nil
+}
+
+type column struct {
+	name        string
+	dataType    string
+	nullable    bool
+	dataDefault sql.NullString
+}
+
+// getColumn queries the column of table from ALL_TAB_COLUMNS, it returns nil
+// if the column does not exist.
+func (g *generator) getColumn(table *parser.ObjectName, name string) (*column, error) {
+	var dataType, charUsed, nullable string
+	var dataLength, charLength int64
+	var precision, scale sql.NullInt64
+	c := &column{name: name}
+	err := g.conn.QueryRowContext(g.ctx, fmt.Sprintf(`SELECT data_type, data_length, char_length, NVL(char_used, 'B'),
+data_precision, data_scale, nullable, data_default FROM all_tab_columns
+WHERE owner = NVL(:1, %s) AND table_name = :2 AND column_name = :3`, currentSchema),
+		table.Schema, table.Name, name).
+		Scan(&dataType, &dataLength, &charLength, &charUsed, &precision, &scale, &nullable, &c.dataDefault)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "get column %s of %s", name, table)
+	}
+	c.nullable = nullable == "Y"
+
+	switch {
+	case dataType == "VARCHAR2" || dataType == "CHAR":
+		unit := "BYTE"
+		if charUsed == "C" {
+			unit = "CHAR"
+		}
+		c.dataType = fmt.Sprintf("%s(%d %s)", dataType, charLength, unit)
+	case dataType == "NVARCHAR2" || dataType == "NCHAR":
+		c.dataType = fmt.Sprintf("%s(%d)", dataType, charLength)
+	case dataType == "RAW" || dataType == "UROWID":
+		c.dataType = fmt.Sprintf("%s(%d)", dataType, dataLength)
+	case dataType == "NUMBER" && precision.Valid && scale.Valid:
+		c.dataType = fmt.Sprintf("NUMBER(%d,%d)", precision.Int64, scale.Int64)
+	case dataType == "NUMBER" && scale.Valid:
+		// NUMBER(*,0), such as INTEGER.
+		c.dataType = fmt.Sprintf("NUMBER(*,%d)", scale.Int64)
+	case dataType == "FLOAT" && precision.Valid:
+		c.dataType = fmt.Sprintf("FLOAT(%d)", precision.Int64)
+	default:
+		c.dataType = dataType
+	}
+	return c, nil
+}
+
+func (c *column) definition() string {
+	def := fmt.Sprintf("%s %s", parser.QuoteIdent(c.name), c.dataType)
+	if c.dataDefault.Valid && strings.TrimSpace(c.dataDefault.String) != "" {
+		def += " DEFAULT " + strings.TrimSpace(c.dataDefault.String)
+	}
+	if !c.nullable {
+		def += " NOT NULL"
+	}
+	return def
+}
+
+func (g *generator) generateDropColumnRollbackSQL(table *parser.ObjectName, cmd *parser.AlterTableCmd) ([]string, string, error) {
+	if g.conn == nil {
+		return nil, fmt.Sprintf(NotSupportOfflineRollback, table), nil
+	}
+	defs := make([]string, 0, len(cmd.ColumnNames))
+	for _, name := range cmd.ColumnNames {
+		c, err := g.getColumn(table, name)
+		if err != nil {
+			return nil, "", err
+		}
+		if c == nil {
+			return nil, fmt.Sprintf(UnknownColumnRollback, name), nil
+		}
+		defs = append(defs, c.definition())
+	}
+	return []string{fmt.Sprintf("ALTER TABLE %s ADD (%s)", table, strings.Join(defs, ", "))}, "", nil
+}
+
+func (g *generator) generateModifyColumnRollbackSQL(table *parser.ObjectName, cmd *parser.AlterTableCmd) ([]string, string, error) {
+	if g.conn == nil {
+		return nil, fmt.Sprintf(NotSupportOfflineRollback, table), nil
+	}
+	defs := make([]string, 0, len(cmd.Columns))
+	for _, modified := range cmd.Columns {
+		c, err := g.getColumn(table, modified.Name)
+		if err != nil {
+			return nil, "", err
+		}
+		if c == nil {
+			return nil, fmt.Sprintf(UnknownColumnRollback, modified.Name), nil
+		}
+		def := parser.QuoteIdent(c.name)
+		if modified.Type != "" {
+			def += " " + c.dataType
+		}
+		if modified.Default != "" {
+			if c.dataDefault.Valid && strings.TrimSpace(c.dataDefault.String) != "" {
+				def += " DEFAULT " + strings.TrimSpace(c.dataDefault.String)
+			} else {
+				def += " DEFAULT NULL"
+			}
+		}
+		// modifying NOT NULL column to NOT NULL is an error, so the
+		// nullability is only restored if it is changed.
+		if modified.NotNull && c.nullable {
+			def += " NULL"
+		}
+		defs = append(defs, def)
+	}
+	return []string{fmt.Sprintf("ALTER TABLE %s MODIFY (%s)", table, strings.Join(defs, ", "))}, "", nil
+}
+
+// generateDropConstraintRollbackSQL generates the rollback SQL of DROP
+// CONSTRAINT, the primary key is dropped if name is empty.
+func (g *generator) generateDropConstraintRollbackSQL(table *parser.ObjectName, name string) ([]string, string, error) {
+	if g.conn == nil {
+		return nil, fmt.Sprintf(NotSupportOfflineRollback, table), nil
+	}
+	var constraintName, constraintType string
+	err := g.conn.QueryRowContext(g.ctx, fmt.Sprintf(`SELECT constraint_name, constraint_type FROM all_constraints
+WHERE owner = NVL(:1, %s) AND table_name = :2 AND (constraint_name = :3 OR :4 IS NULL AND constraint_type = 'P')`, currentSchema),
+		table.Schema, table.Name, name, name).
+		Scan(&constraintName, &constraintType)
+	if err == sql.ErrNoRows {
+		if name == "" {
+			name = "PRIMARY KEY"
+		}
+		return nil, fmt.Sprintf(UnknownObjectRollback, "CONSTRAINT", name), nil
+	}
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "get constraint of %s", table)
+	}
+
+	objectType := "CONSTRAINT"
+	if constraintType == "R" {
+		objectType = "REF_CONSTRAINT"
+	}
+	return g.getDDL(objectType, &parser.ObjectName{Schema: table.Schema, Name: constraintName})
+}
@@ -0,0 +1,127 @@
+package rollback
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+
+	"github.com/actiontech/sqle/sqle/pkg/oracle/parser"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func generate(t *testing.T, conn *sql.Conn, sql string) (string, string) {
+	stmt, err := parser.ParseOne(sql)
+	if !assert.NoError(t, err, sql) {
+		t.FailNow()
+	}
+	rollbackSQL, reason, err := GenerateRollbackSQL(context.TODO(), conn, stmt)
+	if !assert.NoError(t, err, sql) {
+		t.FailNow()
+	}
+	return rollbackSQL, reason
+}
+
+func TestGenerateRollbackSQLOffline(t *testing.T) {
+	cases := []struct {
+		sql      string
+		rollback string
+		reason   string
+	}{
+		{"create table s.t1 (id number primary key)", "DROP TABLE S.T1", ""},
+		{`create unique index "idx_1" on t1 (a)`, `DROP INDEX "idx_1"`, ""},
+		{"create sequence seq1 cache 100", "DROP SEQUENCE SEQ1", ""},
+		{"alter table t1 add (a number, b date) add constraint uk_1 unique (a)",
+			"ALTER TABLE T1 DROP CONSTRAINT UK_1;\nALTER TABLE T1 DROP (A, B)", ""},
+		{"alter table t1 rename column a to b", "ALTER TABLE T1 RENAME COLUMN B TO A", ""},
+		{"alter table s.t1 rename to t2", "ALTER TABLE S.T2 RENAME TO T1", ""},
+		{"alter table t1 add primary key (id)", "ALTER TABLE T1 DROP PRIMARY KEY", ""},
+		{"alter table t1 add check (a > 0)", "", fmt.Sprintf(NotSupportUnnamedConstraint, "add check (a > 0)")},
+		{"alter table t1 move tablespace users", "", fmt.Sprintf(NotSupportAlterTableCmdRollback, "move tablespace users")},
+		{"alter table t1 drop column a", "", fmt.Sprintf(NotSupportOfflineRollback, "T1")},
+		{"drop table t1", "", fmt.Sprintf(NotSupportOfflineRollback, "T1")},
+		{"drop user u1", "", NotSupportStatementRollback},
+		{"truncate table t1", "", NotSupportTruncateRollback},
+		{"update t1 set a = 1", "", ""},
+	}
+	for _, c := range cases {
+		rollbackSQL, reason := generate(t, nil, c.sql)
+		assert.Equal(t, c.rollback, rollbackSQL, c.sql)
+		assert.Equal(t, c.reason, reason, c.sql)
+	}
+}
+
+func newConn(t *testing.T) (*sql.Conn, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	conn, err := db.Conn(context.TODO())
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	return conn, mock
+}
+
+func TestGenerateRollbackSQLOnline(t *testing.T) {
+	conn, mock := newConn(t)
+	getDDL := fmt.Sprintf("SELECT DBMS_METADATA.GET_DDL(:1, :2, NVL(:3, %s)) FROM DUAL", currentSchema)
+	getColumn := fmt.Sprintf(`SELECT data_type, data_length, char_length, NVL(char_used, 'B'),
+data_precision, data_scale, nullable, data_default FROM all_tab_columns
+WHERE owner = NVL(:1, %s) AND table_name = :2 AND column_name = :3`, currentSchema)
+	columns := []string{"data_type", "data_length", "char_length", "char_used", "data_precision", "data_scale", "nullable", "data_default"}
+
+	mock.ExpectQuery(getDDL).WithArgs("TABLE", "T1", "S").
+		WillReturnRows(sqlmock.NewRows([]string{"ddl"}).AddRow("\n  CREATE TABLE \"S\".\"T1\" (\"ID\" NUMBER)"))
+	rollbackSQL, reason := generate(t, conn, "drop table s.t1 purge")
+	assert.Equal(t, "CREATE TABLE \"S\".\"T1\" (\"ID\" NUMBER)", rollbackSQL)
+	assert.Equal(t, "", reason)
+
+	mock.ExpectQuery(getDDL).WithArgs("INDEX", "IDX_1", "").WillReturnError(fmt.Errorf("ORA-31603: object \"IDX_1\" of type INDEX not found"))
+	rollbackSQL, reason = generate(t, conn, "drop index idx_1")
+	assert.Equal(t, "", rollbackSQL)
+	assert.Equal(t, fmt.Sprintf(UnknownObjectRollback, "INDEX", "IDX_1"), reason)
+
+	mock.ExpectQuery(getColumn).WithArgs("", "T1", "A").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("VARCHAR2", 80, 20, "C", nil, nil, "N", "'x' "))
+	mock.ExpectQuery(getColumn).WithArgs("", "T1", "B").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("NUMBER", 22, 0, "B", 10, 2, "Y", nil))
+	rollbackSQL, reason = generate(t, conn, "alter table t1 drop (a, b)")
+	assert.Equal(t, "ALTER TABLE T1 ADD (A VARCHAR2(20 CHAR) DEFAULT 'x' NOT NULL, B NUMBER(10,2))", rollbackSQL)
+	assert.Equal(t, "", reason)
+
+	mock.ExpectQuery(getColumn).WithArgs("", "T1", "C").
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("DATE", 7, 0, "B", nil, nil, "Y", nil))
+	rollbackSQL, reason = generate(t, conn, "alter table t1 modify c timestamp default sysdate not null")
+	assert.Equal(t, "ALTER TABLE T1 MODIFY (C DATE DEFAULT NULL NULL)", rollbackSQL)
+	assert.Equal(t, "", reason)
+
+	mock.ExpectQuery(getColumn).WithArgs("", "T1", "D").WillReturnRows(sqlmock.NewRows(columns))
+	rollbackSQL, reason = generate(t, conn, "alter table t1 drop column d")
+	assert.Equal(t, "", rollbackSQL)
+	assert.Equal(t, fmt.Sprintf(UnknownColumnRollback, "D"), reason)
+
+	mock.ExpectQuery(fmt.Sprintf(`SELECT constraint_name, constraint_type FROM all_constraints
+WHERE owner = NVL(:1, %s) AND table_name = :2 AND (constraint_name = :3 OR :4 IS NULL AND constraint_type = 'P')`, currentSchema)).
+		WithArgs("", "T1", "", "").
+		WillReturnRows(sqlmock.NewRows([]string{"constraint_name", "constraint_type"}).AddRow("PK_T1", "P"))
+	mock.ExpectQuery(getDDL).WithArgs("CONSTRAINT", "PK_T1", "").
+		WillReturnRows(sqlmock.NewRows([]string{"ddl"}).AddRow(`ALTER TABLE "T1" ADD CONSTRAINT "PK_T1" PRIMARY KEY ("ID")`))
+	rollbackSQL, reason = generate(t, conn, "alter table t1 drop primary key")
+	assert.Equal(t, `ALTER TABLE "T1" ADD CONSTRAINT "PK_T1" PRIMARY KEY ("ID")`, rollbackSQL)
+	assert.Equal(t, "", reason)
+
+	mock.ExpectQuery(fmt.Sprintf(`SELECT TO_CHAR(min_value), TO_CHAR(max_value),
+TO_CHAR(increment_by), cycle_flag, order_flag, cache_size FROM all_sequences
+WHERE sequence_owner = NVL(:1, %s) AND sequence_name = :2`, currentSchema)).
+		WithArgs("", "SEQ1").
+		WillReturnRows(sqlmock.NewRows([]string{"min_value", "max_value", "increment_by", "cycle_flag", "order_flag", "cache_size"}).
+			AddRow("1", "9999999999999999999999999999", "1", "N", "N", 0))
+	rollbackSQL, reason = generate(t, conn, "alter sequence seq1 cache 100")
+	assert.Equal(t, "ALTER SEQUENCE SEQ1 INCREMENT BY 1 MINVALUE 1 MAXVALUE 9999999999999999999999999999 NOCYCLE NOCACHE NOORDER", rollbackSQL)
+	assert.Equal(t, "", reason)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
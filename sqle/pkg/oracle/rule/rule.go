@@ -0,0 +1,236 @@
+package rule
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/pkg/oracle/parser"
+	"github.com/actiontech/sqle/sqle/pkg/params"
+)
+
+// rule type
+const (
+	RuleTypeIndexingConvention = "索引规范"
+	RuleTypeDDLConvention      = "DDL规范"
+	RuleTypeDMLConvention      = "DML规范"
+	RuleTypeUsageSuggestion    = "使用建议"
+)
+
+const (
+	DMLCheckBindVariable      = "dml_check_bind_variable"
+	DMLDisableSelectAllColumn = "dml_disable_select_all_column"
+	DMLCheckCartesianJoin     = "dml_check_cartesian_join"
+	DDLDisableDropStatement   = "ddl_disable_drop_statement"
+	DDLCheckPKNotExist        = "ddl_check_pk_not_exist"
+	DDLCheckSequenceCache     = "ddl_check_sequence_cache"
+)
+
+const DefaultSingleParamKeyName = "first_key"
+
+type RuleHandler struct {
+	Rule    driver.Rule
+	Message string
+	// Func returns the arguments of Message if the statement violates the
+	// rule, ok is false if there is no finding.
+	Func func(rule *driver.Rule, stmt parser.Stmt) (args []interface{}, ok bool)
+	// Position returns the byte offset of the offending token in SQL, the
+	// finding is located at the beginning of statement if it is nil.
+	Position func(stmt parser.Stmt) int
+}
+
+var RuleHandlers = []RuleHandler{
+	{
+		Rule: driver.Rule{
+			Name:     DMLCheckBindVariable,
+			Desc:     "建议使用绑定变量代替字面量",
+			Level:    driver.RuleLevelError,
+			Category: RuleTypeUsageSuggestion,
+			Params: params.Params{
+				&params.Param{
+					Key:   DefaultSingleParamKeyName,
+					Value: "3",
+					Desc:  "最大字面量数量",
+					Type:  params.ParamTypeInt,
+				},
+			},
+		},
+		Message: "SQL 中有 %d 个字面量, 建议使用绑定变量, 避免产生大量硬解析",
+		Func:    checkBindVariable,
+	},
+	{
+		Rule: driver.Rule{
+			Name:     DMLDisableSelectAllColumn,
+			Desc:     "不建议使用select *",
+			Level:    driver.RuleLevelNotice,
+			Category: RuleTypeDMLConvention,
+		},
+		Message:  "不建议使用select *",
+		Func:     checkSelectAll,
+		Position: selectStarPos,
+	},
+	{
+		Rule: driver.Rule{
+			Name:     DMLCheckCartesianJoin,
+			Desc:     "禁止使用没有连接条件的多表连接",
+			Level:    driver.RuleLevelError,
+			Category: RuleTypeDMLConvention,
+		},
+		Message: "表 %s 之间没有连接条件, 将产生笛卡尔积",
+		Func:    checkCartesianJoin,
+	},
+	{
+		Rule: driver.Rule{
+			Name:     DDLDisableDropStatement,
+			Desc:     "禁止使用 DROP 和 TRUNCATE 语句",
+			Level:    driver.RuleLevelError,
+			Category: RuleTypeUsageSuggestion,
+		},
+		Message: "禁止使用 %s 语句",
+		Func:    checkDropStatement,
+	},
+	{
+		Rule: driver.Rule{
+			Name:     DDLCheckPKNotExist,
+			Desc:     "表必须有主键",
+			Level:    driver.RuleLevelError,
+			Category: RuleTypeIndexingConvention,
+		},
+		Message: "表 %s 没有主键",
+		Func:    checkPrimaryKey,
+	},
+	{
+		Rule: driver.Rule{
+			Name:     DDLCheckSequenceCache,
+			Desc:     "序列建议使用 CACHE, 且 CACHE 值不小于设定值",
+			Level:    driver.RuleLevelWarn,
+			Category: RuleTypeDDLConvention,
+			Params: params.Params{
+				&params.Param{
+					Key:   DefaultSingleParamKeyName,
+					Value: "20",
+					Desc:  "最小 CACHE 值",
+					Type:  params.ParamTypeInt,
+				},
+			},
+		},
+		Message: "序列 %s 未使用 CACHE 或 CACHE 值小于 %d, 高并发获取序列值时将产生争用",
+		Func:    checkSequenceCache,
+	},
+}
+
+// Audit checks the ast which is parsed by ParseSQL, it returns empty message
+// if the statement does not violate the rule.
+func (rh *RuleHandler) Audit(rule *driver.Rule, ast interface{}) (string, error) {
+	stmt, ok := ast.(parser.Stmt)
+	if !ok {
+		return "", fmt.Errorf("unexpected ast type %T", ast)
+	}
+	args, ok := rh.Func(rule, stmt)
+	if !ok {
+		return "", nil
+	}
+	return fmt.Sprintf(rh.Message, args...), nil
+}
+
+// Offset returns the byte offset of the finding in SQL which is parsed by
+// ParseSQL, it is -1 if the rule does not locate the finding.
+func (rh *RuleHandler) Offset(ast interface{}) int {
+	stmt, ok := ast.(parser.Stmt)
+	if !ok || rh.Position == nil {
+		return -1
+	}
+	return rh.Position(stmt)
+}
+
+// ParseSQL parses the SQL which has one statement exactly, the ast is used by
+// RuleHandler.Audit.
+func ParseSQL(sql string) (interface{}, error) {
+	return parser.ParseOne(sql)
+}
+
+func checkBindVariable(rule *driver.Rule, stmt parser.Stmt) ([]interface{}, bool) {
+	if _, ok := stmt.(*parser.DMLStmt); !ok {
+		return nil, false
+	}
+	max := rule.Params.GetParam(DefaultSingleParamKeyName).Int()
+	if max <= 0 || stmt.Literals() < max {
+		return nil, false
+	}
+	return []interface{}{stmt.Literals()}, true
+}
+
+func checkSelectAll(rule *driver.Rule, stmt parser.Stmt) ([]interface{}, bool) {
+	if stmt, ok := stmt.(*parser.DMLStmt); ok && stmt.SelectStar {
+		return nil, true
+	}
+	return nil, false
+}
+
+func selectStarPos(stmt parser.Stmt) int {
+	if stmt, ok := stmt.(*parser.DMLStmt); ok && stmt.SelectStar {
+		return stmt.SelectStarPos
+	}
+	return -1
+}
+
+func checkCartesianJoin(rule *driver.Rule, stmt parser.Stmt) ([]interface{}, bool) {
+	if stmt, ok := stmt.(*parser.DMLStmt); ok && len(stmt.CartesianJoins) > 0 {
+		return []interface{}{strings.Join(stmt.CartesianJoins, "; ")}, true
+	}
+	return nil, false
+}
+
+func checkDropStatement(rule *driver.Rule, stmt parser.Stmt) ([]interface{}, bool) {
+	switch stmt := stmt.(type) {
+	case *parser.DropStmt:
+		return []interface{}{"DROP " + stmt.ObjectType}, true
+	case *parser.TruncateStmt:
+		return []interface{}{"TRUNCATE TABLE"}, true
+	}
+	return nil, false
+}
+
+func checkPrimaryKey(rule *driver.Rule, stmt parser.Stmt) ([]interface{}, bool) {
+	switch stmt := stmt.(type) {
+	case *parser.CreateTableStmt:
+		// the temporary table and the table created by "AS SELECT" are usually
+		// used for intermediate result.
+		if stmt.Temporary || stmt.AsSelect || stmt.HasPrimaryKey {
+			return nil, false
+		}
+		return []interface{}{stmt.Table}, true
+	case *parser.AlterTableStmt:
+		for _, cmd := range stmt.Cmds {
+			if cmd.Type == parser.AlterTableDropPrimaryKey {
+				return []interface{}{stmt.Table}, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// defaultSequenceCache is the cache size of sequence if CACHE is not specified.
+const defaultSequenceCache = 20
+
+func checkSequenceCache(rule *driver.Rule, stmt parser.Stmt) ([]interface{}, bool) {
+	s, ok := stmt.(*parser.SequenceStmt)
+	if !ok {
+		return nil, false
+	}
+	min := rule.Params.GetParam(DefaultSingleParamKeyName).Int()
+	cache := s.Cache
+	switch {
+	case s.NoCache:
+		cache = 0
+	case cache < 0 && s.Alter:
+		// the cache is not changed.
+		return nil, false
+	case cache < 0:
+		cache = defaultSequenceCache
+	}
+	if cache >= min && cache > 1 {
+		return nil, false
+	}
+	return []interface{}{s.Name, min}, true
+}
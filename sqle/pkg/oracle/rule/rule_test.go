@@ -0,0 +1,96 @@
+package rule
+
+import (
+	"testing"
+
+	"github.com/actiontech/sqle/sqle/driver"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ruleHandler(t *testing.T, name string) *RuleHandler {
+	var handler *RuleHandler
+	for i := range RuleHandlers {
+		if RuleHandlers[i].Rule.Name == name {
+			handler = &RuleHandlers[i]
+		}
+	}
+	if !assert.NotNil(t, handler, name) {
+		t.FailNow()
+	}
+	return handler
+}
+
+func parseSQL(t *testing.T, sql string) interface{} {
+	ast, err := ParseSQL(sql)
+	if !assert.NoError(t, err, sql) {
+		t.FailNow()
+	}
+	return ast
+}
+
+func audit(t *testing.T, name, sql string) string {
+	handler := ruleHandler(t, name)
+	rule := handler.Rule
+	msg, err := handler.Audit(&rule, parseSQL(t, sql))
+	if !assert.NoError(t, err, sql) {
+		t.FailNow()
+	}
+	return msg
+}
+
+func offset(t *testing.T, name, sql string) int {
+	return ruleHandler(t, name).Offset(parseSQL(t, sql))
+}
+
+func TestRuleHandlerOffset(t *testing.T) {
+	assert.Equal(t, 16, offset(t, DMLDisableSelectAllColumn, "select distinct t1.* from t1"))
+	assert.Equal(t, -1, offset(t, DMLCheckCartesianJoin, "select a from t1, t2"))
+}
+
+func TestCheckBindVariable(t *testing.T) {
+	assert.Equal(t, "SQL 中有 3 个字面量, 建议使用绑定变量, 避免产生大量硬解析",
+		audit(t, DMLCheckBindVariable, "select a from t1 where b = 'x' and c = 1 and d = 2"))
+	assert.Equal(t, "", audit(t, DMLCheckBindVariable, "select a from t1 where b = :1 and c = 1"))
+	assert.Equal(t, "", audit(t, DMLCheckBindVariable, "create table t1 (a varchar2(10), b number(10, 2))"))
+}
+
+func TestCheckSelectAll(t *testing.T) {
+	assert.Equal(t, "不建议使用select *", audit(t, DMLDisableSelectAllColumn, "select * from t1"))
+	assert.Equal(t, "", audit(t, DMLDisableSelectAllColumn, "select count(*) from t1"))
+}
+
+func TestCheckCartesianJoin(t *testing.T) {
+	assert.Equal(t, "表 T1, T2 之间没有连接条件, 将产生笛卡尔积",
+		audit(t, DMLCheckCartesianJoin, "select a from t1, t2 where t1.b = 1"))
+	assert.Equal(t, "", audit(t, DMLCheckCartesianJoin, "select a from t1, t2 where t1.id = t2.id"))
+}
+
+func TestCheckDropStatement(t *testing.T) {
+	assert.Equal(t, "禁止使用 DROP TABLE 语句", audit(t, DDLDisableDropStatement, "drop table t1"))
+	assert.Equal(t, "禁止使用 TRUNCATE TABLE 语句", audit(t, DDLDisableDropStatement, "truncate table t1"))
+	assert.Equal(t, "", audit(t, DDLDisableDropStatement, "delete from t1 where id = 1"))
+}
+
+func TestCheckPrimaryKey(t *testing.T) {
+	assert.Equal(t, "表 T1 没有主键", audit(t, DDLCheckPKNotExist, "create table t1 (id number)"))
+	assert.Equal(t, "", audit(t, DDLCheckPKNotExist, "create table t1 (id number primary key)"))
+	assert.Equal(t, "", audit(t, DDLCheckPKNotExist, "create global temporary table t1 (id number)"))
+	assert.Equal(t, "表 T1 没有主键", audit(t, DDLCheckPKNotExist, "alter table t1 drop primary key"))
+}
+
+func TestCheckSequenceCache(t *testing.T) {
+	msg := "序列 SEQ1 未使用 CACHE 或 CACHE 值小于 20, 高并发获取序列值时将产生争用"
+	assert.Equal(t, msg, audit(t, DDLCheckSequenceCache, "create sequence seq1 nocache"))
+	assert.Equal(t, msg, audit(t, DDLCheckSequenceCache, "alter sequence seq1 cache 10"))
+	assert.Equal(t, "", audit(t, DDLCheckSequenceCache, "create sequence seq1"))
+	assert.Equal(t, "", audit(t, DDLCheckSequenceCache, "create sequence seq1 cache 1000"))
+	assert.Equal(t, "", audit(t, DDLCheckSequenceCache, "alter sequence seq1 increment by 2"))
+}
+
+func TestRuleLevel(t *testing.T) {
+	for _, rh := range RuleHandlers {
+		assert.True(t, rh.Rule.Level == driver.RuleLevelError || rh.Rule.Level == driver.RuleLevelWarn ||
+			rh.Rule.Level == driver.RuleLevelNotice, rh.Rule.Name)
+	}
+}
@@ -0,0 +1,104 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/driver"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name          `xml:"testsuites"`
+	Name     string            `xml:"name,attr"`
+	Tests    int               `xml:"tests,attr"`
+	Failures int               `xml:"failures,attr"`
+	Suites   []*junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Cases    []*junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Content string `xml:",chardata"`
+}
+
+// WriteJUnit writes a test case for each SQL, the SQLs are grouped to test
+// suites by file. The SQL whose level is more than or equal to failLevel is
+// a failed test case, the findings of other SQLs are written to system-out.
+// The exempted findings are prefixed with "[exempted]" and do not fail SQL.
+func WriteJUnit(w io.Writer, r *Report, failLevel driver.RuleLevel) error {
+	suites := &junitTestSuites{Name: r.Name}
+	suiteMap := map[string]*junitTestSuite{}
+	for _, sql := range r.SQLs {
+		suiteName := sql.FilePath
+		if suiteName == "" {
+			suiteName = r.Name
+		}
+		suite, ok := suiteMap[suiteName]
+		if !ok {
+			suite = &junitTestSuite{Name: suiteName}
+			suiteMap[suiteName] = suite
+			suites.Suites = append(suites.Suites, suite)
+		}
+
+		messages := make([]string, 0, len(sql.Results))
+		failureMessage := ""
+		for _, result := range sql.Results {
+			message := fmt.Sprintf("[%s]%s", result.Level, result.Message)
+			if result.RuleName != "" {
+				message = fmt.Sprintf("[%s]%s: %s", result.Level, result.RuleName, result.Message)
+			}
+			if result.Exempted {
+				message = "[exempted]" + message
+			} else if failureMessage == "" {
+				failureMessage = message
+			}
+			messages = append(messages, message)
+		}
+		testCase := &junitTestCase{
+			Name:      sql.Location(),
+			ClassName: suiteName,
+		}
+		level := sql.Level()
+		if level != driver.RuleLevelNull && level.MoreOrEqual(failLevel) {
+			testCase.Failure = &junitFailure{
+				Message: failureMessage,
+				Type:    string(level),
+				Content: strings.Join(messages, "\n") + "\n\n" + sql.Content,
+			}
+			suite.Failures++
+			suites.Failures++
+		} else if len(messages) > 0 {
+			testCase.SystemOut = strings.Join(messages, "\n")
+		}
+		suite.Cases = append(suite.Cases, testCase)
+		suite.Tests++
+		suites.Tests++
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
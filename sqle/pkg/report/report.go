@@ -0,0 +1,189 @@
+// Package report renders the audit results of SQLs to the formats which can
+// be consumed by CI and code review tools, such as JUnit XML and SARIF.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/driver"
+)
+
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+	FormatSARIF = "sarif"
+)
+
+var Formats = []string{FormatText, FormatJSON, FormatJUnit, FormatSARIF}
+
+// Report is the audit results of SQLs.
+type Report struct {
+	// Name is the name of audited object, such as task or audit plan.
+	Name string `json:"name"`
+	// Version is the version of SQLE which audits the SQLs.
+	Version string `json:"sqle_version"`
+	// Rules are the rules used by audit, they are the rule metadata of SARIF.
+	Rules []*driver.Rule `json:"-"`
+	SQLs  []*SQL         `json:"sqls"`
+}
+
+// SQL is an audited SQL, FilePath and Line are set if the SQL comes from
+// file, such as SQL file and MyBatis XML.
+type SQL struct {
+	Number   uint      `json:"number"`
+	Content  string    `json:"sql"`
+	FilePath string    `json:"file_path,omitempty"`
+	Line     int       `json:"line,omitempty"`
+	Results  []*Result `json:"results"`
+}
+
+type Result struct {
+	Level      string `json:"level"`
+	Message    string `json:"message"`
+	RuleName   string `json:"rule_name,omitempty"`
+	Category   string `json:"category,omitempty"`
+	Suggestion string `json:"suggestion,omitempty"`
+	// Line is the line of finding in SQL, it starts from 1. It is 0 if the
+	// driver can not locate the finding.
+	Line int `json:"line,omitempty"`
+	// Exempted is true if the finding is exempted by rule exemption, it is
+	// reported but does not affect the level of SQL.
+	Exempted bool `json:"exempted,omitempty"`
+}
+
+// NewResults converts the audit result of driver.
+func NewResults(result *driver.AuditResult) []*Result {
+	results := make([]*Result, 0, len(result.Results()))
+	for _, item := range result.Results() {
+		r := &Result{
+			Level:      string(item.Level),
+			Message:    item.Message,
+			RuleName:   item.RuleName,
+			Category:   item.Category,
+			Suggestion: item.Suggestion,
+		}
+		if item.Position != nil {
+			r.Line = item.Position.Line
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// Level returns the highest level of results which are not exempted.
+func (s *SQL) Level() driver.RuleLevel {
+	level := driver.RuleLevelNull
+	for _, r := range s.Results {
+		if !r.Exempted && driver.RuleLevel(r.Level).More(level) {
+			level = driver.RuleLevel(r.Level)
+		}
+	}
+	return level
+}
+
+// Location returns the location of SQL, such as "V1__init.sql:10". It is
+// the number of SQL if the SQL does not come from file.
+func (s *SQL) Location() string {
+	if s.FilePath == "" {
+		return fmt.Sprintf("SQL %d", s.Number)
+	}
+	if s.Line <= 0 {
+		return s.FilePath
+	}
+	return fmt.Sprintf("%s:%d", s.FilePath, s.Line)
+}
+
+// resultLine returns the line of finding in file.
+func (s *SQL) resultLine(r *Result) int {
+	if s.Line <= 0 {
+		return 0
+	}
+	if r.Line <= 0 {
+		return s.Line
+	}
+	return s.Line + r.Line - 1
+}
+
+// Level returns the highest level of SQLs.
+func (r *Report) Level() driver.RuleLevel {
+	level := driver.RuleLevelNull
+	for _, sql := range r.SQLs {
+		if sql.Level().More(level) {
+			level = sql.Level()
+		}
+	}
+	return level
+}
+
+// Write writes the report in format, the SQLs whose level is more than or
+// equal to failLevel are reported as failures in JUnit.
+func Write(w io.Writer, format string, r *Report, failLevel driver.RuleLevel) error {
+	switch format {
+	case FormatText:
+		return WriteText(w, r)
+	case FormatJSON:
+		return WriteJSON(w, r)
+	case FormatJUnit:
+		return WriteJUnit(w, r, failLevel)
+	case FormatSARIF:
+		return WriteSARIF(w, r)
+	default:
+		return fmt.Errorf("unsupported report format %s, expect one of %s", format, strings.Join(Formats, ", "))
+	}
+}
+
+// WriteText writes a line for each finding, such as:
+//
+//	V1__init.sql:2 [error] ddl_check_pk_not_exist: 表必须有主键
+//
+// and a summary line at the end. The exempted finding is marked with
+// "(exempted)" and is not counted in summary.
+func WriteText(w io.Writer, r *Report) error {
+	counter := map[driver.RuleLevel]int{}
+	exempted := 0
+	for _, sql := range r.SQLs {
+		for _, result := range sql.Results {
+			if result.Exempted {
+				exempted++
+			} else {
+				counter[driver.RuleLevel(result.Level)]++
+			}
+			if driver.RuleLevel(result.Level) == driver.RuleLevelNormal {
+				continue
+			}
+			location := sql.Location()
+			if line := sql.resultLine(result); line > 0 {
+				location = fmt.Sprintf("%s:%d", sql.FilePath, line)
+			}
+			ruleName := ""
+			if result.RuleName != "" {
+				ruleName = result.RuleName + ": "
+			}
+			suffix := ""
+			if result.Exempted {
+				suffix = " (exempted)"
+			}
+			if _, err := fmt.Fprintf(w, "%s [%s] %s%s%s\n", location, result.Level, ruleName, result.Message, suffix); err != nil {
+				return err
+			}
+		}
+	}
+	summary := fmt.Sprintf("%d SQLs audited, %d error, %d warn, %d notice", len(r.SQLs),
+		counter[driver.RuleLevelError], counter[driver.RuleLevelWarn], counter[driver.RuleLevelNotice])
+	if exempted > 0 {
+		summary += fmt.Sprintf(", %d exempted", exempted)
+	}
+	_, err := fmt.Fprintln(w, summary)
+	return err
+}
+
+func WriteJSON(w io.Writer, r *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
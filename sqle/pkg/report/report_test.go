@@ -0,0 +1,135 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReport() *Report {
+	return &Report{
+		Name:    "task_1",
+		Version: "v1.2207.0",
+		Rules: []*driver.Rule{
+			{Name: "ddl_check_pk_not_exist", Desc: "表必须有主键", Level: driver.RuleLevelError, Category: "索引规范"},
+		},
+		SQLs: []*SQL{
+			{
+				Number:   1,
+				Content:  "CREATE TABLE t1 (id INT)",
+				FilePath: "V1__init.sql",
+				Line:     3,
+				Results: []*Result{
+					{Level: "error", Message: "表必须有主键", RuleName: "ddl_check_pk_not_exist"},
+					{Level: "notice", Message: "建议添加注释", RuleName: "ddl_check_table_without_comment", Category: "命名规范", Line: 2},
+				},
+			},
+			{
+				Number:  2,
+				Content: "SELECT 1",
+				Results: []*Result{{Level: "normal", Message: "审核通过"}},
+			},
+		},
+	}
+}
+
+func TestWriteText(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, Write(buf, FormatText, newTestReport(), driver.RuleLevelError))
+	assert.Equal(t, "V1__init.sql:3 [error] ddl_check_pk_not_exist: 表必须有主键\n"+
+		"V1__init.sql:4 [notice] ddl_check_table_without_comment: 建议添加注释\n"+
+		"2 SQLs audited, 1 error, 0 warn, 1 notice\n", buf.String())
+
+	assert.Error(t, Write(buf, "html", newTestReport(), driver.RuleLevelError))
+}
+
+func TestWriteJUnit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteJUnit(buf, newTestReport(), driver.RuleLevelWarn))
+
+	suites := &junitTestSuites{}
+	if !assert.NoError(t, xml.Unmarshal(buf.Bytes(), suites)) || !assert.Len(t, suites.Suites, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, 2, suites.Tests)
+	assert.Equal(t, 1, suites.Failures)
+
+	assert.Equal(t, "V1__init.sql", suites.Suites[0].Name)
+	failed := suites.Suites[0].Cases[0]
+	assert.Equal(t, "V1__init.sql:3", failed.Name)
+	assert.Equal(t, "error", failed.Failure.Type)
+	assert.Equal(t, "[error]ddl_check_pk_not_exist: 表必须有主键", failed.Failure.Message)
+
+	assert.Equal(t, "task_1", suites.Suites[1].Name)
+	passed := suites.Suites[1].Cases[0]
+	assert.Equal(t, "SQL 2", passed.Name)
+	assert.Nil(t, passed.Failure)
+	assert.Equal(t, "[normal]审核通过", passed.SystemOut)
+}
+
+func TestWriteSARIF(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteSARIF(buf, newTestReport()))
+
+	log := &sarifLog{}
+	if !assert.NoError(t, json.Unmarshal(buf.Bytes(), log)) || !assert.Len(t, log.Runs, 1) {
+		t.FailNow()
+	}
+	assert.Equal(t, "2.1.0", log.Version)
+	run := log.Runs[0]
+	assert.Equal(t, "v1.2207.0", run.Tool.Driver.Version)
+	if !assert.Len(t, run.Tool.Driver.Rules, 2) || !assert.Len(t, run.Results, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, "表必须有主键", run.Tool.Driver.Rules[0].ShortDescription.Text)
+	assert.Equal(t, "索引规范", run.Tool.Driver.Rules[0].Properties["category"])
+	assert.Equal(t, "note", run.Tool.Driver.Rules[1].DefaultConfiguration.Level)
+
+	assert.Equal(t, "error", run.Results[0].Level)
+	assert.Equal(t, "V1__init.sql", run.Results[0].Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 3, run.Results[0].Locations[0].PhysicalLocation.Region.StartLine)
+	assert.Equal(t, "note", run.Results[1].Level)
+	assert.Equal(t, 4, run.Results[1].Locations[0].PhysicalLocation.Region.StartLine)
+}
+
+func TestSARIFLevel(t *testing.T) {
+	assert.Equal(t, "error", SARIFLevel(driver.RuleLevelError))
+	assert.Equal(t, "warning", SARIFLevel(driver.RuleLevelWarn))
+	assert.Equal(t, "note", SARIFLevel(driver.RuleLevelNotice))
+	assert.Equal(t, "none", SARIFLevel(driver.RuleLevelNormal))
+}
+
+func TestExemptedResult(t *testing.T) {
+	r := newTestReport()
+	r.SQLs[0].Results[0].Exempted = true
+	assert.Equal(t, driver.RuleLevelNotice, r.SQLs[0].Level())
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, WriteText(buf, r))
+	assert.Equal(t, "V1__init.sql:3 [error] ddl_check_pk_not_exist: 表必须有主键 (exempted)\n"+
+		"V1__init.sql:4 [notice] ddl_check_table_without_comment: 建议添加注释\n"+
+		"2 SQLs audited, 0 error, 0 warn, 1 notice, 1 exempted\n", buf.String())
+
+	buf.Reset()
+	assert.NoError(t, WriteJUnit(buf, r, driver.RuleLevelWarn))
+	suites := &junitTestSuites{}
+	if !assert.NoError(t, xml.Unmarshal(buf.Bytes(), suites)) {
+		t.FailNow()
+	}
+	assert.Equal(t, 0, suites.Failures)
+	assert.Equal(t, "[exempted][error]ddl_check_pk_not_exist: 表必须有主键\n"+
+		"[notice]ddl_check_table_without_comment: 建议添加注释", suites.Suites[0].Cases[0].SystemOut)
+
+	buf.Reset()
+	assert.NoError(t, WriteSARIF(buf, r))
+	log := &sarifLog{}
+	if !assert.NoError(t, json.Unmarshal(buf.Bytes(), log)) || !assert.Len(t, log.Runs[0].Results, 2) {
+		t.FailNow()
+	}
+	assert.Equal(t, "external", log.Runs[0].Results[0].Suppressions[0].Kind)
+	assert.Nil(t, log.Runs[0].Results[1].Suppressions)
+}
@@ -0,0 +1,173 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/actiontech/sqle/sqle/driver"
+)
+
+// the SARIF 2.1.0 log, ref to https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string      `json:"version"`
+	Schema  string      `json:"$schema"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool      `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	Version        string       `json:"version,omitempty"`
+	InformationURI string       `json:"informationUri"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string                 `json:"id"`
+	ShortDescription     sarifMessage           `json:"shortDescription"`
+	DefaultConfiguration sarifRuleConfiguration `json:"defaultConfiguration"`
+	Properties           map[string]string      `json:"properties,omitempty"`
+}
+
+type sarifRuleConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID       string              `json:"ruleId,omitempty"`
+	Level        string              `json:"level"`
+	Message      sarifMessage        `json:"message"`
+	Locations    []*sarifLocation    `json:"locations,omitempty"`
+	Suppressions []*sarifSuppression `json:"suppressions,omitempty"`
+}
+
+type sarifSuppression struct {
+	Kind string `json:"kind"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// SARIFLevel converts the rule level to SARIF level.
+func SARIFLevel(level driver.RuleLevel) string {
+	switch level {
+	case driver.RuleLevelError:
+		return "error"
+	case driver.RuleLevelWarn:
+		return "warning"
+	case driver.RuleLevelNotice:
+		return "note"
+	default:
+		return "none"
+	}
+}
+
+// WriteSARIF writes the findings as SARIF results, the findings of normal
+// level are skipped. The location of result is set if the SQL comes from file.
+// The exempted finding is the result with the suppression of external kind,
+// since it is exempted in SQLE instead of source code.
+func WriteSARIF(w io.Writer, r *Report) error {
+	ruleMap := make(map[string]*driver.Rule, len(r.Rules))
+	for _, rule := range r.Rules {
+		ruleMap[rule.Name] = rule
+	}
+
+	run := &sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "SQLE",
+			Version:        r.Version,
+			InformationURI: "https://github.com/actiontech/sqle",
+			Rules:          []*sarifRule{},
+		}},
+		Results: []*sarifResult{},
+	}
+	reportedRules := map[string]struct{}{}
+	for _, sql := range r.SQLs {
+		for _, result := range sql.Results {
+			level := driver.RuleLevel(result.Level)
+			if level == driver.RuleLevelNormal {
+				continue
+			}
+			sr := &sarifResult{
+				RuleID:  result.RuleName,
+				Level:   SARIFLevel(level),
+				Message: sarifMessage{Text: result.Message},
+			}
+			if result.Exempted {
+				sr.Suppressions = []*sarifSuppression{{Kind: "external"}}
+			}
+			if sql.FilePath != "" {
+				location := &sarifLocation{PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: sql.FilePath},
+				}}
+				if line := sql.resultLine(result); line > 0 {
+					location.PhysicalLocation.Region = &sarifRegion{StartLine: line}
+				}
+				sr.Locations = []*sarifLocation{location}
+			}
+			run.Results = append(run.Results, sr)
+
+			if result.RuleName == "" {
+				continue
+			}
+			if _, ok := reportedRules[result.RuleName]; ok {
+				continue
+			}
+			reportedRules[result.RuleName] = struct{}{}
+			rule := &sarifRule{
+				ID:                   result.RuleName,
+				ShortDescription:     sarifMessage{Text: result.RuleName},
+				DefaultConfiguration: sarifRuleConfiguration{Level: SARIFLevel(level)},
+			}
+			if result.Category != "" {
+				rule.Properties = map[string]string{"category": result.Category}
+			}
+			if dr, ok := ruleMap[result.RuleName]; ok {
+				rule.ShortDescription.Text = dr.Desc
+				rule.DefaultConfiguration.Level = SARIFLevel(dr.Level)
+				rule.Properties = map[string]string{"category": dr.Category}
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, rule)
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(&sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []*sarifRun{run},
+	})
+}
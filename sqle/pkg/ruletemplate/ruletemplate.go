@@ -0,0 +1,139 @@
+// Package ruletemplate defines the file format of exported rule template. The
+// file is used to promote rule templates between SQLE servers, and to audit
+// SQL offline by scannerd without connecting to SQLE server.
+package ruletemplate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/actiontech/sqle/sqle/driver"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FormatVersion is the version of file format, it is changed when the format
+// is changed incompatibly.
+const FormatVersion = "v1"
+
+// File is the exported rule template, it can be written in YAML or JSON.
+type File struct {
+	FormatVersion string `json:"format_version" yaml:"format_version"`
+	// SQLEVersion is the version of SQLE which exports the file.
+	SQLEVersion   string   `json:"sqle_version" yaml:"sqle_version"`
+	Name          string   `json:"name" yaml:"name"`
+	Desc          string   `json:"desc" yaml:"desc"`
+	DBType        string   `json:"db_type" yaml:"db_type"`
+	InstanceNames []string `json:"instance_names,omitempty" yaml:"instance_names,omitempty"`
+	Rules         []Rule   `json:"rules" yaml:"rules"`
+}
+
+type Rule struct {
+	Name   string  `json:"name" yaml:"name"`
+	Level  string  `json:"level" yaml:"level"`
+	Params []Param `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+type Param struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value" yaml:"value"`
+}
+
+var validRuleLevels = map[driver.RuleLevel]struct{}{
+	driver.RuleLevelNormal: {},
+	driver.RuleLevelNotice: {},
+	driver.RuleLevelWarn:   {},
+	driver.RuleLevelError:  {},
+}
+
+// Unmarshal parses the file in YAML or JSON, JSON is parsed as YAML since it
+// is a subset of YAML.
+func Unmarshal(data []byte) (*File, error) {
+	f := &File{}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, err
+	}
+	if f.FormatVersion != FormatVersion {
+		return nil, fmt.Errorf("unsupported format version \"%s\" of rule template, expect \"%s\"",
+			f.FormatVersion, FormatVersion)
+	}
+	if f.DBType == "" {
+		return nil, fmt.Errorf("db type of rule template is empty")
+	}
+	ruleNames := make(map[string]struct{}, len(f.Rules))
+	for _, rule := range f.Rules {
+		if _, ok := validRuleLevels[driver.RuleLevel(rule.Level)]; !ok {
+			return nil, fmt.Errorf("invalid level \"%s\" of rule %s", rule.Level, rule.Name)
+		}
+		if _, ok := ruleNames[rule.Name]; ok {
+			return nil, fmt.Errorf("duplicate rule %s in rule template", rule.Name)
+		}
+		ruleNames[rule.Name] = struct{}{}
+	}
+	return f, nil
+}
+
+const (
+	FormatYAML = "yaml"
+	FormatJSON = "json"
+)
+
+// Marshal encodes the file in YAML or JSON.
+func Marshal(f *File, format string) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		return yaml.Marshal(f)
+	case FormatJSON:
+		return json.MarshalIndent(f, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported format \"%s\" of rule template", format)
+	}
+}
+
+// Load reads the file from path.
+func Load(path string) (*File, error) {
+	data, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, err
+	}
+	f, err := Unmarshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("load rule template from %s failed: %v", path, err)
+	}
+	return f, nil
+}
+
+// DriverRules returns the rules of template, which are used by driver to
+// audit SQL. The rules are copied from driverRules with the level and params
+// of template. The rules of template which are not in driverRules are
+// returned as unknown, they may be removed or not supported by this version.
+func (f *File) DriverRules(driverRules []*driver.Rule) (rules []*driver.Rule, unknown []string, err error) {
+	ruleMap := make(map[string]*driver.Rule, len(driverRules))
+	for _, rule := range driverRules {
+		ruleMap[rule.Name] = rule
+	}
+
+	for _, r := range f.Rules {
+		driverRule, ok := ruleMap[r.Name]
+		if !ok {
+			unknown = append(unknown, r.Name)
+			continue
+		}
+		rule := &driver.Rule{
+			Name:     driverRule.Name,
+			Desc:     driverRule.Desc,
+			Category: driverRule.Category,
+			Level:    driver.RuleLevel(r.Level),
+			Params:   driverRule.Params.Copy(),
+		}
+		for _, p := range r.Params {
+			if err := rule.Params.SetParamValue(p.Key, p.Value); err != nil {
+				return nil, nil, fmt.Errorf("invalid param of rule %s: %v", r.Name, err)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, unknown, nil
+}
@@ -0,0 +1,102 @@
+package ruletemplate
+
+import (
+	"testing"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/pkg/params"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnmarshal(t *testing.T) {
+	f, err := Unmarshal([]byte(`
+format_version: v1
+sqle_version: v1.2207.0
+name: t1
+db_type: mysql
+instance_names: [inst1]
+rules:
+  - name: rule1
+    level: error
+    params:
+      - key: first_key
+        value: "10"
+`))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, "t1", f.Name)
+	assert.Equal(t, []string{"inst1"}, f.InstanceNames)
+	assert.Equal(t, []Param{{Key: "first_key", Value: "10"}}, f.Rules[0].Params)
+
+	// JSON is also supported.
+	f, err = Unmarshal([]byte(`{"format_version": "v1", "db_type": "mysql", "rules": [{"name": "rule1", "level": "warn"}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "warn", f.Rules[0].Level)
+
+	_, err = Unmarshal([]byte(`{"format_version": "v2", "db_type": "mysql"}`))
+	assert.Error(t, err)
+	_, err = Unmarshal([]byte(`{"format_version": "v1", "db_type": "mysql", "rules": [{"name": "rule1", "level": "fatal"}]}`))
+	assert.Error(t, err)
+	_, err = Unmarshal([]byte(`{"format_version": "v1", "db_type": "mysql", "rules": [{"name": "rule1", "level": "warn"}, {"name": "rule1", "level": "error"}]}`))
+	assert.Error(t, err)
+}
+
+func TestMarshal(t *testing.T) {
+	f := &File{
+		FormatVersion: FormatVersion,
+		SQLEVersion:   "v1.2207.0",
+		Name:          "t1",
+		DBType:        "mysql",
+		InstanceNames: []string{"inst1"},
+		Rules: []Rule{
+			{Name: "rule1", Level: "error", Params: []Param{{Key: "first_key", Value: "10"}}},
+			{Name: "rule2", Level: "notice"},
+		},
+	}
+	for _, format := range []string{FormatYAML, FormatJSON} {
+		data, err := Marshal(f, format)
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+		actual, err := Unmarshal(data)
+		assert.NoError(t, err)
+		assert.Equal(t, f, actual)
+	}
+
+	_, err := Marshal(f, "xml")
+	assert.Error(t, err)
+}
+
+func TestDriverRules(t *testing.T) {
+	driverRules := []*driver.Rule{
+		{
+			Name:     "rule1",
+			Desc:     "desc1",
+			Category: "category1",
+			Level:    driver.RuleLevelNotice,
+			Params: params.Params{
+				&params.Param{Key: "first_key", Value: "5", Type: params.ParamTypeInt},
+			},
+		},
+		{Name: "rule2", Level: driver.RuleLevelNotice},
+	}
+	f := &File{Rules: []Rule{
+		{Name: "rule1", Level: "error", Params: []Param{{Key: "first_key", Value: "10"}}},
+		{Name: "rule3", Level: "warn"},
+	}}
+	rules, unknown, err := f.DriverRules(driverRules)
+	if !assert.NoError(t, err) || !assert.Len(t, rules, 1) {
+		t.FailNow()
+	}
+	assert.Equal(t, []string{"rule3"}, unknown)
+	assert.Equal(t, "desc1", rules[0].Desc)
+	assert.Equal(t, driver.RuleLevelError, rules[0].Level)
+	assert.Equal(t, "10", rules[0].Params.GetParam("first_key").Value)
+	// the default rules are not changed.
+	assert.Equal(t, "5", driverRules[0].Params.GetParam("first_key").Value)
+
+	f.Rules[0].Params[0].Value = "ten"
+	_, _, err = f.DriverRules(driverRules)
+	assert.Error(t, err)
+}
@@ -0,0 +1,329 @@
+// Package tokenizer splits SQL text into tokens for the hand-written parsers of
+// PostgreSQL, Oracle and SQL Server. The lexical rules shared by the dialects
+// are implemented here, the differences such as quoting, comments and
+// variables are customized by Dialect.
+package tokenizer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type TokenType int
+
+const (
+	TokenEOF TokenType = iota
+	// TokenIdent is unquoted identifier or keyword, the value is folded by
+	// Dialect.FoldIdent.
+	TokenIdent
+	// TokenQuotedIdent is quoted identifier, the value keeps the case.
+	TokenQuotedIdent
+	TokenString
+	TokenNumber
+	// TokenVariable is parameter or variable, such as $1 in PostgreSQL, :name
+	// in Oracle and @id in SQL Server.
+	TokenVariable
+	TokenOperator
+	TokenPunct
+	// TokenDelimiter is the command of client which separates SQLs, such as
+	// "/" of SQL*Plus and "GO" of sqlcmd.
+	TokenDelimiter
+)
+
+type Token struct {
+	Type TokenType
+	// Value is the normalized value of token, the identifier is folded, the
+	// quotes of identifier and string are removed.
+	Value string
+	// Pos and End are the byte offsets of token in SQL text.
+	Pos int
+	End int
+}
+
+func (t Token) Is(typ TokenType, value string) bool {
+	return t.Type == typ && t.Value == value
+}
+
+// IsKeyword returns true if token is the unquoted identifier kw, kw must be
+// folded as Dialect.FoldIdent does.
+func (t Token) IsKeyword(kw string) bool {
+	return t.Is(TokenIdent, kw)
+}
+
+func (t Token) IsPunct(p string) bool {
+	return t.Is(TokenPunct, p)
+}
+
+// IsName returns true if token can be used as name of object.
+func (t Token) IsName() bool {
+	return t.Type == TokenIdent || t.Type == TokenQuotedIdent
+}
+
+// IsLiteral returns true if token is string or number literal.
+func (t Token) IsLiteral() bool {
+	return t.Type == TokenString || t.Type == TokenNumber
+}
+
+// Dialect is the lexical rules which differ between SQL dialects.
+type Dialect struct {
+	// FoldIdent normalizes the unquoted identifier, such as strings.ToUpper.
+	FoldIdent func(string) string
+	// IdentStart and IdentPart are the characters allowed in unquoted
+	// identifier besides letters, and digits for IdentPart.
+	IdentStart string
+	IdentPart  string
+	// NestedComment is true if the block comments can be nested.
+	NestedComment bool
+	Puncts        string
+	// Operators is the characters of operator, an operator is one of them or
+	// one of TwoCharOperators.
+	Operators        string
+	TwoCharOperators []string
+	// NumberUnderscore allows "_" in the digits of number, such as 1_000.
+	NumberUnderscore bool
+	// NumberSuffixes is the characters allowed at the end of number, such as
+	// "f" of 1.5f.
+	NumberSuffixes string
+	// Scan scans the token of dialect before the shared rules, such as the
+	// prefixed string and the variable. ok is false if there is no such token
+	// at the position of scanner.
+	Scan func(s *Scanner) (t Token, ok bool, err error)
+}
+
+// Scanner is the state of tokenizing, it is used by Dialect.Scan.
+type Scanner struct {
+	sql string
+	pos int
+	d   *Dialect
+}
+
+// Tokenize splits sql into tokens of dialect d, the comments and white spaces
+// are dropped.
+func Tokenize(sql string, d *Dialect) ([]Token, error) {
+	s := &Scanner{sql: sql, d: d}
+	var tokens []Token
+	for {
+		t, err := s.next()
+		if err != nil {
+			return nil, err
+		}
+		if t.Type == TokenEOF {
+			return tokens, nil
+		}
+		tokens = append(tokens, t)
+	}
+}
+
+// SQL returns the SQL text which is tokenized.
+func (s *Scanner) SQL() string {
+	return s.sql
+}
+
+// Pos returns the byte offset of the next character to be scanned.
+func (s *Scanner) Pos() int {
+	return s.pos
+}
+
+// Peek returns the character at offset after Pos, it is 0 at the end of SQL.
+func (s *Scanner) Peek(offset int) byte {
+	if s.pos+offset < len(s.sql) {
+		return s.sql[s.pos+offset]
+	}
+	return 0
+}
+
+// Skip moves forward n bytes.
+func (s *Scanner) Skip(n int) {
+	s.pos += n
+	if s.pos > len(s.sql) {
+		s.pos = len(s.sql)
+	}
+}
+
+// Token returns the token which begins at start and ends at Pos.
+func (s *Scanner) Token(typ TokenType, value string, start int) Token {
+	return Token{Type: typ, Value: value, Pos: start, End: s.pos}
+}
+
+// ScanQuoted scans the quoted string or identifier which begins at start, Pos
+// must be at the open quote. The close quote is escaped by doubling it, or by
+// backslash if backslashEscape is true.
+func (s *Scanner) ScanQuoted(start int, typ TokenType, close byte, backslashEscape bool) (Token, error) {
+	s.pos++
+	var b strings.Builder
+	for s.pos < len(s.sql) {
+		c := s.sql[s.pos]
+		switch {
+		case backslashEscape && c == '\\' && s.pos+1 < len(s.sql):
+			b.WriteByte(s.sql[s.pos+1])
+			s.pos += 2
+		case c == close && s.Peek(1) == close:
+			b.WriteByte(close)
+			s.pos += 2
+		case c == close:
+			s.pos++
+			return s.Token(typ, b.String(), start), nil
+		default:
+			b.WriteByte(c)
+			s.pos++
+		}
+	}
+	if typ == TokenQuotedIdent {
+		return Token{}, fmt.Errorf("unterminated quoted identifier at position %d", start)
+	}
+	return Token{}, fmt.Errorf("unterminated quoted string at position %d", start)
+}
+
+// ScanIdent moves forward over the characters of unquoted identifier.
+func (s *Scanner) ScanIdent() {
+	for s.pos < len(s.sql) && s.IsIdentPart(s.sql[s.pos:]) {
+		_, size := utf8.DecodeRuneInString(s.sql[s.pos:])
+		s.pos += size
+	}
+}
+
+// IsIdentStart returns true if str starts with the character which can begin
+// unquoted identifier.
+func (s *Scanner) IsIdentStart(str string) bool {
+	r, _ := utf8.DecodeRuneInString(str)
+	return unicode.IsLetter(r) || (r < utf8.RuneSelf && r != 0 && strings.ContainsRune(s.d.IdentStart, r))
+}
+
+// IsIdentPart returns true if str starts with the character which can be in
+// unquoted identifier.
+func (s *Scanner) IsIdentPart(str string) bool {
+	r, _ := utf8.DecodeRuneInString(str)
+	return unicode.IsLetter(r) || unicode.IsDigit(r) ||
+		(r < utf8.RuneSelf && r != 0 && strings.ContainsRune(s.d.IdentPart, r))
+}
+
+// IsLineStart returns true if there are only white spaces before pos in its line.
+func (s *Scanner) IsLineStart(pos int) bool {
+	for i := pos - 1; i >= 0 && s.sql[i] != '\n'; i-- {
+		if !unicode.IsSpace(rune(s.sql[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Scanner) skipSpaceAndComment() error {
+	for s.pos < len(s.sql) {
+		c := s.sql[s.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f':
+			s.pos++
+		case c == '-' && s.Peek(1) == '-':
+			end := strings.IndexByte(s.sql[s.pos:], '\n')
+			if end < 0 {
+				s.pos = len(s.sql)
+			} else {
+				s.pos += end + 1
+			}
+		case c == '/' && s.Peek(1) == '*':
+			if err := s.skipBlockComment(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+func (s *Scanner) skipBlockComment() error {
+	start := s.pos
+	depth := 0
+	for s.pos < len(s.sql) {
+		switch {
+		case s.sql[s.pos] == '/' && s.Peek(1) == '*' && (depth == 0 || s.d.NestedComment):
+			depth++
+			s.pos += 2
+		case s.sql[s.pos] == '*' && s.Peek(1) == '/':
+			depth--
+			s.pos += 2
+			if depth == 0 {
+				return nil
+			}
+		default:
+			s.pos++
+		}
+	}
+	return fmt.Errorf("unterminated /* comment at position %d", start)
+}
+
+func (s *Scanner) next() (Token, error) {
+	if err := s.skipSpaceAndComment(); err != nil {
+		return Token{}, err
+	}
+	if s.pos >= len(s.sql) {
+		return s.Token(TokenEOF, "", s.pos), nil
+	}
+
+	if s.d.Scan != nil {
+		if t, ok, err := s.d.Scan(s); err != nil || ok {
+			return t, err
+		}
+	}
+
+	start := s.pos
+	c := s.sql[s.pos]
+	switch {
+	case c == '\'':
+		return s.ScanQuoted(start, TokenString, '\'', false)
+	case c == '"':
+		return s.ScanQuoted(start, TokenQuotedIdent, '"', false)
+	case isDigit(c) || (c == '.' && isDigit(s.Peek(1))):
+		return s.ScanNumber(start), nil
+	case s.IsIdentStart(s.sql[s.pos:]):
+		s.ScanIdent()
+		return s.Token(TokenIdent, s.d.FoldIdent(s.sql[start:s.pos]), start), nil
+	case strings.IndexByte(s.d.Puncts, c) >= 0:
+		s.pos++
+		return s.Token(TokenPunct, string(c), start), nil
+	case strings.IndexByte(s.d.Operators, c) >= 0:
+		s.pos++
+		for _, op := range s.d.TwoCharOperators {
+			if strings.HasPrefix(s.sql[start:], op) {
+				s.pos = start + len(op)
+				break
+			}
+		}
+		return s.Token(TokenOperator, s.sql[start:s.pos], start), nil
+	default:
+		return Token{}, fmt.Errorf("unexpected character %q at position %d", c, start)
+	}
+}
+
+// ScanNumber scans the decimal number which begins at start.
+func (s *Scanner) ScanNumber(start int) Token {
+	for s.pos < len(s.sql) && (isDigit(s.sql[s.pos]) || (s.d.NumberUnderscore && s.sql[s.pos] == '_')) {
+		s.pos++
+	}
+	// ".." is not a part of number, such as the range "FOR i IN 1..10" of PL/SQL.
+	if s.pos < len(s.sql) && s.sql[s.pos] == '.' && s.Peek(1) != '.' {
+		s.pos++
+		for s.pos < len(s.sql) && isDigit(s.sql[s.pos]) {
+			s.pos++
+		}
+	}
+	if s.pos < len(s.sql) && (s.sql[s.pos] == 'e' || s.sql[s.pos] == 'E') {
+		next := s.Peek(1)
+		if isDigit(next) || ((next == '+' || next == '-') && isDigit(s.Peek(2))) {
+			s.pos += 2
+			for s.pos < len(s.sql) && isDigit(s.sql[s.pos]) {
+				s.pos++
+			}
+		}
+	}
+	if s.pos < len(s.sql) && strings.IndexByte(s.d.NumberSuffixes, s.sql[s.pos]) >= 0 && !s.IsIdentPart(s.sql[s.pos+1:]) {
+		s.pos++
+	}
+	return s.Token(TokenNumber, s.sql[start:s.pos], start)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,66 @@
+package tokenizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenize(t *testing.T) {
+	d := &Dialect{
+		FoldIdent:        strings.ToUpper,
+		IdentStart:       "_",
+		IdentPart:        "_$",
+		Puncts:           "(),;.",
+		Operators:        "<>=!:",
+		TwoCharOperators: []string{"<>", ":="},
+		NumberSuffixes:   "f",
+		Scan: func(s *Scanner) (Token, bool, error) {
+			start := s.Pos()
+			if s.Peek(0) == '@' && s.IsIdentStart(s.SQL()[start+1:]) {
+				s.Skip(1)
+				s.ScanIdent()
+				return s.Token(TokenVariable, s.SQL()[start:s.Pos()], start), true, nil
+			}
+			return Token{}, false, nil
+		},
+	}
+	tokens, err := Tokenize(`select "a""b", 'it''s', 1.5f, 1e3 /* c */ from t_1 -- c
+where x <> @v_1;`, d)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	var values []string
+	var types []TokenType
+	for _, token := range tokens {
+		values = append(values, token.Value)
+		types = append(types, token.Type)
+	}
+	assert.Equal(t, []string{"SELECT", `a"b`, ",", "it's", ",", "1.5f", ",", "1e3", "FROM", "T_1",
+		"WHERE", "X", "<>", "@v_1", ";"}, values)
+	assert.Equal(t, []TokenType{TokenIdent, TokenQuotedIdent, TokenPunct, TokenString, TokenPunct, TokenNumber,
+		TokenPunct, TokenNumber, TokenIdent, TokenIdent, TokenIdent, TokenIdent, TokenOperator, TokenVariable,
+		TokenPunct}, types)
+	assert.Equal(t, 7, tokens[1].Pos)
+	assert.Equal(t, 13, tokens[1].End)
+
+	d.NestedComment = true
+	tokens, err = Tokenize("/* a /* b */ c */ x", d)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []Token{{Type: TokenIdent, Value: "X", Pos: 18, End: 19}}, tokens)
+
+	d.NestedComment = false
+	tokens, err = Tokenize("/* a /* b */ x", d)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, []Token{{Type: TokenIdent, Value: "X", Pos: 13, End: 14}}, tokens)
+
+	for _, sql := range []string{"'abc", `"abc`, "/* abc", "a ? b"} {
+		_, err = Tokenize(sql, d)
+		assert.Error(t, err, sql)
+	}
+}
@@ -30,49 +30,90 @@ func audit(l *logrus.Entry, task *model.Task, d driver.Driver) (err error) {
 	if err != nil {
 		return err
 	}
-	for _, executeSQL := range task.ExecuteSQLs {
-		// We always trust the ExecuteSQL.Content is single SQL.
-		//
-		// The audit() function has two producers for now:
-		// 1. from API controller
-		//		- the API controller should call Parse before audit.
-		//      - If Parse() can not splits SQL to expected case, user can add SQL to whitelist for workaround.
-		// 2. from audit plan
-		//		- the audit plan may collect SQLs which plugins can not Parse.
-		//      - In these case, we pass the raw SQL to plugins, it's ok.
-		node, err := parse(l, d, executeSQL.Content)
-		if err != nil {
-			return err
+	exemptions, err := st.GetUnexpiredRuleExemptions()
+	if err != nil {
+		return err
+	}
+	exemptionMatcher := newRuleExemptionMatcher(l, task, d, exemptions)
+
+	// the fingerprints of whitelist are parsed once for all SQLs of task.
+	fpWhitelist := []string{}
+	for _, wl := range whitelist {
+		if wl.MatchType == model.SQLWhitelistFPMatch {
+			fpWhitelist = append(fpWhitelist, wl.Value)
 		}
-		var whitelistMatch bool
+	}
+	fpNodes, err := parseSQLs(l, d, fpWhitelist)
+	if err != nil {
+		return err
+	}
+	whitelistFingerprints := make(map[string]struct{}, len(fpNodes))
+	for _, node := range fpNodes {
+		whitelistFingerprints[node.Fingerprint] = struct{}{}
+	}
+
+	// We always trust the ExecuteSQL.Content is single SQL.
+	//
+	// The audit() function has two producers for now:
+	// 1. from API controller
+	//		- the API controller should call Parse before audit.
+	//      - If Parse() can not splits SQL to expected case, user can add SQL to whitelist for workaround.
+	// 2. from audit plan
+	//		- the audit plan may collect SQLs which plugins can not Parse.
+	//      - In these case, we pass the raw SQL to plugins, it's ok.
+	sqls := make([]string, 0, len(task.ExecuteSQLs))
+	for _, executeSQL := range task.ExecuteSQLs {
+		sqls = append(sqls, executeSQL.Content)
+	}
+	nodes, err := parseSQLs(l, d, sqls)
+	if err != nil {
+		return err
+	}
+	results := make([]*driver.AuditResult, len(task.ExecuteSQLs))
+
+	// auditSQLs keep the SQLs which not match whitelist, they are audited in
+	// one batch, the order of SQLs is kept for the SQL context in driver.
+	auditSQLs := []string{}
+	auditSQLIdxs := []int{}
+	for idx, executeSQL := range task.ExecuteSQLs {
+		node := nodes[idx]
+		_, whitelistMatch := whitelistFingerprints[node.Fingerprint]
 		for _, wl := range whitelist {
-			if wl.MatchType == model.SQLWhitelistFPMatch {
-				wlNode, err := parse(l, d, wl.Value)
-				if err != nil {
-					return err
-				}
-				if node.Fingerprint == wlNode.Fingerprint {
-					whitelistMatch = true
-				}
-			} else {
-				if wl.CapitalizedValue == strings.ToUpper(node.Text) {
-					whitelistMatch = true
-				}
+			if wl.MatchType != model.SQLWhitelistFPMatch && wl.CapitalizedValue == strings.ToUpper(node.Text) {
+				whitelistMatch = true
 			}
 		}
-		result := driver.NewInspectResults()
 		if whitelistMatch {
+			result := driver.NewInspectResults()
 			result.Add(driver.RuleLevelNormal, "白名单")
+			results[idx] = result
 		} else {
-			result, err = d.Audit(context.TODO(), executeSQL.Content)
-			if err != nil {
-				return err
-			}
+			auditSQLs = append(auditSQLs, executeSQL.Content)
+			auditSQLIdxs = append(auditSQLIdxs, idx)
+		}
+	}
+
+	if len(auditSQLs) > 0 {
+		auditResults, err := d.AuditBatch(context.TODO(), auditSQLs)
+		if err != nil {
+			return err
 		}
+		if len(auditResults) != len(auditSQLs) {
+			return fmt.Errorf("expect %d audit results, but got %d", len(auditSQLs), len(auditResults))
+		}
+		for i, idx := range auditSQLIdxs {
+			results[idx] = auditResults[i]
+		}
+	}
+
+	for idx, executeSQL := range task.ExecuteSQLs {
+		result := results[idx]
+		effective, exempted := exemptionMatcher.apply(result, nodes[idx])
 		executeSQL.AuditStatus = model.SQLAuditStatusFinished
-		executeSQL.AuditLevel = string(result.Level())
-		executeSQL.AuditResult = result.Message()
-		executeSQL.AuditFingerprint = utils.Md5String(string(append([]byte(result.Message()), []byte(node.Fingerprint)...)))
+		executeSQL.AuditLevel = string(effective.Level())
+		executeSQL.AuditResult = auditResultMessage(result, effective, exempted)
+		executeSQL.AuditResults = convertAuditResultToModel(result, exempted)
+		executeSQL.AuditFingerprint = utils.Md5String(string(append([]byte(executeSQL.AuditResult), []byte(nodes[idx].Fingerprint)...)))
 
 		l.WithFields(logrus.Fields{
 			"SQL":    executeSQL.Content,
@@ -80,12 +121,37 @@ func audit(l *logrus.Entry, task *model.Task, d driver.Driver) (err error) {
 			"result": executeSQL.AuditResult}).Info("audit finished")
 	}
 
-	replenishTaskStatistics(task)
+	ReplenishTaskStatistics(task)
 
 	return nil
 }
 
-func replenishTaskStatistics(task *model.Task) {
+func convertAuditResultToModel(result *driver.AuditResult,
+	exempted map[*driver.AuditResultItem]*model.RuleExemption) []*model.ExecuteSQLAuditResult {
+	results := make([]*model.ExecuteSQLAuditResult, 0, len(result.Results()))
+	for _, item := range result.Results() {
+		r := &model.ExecuteSQLAuditResult{
+			Level:      string(item.Level),
+			Message:    item.Message,
+			RuleName:   item.RuleName,
+			Category:   item.Category,
+			Suggestion: item.Suggestion,
+		}
+		if item.Position != nil {
+			r.PositionOffset = item.Position.Offset
+			r.PositionLine = item.Position.Line
+		}
+		if e, ok := exempted[item]; ok {
+			r.ExemptionId = e.ID
+		}
+		results = append(results, r)
+	}
+	return results
+}
+
+// ReplenishTaskStatistics sets the pass rate, audit level and score of task by
+// the audit levels of its SQLs.
+func ReplenishTaskStatistics(task *model.Task) {
 	var normalCount float64
 	maxAuditLevel := driver.RuleLevelNull
 	for _, executeSQL := range task.ExecuteSQLs {
@@ -192,6 +258,56 @@ func parse(l *logrus.Entry, d driver.Driver, sql string) (node driver.Node, err
 	return nodes[0], nil
 }
 
+// parseSQLs parses the single SQLs in one Parse call, the SQLs are joined by
+// ";". It falls back to parse the SQLs one by one if the joined text is not
+// split back to the SQLs, such as the SQL can not be parsed or contains the
+// delimiter of driver.
+func parseSQLs(l *logrus.Entry, d driver.Driver, sqls []string) ([]driver.Node, error) {
+	if len(sqls) > 1 {
+		buf := strings.Builder{}
+		for _, sql := range sqls {
+			sql = strings.TrimSpace(sql)
+			buf.WriteString(sql)
+			if !strings.HasSuffix(sql, ";") {
+				buf.WriteString(";")
+			}
+			buf.WriteString("\n")
+		}
+		nodes, err := d.Parse(context.TODO(), buf.String())
+		if err == nil && isSplitBack(nodes, sqls) {
+			for i := range nodes {
+				nodes[i].Text = sqls[i]
+			}
+			return nodes, nil
+		}
+	}
+
+	nodes := make([]driver.Node, 0, len(sqls))
+	for _, sql := range sqls {
+		node, err := parse(l, d, sql)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func isSplitBack(nodes []driver.Node, sqls []string) bool {
+	if len(nodes) != len(sqls) {
+		return false
+	}
+	trim := func(sql string) string {
+		return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(sql), ";"))
+	}
+	for i := range nodes {
+		if trim(nodes[i].Text) != trim(sqls[i]) {
+			return false
+		}
+	}
+	return true
+}
+
 func genRollbackSQL(l *logrus.Entry, task *model.Task, d driver.Driver) ([]*model.RollbackSQL, error) {
 	rollbackSQLs := make([]*model.RollbackSQL, 0, len(task.ExecuteSQLs))
 	for _, executeSQL := range task.ExecuteSQLs {
@@ -200,11 +316,23 @@ func genRollbackSQL(l *logrus.Entry, task *model.Task, d driver.Driver) ([]*mode
 			l.Errorf("gen rollback sql error, %v", err)
 			return nil, err
 		}
-		result := driver.NewInspectResults()
-		result.Add(driver.RuleLevel(executeSQL.AuditLevel), executeSQL.AuditResult)
-		result.Add(driver.RuleLevelNotice, reason)
-		executeSQL.AuditLevel = string(result.Level())
-		executeSQL.AuditResult = result.Message()
+		// the reason is appended to the audit result, the exempted findings in
+		// audit result are kept even if the audit level is empty.
+		if reason != "" {
+			if driver.RuleLevelNotice.More(driver.RuleLevel(executeSQL.AuditLevel)) {
+				executeSQL.AuditLevel = string(driver.RuleLevelNotice)
+			}
+			item := &driver.AuditResultItem{Level: driver.RuleLevelNotice, Message: reason}
+			if executeSQL.AuditResult == "" {
+				executeSQL.AuditResult = item.String()
+			} else {
+				executeSQL.AuditResult = executeSQL.AuditResult + "\n" + item.String()
+			}
+			executeSQL.AuditResults = append(executeSQL.AuditResults, &model.ExecuteSQLAuditResult{
+				Level:   string(driver.RuleLevelNotice),
+				Message: reason,
+			})
+		}
 
 		rollbackSQLs = append(rollbackSQLs, &model.RollbackSQL{
 			BaseSQL: model.BaseSQL{
@@ -3,6 +3,8 @@ package auditplan
 import (
 	"fmt"
 
+	"github.com/actiontech/sqle/sqle/driver/mysql/executor"
+	"github.com/actiontech/sqle/sqle/pkg/mssql"
 	"github.com/actiontech/sqle/sqle/pkg/oracle"
 	"github.com/actiontech/sqle/sqle/pkg/params"
 )
@@ -18,8 +20,14 @@ const (
 	TypeDefault         = "default"
 	TypeMySQLSlowLog    = "mysql_slow_log"
 	TypeMySQLMybatis    = "mysql_mybatis"
+	TypeMySQLFlyway     = "mysql_flyway"
+	TypeMySQLLiquibase  = "mysql_liquibase"
+	TypeMySQLGoSource   = "mysql_go_source"
+	TypeMySQLGitRepo    = "mysql_git_repository"
 	TypeMySQLSchemaMeta = "mysql_schema_meta"
+	TypeMySQLDigest     = "mysql_performance_schema_digest"
 	TypeOracleTopSQL    = "oracle_top_sql"
+	TypeMssqlTopSQL     = "mssql_top_sql"
 	TypeAllAppExtract   = "all_app_extract"
 )
 
@@ -27,10 +35,14 @@ const (
 	InstanceTypeAll    = ""
 	InstanceTypeMySQL  = "mysql"
 	InstanceTypeOracle = "Oracle"
+	InstanceTypeMssql  = "SQL Server"
 )
 
 const (
 	paramKeyCollectIntervalMinute = "collect_interval_minute"
+	paramKeyRepositoryPath        = "repository_path"
+	paramKeyBranch                = "branch"
+	paramKeyFilePatterns          = "file_patterns"
 )
 
 var Metas = []Meta{
@@ -49,6 +61,46 @@ var Metas = []Meta{
 		Desc:         "Mybatis 扫描",
 		InstanceType: InstanceTypeMySQL,
 	},
+	{
+		Type:         TypeMySQLFlyway,
+		Desc:         "Flyway 迁移脚本扫描",
+		InstanceType: InstanceTypeMySQL,
+	},
+	{
+		Type:         TypeMySQLLiquibase,
+		Desc:         "Liquibase 变更集扫描",
+		InstanceType: InstanceTypeMySQL,
+	},
+	{
+		Type:         TypeMySQLGoSource,
+		Desc:         "Go 源码扫描",
+		InstanceType: InstanceTypeMySQL,
+	},
+	{
+		Type:         TypeMySQLGitRepo,
+		Desc:         "Git 仓库 SQL 变更",
+		InstanceType: InstanceTypeMySQL,
+		Params: []*params.Param{
+			{
+				Key:   paramKeyRepositoryPath,
+				Desc:  "仓库本地路径（支持 bare 镜像仓库）",
+				Value: "",
+				Type:  params.ParamTypeString,
+			},
+			{
+				Key:   paramKeyBranch,
+				Desc:  "分支",
+				Value: "master",
+				Type:  params.ParamTypeString,
+			},
+			{
+				Key:   paramKeyFilePatterns,
+				Desc:  "文件匹配规则（逗号分隔，不含\"/\"时匹配文件名）",
+				Value: "*.sql,*.xml",
+				Type:  params.ParamTypeString,
+			},
+		},
+	},
 	{
 		Type:         TypeMySQLSchemaMeta,
 		Desc:         "库表元数据",
@@ -68,6 +120,31 @@ var Metas = []Meta{
 			},
 		},
 	},
+	{
+		Type:         TypeMySQLDigest,
+		Desc:         "performance_schema TOP SQL",
+		InstanceType: InstanceTypeMySQL,
+		Params: []*params.Param{
+			{
+				Key:   paramKeyCollectIntervalMinute,
+				Desc:  "采集周期（分钟）",
+				Value: "60",
+				Type:  params.ParamTypeInt,
+			},
+			{
+				Key:   "top_n",
+				Desc:  "Top N",
+				Value: "3",
+				Type:  params.ParamTypeInt,
+			},
+			{
+				Key:   "order_by_column",
+				Desc:  "events_statements_summary_by_digest中的排序字段",
+				Value: executor.StatementDigestColumnSumTimerWait,
+				Type:  params.ParamTypeString,
+			},
+		},
+	},
 	{
 		Type:         TypeOracleTopSQL,
 		Desc:         "Oracle TOP SQL",
@@ -93,6 +170,31 @@ var Metas = []Meta{
 			},
 		},
 	},
+	{
+		Type:         TypeMssqlTopSQL,
+		Desc:         "SQL Server TOP SQL",
+		InstanceType: InstanceTypeMssql,
+		Params: []*params.Param{
+			{
+				Key:   paramKeyCollectIntervalMinute,
+				Desc:  "采集周期（分钟）",
+				Value: "60",
+				Type:  params.ParamTypeInt,
+			},
+			{
+				Key:   "top_n",
+				Desc:  "Top N",
+				Value: "3",
+				Type:  params.ParamTypeInt,
+			},
+			{
+				Key:   "order_by_column",
+				Desc:  "sys.dm_exec_query_stats中的排序字段",
+				Value: mssql.DynExecQueryStatsColumnTotalElapsedTime,
+				Type:  params.ParamTypeString,
+			},
+		},
+	},
 	{
 		Type:         TypeAllAppExtract,
 		Desc:         "应用程序SQL抓取",
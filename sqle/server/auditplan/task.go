@@ -4,15 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"path"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/actiontech/sqle/sqle/driver"
 	"github.com/actiontech/sqle/sqle/driver/mysql/executor"
+	"github.com/actiontech/sqle/sqle/driver/mysql/util"
 	"github.com/actiontech/sqle/sqle/errors"
 	"github.com/actiontech/sqle/sqle/log"
 	"github.com/actiontech/sqle/sqle/model"
+	"github.com/actiontech/sqle/sqle/pkg/git"
+	"github.com/actiontech/sqle/sqle/pkg/mssql"
+	"github.com/actiontech/sqle/sqle/pkg/mybatis"
 	"github.com/actiontech/sqle/sqle/pkg/oracle"
 	"github.com/actiontech/sqle/sqle/server"
 	"github.com/actiontech/sqle/sqle/utils"
@@ -21,6 +27,8 @@ import (
 )
 
 var errNoSQLInAuditPlan = errors.New(errors.DataConflict, fmt.Errorf("there is no SQLs in audit plan"))
+var errNoSampleSQLInAuditPlan = errors.New(errors.DataConflict,
+	fmt.Errorf("there is no sample SQLs in audit plan, the digests are not audited before MySQL 8.0.3"))
 
 type Task interface {
 	Start() error
@@ -46,10 +54,20 @@ type SQL struct {
 func NewTask(entry *logrus.Entry, ap *model.AuditPlan) Task {
 	entry = entry.WithField("name", ap.Name)
 	switch ap.Type {
+	case TypeMySQLSlowLog:
+		return NewSlowLogTask(entry, ap)
+	case TypeMySQLMybatis, TypeMySQLFlyway, TypeMySQLLiquibase, TypeMySQLGoSource:
+		return NewSourceCodeTask(entry, ap)
+	case TypeMySQLGitRepo:
+		return NewGitRepositoryTask(entry, ap)
 	case TypeMySQLSchemaMeta:
 		return NewSchemaMetaTask(entry, ap)
+	case TypeMySQLDigest:
+		return NewMySQLDigestTask(entry, ap)
 	case TypeOracleTopSQL:
 		return NewOracleTopSQLTask(entry, ap)
+	case TypeMssqlTopSQL:
+		return NewMssqlTopSQLTask(entry, ap)
 	default:
 		return NewDefaultTask(entry, ap)
 	}
@@ -90,19 +108,33 @@ func (at *baseTask) audit(task *model.Task) (*model.AuditPlanReportV2, error) {
 	}
 
 	for i, sql := range auditPlanSQLs {
-		task.ExecuteSQLs = append(task.ExecuteSQLs, &model.ExecuteSQL{
+		executeSQL := &model.ExecuteSQL{
 			BaseSQL: model.BaseSQL{
 				Number:  uint(i),
 				Content: sql.SQLContent,
 			},
-		})
+		}
+		// the SQLs uploaded by scanners of source files have location in info.
+		var location = struct {
+			FilePath string `json:"file_path"`
+			Line     int    `json:"line"`
+		}{}
+		if len(sql.Info) > 0 && json.Unmarshal(sql.Info, &location) == nil {
+			executeSQL.SourceFile = location.FilePath
+			executeSQL.SourceLine = location.Line
+		}
+		task.ExecuteSQLs = append(task.ExecuteSQLs, executeSQL)
 	}
 
 	err = server.Audit(at.logger, task)
 	if err != nil {
 		return nil, err
 	}
+	return at.saveReport(task)
+}
 
+// saveReport saves the audited task as the report of audit plan.
+func (at *baseTask) saveReport(task *model.Task) (*model.AuditPlanReportV2, error) {
 	auditPlanReport := &model.AuditPlanReportV2{
 		AuditPlanID: at.ap.ID,
 		PassRate:    task.PassRate,
@@ -110,12 +142,30 @@ func (at *baseTask) audit(task *model.Task) (*model.AuditPlanReportV2, error) {
 		AuditLevel:  task.AuditLevel,
 	}
 	for _, executeSQL := range task.ExecuteSQLs {
-		auditPlanReport.AuditPlanReportSQLs = append(auditPlanReport.AuditPlanReportSQLs, &model.AuditPlanReportSQLV2{
+		reportSQL := &model.AuditPlanReportSQLV2{
 			SQL:         executeSQL.Content,
 			AuditResult: executeSQL.AuditResult,
-		})
+			SourceFile:  executeSQL.SourceFile,
+			SourceLine:  executeSQL.SourceLine,
+		}
+		results := make([]*model.AuditPlanReportSQLResult, 0, len(executeSQL.AuditResults))
+		for _, result := range executeSQL.AuditResults {
+			results = append(results, &model.AuditPlanReportSQLResult{
+				Level:        result.Level,
+				Message:      result.Message,
+				RuleName:     result.RuleName,
+				Category:     result.Category,
+				PositionLine: result.PositionLine,
+				Suggestion:   result.Suggestion,
+				ExemptionId:  result.ExemptionId,
+			})
+		}
+		if err := reportSQL.SetAuditResults(results); err != nil {
+			return nil, err
+		}
+		auditPlanReport.AuditPlanReportSQLs = append(auditPlanReport.AuditPlanReportSQLs, reportSQL)
 	}
-	err = at.persist.Save(auditPlanReport)
+	err := at.persist.Save(auditPlanReport)
 	if err != nil {
 		return nil, err
 	}
@@ -302,6 +352,453 @@ func (at *baseTask) GetSQLs(args map[string]interface{}) ([]Head, []map[string]
 	return head, rows, count, nil
 }
 
+// the keys of slow query metrics in AuditPlanSQLV2.Info, the time is in seconds.
+const (
+	InfoKeyQueryTimeAvg    = "query_time_avg"
+	InfoKeyQueryTimeMax    = "query_time_max"
+	InfoKeyLockTimeAvg     = "lock_time_avg"
+	InfoKeyRowsExaminedAvg = "rows_examined_avg"
+)
+
+// SlowLogTask is the task of SQLs uploaded by slow query scanner, it is same
+// as DefaultTask except that the metrics of slow query are shown.
+type SlowLogTask struct {
+	*DefaultTask
+}
+
+func NewSlowLogTask(entry *logrus.Entry, ap *model.AuditPlan) *SlowLogTask {
+	return &SlowLogTask{NewDefaultTask(entry, ap)}
+}
+
+// PartialSyncSQLs merges the slow query metrics of SQLs, since the slow query
+// scanner uploads the metrics of new queries only.
+func (at *SlowLogTask) PartialSyncSQLs(sqls []*SQL) error {
+	return at.persist.UpdateSlowLogAuditPlanSQLs(at.ap.Name, convertSQLsToModelSQLs(sqls))
+}
+
+func (at *SlowLogTask) GetSQLs(args map[string]interface{}) ([]Head, []map[string] /* head name */ string, uint64, error) {
+	auditPlanSQLs, count, err := at.persist.GetAuditPlanSQLsByReq(args)
+	if err != nil {
+		return nil, nil, count, err
+	}
+	head := []Head{
+		{
+			Name: "fingerprint",
+			Desc: "SQL指纹",
+			Type: "sql",
+		},
+		{
+			Name: "sql",
+			Desc: "最后一次匹配到该指纹的语句",
+			Type: "sql",
+		},
+		{
+			Name: "counter",
+			Desc: "匹配到该指纹的语句数量",
+		},
+		{
+			Name: InfoKeyQueryTimeAvg,
+			Desc: "平均执行时间(s)",
+		},
+		{
+			Name: InfoKeyQueryTimeMax,
+			Desc: "最大执行时间(s)",
+		},
+		{
+			Name: InfoKeyLockTimeAvg,
+			Desc: "平均锁等待时间(s)",
+		},
+		{
+			Name: InfoKeyRowsExaminedAvg,
+			Desc: "平均扫描行数",
+		},
+		{
+			Name: "last_receive_timestamp",
+			Desc: "最后一次匹配到该指纹的时间",
+		},
+	}
+	rows := make([]map[string]string, 0, len(auditPlanSQLs))
+	for _, sql := range auditPlanSQLs {
+		var info = struct {
+			Counter              uint64  `json:"counter"`
+			LastReceiveTimestamp string  `json:"last_receive_timestamp"`
+			QueryTimeAvg         float64 `json:"query_time_avg"`
+			QueryTimeMax         float64 `json:"query_time_max"`
+			LockTimeAvg          float64 `json:"lock_time_avg"`
+			RowsExaminedAvg      float64 `json:"rows_examined_avg"`
+		}{}
+		err := json.Unmarshal(sql.Info, &info)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		rows = append(rows, map[string]string{
+			"sql":                    sql.SQLContent,
+			"fingerprint":            sql.Fingerprint,
+			"counter":                strconv.FormatUint(info.Counter, 10),
+			InfoKeyQueryTimeAvg:      fmt.Sprintf("%v", utils.Round(info.QueryTimeAvg, 3)),
+			InfoKeyQueryTimeMax:      fmt.Sprintf("%v", utils.Round(info.QueryTimeMax, 3)),
+			InfoKeyLockTimeAvg:       fmt.Sprintf("%v", utils.Round(info.LockTimeAvg, 3)),
+			InfoKeyRowsExaminedAvg:   fmt.Sprintf("%v", utils.Round(info.RowsExaminedAvg, 0)),
+			"last_receive_timestamp": info.LastReceiveTimestamp,
+		})
+	}
+	return head, rows, count, nil
+}
+
+// the keys of SQL location in AuditPlanSQLV2.Info, they are uploaded by the
+// scanners which extract SQL from source files.
+const (
+	InfoKeyFilePath = "file_path"
+	InfoKeyLine     = "line"
+)
+
+// SourceCodeTask is the task of SQLs extracted from source files, it is same
+// as DefaultTask except that the location of SQL is shown.
+type SourceCodeTask struct {
+	*DefaultTask
+}
+
+func NewSourceCodeTask(entry *logrus.Entry, ap *model.AuditPlan) *SourceCodeTask {
+	return &SourceCodeTask{NewDefaultTask(entry, ap)}
+}
+
+func (at *SourceCodeTask) GetSQLs(args map[string]interface{}) ([]Head, []map[string] /* head name */ string, uint64, error) {
+	auditPlanSQLs, count, err := at.persist.GetAuditPlanSQLsByReq(args)
+	if err != nil {
+		return nil, nil, count, err
+	}
+	head := []Head{
+		{
+			Name: "fingerprint",
+			Desc: "SQL指纹",
+			Type: "sql",
+		},
+		{
+			Name: "sql",
+			Desc: "最后一次匹配到该指纹的语句",
+			Type: "sql",
+		},
+		{
+			Name: "counter",
+			Desc: "匹配到该指纹的语句数量",
+		},
+		{
+			Name: InfoKeyFilePath,
+			Desc: "文件",
+		},
+		{
+			Name: InfoKeyLine,
+			Desc: "行号",
+		},
+		{
+			Name: "last_receive_timestamp",
+			Desc: "最后一次匹配到该指纹的时间",
+		},
+	}
+	rows := make([]map[string]string, 0, len(auditPlanSQLs))
+	for _, sql := range auditPlanSQLs {
+		var info = struct {
+			Counter              uint64 `json:"counter"`
+			LastReceiveTimestamp string `json:"last_receive_timestamp"`
+			FilePath             string `json:"file_path"`
+			Line                 int    `json:"line"`
+		}{}
+		err := json.Unmarshal(sql.Info, &info)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		rows = append(rows, map[string]string{
+			"sql":                    sql.SQLContent,
+			"fingerprint":            sql.Fingerprint,
+			"counter":                strconv.FormatUint(info.Counter, 10),
+			InfoKeyFilePath:          info.FilePath,
+			InfoKeyLine:              strconv.Itoa(info.Line),
+			"last_receive_timestamp": info.LastReceiveTimestamp,
+		})
+	}
+	return head, rows, count, nil
+}
+
+// the keys of commit in AuditPlanSQLV2.Info, they are the last commit which
+// changes the file of SQL.
+const (
+	InfoKeyCommit     = "commit"
+	InfoKeyAuthor     = "author"
+	InfoKeyCommitTime = "commit_time"
+)
+
+var errNoSQLChangedInRepository = errors.New(errors.DataConflict, fmt.Errorf("there is no SQL changed in git repository since last audit"))
+
+// GitRepositoryTask implement the Task interface.
+//
+// GitRepositoryTask audits the SQLs which are added or changed in the branch
+// of local git repository since the last audited commit, the SQLs are read
+// from the SQL files and MyBatis mapper XML files which match the patterns.
+type GitRepositoryTask struct {
+	*DefaultTask
+}
+
+func NewGitRepositoryTask(entry *logrus.Entry, ap *model.AuditPlan) *GitRepositoryTask {
+	return &GitRepositoryTask{NewDefaultTask(entry, ap)}
+}
+
+func (at *GitRepositoryTask) FullSyncSQLs(sqls []*SQL) error {
+	at.logger.Warnf("someone try to sync sql to audit plan(%v), but sql should collected by task itself", at.ap.Name)
+	return nil
+}
+
+func (at *GitRepositoryTask) PartialSyncSQLs(sqls []*SQL) error {
+	at.logger.Warnf("someone try to sync sql to audit plan(%v), but sql should collected by task itself", at.ap.Name)
+	return nil
+}
+
+func (at *GitRepositoryTask) Audit() (*model.AuditPlanReportV2, error) {
+	repo, err := git.NewRepository(at.ap.Params.GetParam(paramKeyRepositoryPath).String())
+	if err != nil {
+		return nil, err
+	}
+	head, err := repo.ResolveCommit(at.ap.Params.GetParam(paramKeyBranch).String())
+	if err != nil {
+		return nil, err
+	}
+	last := at.ap.LastAuditedCommit
+	if last == head {
+		return nil, errNoSQLChangedInRepository
+	}
+	if last != "" && !repo.HasCommit(last) {
+		at.logger.Warnf("the last audited commit %s is not found in repository, audit all files", last)
+		last = ""
+	}
+
+	sqls, failedFiles, err := at.collectChangedSQLs(repo, last, head)
+	if err != nil {
+		return nil, err
+	}
+	// the changed files which fail to read are audited again next time.
+	updateLastAuditedCommit := func() error {
+		if len(failedFiles) > 0 {
+			at.logger.Warnf("the last audited commit is not updated to %s, since reading %s failed",
+				head, strings.Join(failedFiles, ", "))
+			return nil
+		}
+		return at.updateLastAuditedCommit(head)
+	}
+	if len(sqls) == 0 {
+		if err := updateLastAuditedCommit(); err != nil {
+			return nil, err
+		}
+		return nil, errNoSQLChangedInRepository
+	}
+	at.logger.Infof("%d SQLs are changed from commit %s to %s", len(sqls), last, head)
+
+	err = at.persist.OverrideAuditPlanSQLs(at.ap.Name, convertSQLsToModelSQLs(sqls))
+	if err != nil {
+		return nil, err
+	}
+	report, err := at.DefaultTask.Audit()
+	if err != nil {
+		return nil, err
+	}
+	return report, updateLastAuditedCommit()
+}
+
+func (at *GitRepositoryTask) updateLastAuditedCommit(commit string) error {
+	err := at.persist.UpdateAuditPlanByName(at.ap.Name, map[string]interface{}{"last_audited_commit": commit})
+	if err != nil {
+		return err
+	}
+	at.ap.LastAuditedCommit = commit
+	return nil
+}
+
+// collectChangedSQLs returns the SQLs which are added or changed from commit
+// "last" to "head", all SQLs in "head" are returned if "last" is empty. The
+// files of "head" which fail to read are skipped and returned as failedFiles.
+func (at *GitRepositoryTask) collectChangedSQLs(repo *git.Repository, last, head string) (
+	sqls []*SQL, failedFiles []string, err error) {
+	var files []string
+	if last == "" {
+		files, err = repo.ListFiles(head)
+	} else {
+		files, err = repo.ChangedFiles(last, head)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	patterns := strings.Split(at.ap.Params.GetParam(paramKeyFilePatterns).String(), ",")
+	sqls = []*SQL{}
+	fingerprints := map[string]struct{}{}
+	for _, file := range files {
+		if !matchFilePatterns(file, patterns) {
+			continue
+		}
+		stmts, err := readSQLsFromRepository(repo, head, file)
+		if err != nil {
+			at.logger.Warnf("read SQLs from %s failed, error: %v", file, err)
+			failedFiles = append(failedFiles, file)
+			continue
+		}
+		if last != "" {
+			oldStmts, err := readSQLsFromRepository(repo, last, file)
+			if err != nil {
+				at.logger.Warnf("read SQLs from %s of commit %s failed, error: %v", file, last, err)
+			}
+			stmts = excludeUnchangedSQLs(stmts, oldStmts)
+		}
+		if len(stmts) == 0 {
+			continue
+		}
+
+		commit, err := repo.LastCommit(last, head, file)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, stmt := range stmts {
+			fingerprint, err := util.Fingerprint(stmt.Text, true)
+			if err != nil {
+				fingerprint = stmt.Text
+			}
+			// the fingerprint is unique in audit plan, only the first one is audited.
+			if _, ok := fingerprints[fingerprint]; ok {
+				continue
+			}
+			fingerprints[fingerprint] = struct{}{}
+			sqls = append(sqls, &SQL{
+				SQLContent:  stmt.Text,
+				Fingerprint: fingerprint,
+				Info: map[string]interface{}{
+					InfoKeyFilePath:   file,
+					InfoKeyLine:       stmt.Line,
+					InfoKeyCommit:     commit.Hash,
+					InfoKeyAuthor:     commit.Author,
+					InfoKeyCommitTime: commit.Time.Format(time.RFC3339),
+				},
+			})
+		}
+	}
+	return sqls, failedFiles, nil
+}
+
+// matchFilePatterns returns true if the file matches any pattern, such as
+// "*.sql" or "db/migration/*.sql". The pattern without "/" matches the file
+// name, otherwise it matches the path in repository.
+func matchFilePatterns(file string, patterns []string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		name := file
+		if !strings.Contains(pattern, "/") {
+			name = path.Base(file)
+		}
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// readSQLsFromRepository reads the SQLs from the file in commit, the file is
+// MyBatis mapper XML if its extension is ".xml", otherwise it is SQL file.
+func readSQLsFromRepository(repo *git.Repository, commit, file string) ([]util.StmtLine, error) {
+	content, exist, err := repo.ReadFile(commit, file)
+	if err != nil || !exist {
+		return nil, err
+	}
+	if !strings.EqualFold(path.Ext(file), ".xml") {
+		return util.SplitSqlWithLine(content, 1)
+	}
+
+	queries, err := mybatis.ParseXMLQuery(content, true)
+	if err != nil {
+		return nil, err
+	}
+	stmts := []util.StmtLine{}
+	for _, query := range queries {
+		queryStmts, err := util.SplitSqlWithLine(query.SQL, query.Line)
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, queryStmts...)
+	}
+	return stmts, nil
+}
+
+// excludeUnchangedSQLs returns the SQLs which are not in the old SQLs, the
+// SQLs are compared without the differences of blanks.
+func excludeUnchangedSQLs(sqls, oldSQLs []util.StmtLine) []util.StmtLine {
+	old := make(map[string]struct{}, len(oldSQLs))
+	for _, sql := range oldSQLs {
+		old[strings.Join(strings.Fields(sql.Text), " ")] = struct{}{}
+	}
+	changed := []util.StmtLine{}
+	for _, sql := range sqls {
+		if _, ok := old[strings.Join(strings.Fields(sql.Text), " ")]; !ok {
+			changed = append(changed, sql)
+		}
+	}
+	return changed
+}
+
+func (at *GitRepositoryTask) GetSQLs(args map[string]interface{}) ([]Head, []map[string] /* head name */ string, uint64, error) {
+	auditPlanSQLs, count, err := at.persist.GetAuditPlanSQLsByReq(args)
+	if err != nil {
+		return nil, nil, count, err
+	}
+	head := []Head{
+		{
+			Name: "sql",
+			Desc: "SQL语句",
+			Type: "sql",
+		},
+		{
+			Name: InfoKeyFilePath,
+			Desc: "文件",
+		},
+		{
+			Name: InfoKeyLine,
+			Desc: "行号",
+		},
+		{
+			Name: InfoKeyCommit,
+			Desc: "提交",
+		},
+		{
+			Name: InfoKeyAuthor,
+			Desc: "作者",
+		},
+		{
+			Name: InfoKeyCommitTime,
+			Desc: "提交时间",
+		},
+	}
+	rows := make([]map[string]string, 0, len(auditPlanSQLs))
+	for _, sql := range auditPlanSQLs {
+		var info = struct {
+			FilePath   string `json:"file_path"`
+			Line       int    `json:"line"`
+			Commit     string `json:"commit"`
+			Author     string `json:"author"`
+			CommitTime string `json:"commit_time"`
+		}{}
+		err := json.Unmarshal(sql.Info, &info)
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		rows = append(rows, map[string]string{
+			"sql":             sql.SQLContent,
+			InfoKeyFilePath:   info.FilePath,
+			InfoKeyLine:       strconv.Itoa(info.Line),
+			InfoKeyCommit:     info.Commit,
+			InfoKeyAuthor:     info.Author,
+			InfoKeyCommitTime: info.CommitTime,
+		})
+	}
+	return head, rows, count, nil
+}
+
 type SchemaMetaTask struct {
 	*sqlCollector
 }
@@ -409,6 +906,239 @@ func (at *SchemaMetaTask) GetSQLs(args map[string]interface{}) ([]Head, []map[st
 	return head, rows, count, nil
 }
 
+// MySQLDigestTask implement the Task interface.
+//
+// MySQLDigestTask is a loop task which collect Top SQL from
+// performance_schema.events_statements_summary_by_digest of MySQL instance.
+type MySQLDigestTask struct {
+	*sqlCollector
+}
+
+func NewMySQLDigestTask(entry *logrus.Entry, ap *model.AuditPlan) *MySQLDigestTask {
+	task := &MySQLDigestTask{
+		sqlCollector: newSQLCollector(entry, ap),
+	}
+	task.sqlCollector.do = task.collectorDo
+	return task
+}
+
+func (at *MySQLDigestTask) collectorDo() {
+	select {
+	case <-at.cancel:
+		at.logger.Info("cancel task")
+		return
+	default:
+	}
+
+	if at.ap.InstanceName == "" {
+		at.logger.Warnf("instance is not configured")
+		return
+	}
+
+	inst, _, err := at.persist.GetInstanceByName(at.ap.InstanceName)
+	if err != nil {
+		at.logger.Warnf("get instance fail, error: %v", err)
+		return
+	}
+	db, err := executor.NewExecutor(at.logger, &driver.DSN{
+		Host:             inst.Host,
+		Port:             inst.Port,
+		User:             inst.User,
+		Password:         inst.Password,
+		AdditionalParams: inst.AdditionalParams,
+		DatabaseName:     at.ap.InstanceDatabase,
+	},
+		at.ap.InstanceDatabase)
+	if err != nil {
+		at.logger.Errorf("connect to instance fail, error: %v", err)
+		return
+	}
+	defer db.Db.Close()
+
+	digests, err := db.ShowTopStatementDigests(at.ap.Params.GetParam("top_n").Int(), at.ap.Params.GetParam("order_by_column").String())
+	if err != nil {
+		at.logger.Errorf("query top sql fail, error: %v", err)
+		return
+	}
+	if len(digests) > 0 {
+		apSQLs := make([]*SQL, 0, len(digests))
+		// the same digest text may be collected from different schemas, only the top one is kept.
+		fingerprints := map[string]struct{}{}
+		for _, digest := range digests {
+			if _, ok := fingerprints[digest.DigestText]; ok {
+				continue
+			}
+			fingerprints[digest.DigestText] = struct{}{}
+			// there is no sample SQL before MySQL 8.0.3, the digest text is
+			// kept to show the top SQL, but it is not audited.
+			content := digest.SampleSQL
+			if content == "" {
+				content = digest.DigestText
+			}
+			apSQLs = append(apSQLs, &SQL{
+				SQLContent:  content,
+				Fingerprint: digest.DigestText,
+				Info: map[string]interface{}{
+					"schema_name":                                 digest.Schema,
+					infoKeyDigestTextOnly:                         digest.SampleSQL == "",
+					executor.StatementDigestColumnCountStar:       digest.CountStar,
+					executor.StatementDigestColumnSumTimerWait:    digest.SumTimerWait,
+					executor.StatementDigestColumnSumRowsExamined: digest.SumRowsExamined,
+					"sum_rows_sent":                               digest.SumRowsSent,
+					executor.StatementDigestColumnSumNoIndexUsed:  digest.SumNoIndexUsed,
+					"sum_no_good_index_used":                      digest.SumNoGoodIndexUsed,
+					"last_seen":                                   digest.LastSeen,
+				},
+			})
+		}
+
+		err = at.persist.OverrideAuditPlanSQLs(at.ap.Name, convertSQLsToModelSQLs(apSQLs))
+		if err != nil {
+			at.logger.Errorf("save top sql to storage fail, error: %v", err)
+		}
+	}
+}
+
+// infoKeyDigestTextOnly is true if the SQL content of digest is the digest text,
+// the literals of which are replaced by "?".
+const infoKeyDigestTextOnly = "digest_text_only"
+
+// Audit audits the digests in the schemas where they are executed, the digests
+// without schema are audited in the database of audit plan. The digests without
+// sample SQL are skipped, the rules about literals can not be checked on them.
+func (at *MySQLDigestTask) Audit() (*model.AuditPlanReportV2, error) {
+	task := &model.Task{
+		DBType: at.ap.DBType,
+	}
+	if at.ap.InstanceName != "" {
+		instance, _, err := at.persist.GetInstanceByName(at.ap.InstanceName)
+		if err != nil {
+			return nil, err
+		}
+		task.Instance = instance
+		task.Schema = at.ap.InstanceDatabase
+	}
+
+	auditPlanSQLs, err := at.persist.GetAuditPlanSQLs(at.ap.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(auditPlanSQLs) == 0 {
+		return nil, errNoSQLInAuditPlan
+	}
+
+	schemas := []string{}
+	schemaToSQLs := map[string][]*model.ExecuteSQL{}
+	skipped := 0
+	for _, sql := range auditPlanSQLs {
+		var info = struct {
+			SchemaName     string `json:"schema_name"`
+			DigestTextOnly bool   `json:"digest_text_only"`
+		}{}
+		if len(sql.Info) > 0 {
+			if err := json.Unmarshal(sql.Info, &info); err != nil {
+				return nil, err
+			}
+		}
+		if info.DigestTextOnly {
+			skipped++
+			continue
+		}
+		executeSQL := &model.ExecuteSQL{
+			BaseSQL: model.BaseSQL{
+				Number:  uint(len(task.ExecuteSQLs)),
+				Content: sql.SQLContent,
+			},
+		}
+		task.ExecuteSQLs = append(task.ExecuteSQLs, executeSQL)
+
+		schema := info.SchemaName
+		if schema == "" {
+			schema = at.ap.InstanceDatabase
+		}
+		if _, ok := schemaToSQLs[schema]; !ok {
+			schemas = append(schemas, schema)
+		}
+		schemaToSQLs[schema] = append(schemaToSQLs[schema], executeSQL)
+	}
+	if skipped > 0 {
+		at.logger.Warnf("skip %d digests without sample SQL, QUERY_SAMPLE_TEXT is supported since MySQL 8.0.3", skipped)
+	}
+	if len(task.ExecuteSQLs) == 0 {
+		return nil, errNoSampleSQLInAuditPlan
+	}
+
+	for _, schema := range schemas {
+		schemaTask := &model.Task{
+			Instance:    task.Instance,
+			Schema:      schema,
+			DBType:      task.DBType,
+			ExecuteSQLs: schemaToSQLs[schema],
+		}
+		if err := server.Audit(at.logger, schemaTask); err != nil {
+			return nil, err
+		}
+	}
+	server.ReplenishTaskStatistics(task)
+	return at.saveReport(task)
+}
+
+func (at *MySQLDigestTask) GetSQLs(args map[string]interface{}) ([]Head, []map[string] /* head name */ string, uint64, error) {
+	auditPlanSQLs, count, err := at.persist.GetAuditPlanSQLsByReq(args)
+	if err != nil {
+		return nil, nil, count, err
+	}
+	heads := []Head{
+		{
+			Name: "sql",
+			Desc: "SQL语句",
+			Type: "sql",
+		},
+		{
+			Name: "schema_name",
+			Desc: "Schema",
+		},
+		{
+			Name: executor.StatementDigestColumnCountStar,
+			Desc: "总执行次数",
+		},
+		{
+			Name: executor.StatementDigestColumnSumTimerWait,
+			Desc: "执行时间(s)",
+		},
+		{
+			Name: executor.StatementDigestColumnSumRowsExamined,
+			Desc: "扫描行数",
+		},
+		{
+			Name: executor.StatementDigestColumnSumNoIndexUsed,
+			Desc: "未使用索引次数",
+		},
+		{
+			Name: "last_seen",
+			Desc: "最后执行时间",
+		},
+	}
+	rows := make([]map[string]string, 0, len(auditPlanSQLs))
+	for _, sql := range auditPlanSQLs {
+		info := &executor.StatementDigest{}
+		if err := json.Unmarshal(sql.Info, info); err != nil {
+			return nil, nil, 0, err
+		}
+		rows = append(rows, map[string]string{
+			"sql":                                   sql.SQLContent,
+			"schema_name":                           info.Schema,
+			executor.StatementDigestColumnCountStar: strconv.FormatInt(info.CountStar, 10),
+			// the timer is in picoseconds.
+			executor.StatementDigestColumnSumTimerWait:    fmt.Sprintf("%v", utils.Round(float64(info.SumTimerWait)/1000/1000/1000/1000, 3)),
+			executor.StatementDigestColumnSumRowsExamined: strconv.FormatInt(info.SumRowsExamined, 10),
+			executor.StatementDigestColumnSumNoIndexUsed:  strconv.FormatInt(info.SumNoIndexUsed, 10),
+			"last_seen": info.LastSeen,
+		})
+	}
+	return heads, rows, count, nil
+}
+
 // OracleTopSQLTask implement the Task interface.
 //
 // OracleTopSQLTask is a loop task which collect Top SQL from oracle instance.
@@ -549,3 +1279,144 @@ func (at *OracleTopSQLTask) GetSQLs(args map[string]interface{}) ([]Head, []map[
 	}
 	return heads, rows, count, nil
 }
+
+// MssqlTopSQLTask implement the Task interface.
+//
+// MssqlTopSQLTask is a loop task which collect Top SQL from SQL Server instance.
+type MssqlTopSQLTask struct {
+	*sqlCollector
+}
+
+func NewMssqlTopSQLTask(entry *logrus.Entry, ap *model.AuditPlan) *MssqlTopSQLTask {
+	task := &MssqlTopSQLTask{
+		sqlCollector: newSQLCollector(entry, ap),
+	}
+	task.sqlCollector.do = task.collectorDo
+	return task
+}
+
+func (at *MssqlTopSQLTask) collectorDo() {
+	select {
+	case <-at.cancel:
+		at.logger.Info("cancel task")
+		return
+	default:
+	}
+
+	if at.ap.InstanceName == "" {
+		at.logger.Warnf("instance is not configured")
+		return
+	}
+
+	inst, _, err := at.persist.GetInstanceByName(at.ap.InstanceName)
+	if err != nil {
+		at.logger.Warnf("get instance fail, error: %v", err)
+		return
+	}
+	dsn := &mssql.DSN{
+		Host:     inst.Host,
+		Port:     inst.Port,
+		User:     inst.User,
+		Password: inst.Password,
+		Database: at.ap.InstanceDatabase,
+	}
+	db, err := mssql.NewDB(dsn)
+	if err != nil {
+		at.logger.Errorf("connect to instance fail, error: %v", err)
+		return
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sqls, err := db.QueryTopSQLs(ctx, at.ap.Params.GetParam("top_n").Int(), at.ap.Params.GetParam("order_by_column").String())
+	if err != nil {
+		at.logger.Errorf("query top sql fail, error: %v", err)
+		return
+	}
+	if len(sqls) > 0 {
+		apSQLs := make([]*SQL, 0, len(sqls))
+		for _, sql := range sqls {
+			apSQLs = append(apSQLs, &SQL{
+				SQLContent:  sql.SQLText,
+				Fingerprint: sql.SQLText,
+				Info: map[string]interface{}{
+					mssql.DynExecQueryStatsColumnExecutionCount:     sql.ExecutionCount,
+					mssql.DynExecQueryStatsColumnTotalElapsedTime:   sql.TotalElapsedTime,
+					mssql.DynExecQueryStatsColumnTotalWorkerTime:    sql.TotalWorkerTime,
+					mssql.DynExecQueryStatsColumnTotalLogicalReads:  sql.TotalLogicalReads,
+					mssql.DynExecQueryStatsColumnTotalPhysicalReads: sql.TotalPhysicalReads,
+					mssql.DynExecQueryStatsColumnTotalLogicalWrites: sql.TotalLogicalWrites,
+				},
+			})
+		}
+
+		err = at.persist.OverrideAuditPlanSQLs(at.ap.Name, convertSQLsToModelSQLs(apSQLs))
+		if err != nil {
+			at.logger.Errorf("save top sql to storage fail, error: %v", err)
+		}
+	}
+}
+
+func (at *MssqlTopSQLTask) Audit() (*model.AuditPlanReportV2, error) {
+	task := &model.Task{
+		DBType: at.ap.DBType,
+	}
+	return at.baseTask.audit(task)
+}
+
+func (at *MssqlTopSQLTask) GetSQLs(args map[string]interface{}) ([]Head, []map[string] /* head name */ string, uint64, error) {
+	auditPlanSQLs, count, err := at.persist.GetAuditPlanSQLsByReq(args)
+	if err != nil {
+		return nil, nil, count, err
+	}
+	heads := []Head{
+		{
+			Name: "sql",
+			Desc: "SQL语句",
+			Type: "sql",
+		},
+		{
+			Name: mssql.DynExecQueryStatsColumnExecutionCount,
+			Desc: "总执行次数",
+		},
+		{
+			Name: mssql.DynExecQueryStatsColumnTotalElapsedTime,
+			Desc: "执行时间(s)",
+		},
+		{
+			Name: mssql.DynExecQueryStatsColumnTotalWorkerTime,
+			Desc: "CPU消耗时间(s)",
+		},
+		{
+			Name: mssql.DynExecQueryStatsColumnTotalPhysicalReads,
+			Desc: "物理读",
+		},
+		{
+			Name: mssql.DynExecQueryStatsColumnTotalLogicalReads,
+			Desc: "逻辑读",
+		},
+		{
+			Name: mssql.DynExecQueryStatsColumnTotalLogicalWrites,
+			Desc: "逻辑写",
+		},
+	}
+	rows := make([]map[string]string, 0, len(auditPlanSQLs))
+	for _, sql := range auditPlanSQLs {
+		info := &mssql.DynExecQueryStats{}
+		if err := json.Unmarshal(sql.Info, info); err != nil {
+			return nil, nil, 0, err
+		}
+		rows = append(rows, map[string]string{
+			"sql": sql.SQLContent,
+			mssql.DynExecQueryStatsColumnExecutionCount:     strconv.FormatInt(info.ExecutionCount, 10),
+			mssql.DynExecQueryStatsColumnTotalElapsedTime:   fmt.Sprintf("%v", utils.Round(float64(info.TotalElapsedTime)/1000/1000, 3)),
+			mssql.DynExecQueryStatsColumnTotalWorkerTime:    fmt.Sprintf("%v", utils.Round(float64(info.TotalWorkerTime)/1000/1000, 3)),
+			mssql.DynExecQueryStatsColumnTotalPhysicalReads: strconv.FormatInt(info.TotalPhysicalReads, 10),
+			mssql.DynExecQueryStatsColumnTotalLogicalReads:  strconv.FormatInt(info.TotalLogicalReads, 10),
+			mssql.DynExecQueryStatsColumnTotalLogicalWrites: strconv.FormatInt(info.TotalLogicalWrites, 10),
+		})
+	}
+	return heads, rows, count, nil
+}
@@ -0,0 +1,176 @@
+package auditplan
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/log"
+	"github.com/actiontech/sqle/sqle/model"
+	"github.com/actiontech/sqle/sqle/pkg/git"
+	"github.com/actiontech/sqle/sqle/server"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/agiledragon/gomonkey"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchFilePatterns(t *testing.T) {
+	patterns := []string{"*.sql", " db/mapper/*.xml"}
+	assert.True(t, matchFilePatterns("V1__init.sql", patterns))
+	assert.True(t, matchFilePatterns("db/migration/V1__init.sql", patterns))
+	assert.True(t, matchFilePatterns("db/mapper/user.xml", patterns))
+	assert.False(t, matchFilePatterns("src/user.xml", patterns))
+	assert.False(t, matchFilePatterns("README.md", patterns))
+}
+
+func TestGitRepositoryTask_collectChangedSQLs(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git command is not found")
+	}
+	dir, err := ioutil.TempDir("", "sqle_git_audit_plan_test")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer os.RemoveAll(dir)
+
+	runGit := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=tester", "GIT_AUTHOR_EMAIL=tester@example.com",
+			"GIT_COMMITTER_NAME=tester", "GIT_COMMITTER_EMAIL=tester@example.com")
+		out, err := cmd.CombinedOutput()
+		if !assert.NoError(t, err, string(out)) {
+			t.FailNow()
+		}
+		return string(out)
+	}
+	commit := func(files map[string]string) string {
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+		}
+		runGit("add", "-A")
+		runGit("commit", "-q", "-m", "test")
+		return runGit("rev-parse", "HEAD")[:40]
+	}
+	runGit("init", "-q")
+
+	first := commit(map[string]string{
+		"db/V1__init.sql": "-- init\nCREATE TABLE t1 (id int);\n\nINSERT INTO t1 VALUES (1);\n",
+		"README.md":       "select 1;",
+	})
+	second := commit(map[string]string{
+		"db/V1__init.sql": "-- init\nCREATE TABLE t1 (id int);\n\nINSERT INTO t1 VALUES (2);\n",
+		"mapper/user.xml": `<mapper namespace="user">
+	<select id="get">SELECT * FROM t1 WHERE id = #{id}</select>
+</mapper>`,
+	})
+
+	ap := &model.AuditPlan{Name: "test", Type: TypeMySQLGitRepo}
+	meta, err := GetMeta(TypeMySQLGitRepo)
+	assert.NoError(t, err)
+	ap.Params = meta.Params
+	assert.NoError(t, ap.Params.SetParamValue(paramKeyRepositoryPath, dir))
+	task := NewGitRepositoryTask(log.NewEntry(), ap)
+
+	repo, err := git.NewRepository(dir)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	sqls, failedFiles, err := task.collectChangedSQLs(repo, "", first)
+	assert.NoError(t, err)
+	assert.Len(t, failedFiles, 0)
+	if assert.Len(t, sqls, 2) {
+		assert.Equal(t, "CREATE TABLE t1 (id int);", sqls[0].SQLContent)
+		assert.Equal(t, "db/V1__init.sql", sqls[0].Info[InfoKeyFilePath])
+		assert.Equal(t, 2, sqls[0].Info[InfoKeyLine])
+		assert.Equal(t, first, sqls[0].Info[InfoKeyCommit])
+		assert.Equal(t, "tester <tester@example.com>", sqls[0].Info[InfoKeyAuthor])
+	}
+
+	sqls, failedFiles, err = task.collectChangedSQLs(repo, first, second)
+	assert.NoError(t, err)
+	assert.Len(t, failedFiles, 0)
+	if assert.Len(t, sqls, 2) {
+		assert.Equal(t, "INSERT INTO t1 VALUES (2);", sqls[0].SQLContent)
+		assert.Equal(t, 4, sqls[0].Info[InfoKeyLine])
+		assert.Equal(t, "mapper/user.xml", sqls[1].Info[InfoKeyFilePath])
+		assert.Equal(t, 2, sqls[1].Info[InfoKeyLine])
+		assert.Equal(t, second, sqls[1].Info[InfoKeyCommit])
+	}
+
+	// the file which fails to read is returned.
+	third := commit(map[string]string{
+		"db/V2__add.sql": "INSERT INTO t1 VALUES (3);\n",
+		"mapper/bad.xml": "<mapper namespace=\"bad\"><select id=\"get\">",
+	})
+	sqls, failedFiles, err = task.collectChangedSQLs(repo, second, third)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"mapper/bad.xml"}, failedFiles)
+	if assert.Len(t, sqls, 1) {
+		assert.Equal(t, "INSERT INTO t1 VALUES (3);", sqls[0].SQLContent)
+	}
+}
+
+func TestMySQLDigestTask_Audit(t *testing.T) {
+	ap := &model.AuditPlan{Name: "test", Type: TypeMySQLDigest, InstanceDatabase: "db0"}
+
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(&model.Storage{}), "GetAuditPlanSQLs",
+		func(_ *model.Storage, _ string) ([]*model.AuditPlanSQLV2, error) {
+			return []*model.AuditPlanSQLV2{
+				{SQLContent: "select * from t1", Info: []byte(`{"schema_name": "db1"}`)},
+				{SQLContent: "select * from t2", Info: []byte(`{"schema_name": "db2"}`)},
+				{SQLContent: "select * from t3", Info: []byte(`{"schema_name": "db1"}`)},
+				{SQLContent: "select * from t4 where id = ?", Info: []byte(`{"schema_name": "db1", "digest_text_only": true}`)},
+				{SQLContent: "select 1", Info: []byte(`{"schema_name": ""}`)},
+			}, nil
+		})
+	defer patches.Reset()
+	mockDB, mock, err := sqlmock.New()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	model.InitMockStorage(mockDB)
+	defer mockDB.Close()
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO `audit_plan_reports_v2`").WillReturnResult(sqlmock.NewResult(1, 1))
+	for i := 0; i < 4; i++ {
+		mock.ExpectExec("INSERT INTO `audit_plan_report_sqls_v2`").WillReturnResult(sqlmock.NewResult(int64(i+1), 1))
+	}
+	mock.ExpectCommit()
+	schemaToSQLs := map[string][]string{}
+	patches.ApplyFunc(server.Audit, func(_ *logrus.Entry, task *model.Task) error {
+		for _, sql := range task.ExecuteSQLs {
+			schemaToSQLs[task.Schema] = append(schemaToSQLs[task.Schema], sql.Content)
+			if task.Schema == "db2" {
+				sql.AuditLevel = string(driver.RuleLevelError)
+			} else {
+				sql.AuditLevel = string(driver.RuleLevelNormal)
+			}
+		}
+		return nil
+	})
+
+	report, err := NewMySQLDigestTask(log.NewEntry(), ap).Audit()
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, map[string][]string{
+		"db0": {"select 1"},
+		"db1": {"select * from t1", "select * from t3"},
+		"db2": {"select * from t2"},
+	}, schemaToSQLs)
+	if assert.Len(t, report.AuditPlanReportSQLs, 4) {
+		assert.Equal(t, "select * from t2", report.AuditPlanReportSQLs[1].SQL)
+	}
+	assert.Equal(t, string(driver.RuleLevelError), report.AuditLevel)
+	assert.Equal(t, 0.75, report.PassRate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/actiontech/sqle/sqle/driver"
+	"github.com/actiontech/sqle/sqle/model"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ruleExemptionMatcher matches the findings of task against the unexpired
+// rule exemptions whose instance scope is matched by the task.
+type ruleExemptionMatcher struct {
+	schema     string
+	exemptions []*ruleExemption
+}
+
+type ruleExemption struct {
+	*model.RuleExemption
+	fingerprint string
+	schemaReg   *regexp.Regexp
+	tableReg    *regexp.Regexp
+}
+
+// identifierQuotes are the quotes of identifier in MySQL, PostgreSQL, Oracle
+// and SQL Server.
+const identifierQuotes = "`\"\\["
+
+// unqualifiedTableReg matches the table name without schema, which is in the
+// default schema of task.
+var unqualifiedTableReg = regexp.MustCompile("(?i)(^|[^\\w$])(from|join|update|into|table|references)\\s+" +
+	"(if\\s+(not\\s+)?exists\\s+)?(`[^`]+`|\"[^\"]+\"|\\[[^\\]]+\\]|[\\w$]+)($|[^\\w$.])")
+
+func newRuleExemptionMatcher(l *logrus.Entry, task *model.Task, d driver.Driver,
+	exemptions []*model.RuleExemption) *ruleExemptionMatcher {
+	m := &ruleExemptionMatcher{schema: task.Schema}
+	for _, e := range exemptions {
+		if e.InstanceName != "" && e.InstanceName != task.InstanceName() {
+			continue
+		}
+		re := &ruleExemption{RuleExemption: e}
+		if e.FingerprintSQL != "" {
+			// the exemption may be created for the SQL of other db type, it
+			// is skipped if the SQL can not be parsed by the driver of task.
+			node, err := parse(l, d, e.FingerprintSQL)
+			if err != nil {
+				l.Warnf("skip rule exemption %d, %v", e.ID, err)
+				continue
+			}
+			re.fingerprint = nodeFingerprint(node)
+		}
+		if e.Schema != "" {
+			re.schemaReg = regexp.MustCompile(fmt.Sprintf(`(?i)(^|[^\w$])[%s]?%s[%s]?\.`,
+				identifierQuotes, regexp.QuoteMeta(e.Schema), strings.Replace(identifierQuotes, "[", "]", 1)))
+		}
+		if e.Table != "" {
+			// the table is matched after the keywords which are followed by
+			// table name, so the columns and aliases with the same name are
+			// not matched, such as "select id from t1" for table "id".
+			re.tableReg = regexp.MustCompile(fmt.Sprintf(
+				`(?i)(^|[^\w$])(from|join|update|into|table|references)\s+(if\s+(not\s+)?exists\s+)?`+
+					`([%[1]s]?[\w$]+[%[2]s]?\.)?[%[1]s]?%[3]s[%[2]s]?($|[^\w$])`,
+				identifierQuotes, strings.Replace(identifierQuotes, "[", "]", 1), regexp.QuoteMeta(e.Table)))
+		}
+		m.exemptions = append(m.exemptions, re)
+	}
+	return m
+}
+
+// match returns the first exemption of the rule whose scope contains the SQL,
+// it returns nil if the finding is not exempted.
+func (m *ruleExemptionMatcher) match(ruleName string, node driver.Node) *model.RuleExemption {
+	if ruleName == "" {
+		return nil
+	}
+	// the literals of fingerprint are replaced, so the table name in string
+	// literal is not matched.
+	text := nodeFingerprint(node)
+	for _, e := range m.exemptions {
+		if !e.HasRule(ruleName) {
+			continue
+		}
+		if e.FingerprintSQL != "" && e.fingerprint != text {
+			continue
+		}
+		if e.schemaReg != nil && !e.schemaReg.MatchString(text) &&
+			!(strings.EqualFold(e.Schema, m.schema) && unqualifiedTableReg.MatchString(text)) {
+			continue
+		}
+		if e.tableReg != nil && !e.tableReg.MatchString(text) {
+			continue
+		}
+		return e.RuleExemption
+	}
+	return nil
+}
+
+// nodeFingerprint returns the fingerprint of node, it is the SQL text if the
+// driver does not support fingerprint.
+func nodeFingerprint(node driver.Node) string {
+	if node.Fingerprint == "" {
+		return node.Text
+	}
+	return node.Fingerprint
+}
+
+// apply splits the findings of SQL to the effective findings and the exempted
+// findings, the exempted findings do not affect the audit level of SQL.
+func (m *ruleExemptionMatcher) apply(result *driver.AuditResult, node driver.Node) (
+	effective *driver.AuditResult, exempted map[*driver.AuditResultItem]*model.RuleExemption) {
+	effective = driver.NewInspectResults()
+	exempted = map[*driver.AuditResultItem]*model.RuleExemption{}
+	for _, item := range result.Results() {
+		if e := m.match(item.RuleName, node); e != nil {
+			exempted[item] = e
+			continue
+		}
+		effective.AddResult(item)
+	}
+	return effective, exempted
+}
+
+// auditResultMessage returns the audit result text of SQL, the exempted
+// findings of result are appended to the effective findings with the prefix
+// "[exempted]" instead of the level, so they are not counted as the level.
+func auditResultMessage(result, effective *driver.AuditResult,
+	exempted map[*driver.AuditResultItem]*model.RuleExemption) string {
+	messages := []string{}
+	if message := effective.Message(); message != "" {
+		messages = append(messages, message)
+	}
+	for _, item := range result.Results() {
+		if _, ok := exempted[item]; ok {
+			messages = append(messages, fmt.Sprintf("[exempted]%s", item.Message))
+		}
+	}
+	return strings.Join(messages, "\n")
+}
@@ -2,13 +2,17 @@ package server
 
 import (
 	"context"
+	_driver "database/sql/driver"
 	_errors "errors"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/actiontech/sqle/sqle/driver"
 	_ "github.com/actiontech/sqle/sqle/driver/mysql"
+	_ "github.com/actiontech/sqle/sqle/driver/postgresql"
 	"github.com/actiontech/sqle/sqle/errors"
 	"github.com/actiontech/sqle/sqle/log"
 	"github.com/actiontech/sqle/sqle/model"
@@ -30,9 +34,9 @@ type Sqled struct {
 	sync.Mutex
 	// exit is Sqled service exit signal.
 	exit chan struct{}
-	// currentTask record the current task before execution,
+	// currentTask record the action of current task before execution,
 	// and delete it after execution.
-	currentTask map[string]struct{}
+	currentTask map[string]*action
 	// queue is a chan used to receive tasks.
 	queue chan *action
 }
@@ -40,7 +44,7 @@ type Sqled struct {
 func InitSqled(exit chan struct{}) {
 	sqled = &Sqled{
 		exit:        exit,
-		currentTask: map[string]struct{}{},
+		currentTask: map[string]*action{},
 		queue:       make(chan *action, 1024),
 	}
 	sqled.Start()
@@ -56,19 +60,18 @@ func (s *Sqled) HasTask(taskId string) bool {
 // addTask receive taskId and action type, using taskId and typ to create an action;
 // action will be validated, and sent to Sqled.queue.
 func (s *Sqled) addTask(taskId string, typ int) (*action, error) {
+	return s.addAction(taskId, newAction(taskId, typ))
+}
+
+func (s *Sqled) addAction(taskId string, action *action) (*action, error) {
 	var err error
 	var d driver.Driver
-	entry := log.NewEntry().WithField("task_id", taskId)
-	action := &action{
-		typ:   typ,
-		entry: entry,
-		done:  make(chan struct{}),
-	}
+	entry := action.entry
 
 	s.Lock()
 	_, taskRunning := s.currentTask[taskId]
 	if !taskRunning {
-		s.currentTask[taskId] = struct{}{}
+		s.currentTask[taskId] = action
 	}
 	s.Unlock()
 	if taskRunning {
@@ -119,6 +122,54 @@ func (s *Sqled) AddTaskWaitResult(taskId string, typ int) (*model.Task, error) {
 	return action.task, action.err
 }
 
+// RollbackTaskWaitResult rolls back the execute SQLs of task which are specified
+// by sqlNumbers, all of the execute SQLs are rolled back if sqlNumbers is empty.
+func (s *Sqled) RollbackTaskWaitResult(taskId string, sqlNumbers []uint) (*model.Task, error) {
+	a := newAction(taskId, ActionTypeRollback)
+	a.rollbackSQLNumbers = sqlNumbers
+	action, err := s.addAction(taskId, a)
+	if err != nil {
+		return nil, err
+	}
+	<-action.done
+	return action.task, action.err
+}
+
+var (
+	ErrTaskNotRunning           = _errors.New("task is not running, can not cancel it")
+	ErrActionCancelNotSupported = _errors.New("audit can not be canceled")
+	ErrActionCanceled           = _errors.New("action is canceled")
+)
+
+// CancelTask cancels the running action of task. The running SQL is killed, and
+// the remaining SQLs are skipped.
+func (s *Sqled) CancelTask(taskId string) error {
+	s.Lock()
+	action, ok := s.currentTask[taskId]
+	s.Unlock()
+	if !ok {
+		return errors.New(errors.TaskActionInvalid, ErrTaskNotRunning)
+	}
+	if action.typ == ActionTypeAudit {
+		return errors.New(errors.TaskActionInvalid, ErrActionCancelNotSupported)
+	}
+	action.entry.Info("cancel the action of task")
+	action.cancel()
+	return nil
+}
+
+// GetTaskProgress returns the progress of the running action of task, it returns
+// false if the task is not running.
+func (s *Sqled) GetTaskProgress(taskId string) (*TaskProgress, bool) {
+	s.Lock()
+	action, ok := s.currentTask[taskId]
+	s.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return action.getProgress(), true
+}
+
 func (s *Sqled) Start() {
 	go s.taskLoop()
 	go s.cleanLoop()
@@ -150,12 +201,15 @@ func (s *Sqled) do(action *action) error {
 		err = action.execute()
 	case ActionTypeRollback:
 		err = action.rollback()
+	case ActionTypeDryRun:
+		err = action.dryRun()
 	}
 	if err != nil {
 		action.err = err
 	}
 
 	action.driver.Close(context.TODO())
+	action.cancel()
 
 	s.Lock()
 	taskId := fmt.Sprintf("%d", action.task.ID)
@@ -173,6 +227,7 @@ const (
 	ActionTypeAudit = iota + 1
 	ActionTypeExecute
 	ActionTypeRollback
+	ActionTypeDryRun
 )
 
 // Action is an action for the task;
@@ -180,6 +235,14 @@ const (
 type action struct {
 	sync.Mutex
 
+	// ctx is canceled when the action is canceled or done, the running SQL is
+	// killed on canceling.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// progress is protected by the mutex of action.
+	progress TaskProgress
+
 	// driver is interface which communicate with specify instance.
 	driver driver.Driver
 
@@ -190,6 +253,101 @@ type action struct {
 	typ  int
 	err  error
 	done chan struct{}
+
+	// rollbackSQLNumbers is the numbers of execute SQL to be rolled back,
+	// it is only used by rollback action.
+	rollbackSQLNumbers []uint
+}
+
+func newAction(taskId string, typ int) *action {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &action{
+		ctx:    ctx,
+		cancel: cancel,
+		typ:    typ,
+		entry:  log.NewEntry().WithField("task_id", taskId),
+		done:   make(chan struct{}),
+		progress: TaskProgress{
+			ActionType: typ,
+		},
+	}
+}
+
+// TaskProgress is the progress of the running action of task.
+type TaskProgress struct {
+	ActionType int
+	// StartAt is zero if the action is waiting in queue.
+	StartAt   time.Time
+	Canceled  bool
+	TotalSQLs int
+	// FinishedSQLs counts the SQLs which are succeeded, failed or skipped.
+	FinishedSQLs int
+	RowAffects   int64
+
+	// CurrentSQLs is the running SQLs, the adjacent DMLs are executed together
+	// in one transaction.
+	CurrentSQLNumbers []uint
+	CurrentSQL        string
+	CurrentSQLStartAt time.Time
+
+	// Rollback is the progress of rolling back the executed SQLs after the
+	// execution failed, it is nil if the executed SQLs are not rolled back.
+	Rollback *TaskProgress
+}
+
+func (p *TaskProgress) clone(canceled bool) *TaskProgress {
+	c := *p
+	c.CurrentSQLNumbers = append([]uint{}, p.CurrentSQLNumbers...)
+	c.Canceled = canceled
+	if p.Rollback != nil {
+		c.Rollback = p.Rollback.clone(canceled)
+	}
+	return &c
+}
+
+func (a *action) getProgress() *TaskProgress {
+	a.Lock()
+	defer a.Unlock()
+	return a.progress.clone(a.ctx.Err() != nil)
+}
+
+// currentProgress returns the progress updated by the running SQLs, it is the
+// rollback progress while the executed SQLs are rolled back after the
+// execution failed, so the progress of execution is kept.
+func (a *action) currentProgress() *TaskProgress {
+	if a.progress.Rollback != nil {
+		return a.progress.Rollback
+	}
+	return &a.progress
+}
+
+func (a *action) startProgress(totalSQLs int) {
+	a.Lock()
+	p := a.currentProgress()
+	p.StartAt = time.Now()
+	p.TotalSQLs = totalSQLs
+	p.FinishedSQLs = 0
+	p.RowAffects = 0
+	a.Unlock()
+}
+
+func (a *action) startSQLs(numbers []uint, contents []string) {
+	a.Lock()
+	p := a.currentProgress()
+	p.CurrentSQLNumbers = numbers
+	p.CurrentSQL = strings.Join(contents, "\n")
+	p.CurrentSQLStartAt = time.Now()
+	a.Unlock()
+}
+
+func (a *action) finishSQLs(count int, rowAffects int64) {
+	a.Lock()
+	p := a.currentProgress()
+	p.FinishedSQLs += count
+	p.RowAffects += rowAffects
+	p.CurrentSQLNumbers = nil
+	p.CurrentSQL = ""
+	a.Unlock()
 }
 
 var (
@@ -198,6 +356,13 @@ var (
 	ErrActionRollbackOnRollbackedTask    = _errors.New("task has been rollbacked, can not do rollback on it")
 	ErrActionRollbackOnExecuteFailedTask = _errors.New("task has been executed failed, can not do rollback on it")
 	ErrActionRollbackOnNonExecutedTask   = _errors.New("task has not been executed, can not do rollback on it")
+	ErrActionRollbackNotSupported        = _errors.New("the driver of task does not support rollback, can not do rollback on it")
+	ErrActionRollbackOnNonExecutedSQL    = _errors.New("SQL has not been executed successfully, can not do rollback on it")
+	ErrActionRollbackOnRollbackedSQL     = _errors.New("SQL has been rollbacked, can not do rollback on it")
+	ErrActionRollbackSQLNotExist         = _errors.New("SQL has no rollback SQL, can not do rollback on it")
+	ErrActionDryRunOnExecutedTask        = _errors.New("task has been executed, can not do dry run on it")
+	ErrActionDryRunOnNonAuditedTask      = _errors.New("task has not been audited, can not do dry run on it")
+	ErrActionDryRunNotSupported          = _errors.New("the driver of task does not support dry run, can not do dry run on it")
 )
 
 // validation validate whether task can do action type(a.typ) or not.
@@ -214,6 +379,9 @@ func (a *action) validation(task *model.Task) error {
 			return errors.New(errors.TaskActionInvalid, ErrActionExecuteOnNonAuditedTask)
 		}
 	case ActionTypeRollback:
+		if len(a.rollbackSQLNumbers) > 0 {
+			return a.validateRollbackSQLs(task)
+		}
 		if task.HasDoingRollback() {
 			return errors.New(errors.TaskActionDone, ErrActionRollbackOnRollbackedTask)
 		}
@@ -223,6 +391,53 @@ func (a *action) validation(task *model.Task) error {
 		if !task.HasDoingExecute() {
 			return errors.New(errors.TaskActionInvalid, ErrActionRollbackOnNonExecutedTask)
 		}
+		if !driver.HasCapability(task.DBType, driver.CapabilityRollback) {
+			return errors.New(errors.TaskActionInvalid, ErrActionRollbackNotSupported)
+		}
+	case ActionTypeDryRun:
+		if task.HasDoingExecute() {
+			return errors.New(errors.TaskActionDone, ErrActionDryRunOnExecutedTask)
+		}
+		if !task.HasDoingAudit() {
+			return errors.New(errors.TaskActionInvalid, ErrActionDryRunOnNonAuditedTask)
+		}
+		if !driver.HasCapability(task.DBType, driver.CapabilityDryRun) {
+			return errors.New(errors.TaskActionInvalid, ErrActionDryRunNotSupported)
+		}
+	}
+	return nil
+}
+
+// validateRollbackSQLs validates the execute SQLs selected to roll back. Unlike
+// rolling back the whole task, the succeeded SQLs of an execute failed task are
+// allowed to be rolled back.
+func (a *action) validateRollbackSQLs(task *model.Task) error {
+	if !driver.HasCapability(task.DBType, driver.CapabilityRollback) {
+		return errors.New(errors.TaskActionInvalid, ErrActionRollbackNotSupported)
+	}
+	executeSQLs := map[uint]*model.ExecuteSQL{}
+	for _, executeSQL := range task.ExecuteSQLs {
+		executeSQLs[executeSQL.Number] = executeSQL
+	}
+	rollbackSQLs := map[uint]*model.RollbackSQL{}
+	for _, rollbackSQL := range task.RollbackSQLs {
+		rollbackSQLs[rollbackSQL.ExecuteSQLId] = rollbackSQL
+	}
+	for _, number := range a.rollbackSQLNumbers {
+		executeSQL, ok := executeSQLs[number]
+		if !ok {
+			return errors.New(errors.DataNotExist, fmt.Errorf("SQL number %d is not exist in task", number))
+		}
+		if executeSQL.ExecStatus != model.SQLExecuteStatusSucceeded {
+			return errors.New(errors.TaskActionInvalid, ErrActionRollbackOnNonExecutedSQL)
+		}
+		rollbackSQL, ok := rollbackSQLs[executeSQL.ID]
+		if !ok || rollbackSQL.Content == "" {
+			return errors.New(errors.TaskActionInvalid, ErrActionRollbackSQLNotExist)
+		}
+		if rollbackSQL.ExecStatus != model.SQLExecuteStatusInitialized {
+			return errors.New(errors.TaskActionDone, ErrActionRollbackOnRollbackedSQL)
+		}
 	}
 	return nil
 }
@@ -235,15 +450,18 @@ func (a *action) audit() (err error) {
 		return err
 	}
 
-	// skip generate if audit is static
-	if a.task.SQLSource == model.TaskSQLSourceFromMyBatisXMLFile || a.task.InstanceId == 0 {
+	// skip generate if audit is static or driver can not generate rollback SQL
+	if a.task.SQLSource == model.TaskSQLSourceFromMyBatisXMLFile || a.task.InstanceId == 0 ||
+		!driver.HasCapability(a.task.DBType, driver.CapabilityRollback) {
 		a.entry.Warn("skip generate rollback SQLs")
 	} else {
-		d, err := newDriverWithAudit(a.entry, a.task.Instance, a.task.Schema, a.task.DBType)
+		d, err := a.newDriverForRollback()
 		if err != nil {
 			return xerrors.Wrap(err, "new driver for generate rollback SQL")
 		}
-		defer d.Close(context.TODO())
+		if d != a.driver {
+			defer d.Close(context.TODO())
+		}
 
 		rollbackSQLs, err := genRollbackSQL(a.entry, a.task, d)
 		if err != nil {
@@ -261,6 +479,11 @@ func (a *action) audit() (err error) {
 		return err
 	}
 
+	if err = st.UpdateExecuteSQLAuditResults(a.task.ExecuteSQLs); err != nil {
+		a.entry.Errorf("save SQL audit results error:%v", err)
+		return err
+	}
+
 	if err = st.UpdateTask(a.task, map[string]interface{}{
 		"pass_rate":   a.task.PassRate,
 		"audit_level": a.task.AuditLevel,
@@ -273,6 +496,24 @@ func (a *action) audit() (err error) {
 	return nil
 }
 
+// newDriverForRollback returns a driver with empty SQL context to generate
+// rollback SQL. The task driver is reset and reused if possible, so the task
+// does not hold another driver of the pool.
+func (a *action) newDriverForRollback() (driver.Driver, error) {
+	if r, ok := a.driver.(driver.Resetter); ok {
+		cfg, err := newDriverConfigWithAudit(a.task.Instance, a.task.Schema, a.task.DBType)
+		if err != nil {
+			return nil, err
+		}
+		err = r.Reset(context.TODO(), cfg.Rules)
+		if err == nil {
+			return a.driver, nil
+		}
+		a.entry.Warnf("reset driver error: %v, new a driver instead", err)
+	}
+	return newDriverWithAudit(a.entry, a.task.Instance, a.task.Schema, a.task.DBType)
+}
+
 func (a *action) execute() (err error) {
 	st := model.GetStorage()
 	task := a.task
@@ -287,11 +528,16 @@ func (a *action) execute() (err error) {
 		return err
 	}
 
+	a.startProgress(len(task.ExecuteSQLs))
+
 	// txSQLs keep adjacent DMLs, execute in one transaction.
 	var txSQLs []*model.ExecuteSQL
 
 outerLoop:
 	for i, executeSQL := range task.ExecuteSQLs {
+		if a.shouldStopExecution() {
+			break outerLoop
+		}
 		var nodes []driver.Node
 		if nodes, err = a.driver.Parse(context.TODO(), executeSQL.Content); err != nil {
 			break outerLoop
@@ -313,6 +559,9 @@ outerLoop:
 					break outerLoop
 				}
 				txSQLs = nil
+				if a.shouldStopExecution() {
+					break outerLoop
+				}
 			}
 			if err = a.execSQL(executeSQL); err != nil {
 				break outerLoop
@@ -326,19 +575,21 @@ outerLoop:
 
 	taskStatus := model.TaskStatusExecuteSucceeded
 
-	if err != nil {
+	canceled := a.ctx.Err() != nil
+	switch {
+	case err != nil:
+	case canceled:
+		_, err = a.skipRemainingSQLs("skipped since the execution is canceled")
+	case task.IsExecuteFailed():
+		err = a.handleExecFailure()
+	}
+
+	if err != nil || canceled || task.IsExecuteFailed() {
 		taskStatus = model.TaskStatusExecuteFailed
-	} else {
-		for _, sql := range task.ExecuteSQLs {
-			if sql.ExecStatus == model.SQLExecuteStatusFailed {
-				taskStatus = model.TaskStatusExecuteFailed
-				break
-			}
-		}
 	}
 	task.Status = taskStatus
 
-	a.entry.WithField("task_status", taskStatus).Infof("execution is completed, err:%v", err)
+	a.entry.WithField("task_status", taskStatus).Infof("execution is completed, canceled:%v, err:%v", canceled, err)
 
 	attrs = map[string]interface{}{
 		"status":      taskStatus,
@@ -355,24 +606,139 @@ func (a *action) execSQL(executeSQL *model.ExecuteSQL) error {
 		return err
 	}
 
-	_, err := a.driver.Exec(context.TODO(), executeSQL.Content)
+	a.startSQLs([]uint{executeSQL.Number}, []string{executeSQL.Content})
+	result, err := a.driver.Exec(a.ctx, executeSQL.Content)
 	if err != nil {
 		executeSQL.ExecStatus = model.SQLExecuteStatusFailed
 		executeSQL.ExecResult = err.Error()
 	} else {
+		if result != nil {
+			executeSQL.RowAffects, _ = result.RowsAffected()
+		}
 		executeSQL.ExecStatus = model.SQLExecuteStatusSucceeded
 		executeSQL.ExecResult = model.TaskExecResultOK
 	}
+	a.finishSQLs(1, executeSQL.RowAffects)
 	if err := st.Save(executeSQL); err != nil {
 		return err
 	}
 	return nil
 }
 
+// shouldStopExecution returns true if the execution is canceled, or a SQL failed
+// and the failure strategy of task does not continue the execution.
+func (a *action) shouldStopExecution() bool {
+	if a.ctx.Err() != nil {
+		return true
+	}
+	return a.task.ExecFailureStrategy != model.ExecFailureStrategyContinue && a.task.IsExecuteFailed()
+}
+
+// handleExecFailure handles the failed SQLs by the failure strategy of task, and
+// records the result on task.
+func (a *action) handleExecFailure() error {
+	task := a.task
+
+	var result string
+	switch task.ExecFailureStrategy {
+	case model.ExecFailureStrategyContinue:
+		failed := 0
+		for _, executeSQL := range task.ExecuteSQLs {
+			if executeSQL.ExecStatus == model.SQLExecuteStatusFailed {
+				failed++
+			}
+		}
+		result = fmt.Sprintf("%d SQLs failed, the other SQLs are executed", failed)
+	default:
+		skipped, err := a.skipRemainingSQLs("skipped since the previous SQL failed")
+		if err != nil {
+			return err
+		}
+		result = fmt.Sprintf("execution is stopped at the failed SQL, %d SQLs are skipped", skipped)
+		if task.ExecFailureStrategy == model.ExecFailureStrategyRollbackExecuted {
+			result = fmt.Sprintf("%s; %s", result, a.rollbackExecuted())
+		}
+	}
+
+	a.entry.Infof("execution failure is handled by strategy %s: %s", task.ExecFailureStrategy, result)
+	task.ExecFailureResult = result
+	return model.GetStorage().UpdateTask(task, map[string]interface{}{
+		"exec_failure_result": result,
+	})
+}
+
+// rollbackExecuted executes the rollback SQLs of the succeeded SQLs in reverse
+// order, it returns the result description. The executed SQL without rollback
+// SQL is not counted as rolled back.
+func (a *action) rollbackExecuted() string {
+	var numbers []uint
+	executed := map[uint]struct{}{}
+	for _, executeSQL := range a.task.ExecuteSQLs {
+		if executeSQL.ExecStatus == model.SQLExecuteStatusSucceeded {
+			numbers = append(numbers, executeSQL.Number)
+			executed[executeSQL.ID] = struct{}{}
+		}
+	}
+	if len(numbers) == 0 {
+		return "no executed SQL needs to be rolled back"
+	}
+
+	a.rollbackSQLNumbers = numbers
+	a.Lock()
+	a.progress.Rollback = &TaskProgress{ActionType: ActionTypeRollback}
+	a.Unlock()
+	err := a.rollback()
+	rolledBack := 0
+	for _, rollbackSQL := range a.task.RollbackSQLs {
+		if _, ok := executed[rollbackSQL.ExecuteSQLId]; ok && rollbackSQL.ExecStatus == model.SQLExecuteStatusSucceeded {
+			rolledBack++
+		}
+	}
+	if err != nil {
+		return fmt.Sprintf("%d of the %d executed SQLs are rolled back, rollback failed: %v", rolledBack, len(numbers), err)
+	}
+	if rolledBack < len(numbers) {
+		return fmt.Sprintf("%d of the %d executed SQLs are rolled back, the others have no rollback SQL", rolledBack, len(numbers))
+	}
+	return fmt.Sprintf("the %d executed SQLs are rolled back", len(numbers))
+}
+
+// skipRemainingSQLs marks the SQLs not executed as skipped with the reason, it
+// returns the count of skipped SQLs.
+func (a *action) skipRemainingSQLs(reason string) (int, error) {
+	var skipped []*model.ExecuteSQL
+	for _, executeSQL := range a.task.ExecuteSQLs {
+		if executeSQL.ExecStatus != model.SQLExecuteStatusInitialized {
+			continue
+		}
+		executeSQL.ExecStatus = model.SQLExecuteStatusSkipped
+		executeSQL.ExecResult = reason
+		skipped = append(skipped, executeSQL)
+	}
+	if len(skipped) == 0 {
+		return 0, nil
+	}
+	a.finishSQLs(len(skipped), 0)
+	return len(skipped), model.GetStorage().UpdateExecuteSQLs(skipped)
+}
+
 // execSQLs execute SQLs and update SQLs' executed status to storage.
 func (a *action) execSQLs(executeSQLs []*model.ExecuteSQL) error {
 	st := model.GetStorage()
 
+	// execute SQLs one by one if driver does not support transaction.
+	if !driver.HasCapability(a.task.DBType, driver.CapabilityTx) {
+		for _, executeSQL := range executeSQLs {
+			if a.shouldStopExecution() {
+				return nil
+			}
+			if err := a.execSQL(executeSQL); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
 	for _, executeSQL := range executeSQLs {
 		executeSQL.ExecStatus = model.SQLExecuteStatusDoing
 	}
@@ -381,11 +747,15 @@ func (a *action) execSQLs(executeSQLs []*model.ExecuteSQL) error {
 	}
 
 	qs := make([]string, 0, len(executeSQLs))
+	numbers := make([]uint, 0, len(executeSQLs))
 	for _, executeSQL := range executeSQLs {
 		qs = append(qs, executeSQL.Content)
+		numbers = append(numbers, executeSQL.Number)
 	}
 
-	results, txErr := a.driver.Tx(context.TODO(), qs...)
+	a.startSQLs(numbers, qs)
+	results, txErr := a.driver.Tx(a.ctx, qs...)
+	var rowAffectsSum int64
 	for idx, executeSQL := range executeSQLs {
 		if txErr != nil {
 			executeSQL.ExecStatus = model.SQLExecuteStatusFailed
@@ -396,22 +766,160 @@ func (a *action) execSQLs(executeSQLs []*model.ExecuteSQL) error {
 		executeSQL.RowAffects = rowAffects
 		executeSQL.ExecStatus = model.SQLExecuteStatusSucceeded
 		executeSQL.ExecResult = model.TaskExecResultOK
+		rowAffectsSum += rowAffects
 	}
+	a.finishSQLs(len(executeSQLs), rowAffectsSum)
 
 	return st.UpdateExecuteSQLs(executeSQLs)
 }
 
+// dryRun runs the SQLs of task without changing the database, and records the
+// affected rows of each SQL. Adjacent DMLs are run in one transaction which is
+// rolled back, so the later DMLs see the changes of the former ones. Unlike
+// execute, dry run does not stop at the failed SQL.
+func (a *action) dryRun() (err error) {
+	st := model.GetStorage()
+	task := a.task
+
+	dr, ok := a.driver.(driver.DryRunner)
+	if !ok {
+		return errors.New(errors.TaskActionInvalid, ErrActionDryRunNotSupported)
+	}
+
+	a.entry.Info("start dry run...")
+	a.startProgress(len(task.ExecuteSQLs))
+
+	// txSQLs keep adjacent DMLs, dry run in one transaction.
+	var txSQLs []*model.ExecuteSQL
+	for i, executeSQL := range task.ExecuteSQLs {
+		if a.ctx.Err() != nil {
+			// the SQLs not run are skipped, including the DMLs kept in txSQLs.
+			setDryRunResult(executeSQL, nil, ErrActionCanceled)
+			continue
+		}
+		var nodes []driver.Node
+		if nodes, err = a.driver.Parse(context.TODO(), executeSQL.Content); err != nil {
+			return err
+		}
+
+		switch nodes[0].Type {
+		case driver.SQLTypeDML:
+			txSQLs = append(txSQLs, executeSQL)
+			if i == len(task.ExecuteSQLs)-1 {
+				a.dryRunSQLs(dr, txSQLs)
+			}
+		case driver.SQLTypeDDL:
+			if len(txSQLs) > 0 {
+				a.dryRunSQLs(dr, txSQLs)
+				txSQLs = nil
+			}
+			a.startSQLs([]uint{executeSQL.Number}, []string{executeSQL.Content})
+			result, execErr := dr.DryRunExec(a.ctx, executeSQL.Content)
+			setDryRunResult(executeSQL, result, execErr)
+			a.finishSQLs(1, executeSQL.DryRunRowAffects)
+		default:
+			return fmt.Errorf("unknown SQL type %v", nodes[0].Type)
+		}
+	}
+
+	if err = st.UpdateExecuteSQLs(task.ExecuteSQLs); err != nil {
+		return err
+	}
+
+	a.entry.Info("dry run is completed")
+
+	now := time.Now()
+	task.DryRunAt = &now
+	return st.UpdateTask(task, map[string]interface{}{
+		"dry_run_at": now,
+	})
+}
+
+func (a *action) dryRunSQLs(dr driver.DryRunner, executeSQLs []*model.ExecuteSQL) {
+	qs := make([]string, 0, len(executeSQLs))
+	numbers := make([]uint, 0, len(executeSQLs))
+	for _, executeSQL := range executeSQLs {
+		qs = append(qs, executeSQL.Content)
+		numbers = append(numbers, executeSQL.Number)
+	}
+
+	a.startSQLs(numbers, qs)
+	results, txErr := dr.DryRunTx(a.ctx, qs...)
+	var rowAffectsSum int64
+	for idx, executeSQL := range executeSQLs {
+		if txErr != nil {
+			setDryRunResult(executeSQL, nil, txErr)
+			continue
+		}
+		setDryRunResult(executeSQL, results[idx], nil)
+		rowAffectsSum += executeSQL.DryRunRowAffects
+	}
+	a.finishSQLs(len(executeSQLs), rowAffectsSum)
+}
+
+func setDryRunResult(executeSQL *model.ExecuteSQL, result _driver.Result, err error) {
+	executeSQL.DryRunRowAffects = 0
+	switch {
+	case _errors.Is(err, driver.ErrDryRunSkipped), _errors.Is(err, ErrActionCanceled):
+		executeSQL.DryRunStatus = model.SQLDryRunStatusSkipped
+		executeSQL.DryRunResult = err.Error()
+	case err != nil:
+		executeSQL.DryRunStatus = model.SQLDryRunStatusFailed
+		executeSQL.DryRunResult = err.Error()
+	default:
+		if result != nil {
+			executeSQL.DryRunRowAffects, _ = result.RowsAffected()
+		}
+		executeSQL.DryRunStatus = model.SQLDryRunStatusSucceeded
+		executeSQL.DryRunResult = model.TaskExecResultOK
+	}
+}
+
+// rollback executes the rollback SQLs in the reverse order of the execute SQLs,
+// it stops at the first failed rollback SQL since the rollback SQLs before it
+// may depend on it.
 func (a *action) rollback() (err error) {
 	task := a.task
 	a.entry.Info("start rollback SQL")
 
-	var execErr error
-	st := model.GetStorage()
-ExecSQLs:
+	selected := map[uint]struct{}{}
+	for _, number := range a.rollbackSQLNumbers {
+		selected[number] = struct{}{}
+	}
+	executeSQLNumbers := map[uint]uint{}
+	for _, executeSQL := range task.ExecuteSQLs {
+		executeSQLNumbers[executeSQL.ID] = executeSQL.Number
+	}
+	rollbackSQLs := make([]*model.RollbackSQL, 0, len(task.RollbackSQLs))
 	for _, rollbackSQL := range task.RollbackSQLs {
 		if rollbackSQL.Content == "" {
 			continue
 		}
+		if _, ok := selected[executeSQLNumbers[rollbackSQL.ExecuteSQLId]]; len(selected) > 0 && !ok {
+			continue
+		}
+		rollbackSQLs = append(rollbackSQLs, rollbackSQL)
+	}
+	sort.SliceStable(rollbackSQLs, func(i, j int) bool {
+		return executeSQLNumbers[rollbackSQLs[i].ExecuteSQLId] > executeSQLNumbers[rollbackSQLs[j].ExecuteSQLId]
+	})
+
+	a.startProgress(len(rollbackSQLs))
+
+	var execErr error
+	st := model.GetStorage()
+	for i, rollbackSQL := range rollbackSQLs {
+		if a.ctx.Err() != nil {
+			for _, skipped := range rollbackSQLs[i:] {
+				if err = st.UpdateRollbackSqlStatus(&skipped.BaseSQL, model.SQLExecuteStatusSkipped,
+					"skipped since the rollback is canceled"); err != nil {
+					return err
+				}
+			}
+			a.finishSQLs(len(rollbackSQLs)-i, 0)
+			execErr = ErrActionCanceled
+			break
+		}
 		if err = st.UpdateRollbackSqlStatus(&rollbackSQL.BaseSQL, model.SQLExecuteStatusDoing, ""); err != nil {
 			return err
 		}
@@ -421,29 +929,35 @@ ExecSQLs:
 			return err
 		}
 		// todo: execute in transaction
+		a.startSQLs([]uint{rollbackSQL.Number}, []string{rollbackSQL.Content})
+		var rowAffects int64
 		for _, node := range nodes {
-			currentSQL := model.RollbackSQL{BaseSQL: model.BaseSQL{
-				TaskId:  rollbackSQL.TaskId,
-				Content: node.Text,
-			}, ExecuteSQLId: rollbackSQL.ExecuteSQLId}
-			_, execErr := a.driver.Exec(context.TODO(), node.Text)
-			if execErr != nil {
-				currentSQL.ExecStatus = model.SQLExecuteStatusFailed
-				currentSQL.ExecResult = execErr.Error()
-			} else {
-				currentSQL.ExecStatus = model.SQLExecuteStatusSucceeded
-				currentSQL.ExecResult = model.TaskExecResultOK
+			var result _driver.Result
+			if result, execErr = a.driver.Exec(a.ctx, node.Text); execErr != nil {
+				break
 			}
-			if execErr := st.Save(currentSQL); execErr != nil {
-				break ExecSQLs
+			if result != nil {
+				n, _ := result.RowsAffected()
+				rowAffects += n
 			}
 		}
+		a.finishSQLs(1, rowAffects)
+		status, result := model.SQLExecuteStatusSucceeded, model.TaskExecResultOK
+		if execErr != nil {
+			status, result = model.SQLExecuteStatusFailed, execErr.Error()
+		}
+		if err = st.UpdateRollbackSqlStatus(&rollbackSQL.BaseSQL, status, result); err != nil {
+			return err
+		}
+		if execErr != nil {
+			break
+		}
 	}
 
 	if execErr != nil {
 		a.entry.Errorf("rollback SQL error:%v", execErr)
 	} else {
-		a.entry.Error("rollback SQL finished")
+		a.entry.Info("rollback SQL finished")
 	}
 	return execErr
 }
@@ -457,6 +971,15 @@ func newDriverWithAudit(l *logrus.Entry, inst *model.Instance, database string,
 		dbType = inst.DbType
 	}
 
+	cfg, err := newDriverConfigWithAudit(inst, database, dbType)
+	if err != nil {
+		return nil, err
+	}
+
+	return driver.NewDriver(l, dbType, cfg)
+}
+
+func newDriverConfigWithAudit(inst *model.Instance, database string, dbType string) (*driver.Config, error) {
 	st := model.GetStorage()
 
 	var err error
@@ -493,6 +1016,5 @@ func newDriverWithAudit(l *logrus.Entry, inst *model.Instance, database string,
 	if err != nil {
 		return nil, xerrors.Wrap(err, "new driver with audit")
 	}
-
-	return driver.NewDriver(l, dbType, cfg)
+	return cfg, nil
 }
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"reflect"
 	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -22,6 +23,7 @@ func getAction(sqls []string, typ int, d driver.Driver) *action {
 	task := &model.Task{
 		Model:     model.Model{ID: 1},
 		SQLSource: model.TaskSQLSourceFromMyBatisXMLFile,
+		DBType:    driver.DriverTypeMySQL,
 	}
 
 	for _, sql := range sqls {
@@ -30,14 +32,10 @@ func getAction(sqls []string, typ int, d driver.Driver) *action {
 		})
 	}
 
-	entry := log.NewEntry().WithField("task_id", task.ID)
-	return &action{
-		task:   task,
-		driver: d,
-		typ:    typ,
-		entry:  entry,
-		done:   make(chan struct{}),
-	}
+	a := newAction(fmt.Sprint(task.ID), typ)
+	a.task = task
+	a.driver = d
+	return a
 }
 
 type mockDriver struct {
@@ -74,6 +72,14 @@ func (d *mockDriver) Audit(ctx context.Context, sql string) (*driver.AuditResult
 	return nil, nil
 }
 
+func (d *mockDriver) AuditBatch(ctx context.Context, sqls []string) ([]*driver.AuditResult, error) {
+	results := make([]*driver.AuditResult, 0, len(sqls))
+	for range sqls {
+		results = append(results, driver.NewInspectResults())
+	}
+	return results, nil
+}
+
 func (d *mockDriver) GenRollbackSQL(ctx context.Context, sql string) (string, string, error) {
 	return "", "", nil
 }
@@ -128,6 +134,201 @@ func TestAction_validation(t *testing.T) {
 		{BaseSQL: model.BaseSQL{ExecStatus: model.SQLExecuteStatusInitialized}, AuditStatus: model.SQLAuditStatusFinished},
 	}}
 	assert.EqualError(t, actions[ActionTypeRollback].validation(noExecutedTask), ErrActionRollbackOnNonExecutedTask.Error())
+
+	notSupportRollbackTask := &model.Task{DBType: "not_support_rollback", ExecuteSQLs: []*model.ExecuteSQL{
+		{BaseSQL: model.BaseSQL{ExecStatus: model.SQLExecuteStatusSucceeded}, AuditStatus: model.SQLAuditStatusFinished},
+	}}
+	assert.EqualError(t, actions[ActionTypeRollback].validation(notSupportRollbackTask), ErrActionRollbackNotSupported.Error())
+
+	rollbackSQLsAction := &action{typ: ActionTypeRollback, rollbackSQLNumbers: []uint{1}}
+	partialExecutedTask := &model.Task{DBType: driver.DriverTypeMySQL,
+		ExecuteSQLs: []*model.ExecuteSQL{
+			{BaseSQL: model.BaseSQL{Model: model.Model{ID: 1}, Number: 1, ExecStatus: model.SQLExecuteStatusSucceeded}},
+			{BaseSQL: model.BaseSQL{Model: model.Model{ID: 2}, Number: 2, ExecStatus: model.SQLExecuteStatusFailed}},
+		},
+		RollbackSQLs: []*model.RollbackSQL{
+			{BaseSQL: model.BaseSQL{Content: "drop table t1", ExecStatus: model.SQLExecuteStatusInitialized}, ExecuteSQLId: 1},
+			{BaseSQL: model.BaseSQL{Content: "drop table t2", ExecStatus: model.SQLExecuteStatusInitialized}, ExecuteSQLId: 2},
+		},
+	}
+	assert.Nil(t, rollbackSQLsAction.validation(partialExecutedTask))
+
+	rollbackSQLsAction.rollbackSQLNumbers = []uint{2}
+	assert.EqualError(t, rollbackSQLsAction.validation(partialExecutedTask), ErrActionRollbackOnNonExecutedSQL.Error())
+
+	rollbackSQLsAction.rollbackSQLNumbers = []uint{1}
+	partialExecutedTask.RollbackSQLs[0].ExecStatus = model.SQLExecuteStatusSucceeded
+	assert.EqualError(t, rollbackSQLsAction.validation(partialExecutedTask), ErrActionRollbackOnRollbackedSQL.Error())
+
+	dryRunAction := &action{typ: ActionTypeDryRun}
+	assert.EqualError(t, dryRunAction.validation(executingTask), ErrActionDryRunOnExecutedTask.Error())
+	assert.EqualError(t, dryRunAction.validation(noAuditedTask), ErrActionDryRunOnNonAuditedTask.Error())
+}
+
+func Test_ruleExemptionMatcher(t *testing.T) {
+	task := &model.Task{
+		Schema:   "shop",
+		Instance: &model.Instance{Name: "inst_1"},
+	}
+	exemptions := []*model.RuleExemption{
+		{Model: model.Model{ID: 1}, RuleNames: "dml_check_where_is_invalid", Table: "legacy_orders"},
+		{Model: model.Model{ID: 2}, RuleNames: "dml_check_limit,ddl_check_pk_not_exist", InstanceName: "inst_2"},
+		{Model: model.Model{ID: 3}, RuleNames: "ddl_check_pk_not_exist", InstanceName: "inst_1", Schema: "report"},
+		{Model: model.Model{ID: 4}, RuleNames: "dml_check_limit", FingerprintSQL: "select * from t1"},
+		{Model: model.Model{ID: 5}, RuleNames: "dml_check_where_exist_func", Table: "id"},
+	}
+	m := newRuleExemptionMatcher(log.NewEntry(), task, &mockDriver{}, exemptions)
+
+	for _, c := range []struct {
+		rule     string
+		sql      string
+		expected uint
+	}{
+		{"dml_check_where_is_invalid", "update legacy_orders set a = 1", 1},
+		{"dml_check_where_is_invalid", "update `shop`.`legacy_orders` set a = 1", 1},
+		{"dml_check_where_is_invalid", "update legacy_orders_v2 set a = 1", 0},
+		{"dml_check_limit", "update legacy_orders set a = 1", 0},
+		{"ddl_check_pk_not_exist", "create table report.t1 (id int)", 3},
+		{"ddl_check_pk_not_exist", "create table t1 (id int)", 0},
+		{"dml_check_limit", "select * from t1", 4},
+		{"dml_check_limit", "select * from t2", 0},
+		{"", "update legacy_orders set a = 1", 0},
+		{"dml_check_where_is_invalid", "insert into legacy_orders values (1)", 1},
+		{"dml_check_where_is_invalid", "select legacy_orders.a from t1 as legacy_orders", 0},
+		{"dml_check_where_exist_func", "select id from t1 where id = 1", 0},
+		{"dml_check_where_exist_func", "select * from `id` where a = 1", 5},
+		{"dml_check_where_exist_func", "select * from t1 join shop.id on t1.id = id.a", 5},
+		{"dml_check_where_exist_func", "create table if not exists [id] (a int)", 5},
+	} {
+		var id uint
+		if e := m.match(c.rule, driver.Node{Text: c.sql}); e != nil {
+			id = e.ID
+		}
+		assert.Equal(t, c.expected, id, c.sql)
+	}
+
+	task.Schema = "report"
+	m = newRuleExemptionMatcher(log.NewEntry(), task, &mockDriver{}, exemptions)
+	for _, c := range []struct {
+		sql      string
+		expected uint
+	}{
+		{"create table t1 (id int)", 3},
+		{"create table `t1` (id int)", 3},
+		{"create table report.t1 (id int)", 3},
+		{"create table shop.t1 (id int)", 0},
+		{"create table `shop`.`t1` (id int)", 0},
+		{"insert into shop.t1 select * from t2", 3},
+	} {
+		var id uint
+		if e := m.match("ddl_check_pk_not_exist", driver.Node{Text: c.sql}); e != nil {
+			id = e.ID
+		}
+		assert.Equal(t, c.expected, id, c.sql)
+	}
+
+	result := driver.NewInspectResults()
+	result.AddResult(&driver.AuditResultItem{Level: driver.RuleLevelError, Message: "表必须有主键", RuleName: "ddl_check_pk_not_exist"})
+	result.AddResult(&driver.AuditResultItem{Level: driver.RuleLevelNotice, Message: "建议添加注释", RuleName: "ddl_check_table_without_comment"})
+	effective, exempted := m.apply(result, driver.Node{Text: "create table t1 (id int)"})
+	assert.Equal(t, driver.RuleLevelNotice, effective.Level())
+	assert.Equal(t, "[notice]建议添加注释\n[exempted]表必须有主键", auditResultMessage(result, effective, exempted))
+	results := convertAuditResultToModel(result, exempted)
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, uint(3), results[0].ExemptionId)
+		assert.Equal(t, uint(0), results[1].ExemptionId)
+	}
+}
+
+// rollbackDriver returns the reason of rollback SQL.
+type rollbackDriver struct {
+	mockDriver
+	reason string
+}
+
+func (d *rollbackDriver) GenRollbackSQL(ctx context.Context, sql string) (string, string, error) {
+	return "", d.reason, nil
+}
+
+func Test_genRollbackSQL(t *testing.T) {
+	task := &model.Task{
+		ExecuteSQLs: []*model.ExecuteSQL{
+			{AuditLevel: "", AuditResult: "[exempted]表必须有主键"},
+			{AuditLevel: string(driver.RuleLevelError), AuditResult: "[error]表必须有主键"},
+			{AuditLevel: string(driver.RuleLevelNormal), AuditResult: ""},
+		},
+	}
+	_, err := genRollbackSQL(log.NewEntry(), task, &rollbackDriver{reason: "不支持回滚"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, string(driver.RuleLevelNotice), task.ExecuteSQLs[0].AuditLevel)
+	assert.Equal(t, "[exempted]表必须有主键\n[notice]不支持回滚", task.ExecuteSQLs[0].AuditResult)
+	assert.Equal(t, string(driver.RuleLevelError), task.ExecuteSQLs[1].AuditLevel)
+	assert.Equal(t, "[error]表必须有主键\n[notice]不支持回滚", task.ExecuteSQLs[1].AuditResult)
+	assert.Equal(t, string(driver.RuleLevelNotice), task.ExecuteSQLs[2].AuditLevel)
+	assert.Equal(t, "[notice]不支持回滚", task.ExecuteSQLs[2].AuditResult)
+	assert.Len(t, task.ExecuteSQLs[2].AuditResults, 1)
+
+	// the audit result is not changed if there is no reason.
+	_, err = genRollbackSQL(log.NewEntry(), task, &rollbackDriver{})
+	assert.NoError(t, err)
+	assert.Equal(t, "[error]表必须有主键\n[notice]不支持回滚", task.ExecuteSQLs[1].AuditResult)
+}
+
+// splitDriver splits the SQLs by ";" and counts the Parse calls.
+type splitDriver struct {
+	mockDriver
+	parseCount int
+}
+
+func (d *splitDriver) Parse(ctx context.Context, sqlText string) ([]driver.Node, error) {
+	d.parseCount++
+	nodes := []driver.Node{}
+	for _, sql := range strings.Split(sqlText, ";") {
+		if sql = strings.TrimSpace(sql); sql != "" {
+			nodes = append(nodes, driver.Node{Text: sql, Fingerprint: strings.ToUpper(sql)})
+		}
+	}
+	return nodes, nil
+}
+
+func Test_parseSQLs(t *testing.T) {
+	d := &splitDriver{}
+	nodes, err := parseSQLs(log.NewEntry(), d, []string{"select 1", "select 2;", " select 3 "})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 1, d.parseCount)
+	assert.Equal(t, []driver.Node{
+		{Text: "select 1", Fingerprint: "SELECT 1"},
+		{Text: "select 2;", Fingerprint: "SELECT 2"},
+		{Text: " select 3 ", Fingerprint: "SELECT 3"},
+	}, nodes)
+
+	// the SQL contains delimiter is not split back, the SQLs are parsed one by one.
+	d = &splitDriver{}
+	nodes, err = parseSQLs(log.NewEntry(), d, []string{"select 1", "select ';'"})
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	assert.Equal(t, 3, d.parseCount)
+	assert.Len(t, nodes, 2)
+}
+
+func Test_setDryRunResult(t *testing.T) {
+	executeSQL := &model.ExecuteSQL{}
+	setDryRunResult(executeSQL, _driver.RowsAffected(3), nil)
+	assert.Equal(t, model.SQLDryRunStatusSucceeded, executeSQL.DryRunStatus)
+	assert.Equal(t, int64(3), executeSQL.DryRunRowAffects)
+
+	setDryRunResult(executeSQL, nil, driver.ErrDryRunSkipped)
+	assert.Equal(t, model.SQLDryRunStatusSkipped, executeSQL.DryRunStatus)
+	assert.Equal(t, int64(0), executeSQL.DryRunRowAffects)
+
+	setDryRunResult(executeSQL, nil, errors.New("table not exist"))
+	assert.Equal(t, model.SQLDryRunStatusFailed, executeSQL.DryRunStatus)
+	assert.Equal(t, "table not exist", executeSQL.DryRunResult)
 }
 
 func Test_action_audit_UpdateTask(t *testing.T) {
@@ -145,10 +346,21 @@ func Test_action_audit_UpdateTask(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"value", "match_type"}).AddRow(whitelist.Value, whitelist.MatchType))
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT count(*) FROM `sql_whitelist`")).
 		WillReturnRows(sqlmock.NewRows([]string{"count(*)"}).AddRow("1"))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `rule_exemptions`")).
+		WillReturnRows(sqlmock.NewRows([]string{"rule_names"}))
 
 	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `execute_sql_detail`")).
-		WithArgs(model.MockTime, model.MockTime, nil, 0, 0, act.task.ExecuteSQLs[0].Content, "", "", 0, "", 0, 0, "", model.SQLAuditStatusFinished, "[normal]白名单", "2882fdbb7d5bcda7b49ea0803493467e", "normal").
+		WithArgs(model.MockTime, model.MockTime, nil, 0, 0, act.task.ExecuteSQLs[0].Content, "", "", 0, "", 0, 0, "", model.SQLAuditStatusFinished, "[normal]白名单", "2882fdbb7d5bcda7b49ea0803493467e", "normal", "", 0, "", 0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `execute_sql_audit_results`")).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `execute_sql_audit_results`")).
+		WithArgs(model.MockTime, model.MockTime, nil, 0, 1, "normal", "白名单", "", "", 0, 0, "", 0).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -302,3 +514,133 @@ func TestScoreTask(t *testing.T) {
 
 	assert.Equal(t, int32(45), score)
 }
+
+func TestSqled_CancelTask(t *testing.T) {
+	s := &Sqled{currentTask: map[string]*action{}}
+
+	assert.Error(t, s.CancelTask("1"))
+	_, running := s.GetTaskProgress("1")
+	assert.False(t, running)
+
+	s.currentTask["1"] = newAction("1", ActionTypeAudit)
+	assert.Error(t, s.CancelTask("1"))
+
+	a := newAction("2", ActionTypeExecute)
+	s.currentTask["2"] = a
+	a.startProgress(2)
+	a.startSQLs([]uint{1}, []string{"update t1 set c1=1"})
+
+	progress, running := s.GetTaskProgress("2")
+	assert.True(t, running)
+	assert.False(t, progress.Canceled)
+	assert.Equal(t, []uint{1}, progress.CurrentSQLNumbers)
+
+	a.finishSQLs(1, 3)
+	assert.NoError(t, s.CancelTask("2"))
+	assert.Error(t, a.ctx.Err())
+
+	progress, _ = s.GetTaskProgress("2")
+	assert.True(t, progress.Canceled)
+	assert.Equal(t, 1, progress.FinishedSQLs)
+	assert.Equal(t, int64(3), progress.RowAffects)
+	assert.Empty(t, progress.CurrentSQL)
+}
+
+func TestAction_shouldStopExecution(t *testing.T) {
+	a := getAction([]string{"update t1 set c1=1", "update t1 set c1=2"}, ActionTypeExecute, nil)
+	assert.False(t, a.shouldStopExecution())
+
+	a.task.ExecuteSQLs[0].ExecStatus = model.SQLExecuteStatusFailed
+	for _, strategy := range []string{"", model.ExecFailureStrategyStop, model.ExecFailureStrategyRollbackExecuted} {
+		a.task.ExecFailureStrategy = strategy
+		assert.True(t, a.shouldStopExecution(), strategy)
+	}
+	a.task.ExecFailureStrategy = model.ExecFailureStrategyContinue
+	assert.False(t, a.shouldStopExecution())
+
+	a.cancel()
+	assert.True(t, a.shouldStopExecution())
+}
+
+func TestRollbackWorkflow_NotApproved(t *testing.T) {
+	workflow := &model.Workflow{
+		Record: &model.WorkflowRecord{
+			Status:                model.WorkflowStatusFinish,
+			CurrentWorkflowStepId: 2,
+			Steps: []*model.WorkflowStep{
+				{Model: model.Model{ID: 1}, Template: &model.WorkflowStepTemplate{Typ: model.WorkflowStepTypeSQLExecute}},
+				{Model: model.Model{ID: 2}, Template: &model.WorkflowStepTemplate{Typ: model.WorkflowStepTypeSQLRollback},
+					State: model.WorkflowStepStateInit},
+			},
+		},
+	}
+	err := RollbackWorkflow(workflow, []uint{1}, nil)
+	assert.EqualError(t, err, ErrActionRollbackOnNotApprovedWorkflow.Error())
+}
+
+func TestRollbackWorkflow_NotExecutedTask(t *testing.T) {
+	status := model.TaskStatusAudited
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(&model.Storage{}), "GetTaskDetailById",
+		func(_ *model.Storage, _ string) (*model.Task, bool, error) {
+			return &model.Task{Status: status}, true, nil
+		})
+	defer patches.Reset()
+
+	// the workflow record is finished when the execution is started, the
+	// rollback depends on the execution of task.
+	workflow := &model.Workflow{
+		Record: &model.WorkflowRecord{
+			Status: model.WorkflowStatusFinish,
+			Steps: []*model.WorkflowStep{
+				{Template: &model.WorkflowStepTemplate{Typ: model.WorkflowStepTypeSQLExecute}},
+			},
+		},
+	}
+	err := RollbackWorkflow(workflow, []uint{1}, nil)
+	assert.EqualError(t, err, ErrActionRollbackOnNonExecutedTask.Error())
+
+	status = model.TaskStatusExecuting
+	err = RollbackWorkflow(workflow, []uint{1}, nil)
+	assert.EqualError(t, err, ErrActionRollbackOnExecutingTask.Error())
+}
+
+func TestAction_rollbackExecuted(t *testing.T) {
+	patches := gomonkey.ApplyMethod(reflect.TypeOf(&model.Storage{}), "UpdateRollbackSqlStatus",
+		func(_ *model.Storage, baseSQL *model.BaseSQL, status, _ string) error {
+			if status != "" {
+				baseSQL.ExecStatus = status
+			}
+			return nil
+		})
+	defer patches.Reset()
+
+	a := getAction([]string{"insert into t1 values(1)", "update t1 set c1=1", "select * from t1"},
+		ActionTypeExecute, &mockDriver{})
+	for i, executeSQL := range a.task.ExecuteSQLs {
+		executeSQL.ID = uint(i + 1)
+		executeSQL.Number = uint(i + 1)
+		executeSQL.ExecStatus = model.SQLExecuteStatusSucceeded
+	}
+	a.task.ExecuteSQLs[2].ExecStatus = model.SQLExecuteStatusFailed
+	a.task.RollbackSQLs = []*model.RollbackSQL{
+		{BaseSQL: model.BaseSQL{Content: "delete from t1 where c1=1"}, ExecuteSQLId: 1},
+		{BaseSQL: model.BaseSQL{Content: ""}, ExecuteSQLId: 2},
+	}
+	a.startProgress(3)
+	a.finishSQLs(3, 2)
+
+	assert.Equal(t, "1 of the 2 executed SQLs are rolled back, the others have no rollback SQL", a.rollbackExecuted())
+	assert.Equal(t, model.SQLExecuteStatusSucceeded, a.task.RollbackSQLs[0].ExecStatus)
+
+	// the progress of execution is kept, and the rollback is tracked separately.
+	progress := a.getProgress()
+	assert.Equal(t, 3, progress.TotalSQLs)
+	assert.Equal(t, 3, progress.FinishedSQLs)
+	assert.Equal(t, int64(2), progress.RowAffects)
+	if !assert.NotNil(t, progress.Rollback) {
+		t.FailNow()
+	}
+	assert.Equal(t, ActionTypeRollback, progress.Rollback.ActionType)
+	assert.Equal(t, 1, progress.Rollback.TotalSQLs)
+	assert.Equal(t, 1, progress.Rollback.FinishedSQLs)
+}
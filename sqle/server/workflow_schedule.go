@@ -2,8 +2,11 @@ package server
 
 import (
 	"context"
+	_errors "errors"
 	"fmt"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/actiontech/sqle/sqle/notification"
@@ -70,21 +73,218 @@ func (s *Sqled) WorkflowSchedule(entry *logrus.Entry) {
 func ExecuteWorkflow(workflow *model.Workflow, userId uint) error {
 	s := model.GetStorage()
 
-	// get task and check connection before to execute it.
-	taskId := fmt.Sprintf("%d", workflow.Record.TaskId)
-	task, exist, err := s.GetTaskDetailById(taskId)
+	// get tasks of all targets and check connection before to execute them.
+	taskIds := workflow.Record.TaskIds()
+	for _, taskId := range taskIds {
+		task, exist, err := s.GetTaskDetailById(fmt.Sprintf("%d", taskId))
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return errors.New(errors.DataNotExist, fmt.Errorf("task is not exist"))
+		}
+		if err := checkTaskConnectable(task); err != nil {
+			return err
+		}
+		if err := setTaskExecFailureStrategy(task); err != nil {
+			return err
+		}
+	}
+
+	currentStep := workflow.CurrentStep()
+	if currentStep == nil {
+		return fmt.Errorf("workflow current step not found")
+	}
+	// update workflow
+	currentStep.State = model.WorkflowStepStateApprove
+	now := time.Now()
+	currentStep.OperateAt = &now
+	currentStep.OperationUserId = userId
+	workflow.Record.Status = model.WorkflowStatusFinish
+	workflow.Record.CurrentWorkflowStepId = 0
+
+	err := s.UpdateWorkflowStatus(workflow, currentStep)
 	if err != nil {
 		return err
 	}
-	if !exist {
-		return errors.New(errors.DataNotExist, fmt.Errorf("task is not exist"))
+	go func() {
+		sqledServer := GetSqled()
+
+		// each target is executed independently, one failed target does not
+		// stop the others.
+		var wg sync.WaitGroup
+		var failed int32
+		for _, taskId := range taskIds {
+			wg.Add(1)
+			go func(taskId uint) {
+				defer wg.Done()
+				task, err := sqledServer.AddTaskWaitResult(fmt.Sprintf("%d", taskId), ActionTypeExecute)
+				if err != nil || task.Status == model.TaskStatusExecuteFailed {
+					atomic.StoreInt32(&failed, 1)
+				}
+			}(taskId)
+		}
+		wg.Wait()
+
+		if atomic.LoadInt32(&failed) == 1 {
+			go notification.NotifyWorkflow(fmt.Sprintf("%v", workflow.ID), notification.WorkflowNotifyTypeExecuteFail)
+		} else {
+			go notification.NotifyWorkflow(fmt.Sprintf("%v", workflow.ID), notification.WorkflowNotifyTypeExecuteSuccess)
+		}
+	}()
+	return nil
+}
+
+// setTaskExecFailureStrategy copies the failure strategy from the workflow
+// template of task instance, so the changes of template later do not affect
+// the executed task.
+func setTaskExecFailureStrategy(task *model.Task) error {
+	s := model.GetStorage()
+	strategy := model.ExecFailureStrategyStop
+	if task.Instance != nil {
+		template, exist, err := s.GetWorkflowTemplateById(task.Instance.WorkflowTemplateId)
+		if err != nil {
+			return err
+		}
+		if exist && template.ExecFailureStrategy != "" {
+			strategy = template.ExecFailureStrategy
+		}
+	}
+	return s.UpdateTask(task, map[string]interface{}{
+		"exec_failure_strategy": strategy,
+	})
+}
+
+var (
+	ErrActionRollbackOnExecutingTask       = _errors.New("task is executing, can not do rollback on it")
+	ErrActionRollbackOnNotApprovedWorkflow = _errors.New("the rollback of workflow is waiting for approval, can not do rollback on it")
+	ErrActionApproveRollbackWithoutRequest = _errors.New("no rollback is requested on workflow")
+)
+
+// RollbackWorkflow rolls back the execute SQLs specified by sqlNumbers on the
+// tasks of workflow, all of the execute SQLs are rolled back if sqlNumbers is
+// empty. If the workflow has sql_rollback step, the rollback is saved in
+// workflow and the step is waiting for approval, the tasks are rolled back by
+// ApproveRollbackWorkflow after it is approved.
+func RollbackWorkflow(workflow *model.Workflow, taskIds []uint, sqlNumbers []uint) error {
+	if workflow.Record.CurrentWorkflowStepId != 0 {
+		return errors.New(errors.TaskActionInvalid, ErrActionRollbackOnNotApprovedWorkflow)
+	}
+	if err := validateRollbackTasks(taskIds, sqlNumbers); err != nil {
+		return err
+	}
+
+	rollbackStep := workflow.RollbackStep()
+	if rollbackStep == nil {
+		rollbackWorkflowTasks(workflow, taskIds, sqlNumbers)
+		return nil
+	}
+	// the step is approved for each rollback, the state of the previous
+	// rollback is overwritten.
+	rollbackStep.State = model.WorkflowStepStateInit
+	rollbackStep.OperationUserId = 0
+	rollbackStep.OperateAt = nil
+	rollbackStep.Reason = ""
+	workflow.Record.CurrentWorkflowStepId = rollbackStep.ID
+	workflow.Record.SetRollbackRequest(taskIds, sqlNumbers)
+	if err := model.GetStorage().UpdateWorkflowRollback(workflow, rollbackStep); err != nil {
+		return err
 	}
+	go notification.NotifyWorkflow(fmt.Sprintf("%v", workflow.ID), notification.WorkflowNotifyTypeApprove)
+	return nil
+}
+
+// ApproveRollbackWorkflow approves the sql_rollback step of workflow, and rolls
+// back the tasks and SQLs requested by RollbackWorkflow. The approval does not
+// cover the other tasks and SQLs of workflow.
+func ApproveRollbackWorkflow(workflow *model.Workflow, userId uint) error {
+	rollbackStep := workflow.CurrentStep()
+	if rollbackStep == nil || rollbackStep.Template.Typ != model.WorkflowStepTypeSQLRollback {
+		return fmt.Errorf("workflow current step is not sql_rollback")
+	}
+	taskIds, sqlNumbers := workflow.Record.RollbackRequest()
+	if len(taskIds) == 0 {
+		return errors.New(errors.TaskActionInvalid, ErrActionApproveRollbackWithoutRequest)
+	}
+	// the tasks may be changed after the rollback is requested.
+	if err := validateRollbackTasks(taskIds, sqlNumbers); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	rollbackStep.State = model.WorkflowStepStateApprove
+	rollbackStep.OperateAt = &now
+	rollbackStep.OperationUserId = userId
+	workflow.Record.CurrentWorkflowStepId = 0
+	workflow.Record.SetRollbackRequest(nil, nil)
+	if err := model.GetStorage().UpdateWorkflowRollback(workflow, rollbackStep); err != nil {
+		return err
+	}
+	rollbackWorkflowTasks(workflow, taskIds, sqlNumbers)
+	return nil
+}
+
+// validateRollbackTasks validates all tasks before to roll back them, the
+// rollback SQLs are executed asynchronously. The task is allowed to roll back
+// only after its execution is ended.
+func validateRollbackTasks(taskIds []uint, sqlNumbers []uint) error {
+	s := model.GetStorage()
+	for _, taskId := range taskIds {
+		task, exist, err := s.GetTaskDetailById(fmt.Sprintf("%d", taskId))
+		if err != nil {
+			return err
+		}
+		if !exist {
+			return errors.New(errors.DataNotExist, fmt.Errorf("task is not exist"))
+		}
+		switch task.Status {
+		case model.TaskStatusExecuting:
+			return errors.New(errors.TaskActionInvalid, ErrActionRollbackOnExecutingTask)
+		case model.TaskStatusExecuteSucceeded, model.TaskStatusExecuteFailed:
+		default:
+			return errors.New(errors.TaskActionInvalid, ErrActionRollbackOnNonExecutedTask)
+		}
+		a := &action{typ: ActionTypeRollback, rollbackSQLNumbers: sqlNumbers}
+		if err := a.validation(task); err != nil {
+			return err
+		}
+		if err := checkTaskConnectable(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rollbackWorkflowTasks rolls back the tasks one by one, and the workflow
+// creator and the approver of sql_rollback step are notified when all of them
+// are finished.
+func rollbackWorkflowTasks(workflow *model.Workflow, taskIds []uint, sqlNumbers []uint) {
+	go func() {
+		sqledServer := GetSqled()
+		var failed bool
+		for _, taskId := range taskIds {
+			_, err := sqledServer.RollbackTaskWaitResult(fmt.Sprintf("%d", taskId), sqlNumbers)
+			if err != nil {
+				failed = true
+			}
+		}
+
+		if failed {
+			go notification.NotifyWorkflow(fmt.Sprintf("%v", workflow.ID), notification.WorkflowNotifyTypeRollbackFail)
+		} else {
+			go notification.NotifyWorkflow(fmt.Sprintf("%v", workflow.ID), notification.WorkflowNotifyTypeRollbackSuccess)
+		}
+	}()
+}
+
+// checkTaskConnectable checks the instance of task is connectable. If instance is
+// not connectable, exec sql must be failed; commit action unable to retry, so
+// don't to exec it.
+func checkTaskConnectable(task *model.Task) error {
 	if task.Instance == nil {
 		return errors.New(errors.DataNotExist, fmt.Errorf("instance is not exist"))
 	}
 
-	// if instance is not connectable, exec sql must be failed;
-	// commit action unable to retry, so don't to exec it.
 	dsn := &driver.DSN{
 		Host:             task.Instance.Host,
 		Port:             task.Instance.Port,
@@ -107,31 +307,5 @@ func ExecuteWorkflow(workflow *model.Workflow, userId uint) error {
 	if err := d.Ping(context.TODO()); err != nil {
 		return errors.New(errors.ConnectRemoteDatabaseError, err)
 	}
-
-	currentStep := workflow.CurrentStep()
-	if currentStep == nil {
-		return fmt.Errorf("workflow current step not found")
-	}
-	// update workflow
-	currentStep.State = model.WorkflowStepStateApprove
-	now := time.Now()
-	currentStep.OperateAt = &now
-	currentStep.OperationUserId = userId
-	workflow.Record.Status = model.WorkflowStatusFinish
-	workflow.Record.CurrentWorkflowStepId = 0
-
-	err = s.UpdateWorkflowStatus(workflow, currentStep)
-	if err != nil {
-		return err
-	}
-	go func() {
-		sqledServer := GetSqled()
-		task, err := sqledServer.AddTaskWaitResult(taskId, ActionTypeExecute)
-		if err != nil || task.Status == model.TaskStatusExecuteFailed {
-			go notification.NotifyWorkflow(fmt.Sprintf("%v", workflow.ID), notification.WorkflowNotifyTypeExecuteFail)
-		} else {
-			go notification.NotifyWorkflow(fmt.Sprintf("%v", workflow.ID), notification.WorkflowNotifyTypeExecuteSuccess)
-		}
-	}()
 	return nil
 }
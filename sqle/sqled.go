@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/actiontech/sqle/sqle/utils"
 
@@ -19,6 +20,11 @@ import (
 	"github.com/facebookgo/grace/gracenet"
 )
 
+const (
+	defaultDriverPoolMaxSize     = 10
+	defaultDriverPoolIdleTimeout = 300 // seconds
+)
+
 func Run(config *config.Config) error {
 	// init logger
 	log.InitLogger(config.Server.SqleCnf.LogPath)
@@ -36,6 +42,18 @@ func Run(config *config.Config) error {
 	if err := driver.InitPlugins(config.Server.SqleCnf.PluginPath); err != nil {
 		return fmt.Errorf("init plugins error: %v", err)
 	}
+	defer driver.StopPlugins()
+
+	poolMaxSize := config.Server.SqleCnf.DriverPoolMaxSize
+	if poolMaxSize == 0 {
+		poolMaxSize = defaultDriverPoolMaxSize
+	}
+	poolIdleTimeout := config.Server.SqleCnf.DriverPoolIdleTimeout
+	if poolIdleTimeout <= 0 {
+		poolIdleTimeout = defaultDriverPoolIdleTimeout
+	}
+	driver.InitDriverPool(poolMaxSize, time.Duration(poolIdleTimeout)*time.Second)
+	defer driver.StopDriverPool()
 
 	dbConfig := config.Server.DBCnf.MysqlCnf
 